@@ -0,0 +1,184 @@
+//go:build tinygo
+
+// Package ad8232 implements a capture helper for single lead ECG analog
+// front-ends such as the AD8232 (and pin compatible boards like the
+// SparkFun "Heart Rate Monitor").
+//
+// The analog output is sampled at a fixed rate on an ADC pin, the lead-off
+// comparator outputs (LO+ and LO-) are checked on every sample, and the
+// samples are passed through a filter chain removing the baseline wander and
+// mains interference.
+//
+// Datasheet: https://www.analog.com/media/en/technical-documentation/data-sheets/ad8232.pdf
+package ad8232 // import "tinygo.org/x/drivers/ad8232"
+
+import (
+	"errors"
+	"machine"
+	"time"
+)
+
+// ErrLeadOff is returned when one of the electrodes is not connected.
+var ErrLeadOff = errors.New("ad8232: lead off")
+
+// Config holds the capture settings.
+type Config struct {
+	// SampleRate in Hz. Defaults to 250.
+	SampleRate uint32
+
+	// MainsFrequency is the frequency to remove with the notch filter,
+	// usually 50 or 60Hz. Defaults to 50. Set to a negative value to
+	// disable the notch filter.
+	MainsFrequency int32
+
+	// HighPassCutoff is the cutoff of the baseline wander filter in
+	// millihertz. Defaults to 500 (0.5Hz).
+	HighPassCutoff uint32
+
+	// LowPassCutoff is the cutoff of the optional muscle noise filter in
+	// Hz, 0 disables it.
+	LowPassCutoff uint32
+}
+
+// Device holds the pins and filter chain.
+type Device struct {
+	adc     machine.ADC
+	loPlus  machine.Pin
+	loMinus machine.Pin
+	sdn     machine.Pin
+
+	period   time.Duration
+	deadline time.Time
+
+	highPass HighPass
+	notch    Biquad
+	lowPass  Biquad
+	useNotch bool
+	useLow   bool
+}
+
+// New returns a new ECG front-end. loPlus and loMinus are the lead-off
+// outputs, sdn is the active low shutdown input. Any of them may be
+// machine.NoPin when not connected.
+func New(output, loPlus, loMinus, sdn machine.Pin) *Device {
+	return &Device{
+		adc:     machine.ADC{Pin: output},
+		loPlus:  loPlus,
+		loMinus: loMinus,
+		sdn:     sdn,
+	}
+}
+
+// Configure sets up the pins and filter chain.
+func (d *Device) Configure(cfg Config) {
+	if cfg.SampleRate == 0 {
+		cfg.SampleRate = 250
+	}
+	if cfg.MainsFrequency == 0 {
+		cfg.MainsFrequency = 50
+	}
+	if cfg.HighPassCutoff == 0 {
+		cfg.HighPassCutoff = 500
+	}
+
+	machine.InitADC()
+	d.adc.Configure(machine.ADCConfig{})
+	if d.loPlus != machine.NoPin {
+		d.loPlus.Configure(machine.PinConfig{Mode: machine.PinInput})
+	}
+	if d.loMinus != machine.NoPin {
+		d.loMinus.Configure(machine.PinConfig{Mode: machine.PinInput})
+	}
+	if d.sdn != machine.NoPin {
+		d.sdn.Configure(machine.PinConfig{Mode: machine.PinOutput})
+		d.sdn.High()
+	}
+
+	rate := float32(cfg.SampleRate)
+	d.period = time.Second / time.Duration(cfg.SampleRate)
+	d.highPass = NewHighPass(float32(cfg.HighPassCutoff)/1000, rate)
+	d.useNotch = cfg.MainsFrequency > 0 && uint32(cfg.MainsFrequency)*2 < cfg.SampleRate
+	if d.useNotch {
+		d.notch = NewNotch(float32(cfg.MainsFrequency), rate, 20)
+	}
+	d.useLow = cfg.LowPassCutoff > 0 && cfg.LowPassCutoff*2 < cfg.SampleRate
+	if d.useLow {
+		d.lowPass = NewLowPass(float32(cfg.LowPassCutoff), rate)
+	}
+	d.deadline = time.Time{}
+}
+
+// Shutdown puts the front-end in low power mode, or wakes it up.
+func (d *Device) Shutdown(shutdown bool) {
+	if d.sdn == machine.NoPin {
+		return
+	}
+	d.sdn.Set(!shutdown)
+}
+
+// LeadOff returns whether one of the electrodes is disconnected.
+func (d *Device) LeadOff() bool {
+	return (d.loPlus != machine.NoPin && d.loPlus.Get()) ||
+		(d.loMinus != machine.NoPin && d.loMinus.Get())
+}
+
+// ReadRaw reads the unfiltered ADC value.
+func (d *Device) ReadRaw() uint16 {
+	return d.adc.Get()
+}
+
+// Sample reads and filters a single sample, without pacing. The result is
+// centered around zero in ADC units (full scale is ±32768). When a lead is
+// off, the filters are reset so they settle quickly once it is reconnected,
+// and ErrLeadOff is returned.
+func (d *Device) Sample() (int32, error) {
+	if d.LeadOff() {
+		d.Reset()
+		return 0, ErrLeadOff
+	}
+	y := d.highPass.Filter(float32(d.adc.Get()))
+	if d.useNotch {
+		y = d.notch.Filter(y)
+	}
+	if d.useLow {
+		y = d.lowPass.Filter(y)
+	}
+	return int32(y), nil
+}
+
+// Next waits until the next sample is due and returns it. Deadlines are
+// absolute, so the sample rate does not drift with the processing time;
+// when the caller falls behind, the missed sample slots are skipped.
+func (d *Device) Next() (int32, error) {
+	now := time.Now()
+	if d.deadline.IsZero() {
+		d.deadline = now
+	}
+	if wait := d.deadline.Sub(now); wait > 0 {
+		time.Sleep(wait)
+	} else if -wait > d.period {
+		d.deadline = now
+	}
+	d.deadline = d.deadline.Add(d.period)
+	return d.Sample()
+}
+
+// Capture fills buf with paced samples. It stops at the first error, and
+// returns the number of samples captured.
+func (d *Device) Capture(buf []int32) (int, error) {
+	for i := range buf {
+		v, err := d.Next()
+		if err != nil {
+			return i, err
+		}
+		buf[i] = v
+	}
+	return len(buf), nil
+}
+
+// Reset clears the filter state.
+func (d *Device) Reset() {
+	d.highPass.Reset()
+	d.notch.Reset()
+	d.lowPass.Reset()
+}
@@ -0,0 +1,90 @@
+package ad8232
+
+import "math"
+
+// HighPass is a first order IIR high-pass filter, used to remove the
+// baseline wander caused by breathing and electrode movement.
+type HighPass struct {
+	a     float32
+	prevX float32
+	prevY float32
+	init  bool
+}
+
+// NewHighPass returns a high-pass filter with the given cutoff frequency for
+// a signal sampled at sampleRate, both in Hz.
+func NewHighPass(cutoff, sampleRate float32) HighPass {
+	rc := 1 / (2 * math.Pi * float64(cutoff))
+	dt := 1 / float64(sampleRate)
+	return HighPass{a: float32(rc / (rc + dt))}
+}
+
+// Filter processes a single sample.
+func (f *HighPass) Filter(x float32) float32 {
+	if !f.init {
+		// Start from the first sample to avoid a large step response.
+		f.prevX = x
+		f.init = true
+	}
+	y := f.a * (f.prevY + x - f.prevX)
+	f.prevX = x
+	f.prevY = y
+	return y
+}
+
+// Reset clears the filter state.
+func (f *HighPass) Reset() {
+	f.prevX, f.prevY, f.init = 0, 0, false
+}
+
+// Biquad is a second order IIR filter in direct form I.
+type Biquad struct {
+	b0, b1, b2, a1, a2 float32
+	x1, x2, y1, y2     float32
+}
+
+// NewNotch returns a notch filter removing the given frequency, for example
+// 50Hz or 60Hz mains interference. A higher q gives a narrower notch; 10 to
+// 30 is a reasonable range for ECG signals.
+func NewNotch(frequency, sampleRate, q float32) Biquad {
+	w0 := 2 * math.Pi * float64(frequency) / float64(sampleRate)
+	cos := math.Cos(w0)
+	alpha := math.Sin(w0) / (2 * float64(q))
+	a0 := 1 + alpha
+	return Biquad{
+		b0: float32(1 / a0),
+		b1: float32(-2 * cos / a0),
+		b2: float32(1 / a0),
+		a1: float32(-2 * cos / a0),
+		a2: float32((1 - alpha) / a0),
+	}
+}
+
+// NewLowPass returns a second order Butterworth low-pass filter with the
+// given cutoff frequency, useful to remove muscle noise above ~40Hz.
+func NewLowPass(cutoff, sampleRate float32) Biquad {
+	w0 := 2 * math.Pi * float64(cutoff) / float64(sampleRate)
+	cos := math.Cos(w0)
+	alpha := math.Sin(w0) / math.Sqrt2
+	a0 := 1 + alpha
+	return Biquad{
+		b0: float32((1 - cos) / 2 / a0),
+		b1: float32((1 - cos) / a0),
+		b2: float32((1 - cos) / 2 / a0),
+		a1: float32(-2 * cos / a0),
+		a2: float32((1 - alpha) / a0),
+	}
+}
+
+// Filter processes a single sample.
+func (f *Biquad) Filter(x float32) float32 {
+	y := f.b0*x + f.b1*f.x1 + f.b2*f.x2 - f.a1*f.y1 - f.a2*f.y2
+	f.x2, f.x1 = f.x1, x
+	f.y2, f.y1 = f.y1, y
+	return y
+}
+
+// Reset clears the filter state.
+func (f *Biquad) Reset() {
+	f.x1, f.x2, f.y1, f.y2 = 0, 0, 0, 0
+}
@@ -0,0 +1,39 @@
+package ad8232
+
+import (
+	"math"
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+)
+
+// amplitude returns the peak amplitude of a filtered sine wave, after the
+// filter has settled.
+func amplitude(filter func(float32) float32, frequency, sampleRate float64) float32 {
+	var peak float32
+	for i := 0; i < 2000; i++ {
+		y := filter(float32(1000 * math.Sin(2*math.Pi*frequency*float64(i)/sampleRate)))
+		if i > 1000 && y > peak {
+			peak = y
+		}
+	}
+	return peak
+}
+
+func TestNotch(t *testing.T) {
+	c := qt.New(t)
+
+	n := NewNotch(50, 250, 20)
+	c.Assert(amplitude(n.Filter, 50, 250) < 20, qt.IsTrue)
+	n = NewNotch(50, 250, 20)
+	c.Assert(amplitude(n.Filter, 10, 250) > 950, qt.IsTrue)
+}
+
+func TestHighPass(t *testing.T) {
+	c := qt.New(t)
+
+	h := NewHighPass(0.5, 250)
+	c.Assert(amplitude(h.Filter, 0.05, 250) < 150, qt.IsTrue)
+	h = NewHighPass(0.5, 250)
+	c.Assert(amplitude(h.Filter, 10, 250) > 950, qt.IsTrue)
+}
@@ -0,0 +1,157 @@
+// Package ad9833 implements a driver for the AD9833 waveform generator, a
+// direct digital synthesizer making sine, triangle and square waves up to
+// 12.5MHz from a 25MHz clock.
+//
+// The SPI bus must be configured in mode 2, MSB first, up to 40MHz.
+//
+// Datasheet: https://www.analog.com/media/en/technical-documentation/data-sheets/ad9833.pdf
+package ad9833 // import "tinygo.org/x/drivers/ad9833"
+
+import (
+	"tinygo.org/x/drivers"
+)
+
+// Bits of the control register.
+const (
+	controlB28     = 0x2000
+	controlHLB     = 0x1000
+	controlFSelect = 0x0800
+	controlPSelect = 0x0400
+	controlReset   = 0x0100
+	controlSleep1  = 0x0080 // MCLK disabled
+	controlSleep12 = 0x0040 // DAC powered down
+	controlOpBitEn = 0x0020
+	controlDiv2    = 0x0008
+	controlMode    = 0x0002
+
+	regFreq0  = 0x4000
+	regFreq1  = 0x8000
+	regPhase0 = 0xC000
+	regPhase1 = 0xE000
+)
+
+// Waveform is the shape of the output.
+type Waveform uint16
+
+const (
+	Sine     Waveform = 0
+	Triangle Waveform = controlMode
+
+	// Square is a square wave at the output frequency, with the full
+	// supply voltage swing.
+	Square Waveform = controlOpBitEn | controlDiv2
+
+	// SquareHalf is a square wave at half the output frequency.
+	SquareHalf Waveform = controlOpBitEn
+)
+
+// chipSelect is the FSYNC pin, a machine.Pin.
+type chipSelect interface {
+	High()
+	Low()
+}
+
+// Config is the configuration of the device.
+type Config struct {
+	// MasterClock is the frequency of the MCLK input in Hz, 25MHz when 0.
+	MasterClock uint32
+}
+
+// Device wraps an SPI connection to an AD9833 device.
+type Device struct {
+	bus     drivers.SPI
+	fsync   chipSelect
+	mclk    uint32
+	control uint16
+	buf     [2]byte
+}
+
+// Configure resets the device, and starts a sine wave at 0Hz from the
+// registers FREQ0 and PHASE0.
+func (d *Device) Configure(cfg Config) error {
+	d.mclk = cfg.MasterClock
+	if d.mclk == 0 {
+		d.mclk = 25000000
+	}
+	// B28 makes the frequency registers load as two consecutive writes.
+	d.control = controlB28
+	if err := d.write(d.control | controlReset); err != nil {
+		return err
+	}
+	for reg := uint8(0); reg < 2; reg++ {
+		if err := d.SetFrequency(reg, 0); err != nil {
+			return err
+		}
+		if err := d.SetPhase(reg, 0); err != nil {
+			return err
+		}
+	}
+	return d.write(d.control)
+}
+
+// SetFrequency sets a frequency register, 0 or 1, to a frequency in Hz. The
+// resolution is MCLK / 2^28, 0.1Hz with a 25MHz clock.
+func (d *Device) SetFrequency(reg uint8, hz uint32) error {
+	word := uint32(uint64(hz) << 28 / uint64(d.mclk))
+	prefix := uint16(regFreq0)
+	if reg&1 != 0 {
+		prefix = regFreq1
+	}
+	if err := d.write(prefix | uint16(word&0x3FFF)); err != nil {
+		return err
+	}
+	return d.write(prefix | uint16(word>>14&0x3FFF))
+}
+
+// SetPhase sets a phase register, 0 or 1, to a phase offset in degrees.
+func (d *Device) SetPhase(reg uint8, degrees uint16) error {
+	word := uint32(degrees%360) * 4096 / 360
+	prefix := uint16(regPhase0)
+	if reg&1 != 0 {
+		prefix = regPhase1
+	}
+	return d.write(prefix | uint16(word))
+}
+
+// SelectFrequency selects the frequency register, 0 or 1, of the output.
+// Switching between two preset registers makes FSK modulation.
+func (d *Device) SelectFrequency(reg uint8) error {
+	return d.setControl(controlFSelect, reg&1 != 0)
+}
+
+// SelectPhase selects the phase register, 0 or 1, of the output, for PSK
+// modulation.
+func (d *Device) SelectPhase(reg uint8) error {
+	return d.setControl(controlPSelect, reg&1 != 0)
+}
+
+// SetWaveform sets the shape of the output.
+func (d *Device) SetWaveform(w Waveform) error {
+	d.control &^= uint16(Square | Triangle)
+	d.control |= uint16(w)
+	return d.write(d.control)
+}
+
+// Sleep stops the clock and powers down the DAC when on is set. The output
+// stays at its current level.
+func (d *Device) Sleep(on bool) error {
+	return d.setControl(controlSleep1|controlSleep12, on)
+}
+
+func (d *Device) setControl(bits uint16, on bool) error {
+	if on {
+		d.control |= bits
+	} else {
+		d.control &^= bits
+	}
+	return d.write(d.control)
+}
+
+func (d *Device) write(word uint16) error {
+	d.buf[0] = byte(word >> 8)
+	d.buf[1] = byte(word)
+	d.fsync.Low()
+	err := d.bus.Tx(d.buf[:], nil)
+	d.fsync.High()
+	return err
+}
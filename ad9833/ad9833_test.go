@@ -0,0 +1,51 @@
+package ad9833
+
+import (
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+)
+
+// fakeBus records the words written while FSYNC is low.
+type fakeBus struct {
+	low   bool
+	words []uint16
+}
+
+func (b *fakeBus) High() { b.low = false }
+func (b *fakeBus) Low()  { b.low = true }
+
+func (b *fakeBus) Tx(w, r []byte) error {
+	if b.low && len(w) == 2 {
+		b.words = append(b.words, uint16(w[0])<<8|uint16(w[1]))
+	}
+	return nil
+}
+
+func (b *fakeBus) Transfer(w byte) (byte, error) { return 0, nil }
+
+func TestDevice(t *testing.T) {
+	c := qt.New(t)
+	bus := &fakeBus{}
+	d := &Device{bus: bus, fsync: bus}
+	c.Assert(d.Configure(Config{}), qt.IsNil)
+	c.Assert(bus.words, qt.DeepEquals, []uint16{
+		0x2100,
+		0x4000, 0x4000, 0xC000,
+		0x8000, 0x8000, 0xE000,
+		0x2000,
+	})
+
+	// 1kHz from 25MHz: 1000 * 2^28 / 25e6 = 10737.
+	bus.words = nil
+	c.Assert(d.SetFrequency(1, 1000), qt.IsNil)
+	c.Assert(d.SetPhase(0, 90), qt.IsNil)
+	c.Assert(bus.words, qt.DeepEquals, []uint16{0x8000 | 10737, 0x8000, 0xC000 | 1024})
+
+	bus.words = nil
+	c.Assert(d.SetWaveform(Square), qt.IsNil)
+	c.Assert(d.SetWaveform(Triangle), qt.IsNil)
+	c.Assert(d.SelectFrequency(1), qt.IsNil)
+	c.Assert(d.Sleep(true), qt.IsNil)
+	c.Assert(bus.words, qt.DeepEquals, []uint16{0x2028, 0x2002, 0x2802, 0x28C2})
+}
@@ -0,0 +1,23 @@
+//go:build tinygo
+
+package ad9833
+
+import (
+	"machine"
+
+	"tinygo.org/x/drivers"
+)
+
+// New creates a new AD9833 connection. The SPI bus must already be
+// configured in mode 2.
+//
+// This function only creates the Device object and sets up the FSYNC pin,
+// it does not touch the device.
+func New(bus drivers.SPI, fsync machine.Pin) *Device {
+	fsync.Configure(machine.PinConfig{Mode: machine.PinOutput})
+	fsync.High()
+	return &Device{
+		bus:   bus,
+		fsync: fsync,
+	}
+}
@@ -0,0 +1,302 @@
+// Package ads131m04 implements a driver for the ADS131M04, a 4 channel,
+// simultaneously sampling 24-bit delta-sigma ADC with a programmable gain
+// amplifier on every channel, made for precision sensing such as load cells
+// and energy metering.
+//
+// Samples can be read one at a time with Update or ReadSample, or streamed
+// from the DRDY interrupt into a ring buffer with StartStream.
+//
+// Datasheet: https://www.ti.com/lit/ds/symlink/ads131m04.pdf
+package ads131m04 // import "tinygo.org/x/drivers/ads131m04"
+
+import (
+	"errors"
+	"sync/atomic"
+	"time"
+
+	"tinygo.org/x/drivers"
+)
+
+var (
+	errNotFound  = errors.New("ads131m04: device not found")
+	errNoCommand = errors.New("ads131m04: command not acknowledged")
+	errStreaming = errors.New("ads131m04: streaming in progress")
+	errNoDRDY    = errors.New("ads131m04: no DRDY pin")
+)
+
+// Channels is the number of channels.
+const Channels = 4
+
+// Gain is the gain of the programmable gain amplifier of a channel. The full
+// scale range is ±1.2V divided by the gain.
+type Gain uint8
+
+const (
+	Gain1 Gain = iota // default
+	Gain2
+	Gain4
+	Gain8
+	Gain16
+	Gain32
+	Gain64
+	Gain128
+)
+
+// OSR is the oversampling ratio. With the usual 8.192MHz clock the data
+// rate is 4.096MHz divided by the oversampling ratio.
+type OSR uint8
+
+const (
+	OSR128 OSR = iota + 1
+	OSR256
+	OSR512
+	OSR1024 // default, 4kSPS
+	OSR2048
+	OSR4096
+	OSR8192
+	OSR16256
+)
+
+// Config holds the ADC configuration.
+type Config struct {
+	Gains [Channels]Gain
+	OSR   OSR
+}
+
+// Sample is one conversion result of all channels, as 24-bit signed values.
+type Sample [Channels]int32
+
+// Device wraps an SPI connection to an ADS131M04.
+type Device struct {
+	bus   drivers.SPI
+	cs    func(level bool)
+	ready func() bool                // DRDY pin, nil when not set
+	irq   func(handler func()) error // DRDY interrupt, nil when not set
+	gains [Channels]Gain
+	tx    [frameSize]byte
+	rx    [frameSize]byte
+	last  Sample
+
+	// Ring buffer written by the DRDY interrupt.
+	ring      []Sample
+	head      uint32 // written by the interrupt
+	tail      uint32 // written by Read
+	overflows uint32
+}
+
+// A frame is the command or status word, one word per channel and the CRC
+// word, with the default 24-bit words.
+const frameSize = (Channels + 2) * 3
+
+// Configure resets the ADC and writes the gains and the oversampling ratio.
+func (d *Device) Configure(cfg Config) error {
+	if err := d.frame(cmdReset, 0); err != nil {
+		return err
+	}
+	time.Sleep(time.Millisecond)
+	// The reset response comes with the next frame, as for any command.
+	if err := d.frame(cmdNull, 0); err != nil {
+		return err
+	}
+	if d.word(0)>>8 != resetResponse {
+		return errNotFound
+	}
+	id, err := d.readRegister(regID)
+	if err != nil {
+		return err
+	}
+	if id>>8 != idChannels {
+		return errNotFound
+	}
+
+	d.gains = cfg.Gains
+	gain := uint16(0)
+	for ch, g := range cfg.Gains {
+		gain |= uint16(g&0x07) << (4 * ch)
+	}
+	if err := d.writeRegister(regGAIN1, gain); err != nil {
+		return err
+	}
+
+	osr := uint16(3)
+	if cfg.OSR != 0 {
+		osr = uint16(cfg.OSR - 1)
+	}
+	return d.writeRegister(regCLOCK, clockChannelsEnabled|osr<<2|clockPowerHighRes)
+}
+
+// Standby stops the conversions to save power.
+func (d *Device) Standby() error {
+	return d.frame(cmdStandby, 0)
+}
+
+// Wakeup resumes the conversions after Standby.
+func (d *Device) Wakeup() error {
+	return d.frame(cmdWakeup, 0)
+}
+
+// ReadSample reads the latest conversion result. It does not wait for a new
+// one, see SetDataReadyPin and StartStream.
+func (d *Device) ReadSample(dst *Sample) error {
+	if d.ring != nil {
+		return errStreaming
+	}
+	return d.readSample(dst)
+}
+
+// Ready returns whether a new conversion result is available. It always
+// returns true if no DRDY pin has been set.
+func (d *Device) Ready() bool {
+	return d.ready == nil || !d.ready()
+}
+
+// Update reads the latest conversion result of all channels.
+func (d *Device) Update(which drivers.Measurement) error {
+	if which&drivers.Voltage == 0 {
+		return nil
+	}
+	return d.ReadSample(&d.last)
+}
+
+// Voltage returns the voltage of a channel in µV, as of the last Update.
+func (d *Device) Voltage(ch int) int32 {
+	return d.Microvolts(ch, d.last[ch])
+}
+
+// Microvolts converts a raw value of the given channel to µV, according to
+// its gain.
+func (d *Device) Microvolts(ch int, raw int32) int32 {
+	// 1.2V full scale over 2^23 codes.
+	return int32(int64(raw) * 1200000 >> d.gains[ch] >> 23)
+}
+
+// StartStream reads every conversion result from the DRDY interrupt into
+// buf, used as a ring buffer, until StopStream is called. Results are taken
+// out with Read; when the buffer is full new results are dropped and
+// counted by Overflows. The DRDY pin must have been set with
+// SetDataReadyPin, and the SPI bus must not be used by anything else while
+// streaming.
+func (d *Device) StartStream(buf []Sample) error {
+	if d.irq == nil {
+		return errNoDRDY
+	}
+	d.ring = buf
+	atomic.StoreUint32(&d.head, 0)
+	atomic.StoreUint32(&d.tail, 0)
+	atomic.StoreUint32(&d.overflows, 0)
+	return d.irq(d.dataReady)
+}
+
+// StopStream stops streaming. Results already in the buffer can still be
+// read.
+func (d *Device) StopStream() error {
+	if d.irq == nil {
+		return errNoDRDY
+	}
+	err := d.irq(nil)
+	d.ring = nil
+	return err
+}
+
+// Buffered returns the number of results waiting in the ring buffer.
+func (d *Device) Buffered() int {
+	return int(atomic.LoadUint32(&d.head) - atomic.LoadUint32(&d.tail))
+}
+
+// Read moves the oldest results from the ring buffer to dst and returns how
+// many were moved.
+func (d *Device) Read(dst []Sample) int {
+	ring := d.ring
+	if ring == nil {
+		return 0
+	}
+	tail := atomic.LoadUint32(&d.tail)
+	head := atomic.LoadUint32(&d.head)
+	n := 0
+	for ; n < len(dst) && tail != head; n++ {
+		dst[n] = ring[tail%uint32(len(ring))]
+		tail++
+	}
+	atomic.StoreUint32(&d.tail, tail)
+	return n
+}
+
+// Overflows returns the number of results dropped because the ring buffer
+// was full.
+func (d *Device) Overflows() uint32 {
+	return atomic.LoadUint32(&d.overflows)
+}
+
+// dataReady is called from the DRDY interrupt.
+func (d *Device) dataReady() {
+	ring := d.ring
+	if len(ring) == 0 {
+		return
+	}
+	head := atomic.LoadUint32(&d.head)
+	if head-atomic.LoadUint32(&d.tail) >= uint32(len(ring)) {
+		// The result must still be read, or DRDY stays low.
+		var s Sample
+		d.readSample(&s)
+		atomic.AddUint32(&d.overflows, 1)
+		return
+	}
+	if d.readSample(&ring[head%uint32(len(ring))]) == nil {
+		atomic.StoreUint32(&d.head, head+1)
+	}
+}
+
+func (d *Device) readSample(dst *Sample) error {
+	if err := d.frame(cmdNull, 0); err != nil {
+		return err
+	}
+	for ch := range dst {
+		dst[ch] = int32(d.word(ch+1)<<8) >> 8
+	}
+	return nil
+}
+
+func (d *Device) readRegister(reg uint8) (uint16, error) {
+	if err := d.frame(cmdRReg|uint16(reg)<<7, 0); err != nil {
+		return 0, err
+	}
+	if err := d.frame(cmdNull, 0); err != nil {
+		return 0, err
+	}
+	return uint16(d.word(0) >> 8), nil
+}
+
+func (d *Device) writeRegister(reg uint8, value uint16) error {
+	cmd := cmdWReg | uint16(reg)<<7
+	if err := d.frame(cmd, value); err != nil {
+		return err
+	}
+	if err := d.frame(cmdNull, 0); err != nil {
+		return err
+	}
+	// The acknowledgement is the RREG command for the written registers.
+	if uint16(d.word(0)>>8) != cmd&^0x2000 {
+		return errNoCommand
+	}
+	return nil
+}
+
+// frame sends a command word, with an optional data word, and receives the
+// response of the previous command and the conversion results.
+func (d *Device) frame(cmd, data uint16) error {
+	d.tx[0] = byte(cmd >> 8)
+	d.tx[1] = byte(cmd)
+	d.tx[3] = byte(data >> 8)
+	d.tx[4] = byte(data)
+	d.cs(false)
+	err := d.bus.Tx(d.tx[:], d.rx[:])
+	d.cs(true)
+	d.tx[3], d.tx[4] = 0, 0
+	return err
+}
+
+// word returns the 24-bit word i of the last received frame.
+func (d *Device) word(i int) uint32 {
+	b := d.rx[i*3:]
+	return uint32(b[0])<<16 | uint32(b[1])<<8 | uint32(b[2])
+}
@@ -0,0 +1,49 @@
+package ads131m04
+
+import (
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+)
+
+// fakeSPI returns a canned frame with the given channel values.
+type fakeSPI struct {
+	values [Channels]int32
+}
+
+func (s *fakeSPI) Tx(w, r []byte) error {
+	for ch, v := range s.values {
+		r[3+ch*3] = byte(v >> 16)
+		r[4+ch*3] = byte(v >> 8)
+		r[5+ch*3] = byte(v)
+	}
+	return nil
+}
+
+func (s *fakeSPI) Transfer(b byte) (byte, error) {
+	return 0, nil
+}
+
+func TestStream(t *testing.T) {
+	c := qt.New(t)
+	bus := &fakeSPI{values: [Channels]int32{0x7FFFFF, -0x800000, 1, -1}}
+	d := &Device{bus: bus, cs: func(bool) {}}
+	d.gains = [Channels]Gain{Gain1, Gain1, Gain2, Gain1}
+
+	ring := make([]Sample, 2)
+	d.ring = ring
+	for i := 0; i < 3; i++ {
+		d.dataReady()
+	}
+	c.Assert(d.Buffered(), qt.Equals, 2)
+	c.Assert(d.Overflows(), qt.Equals, uint32(1))
+
+	var got [4]Sample
+	c.Assert(d.Read(got[:]), qt.Equals, 2)
+	c.Assert(got[0], qt.Equals, Sample{0x7FFFFF, -0x800000, 1, -1})
+	c.Assert(d.Buffered(), qt.Equals, 0)
+
+	c.Assert(d.Microvolts(0, got[0][0]), qt.Equals, int32(1199999))
+	c.Assert(d.Microvolts(1, got[0][1]), qt.Equals, int32(-1200000))
+	c.Assert(d.Microvolts(2, 0x400000), qt.Equals, int32(300000))
+}
@@ -0,0 +1,38 @@
+//go:build tinygo
+
+package ads131m04
+
+import (
+	"machine"
+
+	"tinygo.org/x/drivers"
+)
+
+// New creates a new ADS131M04 connection. The SPI bus must already be
+// configured in mode 1, at up to 25MHz.
+//
+// This function only creates the Device object and sets up the CS pin, it
+// does not touch the device.
+func New(bus drivers.SPI, cs machine.Pin) *Device {
+	cs.Configure(machine.PinConfig{Mode: machine.PinOutput})
+	cs.High()
+	return &Device{
+		bus: bus,
+		cs:  cs.Set,
+	}
+}
+
+// SetDataReadyPin sets the pin connected to DRDY, which goes low when a new
+// conversion result is available.
+func (d *Device) SetDataReadyPin(pin machine.Pin) {
+	pin.Configure(machine.PinConfig{Mode: machine.PinInput})
+	d.ready = pin.Get
+	d.irq = func(handler func()) error {
+		if handler == nil {
+			return pin.SetInterrupt(0, nil)
+		}
+		return pin.SetInterrupt(machine.PinFalling, func(machine.Pin) {
+			handler()
+		})
+	}
+}
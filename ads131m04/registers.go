@@ -0,0 +1,34 @@
+package ads131m04
+
+// Commands, in the upper 16 bits of the command word.
+const (
+	cmdNull    = 0x0000
+	cmdReset   = 0x0011
+	cmdStandby = 0x0022
+	cmdWakeup  = 0x0033
+	cmdLock    = 0x0555
+	cmdUnlock  = 0x0655
+	cmdRReg    = 0xA000 // | address<<7 | (count-1)
+	cmdWReg    = 0x6000 // | address<<7 | (count-1)
+)
+
+// Registers
+const (
+	regID     = 0x00
+	regSTATUS = 0x01
+	regMODE   = 0x02
+	regCLOCK  = 0x03
+	regGAIN1  = 0x04
+	regCFG    = 0x06
+)
+
+const (
+	// resetResponse is the response to a reset of the 4 channel version.
+	resetResponse = 0xFF24
+	// idChannels is the upper byte of the ID register: 0x2 and the number
+	// of channels.
+	idChannels = 0x24
+
+	clockChannelsEnabled = 0x0F00
+	clockPowerHighRes    = 0x0002
+)
@@ -0,0 +1,130 @@
+// Package ant decodes the broadcast data pages of ANT+ fitness sensors:
+// heart rate straps, and bike speed and cadence sensors.
+//
+// Sensors broadcast a data page of 8 bytes a few times per second. The
+// package does not receive them: feed the pages received by a radio to the
+// Update method of the profile.
+package ant // import "tinygo.org/x/drivers/ant"
+
+import "errors"
+
+// Radio settings of the ANT+ profiles.
+const (
+	// Frequency is the RF channel of ANT+, 2457MHz.
+	Frequency = 2457000000
+
+	HeartRateDevice    = 120 // device type of heart rate monitors
+	SpeedCadenceDevice = 121 // device type of combined bike speed and cadence sensors
+	HeartRatePeriod    = 8070
+	SpeedCadencePeriod = 8086 // channel periods, in 1/32768 s
+)
+
+// PageSize is the length of a data page.
+const PageSize = 8
+
+var errPageSize = errors.New("ant: data page is not 8 bytes")
+
+// HeartRate is the state of a heart rate monitor.
+type HeartRate struct {
+	// BPM is the heart rate computed by the monitor, in beats per minute,
+	// or 0 if not valid.
+	BPM uint8
+
+	// Beats counts the heart beats, wrapping at 256.
+	Beats uint8
+
+	// RR is the last interval between two beats, in milliseconds, or 0 if
+	// not known yet.
+	RR uint16
+
+	beatTime uint16 // of the last beat, in 1/1024 s
+	valid    bool
+}
+
+// Update decodes a data page of the heart rate profile.
+func (h *HeartRate) Update(page []byte) error {
+	if len(page) != PageSize {
+		return errPageSize
+	}
+	beatTime := uint16(page[4]) | uint16(page[5])<<8
+	beats := page[6]
+	switch {
+	case page[0]&0x7F == 4:
+		// The previous beat time is in the page.
+		prev := uint16(page[2]) | uint16(page[3])<<8
+		h.RR = rrInterval(beatTime - prev)
+	case h.valid && beats == h.Beats+1:
+		h.RR = rrInterval(beatTime - h.beatTime)
+	}
+	h.BPM = page[7]
+	h.Beats = beats
+	h.beatTime = beatTime
+	h.valid = true
+	return nil
+}
+
+// rrInterval converts an interval in 1/1024 s to milliseconds.
+func rrInterval(t uint16) uint16 {
+	return uint16(uint32(t) * 1000 / 1024)
+}
+
+// SpeedCadence is the state of a combined bike speed and cadence sensor.
+type SpeedCadence struct {
+	// Circumference of the wheel in millimeters, 2096 (a 700x23C tire) when
+	// 0.
+	Circumference uint16
+
+	cadence uint16 // rpm
+	speed   uint32 // mm/s
+	last    [PageSize]byte
+	valid   bool
+}
+
+// Update decodes a data page of the bike speed and cadence profile.
+func (s *SpeedCadence) Update(page []byte) error {
+	if len(page) != PageSize {
+		return errPageSize
+	}
+	if s.valid {
+		// The event times only change when a revolution was seen.
+		if dt := le16(page[0:]) - le16(s.last[0:]); dt != 0 {
+			revs := le16(page[2:]) - le16(s.last[2:])
+			s.cadence = uint16(uint32(revs) * 60 * 1024 / uint32(dt))
+		}
+		if dt := le16(page[4:]) - le16(s.last[4:]); dt != 0 {
+			circ := uint32(s.Circumference)
+			if circ == 0 {
+				circ = 2096
+			}
+			revs := le16(page[6:]) - le16(s.last[6:])
+			s.speed = uint32(revs) * circ * 1024 / uint32(dt)
+		}
+	}
+	copy(s.last[:], page)
+	s.valid = true
+	return nil
+}
+
+// Cadence returns the pedaling cadence in revolutions per minute.
+func (s *SpeedCadence) Cadence() uint16 {
+	return s.cadence
+}
+
+// Speed returns the speed in millimeters per second.
+func (s *SpeedCadence) Speed() uint32 {
+	return s.speed
+}
+
+// Distance returns the distance in millimeters covered by the wheel
+// revolutions counted by the sensor, which wrap at 65536.
+func (s *SpeedCadence) Distance() uint32 {
+	circ := uint32(s.Circumference)
+	if circ == 0 {
+		circ = 2096
+	}
+	return uint32(le16(s.last[6:])) * circ
+}
+
+func le16(b []byte) uint16 {
+	return uint16(b[0]) | uint16(b[1])<<8
+}
@@ -0,0 +1,44 @@
+package ant
+
+import (
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+)
+
+func TestHeartRate(t *testing.T) {
+	c := qt.New(t)
+
+	var h HeartRate
+	c.Assert(h.Update([]byte{0x00, 0xFF, 0xFF, 0xFF, 0x00, 0x04, 10, 72}), qt.IsNil)
+	c.Assert(h.BPM, qt.Equals, uint8(72))
+	c.Assert(h.RR, qt.Equals, uint16(0))
+
+	// The next beat, 850 ms later, in a page 0 with the toggle bit set.
+	c.Assert(h.Update([]byte{0x80, 0xFF, 0xFF, 0xFF, 0x66, 0x07, 11, 71}), qt.IsNil)
+	c.Assert(h.RR, qt.Equals, uint16(849))
+	c.Assert(h.Beats, qt.Equals, uint8(11))
+
+	// Page 4 holds the previous beat time, with the counters wrapping.
+	c.Assert(h.Update([]byte{0x04, 0x00, 0x00, 0xFF, 0x00, 0x03, 12, 70}), qt.IsNil)
+	c.Assert(h.RR, qt.Equals, uint16(1000))
+
+	c.Assert(h.Update([]byte{0x00}), qt.Equals, errPageSize)
+}
+
+func TestSpeedCadence(t *testing.T) {
+	c := qt.New(t)
+
+	s := SpeedCadence{Circumference: 2000}
+	s.Update([]byte{0x00, 0xF0, 0xFE, 0xFF, 0x00, 0xF0, 0xFF, 0xFF})
+	// One crank revolution in 2/3 s, and 5 wheel revolutions in 1 s, with
+	// the counters wrapping.
+	s.Update([]byte{0xAA, 0xF2, 0xFF, 0xFF, 0x00, 0xF4, 0x04, 0x00})
+	c.Assert(s.Cadence(), qt.Equals, uint16(90))
+	c.Assert(s.Speed(), qt.Equals, uint32(10000))
+	c.Assert(s.Distance(), qt.Equals, uint32(4*2000))
+
+	// No new revolution: the last values are kept.
+	s.Update([]byte{0xAA, 0xF2, 0xFF, 0xFF, 0x00, 0xF4, 0x04, 0x00})
+	c.Assert(s.Cadence(), qt.Equals, uint16(90))
+}
@@ -5,7 +5,6 @@ package apa102 // import "tinygo.org/x/drivers/apa102"
 
 import (
 	"image/color"
-	"machine"
 
 	"tinygo.org/x/drivers"
 )
@@ -35,12 +34,6 @@ func New(b drivers.SPI) *Device {
 	return &Device{bus: b, Order: BGR}
 }
 
-// NewSoftwareSPI returns a new APA102 driver that will use a software based
-// implementation of the SPI protocol.
-func NewSoftwareSPI(sckPin, sdoPin machine.Pin, delay uint32) *Device {
-	return New(&bbSPI{SCK: sckPin, SDO: sdoPin, Delay: delay})
-}
-
 // WriteColors writes the given RGBA color slice out using the APA102 protocol.
 // The A value (Alpha channel) is used for brightness, set to 0xff (255) for maximum.
 func (d *Device) WriteColors(cs []color.RGBA) (n int, err error) {
@@ -48,24 +41,7 @@ func (d *Device) WriteColors(cs []color.RGBA) (n int, err error) {
 
 	// write data
 	for _, c := range cs {
-		// brightness is scaled to 5 bit value
-		d.buf[0] = 0xe0 | (c.A >> 3)
-
-		// set the colors
-		switch d.Order {
-		case BRG:
-			d.buf[1] = c.B
-			d.buf[2] = c.R
-			d.buf[3] = c.G
-		case GRB:
-			d.buf[1] = c.G
-			d.buf[2] = c.R
-			d.buf[3] = c.B
-		case BGR:
-			d.buf[1] = c.B
-			d.buf[2] = c.G
-			d.buf[3] = c.R
-		}
+		d.encode(d.buf[:], c)
 		d.bus.Tx(d.buf[:], nil)
 	}
 
@@ -83,6 +59,29 @@ func (d *Device) Write(buf []byte) (n int, err error) {
 	return len(buf), nil
 }
 
+// encode stores the 4 byte LED frame for the color c into buf, using the
+// color order of the device.
+func (d *Device) encode(buf []byte, c color.RGBA) {
+	// brightness is scaled to 5 bit value
+	buf[0] = 0xe0 | (c.A >> 3)
+
+	// set the colors
+	switch d.Order {
+	case BRG:
+		buf[1] = c.B
+		buf[2] = c.R
+		buf[3] = c.G
+	case GRB:
+		buf[1] = c.G
+		buf[2] = c.R
+		buf[3] = c.B
+	case BGR:
+		buf[1] = c.B
+		buf[2] = c.G
+		buf[3] = c.R
+	}
+}
+
 // startFrame sends the start bytes for a strand of LEDs.
 func (d *Device) startFrame() {
 	d.bus.Tx(startFrame, nil)
@@ -92,7 +91,14 @@ func (d *Device) startFrame() {
 // long strands of LEDs receive the necessary termination for updates.
 // See https://cpldcpu.wordpress.com/2014/11/30/understanding-the-apa102-superled/
 func (d *Device) endFrame(count int) {
-	for i := 0; i < count/16; i++ {
+	for i := 0; i < endFrameLen(count); i++ {
 		d.bus.Transfer(0xff)
 	}
 }
+
+// endFrameLen returns the number of end frame bytes needed for a strand of
+// count LEDs. Every LED delays the data by half a clock cycle, so at least
+// count/2 extra clock edges are needed, rounded up to whole bytes.
+func endFrameLen(count int) int {
+	return (count + 15) / 16
+}
@@ -0,0 +1,123 @@
+package apa102
+
+import (
+	"errors"
+	"image/color"
+	"time"
+)
+
+var errFrameIndex = errors.New("apa102: frame index out of range")
+
+// POV drives an APA102 strand as a persistence-of-vision display. All frames
+// are preformatted into complete SPI transfers (start frame, LED frames and
+// end frame) ahead of time, so that showing a frame is a single Tx call that a
+// DMA capable SPI bus can send without any CPU work per LED.
+//
+// Frames are paced on absolute deadlines: the time a frame is due is derived
+// from the previous deadline and not from the time the previous frame was
+// actually sent, so jitter in the caller does not accumulate as drift.
+type POV struct {
+	dev     *Device
+	leds    int
+	frames  [][]byte
+	period  time.Duration
+	due     time.Time
+	current int
+}
+
+// NewPOV returns a POV helper for a strand of leds LEDs that can hold frames
+// preformatted frames. The frame buffers are allocated once here.
+func (d *Device) NewPOV(leds, frames int) *POV {
+	size := len(startFrame) + 4*leds + endFrameLen(leds)
+	p := &POV{
+		dev:    d,
+		leds:   leds,
+		frames: make([][]byte, frames),
+	}
+	for i := range p.frames {
+		buf := make([]byte, size)
+		// LED frames with zero brightness, end frame all ones.
+		for j := len(startFrame); j < len(startFrame)+4*leds; j += 4 {
+			buf[j] = 0xe0
+		}
+		for j := len(startFrame) + 4*leds; j < size; j++ {
+			buf[j] = 0xff
+		}
+		p.frames[i] = buf
+	}
+	return p
+}
+
+// Frames returns the number of frames held by the POV helper.
+func (p *POV) Frames() int {
+	return len(p.frames)
+}
+
+// SetFrame preformats the colors cs into frame index. Colors beyond the
+// length of the strand are ignored, missing colors are left unchanged. The A
+// value (Alpha channel) is used for brightness, just like in WriteColors.
+func (p *POV) SetFrame(index int, cs []color.RGBA) error {
+	if index < 0 || index >= len(p.frames) {
+		return errFrameIndex
+	}
+	if len(cs) > p.leds {
+		cs = cs[:p.leds]
+	}
+	buf := p.frames[index][len(startFrame):]
+	for i, c := range cs {
+		p.dev.encode(buf[i*4:i*4+4], c)
+	}
+	return nil
+}
+
+// SetPeriod sets the time between two consecutive frames and restarts the
+// pacing from the current time.
+func (p *POV) SetPeriod(period time.Duration) {
+	p.period = period
+	p.due = time.Time{}
+}
+
+// Show sends frame index to the strand immediately.
+func (p *POV) Show(index int) error {
+	if index < 0 || index >= len(p.frames) {
+		return errFrameIndex
+	}
+	p.current = index
+	return p.dev.bus.Tx(p.frames[index], nil)
+}
+
+// Tick sends the next frame if it is due and reports whether a frame was
+// sent. It is meant to be called from a timer callback (or a tight loop)
+// running faster than the frame period. When the caller falls behind by more
+// than one period, the missed frames are skipped instead of being sent in a
+// burst.
+func (p *POV) Tick() (bool, error) {
+	now := time.Now()
+	if p.due.IsZero() {
+		p.due = now
+	}
+	if now.Before(p.due) {
+		return false, nil
+	}
+	p.due = p.due.Add(p.period)
+	if p.period > 0 && now.Sub(p.due) >= p.period {
+		// Resynchronize instead of trying to catch up.
+		p.due = now.Add(p.period)
+	}
+	next := p.current + 1
+	if next >= len(p.frames) {
+		next = 0
+	}
+	return true, p.Show(next)
+}
+
+// Run shows all frames in a loop with the configured period until stop
+// returns true. stop may be nil to run forever.
+func (p *POV) Run(stop func() bool) error {
+	for stop == nil || !stop() {
+		if _, err := p.Tick(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
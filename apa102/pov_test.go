@@ -0,0 +1,54 @@
+package apa102
+
+import (
+	"image/color"
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+)
+
+// fakeSPI records the transfers sent to the strand.
+type fakeSPI struct {
+	tx [][]byte
+}
+
+func (s *fakeSPI) Tx(w, r []byte) error {
+	s.tx = append(s.tx, append([]byte(nil), w...))
+	return nil
+}
+
+func (s *fakeSPI) Transfer(b byte) (byte, error) {
+	s.tx = append(s.tx, []byte{b})
+	return 0, nil
+}
+
+func TestPOV(t *testing.T) {
+	c := qt.New(t)
+	bus := &fakeSPI{}
+	p := New(bus).NewPOV(3, 2)
+	c.Assert(p.Frames(), qt.Equals, 2)
+
+	// Extra colors are ignored, missing ones left dark.
+	c.Assert(p.SetFrame(1, []color.RGBA{{R: 0xFF, A: 0xFF}, {G: 0x80, B: 0x10, A: 0x40}}), qt.IsNil)
+	c.Assert(p.SetFrame(0, make([]color.RGBA, 5)), qt.IsNil)
+	c.Assert(p.SetFrame(2, nil), qt.Equals, errFrameIndex)
+
+	c.Assert(p.Show(1), qt.IsNil)
+	c.Assert(bus.tx, qt.DeepEquals, [][]byte{{
+		0x00, 0x00, 0x00, 0x00,
+		0xFF, 0x00, 0x00, 0xFF,
+		0xE8, 0x10, 0x80, 0x00,
+		0xE0, 0x00, 0x00, 0x00,
+		0xFF,
+	}})
+
+	// Without a period, every tick shows the next frame, wrapping around.
+	for _, want := range []int{0, 1} {
+		bus.tx = nil
+		sent, err := p.Tick()
+		c.Assert(err, qt.IsNil)
+		c.Assert(sent, qt.IsTrue)
+		c.Assert(bus.tx, qt.DeepEquals, [][]byte{p.frames[want]})
+	}
+	c.Assert(p.Show(-1), qt.Equals, errFrameIndex)
+}
@@ -1,7 +1,15 @@
+//go:build tinygo
+
 package apa102
 
 import "machine"
 
+// NewSoftwareSPI returns a new APA102 driver that will use a software based
+// implementation of the SPI protocol.
+func NewSoftwareSPI(sckPin, sdoPin machine.Pin, delay uint32) *Device {
+	return New(&bbSPI{SCK: sckPin, SDO: sdoPin, Delay: delay})
+}
+
 // bbSPI is a dumb bit-bang implementation of SPI protocol that is hardcoded
 // to mode 0 and ignores trying to receive data. Just enough for the APA102.
 // Note: making this unexported for now because it is probable not suitable
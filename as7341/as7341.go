@@ -0,0 +1,251 @@
+// Package as7341 implements a driver for the AS7341 11-channel spectral
+// color sensor.
+//
+// Datasheet: https://ams.com/documents/20143/36005/AS7341_DS000504_3-00.pdf
+//
+// The sensor has more photodiodes than ADCs, so they are connected to the
+// ADCs through a multiplexer (SMUX). Reading all channels takes two
+// measurements: one for F1-F4 and one for F5-F8, both including the clear and
+// near infrared channels.
+package as7341 // import "tinygo.org/x/drivers/as7341"
+
+import (
+	"errors"
+	"time"
+
+	"tinygo.org/x/drivers"
+)
+
+var (
+	errTimeout = errors.New("as7341: timeout")
+)
+
+// Channels holds the raw counts of all spectral channels.
+type Channels struct {
+	F1    uint16 // 415nm violet
+	F2    uint16 // 445nm indigo
+	F3    uint16 // 480nm blue
+	F4    uint16 // 515nm cyan
+	F5    uint16 // 555nm green
+	F6    uint16 // 590nm yellow
+	F7    uint16 // 630nm orange
+	F8    uint16 // 680nm red
+	Clear uint16
+	NIR   uint16 // near infrared
+}
+
+// Config contains the integration time and gain.
+type Config struct {
+	// Integration time is (ATIME+1) * (ASTEP+1) * 2.78µs. The default of
+	// ATIME=29, ASTEP=599 gives 50ms.
+	ATIME uint8
+	ASTEP uint16
+
+	Gain Gain
+}
+
+// Device wraps an I2C connection to an AS7341 device.
+type Device struct {
+	bus     drivers.I2C
+	Address uint8
+	atime   uint8
+	astep   uint16
+	buf     [21]byte
+}
+
+// New creates a new AS7341 connection. The I2C bus must already be
+// configured.
+//
+// This function only creates the Device object, it does not touch the device.
+func New(bus drivers.I2C) Device {
+	return Device{bus: bus, Address: Address}
+}
+
+// Connected returns whether an AS7341 has been found.
+func (d *Device) Connected() bool {
+	id, err := d.read8(ID)
+	return err == nil && id&0xFC == 0x24
+}
+
+// Configure powers up the sensor and sets the integration time and gain.
+func (d *Device) Configure(cfg Config) error {
+	if cfg.ATIME == 0 && cfg.ASTEP == 0 {
+		cfg.ATIME = 29
+		cfg.ASTEP = 599
+	}
+	if err := d.write8(ENABLE, ENABLE_PON); err != nil {
+		return err
+	}
+	if err := d.SetIntegrationTime(cfg.ATIME, cfg.ASTEP); err != nil {
+		return err
+	}
+	return d.SetGain(cfg.Gain)
+}
+
+// SetIntegrationTime sets the integration time to (atime+1) * (astep+1) *
+// 2.78µs.
+func (d *Device) SetIntegrationTime(atime uint8, astep uint16) error {
+	d.atime = atime
+	d.astep = astep
+	if err := d.write8(ATIME, atime); err != nil {
+		return err
+	}
+	w := [...]byte{ASTEP_L, byte(astep), byte(astep >> 8)}
+	return d.bus.Tx(uint16(d.Address), w[:], nil)
+}
+
+// IntegrationTime returns the current integration time.
+func (d *Device) IntegrationTime() time.Duration {
+	return time.Duration(int64(d.atime)+1) * time.Duration(int64(d.astep)+1) * 2780 * time.Nanosecond
+}
+
+// SetGain sets the analog gain of the spectral channels.
+func (d *Device) SetGain(gain Gain) error {
+	return d.write8(CFG1, uint8(gain)&0x1F)
+}
+
+// SetLED turns the LED driven by the LDR pin on or off. The current is
+// between 4mA and 258mA in steps of 2mA.
+func (d *Device) SetLED(on bool, milliAmps uint16) error {
+	if err := d.setBank(true); err != nil {
+		return err
+	}
+	defer d.setBank(false)
+
+	config, err := d.read8(CONFIG)
+	if err != nil {
+		return err
+	}
+	if on {
+		config |= CONFIG_LED_SEL
+	} else {
+		config &^= CONFIG_LED_SEL
+	}
+	if err := d.write8(CONFIG, config); err != nil {
+		return err
+	}
+
+	if milliAmps < 4 {
+		milliAmps = 4
+	}
+	if milliAmps > 258 {
+		milliAmps = 258
+	}
+	led := uint8((milliAmps - 4) / 2)
+	if on {
+		led |= LED_ACT
+	}
+	return d.write8(LED, led)
+}
+
+// ReadChannels performs two measurements to read all spectral channels. It
+// blocks for about twice the integration time.
+func (d *Device) ReadChannels() (ch Channels, err error) {
+	var data [6]uint16
+	if err = d.measure(&smuxF1F4, &data); err != nil {
+		return
+	}
+	ch.F1, ch.F2, ch.F3, ch.F4 = data[0], data[1], data[2], data[3]
+	if err = d.measure(&smuxF5F8, &data); err != nil {
+		return
+	}
+	ch.F5, ch.F6, ch.F7, ch.F8 = data[0], data[1], data[2], data[3]
+	ch.Clear, ch.NIR = data[4], data[5]
+	return
+}
+
+// measure configures the SMUX, runs a single spectral measurement and reads
+// the six ADC channels.
+func (d *Device) measure(smux *[20]byte, data *[6]uint16) error {
+	// Stop measurements while reconfiguring.
+	if err := d.write8(ENABLE, ENABLE_PON); err != nil {
+		return err
+	}
+	if err := d.write8(CFG6, CFG6_SMUX_CMD_WR); err != nil {
+		return err
+	}
+	d.buf[0] = 0x00
+	copy(d.buf[1:], smux[:])
+	if err := d.bus.Tx(uint16(d.Address), d.buf[:21], nil); err != nil {
+		return err
+	}
+	if err := d.write8(ENABLE, ENABLE_PON|ENABLE_SMUXEN); err != nil {
+		return err
+	}
+	if err := d.waitClear(ENABLE, ENABLE_SMUXEN); err != nil {
+		return err
+	}
+
+	if err := d.write8(ENABLE, ENABLE_PON|ENABLE_SP_EN); err != nil {
+		return err
+	}
+	timeout := time.Now().Add(2*d.IntegrationTime() + 100*time.Millisecond)
+	for {
+		status, err := d.read8(STATUS2)
+		if err != nil {
+			return err
+		}
+		if status&STATUS2_AVALID != 0 {
+			break
+		}
+		if time.Now().After(timeout) {
+			return errTimeout
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	// Reading ASTATUS latches the channel data.
+	d.buf[0] = ASTATUS
+	if err := d.bus.Tx(uint16(d.Address), d.buf[:1], d.buf[:13]); err != nil {
+		return err
+	}
+	for i := range data {
+		data[i] = uint16(d.buf[1+2*i]) | uint16(d.buf[2+2*i])<<8
+	}
+	return nil
+}
+
+// waitClear waits until the bits in mask are cleared in the register.
+func (d *Device) waitClear(reg, mask uint8) error {
+	timeout := time.Now().Add(100 * time.Millisecond)
+	for {
+		val, err := d.read8(reg)
+		if err != nil {
+			return err
+		}
+		if val&mask == 0 {
+			return nil
+		}
+		if time.Now().After(timeout) {
+			return errTimeout
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+// setBank selects access to registers 0x60-0x74 (true) or 0x80 and above
+// (false).
+func (d *Device) setBank(low bool) error {
+	cfg0, err := d.read8(CFG0)
+	if err != nil {
+		return err
+	}
+	if low {
+		cfg0 |= CFG0_REG_BANK
+	} else {
+		cfg0 &^= CFG0_REG_BANK
+	}
+	return d.write8(CFG0, cfg0)
+}
+
+func (d *Device) read8(reg uint8) (uint8, error) {
+	d.buf[0] = reg
+	err := d.bus.Tx(uint16(d.Address), d.buf[:1], d.buf[1:2])
+	return d.buf[1], err
+}
+
+func (d *Device) write8(reg, value uint8) error {
+	d.buf[0] = reg
+	d.buf[1] = value
+	return d.bus.Tx(uint16(d.Address), d.buf[:2], nil)
+}
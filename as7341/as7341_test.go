@@ -0,0 +1,66 @@
+package as7341
+
+import (
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+	"tinygo.org/x/drivers/tester"
+)
+
+// fakeSensor is an AS7341 which runs the SMUX commands at once, and measures
+// the channels given for each SMUX configuration.
+type fakeSensor struct {
+	*tester.I2CDevice8
+	f1f4, f5f8 [6]uint16
+	smux       [20]byte
+}
+
+func (f *fakeSensor) Tx(w, r []byte) error {
+	switch {
+	case len(w) == 21 && w[0] == 0x00:
+		copy(f.smux[:], w[1:])
+		return nil
+	case len(w) == 2 && w[0] == ENABLE && w[1]&ENABLE_SMUXEN != 0:
+		// The SMUX command completes at once.
+		f.Registers[ENABLE] = w[1] &^ ENABLE_SMUXEN
+		return nil
+	case len(w) == 2 && w[0] == ENABLE && w[1]&ENABLE_SP_EN != 0:
+		data := f.f5f8
+		if f.smux == smuxF1F4 {
+			data = f.f1f4
+		}
+		for i, v := range data {
+			f.Registers[CH0_DATA_L+2*i] = byte(v)
+			f.Registers[CH0_DATA_L+2*i+1] = byte(v >> 8)
+		}
+		f.Registers[STATUS2] = STATUS2_AVALID
+	}
+	return f.I2CDevice8.Tx(w, r)
+}
+
+func TestReadChannels(t *testing.T) {
+	c := qt.New(t)
+	bus := tester.NewI2CBus(c)
+	fake := &fakeSensor{
+		I2CDevice8: tester.NewI2CDevice8(c, Address),
+		f1f4:       [6]uint16{100, 200, 300, 400, 0xFFFF, 7},
+		f5f8:       [6]uint16{500, 600, 700, 0x1234, 1000, 50},
+	}
+	fake.Registers[ID] = 0x24
+	bus.AddDevice(fake)
+
+	dev := New(bus)
+	c.Assert(dev.Connected(), qt.IsTrue)
+	c.Assert(dev.Configure(Config{}), qt.IsNil)
+	c.Assert(fake.Registers[ATIME], qt.Equals, uint8(29))
+	c.Assert(fake.Registers[ASTEP_L:ASTEP_L+2], qt.DeepEquals, []byte{0x57, 0x02})
+	c.Assert(dev.IntegrationTime().Microseconds(), qt.Equals, int64(50040))
+
+	ch, err := dev.ReadChannels()
+	c.Assert(err, qt.IsNil)
+	c.Assert(ch, qt.Equals, Channels{
+		F1: 100, F2: 200, F3: 300, F4: 400,
+		F5: 500, F6: 600, F7: 700, F8: 0x1234,
+		Clear: 1000, NIR: 50,
+	})
+}
@@ -0,0 +1,68 @@
+package as7341
+
+// The I2C address which this device listens to.
+const Address = 0x39
+
+// Registers. Registers below 0x80 can only be accessed with REG_BANK set in
+// CFG0.
+const (
+	CONFIG     = 0x70
+	LED        = 0x74
+	ENABLE     = 0x80
+	ATIME      = 0x81
+	WTIME      = 0x83
+	ID         = 0x92
+	ASTATUS    = 0x94
+	CH0_DATA_L = 0x95
+	STATUS2    = 0xA3
+	CFG0       = 0xA9
+	CFG1       = 0xAA
+	CFG6       = 0xAF
+	FD_TIME    = 0xD8
+	FD_STATUS  = 0xDB
+	ASTEP_L    = 0xCA
+)
+
+// Bits in the ENABLE register.
+const (
+	ENABLE_PON    = 0x01 // power on
+	ENABLE_SP_EN  = 0x02 // spectral measurement enable
+	ENABLE_WEN    = 0x08 // wait enable
+	ENABLE_SMUXEN = 0x10 // start SMUX command
+	ENABLE_FDEN   = 0x40 // flicker detection enable
+)
+
+const (
+	CFG0_REG_BANK    = 0x10
+	CONFIG_LED_SEL   = 0x08
+	STATUS2_AVALID   = 0x40
+	CFG6_SMUX_CMD_WR = 0x10
+	LED_ACT          = 0x80
+)
+
+// Gain of the spectral channels.
+type Gain uint8
+
+const (
+	GAIN_0_5X Gain = iota
+	GAIN_1X
+	GAIN_2X
+	GAIN_4X
+	GAIN_8X
+	GAIN_16X
+	GAIN_32X
+	GAIN_64X
+	GAIN_128X
+	GAIN_256X
+	GAIN_512X
+)
+
+// SMUX configurations connecting the photodiodes to the six ADCs. Each one
+// is written to RAM registers 0x00-0x13.
+var (
+	// F1, F2, F3, F4, Clear and NIR on ADC0-ADC5.
+	smuxF1F4 = [20]byte{0x30, 0x01, 0x00, 0x00, 0x00, 0x42, 0x00, 0x00, 0x50, 0x00, 0x00, 0x00, 0x20, 0x04, 0x00, 0x30, 0x01, 0x50, 0x00, 0x06}
+
+	// F5, F6, F7, F8, Clear and NIR on ADC0-ADC5.
+	smuxF5F8 = [20]byte{0x00, 0x00, 0x00, 0x40, 0x02, 0x00, 0x10, 0x03, 0x50, 0x10, 0x03, 0x00, 0x00, 0x00, 0x24, 0x00, 0x00, 0x50, 0x00, 0x06}
+)
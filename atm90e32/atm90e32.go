@@ -0,0 +1,231 @@
+// Package atm90e32 implements a driver for the ATM90E32AS poly-phase energy
+// metering chip over SPI, as used on three phase energy monitors.
+//
+// The chip measures up to three phases, which are numbered 0 (A) to 2 (C).
+//
+// Datasheet: https://ww1.microchip.com/downloads/en/DeviceDoc/Atmel-46003-SE-M90E32AS-Datasheet.pdf
+package atm90e32 // import "tinygo.org/x/drivers/atm90e32"
+
+import (
+	"errors"
+	"time"
+
+	"tinygo.org/x/drivers"
+)
+
+// Phases is the number of phases.
+const Phases = 3
+
+var errNotFound = errors.New("atm90e32: device not found")
+
+// Config holds the line frequency and the calibration of the chip.
+type Config struct {
+	// Frequency60Hz selects a 60Hz line frequency instead of 50Hz.
+	Frequency60Hz bool
+
+	// PGAGain is the MMode1 register, with the gain of the current
+	// channels. It defaults to 0, a gain of 1 on all channels.
+	PGAGain uint16
+
+	// VoltageGain and CurrentGain are the Ugain and Igain calibration
+	// registers of each phase. Zero keeps the power on default.
+	VoltageGain [Phases]uint16
+	CurrentGain [Phases]uint16
+
+	// MeterConstant is the number of energy pulses per kWh, as set by the
+	// PLconst registers. It defaults to 3200, the power on default.
+	MeterConstant uint32
+}
+
+// chipSelect is the CS pin of the chip, a machine.Pin.
+type chipSelect interface {
+	High()
+	Low()
+}
+
+// Device wraps an SPI connection to an ATM90E32AS.
+type Device struct {
+	bus drivers.SPI
+	cs  chipSelect
+	cfg Config
+	tx  [4]byte
+	rx  [4]byte
+
+	voltage     [Phases]int32
+	current     [Phases]int32
+	power       [Phases]int32
+	powerFactor [Phases]int32
+	energy      [Phases]uint64 // in 0.01 pulses
+	frequency   int32
+	temperature int32
+}
+
+// Configure resets the chip and writes the configuration and calibration
+// registers.
+func (d *Device) Configure(cfg Config) error {
+	if cfg.MeterConstant == 0 {
+		cfg.MeterConstant = 3200
+	}
+	d.cfg = cfg
+
+	if err := d.writeRegister(regSoftReset, softReset); err != nil {
+		return err
+	}
+	time.Sleep(10 * time.Millisecond)
+	if err := d.writeRegister(regCfgRegAccEn, cfgAccess); err != nil {
+		return err
+	}
+
+	mmode0 := uint16(mmode0Default)
+	if cfg.Frequency60Hz {
+		mmode0 |= mmode0Freq60
+	}
+	regs := []uint16{
+		regMeterEn, 0x0001,
+		regMMode0, mmode0,
+		regMMode1, cfg.PGAGain,
+	}
+	for i := 0; i < len(regs); i += 2 {
+		if err := d.writeRegister(uint8(regs[i]), regs[i+1]); err != nil {
+			return err
+		}
+	}
+	if v, err := d.readRegister(regMMode0); err != nil {
+		return err
+	} else if v != mmode0 {
+		return errNotFound
+	}
+	for ph := 0; ph < Phases; ph++ {
+		// The calibration registers of the phases are 4 apart.
+		if g := cfg.VoltageGain[ph]; g != 0 {
+			if err := d.writeRegister(regUgainA+uint8(ph)*4, g); err != nil {
+				return err
+			}
+		}
+		if g := cfg.CurrentGain[ph]; g != 0 {
+			if err := d.writeRegister(regIgainA+uint8(ph)*4, g); err != nil {
+				return err
+			}
+		}
+	}
+	return d.writeRegister(regCfgRegAccEn, cfgAccessDone)
+}
+
+// Update reads the measurements of all phases and accumulates their energy.
+func (d *Device) Update(which drivers.Measurement) error {
+	for ph := uint8(0); ph < Phases; ph++ {
+		u, err := d.readRegister(regUrmsA + ph)
+		if err != nil {
+			return err
+		}
+		ulsb, err := d.readRegister(regUrmsALSB + ph)
+		if err != nil {
+			return err
+		}
+		// 0.01V with 8 more bits in the LSB register.
+		d.voltage[ph] = int32(u)*10 + int32(ulsb>>8)*10/256
+
+		i, err := d.readRegister(regIrmsA + ph)
+		if err != nil {
+			return err
+		}
+		d.current[ph] = int32(i)
+
+		p, err := d.readRegister(regPmeanA + ph)
+		if err != nil {
+			return err
+		}
+		plsb, err := d.readRegister(regPmeanALSB + ph)
+		if err != nil {
+			return err
+		}
+		// 1W with 8 more bits in the LSB register.
+		d.power[ph] = (int32(int16(p))<<8 | int32(plsb>>8)) * 1000 / 256
+
+		pf, err := d.readRegister(regPFmeanA + ph)
+		if err != nil {
+			return err
+		}
+		d.powerFactor[ph] = int32(int16(pf))
+
+		e, err := d.readRegister(regAPenergyA + ph)
+		if err != nil {
+			return err
+		}
+		d.energy[ph] += uint64(e)
+	}
+
+	f, err := d.readRegister(regFreq)
+	if err != nil {
+		return err
+	}
+	d.frequency = int32(f) * 10
+	t, err := d.readRegister(regTemp)
+	if err != nil {
+		return err
+	}
+	d.temperature = int32(int16(t)) * 1000
+	return nil
+}
+
+// Voltage returns the RMS voltage of a phase in mV.
+func (d *Device) Voltage(phase int) int32 {
+	return d.voltage[phase]
+}
+
+// Current returns the RMS current of a phase in mA.
+func (d *Device) Current(phase int) int32 {
+	return d.current[phase]
+}
+
+// ActivePower returns the active power of a phase in mW.
+func (d *Device) ActivePower(phase int) int32 {
+	return d.power[phase]
+}
+
+// PowerFactor returns the power factor of a phase in thousandths.
+func (d *Device) PowerFactor(phase int) int32 {
+	return d.powerFactor[phase]
+}
+
+// Energy returns the forward active energy of a phase counted since
+// Configure in mWh. Update must be called often enough for the 16-bit
+// energy registers of the chip not to overflow.
+func (d *Device) Energy(phase int) int64 {
+	// 0.01 pulse per count, MeterConstant pulses per kWh.
+	return int64(d.energy[phase] * 10000 / uint64(d.cfg.MeterConstant))
+}
+
+// ResetEnergy sets the energy counters back to zero.
+func (d *Device) ResetEnergy() {
+	d.energy = [Phases]uint64{}
+}
+
+// Frequency returns the line frequency in mHz.
+func (d *Device) Frequency() int32 {
+	return d.frequency
+}
+
+// Temperature returns the temperature of the chip in m°C.
+func (d *Device) Temperature() int32 {
+	return d.temperature
+}
+
+func (d *Device) readRegister(reg uint8) (uint16, error) {
+	addr := regRead | uint16(reg)
+	d.tx = [4]byte{byte(addr >> 8), byte(addr), 0, 0}
+	d.cs.Low()
+	// At 200kHz the delay the chip needs before the data is met without
+	// waiting.
+	err := d.bus.Tx(d.tx[:], d.rx[:])
+	d.cs.High()
+	return uint16(d.rx[2])<<8 | uint16(d.rx[3]), err
+}
+
+func (d *Device) writeRegister(reg uint8, value uint16) error {
+	d.tx = [4]byte{0, reg, byte(value >> 8), byte(value)}
+	d.cs.Low()
+	err := d.bus.Tx(d.tx[:], nil)
+	d.cs.High()
+	return err
+}
@@ -0,0 +1,107 @@
+package atm90e32
+
+import (
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+)
+
+// fakeChip is an ATM90E32AS on the SPI bus, with its registers.
+type fakeChip struct {
+	c        *qt.C
+	regs     map[uint8]uint16
+	readOnly bool // ignores writes, as when no chip answers
+	selected bool
+}
+
+func (f *fakeChip) High() { f.selected = false }
+func (f *fakeChip) Low()  { f.selected = true }
+
+func (f *fakeChip) Transfer(b byte) (byte, error) {
+	f.c.Fatal("unexpected Transfer")
+	return 0, nil
+}
+
+func (f *fakeChip) Tx(w, r []byte) error {
+	f.c.Assert(f.selected, qt.IsTrue)
+	f.c.Assert(w, qt.HasLen, 4)
+	reg := w[1]
+	if w[0]&0x80 != 0 {
+		v := f.regs[reg]
+		r[2], r[3] = byte(v>>8), byte(v)
+		return nil
+	}
+	if !f.readOnly {
+		f.regs[reg] = uint16(w[2])<<8 | uint16(w[3])
+	}
+	return nil
+}
+
+func newDevice(c *qt.C) (*Device, *fakeChip) {
+	f := &fakeChip{c: c, regs: map[uint8]uint16{}}
+	return &Device{bus: f, cs: f}, f
+}
+
+func TestConfigure(t *testing.T) {
+	c := qt.New(t)
+	d, f := newDevice(c)
+	cfg := Config{Frequency60Hz: true, PGAGain: 0x0015}
+	cfg.VoltageGain[1] = 0x1234
+	cfg.CurrentGain[2] = 0x5678
+	c.Assert(d.Configure(cfg), qt.IsNil)
+	c.Assert(f.regs[regSoftReset], qt.Equals, uint16(softReset))
+	c.Assert(f.regs[regMMode0], qt.Equals, uint16(0x1087))
+	c.Assert(f.regs[regMMode1], qt.Equals, uint16(0x0015))
+	c.Assert(f.regs[regUgainA+4], qt.Equals, uint16(0x1234))
+	c.Assert(f.regs[regIgainA+8], qt.Equals, uint16(0x5678))
+	_, ok := f.regs[regUgainA]
+	c.Assert(ok, qt.IsFalse, qt.Commentf("default gain written"))
+	c.Assert(f.regs[regCfgRegAccEn], qt.Equals, uint16(cfgAccessDone))
+	c.Assert(f.selected, qt.IsFalse)
+
+	d, f = newDevice(c)
+	f.readOnly = true
+	c.Assert(d.Configure(Config{}), qt.Equals, errNotFound)
+}
+
+func TestUpdate(t *testing.T) {
+	c := qt.New(t)
+	d, f := newDevice(c)
+	c.Assert(d.Configure(Config{}), qt.IsNil)
+
+	// Phase A: 230.125V, 1.5A, 345.25W.
+	f.regs[regUrmsA] = 23012
+	f.regs[regUrmsALSB] = 0x8000
+	f.regs[regIrmsA] = 1500
+	f.regs[regPmeanA] = 345
+	f.regs[regPmeanALSB] = 0x4000
+	f.regs[regPFmeanA] = 980
+	f.regs[regAPenergyA] = 320
+	// Phase B sends power back to the grid: -199.5W.
+	f.regs[regUrmsA+1] = 23100
+	f.regs[regPmeanA+1] = 0xFF38 // -200
+	f.regs[regPmeanALSB+1] = 0x8000
+	f.regs[regPFmeanA+1] = 0xFC4A // -950
+	f.regs[regFreq] = 4998
+	f.regs[regTemp] = 0xFFFB // -5
+
+	c.Assert(d.Update(0), qt.IsNil)
+	c.Assert(d.Voltage(0), qt.Equals, int32(230125))
+	c.Assert(d.Current(0), qt.Equals, int32(1500))
+	c.Assert(d.ActivePower(0), qt.Equals, int32(345250))
+	c.Assert(d.PowerFactor(0), qt.Equals, int32(980))
+	c.Assert(d.Voltage(1), qt.Equals, int32(231000))
+	c.Assert(d.ActivePower(1), qt.Equals, int32(-199500))
+	c.Assert(d.PowerFactor(1), qt.Equals, int32(-950))
+	c.Assert(d.Voltage(2), qt.Equals, int32(0))
+	c.Assert(d.Frequency(), qt.Equals, int32(49980))
+	c.Assert(d.Temperature(), qt.Equals, int32(-5000))
+
+	// 320 hundredths of a pulse at 3200 pulses per kWh are 1Wh, and
+	// accumulate.
+	c.Assert(d.Energy(0), qt.Equals, int64(1000))
+	c.Assert(d.Update(0), qt.IsNil)
+	c.Assert(d.Energy(0), qt.Equals, int64(2000))
+	d.ResetEnergy()
+	c.Assert(d.Energy(0), qt.Equals, int64(0))
+}
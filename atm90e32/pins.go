@@ -0,0 +1,23 @@
+//go:build tinygo
+
+package atm90e32
+
+import (
+	"machine"
+
+	"tinygo.org/x/drivers"
+)
+
+// New creates a new ATM90E32AS connection. The SPI bus must already be
+// configured in mode 3, at up to 200kHz.
+//
+// This function only creates the Device object and sets up the CS pin, it
+// does not touch the device.
+func New(bus drivers.SPI, cs machine.Pin) *Device {
+	cs.Configure(machine.PinConfig{Mode: machine.PinOutput})
+	cs.High()
+	return &Device{
+		bus: bus,
+		cs:  cs,
+	}
+}
@@ -0,0 +1,43 @@
+package atm90e32
+
+// Registers
+const (
+	regMeterEn     = 0x00
+	regPLconstH    = 0x31
+	regPLconstL    = 0x32
+	regMMode0      = 0x33
+	regMMode1      = 0x34
+	regUgainA      = 0x61
+	regIgainA      = 0x62
+	regSoftReset   = 0x70
+	regEMMState0   = 0x71
+	regEMMState1   = 0x72
+	regCfgRegAccEn = 0x7F
+
+	// Read-to-clear forward active energy of the phases.
+	regAPenergyA = 0x81
+
+	regPmeanA    = 0xB1
+	regPFmeanA   = 0xBD
+	regPmeanALSB = 0xC1
+	regUrmsA     = 0xD9
+	regIrmsA     = 0xDD
+	regUrmsALSB  = 0xE9
+	regIrmsALSB  = 0xED
+	regFreq      = 0xF8
+	regTemp      = 0xFC
+
+	regRead = 0x8000
+)
+
+// Values
+const (
+	softReset     = 0x789A
+	cfgAccess     = 0x55AA
+	cfgAccessDone = 0x0000
+
+	// MMode0: 3P4W, with the current of all phases in the sums, and 60Hz
+	// line frequency.
+	mmode0Default = 0x0087
+	mmode0Freq60  = 0x1000
+)
@@ -0,0 +1,240 @@
+// Package bl0940 implements a driver for the BL0940 single phase energy
+// metering chip over its UART interface, as found in smart plugs such as
+// the Sonoff S31 Lite and Tuya based plugs.
+//
+// Datasheet: https://www.belling.com.cn/media/file_object/bel_product/BL0940/datasheet/BL0940_V1.1_en.pdf
+package bl0940 // import "tinygo.org/x/drivers/bl0940"
+
+import (
+	"errors"
+	"time"
+
+	"tinygo.org/x/drivers"
+)
+
+// Commands and registers. The datasheet gives 0x58 and 0xA8 for the read and
+// write commands, but the chips answer to 0x50 and 0xA0.
+const (
+	cmdRead    = 0x50
+	cmdWrite   = 0xA0
+	regAll     = 0xAA // full measurement packet
+	regMode    = 0x18
+	regSoftRst = 0x19
+	regWrProt  = 0x1A
+
+	frameHead = 0x55
+)
+
+// FrameSize is the size of the full measurement packet.
+const FrameSize = 35
+
+var (
+	errTimeout  = errors.New("bl0940: timeout")
+	errChecksum = errors.New("bl0940: checksum error")
+)
+
+// Frame is the full measurement packet. All values are little endian.
+type Frame [FrameSize]byte
+
+func (f *Frame) reg(i int) uint32 {
+	return uint32(f[i]) | uint32(f[i+1])<<8 | uint32(f[i+2])<<16
+}
+
+// valid returns whether the head and the checksum of the frame are valid.
+// The checksum covers the read command too.
+func (f *Frame) valid() bool {
+	sum := byte(cmdRead)
+	for _, b := range f[:FrameSize-1] {
+		sum += b
+	}
+	return f[0] == frameHead && ^sum == f[FrameSize-1]
+}
+
+// Config holds the calibration of the measurement circuit, in raw counts per
+// unit. The defaults are those of the reference design, with a 1mΩ shunt
+// and a 390kΩx5 and 510Ω voltage divider.
+type Config struct {
+	// VoltageRef is the count per V of the RMS voltage. It defaults to
+	// 17476.
+	VoltageRef uint32
+	// CurrentRef is the count per A of the RMS current. It defaults to
+	// 266013.
+	CurrentRef uint32
+	// PowerRef is the count per kW of the active power. It defaults to
+	// 713105.
+	PowerRef uint32
+	// EnergyRef is the count per kWh of the energy pulse counter. It
+	// defaults to 6120.
+	EnergyRef uint32
+}
+
+// Device wraps a UART connection to a BL0940.
+type Device struct {
+	uart  drivers.UART
+	cfg   Config
+	frame Frame
+
+	// Timeout for a response. Defaults to 100ms.
+	Timeout time.Duration
+
+	voltage     int32
+	current     int32
+	power       int32
+	temperature int32
+
+	pulses uint64
+	lastCF uint32
+	haveCF bool
+}
+
+// New creates a new BL0940 connection. The UART must already be configured
+// at 4800 baud, and the SEL pin of the chip tied low.
+//
+// This function only creates the Device object, it does not touch the device.
+func New(uart drivers.UART) *Device {
+	return &Device{uart: uart, Timeout: 100 * time.Millisecond}
+}
+
+// Configure sets the calibration and resets the chip.
+func (d *Device) Configure(cfg Config) error {
+	if cfg.VoltageRef == 0 {
+		cfg.VoltageRef = 17476
+	}
+	if cfg.CurrentRef == 0 {
+		cfg.CurrentRef = 266013
+	}
+	if cfg.PowerRef == 0 {
+		cfg.PowerRef = 713105
+	}
+	if cfg.EnergyRef == 0 {
+		cfg.EnergyRef = 6120
+	}
+	d.cfg = cfg
+
+	// Unlock the user registers and do a soft reset.
+	if err := d.writeRegister(regWrProt, 0x55); err != nil {
+		return err
+	}
+	if err := d.writeRegister(regSoftRst, 0x5A5A5A); err != nil {
+		return err
+	}
+	time.Sleep(10 * time.Millisecond)
+	if err := d.writeRegister(regWrProt, 0x55); err != nil {
+		return err
+	}
+	// Count the energy of both signs, in absolute value, and use a 50Hz
+	// update rate for the RMS values.
+	return d.writeRegister(regMode, 0x001000)
+}
+
+// Update reads all measurements from the chip.
+func (d *Device) Update(which drivers.Measurement) error {
+	d.flush()
+	if _, err := d.uart.Write([]byte{cmdRead, regAll}); err != nil {
+		return err
+	}
+	deadline := time.Now().Add(d.Timeout)
+	for n := 0; n < FrameSize; {
+		if d.uart.Buffered() == 0 {
+			if time.Now().After(deadline) {
+				return errTimeout
+			}
+			time.Sleep(time.Millisecond)
+			continue
+		}
+		m, err := d.uart.Read(d.frame[n:])
+		if err != nil {
+			return err
+		}
+		n += m
+	}
+	if !d.frame.valid() {
+		return errChecksum
+	}
+	d.decode(&d.frame)
+	return nil
+}
+
+func (d *Device) decode(f *Frame) {
+	d.current = int32(uint64(f.reg(4)) * 1000 / uint64(d.cfg.CurrentRef))
+	d.voltage = int32(uint64(f.reg(10)) * 1000 / uint64(d.cfg.VoltageRef))
+	watt := int64(int32(f.reg(16)<<8) >> 8)
+	d.power = int32(watt * 1000000 / int64(d.cfg.PowerRef))
+
+	// Internal temperature: (170/448)(TPS1/2 - 32) - 45 °C.
+	tps := int32(f.reg(28) & 0x3FF)
+	d.temperature = 170000*(tps-64)/896 - 45000
+
+	cf := f.reg(22)
+	if d.haveCF {
+		d.pulses += uint64((cf - d.lastCF) & 0xFFFFFF)
+	}
+	d.lastCF, d.haveCF = cf, true
+}
+
+// Voltage returns the RMS voltage in mV.
+func (d *Device) Voltage() int32 {
+	return d.voltage
+}
+
+// Current returns the RMS current in mA.
+func (d *Device) Current() int32 {
+	return d.current
+}
+
+// ActivePower returns the active power in mW. It is negative when the
+// energy flows back to the grid.
+func (d *Device) ActivePower() int32 {
+	return d.power
+}
+
+// PowerFactor returns the power factor in thousandths.
+func (d *Device) PowerFactor() int32 {
+	s := int64(d.voltage) * int64(d.current) / 1000
+	if s == 0 {
+		return 0
+	}
+	pf := int32(int64(d.power) * 1000 / s)
+	if pf > 1000 {
+		pf = 1000
+	} else if pf < -1000 {
+		pf = -1000
+	}
+	return pf
+}
+
+// Temperature returns the internal temperature of the chip in m°C.
+func (d *Device) Temperature() int32 {
+	return d.temperature
+}
+
+// Energy returns the energy counted since the first Update in mWh.
+func (d *Device) Energy() int64 {
+	if d.cfg.EnergyRef == 0 {
+		return 0
+	}
+	return int64(d.pulses * 1000000 / uint64(d.cfg.EnergyRef))
+}
+
+// ResetEnergy sets the energy counter back to zero.
+func (d *Device) ResetEnergy() {
+	d.pulses = 0
+}
+
+func (d *Device) writeRegister(reg uint8, value uint32) error {
+	buf := [6]byte{cmdWrite, reg, byte(value), byte(value >> 8), byte(value >> 16)}
+	sum := byte(0)
+	for _, b := range buf[:5] {
+		sum += b
+	}
+	buf[5] = ^sum
+	_, err := d.uart.Write(buf[:])
+	return err
+}
+
+// flush discards any stale bytes in the receive buffer.
+func (d *Device) flush() {
+	for d.uart.Buffered() > 0 {
+		d.uart.Read(d.frame[:])
+	}
+}
@@ -0,0 +1,48 @@
+package bl0940
+
+import (
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+)
+
+func packet(irms, vrms uint32, watt int32, cf, tps uint32) Frame {
+	var f Frame
+	f[0] = frameHead
+	put := func(i int, v uint32) {
+		f[i], f[i+1], f[i+2] = byte(v), byte(v>>8), byte(v>>16)
+	}
+	put(4, irms)
+	put(10, vrms)
+	put(16, uint32(watt)&0xFFFFFF)
+	put(22, cf)
+	put(28, tps)
+	sum := byte(cmdRead)
+	for _, b := range f[:FrameSize-1] {
+		sum += b
+	}
+	f[FrameSize-1] = ^sum
+	return f
+}
+
+func TestDecode(t *testing.T) {
+	c := qt.New(t)
+	d := New(nil)
+	d.cfg = Config{VoltageRef: 17476, CurrentRef: 266013, PowerRef: 713105, EnergyRef: 6120}
+
+	f := packet(266013, 17476*230, -713105/2, 0xFFFFF0, 64+448*2)
+	c.Assert(f.valid(), qt.IsTrue)
+	d.decode(&f)
+	c.Assert(d.Voltage(), qt.Equals, int32(230000))
+	c.Assert(d.Current(), qt.Equals, int32(1000))
+	c.Assert(d.ActivePower(), qt.Equals, int32(-499999))
+	c.Assert(d.Temperature(), qt.Equals, int32(125000))
+
+	// The 24-bit pulse counter wraps around.
+	f = packet(0, 0, 0, 6120-0x10, 0)
+	d.decode(&f)
+	c.Assert(d.Energy(), qt.Equals, int64(1000000))
+
+	f[5]++
+	c.Assert(f.valid(), qt.IsFalse)
+}
@@ -158,6 +158,23 @@ func (d *Device) Connected() bool {
 	return data[0] == CHIP_ID
 }
 
+// DriverName returns "bme280".
+func (d *Device) DriverName() string {
+	return "bme280"
+}
+
+// ChipID returns the content of the chip ID register, 0x60 for a BME280.
+func (d *Device) ChipID() (uint32, error) {
+	data := []byte{0}
+	err := legacy.ReadRegister(d.bus, uint8(d.Address), WHO_AM_I, data)
+	return uint32(data[0]), err
+}
+
+// RevisionID returns 0, the BME280 has no revision register.
+func (d *Device) RevisionID() (uint32, error) {
+	return 0, nil
+}
+
 // Reset the device
 func (d *Device) Reset() {
 	legacy.WriteRegister(d.bus, uint8(d.Address), CMD_RESET, []byte{0xB6})
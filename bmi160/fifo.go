@@ -0,0 +1,113 @@
+//go:build tinygo
+
+package bmi160
+
+// ConfigureFIFO enables the FIFO in headerless mode, storing both
+// accelerometer and gyroscope data. When watermark (in frames) is non-zero,
+// the FIFO watermark interrupt is enabled and mapped to INT1. The watermark
+// can be at most 85 frames.
+func (d *DeviceSPI) ConfigureFIFO(watermark uint8) error {
+	if watermark > 85 {
+		watermark = 85
+	}
+	// The watermark is stored in units of 4 bytes.
+	d.writeRegister(reg_FIFO_CONFIG_0, uint8(uint16(watermark)*fifoFrame/4))
+	// fifo_gyr_en=1, fifo_acc_en=1, fifo_header_en=0
+	d.writeRegister(reg_FIFO_CONFIG_1, 0b1100_0000)
+
+	if watermark != 0 {
+		d.writeRegister(reg_INT_EN_1, d.readRegister(reg_INT_EN_1)|0b0100_0000)
+		d.writeRegister(reg_INT_MAP_1, d.readRegister(reg_INT_MAP_1)|0b0100_0000)
+		d.enableInt1()
+	} else {
+		d.writeRegister(reg_INT_EN_1, d.readRegister(reg_INT_EN_1)&^0b0100_0000)
+	}
+
+	d.FlushFIFO()
+	return nil
+}
+
+// FlushFIFO discards all data in the FIFO.
+func (d *DeviceSPI) FlushFIFO() {
+	d.runCommand(0xB0) // fifo_flush
+}
+
+// FIFOLength returns the number of complete frames in the FIFO.
+func (d *DeviceSPI) FIFOLength() (int, error) {
+	data := d.buf[:3]
+	data[0] = 0x80 | reg_FIFO_LENGTH_0
+	data[1] = 0
+	data[2] = 0
+	d.CSB.Low()
+	err := d.Bus.Tx(data, data)
+	d.CSB.High()
+	if err != nil {
+		return 0, err
+	}
+	return int(uint16(data[1])|uint16(data[2]&0x07)<<8) / fifoFrame, nil
+}
+
+// ReadFIFO reads up to len(samples) frames from the FIFO in a single burst
+// per 16 frames and returns the number of samples read.
+func (d *DeviceSPI) ReadFIFO(samples []FIFOSample) (int, error) {
+	n, err := d.FIFOLength()
+	if err != nil {
+		return 0, err
+	}
+	if n > len(samples) {
+		n = len(samples)
+	}
+	var data [1 + 16*fifoFrame]byte
+	for read := 0; read < n; {
+		batch := n - read
+		if batch > 16 {
+			batch = 16
+		}
+		buf := data[:1+batch*fifoFrame]
+		for i := range buf {
+			buf[i] = 0
+		}
+		buf[0] = 0x80 | reg_FIFO_DATA
+		d.CSB.Low()
+		err := d.Bus.Tx(buf, buf)
+		d.CSB.High()
+		if err != nil {
+			return read, err
+		}
+		for i := 0; i < batch; i++ {
+			samples[read+i] = decodeFIFOFrame(buf[1+i*fifoFrame:])
+		}
+		read += batch
+	}
+	return n, nil
+}
+
+// EnableAnyMotion enables the any-motion interrupt on all three axes and maps
+// it to INT1. The threshold is in units of 3.91mg (at the default ±2g range)
+// and duration is the number of consecutive samples (1-4) that must exceed
+// it.
+func (d *DeviceSPI) EnableAnyMotion(threshold uint8, duration uint8) error {
+	if duration < 1 {
+		duration = 1
+	}
+	if duration > 4 {
+		duration = 4
+	}
+	d.writeRegister(reg_INT_MOTION_0, (d.readRegister(reg_INT_MOTION_0)&^0x03)|(duration-1))
+	d.writeRegister(reg_INT_MOTION_1, threshold)
+	d.writeRegister(reg_INT_EN_0, d.readRegister(reg_INT_EN_0)|0b0000_0111)
+	d.writeRegister(reg_INT_MAP_0, d.readRegister(reg_INT_MAP_0)|0b0000_0100)
+	d.enableInt1()
+	return nil
+}
+
+// AnyMotion returns whether the any-motion interrupt has triggered.
+func (d *DeviceSPI) AnyMotion() bool {
+	return d.readRegister(reg_INT_STATUS_0)&0b0000_0100 != 0
+}
+
+// enableInt1 configures the INT1 pin as an active high push-pull output.
+func (d *DeviceSPI) enableInt1() {
+	// int1_output_en=1, int1_od=0, int1_lvl=1
+	d.writeRegister(reg_INT_OUT_CTRL, (d.readRegister(reg_INT_OUT_CTRL)&^0x0F)|0b0000_1010)
+}
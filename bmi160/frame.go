@@ -0,0 +1,26 @@
+package bmi160
+
+// FIFOSample is a single frame read from the FIFO, with the same units as
+// ReadAcceleration (µg) and ReadRotation (µ°/s).
+type FIFOSample struct {
+	AccelX, AccelY, AccelZ int32
+	GyroX, GyroY, GyroZ    int32
+}
+
+// fifoFrame is the size of a headerless FIFO frame with both the gyroscope and
+// accelerometer enabled.
+const fifoFrame = 12
+
+// decodeFIFOFrame converts a headerless FIFO frame. Frames contain the
+// gyroscope data first, followed by the accelerometer data. Scaling is the
+// same as in ReadRotation and ReadAcceleration.
+func decodeFIFOFrame(f []byte) FIFOSample {
+	return FIFOSample{
+		GyroX:  int32(int64(int16(uint16(f[0])|uint16(f[1])<<8)) * 1953125 / 32),
+		GyroY:  int32(int64(int16(uint16(f[2])|uint16(f[3])<<8)) * 1953125 / 32),
+		GyroZ:  int32(int64(int16(uint16(f[4])|uint16(f[5])<<8)) * 1953125 / 32),
+		AccelX: int32(int16(uint16(f[6])|uint16(f[7])<<8)) * 15625 / 256,
+		AccelY: int32(int16(uint16(f[8])|uint16(f[9])<<8)) * 15625 / 256,
+		AccelZ: int32(int16(uint16(f[10])|uint16(f[11])<<8)) * 15625 / 256,
+	}
+}
@@ -0,0 +1,25 @@
+package bmi160
+
+import (
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+)
+
+func TestDecodeFIFOFrame(t *testing.T) {
+	c := qt.New(t)
+	// Gyroscope at +1/-1 LSB and full scale, then 1g, -1g and 0 of the
+	// accelerometer.
+	frame := []byte{
+		0x01, 0x00, 0xFF, 0xFF, 0x00, 0x80,
+		0x00, 0x40, 0x00, 0xC0, 0x00, 0x00,
+	}
+	c.Assert(decodeFIFOFrame(frame), qt.Equals, FIFOSample{
+		GyroX:  61035,
+		GyroY:  -61035,
+		GyroZ:  -2000000000,
+		AccelX: 1000000,
+		AccelY: -1000000,
+		AccelZ: 0,
+	})
+}
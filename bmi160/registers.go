@@ -37,6 +37,22 @@ const (
 	reg_FIFO_LENGTH_0 = 0x22
 	reg_FIFO_LENGTH_1 = 0x23
 	reg_FIFO_DATA     = 0x24
+	reg_ACC_CONF      = 0x40
+	reg_ACC_RANGE     = 0x41
+	reg_GYR_CONF      = 0x42
+	reg_GYR_RANGE     = 0x43
+	reg_FIFO_CONFIG_0 = 0x46
+	reg_FIFO_CONFIG_1 = 0x47
+	reg_INT_EN_0      = 0x50
+	reg_INT_EN_1      = 0x51
+	reg_INT_EN_2      = 0x52
+	reg_INT_OUT_CTRL  = 0x53
+	reg_INT_LATCH     = 0x54
+	reg_INT_MAP_0     = 0x55
+	reg_INT_MAP_1     = 0x56
+	reg_INT_MAP_2     = 0x57
+	reg_INT_MOTION_0  = 0x5F
+	reg_INT_MOTION_1  = 0x60
 
 	// ...
 
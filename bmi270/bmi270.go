@@ -0,0 +1,292 @@
+// Package bmi270 provides a driver for the BMI270 accelerometer/gyroscope
+// found on many smartwatch boards.
+//
+// Datasheet:
+// https://www.bosch-sensortec.com/media/boschsensortec/downloads/datasheets/bst-bmi270-ds000.pdf
+//
+// Like the BMA42x, the BMI270 does not work before a "config file" (really a
+// firmware for the feature engine) has been uploaded to it. Bosch publishes
+// several of them with different feature sets in the BMI270-Sensor-API
+// repository, for example the base one in bmi270.c and the wearable one with
+// the wrist gesture feature in bmi270_wh.c. They are not included in this
+// package: pass the one you need in Config.ConfigFile.
+package bmi270 // import "tinygo.org/x/drivers/bmi270"
+
+import (
+	"errors"
+	"time"
+
+	"tinygo.org/x/drivers"
+)
+
+var (
+	errNoConfigFile  = errors.New("bmi270: no config file")
+	errUnknownDevice = errors.New("bmi270: unknown device")
+	errInitFailed    = errors.New("bmi270: failed to initialize")
+	errTimeout       = errors.New("bmi270: timeout")
+	errNoFeature     = errors.New("bmi270: feature not present in config file")
+)
+
+const (
+	Address    = 0x68 // SDO pulled low
+	AddressAlt = 0x69 // SDO pulled high
+
+	chipID = 0x24
+
+	// uploadChunk is the number of config file bytes written per I2C
+	// transaction. It must be even.
+	uploadChunk = 64
+)
+
+// FeatureAddress is the location of a feature's enable word in the feature
+// pages. These locations depend on the config file in use.
+type FeatureAddress struct {
+	Page   uint8
+	Offset uint8 // byte offset within the page, starting at 0
+	Enable uint16
+}
+
+// AnyMotionBase is the location of the any-motion enable word in the base
+// config file (bmi270.c).
+var AnyMotionBase = FeatureAddress{Page: 1, Offset: 0x0E, Enable: 0x8000}
+
+type Config struct {
+	// The config file to upload during Configure. Required.
+	ConfigFile []byte
+
+	// Full scale ranges. The zero values are ±2g and ±2000°/s.
+	AccelRange AccelRange
+	GyroRange  GyroRange
+
+	// Locations of features in the config file. Leave zero when the feature
+	// is not used or not present in the config file.
+	AnyMotion    FeatureAddress
+	WristGesture FeatureAddress
+}
+
+type Device struct {
+	bus        drivers.I2C
+	address    uint8
+	config     Config
+	accelData  [6]byte
+	gyroData   [6]byte
+	tempData   [2]byte
+	dataBuf    [3]byte
+	uploadBuf  [1 + uploadChunk]byte
+	featureBuf [17]byte
+}
+
+func NewI2C(i2c drivers.I2C, address uint8) *Device {
+	return &Device{
+		bus:     i2c,
+		address: address,
+	}
+}
+
+// Connected returns whether a BMI270 has been found.
+func (d *Device) Connected() bool {
+	val, err := d.read1(_CHIP_ID)
+	return err == nil && val == chipID
+}
+
+// Configure resets the chip, uploads the config file and enables the
+// accelerometer, gyroscope and temperature sensor.
+func (d *Device) Configure(config Config) error {
+	if len(config.ConfigFile) == 0 {
+		return errNoConfigFile
+	}
+	d.config = config
+
+	id, err := d.read1(_CHIP_ID)
+	if err != nil {
+		return err
+	}
+	if id != chipID {
+		return errUnknownDevice
+	}
+
+	err = d.write1(_CMD, cmdSoftReset)
+	if err != nil {
+		return err
+	}
+	time.Sleep(2 * time.Millisecond)
+
+	// Disable advanced power saving, which is required for the burst
+	// writes below.
+	err = d.write1(_PWR_CONF, 0x00)
+	if err != nil {
+		return err
+	}
+	time.Sleep(450 * time.Microsecond)
+
+	err = d.write1(_INIT_CTRL, 0x00)
+	if err != nil {
+		return err
+	}
+	err = d.uploadConfig(config.ConfigFile)
+	if err != nil {
+		return err
+	}
+	err = d.write1(_INIT_CTRL, 0x01)
+	if err != nil {
+		return err
+	}
+
+	// The datasheet says initialization takes at most 20ms.
+	start := time.Now()
+	for {
+		status, err := d.read1(_INTERNAL_STATUS)
+		if err != nil {
+			return err
+		}
+		switch status & 0x0F {
+		case 0x01: // init_ok
+		case 0x00: // not_init
+			if time.Since(start) >= 20*time.Millisecond {
+				return errTimeout
+			}
+			time.Sleep(time.Millisecond)
+			continue
+		default:
+			return errInitFailed
+		}
+		break
+	}
+
+	// Enable the sensors:
+	//   acc_filter_perf=1 gyr_filter_perf=1 (performance mode)
+	//   acc_odr=100Hz acc_bwp=normal
+	//   gyr_odr=200Hz gyr_bwp=normal gyr_noise_perf=1
+	err = d.write1(_PWR_CTRL, pwrAccEn|pwrGyrEn|pwrTempEn)
+	if err != nil {
+		return err
+	}
+	err = d.write1(_ACC_CONF, 0x80|0x02<<4|0x08)
+	if err != nil {
+		return err
+	}
+	err = d.write1(_ACC_RANGE, uint8(config.AccelRange))
+	if err != nil {
+		return err
+	}
+	err = d.write1(_GYR_CONF, 0x80|0x40|0x02<<4|0x09)
+	if err != nil {
+		return err
+	}
+	err = d.write1(_GYR_RANGE, uint8(config.GyroRange))
+	if err != nil {
+		return err
+	}
+
+	// Enable advanced power saving again now that the sensors are set up.
+	return d.write1(_PWR_CONF, 0x02)
+}
+
+// uploadConfig writes the config file to the chip in bursts. The INIT_ADDR
+// registers hold the word (not byte) offset of each burst.
+func (d *Device) uploadConfig(data []byte) error {
+	for offset := 0; offset < len(data); offset += uploadChunk {
+		word := offset / 2
+		err := d.write1(_INIT_ADDR_0, uint8(word&0x0F))
+		if err != nil {
+			return err
+		}
+		err = d.write1(_INIT_ADDR_1, uint8(word>>4))
+		if err != nil {
+			return err
+		}
+		chunk := data[offset:]
+		if len(chunk) > uploadChunk {
+			chunk = chunk[:uploadChunk]
+		}
+		d.uploadBuf[0] = _INIT_DATA
+		n := copy(d.uploadBuf[1:], chunk)
+		err = d.bus.Tx(uint16(d.address), d.uploadBuf[:1+n], nil)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Update reads the requested measurements from the sensor.
+func (d *Device) Update(which drivers.Measurement) error {
+	if which&drivers.Acceleration != 0 {
+		err := d.readn(_DATA_8, d.accelData[:])
+		if err != nil {
+			return err
+		}
+	}
+	if which&drivers.AngularVelocity != 0 {
+		err := d.readn(_DATA_14, d.gyroData[:])
+		if err != nil {
+			return err
+		}
+	}
+	if which&drivers.Temperature != 0 {
+		err := d.readn(_TEMPERATURE_0, d.tempData[:])
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Temperature returns the last read temperature in celsius milli degrees (1°C
+// is 1000).
+func (d *Device) Temperature() int32 {
+	// 0x0000 is 23°C, with 1/512 K per LSB.
+	raw := int32(int16(uint16(d.tempData[0]) | uint16(d.tempData[1])<<8))
+	return raw*125/64 + 23000
+}
+
+// Acceleration returns the last read acceleration in µg (micro-gravity).
+// When one of the axes is pointing straight to Earth and the sensor is not
+// moving the returned value will be around 1000000 or -1000000.
+func (d *Device) Acceleration() (x, y, z int32) {
+	return d.scaleAccel(d.accelData[:])
+}
+
+// AngularVelocity returns the last read rotation in µ°/s (micro-degrees/sec).
+func (d *Device) AngularVelocity() (x, y, z int32) {
+	return d.scaleGyro(d.gyroData[:])
+}
+
+// scaleAccel converts raw little endian acceleration data to µg. At ±2g the
+// scale is 16384 LSB/g, which is 1000000/16384 = 15625/256 µg per LSB. Every
+// doubling of the range doubles that.
+func (d *Device) scaleAccel(data []byte) (x, y, z int32) {
+	shift := d.config.AccelRange
+	x = int32(int16(uint16(data[0])|uint16(data[1])<<8)) * 15625 / (256 >> shift)
+	y = int32(int16(uint16(data[2])|uint16(data[3])<<8)) * 15625 / (256 >> shift)
+	z = int32(int16(uint16(data[4])|uint16(data[5])<<8)) * 15625 / (256 >> shift)
+	return
+}
+
+// scaleGyro converts raw little endian rotation data to µ°/s. At ±2000°/s the
+// scale is 2000e6/32768 = 1953125/32 µ°/s per LSB. Every halving of the range
+// halves that.
+func (d *Device) scaleGyro(data []byte) (x, y, z int32) {
+	shift := d.config.GyroRange
+	x = int32(int64(int16(uint16(data[0])|uint16(data[1])<<8)) * 1953125 / (32 << shift))
+	y = int32(int64(int16(uint16(data[2])|uint16(data[3])<<8)) * 1953125 / (32 << shift))
+	z = int32(int64(int16(uint16(data[4])|uint16(data[5])<<8)) * 1953125 / (32 << shift))
+	return
+}
+
+func (d *Device) read1(register uint8) (uint8, error) {
+	d.dataBuf[0] = register
+	err := d.bus.Tx(uint16(d.address), d.dataBuf[:1], d.dataBuf[1:2])
+	return d.dataBuf[1], err
+}
+
+func (d *Device) readn(register uint8, data []byte) error {
+	d.dataBuf[0] = register
+	return d.bus.Tx(uint16(d.address), d.dataBuf[:1], data)
+}
+
+func (d *Device) write1(register uint8, data uint8) error {
+	d.dataBuf[0] = register
+	d.dataBuf[1] = data
+	return d.bus.Tx(uint16(d.address), d.dataBuf[:2], nil)
+}
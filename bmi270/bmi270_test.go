@@ -0,0 +1,83 @@
+package bmi270
+
+import (
+	"bytes"
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+	"tinygo.org/x/drivers/tester"
+)
+
+// fakeChip is a BMI270 which takes the config file written to INIT_DATA and
+// finishes its initialization once INIT_CTRL is set.
+type fakeChip struct {
+	*tester.I2CDevice8
+	c      *qt.C
+	config []byte
+}
+
+func (f *fakeChip) Tx(w, r []byte) error {
+	switch {
+	case len(w) > 1 && w[0] == _INIT_DATA:
+		word := int(f.Registers[_INIT_ADDR_0]&0x0F) | int(f.Registers[_INIT_ADDR_1])<<4
+		f.c.Assert(2*word, qt.Equals, len(f.config), qt.Commentf("burst offset"))
+		f.config = append(f.config, w[1:]...)
+		return nil
+	case len(w) == 2 && w[0] == _INIT_CTRL && w[1] == 1:
+		f.Registers[_INTERNAL_STATUS] = 0x01
+	}
+	return f.I2CDevice8.Tx(w, r)
+}
+
+func newChip(c *qt.C) (*Device, *fakeChip) {
+	bus := tester.NewI2CBus(c)
+	chip := &fakeChip{I2CDevice8: tester.NewI2CDevice8(c, Address), c: c}
+	chip.Registers[_CHIP_ID] = chipID
+	bus.AddDevice(chip)
+	return NewI2C(bus, Address), chip
+}
+
+func TestConfigure(t *testing.T) {
+	c := qt.New(t)
+	dev, chip := newChip(c)
+	c.Assert(dev.Connected(), qt.IsTrue)
+	c.Assert(dev.Configure(Config{}), qt.Equals, errNoConfigFile)
+
+	// Three bursts, the last one short.
+	file := make([]byte, 2*uploadChunk+22)
+	for i := range file {
+		file[i] = byte(i * 7)
+	}
+	c.Assert(dev.Configure(Config{ConfigFile: file, AccelRange: AccelRange4G}), qt.IsNil)
+	c.Assert(bytes.Equal(chip.config, file), qt.IsTrue)
+	c.Assert(chip.Registers[_ACC_RANGE], qt.Equals, uint8(AccelRange4G))
+	c.Assert(chip.Registers[_PWR_CTRL], qt.Equals, uint8(pwrAccEn|pwrGyrEn|pwrTempEn))
+
+	chip.Registers[_CHIP_ID] = 0x43
+	c.Assert(dev.Configure(Config{ConfigFile: file}), qt.Equals, errUnknownDevice)
+}
+
+func TestReadFIFO(t *testing.T) {
+	c := qt.New(t)
+	dev, chip := newChip(c)
+	dev.config.AccelRange = AccelRange4G
+
+	// Two frames and a partial one.
+	chip.Registers[_FIFO_LENGTH_0] = 2*fifoFrame + 5
+	copy(chip.Registers[_FIFO_DATA:], []byte{
+		0x20, 0x00, 0xE0, 0xFF, 0x00, 0x00, 0x00, 0x20, 0x00, 0xE0, 0x00, 0x00,
+		0x00, 0x00, 0x00, 0x00, 0x01, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x10,
+	})
+	n, err := dev.FIFOLength()
+	c.Assert(err, qt.IsNil)
+	c.Assert(n, qt.Equals, 2)
+
+	samples := make([]Sample, 4)
+	n, err = dev.ReadFIFO(samples)
+	c.Assert(err, qt.IsNil)
+	c.Assert(n, qt.Equals, 2)
+	c.Assert(samples[:2], qt.DeepEquals, []Sample{
+		{GyroX: 1953125, GyroY: -1953125, AccelX: 1000000, AccelY: -1000000},
+		{GyroZ: 61035, AccelZ: 500000},
+	})
+}
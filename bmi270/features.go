@@ -0,0 +1,110 @@
+package bmi270
+
+// AnyMotionConfig configures the any-motion detector.
+type AnyMotionConfig struct {
+	// Threshold is the slope between two samples that counts as motion, in
+	// units of 0.48mg (11 bits).
+	Threshold uint16
+
+	// Duration is the number of consecutive 20ms periods the threshold must
+	// be exceeded for (13 bits).
+	Duration uint16
+}
+
+// EnableAnyMotion configures and enables the any-motion feature on all axes.
+// The location of the feature must be set in Config.AnyMotion, for the base
+// config file this is AnyMotionBase.
+func (d *Device) EnableAnyMotion(cfg AnyMotionConfig) error {
+	addr := d.config.AnyMotion
+	if addr.Enable == 0 {
+		return errNoFeature
+	}
+	// The configuration consists of two words, the second of which holds the
+	// enable bit:
+	//   word 0: duration[12:0], select_x[13], select_y[14], select_z[15]
+	//   word 1: threshold[10:0], ..., enable[15]
+	var words [2]uint16
+	words[0] = cfg.Duration&0x1FFF | 0xE000
+	words[1] = cfg.Threshold&0x07FF | addr.Enable
+	return d.writeFeature(addr.Page, addr.Offset-2, words[:])
+}
+
+// EnableWristGesture enables the wrist gesture feature. It is only present in
+// the wearable config files, and its location must be set in
+// Config.WristGesture.
+func (d *Device) EnableWristGesture() error {
+	addr := d.config.WristGesture
+	if addr.Enable == 0 {
+		return errNoFeature
+	}
+	var word [1]uint16
+	err := d.readFeature(addr.Page, addr.Offset, word[:])
+	if err != nil {
+		return err
+	}
+	word[0] |= addr.Enable
+	return d.writeFeature(addr.Page, addr.Offset, word[:])
+}
+
+// WristGesture returns the last detected wrist gesture.
+func (d *Device) WristGesture() (Gesture, error) {
+	val, err := d.read1(_WR_GEST_ACT)
+	return Gesture(val & 0x07), err
+}
+
+// MapInterrupts maps feature interrupts to the INT1 and INT2 pins, and
+// configures both pins as active high push-pull outputs.
+func (d *Device) MapInterrupts(int1, int2 Interrupt) error {
+	err := d.write1(_INT1_MAP_FEAT, uint8(int1))
+	if err != nil {
+		return err
+	}
+	err = d.write1(_INT2_MAP_FEAT, uint8(int2))
+	if err != nil {
+		return err
+	}
+	// output_en=1, od=0 (push-pull), lvl=1 (active high)
+	err = d.write1(_INT1_IO_CTRL, 0x0A)
+	if err != nil {
+		return err
+	}
+	return d.write1(_INT2_IO_CTRL, 0x0A)
+}
+
+// InterruptStatus reads and clears the feature interrupt status.
+func (d *Device) InterruptStatus() (Interrupt, error) {
+	val, err := d.read1(_INT_STATUS_0)
+	return Interrupt(val), err
+}
+
+// readFeature reads words from a page of the feature configuration.
+func (d *Device) readFeature(page, offset uint8, words []uint16) error {
+	err := d.write1(_FEAT_PAGE, page)
+	if err != nil {
+		return err
+	}
+	data := d.featureBuf[1 : 1+2*len(words)]
+	err = d.readn(_FEATURES+offset, data)
+	if err != nil {
+		return err
+	}
+	for i := range words {
+		words[i] = uint16(data[2*i]) | uint16(data[2*i+1])<<8
+	}
+	return nil
+}
+
+// writeFeature writes words to a page of the feature configuration.
+func (d *Device) writeFeature(page, offset uint8, words []uint16) error {
+	err := d.write1(_FEAT_PAGE, page)
+	if err != nil {
+		return err
+	}
+	buf := d.featureBuf[:1+2*len(words)]
+	buf[0] = _FEATURES + offset
+	for i, w := range words {
+		buf[1+2*i] = uint8(w)
+		buf[2+2*i] = uint8(w >> 8)
+	}
+	return d.bus.Tx(uint16(d.address), buf, nil)
+}
@@ -0,0 +1,91 @@
+package bmi270
+
+// Sample is a single accelerometer and gyroscope frame read from the FIFO,
+// converted to µg and µ°/s.
+type Sample struct {
+	AccelX, AccelY, AccelZ int32
+	GyroX, GyroY, GyroZ    int32
+}
+
+// fifoFrame is the size of a headerless FIFO frame with both the gyroscope
+// and accelerometer enabled.
+const fifoFrame = 12
+
+// ConfigureFIFO enables the FIFO in headerless mode with both accelerometer
+// and gyroscope data. When watermark (in frames) is non-zero, the FIFO
+// watermark interrupt is mapped to INT1.
+func (d *Device) ConfigureFIFO(watermark uint16) error {
+	err := d.write1(_FIFO_CONFIG_0, 0x00) // stop_on_full=0 (stream mode)
+	if err != nil {
+		return err
+	}
+	err = d.write1(_FIFO_CONFIG_1, fifoAccEn|fifoGyrEn)
+	if err != nil {
+		return err
+	}
+	wtm := watermark * fifoFrame
+	d.dataBuf[0] = _FIFO_WTM_0
+	d.dataBuf[1] = uint8(wtm)
+	d.dataBuf[2] = uint8(wtm>>8) & 0x1F
+	err = d.bus.Tx(uint16(d.address), d.dataBuf[:3], nil)
+	if err != nil {
+		return err
+	}
+	var mapData uint8
+	if watermark != 0 {
+		mapData = intMapFIFOWatermark
+	}
+	err = d.write1(_INT_MAP_DATA, mapData)
+	if err != nil {
+		return err
+	}
+	return d.FlushFIFO()
+}
+
+// FlushFIFO discards all data in the FIFO.
+func (d *Device) FlushFIFO() error {
+	return d.write1(_CMD, cmdFIFOFlush)
+}
+
+// FIFOLength returns the number of complete frames in the FIFO.
+func (d *Device) FIFOLength() (int, error) {
+	var data [2]byte
+	err := d.readn(_FIFO_LENGTH_0, data[:])
+	if err != nil {
+		return 0, err
+	}
+	return int(uint16(data[0])|uint16(data[1]&0x3F)<<8) / fifoFrame, nil
+}
+
+// ReadFIFO reads up to len(samples) frames from the FIFO in one burst per 16
+// frames and returns the number of samples read.
+func (d *Device) ReadFIFO(samples []Sample) (int, error) {
+	n, err := d.FIFOLength()
+	if err != nil {
+		return 0, err
+	}
+	if n > len(samples) {
+		n = len(samples)
+	}
+	var data [16 * fifoFrame]byte
+	for read := 0; read < n; {
+		batch := n - read
+		if batch > 16 {
+			batch = 16
+		}
+		err := d.readn(_FIFO_DATA, data[:batch*fifoFrame])
+		if err != nil {
+			return read, err
+		}
+		for i := 0; i < batch; i++ {
+			// In headerless mode the gyroscope data precedes the
+			// accelerometer data in every frame.
+			frame := data[i*fifoFrame:]
+			s := &samples[read+i]
+			s.GyroX, s.GyroY, s.GyroZ = d.scaleGyro(frame[0:6])
+			s.AccelX, s.AccelY, s.AccelZ = d.scaleAccel(frame[6:12])
+		}
+		read += batch
+	}
+	return n, nil
+}
@@ -0,0 +1,106 @@
+package bmi270
+
+const (
+	// I2C registers
+	_CHIP_ID         = 0x00
+	_ERR_REG         = 0x02
+	_STATUS          = 0x03
+	_DATA_8          = 0x0C // ACC_X LSB
+	_DATA_14         = 0x12 // GYR_X LSB
+	_SENSORTIME_0    = 0x18
+	_EVENT           = 0x1B
+	_INT_STATUS_0    = 0x1C
+	_INT_STATUS_1    = 0x1D
+	_WR_GEST_ACT     = 0x20
+	_INTERNAL_STATUS = 0x21
+	_TEMPERATURE_0   = 0x22
+	_FIFO_LENGTH_0   = 0x24
+	_FIFO_DATA       = 0x26
+	_FEAT_PAGE       = 0x2F
+	_FEATURES        = 0x30
+	_ACC_CONF        = 0x40
+	_ACC_RANGE       = 0x41
+	_GYR_CONF        = 0x42
+	_GYR_RANGE       = 0x43
+	_FIFO_WTM_0      = 0x46
+	_FIFO_CONFIG_0   = 0x48
+	_FIFO_CONFIG_1   = 0x49
+	_INT1_IO_CTRL    = 0x53
+	_INT2_IO_CTRL    = 0x54
+	_INT_LATCH       = 0x55
+	_INT1_MAP_FEAT   = 0x56
+	_INT2_MAP_FEAT   = 0x57
+	_INT_MAP_DATA    = 0x58
+	_INIT_CTRL       = 0x59
+	_INIT_ADDR_0     = 0x5B
+	_INIT_ADDR_1     = 0x5C
+	_INIT_DATA       = 0x5E
+	_PWR_CONF        = 0x7C
+	_PWR_CTRL        = 0x7D
+	_CMD             = 0x7E
+
+	// Commands send to _CMD.
+	cmdSoftReset = 0xB6
+	cmdFIFOFlush = 0xB0
+
+	// Bits in _FIFO_CONFIG_1.
+	fifoGyrEn = 0x80
+	fifoAccEn = 0x40
+
+	// Bits in _PWR_CTRL.
+	pwrTempEn = 0x08
+	pwrAccEn  = 0x04
+	pwrGyrEn  = 0x02
+
+	// Bits in _INT_MAP_DATA for INT1 (INT2 uses the same bits shifted by 4).
+	intMapFIFOFull      = 0x01
+	intMapFIFOWatermark = 0x02
+)
+
+// Interrupt is a bit in the feature interrupt status register, and also the
+// bit used to map a feature interrupt to one of the interrupt pins.
+type Interrupt uint8
+
+// Feature interrupts of the base and wearable config files.
+const (
+	InterruptSignificantMotion Interrupt = 1 << 0
+	InterruptStepCounter       Interrupt = 1 << 1
+	InterruptActivity          Interrupt = 1 << 2
+	InterruptWristWearWakeup   Interrupt = 1 << 3
+	InterruptWristGesture      Interrupt = 1 << 4
+	InterruptNoMotion          Interrupt = 1 << 5
+	InterruptAnyMotion         Interrupt = 1 << 6
+)
+
+// Gesture is a wrist gesture as detected by the wrist gesture feature.
+type Gesture uint8
+
+const (
+	GestureUnknown     Gesture = 0
+	GesturePushArmDown Gesture = 1
+	GesturePivotUp     Gesture = 2
+	GestureWristShake  Gesture = 3
+	GestureFlickIn     Gesture = 4
+	GestureFlickOut    Gesture = 5
+)
+
+// AccelRange is the full scale range of the accelerometer.
+type AccelRange uint8
+
+const (
+	AccelRange2G  AccelRange = 0
+	AccelRange4G  AccelRange = 1
+	AccelRange8G  AccelRange = 2
+	AccelRange16G AccelRange = 3
+)
+
+// GyroRange is the full scale range of the gyroscope.
+type GyroRange uint8
+
+const (
+	GyroRange2000 GyroRange = 0 // ±2000°/s
+	GyroRange1000 GyroRange = 1 // ±1000°/s
+	GyroRange500  GyroRange = 2 // ±500°/s
+	GyroRange250  GyroRange = 3 // ±250°/s
+	GyroRange125  GyroRange = 4 // ±125°/s
+)
@@ -69,6 +69,23 @@ func (d *Device) Connected() bool {
 	return data[0] == CHIP_ID
 }
 
+// DriverName returns "bmp280".
+func (d *Device) DriverName() string {
+	return "bmp280"
+}
+
+// ChipID returns the content of the chip ID register, 0x58 for a BMP280.
+func (d *Device) ChipID() (uint32, error) {
+	data := []byte{0}
+	err := legacy.ReadRegister(d.bus, uint8(d.Address), REG_ID, data)
+	return uint32(data[0]), err
+}
+
+// RevisionID returns 0, the BMP280 has no revision register.
+func (d *Device) RevisionID() (uint32, error) {
+	return 0, nil
+}
+
 // Reset preforms complete power-on-reset procedure.
 // It is required to call Configure afterwards.
 func (d *Device) Reset() {
@@ -0,0 +1,74 @@
+package bmp388
+
+import (
+	"math"
+	"time"
+)
+
+// SeaLevelPressure is the standard atmospheric pressure at sea level in centipascals.
+const SeaLevelPressure = 10132500
+
+// Altimeter converts a stream of pressure readings into altitude and vertical speed, using the international
+// barometric formula. The vertical speed is smoothed with an exponential moving average, which makes it usable on
+// drones and model rockets where the raw difference between two readings is mostly noise.
+type Altimeter struct {
+	// Reference pressure at sea level in centipascals. Defaults to SeaLevelPressure. Set it to the local QNH for
+	// absolute altitude, or to the pressure at the launch site for altitude above ground.
+	Reference int32
+
+	// Smoothing of the vertical speed between 0 (no smoothing) and 1 (the speed never changes). Defaults to 0.
+	Smoothing float32
+
+	altitude int32 // mm
+	speed    float32
+	last     time.Time
+	valid    bool
+}
+
+// Altitude returns the pressure altitude in millimeters for a pressure in centipascals, relative to the reference
+// pressure (also in centipascals).
+func Altitude(pressure, reference int32) int32 {
+	if pressure <= 0 || reference <= 0 {
+		return 0
+	}
+	// h = 44330 * (1 - (p/p0)^(1/5.255))
+	ratio := float64(pressure) / float64(reference)
+	return int32(44330000 * (1 - math.Pow(ratio, 1/5.255)))
+}
+
+// Update adds a pressure reading in centipascals taken at time t, and returns the new altitude in millimeters.
+func (a *Altimeter) Update(pressure int32, t time.Time) int32 {
+	ref := a.Reference
+	if ref == 0 {
+		ref = SeaLevelPressure
+	}
+	altitude := Altitude(pressure, ref)
+	if a.valid {
+		dt := t.Sub(a.last).Seconds()
+		if dt > 0 {
+			speed := float32(float64(altitude-a.altitude) / dt)
+			a.speed = a.Smoothing*a.speed + (1-a.Smoothing)*speed
+		}
+	}
+	a.altitude = altitude
+	a.last = t
+	a.valid = true
+	return altitude
+}
+
+// Altitude returns the last computed altitude in millimeters.
+func (a *Altimeter) Altitude() int32 {
+	return a.altitude
+}
+
+// VerticalSpeed returns the smoothed vertical speed in millimeters per second, positive when climbing.
+func (a *Altimeter) VerticalSpeed() int32 {
+	return int32(a.speed)
+}
+
+// Reset forgets all previous readings.
+func (a *Altimeter) Reset() {
+	a.altitude = 0
+	a.speed = 0
+	a.valid = false
+}
@@ -123,15 +123,19 @@ func (d *Device) tlinCompensate() (int64, error) {
 	if err != nil {
 		return 0, err
 	}
+	return d.compensateTemperature(rawTemp), nil
+}
 
+// compensateTemperature computes the value used in the temperature and pressure compensation calculations from a raw
+// temperature reading.
+func (d *Device) compensateTemperature(rawTemp int64) int64 {
 	// pulled from C driver: https://github.com/BoschSensortec/BMP3-Sensor-API/blob/master/bmp3.c
 	partialData1 := rawTemp - (256 * int64(d.cali.t1))
 	partialData2 := int64(d.cali.t2) * partialData1
 	partialData3 := (partialData1 * partialData1)
 	partialData4 := partialData3 * int64(d.cali.t3)
 	partialData5 := (partialData2 * 262144) + partialData4
-	return partialData5 / 4294967296, nil
-
+	return partialData5 / 4294967296
 }
 
 // ReadTemperature returns the temperature in centicelsius, i.e 2426 / 100 = 24.26 C
@@ -157,7 +161,12 @@ func (d *Device) ReadPressure() (int32, error) {
 	if err != nil {
 		return 0, err
 	}
+	return d.compensatePressure(tlin, rawPress), nil
+}
 
+// compensatePressure returns the pressure in centipascals from a raw pressure reading and the result of
+// compensateTemperature.
+func (d *Device) compensatePressure(tlin, rawPress int64) int32 {
 	// code pulled from bmp388 C driver: https://github.com/BoschSensortec/BMP3-Sensor-API/blob/master/bmp3.c
 	partialData1 := tlin * tlin
 	partialData2 := partialData1 / 64
@@ -184,7 +193,7 @@ func (d *Device) ReadPressure() (int32, error) {
 	partialData3 = (partialData2 * rawPress) / 128
 	partialData4 = (offset / 4) + partialData1 + partialData5 + partialData3
 	compPress := ((uint64(partialData4) * 25) / uint64(1099511627776))
-	return int32(compPress), nil
+	return int32(compPress)
 }
 
 // SoftReset commands the BMP388 to reset of all user configuration settings
@@ -196,11 +205,12 @@ func (d *Device) SoftReset() error {
 	return nil
 }
 
-// Connected tries to reach the bmp388 and check its chip id register. Returns true if it was able to successfully
+// Connected tries to reach the bmp388 (or bmp390) and check its chip id register. Returns true if it was able to successfully
 // communicate over i2c and returns the correct value
 func (d *Device) Connected() bool {
 	data, err := d.readRegister(RegChipId, 1)
-	return err == nil && data[0] == ChipId // returns true if i2c comm was good and response equals 0x50
+	// returns true if i2c comm was good and response equals 0x50 (BMP388) or 0x60 (BMP390)
+	return err == nil && (data[0] == ChipId || data[0] == ChipIdBMP390)
 }
 
 // SetMode changes the run mode of the sensor, NORMAL is the one to use for most cases. Use FORCED if you plan to take
@@ -0,0 +1,188 @@
+package bmp388
+
+import (
+	"errors"
+
+	"tinygo.org/x/drivers/internal/legacy"
+)
+
+var errFIFOConfig = errors.New("bmp388: FIFO configuration error")
+
+// fifoSize is the size of the FIFO in bytes.
+const fifoSize = 512
+
+// FIFO frame headers, see section 3.6 of the datasheet.
+const (
+	fifoHeaderPressTemp  = 0x94
+	fifoHeaderTemp       = 0x90
+	fifoHeaderPress      = 0x84
+	fifoHeaderSensorTime = 0xA0
+	fifoHeaderEmpty      = 0x80
+	fifoHeaderConfigErr  = 0x44
+	fifoHeaderConfigChg  = 0x48
+)
+
+// FIFOConfig contains the settings for the FIFO. The sensor must be in normal mode for the FIFO to fill.
+type FIFOConfig struct {
+	// Which measurements to store in the FIFO. When neither is set, the FIFO is disabled.
+	Pressure    bool
+	Temperature bool
+
+	// Stop storing new frames when the FIFO is full, instead of dropping the oldest ones.
+	StopOnFull bool
+
+	// Only store every 2^Subsampling-th measurement (0-7).
+	Subsampling uint8
+
+	// Store the IIR filtered data instead of the unfiltered data.
+	Filtered bool
+
+	// Watermark in bytes at which the watermark interrupt fires. A frame with both pressure and temperature takes 7
+	// bytes. Zero disables the watermark interrupt.
+	Watermark uint16
+}
+
+// FIFOSample is a single measurement read from the FIFO, in the same units as ReadTemperature and ReadPressure.
+type FIFOSample struct {
+	Temperature int32 // centicelsius
+	Pressure    int32 // centipascals
+}
+
+// ConfigureFIFO flushes the FIFO and configures it according to cfg. The watermark interrupt, if enabled, is
+// signaled on the INT pin as an active high push-pull output.
+func (d *Device) ConfigureFIFO(cfg FIFOConfig) error {
+	err := d.FlushFIFO()
+	if err != nil {
+		return err
+	}
+
+	var config1 byte
+	if cfg.Pressure || cfg.Temperature {
+		config1 |= 0x01 // fifo_mode
+	}
+	if cfg.StopOnFull {
+		config1 |= 0x02
+	}
+	if cfg.Pressure {
+		config1 |= 0x08
+	}
+	if cfg.Temperature {
+		config1 |= 0x10
+	}
+	config2 := cfg.Subsampling & 0x07
+	if cfg.Filtered {
+		config2 |= 0x08
+	}
+
+	err = legacy.WriteRegister(d.bus, d.Address, RegFIFOWtm, []byte{byte(cfg.Watermark), byte(cfg.Watermark>>8) & 0x01})
+	if err != nil {
+		return err
+	}
+	err = d.writeRegister(RegFIFOConfig2, config2)
+	if err != nil {
+		return err
+	}
+	err = d.writeRegister(RegFIFOConfig1, config1)
+	if err != nil {
+		return err
+	}
+
+	var intCtrl byte = 0x02 // int_level: active high
+	if cfg.Watermark != 0 {
+		intCtrl |= 0x08 // fwtm_en
+	}
+	err = d.writeRegister(RegIntCtrl, intCtrl)
+	if err != nil {
+		return err
+	}
+
+	if d.configurationError() {
+		return errFIFOConfig
+	}
+	return nil
+}
+
+// FlushFIFO discards all data in the FIFO.
+func (d *Device) FlushFIFO() error {
+	return d.writeRegister(RegCmd, FIFOFlush)
+}
+
+// FIFOLength returns the number of bytes stored in the FIFO.
+func (d *Device) FIFOLength() (int, error) {
+	data, err := d.readRegister(RegFIFOLength, 2)
+	if err != nil {
+		return 0, err
+	}
+	return int(uint16(data[0]) | uint16(data[1]&0x01)<<8), nil
+}
+
+// ReadFIFO reads the FIFO in a single burst and stores the compensated measurements in samples. It returns the number
+// of samples stored. Frames that do not fit in samples are discarded, a FIFO holds at most 73 frames with both
+// pressure and temperature.
+//
+// Frames with only pressure are compensated with the temperature of the last frame that had one, or with the current
+// temperature if there is none.
+func (d *Device) ReadFIFO(samples []FIFOSample) (int, error) {
+	length, err := d.FIFOLength()
+	if err != nil || length == 0 {
+		return 0, err
+	}
+
+	var buf [fifoSize]byte
+	data := buf[:length]
+	err = legacy.ReadRegister(d.bus, d.Address, RegFIFOData, data)
+	if err != nil {
+		return 0, err
+	}
+
+	var tlin int64
+	haveTemp := false
+	n := 0
+	for len(data) > 0 && n < len(samples) {
+		header := data[0]
+		data = data[1:]
+		switch header {
+		case fifoHeaderPressTemp, fifoHeaderTemp, fifoHeaderPress:
+			size := 3
+			if header == fifoHeaderPressTemp {
+				size = 6
+			}
+			if len(data) < size {
+				return n, nil // incomplete frame
+			}
+			if header != fifoHeaderPress {
+				tlin = d.compensateTemperature(int64(data[2])<<16 | int64(data[1])<<8 | int64(data[0]))
+				haveTemp = true
+				data = data[3:]
+			} else if !haveTemp {
+				tlin, err = d.tlinCompensate()
+				if err != nil {
+					return n, err
+				}
+				haveTemp = true
+			}
+			s := &samples[n]
+			s.Temperature = int32((tlin * 25) / 16384)
+			s.Pressure = 0
+			if header != fifoHeaderTemp {
+				s.Pressure = d.compensatePressure(tlin, int64(data[2])<<16|int64(data[1])<<8|int64(data[0]))
+				data = data[3:]
+			}
+			n++
+		case fifoHeaderSensorTime:
+			if len(data) < 3 {
+				return n, nil
+			}
+			data = data[3:]
+		case fifoHeaderConfigErr, fifoHeaderConfigChg:
+			if len(data) < 1 {
+				return n, nil
+			}
+			data = data[1:]
+		default:
+			// Empty frame or unknown header: nothing more to parse.
+			return n, nil
+		}
+	}
+	return n, nil
+}
@@ -0,0 +1,89 @@
+package bmp388
+
+import (
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+	"tinygo.org/x/drivers/tester"
+)
+
+// rawTemp and rawPress are raw readings of about 26°C and 988hPa with the
+// calibration below.
+var (
+	rawTemp  = []byte{0x00, 0x50, 0x83}
+	rawPress = []byte{0x00, 0x30, 0x6B}
+)
+
+func newSensor(c *qt.C) (*Device, *tester.I2CDevice8) {
+	bus := tester.NewI2CBus(c)
+	fake := bus.NewDevice(Address)
+	fake.Registers[RegChipId] = ChipIdBMP390
+	copy(fake.Registers[RegCali:], []byte{
+		0x98, 0x6C, 0xA9, 0x4A, 0xF9, 0xE3, 0x1C, 0x61, 0x16, 0x06, 0x01,
+		0x51, 0x4A, 0xDE, 0x5D, 0x03, 0xFA, 0xF9, 0x0E, 0x06, 0xF5,
+	})
+	dev := New(bus)
+	c.Assert(dev.Configure(Config{}), qt.IsNil)
+	copy(fake.Registers[RegPress:], rawPress)
+	copy(fake.Registers[RegTemp:], rawTemp)
+	return &dev, fake
+}
+
+// frames stores FIFO frames in the fake sensor.
+func frames(fake *tester.I2CDevice8, data ...[]byte) {
+	n := 0
+	for _, f := range data {
+		n += copy(fake.Registers[int(RegFIFOData)+n:], f)
+	}
+	fake.Registers[RegFIFOLength] = byte(n)
+	fake.Registers[RegFIFOLength+1] = byte(n >> 8)
+}
+
+func cat(b ...[]byte) []byte {
+	var out []byte
+	for _, p := range b {
+		out = append(out, p...)
+	}
+	return out
+}
+
+func TestReadFIFO(t *testing.T) {
+	c := qt.New(t)
+	dev, fake := newSensor(c)
+	temp, err := dev.ReadTemperature()
+	c.Assert(err, qt.IsNil)
+	press, err := dev.ReadPressure()
+	c.Assert(err, qt.IsNil)
+	c.Assert(temp > 2000 && temp < 3000, qt.IsTrue, qt.Commentf("temperature %d", temp))
+	c.Assert(press > 9000000 && press < 11000000, qt.IsTrue, qt.Commentf("pressure %d", press))
+
+	// The frames hold the same readings as the data registers.
+	frames(fake,
+		cat([]byte{fifoHeaderPressTemp}, rawTemp, rawPress),
+		[]byte{fifoHeaderSensorTime, 0x12, 0x34, 0x56},
+		cat([]byte{fifoHeaderPress}, rawPress),
+		cat([]byte{fifoHeaderTemp}, rawTemp),
+		[]byte{fifoHeaderConfigChg, 0x00},
+		[]byte{fifoHeaderEmpty, 0x00},
+	)
+	samples := make([]FIFOSample, 5)
+	n, err := dev.ReadFIFO(samples)
+	c.Assert(err, qt.IsNil)
+	c.Assert(samples[:n], qt.DeepEquals, []FIFOSample{
+		{Temperature: temp, Pressure: press},
+		{Temperature: temp, Pressure: press},
+		{Temperature: temp},
+	})
+
+	// Frames that don't fit are dropped.
+	n, err = dev.ReadFIFO(samples[:1])
+	c.Assert(err, qt.IsNil)
+	c.Assert(n, qt.Equals, 1)
+
+	// A pressure frame without temperature uses the current one, an
+	// incomplete frame ends the data.
+	frames(fake, cat([]byte{fifoHeaderPress}, rawPress), []byte{fifoHeaderPressTemp, 0x00})
+	n, err = dev.ReadFIFO(samples)
+	c.Assert(err, qt.IsNil)
+	c.Assert(samples[:n], qt.DeepEquals, []FIFOSample{{Temperature: temp, Pressure: press}})
+}
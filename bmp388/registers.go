@@ -1,5 +1,7 @@
-// Package bmp388 provides a driver for Bosch's BMP388 digital temperature & pressure sensor.
-// The datasheet can be found here: https://www.bosch-sensortec.com/media/boschsensortec/downloads/datasheets/bst-bmp388-ds001.pdf
+// Package bmp388 provides a driver for Bosch's BMP388 and BMP390 digital temperature & pressure sensors.
+// The datasheets can be found here:
+// https://www.bosch-sensortec.com/media/boschsensortec/downloads/datasheets/bst-bmp388-ds001.pdf
+// https://www.bosch-sensortec.com/media/boschsensortec/downloads/datasheets/bst-bmp390-ds002.pdf
 package bmp388
 
 const Address byte = 0x77 // default I2C address
@@ -16,15 +18,25 @@ const (
 	RegStat    byte = 0x03 // sensor status register
 	RegErr     byte = 0x02 // error status register
 	RegIIR     byte = 0x1F
+
+	RegFIFOLength  byte = 0x12 // number of bytes in the FIFO (2 bytes)
+	RegFIFOData    byte = 0x14 // FIFO read out register
+	RegFIFOWtm     byte = 0x15 // FIFO watermark in bytes (2 bytes)
+	RegFIFOConfig1 byte = 0x17 // FIFO enable and frame content
+	RegFIFOConfig2 byte = 0x18 // FIFO subsampling and data source
+	RegIntCtrl     byte = 0x19 // interrupt pin configuration
+	RegIntStatus   byte = 0x11 // interrupt status, cleared on read
 )
 
 const (
-	ChipId    byte = 0x50 // correct response if reading from chip id register
-	PwrPress  byte = 0x01 // power on pressure sensor
-	PwrTemp   byte = 0x02 // power on temperature sensor
-	SoftReset byte = 0xB6 // command to reset all user configuration
-	DRDYPress byte = 0x20 // for checking if pressure data is ready
-	DRDYTemp  byte = 0x40 // for checking if pressure data is ready
+	ChipId       byte = 0x50 // correct response if reading from chip id register
+	ChipIdBMP390 byte = 0x60 // chip id of the BMP390
+	PwrPress     byte = 0x01 // power on pressure sensor
+	PwrTemp      byte = 0x02 // power on temperature sensor
+	SoftReset    byte = 0xB6 // command to reset all user configuration
+	DRDYPress    byte = 0x20 // for checking if pressure data is ready
+	DRDYTemp     byte = 0x40 // for checking if pressure data is ready
+	FIFOFlush    byte = 0xB0 // command to clear all data in the FIFO
 )
 
 // The difference between forced and normal mode is the bmp388 goes to sleep after taking a measurement in forced mode.
@@ -0,0 +1,184 @@
+// Package cap1188 implements a driver for the CAP1188 8-channel capacitive
+// touch controller, using I2C.
+//
+// Datasheet: https://ww1.microchip.com/downloads/en/DeviceDoc/CAP1188%20.pdf
+package cap1188 // import "tinygo.org/x/drivers/cap1188"
+
+import (
+	"sync/atomic"
+
+	"tinygo.org/x/drivers"
+	"tinygo.org/x/drivers/internal/legacy"
+)
+
+// Config holds the channel configuration.
+type Config struct {
+	// Channels to enable as a bitmask. Defaults to all 8.
+	Channels uint8
+
+	// Threshold is the delta count for a touch, applied to all channels.
+	// Defaults to 64. Use SetThreshold to configure channels individually.
+	Threshold uint8
+
+	// Sensitivity from 1 (64x) to 7 (least sensitive, 1x). Defaults to 2
+	// (32x), the power-on value.
+	Sensitivity uint8
+
+	Gain Gain
+
+	// MultiTouch allows more than one channel to be touched at the same
+	// time, which is needed for sliders.
+	MultiTouch bool
+
+	// LinkLEDs makes every LED output follow its touch channel.
+	LinkLEDs bool
+}
+
+// Device wraps an I2C connection to a CAP1188 device.
+type Device struct {
+	bus     drivers.I2C
+	Address uint8
+	gain    Gain
+
+	irq     bool // interrupt enabled
+	pending uint32
+	last    uint8
+
+	onTouch   func(channel uint8)
+	onRelease func(channel uint8)
+}
+
+// New creates a new CAP1188 connection. The I2C bus must already be
+// configured.
+//
+// This function only creates the Device object, it does not touch the device.
+func New(bus drivers.I2C) *Device {
+	return &Device{bus: bus, Address: Address}
+}
+
+// Connected returns whether a CAP1188 has been found.
+func (d *Device) Connected() bool {
+	var id [2]byte
+	err := legacy.ReadRegister(d.bus, d.Address, PRODUCT_ID, id[:])
+	return err == nil && id[0] == 0x50 && id[1] == 0x5D
+}
+
+// Configure sets up the channels and starts a calibration.
+func (d *Device) Configure(cfg Config) error {
+	if cfg.Channels == 0 {
+		cfg.Channels = 0xFF
+	}
+	if cfg.Threshold == 0 {
+		cfg.Threshold = 0x40
+	}
+	if cfg.Sensitivity == 0 {
+		cfg.Sensitivity = 2
+	}
+	d.gain = cfg.Gain
+
+	multi := uint8(0x80) // block multiple touches
+	if cfg.MultiTouch {
+		multi = 0x00
+	}
+	var leds uint8
+	if cfg.LinkLEDs {
+		leds = cfg.Channels
+	}
+	regs := [...][2]uint8{
+		{MAIN_CONTROL, uint8(cfg.Gain) << 6},
+		{SENSOR_INPUT_ENABLE, cfg.Channels},
+		{INTERRUPT_ENABLE, cfg.Channels},
+		// Only interrupt on touch and release, not while held.
+		{REPEAT_RATE_ENABLE, 0x00},
+		{MULTIPLE_TOUCH_CONFIG, multi},
+		{SENSITIVITY_CONTROL, (cfg.Sensitivity&0x07)<<4 | 0x0F},
+		{LED_LINKING, leds},
+	}
+	for _, r := range regs {
+		if err := d.write(r[0], r[1]); err != nil {
+			return err
+		}
+	}
+	for i := uint8(0); i < Channels; i++ {
+		if err := d.SetThreshold(i, cfg.Threshold); err != nil {
+			return err
+		}
+	}
+	return d.Calibrate(cfg.Channels)
+}
+
+// SetThreshold sets the delta count for a touch on a channel.
+func (d *Device) SetThreshold(channel, threshold uint8) error {
+	return d.write(SENSOR_INPUT_THRESHOLD1+channel, threshold&0x7F)
+}
+
+// Calibrate starts a calibration of the channels in the mask. The device
+// also recalibrates automatically to track slow changes of the baseline.
+func (d *Device) Calibrate(channels uint8) error {
+	return d.write(CALIBRATION_ACTIVATE, channels)
+}
+
+// Touched returns the touch status of all channels as a bitmask, and clears
+// the interrupt.
+func (d *Device) Touched() (uint8, error) {
+	status, err := d.read(SENSOR_INPUT_STATUS)
+	if err != nil {
+		return 0, err
+	}
+	// The status bits and the ALERT pin stay set until the INT bit is
+	// cleared.
+	return status, d.write(MAIN_CONTROL, uint8(d.gain)<<6)
+}
+
+// Delta returns the delta count of a channel, the difference between the
+// current measurement and the baseline.
+func (d *Device) Delta(channel uint8) (int8, error) {
+	v, err := d.read(SENSOR_DELTA_1 + channel)
+	return int8(v), err
+}
+
+// SetCallbacks sets the functions called by Update when a channel is touched
+// or released. Either may be nil.
+func (d *Device) SetCallbacks(touched, released func(channel uint8)) {
+	d.onTouch = touched
+	d.onRelease = released
+}
+
+// Update reads the touch status and calls the touched and released callbacks
+// for every channel that changed. It should be called regularly from the
+// main loop; the callbacks are never called from an interrupt. When an ALERT
+// pin is enabled, the bus is only accessed after the pin fired.
+func (d *Device) Update() error {
+	if d.irq && atomic.SwapUint32(&d.pending, 0) == 0 {
+		return nil
+	}
+	touched, err := d.Touched()
+	if err != nil {
+		return err
+	}
+	changed := touched ^ d.last
+	d.last = touched
+	for i := uint8(0); i < Channels; i++ {
+		if changed&(1<<i) == 0 {
+			continue
+		}
+		if touched&(1<<i) != 0 {
+			if d.onTouch != nil {
+				d.onTouch(i)
+			}
+		} else if d.onRelease != nil {
+			d.onRelease(i)
+		}
+	}
+	return nil
+}
+
+func (d *Device) read(reg uint8) (uint8, error) {
+	var data [1]byte
+	err := legacy.ReadRegister(d.bus, d.Address, reg, data[:])
+	return data[0], err
+}
+
+func (d *Device) write(reg, value uint8) error {
+	return legacy.WriteRegister(d.bus, d.Address, reg, []byte{value})
+}
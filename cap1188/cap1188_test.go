@@ -0,0 +1,60 @@
+package cap1188
+
+import (
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+	"tinygo.org/x/drivers/tester"
+)
+
+func TestUpdate(t *testing.T) {
+	c := qt.New(t)
+	bus := tester.NewI2CBus(c)
+	fake := bus.NewDevice(Address)
+	fake.Registers[PRODUCT_ID] = 0x50
+	fake.Registers[PRODUCT_ID+1] = 0x5D
+
+	dev := New(bus)
+	c.Assert(dev.Connected(), qt.IsTrue)
+	c.Assert(dev.Configure(Config{Channels: 0x0F, Gain: Gain4, MultiTouch: true, LinkLEDs: true}), qt.IsNil)
+	c.Assert(fake.Registers[SENSOR_INPUT_ENABLE], qt.Equals, uint8(0x0F))
+	c.Assert(fake.Registers[MULTIPLE_TOUCH_CONFIG], qt.Equals, uint8(0x00))
+	c.Assert(fake.Registers[SENSITIVITY_CONTROL], qt.Equals, uint8(0x2F))
+	c.Assert(fake.Registers[LED_LINKING], qt.Equals, uint8(0x0F))
+	c.Assert(fake.Registers[SENSOR_INPUT_THRESHOLD1+7], qt.Equals, uint8(0x40))
+	c.Assert(fake.Registers[CALIBRATION_ACTIVATE], qt.Equals, uint8(0x0F))
+
+	var touched, released []uint8
+	dev.SetCallbacks(func(ch uint8) {
+		touched = append(touched, ch)
+	}, func(ch uint8) {
+		released = append(released, ch)
+	})
+
+	// CS2 and CS4 touched. Reading the status clears the INT bit.
+	fake.Registers[MAIN_CONTROL] |= 0x01
+	fake.Registers[SENSOR_INPUT_STATUS] = 0x0A
+	c.Assert(dev.Update(), qt.IsNil)
+	c.Assert(touched, qt.DeepEquals, []uint8{1, 3})
+	c.Assert(released, qt.IsNil)
+	c.Assert(fake.Registers[MAIN_CONTROL], qt.Equals, uint8(Gain4)<<6)
+
+	// CS2 released, CS8 touched.
+	touched = nil
+	fake.Registers[SENSOR_INPUT_STATUS] = 0x88
+	c.Assert(dev.Update(), qt.IsNil)
+	c.Assert(touched, qt.DeepEquals, []uint8{7})
+	c.Assert(released, qt.DeepEquals, []uint8{1})
+}
+
+func TestDelta(t *testing.T) {
+	c := qt.New(t)
+	bus := tester.NewI2CBus(c)
+	fake := bus.NewDevice(Address)
+	fake.Registers[SENSOR_DELTA_1+2] = 0xF0
+
+	dev := New(bus)
+	v, err := dev.Delta(2)
+	c.Assert(err, qt.IsNil)
+	c.Assert(v, qt.Equals, int8(-16))
+}
@@ -0,0 +1,19 @@
+//go:build tinygo
+
+package cap1188
+
+import (
+	"machine"
+	"sync/atomic"
+)
+
+// EnableInterrupt uses the ALERT pin to avoid reading the touch status when
+// nothing changed. The pin is active low and open drain.
+func (d *Device) EnableInterrupt(alert machine.Pin) error {
+	d.irq = true
+	atomic.StoreUint32(&d.pending, 1)
+	alert.Configure(machine.PinConfig{Mode: machine.PinInputPullup})
+	return alert.SetInterrupt(machine.PinFalling, func(machine.Pin) {
+		atomic.StoreUint32(&d.pending, 1)
+	})
+}
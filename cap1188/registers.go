@@ -0,0 +1,43 @@
+package cap1188
+
+// The I2C address which this device listens to by default (ADDR_COMM pin
+// pulled to VDD with 0Ω). Other options are 0x28, 0x2A, 0x2B and 0x2C.
+const Address = 0x29
+
+// Registers.
+const (
+	MAIN_CONTROL            = 0x00
+	GENERAL_STATUS          = 0x02
+	SENSOR_INPUT_STATUS     = 0x03
+	SENSOR_DELTA_1          = 0x10 // one signed byte per channel
+	SENSITIVITY_CONTROL     = 0x1F
+	CONFIGURATION           = 0x20
+	SENSOR_INPUT_ENABLE     = 0x21
+	SENSOR_INPUT_CONFIG     = 0x22
+	SENSOR_INPUT_CONFIG2    = 0x23
+	AVERAGING_SAMPLING      = 0x24
+	CALIBRATION_ACTIVATE    = 0x26
+	INTERRUPT_ENABLE        = 0x27
+	REPEAT_RATE_ENABLE      = 0x28
+	MULTIPLE_TOUCH_CONFIG   = 0x2A
+	RECALIBRATION_CONFIG    = 0x2F
+	SENSOR_INPUT_THRESHOLD1 = 0x30 // one byte per channel
+	CONFIGURATION2          = 0x44
+	LED_LINKING             = 0x72
+	PRODUCT_ID              = 0xFD
+	MANUFACTURER_ID         = 0xFE
+	REVISION                = 0xFF
+)
+
+// Channels is the number of touch channels.
+const Channels = 8
+
+// Gain of the sensor inputs.
+type Gain uint8
+
+const (
+	Gain1 Gain = 0
+	Gain2 Gain = 1
+	Gain4 Gain = 2
+	Gain8 Gain = 3
+)
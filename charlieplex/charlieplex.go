@@ -0,0 +1,207 @@
+// Package charlieplex drives charlieplexed LED matrices directly from GPIO
+// pins, without a driver chip.
+//
+// With n pins, up to n*(n-1) LEDs can be connected: one between every
+// ordered pair of pins. Only one anode pin is driven at a time, so the LEDs
+// are multiplexed by calling Tick at a steady rate, preferably from a timer
+// interrupt. Each LED has its own brightness, made by lighting it only in
+// some of the PWM slots of a refresh cycle.
+//
+// Every pin needs a series resistor, as the current of all LEDs of an anode
+// flows through its pin.
+//
+// Background: https://en.wikipedia.org/wiki/Charlieplexing
+package charlieplex // import "tinygo.org/x/drivers/charlieplex"
+
+import (
+	"errors"
+	"image/color"
+)
+
+var (
+	errTooManyLEDs = errors.New("charlieplex: too many LEDs for the number of pins")
+	errTooManyPins = errors.New("charlieplex: at most 32 pins are supported")
+	errLayout      = errors.New("charlieplex: layout does not match the pins")
+)
+
+// LED is a single LED, connected between two pins given by their index.
+type LED struct {
+	Anode   uint8
+	Cathode uint8
+}
+
+// Layout maps the pixels of the display to the LEDs. LEDs holds Width*Height
+// LEDs in row order.
+type Layout struct {
+	Width  int16
+	Height int16
+	LEDs   []LED
+}
+
+// GridLayout returns the layout with all LEDs of the given number of pins.
+// Every row has one anode, and the pixels are the other pins in increasing
+// order, so the display is pins-1 wide and pins high.
+func GridLayout(pins int) Layout {
+	l := Layout{
+		Width:  int16(pins - 1),
+		Height: int16(pins),
+		LEDs:   make([]LED, 0, pins*(pins-1)),
+	}
+	for a := 0; a < pins; a++ {
+		for c := 0; c < pins; c++ {
+			if c != a {
+				l.LEDs = append(l.LEDs, LED{Anode: uint8(a), Cathode: uint8(c)})
+			}
+		}
+	}
+	return l
+}
+
+// FitLayout returns a width by height layout using the LEDs of GridLayout in
+// order, for example a 5x5 display on 6 pins like the micro:bit. Unused LEDs
+// are left out.
+func FitLayout(pins int, width, height int16) (Layout, error) {
+	n := int(width) * int(height)
+	if n > pins*(pins-1) {
+		return Layout{}, errTooManyLEDs
+	}
+	l := GridLayout(pins)
+	return Layout{Width: width, Height: height, LEDs: l.LEDs[:n]}, nil
+}
+
+// Config is the configuration of the display.
+type Config struct {
+	Layout Layout
+
+	// Levels is the number of PWM slots, and so the number of brightness
+	// levels above off. More levels need a faster Tick for the same refresh
+	// rate. Defaults to 8.
+	Levels uint8
+}
+
+// Device is a charlieplexed LED matrix.
+type Device struct {
+	pins   []pin
+	layout Layout
+	levels uint8
+	bright []uint8 // brightness of every LED, 0 to levels
+	anodes []uint8 // pins used as an anode by the layout
+
+	// Multiplexing state, only touched by Tick.
+	anode  int
+	slot   uint8
+	driven uint32 // pins currently driven
+}
+
+// configure checks the layout and resets the display state.
+func (d *Device) configure(cfg Config) error {
+	if len(d.pins) > 32 {
+		return errTooManyPins
+	}
+	l := cfg.Layout
+	if len(l.LEDs) != int(l.Width)*int(l.Height) {
+		return errLayout
+	}
+	var used uint32
+	for _, led := range l.LEDs {
+		if int(led.Anode) >= len(d.pins) || int(led.Cathode) >= len(d.pins) || led.Anode == led.Cathode {
+			return errLayout
+		}
+		used |= 1 << led.Anode
+	}
+	d.anodes = d.anodes[:0]
+	for i := range d.pins {
+		if used&(1<<i) != 0 {
+			d.anodes = append(d.anodes, uint8(i))
+		}
+	}
+
+	d.layout = l
+	d.levels = cfg.Levels
+	if d.levels == 0 {
+		d.levels = 8
+	}
+	d.bright = make([]uint8, len(l.LEDs))
+	d.anode = 0
+	d.slot = 0
+	return nil
+}
+
+// Size returns the size of the display in pixels.
+func (d *Device) Size() (x, y int16) {
+	return d.layout.Width, d.layout.Height
+}
+
+// SetPixel sets the brightness of a pixel to the brightest of the red, green
+// and blue channels of c. The LED changes at the next refresh cycle.
+func (d *Device) SetPixel(x, y int16, c color.RGBA) {
+	v := c.R
+	if c.G > v {
+		v = c.G
+	}
+	if c.B > v {
+		v = c.B
+	}
+	d.SetLevel(x, y, uint8((uint16(v)*uint16(d.levels)+127)/255))
+}
+
+// SetLevel sets the brightness of a pixel, from 0 (off) to Levels (always
+// on).
+func (d *Device) SetLevel(x, y int16, level uint8) {
+	if x < 0 || y < 0 || x >= d.layout.Width || y >= d.layout.Height {
+		return
+	}
+	if level > d.levels {
+		level = d.levels
+	}
+	d.bright[int(y)*int(d.layout.Width)+int(x)] = level
+}
+
+// Level returns the brightness of a pixel.
+func (d *Device) Level(x, y int16) uint8 {
+	if x < 0 || y < 0 || x >= d.layout.Width || y >= d.layout.Height {
+		return 0
+	}
+	return d.bright[int(y)*int(d.layout.Width)+int(x)]
+}
+
+// Levels returns the number of brightness levels above off.
+func (d *Device) Levels() uint8 {
+	return d.levels
+}
+
+// Clear turns off all pixels.
+func (d *Device) Clear() {
+	for i := range d.bright {
+		d.bright[i] = 0
+	}
+}
+
+// Display does nothing, as the pixels are shown by Tick. It is there to
+// implement drivers.Displayer.
+func (d *Device) Display() error {
+	return nil
+}
+
+// step moves to the next anode, and to the next PWM slot after the last
+// anode. It returns the anode pin and the cathode pins to drive.
+func (d *Device) step() (anode uint8, cathodes uint32) {
+	if len(d.anodes) == 0 {
+		return 0, 0
+	}
+	d.anode++
+	if d.anode == len(d.anodes) {
+		d.anode = 0
+		d.slot++
+		if d.slot == d.levels {
+			d.slot = 0
+		}
+	}
+	anode = d.anodes[d.anode]
+	for i, led := range d.layout.LEDs {
+		if led.Anode == anode && d.bright[i] > d.slot {
+			cathodes |= 1 << led.Cathode
+		}
+	}
+	return anode, cathodes
+}
@@ -0,0 +1,67 @@
+package charlieplex
+
+import (
+	"image/color"
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+)
+
+func TestLayout(t *testing.T) {
+	c := qt.New(t)
+
+	l := GridLayout(3)
+	c.Assert(l.Width, qt.Equals, int16(2))
+	c.Assert(l.Height, qt.Equals, int16(3))
+	c.Assert(l.LEDs, qt.DeepEquals, []LED{{0, 1}, {0, 2}, {1, 0}, {1, 2}, {2, 0}, {2, 1}})
+
+	l, err := FitLayout(6, 5, 5)
+	c.Assert(err, qt.IsNil)
+	c.Assert(l.LEDs, qt.HasLen, 25)
+	c.Assert(l.LEDs[24], qt.Equals, LED{4, 5})
+
+	_, err = FitLayout(5, 5, 5)
+	c.Assert(err, qt.Equals, errTooManyLEDs)
+}
+
+func TestConfigure(t *testing.T) {
+	c := qt.New(t)
+
+	d := &Device{pins: make([]pin, 3)}
+	c.Assert(d.configure(Config{Layout: GridLayout(4)}), qt.Equals, errLayout)
+
+	l := GridLayout(3)
+	l.Height = 2
+	c.Assert(d.configure(Config{Layout: l}), qt.Equals, errLayout)
+}
+
+func TestStep(t *testing.T) {
+	c := qt.New(t)
+
+	l, err := FitLayout(3, 2, 2)
+	c.Assert(err, qt.IsNil)
+	d := &Device{pins: make([]pin, 3)}
+	c.Assert(d.configure(Config{Layout: l, Levels: 4}), qt.IsNil)
+	c.Assert(d.anodes, qt.DeepEquals, []uint8{0, 1})
+
+	d.SetLevel(0, 0, 4)                               // anode 0, cathode 1
+	d.SetLevel(1, 0, 1)                               // anode 0, cathode 2
+	d.SetPixel(1, 1, color.RGBA{R: 10, G: 128, B: 3}) // anode 1, cathode 2
+	c.Assert(d.Level(1, 1), qt.Equals, uint8(2))
+
+	// Count in how many ticks of a refresh cycle each LED is on.
+	on := map[LED]int{}
+	for i := 0; i < len(d.anodes)*int(d.levels); i++ {
+		anode, cathodes := d.step()
+		for k := uint8(0); k < 3; k++ {
+			if cathodes&(1<<k) != 0 {
+				on[LED{anode, k}]++
+			}
+		}
+	}
+	c.Assert(on, qt.DeepEquals, map[LED]int{{0, 1}: 4, {0, 2}: 1, {1, 2}: 2})
+
+	d.Clear()
+	_, cathodes := d.step()
+	c.Assert(cathodes, qt.Equals, uint32(0))
+}
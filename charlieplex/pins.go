@@ -0,0 +1,80 @@
+//go:build tinygo
+
+package charlieplex
+
+import (
+	"machine"
+	"time"
+)
+
+type pin = machine.Pin
+
+// New returns a display on the given pins. The pin indexes of the layout
+// refer to this slice.
+//
+// This function only creates the Device object, it does not touch the pins.
+func New(pins []machine.Pin) *Device {
+	return &Device{pins: pins}
+}
+
+// Configure checks the layout and turns off all LEDs.
+func (d *Device) Configure(cfg Config) error {
+	if err := d.configure(cfg); err != nil {
+		return err
+	}
+	for _, p := range d.pins {
+		p.Configure(machine.PinConfig{Mode: machine.PinInput})
+	}
+	d.driven = 0
+	return nil
+}
+
+// Tick lights the LEDs of the next anode for the current PWM slot. A full
+// refresh of the display takes one Tick per anode per brightness level, so
+// for a flicker free display of 5 anodes and 8 levels Tick must be called
+// at least every 250µs. It is safe to call from an interrupt.
+func (d *Device) Tick() {
+	anode, cathodes := d.step()
+
+	// Release the previous pins first, so that no LED of the next anode
+	// lights up with the previous cathodes.
+	d.release()
+	if cathodes == 0 {
+		return
+	}
+	p := d.pins[anode]
+	p.Configure(machine.PinConfig{Mode: machine.PinOutput})
+	p.High()
+	for i, p := range d.pins {
+		if cathodes&(1<<i) != 0 {
+			p.Configure(machine.PinConfig{Mode: machine.PinOutput})
+			p.Low()
+		}
+	}
+	d.driven = cathodes | 1<<anode
+}
+
+// Off turns off all LEDs until the next Tick.
+func (d *Device) Off() {
+	d.release()
+}
+
+// Run calls Tick every interval, forever. It is meant to be run in its own
+// goroutine when no timer interrupt is available; the refresh is then only
+// as steady as the scheduler.
+func (d *Device) Run(interval time.Duration) {
+	for {
+		d.Tick()
+		time.Sleep(interval)
+	}
+}
+
+// release puts the driven pins back in high impedance.
+func (d *Device) release() {
+	for i, p := range d.pins {
+		if d.driven&(1<<i) != 0 {
+			p.Configure(machine.PinConfig{Mode: machine.PinInput})
+		}
+	}
+	d.driven = 0
+}
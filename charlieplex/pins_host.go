@@ -0,0 +1,7 @@
+//go:build !tinygo
+
+package charlieplex
+
+// pin stands in for machine.Pin, so that the multiplexing can be tested with
+// go test.
+type pin uint8
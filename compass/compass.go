@@ -0,0 +1,232 @@
+// Package compass provides magnetometer calibration and tilt-compensated
+// heading calculation. It works with the magnetic field and acceleration
+// readings of any driver in this repository, such as lis2mdl, lsm303agr and
+// qmc5883.
+//
+// A magnetometer mounted on a board sees both the earth's magnetic field and
+// the field of the board itself. Fixed magnets and magnetized parts add a
+// constant offset (hard-iron distortion), while nearby ferrous material
+// stretches the field differently per axis (soft-iron distortion). Both are
+// removed by collecting readings while the user slowly rotates the device in
+// all directions, and fitting those readings to a sphere.
+package compass // import "tinygo.org/x/drivers/compass"
+
+import (
+	"math"
+	"time"
+)
+
+// Calibration holds the hard-iron offsets and soft-iron scale factors of a
+// magnetometer. The zero value is not usable, use Identity for an
+// uncalibrated device.
+type Calibration struct {
+	// Offset is subtracted from the raw reading, in the unit of the reading.
+	Offset [3]int32
+
+	// Scale is applied after removing the offset, in 1/65536.
+	Scale [3]int32
+}
+
+// Identity is the calibration which leaves readings unchanged.
+var Identity = Calibration{Scale: [3]int32{1 << 16, 1 << 16, 1 << 16}}
+
+// Apply returns the calibrated magnetic field.
+func (c Calibration) Apply(x, y, z int32) (int32, int32, int32) {
+	return int32(int64(x-c.Offset[0]) * int64(c.Scale[0]) >> 16),
+		int32(int64(y-c.Offset[1]) * int64(c.Scale[1]) >> 16),
+		int32(int64(z-c.Offset[2]) * int64(c.Scale[2]) >> 16)
+}
+
+// Calibrator collects magnetometer readings and computes a Calibration from
+// them. Readings are accumulated into running sums, so no samples need to be
+// stored.
+type Calibrator struct {
+	min, max [3]int32
+	n        int
+
+	// Sums for the least squares fit of an axis-aligned ellipsoid
+	//   a*x² + b*y² + c*z² + d*x + e*y + f*z = 1
+	// stored as the upper triangle of AᵀA and the vector Aᵀ1.
+	ata [6][6]float64
+	at1 [6]float64
+}
+
+// Add adds a magnetometer reading.
+func (c *Calibrator) Add(x, y, z int32) {
+	v := [3]int32{x, y, z}
+	for i := range v {
+		if c.n == 0 || v[i] < c.min[i] {
+			c.min[i] = v[i]
+		}
+		if c.n == 0 || v[i] > c.max[i] {
+			c.max[i] = v[i]
+		}
+	}
+	c.n++
+
+	fx, fy, fz := float64(x), float64(y), float64(z)
+	row := [6]float64{fx * fx, fy * fy, fz * fz, fx, fy, fz}
+	for i := 0; i < 6; i++ {
+		for j := i; j < 6; j++ {
+			c.ata[i][j] += row[i] * row[j]
+		}
+		c.at1[i] += row[i]
+	}
+}
+
+// Samples returns the number of readings added so far.
+func (c *Calibrator) Samples() int {
+	return c.n
+}
+
+// MinMax returns the calibration derived from the minimum and maximum reading
+// of every axis. This is simple and robust, but requires the device to have
+// been pointed along every axis in both directions.
+func (c *Calibrator) MinMax() Calibration {
+	var cal Calibration
+	var radius [3]int64
+	var avg int64
+	for i := 0; i < 3; i++ {
+		cal.Offset[i] = (c.max[i] + c.min[i]) / 2
+		radius[i] = int64(c.max[i]-c.min[i]) / 2
+		avg += radius[i]
+	}
+	avg /= 3
+	for i := 0; i < 3; i++ {
+		if radius[i] == 0 {
+			cal.Scale[i] = 1 << 16
+			continue
+		}
+		cal.Scale[i] = int32(avg << 16 / radius[i])
+	}
+	return cal
+}
+
+// EllipsoidFit returns the calibration derived from a least squares fit of an
+// axis-aligned ellipsoid through all readings. It needs fewer extreme
+// orientations than MinMax and is less sensitive to outliers, but fails (ok is
+// false) when the readings do not span enough orientations.
+func (c *Calibrator) EllipsoidFit() (cal Calibration, ok bool) {
+	if c.n < 6 {
+		return Calibration{}, false
+	}
+	var m [6][7]float64
+	for i := 0; i < 6; i++ {
+		for j := 0; j < 6; j++ {
+			if j >= i {
+				m[i][j] = c.ata[i][j]
+			} else {
+				m[i][j] = c.ata[j][i]
+			}
+		}
+		m[i][6] = c.at1[i]
+	}
+	p, ok := solve(m)
+	if !ok {
+		return Calibration{}, false
+	}
+
+	// Complete the square per axis:
+	//   a*(x - x0)² + ... = g, with x0 = -d/(2a) and g = 1 + a*x0² + ...
+	// The radius along each axis is then sqrt(g/a).
+	g := 1.0
+	var center, radius [3]float64
+	for i := 0; i < 3; i++ {
+		if p[i] <= 0 {
+			return Calibration{}, false
+		}
+		center[i] = -p[3+i] / (2 * p[i])
+		g += p[i] * center[i] * center[i]
+	}
+	var avg float64
+	for i := 0; i < 3; i++ {
+		radius[i] = math.Sqrt(g / p[i])
+		avg += radius[i] / 3
+	}
+	for i := 0; i < 3; i++ {
+		cal.Offset[i] = int32(math.Round(center[i]))
+		cal.Scale[i] = int32(avg / radius[i] * (1 << 16))
+	}
+	return cal, true
+}
+
+// solve solves the linear system in the augmented matrix m using Gaussian
+// elimination with partial pivoting.
+func solve(m [6][7]float64) (x [6]float64, ok bool) {
+	for col := 0; col < 6; col++ {
+		pivot := col
+		for row := col + 1; row < 6; row++ {
+			if math.Abs(m[row][col]) > math.Abs(m[pivot][col]) {
+				pivot = row
+			}
+		}
+		if m[pivot][col] == 0 {
+			return x, false
+		}
+		m[col], m[pivot] = m[pivot], m[col]
+		for row := col + 1; row < 6; row++ {
+			f := m[row][col] / m[col][col]
+			for k := col; k < 7; k++ {
+				m[row][k] -= f * m[col][k]
+			}
+		}
+	}
+	for row := 5; row >= 0; row-- {
+		sum := m[row][6]
+		for k := row + 1; k < 6; k++ {
+			sum -= m[row][k] * x[k]
+		}
+		x[row] = sum / m[row][row]
+	}
+	return x, true
+}
+
+// Collect feeds readings from read into the calibrator every interval, for
+// the given duration. It is meant to be called while the user rotates the
+// device slowly in all directions, for example in a figure eight. Readings
+// that fail are skipped.
+func (c *Calibrator) Collect(read func() (x, y, z int32, err error), duration, interval time.Duration) {
+	start := time.Now()
+	for time.Since(start) < duration {
+		x, y, z, err := read()
+		if err == nil {
+			c.Add(x, y, z)
+		}
+		time.Sleep(interval)
+	}
+}
+
+// Heading returns the heading in degrees (0-359) from a calibrated magnetic
+// field reading. It assumes the device lies flat with the z axis pointing
+// down, where a heading of zero means the x axis points to magnetic North.
+func Heading(mx, my int32) int32 {
+	return toDegrees(math.Atan2(float64(my), float64(mx)))
+}
+
+// TiltCompensatedHeading returns the heading in degrees (0-359) from a
+// calibrated magnetic field reading and an acceleration reading of an
+// accelerometer with the same axis orientation. The acceleration is used to
+// determine the pitch and roll of the device, so that the heading stays
+// correct while the device is tilted. Any unit can be used for both, as long
+// as it is the same for all three axes.
+func TiltCompensatedHeading(mx, my, mz, ax, ay, az int32) int32 {
+	fax, fay, faz := float64(ax), float64(ay), float64(az)
+	roll := math.Atan2(fay, faz)
+	sinRoll, cosRoll := math.Sincos(roll)
+	pitch := math.Atan2(-fax, fay*sinRoll+faz*cosRoll)
+	sinPitch, cosPitch := math.Sincos(pitch)
+
+	fmx, fmy, fmz := float64(mx), float64(my), float64(mz)
+	// Rotate the magnetic field back into the horizontal plane.
+	xh := fmx*cosPitch + fmy*sinRoll*sinPitch + fmz*cosRoll*sinPitch
+	yh := fmy*cosRoll - fmz*sinRoll
+	return toDegrees(math.Atan2(yh, xh))
+}
+
+func toDegrees(rad float64) int32 {
+	deg := rad * 180 / math.Pi
+	if deg < 0 {
+		deg += 360
+	}
+	return int32(deg) % 360
+}
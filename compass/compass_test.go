@@ -0,0 +1,99 @@
+package compass
+
+import (
+	"math"
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+)
+
+// sphere returns points on a sphere with the given radius, distorted by a
+// per-axis scale and offset.
+func sphere(radius float64, scale, offset [3]float64) [][3]int32 {
+	var points [][3]int32
+	for lat := -80; lat <= 80; lat += 20 {
+		for lon := 0; lon < 360; lon += 30 {
+			la := float64(lat) * math.Pi / 180
+			lo := float64(lon) * math.Pi / 180
+			v := [3]float64{
+				radius * math.Cos(la) * math.Cos(lo),
+				radius * math.Cos(la) * math.Sin(lo),
+				radius * math.Sin(la),
+			}
+			var p [3]int32
+			for i := range v {
+				p[i] = int32(math.Round(v[i]*scale[i] + offset[i]))
+			}
+			points = append(points, p)
+		}
+	}
+	// Poles, so that MinMax sees the full z range.
+	for _, s := range []float64{-1, 1} {
+		points = append(points, [3]int32{
+			int32(offset[0]), int32(offset[1]), int32(math.Round(s*radius*scale[2] + offset[2])),
+		})
+	}
+	return points
+}
+
+func TestCalibration(t *testing.T) {
+	c := qt.New(t)
+	var cal Calibrator
+	for _, p := range sphere(500, [3]float64{1.2, 0.9, 1.0}, [3]float64{120, -300, 45}) {
+		cal.Add(p[0], p[1], p[2])
+	}
+
+	for name, result := range map[string]Calibration{
+		"minmax":    cal.MinMax(),
+		"ellipsoid": mustFit(c, &cal),
+	} {
+		c.Run(name, func(c *qt.C) {
+			c.Assert(result.Offset[0], qt.Satisfies, near(120, 3))
+			c.Assert(result.Offset[1], qt.Satisfies, near(-300, 3))
+			c.Assert(result.Offset[2], qt.Satisfies, near(45, 3))
+
+			// After calibration, all axes should have the same radius.
+			x, _, _ := result.Apply(120+600, -300, 45)
+			_, y, _ := result.Apply(120, -300+450, 45)
+			_, _, z := result.Apply(120, -300, 45+500)
+			c.Assert(x, qt.Satisfies, near(y, 5))
+			c.Assert(y, qt.Satisfies, near(z, 5))
+		})
+	}
+}
+
+func TestHeading(t *testing.T) {
+	c := qt.New(t)
+	c.Assert(Heading(100, 0), qt.Equals, int32(0))
+	c.Assert(Heading(0, 100), qt.Equals, int32(90))
+	c.Assert(Heading(-100, 0), qt.Equals, int32(180))
+	c.Assert(Heading(0, -100), qt.Equals, int32(270))
+
+	// Flat: same as the uncompensated heading.
+	c.Assert(TiltCompensatedHeading(0, 100, 400, 0, 0, 1000), qt.Equals, int32(90))
+
+	// Rolled 30° around the x axis: the vertical field component leaks into
+	// y, which tilt compensation removes.
+	s, co := math.Sincos(30 * math.Pi / 180)
+	my := int32(400 * s)
+	mz := int32(400 * co)
+	ay := int32(1000 * s)
+	az := int32(1000 * co)
+	c.Assert(TiltCompensatedHeading(100, my, mz, 0, ay, az), qt.Satisfies, near(0, 1))
+}
+
+func mustFit(c *qt.C, cal *Calibrator) Calibration {
+	result, ok := cal.EllipsoidFit()
+	c.Assert(ok, qt.IsTrue)
+	return result
+}
+
+func near(want, tolerance int32) func(int32) bool {
+	return func(got int32) bool {
+		d := got - want
+		if d < 0 {
+			d = -d
+		}
+		return d <= tolerance
+	}
+}
@@ -0,0 +1,218 @@
+// Package crsf implements the Crossfire (CRSF) protocol used by TBS
+// Crossfire and ExpressLRS receivers, in both directions: RC channels and
+// link statistics are decoded from the receiver, and telemetry frames
+// (battery, GPS, attitude, flight mode) are sent back to it.
+//
+// CRSF runs at 420000 baud, 8N1, not inverted. Connect the receiver TX to
+// the UART RX and the receiver RX to the UART TX.
+//
+// Protocol reference: https://github.com/crsf-wg/crsf/wiki
+package crsf // import "tinygo.org/x/drivers/crsf"
+
+import (
+	"time"
+
+	"tinygo.org/x/drivers"
+)
+
+// Addresses, used as the first byte of a frame.
+const (
+	AddressFlightController = 0xC8
+	AddressRadio            = 0xEA
+	AddressReceiver         = 0xEC
+	AddressTransmitter      = 0xEE
+)
+
+// Frame types.
+const (
+	TypeGPS            = 0x02
+	TypeBattery        = 0x08
+	TypeLinkStatistics = 0x14
+	TypeRCChannels     = 0x16
+	TypeAttitude       = 0x1E
+	TypeFlightMode     = 0x21
+)
+
+// Channels is the number of RC channels.
+const Channels = 16
+
+// maxFrame is the maximum size of a frame, including address and length.
+const maxFrame = 64
+
+// Timeout is the time without an RC channels frame after which the signal
+// is considered lost.
+const Timeout = 250 * time.Millisecond
+
+// LinkStatistics describes the radio link quality.
+type LinkStatistics struct {
+	UplinkRSSI1     uint8 // -dBm
+	UplinkRSSI2     uint8 // -dBm
+	UplinkQuality   uint8 // %
+	UplinkSNR       int8  // dB
+	ActiveAntenna   uint8
+	RFMode          uint8
+	UplinkTXPower   uint8 // enum, 0 is 0mW, 1 is 10mW, 2 is 25mW...
+	DownlinkRSSI    uint8 // -dBm
+	DownlinkQuality uint8 // %
+	DownlinkSNR     int8  // dB
+}
+
+// Decoder assembles frames from received bytes.
+type Decoder struct {
+	buf [maxFrame]byte
+	n   int
+
+	// Raw 11 bit channel values of the last RC channels frame, 172 to 1811
+	// for the 1000 to 2000µs range.
+	Raw [Channels]uint16
+
+	Link LinkStatistics
+}
+
+// Feed adds a received byte. When the byte completes a frame with a valid
+// checksum, it returns the frame type; otherwise it returns 0. RC channels
+// and link statistics frames are decoded into the Decoder, other frames can
+// be inspected with Payload until the next byte is fed.
+func (d *Decoder) Feed(b byte) uint8 {
+	switch d.n {
+	case 0:
+		if b != AddressFlightController && b != AddressReceiver && b != AddressTransmitter {
+			return 0
+		}
+	case 1:
+		if b < 2 || int(b) > maxFrame-2 {
+			d.n = 0
+			return 0
+		}
+	}
+	d.buf[d.n] = b
+	d.n++
+	if d.n < 2 || d.n < int(d.buf[1])+2 {
+		return 0
+	}
+	d.n = 0
+
+	frame := d.buf[2 : int(d.buf[1])+2]
+	if crc8(frame[:len(frame)-1]) != frame[len(frame)-1] {
+		return 0
+	}
+	typ := frame[0]
+	payload := frame[1 : len(frame)-1]
+	switch typ {
+	case TypeRCChannels:
+		if len(payload) != 22 {
+			return 0
+		}
+		unpack(payload, &d.Raw)
+	case TypeLinkStatistics:
+		if len(payload) != 10 {
+			return 0
+		}
+		d.Link = LinkStatistics{
+			UplinkRSSI1:     payload[0],
+			UplinkRSSI2:     payload[1],
+			UplinkQuality:   payload[2],
+			UplinkSNR:       int8(payload[3]),
+			ActiveAntenna:   payload[4],
+			RFMode:          payload[5],
+			UplinkTXPower:   payload[6],
+			DownlinkRSSI:    payload[7],
+			DownlinkQuality: payload[8],
+			DownlinkSNR:     int8(payload[9]),
+		}
+	}
+	return typ
+}
+
+// Payload returns the payload of the last complete frame.
+func (d *Decoder) Payload() []byte {
+	return d.buf[3 : int(d.buf[1])+1]
+}
+
+// Pulse returns a channel as a servo pulse width in µs, centered at 1500.
+func (d *Decoder) Pulse(channel int) uint16 {
+	return uint16((int32(d.Raw[channel])-992)*5/8 + 1500)
+}
+
+// unpack decodes 16 channels of 11 bits, LSB first.
+func unpack(data []byte, raw *[Channels]uint16) {
+	var acc uint32
+	var bits uint
+	ch := 0
+	for _, b := range data {
+		acc |= uint32(b) << bits
+		bits += 8
+		if bits >= 11 {
+			raw[ch] = uint16(acc & 0x7FF)
+			ch++
+			acc >>= 11
+			bits -= 11
+		}
+	}
+}
+
+// crc8 calculates the CRC-8/DVB-S2 checksum of the type and payload.
+func crc8(data []byte) uint8 {
+	var crc uint8
+	for _, b := range data {
+		crc ^= b
+		for i := 0; i < 8; i++ {
+			if crc&0x80 != 0 {
+				crc = crc<<1 ^ 0xD5
+			} else {
+				crc <<= 1
+			}
+		}
+	}
+	return crc
+}
+
+// Device is a CRSF link over a UART.
+type Device struct {
+	uart    drivers.UART
+	Decoder Decoder
+	last    time.Time
+	rx      [32]byte
+	tx      [maxFrame]byte
+}
+
+// New returns a new CRSF link. The UART must already be configured at
+// 420000 baud.
+func New(uart drivers.UART) *Device {
+	return &Device{uart: uart}
+}
+
+// Update reads the received bytes and returns whether a new RC channels
+// frame was decoded.
+func (d *Device) Update() (bool, error) {
+	updated := false
+	for d.uart.Buffered() > 0 {
+		n, err := d.uart.Read(d.rx[:])
+		if err != nil {
+			return updated, err
+		}
+		for _, b := range d.rx[:n] {
+			if d.Decoder.Feed(b) == TypeRCChannels {
+				d.last = time.Now()
+				updated = true
+			}
+		}
+	}
+	return updated, nil
+}
+
+// Pulse returns a channel of the last RC frame as a servo pulse width in µs.
+func (d *Device) Pulse(channel int) uint16 {
+	return d.Decoder.Pulse(channel)
+}
+
+// Link returns the last link statistics.
+func (d *Device) Link() LinkStatistics {
+	return d.Decoder.Link
+}
+
+// SignalLost returns whether no RC frame was received for Timeout.
+// ExpressLRS receivers stop sending RC frames when the link is lost.
+func (d *Device) SignalLost() bool {
+	return d.last.IsZero() || time.Since(d.last) > Timeout
+}
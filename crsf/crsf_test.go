@@ -0,0 +1,58 @@
+package crsf
+
+import (
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+)
+
+func TestDecoder(t *testing.T) {
+	c := qt.New(t)
+
+	// Pack 16 channels, all centered except channel 2 at minimum.
+	var raw [Channels]uint16
+	for i := range raw {
+		raw[i] = 992
+	}
+	raw[2] = 172
+	var payload []byte
+	var acc uint32
+	var bits uint
+	for _, v := range raw {
+		acc |= uint32(v) << bits
+		bits += 11
+		for bits >= 8 {
+			payload = append(payload, byte(acc))
+			acc >>= 8
+			bits -= 8
+		}
+	}
+
+	var buf [maxFrame]byte
+	n := encode(buf[:], TypeRCChannels, payload)
+
+	var d Decoder
+	var types []uint8
+	for _, b := range append([]byte{0x00, 0x17}, buf[:n]...) {
+		if typ := d.Feed(b); typ != 0 {
+			types = append(types, typ)
+		}
+	}
+	c.Assert(types, qt.DeepEquals, []uint8{TypeRCChannels})
+	c.Assert(d.Raw, qt.Equals, raw)
+	c.Assert(d.Pulse(0), qt.Equals, uint16(1500))
+	c.Assert(d.Pulse(2), qt.Equals, uint16(988))
+
+	// A corrupted frame is rejected.
+	buf[5] ^= 0x10
+	for _, b := range buf[:n] {
+		c.Assert(d.Feed(b), qt.Equals, uint8(0))
+	}
+}
+
+func TestCRC(t *testing.T) {
+	c := qt.New(t)
+
+	// CRC-8/DVB-S2 check value.
+	c.Assert(crc8([]byte("123456789")), qt.Equals, uint8(0xBC))
+}
@@ -0,0 +1,74 @@
+package crsf
+
+// Telemetry frames are sent to the receiver, which forwards them to the
+// radio. Receivers accept about one telemetry frame per received RC frame,
+// so send them at a moderate rate, for example one per Update that returned
+// a new RC frame, cycling through the frame types.
+
+// SendBattery sends the battery sensor frame. Voltage is in mV, current in
+// mA, used capacity in mAh and remaining in percent.
+func (d *Device) SendBattery(voltage, current, capacity uint32, remaining uint8) error {
+	v := voltage / 100
+	c := current / 100
+	return d.send(TypeBattery, []byte{
+		byte(v >> 8), byte(v),
+		byte(c >> 8), byte(c),
+		byte(capacity >> 16), byte(capacity >> 8), byte(capacity),
+		remaining,
+	})
+}
+
+// SendGPS sends the GPS frame. Latitude and longitude are in degrees * 1e7,
+// ground speed in cm/s, heading in centidegrees, altitude in meters above
+// sea level.
+func (d *Device) SendGPS(latitude, longitude int32, speed, heading uint16, altitude int32, satellites uint8) error {
+	// Ground speed in km/h * 10.
+	s := uint32(speed) * 36 / 100
+	alt := uint16(altitude + 1000)
+	return d.send(TypeGPS, []byte{
+		byte(latitude >> 24), byte(latitude >> 16), byte(latitude >> 8), byte(latitude),
+		byte(longitude >> 24), byte(longitude >> 16), byte(longitude >> 8), byte(longitude),
+		byte(s >> 8), byte(s),
+		byte(heading >> 8), byte(heading),
+		byte(alt >> 8), byte(alt),
+		satellites,
+	})
+}
+
+// SendAttitude sends the attitude frame. Angles are in microradians.
+func (d *Device) SendAttitude(pitch, roll, yaw int32) error {
+	// The frame uses radians * 10000.
+	p, r, y := int16(pitch/100), int16(roll/100), int16(yaw/100)
+	return d.send(TypeAttitude, []byte{
+		byte(p >> 8), byte(p),
+		byte(r >> 8), byte(r),
+		byte(y >> 8), byte(y),
+	})
+}
+
+// SendFlightMode sends the flight mode frame, shown as text on the radio.
+func (d *Device) SendFlightMode(mode string) error {
+	if len(mode) > 15 {
+		mode = mode[:15]
+	}
+	payload := make([]byte, len(mode)+1)
+	copy(payload, mode)
+	return d.send(TypeFlightMode, payload)
+}
+
+// send writes a frame addressed to the receiver.
+func (d *Device) send(typ uint8, payload []byte) error {
+	n := encode(d.tx[:], typ, payload)
+	_, err := d.uart.Write(d.tx[:n])
+	return err
+}
+
+// encode builds a frame in buf and returns its length.
+func encode(buf []byte, typ uint8, payload []byte) int {
+	buf[0] = AddressFlightController
+	buf[1] = uint8(len(payload) + 2)
+	buf[2] = typ
+	copy(buf[3:], payload)
+	buf[3+len(payload)] = crc8(buf[2 : 3+len(payload)])
+	return len(payload) + 4
+}
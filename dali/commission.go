@@ -0,0 +1,127 @@
+package dali
+
+// Commission gives short addresses to the control gear, and returns the
+// addresses given. With all set, every gear is readdressed from address 0;
+// otherwise only the gear without a short address is, with the addresses
+// not in use.
+//
+// The gear is found with the random address search of the specification:
+// each gear picks a random 24-bit address, and the lowest one is found by a
+// binary search with the compare command, given the next short address and
+// withdrawn from the search. It takes a few seconds per gear.
+func (m *Master) Commission(all bool) ([]uint8, error) {
+	var used [64]bool
+	if !all {
+		for i := range used {
+			present, err := m.Present(Short(uint8(i)))
+			if err != nil {
+				return nil, err
+			}
+			used[i] = present
+		}
+	}
+
+	// Only the gear targeted by INITIALISE takes part, for 15 minutes.
+	target := uint8(0x00)
+	if !all {
+		target = 0xFF
+	}
+	if err := m.twice(specialInitialise, target); err != nil {
+		return nil, err
+	}
+	if err := m.twice(specialRandomise, 0); err != nil {
+		return nil, err
+	}
+
+	var given []uint8
+	next := 0
+	err := func() error {
+		for {
+			random, found, err := m.lowest()
+			if err != nil || !found {
+				return err
+			}
+			for next < len(used) && used[next] {
+				next++
+			}
+			if next == len(used) {
+				return errRange
+			}
+			if err := m.search(random); err != nil {
+				return err
+			}
+			if err := m.special(specialProgramShort, uint8(Short(uint8(next)))|1); err != nil {
+				return err
+			}
+			if err := m.special(specialWithdraw, 0); err != nil {
+				return err
+			}
+			used[next] = true
+			given = append(given, uint8(next))
+		}
+	}()
+	// Leave the commissioning mode in any case.
+	if terr := m.special(specialTerminate, 0); err == nil {
+		err = terr
+	}
+	return given, err
+}
+
+// lowest returns the lowest random address of the gear still in the search.
+func (m *Master) lowest() (uint32, bool, error) {
+	lo, hi := uint32(0), uint32(0xFFFFFF)
+	if yes, err := m.compare(hi); err != nil || !yes {
+		return 0, false, err
+	}
+	for lo < hi {
+		mid := lo + (hi-lo)/2
+		yes, err := m.compare(mid)
+		if err != nil {
+			return 0, false, err
+		}
+		if yes {
+			hi = mid
+		} else {
+			lo = mid + 1
+		}
+	}
+	return lo, true, nil
+}
+
+// compare returns whether gear has a random address lower than or equal to
+// addr. Several gear answering at once also means yes.
+func (m *Master) compare(addr uint32) (bool, error) {
+	if err := m.search(addr); err != nil {
+		return false, err
+	}
+	if err := m.special(specialCompare, 0); err != nil {
+		return false, err
+	}
+	_, err := m.bus.Backward()
+	switch err {
+	case nil, ErrCollision:
+		return true, nil
+	case ErrNoAnswer:
+		return false, nil
+	}
+	return false, err
+}
+
+// search sets the search address of the gear.
+func (m *Master) search(addr uint32) error {
+	if err := m.special(specialSearchAddrH, uint8(addr>>16)); err != nil {
+		return err
+	}
+	if err := m.special(specialSearchAddrM, uint8(addr>>8)); err != nil {
+		return err
+	}
+	return m.special(specialSearchAddrL, uint8(addr))
+}
+
+// twice sends a special command that must be repeated to be accepted.
+func (m *Master) twice(cmd, data uint8) error {
+	if err := m.special(cmd, data); err != nil {
+		return err
+	}
+	return m.special(cmd, data)
+}
@@ -0,0 +1,254 @@
+// Package dali implements a DALI (Digital Addressable Lighting Interface)
+// master, which controls the dimmable ballasts and LED drivers (control
+// gear) of building lighting over a two-wire bus.
+//
+// The master sends 16-bit forward frames, an address byte and a command or
+// level byte, and queries are answered by an 8-bit backward frame. Both are
+// Manchester encoded at 1200 bit/s. A Line sends and receives them with two
+// GPIOs connected to the bus through a DALI interface circuit; other
+// interfaces can implement Bus.
+//
+// Specification: IEC 62386-101 and IEC 62386-102.
+package dali // import "tinygo.org/x/drivers/dali"
+
+import (
+	"errors"
+)
+
+var (
+	// ErrNoAnswer is returned by Bus.Backward when no backward frame was
+	// received.
+	ErrNoAnswer = errors.New("dali: no answer")
+
+	// ErrCollision is returned by Bus.Backward when an invalid backward
+	// frame was received, which is usually several gear answering at once.
+	ErrCollision = errors.New("dali: invalid backward frame")
+
+	errRange = errors.New("dali: value out of range")
+)
+
+// Bus sends forward frames and receives backward frames.
+type Bus interface {
+	// Forward sends a forward frame, the address byte first.
+	Forward(frame uint16) error
+
+	// Backward waits for the backward frame answering the last forward
+	// frame.
+	Backward() (uint8, error)
+}
+
+// Address selects the control gear a command is sent to.
+type Address uint8
+
+// Broadcast addresses all the control gear.
+const Broadcast Address = 0xFE
+
+// BroadcastUnaddressed addresses the control gear without a short address.
+const BroadcastUnaddressed Address = 0xFC
+
+// Short returns the short address n, from 0 to 63.
+func Short(n uint8) Address {
+	return Address(n&0x3F) << 1
+}
+
+// Group returns the address of group n, from 0 to 15.
+func Group(n uint8) Address {
+	return 0x80 | Address(n&0x0F)<<1
+}
+
+// Commands, sent with Command.
+const (
+	CmdOff             = 0x00
+	CmdUp              = 0x01
+	CmdDown            = 0x02
+	CmdStepUp          = 0x03
+	CmdStepDown        = 0x04
+	CmdRecallMaxLevel  = 0x05
+	CmdRecallMinLevel  = 0x06
+	CmdStepDownAndOff  = 0x07
+	CmdOnAndStepUp     = 0x08
+	CmdGoToLastLevel   = 0x0A
+	CmdGoToScene       = 0x10 // + scene
+	CmdReset           = 0x20
+	CmdStoreLevelInDTR = 0x21
+	CmdSetMaxLevel     = 0x2A
+	CmdSetMinLevel     = 0x2B
+	CmdSetFailureLevel = 0x2C
+	CmdSetPowerOnLevel = 0x2D
+	CmdSetFadeTime     = 0x2E
+	CmdSetFadeRate     = 0x2F
+	CmdSetScene        = 0x40 // + scene
+	CmdRemoveFromScene = 0x50 // + scene
+	CmdAddToGroup      = 0x60 // + group
+	CmdRemoveFromGroup = 0x70 // + group
+	CmdSetShortAddress = 0x80
+)
+
+// Queries, sent with Query.
+const (
+	QueryStatus             = 0x90
+	QueryControlGearPresent = 0x91
+	QueryLampFailure        = 0x92
+	QueryActualLevel        = 0xA0
+	QueryMaxLevel           = 0xA1
+	QueryMinLevel           = 0xA2
+	QueryPowerOnLevel       = 0xA3
+	QueryFailureLevel       = 0xA4
+	QueryFadeTimeRate       = 0xA5
+	QuerySceneLevel         = 0xB0 // + scene
+	QueryGroups0To7         = 0xC0
+	QueryGroups8To15        = 0xC1
+	QueryRandomAddressH     = 0xC2
+	QueryRandomAddressM     = 0xC3
+	QueryRandomAddressL     = 0xC4
+)
+
+// Special commands, whose first byte is the command itself.
+const (
+	specialTerminate    = 0xA1
+	specialDTR0         = 0xA3
+	specialInitialise   = 0xA5
+	specialRandomise    = 0xA7
+	specialCompare      = 0xA9
+	specialWithdraw     = 0xAB
+	specialSearchAddrH  = 0xB1
+	specialSearchAddrM  = 0xB3
+	specialSearchAddrL  = 0xB5
+	specialProgramShort = 0xB7
+)
+
+// Master sends commands to the control gear on a bus.
+type Master struct {
+	bus Bus
+}
+
+// NewMaster returns a master on a bus.
+func NewMaster(bus Bus) *Master {
+	return &Master{bus: bus}
+}
+
+// SetLevel sets the arc power level of the gear, from 1 (minimum) to 254,
+// with the fade time of the gear. Level 0 switches the lamps off, and level
+// 255 stops a running fade.
+func (m *Master) SetLevel(a Address, level uint8) error {
+	return m.bus.Forward(uint16(a)<<8 | uint16(level))
+}
+
+// Command sends a command to the gear. The configuration commands, from
+// CmdReset to CmdSetShortAddress, are sent twice as required.
+func (m *Master) Command(a Address, cmd uint8) error {
+	frame := uint16(a|1)<<8 | uint16(cmd)
+	if err := m.bus.Forward(frame); err != nil {
+		return err
+	}
+	if cmd >= CmdReset && cmd <= CmdSetShortAddress {
+		return m.bus.Forward(frame)
+	}
+	return nil
+}
+
+// Query sends a query to a single gear, and returns its answer.
+func (m *Master) Query(a Address, query uint8) (uint8, error) {
+	if err := m.bus.Forward(uint16(a|1)<<8 | uint16(query)); err != nil {
+		return 0, err
+	}
+	return m.bus.Backward()
+}
+
+// Present returns whether gear answers at the address.
+func (m *Master) Present(a Address) (bool, error) {
+	_, err := m.Query(a, QueryControlGearPresent)
+	switch err {
+	case nil, ErrCollision:
+		return true, nil
+	case ErrNoAnswer:
+		return false, nil
+	}
+	return false, err
+}
+
+// Level returns the actual arc power level of the gear.
+func (m *Master) Level(a Address) (uint8, error) {
+	return m.Query(a, QueryActualLevel)
+}
+
+// GoToScene sets the gear to the level stored for a scene, from 0 to 15.
+func (m *Master) GoToScene(a Address, scene uint8) error {
+	if scene > 15 {
+		return errRange
+	}
+	return m.Command(a, CmdGoToScene+scene)
+}
+
+// SetScene stores a level for a scene, from 0 to 15, in the gear.
+func (m *Master) SetScene(a Address, scene, level uint8) error {
+	if scene > 15 {
+		return errRange
+	}
+	return m.configure(a, CmdSetScene+scene, level)
+}
+
+// RemoveFromScene removes the gear from a scene, from 0 to 15.
+func (m *Master) RemoveFromScene(a Address, scene uint8) error {
+	if scene > 15 {
+		return errRange
+	}
+	return m.Command(a, CmdRemoveFromScene+scene)
+}
+
+// AddToGroup adds the gear to a group, from 0 to 15.
+func (m *Master) AddToGroup(a Address, group uint8) error {
+	if group > 15 {
+		return errRange
+	}
+	return m.Command(a, CmdAddToGroup+group)
+}
+
+// RemoveFromGroup removes the gear from a group, from 0 to 15.
+func (m *Master) RemoveFromGroup(a Address, group uint8) error {
+	if group > 15 {
+		return errRange
+	}
+	return m.Command(a, CmdRemoveFromGroup+group)
+}
+
+// SetFadeTime sets the fade time of the gear, used by SetLevel and
+// GoToScene, from 0 (no fade) to 15 (90.5 seconds): 0.5 * sqrt(2^time)
+// seconds.
+func (m *Master) SetFadeTime(a Address, time uint8) error {
+	if time > 15 {
+		return errRange
+	}
+	return m.configure(a, CmdSetFadeTime, time)
+}
+
+// SetFadeRate sets the fade rate of the gear, used by CmdUp and CmdDown,
+// from 1 (358 steps per second) to 15 (2.8 steps per second): 506 /
+// sqrt(2^rate) steps per second.
+func (m *Master) SetFadeRate(a Address, rate uint8) error {
+	if rate < 1 || rate > 15 {
+		return errRange
+	}
+	return m.configure(a, CmdSetFadeRate, rate)
+}
+
+// SetMinMaxLevel sets the range of levels of the gear.
+func (m *Master) SetMinMaxLevel(a Address, min, max uint8) error {
+	if err := m.configure(a, CmdSetMinLevel, min); err != nil {
+		return err
+	}
+	return m.configure(a, CmdSetMaxLevel, max)
+}
+
+// configure sends a configuration command taking its value from DTR0.
+func (m *Master) configure(a Address, cmd, value uint8) error {
+	if err := m.special(specialDTR0, value); err != nil {
+		return err
+	}
+	return m.Command(a, cmd)
+}
+
+// special sends a special command.
+func (m *Master) special(cmd, data uint8) error {
+	return m.bus.Forward(uint16(cmd)<<8 | uint16(data))
+}
@@ -0,0 +1,164 @@
+package dali
+
+import (
+	"testing"
+	"time"
+
+	qt "github.com/frankban/quicktest"
+)
+
+// gear is a simulated control gear.
+type gear struct {
+	short     int // -1 without a short address
+	random    uint32
+	withdrawn bool
+	level     uint8
+}
+
+// fakeBus is a bus with simulated control gear on it.
+type fakeBus struct {
+	gear    []*gear
+	frames  []uint16
+	search  uint32
+	answers []uint8
+	dtr     uint8
+	init    bool
+}
+
+func (b *fakeBus) Forward(frame uint16) error {
+	b.frames = append(b.frames, frame)
+	addr, data := uint8(frame>>8), uint8(frame)
+	b.answers = b.answers[:0]
+	switch addr {
+	case specialDTR0:
+		b.dtr = data
+	case specialInitialise:
+		b.init = true
+		for _, g := range b.gear {
+			g.withdrawn = data == 0xFF && g.short >= 0
+		}
+	case specialSearchAddrH:
+		b.search = b.search&0x00FFFF | uint32(data)<<16
+	case specialSearchAddrM:
+		b.search = b.search&0xFF00FF | uint32(data)<<8
+	case specialSearchAddrL:
+		b.search = b.search&0xFFFF00 | uint32(data)
+	case specialCompare:
+		for _, g := range b.gear {
+			if b.init && !g.withdrawn && g.random <= b.search {
+				b.answers = append(b.answers, 0xFF)
+			}
+		}
+	case specialProgramShort:
+		for _, g := range b.gear {
+			if g.random == b.search {
+				g.short = int(data >> 1)
+			}
+		}
+	case specialWithdraw:
+		for _, g := range b.gear {
+			if g.random == b.search {
+				g.withdrawn = true
+			}
+		}
+	case specialTerminate:
+		b.init = false
+	default:
+		for _, g := range b.gear {
+			if addr&0xFE != 0xFE && (g.short < 0 || int(addr>>1) != g.short) {
+				continue
+			}
+			switch {
+			case addr&1 == 0:
+				g.level = data
+			case data == QueryControlGearPresent:
+				b.answers = append(b.answers, 0xFF)
+			case data == QueryActualLevel:
+				b.answers = append(b.answers, g.level)
+			}
+		}
+	}
+	return nil
+}
+
+func (b *fakeBus) Backward() (uint8, error) {
+	switch len(b.answers) {
+	case 0:
+		return 0, ErrNoAnswer
+	case 1:
+		return b.answers[0], nil
+	}
+	return 0, ErrCollision
+}
+
+func TestMaster(t *testing.T) {
+	c := qt.New(t)
+	bus := &fakeBus{gear: []*gear{{short: 3}}}
+	m := NewMaster(bus)
+
+	c.Assert(m.SetLevel(Short(3), 200), qt.IsNil)
+	level, err := m.Level(Short(3))
+	c.Assert(err, qt.IsNil)
+	c.Assert(level, qt.Equals, uint8(200))
+	_, err = m.Level(Short(4))
+	c.Assert(err, qt.Equals, ErrNoAnswer)
+
+	bus.frames = nil
+	c.Assert(m.SetFadeTime(Group(2), 7), qt.IsNil)
+	c.Assert(m.GoToScene(Broadcast, 5), qt.IsNil)
+	c.Assert(m.GoToScene(Broadcast, 16), qt.Equals, errRange)
+	c.Assert(bus.frames, qt.DeepEquals, []uint16{0xA307, 0x852E, 0x852E, 0xFF15})
+}
+
+func TestCommission(t *testing.T) {
+	c := qt.New(t)
+	bus := &fakeBus{gear: []*gear{
+		{short: -1, random: 0x800000},
+		{short: 0, random: 0x123456},
+		{short: -1, random: 0x000001},
+	}}
+	m := NewMaster(bus)
+
+	given, err := m.Commission(false)
+	c.Assert(err, qt.IsNil)
+	c.Assert(given, qt.DeepEquals, []uint8{1, 2})
+	c.Assert(bus.gear[2].short, qt.Equals, 1)
+	c.Assert(bus.gear[0].short, qt.Equals, 2)
+	c.Assert(bus.gear[1].short, qt.Equals, 0)
+	c.Assert(bus.init, qt.IsFalse)
+
+	given, err = m.Commission(true)
+	c.Assert(err, qt.IsNil)
+	c.Assert(given, qt.DeepEquals, []uint8{0, 1, 2})
+	c.Assert(bus.gear[1].short, qt.Equals, 1)
+}
+
+func TestManchester(t *testing.T) {
+	c := qt.New(t)
+
+	c.Assert(halfBits(0b10, 2, nil), qt.DeepEquals, []bool{false, true, false, true, true, false})
+
+	// Backward frame 0x96: durations between the edges of the levels.
+	levels := halfBits(0x96, 8, nil)
+	var edges []time.Duration
+	run := 1
+	for i := 1; i < len(levels); i++ {
+		if levels[i] == levels[i-1] {
+			run++
+			continue
+		}
+		edges = append(edges, time.Duration(run)*te)
+		run = 1
+	}
+	// The bus goes back high after a last low half bit.
+	if !levels[len(levels)-1] {
+		edges = append(edges, time.Duration(run)*te)
+	}
+	value, err := decodeBackward(edges)
+	c.Assert(err, qt.IsNil)
+	c.Assert(value, qt.Equals, uint8(0x96))
+
+	edges[3] = 4 * te
+	_, err = decodeBackward(edges)
+	c.Assert(err, qt.Equals, ErrCollision)
+}
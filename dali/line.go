@@ -0,0 +1,174 @@
+package dali
+
+import (
+	"time"
+)
+
+// te is the half bit time at 1200 bit/s.
+const te = 416667 * time.Nanosecond
+
+// Config is the polarity of the DALI interface circuit.
+type Config struct {
+	// InvertTx is set when a high TX pin pulls the bus low (active), which
+	// is the case of the usual transistor and optocoupler interfaces.
+	InvertTx bool
+
+	// InvertRx is set when the RX pin reads low while the bus is high
+	// (idle).
+	InvertRx bool
+}
+
+// Line is a Bus bit-banged on two GPIOs connected to the bus through an
+// interface circuit, which shifts the levels and isolates the bus. The
+// frames are timed by busy-waiting, so interrupts can distort them.
+type Line struct {
+	tx       func(high bool)
+	rx       func() bool
+	invertTx bool
+	invertRx bool
+	end      time.Time // end of the last forward frame
+	next     time.Time // earliest start of the next forward frame
+}
+
+// Configure sets the polarity of the interface, and releases the bus.
+func (l *Line) Configure(cfg Config) {
+	l.invertTx = cfg.InvertTx
+	l.invertRx = cfg.InvertRx
+	l.set(true)
+}
+
+// set drives the bus low, or releases it high.
+func (l *Line) set(high bool) {
+	l.tx(high != l.invertTx)
+}
+
+// get returns the level of the bus.
+func (l *Line) get() bool {
+	return l.rx() != l.invertRx
+}
+
+// Forward sends a forward frame: a start bit and 16 bits, MSB first.
+func (l *Line) Forward(frame uint16) error {
+	wait(l.next)
+	var buf [2 + 2*16]bool
+	levels := halfBits(uint32(frame), 16, buf[:0])
+	start := time.Now()
+	for i, level := range levels {
+		l.set(level)
+		wait(start.Add(time.Duration(i+1) * te))
+	}
+	l.set(true)
+	l.end = time.Now()
+	// Two stop bits, and the settling time in which gear may answer.
+	l.next = l.end.Add(4*te + 22*te)
+	return nil
+}
+
+// Backward waits for a backward frame, which starts at most 22 half bits
+// after the end of the forward frame.
+func (l *Line) Backward() (uint8, error) {
+	deadline := l.end.Add(4*te + 22*te)
+	for l.get() {
+		if time.Now().After(deadline) {
+			return 0, ErrNoAnswer
+		}
+	}
+
+	// Time the levels from the falling edge of the start bit, until the bus
+	// is idle.
+	var edges [20]time.Duration
+	n := 0
+	level := false
+	last := time.Now()
+	for n < len(edges) {
+		now := time.Now()
+		if l.get() != level {
+			edges[n] = now.Sub(last)
+			n++
+			last = now
+			level = !level
+			continue
+		}
+		if now.Sub(last) > 3*te {
+			if !level {
+				// The bus stays low.
+				return 0, ErrCollision
+			}
+			break
+		}
+	}
+	// The next forward frame must wait for the stop bits and the settling
+	// time.
+	l.next = time.Now().Add(10 * te)
+	return decodeBackward(edges[:n])
+}
+
+// halfBits appends the levels of the half bits of a frame of n bits, with
+// its start bit, to dst. A one is low then high, a zero is high then low.
+func halfBits(frame uint32, n int, dst []bool) []bool {
+	dst = append(dst, false, true)
+	for i := n - 1; i >= 0; i-- {
+		one := frame&(1<<i) != 0
+		dst = append(dst, !one, one)
+	}
+	return dst
+}
+
+// decodeBackward decodes a backward frame from the durations of its levels,
+// alternately low and high, starting with the first half of the start bit.
+// The last high level merges with the stop bits, and is not timed.
+func decodeBackward(edges []time.Duration) (uint8, error) {
+	var buf [2 + 2*8]bool
+	levels := buf[:0]
+	level := false
+	for _, d := range edges {
+		var halves int
+		switch {
+		case d < te/2:
+			return 0, ErrCollision
+		case d < 3*te/2:
+			halves = 1
+		case d < 5*te/2:
+			halves = 2
+		default:
+			return 0, ErrCollision
+		}
+		for i := 0; i < halves; i++ {
+			if len(levels) == len(buf) {
+				return 0, ErrCollision
+			}
+			levels = append(levels, level)
+		}
+		level = !level
+	}
+	if len(levels) < len(buf)-1 {
+		return 0, ErrCollision
+	}
+	if len(levels) < len(buf) {
+		levels = append(levels, true)
+	}
+	if levels[0] || !levels[1] {
+		return 0, ErrCollision
+	}
+	value := uint8(0)
+	for i := 2; i < len(levels); i += 2 {
+		if levels[i] == levels[i+1] {
+			return 0, ErrCollision
+		}
+		value = value<<1 | boolBit(levels[i+1])
+	}
+	return value, nil
+}
+
+func boolBit(b bool) uint8 {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+// wait busy-waits until t, as the half bits are too short for time.Sleep.
+func wait(t time.Time) {
+	for time.Now().Before(t) {
+	}
+}
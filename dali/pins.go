@@ -0,0 +1,18 @@
+//go:build tinygo
+
+package dali
+
+import (
+	"machine"
+)
+
+// New returns a bus bit-banged on two pins: tx drives the bus through the
+// interface circuit, and rx reads it.
+//
+// This function only creates the Line object and sets up the pins, it does
+// not touch the bus until Configure.
+func New(tx, rx machine.Pin) *Line {
+	tx.Configure(machine.PinConfig{Mode: machine.PinOutput})
+	rx.Configure(machine.PinConfig{Mode: machine.PinInput})
+	return &Line{tx: tx.Set, rx: rx.Get}
+}
@@ -0,0 +1,161 @@
+// Package dmx implements a DMX512 transmitter, which controls stage lighting
+// fixtures, dimmers and fog machines, and the discovery of RDM responders
+// on the DMX line.
+//
+// DMX512 sends frames of up to 512 slots (channels) of 8 bits at 250000
+// baud, 8 data bits and 2 stop bits, over an RS485 transceiver. Every frame
+// starts with a break, a low level of at least 88µs, and a mark after break
+// of at least 8µs, which a UART can't send: the TX pin is driven as a GPIO
+// during them.
+//
+// Specification: ANSI E1.11 (DMX512-A) and ANSI E1.20 (RDM).
+package dmx // import "tinygo.org/x/drivers/dmx"
+
+import (
+	"time"
+
+	"tinygo.org/x/drivers"
+)
+
+// Slots is the number of slots of a full universe.
+const Slots = 512
+
+// Each slot takes 11 bits at 250000 baud.
+const slotTime = 44 * time.Microsecond
+
+// Config is the timing of the frames.
+type Config struct {
+	// Slots is the number of slots sent in each frame. Fixtures ignore the
+	// slots they do not use, and shorter frames can be sent at a higher
+	// rate. Defaults to 512.
+	Slots int
+
+	// RefreshRate is the number of frames sent per second by Refresh.
+	// Defaults to 40, which a full universe allows.
+	RefreshRate int
+
+	// BreakTime and MarkAfterBreak are the duration of the break and of the
+	// mark after it. Default to 176µs and 12µs, as most consoles.
+	BreakTime      time.Duration
+	MarkAfterBreak time.Duration
+
+	// UID is the unique identifier of the controller in RDM requests.
+	// Defaults to 7FF0:00000001, in the range reserved for prototypes.
+	UID UID
+}
+
+// Device is a DMX512 transmitter on a UART, configured at 250000 baud with 2
+// stop bits.
+type Device struct {
+	uart drivers.UART
+	brk  func(on bool) // drives TX low while on, gives it back to the UART after
+	de   func(on bool) // DE pin of the transceiver, nil if always enabled
+
+	buf      [1 + Slots]byte // start code and slots
+	slots    int
+	interval time.Duration
+	breakLen time.Duration
+	mab      time.Duration
+	last     time.Time // start of the last frame sent by Refresh
+	idle     time.Time // the last frame has left the UART
+
+	uid     UID
+	tn      uint8         // RDM transaction number
+	timeout time.Duration // wait for an RDM response
+}
+
+// Configure sets the frame timing.
+func (d *Device) Configure(cfg Config) {
+	if cfg.Slots <= 0 || cfg.Slots > Slots {
+		cfg.Slots = Slots
+	}
+	if cfg.RefreshRate <= 0 {
+		cfg.RefreshRate = 40
+	}
+	if cfg.BreakTime == 0 {
+		cfg.BreakTime = 176 * time.Microsecond
+	}
+	if cfg.MarkAfterBreak == 0 {
+		cfg.MarkAfterBreak = 12 * time.Microsecond
+	}
+	if cfg.UID == 0 {
+		cfg.UID = 0x7FF000000001
+	}
+	d.slots = cfg.Slots
+	d.interval = time.Second / time.Duration(cfg.RefreshRate)
+	d.breakLen = cfg.BreakTime
+	d.mab = cfg.MarkAfterBreak
+	d.uid = cfg.UID
+	d.timeout = 5 * time.Millisecond
+}
+
+// Set sets the value of a channel, from 1 to 512. Channels out of range are
+// ignored. The value is sent with the next frame.
+func (d *Device) Set(channel int, value uint8) {
+	if channel >= 1 && channel <= Slots {
+		d.buf[channel] = value
+	}
+}
+
+// Get returns the value of a channel, from 1 to 512.
+func (d *Device) Get(channel int) uint8 {
+	if channel >= 1 && channel <= Slots {
+		return d.buf[channel]
+	}
+	return 0
+}
+
+// Universe returns the 512 slots sent, to set many channels at once.
+// Channel n is at index n-1.
+func (d *Device) Universe() []byte {
+	return d.buf[1:]
+}
+
+// Clear sets all the channels to 0.
+func (d *Device) Clear() {
+	for i := range d.buf {
+		d.buf[i] = 0
+	}
+}
+
+// Send sends a frame now. It waits for the previous frame to be sent.
+func (d *Device) Send() error {
+	d.buf[0] = 0 // null start code: dimmer levels
+	return d.send(d.buf[:1+d.slots])
+}
+
+// Refresh sends a frame if the refresh interval has elapsed since the last
+// one, and returns whether it did. DMX512 receivers expect the frames to be
+// repeated continuously, so call it in a loop.
+func (d *Device) Refresh() (bool, error) {
+	now := time.Now()
+	if !d.last.IsZero() && now.Sub(d.last) < d.interval {
+		return false, nil
+	}
+	d.last = now
+	return true, d.Send()
+}
+
+// send sends a break, a mark after break and a packet.
+func (d *Device) send(packet []byte) error {
+	wait(d.idle)
+	if d.de != nil {
+		d.de(true)
+	}
+	d.brk(true)
+	wait(time.Now().Add(d.breakLen))
+	d.brk(false)
+	wait(time.Now().Add(d.mab))
+	start := time.Now()
+	n, err := d.uart.Write(packet)
+	// The write returns while the last bytes are still in the transmit FIFO.
+	d.idle = start.Add(time.Duration(n+1) * slotTime)
+	return err
+}
+
+// wait busy-waits until t, as the break and the mark after it are too short
+// for time.Sleep.
+func wait(t time.Time) {
+	for time.Now().Before(t) {
+	}
+}
@@ -0,0 +1,140 @@
+package dmx
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	qt "github.com/frankban/quicktest"
+)
+
+// fakeLine is a DMX line with RDM responders on it.
+type fakeLine struct {
+	frames     [][]byte
+	breaks     int
+	responders map[UID]bool // UID: muted
+	rx         bytes.Buffer
+}
+
+func (l *fakeLine) Read(b []byte) (int, error) { return l.rx.Read(b) }
+func (l *fakeLine) Buffered() int              { return l.rx.Len() }
+
+func (l *fakeLine) Write(b []byte) (int, error) {
+	l.frames = append(l.frames, append([]byte(nil), b...))
+	if b[0] != rdmStartCode {
+		return len(b), nil
+	}
+	dest := getUID(b[3:9])
+	switch uint16(b[21])<<8 | uint16(b[22]) {
+	case pidDiscUnMute:
+		for uid := range l.responders {
+			l.responders[uid] = false
+		}
+	case pidDiscMute:
+		if _, ok := l.responders[dest]; ok {
+			l.responders[dest] = true
+			resp := make([]byte, 26)
+			resp[0], resp[1], resp[2] = rdmStartCode, rdmSubStartCode, 24
+			putUID(resp[3:9], getUID(b[9:15]))
+			putUID(resp[9:15], dest)
+			resp[20], resp[22] = rdmDiscoveryCommandResponse, pidDiscMute
+			sum := checksum(resp[:24])
+			resp[24], resp[25] = byte(sum>>8), byte(sum)
+			l.rx.WriteByte(0) // break
+			l.rx.Write(resp)
+		}
+	case pidDiscUniqueBranch:
+		lower, upper := getUID(b[24:30]), getUID(b[30:36])
+		var resp []byte
+		for uid, muted := range l.responders {
+			if muted || uid < lower || uid > upper {
+				continue
+			}
+			enc := encodeDiscovery(uid)
+			if resp == nil {
+				resp = enc
+				continue
+			}
+			// Collision.
+			for i := range resp {
+				resp[i] |= enc[i]
+			}
+		}
+		l.rx.Write(resp)
+	}
+	return len(b), nil
+}
+
+func encodeDiscovery(uid UID) []byte {
+	resp := []byte{0xFE, 0xFE, 0xAA}
+	var b [6]byte
+	putUID(b[:], uid)
+	for _, c := range b {
+		resp = append(resp, c|0xAA, c|0x55)
+	}
+	sum := checksum(resp[3:])
+	return append(resp, byte(sum>>8)|0xAA, byte(sum>>8)|0x55, byte(sum)|0xAA, byte(sum)|0x55)
+}
+
+func newTestDevice(line *fakeLine) *Device {
+	d := &Device{
+		uart: line,
+		brk: func(on bool) {
+			if on {
+				line.breaks++
+			}
+		},
+		de: func(bool) {},
+	}
+	d.Configure(Config{Slots: 4, RefreshRate: 100})
+	d.timeout = 100 * time.Microsecond
+	return d
+}
+
+func TestFrames(t *testing.T) {
+	c := qt.New(t)
+	line := &fakeLine{}
+	d := newTestDevice(line)
+
+	d.Set(1, 255)
+	d.Set(4, 10)
+	d.Set(600, 1)
+	c.Assert(d.Get(4), qt.Equals, uint8(10))
+	c.Assert(d.Send(), qt.IsNil)
+	sent, err := d.Refresh()
+	c.Assert(sent, qt.IsTrue)
+	c.Assert(err, qt.IsNil)
+	sent, _ = d.Refresh()
+	c.Assert(sent, qt.IsFalse)
+	c.Assert(line.breaks, qt.Equals, 2)
+	c.Assert(line.frames, qt.DeepEquals, [][]byte{{0, 255, 0, 0, 10}, {0, 255, 0, 0, 10}})
+}
+
+func TestDiscover(t *testing.T) {
+	c := qt.New(t)
+	line := &fakeLine{responders: map[UID]bool{
+		0x123400000001: true,
+		0x123400000002: false,
+		0x7A7000ABCDEF: false,
+	}}
+	d := newTestDevice(line)
+
+	uids, err := d.Discover()
+	c.Assert(err, qt.IsNil)
+	c.Assert(uids, qt.DeepEquals, []UID{0x123400000001, 0x123400000002, 0x7A7000ABCDEF})
+	for uid, muted := range line.responders {
+		c.Check(muted, qt.IsTrue, qt.Commentf("%v", uid))
+	}
+
+	d.de = nil
+	_, err = d.Discover()
+	c.Assert(err, qt.Equals, errNoTransceiver)
+}
+
+func TestUID(t *testing.T) {
+	c := qt.New(t)
+	uid := UID(0x7FF000000001)
+	c.Assert(uid.String(), qt.Equals, "7FF0:00000001")
+	c.Assert(uid.Manufacturer(), qt.Equals, uint16(0x7FF0))
+	c.Assert(uid.Device(), qt.Equals, uint32(1))
+}
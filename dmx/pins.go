@@ -0,0 +1,48 @@
+//go:build tinygo
+
+package dmx
+
+import (
+	"machine"
+)
+
+// New returns a DMX512 transmitter on a UART. cfg is the configuration of
+// the UART, whose baud rate is set to 250000, and whose TX pin is driven
+// low during the break. de is the pin connected to DE and /RE of the RS485
+// transceiver, or machine.NoPin when the transceiver always transmits,
+// which is enough to send frames but not for RDM.
+//
+// This function configures the UART, and sets up the pins so that the
+// transceiver does not drive the line until the first frame.
+func New(uart *machine.UART, cfg machine.UARTConfig, de machine.Pin) (*Device, error) {
+	cfg.BaudRate = 250000
+	configure := func() error {
+		if err := uart.Configure(cfg); err != nil {
+			return err
+		}
+		return uart.SetFormat(8, 2, machine.ParityNone)
+	}
+	if err := configure(); err != nil {
+		return nil, err
+	}
+	tx := cfg.TX
+	d := &Device{
+		uart: uart,
+		brk: func(on bool) {
+			if on {
+				tx.Configure(machine.PinConfig{Mode: machine.PinOutput})
+				tx.Low()
+			} else {
+				tx.High()
+				configure()
+			}
+		},
+	}
+	if de != machine.NoPin {
+		de.Configure(machine.PinConfig{Mode: machine.PinOutput})
+		de.Low()
+		d.de = de.Set
+	}
+	d.Configure(Config{})
+	return d, nil
+}
@@ -0,0 +1,234 @@
+package dmx
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var errNoTransceiver = errors.New("dmx: RDM needs the DE pin of the transceiver")
+
+// UID is the 48-bit unique identifier of an RDM device: a 16-bit ESTA
+// manufacturer ID and a 32-bit device ID.
+type UID uint64
+
+// BroadcastUID addresses all the RDM devices.
+const BroadcastUID UID = 0xFFFFFFFFFFFF
+
+// Manufacturer returns the ESTA manufacturer ID.
+func (u UID) Manufacturer() uint16 {
+	return uint16(u >> 32)
+}
+
+// Device returns the device ID.
+func (u UID) Device() uint32 {
+	return uint32(u)
+}
+
+// String returns the UID in the usual MMMM:DDDDDDDD form.
+func (u UID) String() string {
+	s := strings.ToUpper(strconv.FormatUint(uint64(u)|1<<48, 16)[1:])
+	return s[:4] + ":" + s[4:]
+}
+
+// RDM start codes, command classes and parameter IDs.
+const (
+	rdmStartCode    = 0xCC
+	rdmSubStartCode = 0x01
+
+	rdmDiscoveryCommand         = 0x10
+	rdmDiscoveryCommandResponse = 0x11
+
+	pidDiscUniqueBranch = 0x0001
+	pidDiscMute         = 0x0002
+	pidDiscUnMute       = 0x0003
+
+	rdmResponseAck = 0x00
+)
+
+// Discover finds the RDM devices on the line with the binary search of the
+// discovery commands, and returns their UIDs. All devices are muted when it
+// returns, except those that did not acknowledge the mute command, which
+// are left out.
+//
+// The transceiver is switched to receive after each request, so the Device
+// must have been created with a DE pin, and /RE tied to DE.
+func (d *Device) Discover() ([]UID, error) {
+	if d.de == nil {
+		return nil, errNoTransceiver
+	}
+	if err := d.discovery(BroadcastUID, pidDiscUnMute, nil); err != nil {
+		return nil, err
+	}
+	var found []UID
+	err := d.branch(0, BroadcastUID-1, &found)
+	return found, err
+}
+
+// branch finds the unmuted devices with a UID from lower to upper.
+func (d *Device) branch(lower, upper UID, found *[]UID) error {
+	for {
+		var pd [12]byte
+		putUID(pd[:6], lower)
+		putUID(pd[6:], upper)
+		if err := d.discovery(BroadcastUID, pidDiscUniqueBranch, pd[:]); err != nil {
+			return err
+		}
+		resp := d.receive()
+		if len(resp) == 0 {
+			return nil
+		}
+		uid, ok := decodeDiscovery(resp)
+		if ok && uid >= lower && uid <= upper && d.mute(uid) {
+			*found = append(*found, uid)
+			// There may be more devices in the branch.
+			continue
+		}
+		// Several devices answered at once.
+		if lower == upper {
+			return nil
+		}
+		mid := lower + (upper-lower)/2
+		if err := d.branch(lower, mid, found); err != nil {
+			return err
+		}
+		return d.branch(mid+1, upper, found)
+	}
+}
+
+// mute sends the mute command to a device, and returns whether it
+// acknowledged it.
+func (d *Device) mute(uid UID) bool {
+	if d.discovery(uid, pidDiscMute, nil) != nil {
+		return false
+	}
+	resp := d.receive()
+	// Skip the break, received as a null byte.
+	for len(resp) > 0 && resp[0] != rdmStartCode {
+		resp = resp[1:]
+	}
+	if len(resp) < 26 || resp[1] != rdmSubStartCode || len(resp) < int(resp[2])+2 {
+		return false
+	}
+	n := int(resp[2])
+	if checksum(resp[:n]) != uint16(resp[n])<<8|uint16(resp[n+1]) {
+		return false
+	}
+	return getUID(resp[9:15]) == uid && resp[16] == rdmResponseAck &&
+		resp[20] == rdmDiscoveryCommandResponse && uint16(resp[21])<<8|uint16(resp[22]) == pidDiscMute
+}
+
+// discovery sends a discovery command.
+func (d *Device) discovery(dest UID, pid uint16, pd []byte) error {
+	var buf [26 + 12]byte
+	n := 24 + len(pd)
+	p := buf[:n]
+	p[0] = rdmStartCode
+	p[1] = rdmSubStartCode
+	p[2] = byte(n)
+	putUID(p[3:9], dest)
+	putUID(p[9:15], d.uid)
+	p[15] = d.tn
+	p[16] = 1 // port ID
+	p[20] = rdmDiscoveryCommand
+	p[21] = byte(pid >> 8)
+	p[22] = byte(pid)
+	p[23] = byte(len(pd))
+	copy(p[24:], pd)
+	sum := checksum(p)
+	p = append(p, byte(sum>>8), byte(sum))
+	d.tn++
+
+	// Discard bytes received since the last response.
+	var discard [16]byte
+	for d.uart.Buffered() > 0 {
+		d.uart.Read(discard[:])
+	}
+	return d.send(p)
+}
+
+// receive switches the transceiver to receive once the request is sent, and
+// returns the bytes received until the response timeout or a complete
+// response.
+func (d *Device) receive() []byte {
+	wait(d.idle)
+	d.de(false)
+	defer d.de(true)
+
+	var buf [64]byte
+	n := 0
+	deadline := time.Now().Add(d.timeout)
+	for time.Now().Before(deadline) && n < len(buf) {
+		if d.uart.Buffered() == 0 {
+			continue
+		}
+		k, _ := d.uart.Read(buf[n:])
+		n += k
+		if complete(buf[:n]) {
+			break
+		}
+	}
+	return buf[:n]
+}
+
+// complete returns whether resp holds a whole discovery response or RDM
+// packet.
+func complete(resp []byte) bool {
+	for i, b := range resp {
+		switch b {
+		case 0xAA:
+			return len(resp)-i > 16
+		case rdmStartCode:
+			return len(resp)-i > 2 && len(resp)-i >= int(resp[i+2])+2
+		}
+	}
+	return false
+}
+
+// decodeDiscovery returns the UID in the response to a unique branch
+// command: up to 7 preamble bytes 0xFE, a separator 0xAA, and the UID and
+// its checksum with each byte sent twice, ORed with 0xAA and with 0x55. A
+// collision of several responses breaks the checksum.
+func decodeDiscovery(resp []byte) (UID, bool) {
+	i := 0
+	for i < len(resp) && i < 7 && resp[i] == 0xFE {
+		i++
+	}
+	if i >= len(resp) || resp[i] != 0xAA || len(resp) < i+17 {
+		return 0, false
+	}
+	enc := resp[i+1 : i+17]
+	var uid [6]byte
+	for j := range uid {
+		uid[j] = enc[2*j] & enc[2*j+1]
+	}
+	sum := checksum(enc[:12])
+	if uint16(enc[12]&enc[13])<<8|uint16(enc[14]&enc[15]) != sum {
+		return 0, false
+	}
+	return getUID(uid[:]), true
+}
+
+// checksum returns the RDM checksum: the sum of the bytes.
+func checksum(data []byte) uint16 {
+	sum := uint16(0)
+	for _, b := range data {
+		sum += uint16(b)
+	}
+	return sum
+}
+
+func putUID(b []byte, uid UID) {
+	for i := 0; i < 6; i++ {
+		b[i] = byte(uid >> (40 - 8*i))
+	}
+}
+
+func getUID(b []byte) UID {
+	uid := UID(0)
+	for _, c := range b[:6] {
+		uid = uid<<8 | UID(c)
+	}
+	return uid
+}
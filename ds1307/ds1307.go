@@ -6,6 +6,7 @@ package ds1307 // import "tinygo.org/x/drivers/ds1307"
 
 import (
 	"errors"
+	"io"
 	"time"
 
 	"tinygo.org/x/drivers"
@@ -114,6 +115,41 @@ func (d *Device) Read(data []uint8) (n int, err error) {
 	return len(data), nil
 }
 
+// ReadAt reads len(data) bytes of SRAM at offset off from its start, without
+// moving the offset of Read and Write.
+func (d *Device) ReadAt(data []byte, off int64) (n int, err error) {
+	if off < 0 || off+int64(len(data)) > d.Size() {
+		return 0, io.EOF
+	}
+	err = legacy.ReadRegister(d.bus, d.Address, uint8(SRAMBeginAddres+off), data)
+	if err != nil {
+		return 0, err
+	}
+	return len(data), nil
+}
+
+// WriteAt writes len(data) bytes to SRAM at offset off from its start,
+// without moving the offset of Read and Write.
+func (d *Device) WriteAt(data []byte, off int64) (n int, err error) {
+	if off < 0 || off+int64(len(data)) > d.Size() {
+		return 0, errors.New("writing outside of SRAM")
+	}
+	buffer := make([]byte, len(data)+1)
+	buffer[0] = uint8(SRAMBeginAddres + off)
+	copy(buffer[1:], data)
+	err = d.bus.Tx(uint16(d.Address), buffer, nil)
+	if err != nil {
+		return 0, err
+	}
+	return len(data), nil
+}
+
+// Size returns the size of the SRAM, 56 bytes, which is kept by the backup
+// battery. With ReadAt and WriteAt, it makes the SRAM a persist.Memory.
+func (d *Device) Size() int64 {
+	return SRAMEndAddress - SRAMBeginAddres + 1
+}
+
 // SetOscillatorFrequency sets output oscillator frequency
 // Available modes: SQW_OFF, SQW_1HZ, SQW_4KHZ, SQW_8KHZ, SQW_32KHZ
 func (d *Device) SetOscillatorFrequency(sqw uint8) error {
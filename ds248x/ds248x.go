@@ -0,0 +1,270 @@
+// Package ds248x implements a driver for the DS2484, DS2482-100 and
+// DS2482-800 I2C to 1-Wire bridges. The bridge generates all 1-Wire timing
+// itself, so a 1-Wire network can be driven from any I2C bus.
+//
+// Device implements the same methods as onewire.Device and can be used with
+// the 1-Wire drivers, such as ds18b20.
+//
+// Datasheets:
+// https://www.analog.com/media/en/technical-documentation/data-sheets/DS2484.pdf
+// https://www.analog.com/media/en/technical-documentation/data-sheets/DS2482-800.pdf
+package ds248x // import "tinygo.org/x/drivers/ds248x"
+
+import (
+	"errors"
+	"time"
+
+	"tinygo.org/x/drivers"
+	"tinygo.org/x/drivers/onewire"
+)
+
+var (
+	errNotFound   = errors.New("ds248x: device not found")
+	errTimeout    = errors.New("ds248x: 1-Wire busy timeout")
+	errNoPresence = errors.New("ds248x: no devices on the bus")
+	errShort      = errors.New("ds248x: 1-Wire short detected")
+	errChannel    = errors.New("ds248x: invalid channel")
+)
+
+// Config holds the bridge configuration.
+type Config struct {
+	// ActivePullup enables the active pull-up, recommended for all but the
+	// shortest networks.
+	ActivePullup bool
+	// Overdrive selects the 1-Wire overdrive speed.
+	Overdrive bool
+}
+
+// Device wraps an I2C connection to a DS248x bridge.
+type Device struct {
+	bus     drivers.I2C
+	Address uint16
+	config  uint8
+	buf     [2]byte
+}
+
+// New creates a new DS248x connection. The I2C bus must already be
+// configured.
+//
+// This function only creates the Device object, it does not touch the device.
+func New(bus drivers.I2C) *Device {
+	return &Device{
+		bus:     bus,
+		Address: Address,
+	}
+}
+
+// Configure resets the bridge and writes the configuration.
+func (d *Device) Configure(cfg Config) error {
+	status, err := d.command(cmdDeviceReset)
+	if err != nil {
+		return err
+	}
+	if status&statusRST == 0 {
+		return errNotFound
+	}
+	d.config = 0
+	if cfg.ActivePullup {
+		d.config |= configAPU
+	}
+	if cfg.Overdrive {
+		d.config |= config1WS
+	}
+	return d.writeConfig(d.config)
+}
+
+// Connected returns whether a DS248x has been found.
+func (d *Device) Connected() bool {
+	d.buf[0] = cmdSetReadPtr
+	d.buf[1] = ptrConfig
+	if err := d.bus.Tx(d.Address, d.buf[:2], d.buf[:1]); err != nil {
+		return false
+	}
+	// The upper nibble of the configuration register always reads 0.
+	return d.buf[0]&0xF0 == 0
+}
+
+// SelectChannel selects one of the eight 1-Wire channels of a DS2482-800.
+func (d *Device) SelectChannel(ch int) error {
+	if ch < 0 || ch > 7 {
+		return errChannel
+	}
+	// The channel codes written and read back are not the same.
+	code := byte(0xF0 - ch*0x0F)
+	want := byte(0xB8 - ch*0x07)
+	d.buf[0] = cmdChannelSelect
+	d.buf[1] = code
+	if err := d.bus.Tx(d.Address, d.buf[:2], d.buf[:1]); err != nil {
+		return err
+	}
+	if d.buf[0] != want {
+		return errChannel
+	}
+	return nil
+}
+
+// SetStrongPullup enables the strong pull-up after the next 1-Wire byte or
+// bit, to power a parasite device during a conversion. The bridge clears it
+// after the next 1-Wire reset or command.
+func (d *Device) SetStrongPullup() error {
+	return d.writeConfig(d.config | configSPU)
+}
+
+// Reset sends a 1-Wire reset pulse and checks for a presence pulse.
+func (d *Device) Reset() error {
+	status, err := d.command(cmd1WireReset)
+	if err != nil {
+		return err
+	}
+	if status&statusSD != 0 {
+		return errShort
+	}
+	if status&statusPPD == 0 {
+		return errNoPresence
+	}
+	return nil
+}
+
+// WriteBit transmits a bit to 1-Wire bus.
+func (d *Device) WriteBit(data uint8) {
+	d.command(cmd1WireBit, data<<7)
+}
+
+// ReadBit receives a bit from 1-Wire bus.
+func (d *Device) ReadBit() (data uint8) {
+	status, err := d.command(cmd1WireBit, 0x80)
+	if err == nil && status&statusSBR != 0 {
+		data = 1
+	}
+	return data
+}
+
+// Write transmits a byte to 1-Wire bus. (LSB first)
+func (d *Device) Write(data uint8) {
+	d.command(cmd1WireWrite, data)
+}
+
+// Read receives a byte from 1-Wire bus. (LSB first)
+func (d *Device) Read() uint8 {
+	if _, err := d.command(cmd1WireRead); err != nil {
+		return 0xFF
+	}
+	d.buf[0] = cmdSetReadPtr
+	d.buf[1] = ptrData
+	if err := d.bus.Tx(d.Address, d.buf[:2], d.buf[:1]); err != nil {
+		return 0xFF
+	}
+	return d.buf[0]
+}
+
+// ReadAddress receives a 64-bit unique ROM ID from Device. (LSB first)
+// Note: use this if there is only one slave device on the bus.
+func (d *Device) ReadAddress() ([]uint8, error) {
+	return onewire.ReadAddress(d)
+}
+
+// Select selects the address of the device for communication
+func (d *Device) Select(romid []uint8) error {
+	return onewire.Select(d, romid)
+}
+
+// Crc8 compute a Dallas Semiconductor 8 bit CRC.
+func (d *Device) Сrc8(buffer []uint8, size int) (crc uint8) {
+	return onewire.CRC8(buffer, size)
+}
+
+// Search searches for all devices on the bus using the triplet command of
+// the bridge, which reads both address bits and writes the chosen direction
+// in a single I2C transaction.
+// Note: max 32 slave devices per bus
+func (d *Device) Search(cmd uint8) ([][]uint8, error) {
+	var (
+		romIDs   [][]uint8
+		rom      [8]uint8
+		lastFork = -1
+	)
+	for {
+		if err := d.Reset(); err != nil {
+			return nil, err
+		}
+		d.Write(cmd)
+
+		lastZero := -1
+		for i := 0; i < 64; i++ {
+			dir := byte(0)
+			if i < lastFork {
+				dir = (rom[i>>3] >> (i & 7)) & 1
+			} else if i == lastFork {
+				dir = 1
+			}
+			status, err := d.command(cmd1WireTriplet, dir<<7)
+			if err != nil {
+				return nil, err
+			}
+			if status&statusSBR != 0 && status&statusTSB != 0 {
+				return nil, errNoPresence
+			}
+			if status&statusSBR == 0 && status&statusTSB == 0 && status&statusDIR == 0 {
+				lastZero = i
+			}
+			if status&statusDIR != 0 {
+				rom[i>>3] |= 1 << (i & 7)
+			} else {
+				rom[i>>3] &^= 1 << (i & 7)
+			}
+		}
+
+		if onewire.CRC8(rom[:], 7) == rom[7] {
+			id := make([]uint8, 8)
+			copy(id, rom[:])
+			romIDs = append(romIDs, id)
+		}
+		lastFork = lastZero
+		if lastFork < 0 || len(romIDs) == 32 {
+			return romIDs, nil
+		}
+	}
+}
+
+// writeConfig writes the configuration register, whose upper nibble must be
+// the complement of the lower one.
+func (d *Device) writeConfig(config uint8) error {
+	d.buf[0] = cmdWriteConfig
+	d.buf[1] = config&0x0F | ^config<<4
+	if err := d.bus.Tx(d.Address, d.buf[:2], d.buf[:1]); err != nil {
+		return err
+	}
+	if d.buf[0] != config&0x0F {
+		return errNotFound
+	}
+	return nil
+}
+
+// command sends a command with an optional parameter and waits for the
+// 1-Wire operation to finish. It returns the status register.
+func (d *Device) command(cmd uint8, param ...uint8) (uint8, error) {
+	d.buf[0] = cmd
+	n := 1
+	if len(param) > 0 {
+		d.buf[1] = param[0]
+		n = 2
+	}
+	if err := d.bus.Tx(d.Address, d.buf[:n], nil); err != nil {
+		return 0, err
+	}
+	// The read pointer is left on the status register, which can be polled
+	// until the 1-Wire operation is done. The slowest one, a reset at
+	// standard speed, takes around 1.2ms.
+	start := time.Now()
+	for {
+		if err := d.bus.Tx(d.Address, nil, d.buf[:1]); err != nil {
+			return 0, err
+		}
+		if d.buf[0]&status1WB == 0 {
+			return d.buf[0], nil
+		}
+		if time.Since(start) > 10*time.Millisecond {
+			return d.buf[0], errTimeout
+		}
+	}
+}
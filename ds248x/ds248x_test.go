@@ -0,0 +1,38 @@
+package ds248x
+
+import (
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+	"tinygo.org/x/drivers/tester"
+)
+
+func TestConfigure(t *testing.T) {
+	c := qt.New(t)
+	bus := tester.NewI2CBus(c)
+	fake := tester.NewI2CDeviceCmd(c, Address)
+	fake.Commands = map[uint8]*tester.Cmd{
+		cmdDeviceReset: {
+			Command:  []byte{cmdDeviceReset},
+			Mask:     []byte{0xFF},
+			Response: []byte{statusRST | statusLL},
+		},
+		cmdWriteConfig: {
+			// Active pull-up, with the complement in the upper nibble.
+			Command:  []byte{cmdWriteConfig, 0xE1},
+			Mask:     []byte{0xFF, 0xFF},
+			Response: []byte{configAPU},
+		},
+		cmd1WireReset: {
+			Command:  []byte{cmd1WireReset},
+			Mask:     []byte{0xFF},
+			Response: []byte{statusPPD | statusLL},
+		},
+	}
+	bus.AddDevice(fake)
+
+	dev := New(bus)
+	c.Assert(dev.Configure(Config{ActivePullup: true}), qt.IsNil)
+	c.Assert(fake.Commands[cmdWriteConfig].Invocations, qt.Equals, 1)
+	c.Assert(dev.Reset(), qt.IsNil)
+}
@@ -0,0 +1,45 @@
+package ds248x
+
+// The default I2C address, with AD0 (and AD1 on the DS2482-100) low.
+const Address = 0x18
+
+// Commands
+const (
+	cmdDeviceReset   = 0xF0
+	cmdSetReadPtr    = 0xE1
+	cmdWriteConfig   = 0xD2
+	cmdChannelSelect = 0xC3
+	cmd1WireReset    = 0xB4
+	cmd1WireBit      = 0x87
+	cmd1WireWrite    = 0xA5
+	cmd1WireRead     = 0x96
+	cmd1WireTriplet  = 0x78
+)
+
+// Read pointer codes
+const (
+	ptrStatus  = 0xF0
+	ptrData    = 0xE1
+	ptrChannel = 0xD2 // DS2482-800 only
+	ptrConfig  = 0xC3
+)
+
+// Status register bits
+const (
+	status1WB = 0x01 // 1-Wire busy
+	statusPPD = 0x02 // presence pulse detected
+	statusSD  = 0x04 // short detected
+	statusLL  = 0x08 // logic level of the line
+	statusRST = 0x10 // device reset
+	statusSBR = 0x20 // single bit result
+	statusTSB = 0x40 // triplet second bit
+	statusDIR = 0x80 // branch direction taken
+)
+
+// Configuration register bits
+const (
+	configAPU = 0x01 // active pull-up
+	configPDN = 0x02 // 1-Wire power down, DS2484 only
+	configSPU = 0x04 // strong pull-up
+	config1WS = 0x08 // 1-Wire overdrive speed
+)
@@ -2,8 +2,13 @@ package ds3231
 
 import (
 	"testing"
+
+	"tinygo.org/x/drivers/persist"
+	"tinygo.org/x/drivers/tester"
 )
 
+var _ persist.Memory = AlarmMemory{}
+
 func TestPositiveMilliCelsius(t *testing.T) {
 	t1000 := milliCelsius(0, 0)
 	if t1000 != 0 {
@@ -74,3 +79,27 @@ func TestNegativeMilliCelsius(t *testing.T) {
 		t.Fatal(t1000)
 	}
 }
+
+func TestAlarmMemory(t *testing.T) {
+	bus := tester.NewI2CBus(t)
+	fake := bus.NewDevice(Address)
+
+	dev := New(bus)
+	mem := dev.AlarmMemory()
+	if mem.Size() != 7 {
+		t.Fatal(mem.Size())
+	}
+	if _, err := mem.WriteAt([]byte{1, 2, 3}, 3); err != nil {
+		t.Fatal(err)
+	}
+	if got := fake.Registers[REG_ALARMONE+3 : REG_ALARMONE+6]; string(got) != "\x01\x02\x03" {
+		t.Fatalf("alarm registers % x", got)
+	}
+	buf := make([]byte, 2)
+	if _, err := mem.ReadAt(buf, 4); err != nil || buf[0] != 2 || buf[1] != 3 {
+		t.Fatalf("read % x, %v", buf, err)
+	}
+	if _, err := mem.ReadAt(buf, 6); err == nil {
+		t.Fatal("read past the end of the alarm registers")
+	}
+}
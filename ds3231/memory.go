@@ -0,0 +1,53 @@
+package ds3231
+
+import (
+	"errors"
+	"io"
+
+	"tinygo.org/x/drivers/internal/legacy"
+)
+
+// AlarmMemory is the 7 bytes of the two alarm registers of the DS3231, used
+// as battery-backed memory while the alarms are not used. It implements
+// persist.Memory, to keep boot counters or crash flags.
+//
+// The alarm interrupts must stay disabled (A1IE and A2IE in the control
+// register, the default), but the alarm flags of the status register may be
+// set when the time matches the bytes stored.
+type AlarmMemory struct {
+	d *Device
+}
+
+// AlarmMemory returns the alarm registers as a memory.
+func (d *Device) AlarmMemory() AlarmMemory {
+	return AlarmMemory{d: d}
+}
+
+// Size returns the size of the memory, 7 bytes.
+func (m AlarmMemory) Size() int64 {
+	return REG_ALARMONE_SIZE + REG_ALARMTWO_SIZE
+}
+
+// ReadAt reads len(data) bytes at offset off of the memory.
+func (m AlarmMemory) ReadAt(data []byte, off int64) (int, error) {
+	if off < 0 || off+int64(len(data)) > m.Size() {
+		return 0, io.EOF
+	}
+	err := legacy.ReadRegister(m.d.bus, uint8(m.d.Address), uint8(REG_ALARMONE+off), data)
+	if err != nil {
+		return 0, err
+	}
+	return len(data), nil
+}
+
+// WriteAt writes len(data) bytes at offset off of the memory.
+func (m AlarmMemory) WriteAt(data []byte, off int64) (int, error) {
+	if off < 0 || off+int64(len(data)) > m.Size() {
+		return 0, errors.New("writing outside of the alarm registers")
+	}
+	err := legacy.WriteRegister(m.d.bus, uint8(m.d.Address), uint8(REG_ALARMONE+off), data)
+	if err != nil {
+		return 0, err
+	}
+	return len(data), nil
+}
@@ -0,0 +1,194 @@
+//go:build tinygo
+
+// Package dshot implements outputs for brushless motor ESCs: the digital
+// DShot protocol and the analog OneShot family.
+//
+// DShot frames are sent by an Output. PinOutput bit-bangs the frames on any
+// pin; it needs a fast CPU (DShot150 and DShot300 work from about 100MHz,
+// DShot600 needs more) and the frames can be stretched by interrupts.
+// Targets with programmable I/O or PWM with DMA can provide a more precise
+// Output implementing the same interface.
+//
+// Protocol reference: https://brushlesswhoop.com/dshot-and-bidirectional-dshot/
+package dshot // import "tinygo.org/x/drivers/dshot"
+
+import (
+	"errors"
+	"machine"
+	"time"
+
+	"tinygo.org/x/drivers/delay"
+)
+
+var (
+	errNotArmed = errors.New("dshot: not armed")
+	errRunning  = errors.New("dshot: motor is running")
+)
+
+// Speed is the DShot bit rate.
+type Speed uint8
+
+const (
+	DShot150 Speed = iota
+	DShot300
+	DShot600
+)
+
+// Output sends DShot frames.
+type Output interface {
+	WriteFrame(frame uint16) error
+}
+
+// PinOutput bit-bangs DShot frames on a pin.
+type PinOutput struct {
+	pin   machine.Pin
+	speed Speed
+}
+
+// NewPinOutput returns a bit-banged output on the given pin.
+func NewPinOutput(pin machine.Pin, speed Speed) *PinOutput {
+	pin.Configure(machine.PinConfig{Mode: machine.PinOutput})
+	pin.Low()
+	return &PinOutput{pin: pin, speed: speed}
+}
+
+// WriteFrame sends a frame, MSB first. A one bit is high for 75% of the bit
+// time, a zero bit for 37.5%.
+func (p *PinOutput) WriteFrame(frame uint16) error {
+	switch p.speed {
+	case DShot150:
+		for i := 15; i >= 0; i-- {
+			p.pin.High()
+			if frame&(1<<i) != 0 {
+				delay.Sleep(5000 * time.Nanosecond)
+				p.pin.Low()
+				delay.Sleep(1670 * time.Nanosecond)
+			} else {
+				delay.Sleep(2500 * time.Nanosecond)
+				p.pin.Low()
+				delay.Sleep(4170 * time.Nanosecond)
+			}
+		}
+	case DShot300:
+		for i := 15; i >= 0; i-- {
+			p.pin.High()
+			if frame&(1<<i) != 0 {
+				delay.Sleep(2500 * time.Nanosecond)
+				p.pin.Low()
+				delay.Sleep(830 * time.Nanosecond)
+			} else {
+				delay.Sleep(1250 * time.Nanosecond)
+				p.pin.Low()
+				delay.Sleep(2080 * time.Nanosecond)
+			}
+		}
+	case DShot600:
+		for i := 15; i >= 0; i-- {
+			p.pin.High()
+			if frame&(1<<i) != 0 {
+				delay.Sleep(1250 * time.Nanosecond)
+				p.pin.Low()
+				delay.Sleep(420 * time.Nanosecond)
+			} else {
+				delay.Sleep(625 * time.Nanosecond)
+				p.pin.Low()
+				delay.Sleep(1040 * time.Nanosecond)
+			}
+		}
+	}
+	return nil
+}
+
+// ESC is a DShot ESC. Most ESCs disarm when they stop receiving frames, so
+// Update must be called regularly (every 1 to 10ms) to repeat the last
+// frame.
+type ESC struct {
+	out Output
+
+	// Telemetry sets the telemetry request bit, asking the ESC to send a
+	// telemetry packet on its separate telemetry wire.
+	Telemetry bool
+
+	// Bidirectional selects the inverted checksum of bidirectional DShot.
+	// Note that receiving the eRPM responses is not supported by PinOutput.
+	Bidirectional bool
+
+	armed bool
+	value uint16
+}
+
+// New returns a new ESC using the given output.
+func New(out Output) *ESC {
+	return &ESC{out: out}
+}
+
+// Arm runs the arming sequence: the ESC must receive motor stop frames for
+// a while before it accepts throttle. This blocks for about 300ms.
+func (e *ESC) Arm() error {
+	e.value = CmdMotorStop
+	deadline := time.Now().Add(300 * time.Millisecond)
+	for time.Now().Before(deadline) {
+		if err := e.send(CmdMotorStop, false); err != nil {
+			return err
+		}
+		time.Sleep(time.Millisecond)
+	}
+	e.armed = true
+	return nil
+}
+
+// Disarm stops the motor. Arm must be called again before setting a
+// throttle.
+func (e *ESC) Disarm() error {
+	e.armed = false
+	e.value = CmdMotorStop
+	return e.send(CmdMotorStop, false)
+}
+
+// Armed returns whether the arming sequence has been run.
+func (e *ESC) Armed() bool {
+	return e.armed
+}
+
+// SetThrottle sets the throttle from 0 (motor stopped) to 2000 (full
+// throttle) and sends it.
+func (e *ESC) SetThrottle(throttle uint16) error {
+	if !e.armed {
+		return errNotArmed
+	}
+	if throttle == 0 {
+		e.value = CmdMotorStop
+	} else {
+		if throttle > 2000 {
+			throttle = 2000
+		}
+		e.value = MinThrottle + throttle - 1
+	}
+	return e.send(e.value, e.Telemetry)
+}
+
+// Command sends a command CommandRepeat times. Commands are only accepted
+// while the motor is stopped; afterwards the ESC receives motor stop frames
+// again on Update.
+func (e *ESC) Command(cmd uint16) error {
+	if e.value != CmdMotorStop {
+		return errRunning
+	}
+	for i := 0; i < CommandRepeat; i++ {
+		// Commands always set the telemetry bit.
+		if err := e.send(cmd, true); err != nil {
+			return err
+		}
+		time.Sleep(time.Millisecond)
+	}
+	return nil
+}
+
+// Update sends the last throttle again.
+func (e *ESC) Update() error {
+	return e.send(e.value, e.Telemetry)
+}
+
+func (e *ESC) send(value uint16, telemetry bool) error {
+	return e.out.WriteFrame(Frame(value, telemetry, e.Bidirectional))
+}
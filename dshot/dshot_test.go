@@ -0,0 +1,18 @@
+package dshot
+
+import (
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+)
+
+func TestFrame(t *testing.T) {
+	c := qt.New(t)
+
+	// Reference values from the protocol documentation: throttle 1046
+	// without telemetry.
+	c.Assert(Frame(1046, false, false), qt.Equals, uint16(0b1000001011000110))
+	c.Assert(Frame(CmdMotorStop, false, false), qt.Equals, uint16(0))
+	c.Assert(Frame(CmdMotorStop, false, true), qt.Equals, uint16(0x000F))
+	c.Assert(Frame(1046, true, false)>>4, qt.Equals, uint16(1046<<1|1))
+}
@@ -0,0 +1,54 @@
+package dshot
+
+// Special throttle values below 48 are commands. They are only accepted
+// while the motor is stopped, and most need to be repeated (see
+// CommandRepeat) before the ESC acts on them.
+const (
+	CmdMotorStop             = 0
+	CmdBeep1                 = 1
+	CmdBeep2                 = 2
+	CmdBeep3                 = 3
+	CmdBeep4                 = 4
+	CmdBeep5                 = 5
+	CmdESCInfo               = 6
+	CmdSpinDirection1        = 7
+	CmdSpinDirection2        = 8
+	Cmd3DModeOff             = 9
+	Cmd3DModeOn              = 10
+	CmdSettingsRequest       = 11
+	CmdSaveSettings          = 12
+	CmdSpinDirectionNormal   = 20
+	CmdSpinDirectionReversed = 21
+	CmdLED0On                = 22
+	CmdLED1On                = 23
+	CmdLED2On                = 24
+	CmdLED3On                = 25
+	CmdLED0Off               = 26
+	CmdLED1Off               = 27
+	CmdLED2Off               = 28
+	CmdLED3Off               = 29
+)
+
+// CommandRepeat is the number of times a command frame is sent.
+const CommandRepeat = 10
+
+// MinThrottle and MaxThrottle are the range of throttle values in a frame.
+const (
+	MinThrottle = 48
+	MaxThrottle = 2047
+)
+
+// Frame encodes a 16 bit DShot frame: 11 bits of throttle or command, the
+// telemetry request bit and a 4 bit checksum. Bidirectional DShot uses an
+// inverted checksum.
+func Frame(value uint16, telemetry, bidirectional bool) uint16 {
+	v := (value & 0x7FF) << 1
+	if telemetry {
+		v |= 1
+	}
+	crc := v ^ v>>4 ^ v>>8
+	if bidirectional {
+		crc = ^crc
+	}
+	return v<<4 | crc&0x0F
+}
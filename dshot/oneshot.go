@@ -0,0 +1,88 @@
+//go:build tinygo
+
+package dshot
+
+import (
+	"machine"
+	"time"
+)
+
+// PWM is the interface necessary to generate OneShot pulses. It is
+// implemented by the PWM peripherals of the machine package.
+type PWM interface {
+	Configure(config machine.PWMConfig) error
+	Channel(pin machine.Pin) (channel uint8, err error)
+	Top() uint32
+	Set(channel uint8, value uint32)
+}
+
+// Protocol is an analog ESC protocol: the throttle is the pulse width,
+// between Min and Max nanoseconds, repeated every Period nanoseconds.
+type Protocol struct {
+	Min, Max uint64
+	Period   uint64
+}
+
+// Common analog protocols.
+var (
+	OneShot125 = Protocol{Min: 125000, Max: 250000, Period: 500000}
+	OneShot42  = Protocol{Min: 41667, Max: 83333, Period: 125000}
+	MultiShot  = Protocol{Min: 5000, Max: 25000, Period: 40000}
+)
+
+// OneShot is an ESC driven by a OneShot or MultiShot PWM signal.
+type OneShot struct {
+	pwm      PWM
+	channel  uint8
+	protocol Protocol
+	armed    bool
+}
+
+// NewOneShot configures the PWM peripheral for the protocol and returns an
+// ESC on the given pin. The output starts with the minimum pulse width,
+// which means motor stopped.
+func NewOneShot(pwm PWM, pin machine.Pin, protocol Protocol) (*OneShot, error) {
+	err := pwm.Configure(machine.PWMConfig{Period: protocol.Period})
+	if err != nil {
+		return nil, err
+	}
+	ch, err := pwm.Channel(pin)
+	if err != nil {
+		return nil, err
+	}
+	e := &OneShot{pwm: pwm, channel: ch, protocol: protocol}
+	e.setPulse(protocol.Min)
+	return e, nil
+}
+
+// Arm keeps the throttle at zero for the ESC to arm. This blocks for about
+// one second.
+func (e *OneShot) Arm() {
+	e.setPulse(e.protocol.Min)
+	time.Sleep(time.Second)
+	e.armed = true
+}
+
+// Disarm stops the motor.
+func (e *OneShot) Disarm() {
+	e.armed = false
+	e.setPulse(e.protocol.Min)
+}
+
+// SetThrottle sets the throttle from 0 (motor stopped) to 2000 (full
+// throttle).
+func (e *OneShot) SetThrottle(throttle uint16) error {
+	if !e.armed {
+		return errNotArmed
+	}
+	if throttle > 2000 {
+		throttle = 2000
+	}
+	p := e.protocol
+	e.setPulse(p.Min + (p.Max-p.Min)*uint64(throttle)/2000)
+	return nil
+}
+
+func (e *OneShot) setPulse(ns uint64) {
+	e.pwm.Set(e.channel, uint32(uint64(e.pwm.Top())*ns/e.protocol.Period))
+}
@@ -0,0 +1,9 @@
+package drivers
+
+import "errors"
+
+// ErrWouldBlock is returned by the non-blocking variants of long driver
+// operations while the operation is still in progress. The call should be
+// repeated later, for example in the next iteration of a control loop; the
+// driver keeps the state needed to resume where it stopped.
+var ErrWouldBlock = errors.New("operation would block")
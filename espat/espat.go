@@ -189,6 +189,7 @@ func (d *Device) Response(timeout int) ([]byte, error) {
 			return nil, errors.New("response timeout error:" + string(d.response[start:end]))
 		}
 
+		drivers.Yield()
 		time.Sleep(time.Duration(pause) * time.Millisecond)
 	}
 }
@@ -0,0 +1,26 @@
+package main
+
+import (
+	"machine"
+
+	"tinygo.org/x/drivers/ad8232"
+)
+
+func main() {
+	ecg := ad8232.New(machine.ADC0, machine.D10, machine.D11, machine.NoPin)
+	ecg.Configure(ad8232.Config{
+		SampleRate:     250,
+		MainsFrequency: 50,
+		LowPassCutoff:  40,
+	})
+
+	for {
+		v, err := ecg.Next()
+		if err != nil {
+			println("lead off")
+			continue
+		}
+		// Print in a format suitable for a serial plotter.
+		println(v)
+	}
+}
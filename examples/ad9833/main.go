@@ -0,0 +1,38 @@
+// Sweeps a sine wave from 100Hz to 10kHz, then sends FSK tones by switching
+// between the two frequency registers.
+package main
+
+import (
+	"machine"
+	"time"
+
+	"tinygo.org/x/drivers/ad9833"
+)
+
+func main() {
+	machine.SPI0.Configure(machine.SPIConfig{
+		Frequency: 4000000,
+		Mode:      2,
+	})
+	d := ad9833.New(machine.SPI0, machine.GP17)
+	if err := d.Configure(ad9833.Config{}); err != nil {
+		println("ad9833:", err.Error())
+		return
+	}
+
+	for hz := uint32(100); hz <= 10000; hz += 100 {
+		d.SetFrequency(0, hz)
+		time.Sleep(20 * time.Millisecond)
+	}
+
+	// Bell 202 tones.
+	d.SetFrequency(0, 1200)
+	d.SetFrequency(1, 2200)
+	bits := []byte{0, 1, 1, 0, 1, 0, 0, 1}
+	for {
+		for _, b := range bits {
+			d.SelectFrequency(b)
+			time.Sleep(time.Second / 1200)
+		}
+	}
+}
@@ -0,0 +1,44 @@
+// Streams the four channels of an ADS131M04 from its DRDY interrupt.
+package main
+
+import (
+	"machine"
+	"time"
+
+	"tinygo.org/x/drivers/ads131m04"
+)
+
+func main() {
+	machine.SPI0.Configure(machine.SPIConfig{
+		Frequency: 8000000,
+		Mode:      1,
+	})
+
+	adc := ads131m04.New(machine.SPI0, machine.D5)
+	adc.SetDataReadyPin(machine.D6)
+	err := adc.Configure(ads131m04.Config{
+		// A load cell on channel 0, line voltage and current on 1 and 2.
+		Gains: [ads131m04.Channels]ads131m04.Gain{ads131m04.Gain128, ads131m04.Gain1, ads131m04.Gain8, ads131m04.Gain1},
+		OSR:   ads131m04.OSR4096,
+	})
+	if err != nil {
+		println(err.Error())
+	}
+
+	ring := make([]ads131m04.Sample, 256)
+	if err := adc.StartStream(ring); err != nil {
+		println(err.Error())
+	}
+
+	var samples [64]ads131m04.Sample
+	for {
+		time.Sleep(100 * time.Millisecond)
+		n := adc.Read(samples[:])
+		if n == 0 {
+			continue
+		}
+		s := samples[n-1]
+		println("samples:", n, "overflows:", adc.Overflows(),
+			"ch0:", adc.Microvolts(0, s[0]), "µV ch1:", adc.Microvolts(1, s[1]), "µV")
+	}
+}
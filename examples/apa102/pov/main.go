@@ -0,0 +1,50 @@
+// Shows a rotating rainbow on a 36 LED APA102 strand spinning as a
+// persistence-of-vision display.
+package main
+
+import (
+	"image/color"
+	"machine"
+	"time"
+
+	"tinygo.org/x/drivers/apa102"
+)
+
+const (
+	leds   = 36
+	frames = 60
+)
+
+func main() {
+	machine.SPI0.Configure(machine.SPIConfig{
+		Frequency: 8000000,
+		Mode:      0})
+
+	a := apa102.New(machine.SPI0)
+	pov := a.NewPOV(leds, frames)
+
+	cs := make([]color.RGBA, leds)
+	for f := 0; f < frames; f++ {
+		for i := range cs {
+			cs[i] = wheel(byte((i*256/leds + f*256/frames) & 0xff))
+		}
+		pov.SetFrame(f, cs)
+	}
+
+	// 60 frames per revolution at 20 revolutions per second.
+	pov.SetPeriod(time.Second / (20 * frames))
+	pov.Run(nil)
+}
+
+func wheel(pos byte) color.RGBA {
+	switch {
+	case pos < 85:
+		return color.RGBA{R: pos * 3, G: 255 - pos*3, A: 0x40}
+	case pos < 170:
+		pos -= 85
+		return color.RGBA{R: 255 - pos*3, B: pos * 3, A: 0x40}
+	default:
+		pos -= 170
+		return color.RGBA{G: pos * 3, B: 255 - pos*3, A: 0x40}
+	}
+}
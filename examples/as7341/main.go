@@ -0,0 +1,32 @@
+package main
+
+import (
+	"machine"
+	"time"
+
+	"tinygo.org/x/drivers/as7341"
+)
+
+func main() {
+	machine.I2C0.Configure(machine.I2CConfig{})
+	sensor := as7341.New(machine.I2C0)
+
+	if !sensor.Connected() {
+		println("AS7341 not detected")
+		return
+	}
+	sensor.Configure(as7341.Config{Gain: as7341.GAIN_256X})
+
+	for {
+		ch, err := sensor.ReadChannels()
+		if err != nil {
+			println("error:", err.Error())
+			time.Sleep(time.Second)
+			continue
+		}
+		println("415nm:", ch.F1, "445nm:", ch.F2, "480nm:", ch.F3, "515nm:", ch.F4)
+		println("555nm:", ch.F5, "590nm:", ch.F6, "630nm:", ch.F7, "680nm:", ch.F8)
+		println("clear:", ch.Clear, "NIR:", ch.NIR)
+		time.Sleep(time.Second)
+	}
+}
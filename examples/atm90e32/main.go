@@ -0,0 +1,39 @@
+// Reads the three phases of an ATM90E32AS energy monitor.
+package main
+
+import (
+	"machine"
+	"time"
+
+	"tinygo.org/x/drivers"
+	"tinygo.org/x/drivers/atm90e32"
+)
+
+func main() {
+	machine.SPI0.Configure(machine.SPIConfig{
+		Frequency: 200000,
+		Mode:      3,
+	})
+
+	meter := atm90e32.New(machine.SPI0, machine.D5)
+	err := meter.Configure(atm90e32.Config{
+		// Calibration of the reference board.
+		VoltageGain: [atm90e32.Phases]uint16{7305, 7305, 7305},
+		CurrentGain: [atm90e32.Phases]uint16{27961, 27961, 27961},
+	})
+	if err != nil {
+		println(err.Error())
+	}
+
+	for {
+		if err := meter.Update(drivers.Voltage); err != nil {
+			println(err.Error())
+		}
+		for ph := 0; ph < atm90e32.Phases; ph++ {
+			println("phase", ph, "voltage:", meter.Voltage(ph), "mV current:", meter.Current(ph),
+				"mA power:", meter.ActivePower(ph), "mW energy:", meter.Energy(ph), "mWh")
+		}
+		println("frequency:", meter.Frequency(), "mHz")
+		time.Sleep(time.Second)
+	}
+}
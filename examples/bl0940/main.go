@@ -0,0 +1,30 @@
+// Reads the voltage, current, power and energy of a BL0940 based smart plug.
+package main
+
+import (
+	"machine"
+	"time"
+
+	"tinygo.org/x/drivers"
+	"tinygo.org/x/drivers/bl0940"
+)
+
+func main() {
+	uart := machine.UART1
+	uart.Configure(machine.UARTConfig{BaudRate: 4800})
+
+	meter := bl0940.New(uart)
+	if err := meter.Configure(bl0940.Config{}); err != nil {
+		println(err.Error())
+	}
+
+	for {
+		if err := meter.Update(drivers.Voltage); err != nil {
+			println(err.Error())
+		} else {
+			println("voltage:", meter.Voltage(), "mV current:", meter.Current(), "mA power:", meter.ActivePower(),
+				"mW energy:", meter.Energy(), "mWh temperature:", meter.Temperature(), "m°C")
+		}
+		time.Sleep(time.Second)
+	}
+}
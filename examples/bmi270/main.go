@@ -0,0 +1,67 @@
+package main
+
+// Smoke test for the BMI270 sensor.
+//
+// The BMI270 needs a config file to work. Copy the bmi270_config_file array
+// from the Bosch BMI270-Sensor-API (bmi270.c) into configFile before flashing.
+
+import (
+	"fmt"
+	"machine"
+	"time"
+
+	"tinygo.org/x/drivers"
+	"tinygo.org/x/drivers/bmi270"
+)
+
+// configFile must hold the contents of bmi270_config_file.
+var configFile = []byte{}
+
+func main() {
+	time.Sleep(5 * time.Second)
+
+	i2cBus := machine.I2C0
+	i2cBus.Configure(machine.I2CConfig{
+		Frequency: 400 * machine.KHz,
+	})
+
+	sensor := bmi270.NewI2C(i2cBus, bmi270.Address)
+	if !sensor.Connected() {
+		println("BMI270 not connected")
+		return
+	}
+
+	err := sensor.Configure(bmi270.Config{
+		ConfigFile: configFile,
+		AccelRange: bmi270.AccelRange4G,
+		AnyMotion:  bmi270.AnyMotionBase,
+	})
+	if err != nil {
+		println("could not configure BMI270:", err.Error())
+		return
+	}
+	sensor.EnableAnyMotion(bmi270.AnyMotionConfig{Threshold: 0xAA, Duration: 5})
+	sensor.MapInterrupts(bmi270.InterruptAnyMotion, 0)
+
+	for {
+		time.Sleep(time.Second)
+
+		err := sensor.Update(drivers.Acceleration | drivers.AngularVelocity | drivers.Temperature)
+		if err != nil {
+			println("Error reading sensor", err.Error())
+			continue
+		}
+
+		fmt.Printf("Temperature: %.2f °C\n", float32(sensor.Temperature())/1000)
+
+		accelX, accelY, accelZ := sensor.Acceleration()
+		fmt.Printf("Acceleration: %.2fg %.2fg %.2fg\n", float32(accelX)/1e6, float32(accelY)/1e6, float32(accelZ)/1e6)
+
+		gyroX, gyroY, gyroZ := sensor.AngularVelocity()
+		fmt.Printf("Rotation: %.2f°/s %.2f°/s %.2f°/s\n", float32(gyroX)/1e6, float32(gyroY)/1e6, float32(gyroZ)/1e6)
+
+		if status, _ := sensor.InterruptStatus(); status&bmi270.InterruptAnyMotion != 0 {
+			println("motion detected")
+		}
+	}
+}
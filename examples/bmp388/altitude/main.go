@@ -0,0 +1,58 @@
+// Tracks altitude and vertical speed from the BMP388/BMP390 FIFO at 50Hz.
+package main
+
+import (
+	"machine"
+	"time"
+
+	"tinygo.org/x/drivers/bmp388"
+)
+
+func main() {
+	machine.I2C0.Configure(machine.I2CConfig{Frequency: 400 * machine.KHz})
+
+	sensor := bmp388.New(machine.I2C0)
+	if !sensor.Connected() {
+		println("BMP388 not detected")
+		return
+	}
+
+	// Drone settings from table 9 of the datasheet, at a higher data rate.
+	err := sensor.Configure(bmp388.Config{
+		Pressure:    bmp388.Sampling8X,
+		Temperature: bmp388.Sampling1X,
+		ODR:         bmp388.Odr50,
+		IIR:         bmp388.Coeff3,
+		Mode:        bmp388.Normal,
+	})
+	if err != nil {
+		println(err.Error())
+		return
+	}
+	err = sensor.ConfigureFIFO(bmp388.FIFOConfig{Pressure: true, Temperature: true})
+	if err != nil {
+		println(err.Error())
+		return
+	}
+
+	// Use the current pressure as reference, so the altitude is relative to
+	// the starting point.
+	ground, _ := sensor.ReadPressure()
+	alt := bmp388.Altimeter{Reference: ground, Smoothing: 0.8}
+
+	samples := make([]bmp388.FIFOSample, 80)
+	t := time.Now()
+	for {
+		time.Sleep(500 * time.Millisecond)
+		n, err := sensor.ReadFIFO(samples)
+		if err != nil {
+			println(err.Error())
+			continue
+		}
+		for _, s := range samples[:n] {
+			t = t.Add(20 * time.Millisecond) // 50Hz
+			alt.Update(s.Pressure, t)
+		}
+		println("altitude:", alt.Altitude(), "mm, vertical speed:", alt.VerticalSpeed(), "mm/s")
+	}
+}
@@ -0,0 +1,38 @@
+package main
+
+import (
+	"machine"
+	"time"
+
+	"tinygo.org/x/drivers/cap1188"
+)
+
+func main() {
+	machine.I2C0.Configure(machine.I2CConfig{})
+	sensor := cap1188.New(machine.I2C0)
+	if !sensor.Connected() {
+		println("CAP1188 not detected")
+		return
+	}
+	err := sensor.Configure(cap1188.Config{
+		MultiTouch: true,
+		LinkLEDs:   true,
+	})
+	if err != nil {
+		println(err.Error())
+		return
+	}
+
+	sensor.SetCallbacks(func(channel uint8) {
+		println("touched", channel)
+	}, func(channel uint8) {
+		println("released", channel)
+	})
+
+	for {
+		if err := sensor.Update(); err != nil {
+			println(err.Error())
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+}
@@ -0,0 +1,37 @@
+// Shows a brightness gradient that moves across a 5x5 charlieplexed LED
+// matrix on 6 pins.
+package main
+
+import (
+	"machine"
+	"time"
+
+	"tinygo.org/x/drivers/charlieplex"
+)
+
+func main() {
+	pins := []machine.Pin{machine.GP2, machine.GP3, machine.GP4, machine.GP5, machine.GP6, machine.GP7}
+	layout, err := charlieplex.FitLayout(len(pins), 5, 5)
+	if err != nil {
+		println(err.Error())
+		return
+	}
+	display := charlieplex.New(pins)
+	err = display.Configure(charlieplex.Config{Layout: layout})
+	if err != nil {
+		println(err.Error())
+		return
+	}
+	go display.Run(200 * time.Microsecond)
+
+	levels := int16(display.Levels())
+	for offset := int16(0); ; offset++ {
+		w, h := display.Size()
+		for y := int16(0); y < h; y++ {
+			for x := int16(0); x < w; x++ {
+				display.SetLevel(x, y, uint8((x+y+offset)%(levels+1)))
+			}
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+}
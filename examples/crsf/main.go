@@ -0,0 +1,39 @@
+package main
+
+import (
+	"machine"
+	"time"
+
+	"tinygo.org/x/drivers/crsf"
+)
+
+func main() {
+	uart := machine.UART1
+	uart.Configure(machine.UARTConfig{BaudRate: 420000})
+
+	rx := crsf.New(uart)
+	frames := 0
+	for {
+		updated, err := rx.Update()
+		if err != nil {
+			println(err.Error())
+		}
+		if updated {
+			frames++
+			println("throttle:", rx.Pulse(2), "roll:", rx.Pulse(0), "LQ:", rx.Link().UplinkQuality)
+
+			// Send one telemetry frame for every few RC frames.
+			switch frames % 20 {
+			case 0:
+				rx.SendBattery(11100, 2500, 350, 80)
+			case 10:
+				rx.SendFlightMode("ACRO")
+			}
+		}
+		if rx.SignalLost() {
+			println("signal lost")
+			time.Sleep(100 * time.Millisecond)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
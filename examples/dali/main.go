@@ -0,0 +1,34 @@
+// Gives short addresses to new DALI control gear, then cycles all the lamps
+// through three levels.
+package main
+
+import (
+	"machine"
+	"time"
+
+	"tinygo.org/x/drivers/dali"
+)
+
+func main() {
+	time.Sleep(2 * time.Second)
+
+	line := dali.New(machine.GP2, machine.GP3)
+	line.Configure(dali.Config{InvertTx: true})
+	master := dali.NewMaster(line)
+
+	given, err := master.Commission(false)
+	if err != nil {
+		println("commission:", err.Error())
+	}
+	for _, addr := range given {
+		println("new gear at short address", addr)
+	}
+
+	master.SetFadeTime(dali.Broadcast, 4)
+	for {
+		for _, level := range []uint8{254, 170, 85} {
+			master.SetLevel(dali.Broadcast, level)
+			time.Sleep(3 * time.Second)
+		}
+	}
+}
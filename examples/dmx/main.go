@@ -0,0 +1,40 @@
+// Lists the RDM devices on a DMX line driven by a MAX485 transceiver, then
+// fades the first channel up and down.
+package main
+
+import (
+	"machine"
+	"time"
+
+	"tinygo.org/x/drivers/dmx"
+)
+
+func main() {
+	time.Sleep(2 * time.Second)
+
+	// DE and /RE are tied together.
+	d, err := dmx.New(machine.UART1, machine.UARTConfig{TX: machine.GP4, RX: machine.GP5}, machine.GP6)
+	if err != nil {
+		println("dmx:", err.Error())
+		return
+	}
+
+	uids, err := d.Discover()
+	if err != nil {
+		println("discover:", err.Error())
+	}
+	for _, uid := range uids {
+		println("found", uid.String())
+	}
+
+	level, step := 0, 1
+	for {
+		if sent, _ := d.Refresh(); sent {
+			level += step
+			if level == 0 || level == 255 {
+				step = -step
+			}
+			d.Set(1, uint8(level))
+		}
+	}
+}
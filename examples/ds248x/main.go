@@ -0,0 +1,47 @@
+// Reads DS18B20 temperature sensors through a DS2484 I2C to 1-Wire bridge.
+package main
+
+import (
+	"encoding/hex"
+	"machine"
+	"time"
+
+	"tinygo.org/x/drivers/ds18b20"
+	"tinygo.org/x/drivers/ds248x"
+	"tinygo.org/x/drivers/onewire"
+)
+
+func main() {
+	machine.I2C0.Configure(machine.I2CConfig{})
+
+	bridge := ds248x.New(machine.I2C0)
+	err := bridge.Configure(ds248x.Config{ActivePullup: true})
+	if err != nil {
+		println(err.Error())
+	}
+
+	romIDs, err := bridge.Search(onewire.SEARCH_ROM)
+	if err != nil {
+		println(err.Error())
+	}
+	sensor := ds18b20.New(bridge)
+
+	for {
+		for _, romid := range romIDs {
+			sensor.RequestTemperature(romid)
+		}
+
+		// wait 750ms or more for DS18B20 convert T
+		time.Sleep(1 * time.Second)
+
+		for _, romid := range romIDs {
+			t, err := sensor.ReadTemperature(romid)
+			if err != nil {
+				println(hex.EncodeToString(romid), err.Error())
+				continue
+			}
+			println(hex.EncodeToString(romid), "temperature:", t, "m°C")
+		}
+		time.Sleep(2 * time.Second)
+	}
+}
@@ -0,0 +1,31 @@
+package main
+
+import (
+	"machine"
+	"time"
+
+	"tinygo.org/x/drivers/dshot"
+)
+
+func main() {
+	esc := dshot.New(dshot.NewPinOutput(machine.GP2, dshot.DShot300))
+
+	println("arming")
+	if err := esc.Arm(); err != nil {
+		println(err.Error())
+		return
+	}
+	esc.Command(dshot.CmdBeep1)
+
+	// Ramp up to 10% throttle and back down, repeating the frame every
+	// millisecond.
+	for {
+		for _, throttle := range []uint16{0, 50, 100, 150, 200, 150, 100, 50} {
+			esc.SetThrottle(throttle)
+			for i := 0; i < 500; i++ {
+				esc.Update()
+				time.Sleep(time.Millisecond)
+			}
+		}
+	}
+}
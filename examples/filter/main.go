@@ -0,0 +1,42 @@
+// This example prints the pitch and roll of an LSM6DS3, fusing the tilt
+// from the accelerometer with the rotation from the gyroscope.
+package main
+
+import (
+	"machine"
+	"time"
+
+	"tinygo.org/x/drivers/filter"
+	"tinygo.org/x/drivers/lsm6ds3"
+)
+
+const interval = 10 * time.Millisecond
+
+func main() {
+	machine.I2C0.Configure(machine.I2CConfig{})
+
+	imu := lsm6ds3.New(machine.I2C0)
+	if err := imu.Configure(lsm6ds3.Configuration{}); err != nil {
+		for {
+			println("Failed to configure", err.Error())
+			time.Sleep(time.Second)
+		}
+	}
+
+	// The accelerometer prevails over half a second.
+	alpha := filter.One - filter.Alpha(interval, 500*time.Millisecond)
+	pitch := filter.NewComplementary(alpha)
+	roll := filter.NewComplementary(alpha)
+	for i := 0; ; i++ {
+		ax, ay, az, _ := imu.ReadAcceleration()
+		gx, gy, _, _ := imu.ReadRotation()
+		p, r := filter.Tilt(ax, ay, az)
+		// A rotation around Y lowers the X axis.
+		p = pitch.Update(-gy, p, interval)
+		r = roll.Update(gx, r, interval)
+		if i%50 == 0 {
+			println("pitch:", p/1000, "roll:", r/1000, "(milli degrees)")
+		}
+		time.Sleep(interval)
+	}
+}
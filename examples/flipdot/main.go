@@ -0,0 +1,42 @@
+// Scrolls a bar over a 28x14 flip-dot display made of two AlfaZeta XY5
+// panels, at the addresses 1 and 2, on an RS485 transceiver with automatic
+// direction control.
+package main
+
+import (
+	"machine"
+	"time"
+
+	"tinygo.org/x/drivers/flipdot"
+)
+
+func main() {
+	uart := machine.UART1
+	uart.Configure(machine.UARTConfig{BaudRate: 57600, TX: machine.GP4, RX: machine.GP5})
+
+	display := flipdot.New(uart)
+	err := display.Configure(flipdot.Config{
+		Width:  28,
+		Height: 14,
+		Panels: []flipdot.Panel{
+			{Address: 1, X: 0, Y: 0},
+			{Address: 2, X: 0, Y: 7},
+		},
+	})
+	if err != nil {
+		println(err.Error())
+		return
+	}
+
+	w, h := display.Size()
+	for x := int16(0); ; x = (x + 1) % w {
+		display.Fill(false)
+		for y := int16(0); y < h; y++ {
+			display.SetDot(x, y, true)
+		}
+		if err := display.Display(); err != nil {
+			println(err.Error())
+		}
+		time.Sleep(200 * time.Millisecond)
+	}
+}
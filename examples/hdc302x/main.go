@@ -0,0 +1,44 @@
+package main
+
+import (
+	"machine"
+	"time"
+
+	"tinygo.org/x/drivers/hdc302x"
+)
+
+func main() {
+	machine.I2C0.Configure(machine.I2CConfig{})
+	sensor := hdc302x.New(machine.I2C0)
+
+	if !sensor.Connected() {
+		println("HDC302x not detected")
+		return
+	}
+	sensor.Reset()
+
+	// Assert ALERT above 30°C or 70%, release it below 28°C and 65%.
+	sensor.SetAlert(
+		hdc302x.Thresholds{LowTemperature: -40000, HighTemperature: 30000, LowHumidity: 0, HighHumidity: 7000},
+		hdc302x.Thresholds{LowTemperature: -39000, HighTemperature: 28000, LowHumidity: 100, HighHumidity: 6500},
+	)
+	sensor.Mode = hdc302x.LPM3
+	sensor.StartAutoMeasurement(hdc302x.Rate1Hz)
+
+	for {
+		time.Sleep(2 * time.Second)
+
+		temp, humidity, err := sensor.ReadAutoMeasurement()
+		if err != nil {
+			println("error:", err.Error())
+			continue
+		}
+		println("Temperature:", temp/1000, "°C")
+		println("Humidity:", humidity/100, "%")
+
+		status, _ := sensor.ReadStatus()
+		if status&hdc302x.StatusAlert != 0 {
+			println("Alert!")
+		}
+	}
+}
@@ -0,0 +1,51 @@
+package main
+
+// A 4x4 keypad acting as a macro keyboard. The reports are printed here;
+// connect the senders to the HID endpoints of the USB stack instead.
+
+import (
+	"machine"
+	"time"
+
+	"tinygo.org/x/drivers/hid"
+	"tinygo.org/x/drivers/keypad4x4"
+)
+
+func main() {
+	keypad := keypad4x4.NewDevice(machine.D2, machine.D3, machine.D4, machine.D5, machine.D6, machine.D7, machine.D8, machine.D9)
+	keypad.Configure()
+
+	m := hid.Mapper{
+		Bindings: []hid.Binding{
+			{Input: 0, Key: hid.Key1},
+			{Input: 1, Key: hid.Key2},
+			{Input: 2, Key: hid.Key3},
+			{Input: 3, Key: hid.KeyC, Modifiers: hid.ModifierLeftCtrl},
+			{Input: 7, Key: hid.KeyV, Modifiers: hid.ModifierLeftCtrl},
+			{Input: 12, MouseButtons: hid.MouseLeft},
+			{Input: 15, Key: hid.KeyEnter},
+		},
+		KeyboardSender: hid.SenderFunc(printReport),
+		MouseSender:    hid.SenderFunc(printReport),
+	}
+
+	for {
+		var mask uint32
+		if key := keypad.GetKey(); key != keypad4x4.NoKeyPressed {
+			mask = 1 << key
+		}
+		m.SetInputs(0, mask)
+		if err := m.Flush(); err != nil {
+			println(err.Error())
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func printReport(report []byte) error {
+	for _, b := range report {
+		print(b, " ")
+	}
+	println()
+	return nil
+}
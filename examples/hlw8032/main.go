@@ -0,0 +1,32 @@
+// Reads the voltage, current, power and energy of an HLW8032 based smart
+// plug.
+package main
+
+import (
+	"machine"
+	"time"
+
+	"tinygo.org/x/drivers"
+	"tinygo.org/x/drivers/hlw8032"
+)
+
+func main() {
+	uart := machine.UART1
+	uart.Configure(machine.UARTConfig{BaudRate: 4800})
+	uart.SetFormat(8, 1, machine.ParityEven)
+
+	meter := hlw8032.New(uart)
+	meter.Configure(hlw8032.Config{})
+
+	for {
+		// The chip sends a frame every 50ms.
+		for i := 0; i < 20; i++ {
+			if err := meter.Update(drivers.Voltage); err != nil {
+				println(err.Error())
+			}
+			time.Sleep(50 * time.Millisecond)
+		}
+		println("voltage:", meter.Voltage(), "mV current:", meter.Current(), "mA power:", meter.ActivePower(),
+			"mW pf:", meter.PowerFactor(), "energy:", meter.Energy(), "mWh")
+	}
+}
@@ -0,0 +1,27 @@
+package main
+
+import (
+	"machine"
+	"time"
+
+	"tinygo.org/x/drivers/ibus"
+)
+
+func main() {
+	uart := machine.UART1
+	uart.Configure(machine.UARTConfig{BaudRate: 115200})
+
+	rx := ibus.New(uart)
+	for {
+		updated, err := rx.Update()
+		if err != nil {
+			println(err.Error())
+		}
+		if rx.SignalLost() {
+			println("signal lost")
+		} else if updated {
+			println("throttle:", rx.Pulse(2), "roll:", rx.Pulse(0), "pitch:", rx.Pulse(1))
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
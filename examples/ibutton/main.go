@@ -0,0 +1,35 @@
+// Reads DS1990A iButton keys through a 1-Wire bus driven by a UART.
+package main
+
+import (
+	"machine"
+	"time"
+
+	"tinygo.org/x/drivers/ibutton"
+	"tinygo.org/x/drivers/onewire"
+)
+
+func main() {
+	// TX and RX tied together to the probe, with a 4.7k pull-up and a diode
+	// (cathode to TX) so that TX only pulls the line low.
+	uart := machine.UART1
+	uart.Configure(machine.UARTConfig{BaudRate: 115200})
+
+	ow := onewire.NewUART(uart)
+	reader := ibutton.New(ow)
+
+	for {
+		id, ev := reader.Poll()
+		switch ev {
+		case ibutton.Touched:
+			if id.IsDS1990() {
+				println("key touched:", id.String())
+			} else {
+				println("unknown iButton family:", id.Family())
+			}
+		case ibutton.Released:
+			println("key released:", id.String())
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+}
@@ -0,0 +1,63 @@
+package main
+
+// Records a 10 second power profile to the raw blocks of an SD card, in CSV.
+// Copy the result with, for example:
+//
+//	dd if=/dev/sdX bs=512 count=4096 | strings > profile.csv
+
+import (
+	"bufio"
+	"machine"
+	"time"
+
+	"tinygo.org/x/drivers/ina260"
+	"tinygo.org/x/drivers/sdcard"
+)
+
+// cardWriter writes sequentially to the raw card.
+type cardWriter struct {
+	card   *sdcard.Device
+	offset int64
+}
+
+func (w *cardWriter) Write(b []byte) (int, error) {
+	n, err := w.card.WriteAt(b, w.offset)
+	w.offset += int64(n)
+	return n, err
+}
+
+func main() {
+	machine.I2C0.Configure(machine.I2CConfig{Frequency: 400 * machine.KHz})
+	sensor := ina260.New(machine.I2C0)
+	if !sensor.Connected() {
+		println("INA260 not detected")
+		return
+	}
+
+	card := sdcard.New(machine.SPI0, machine.SPI0_SCK_PIN, machine.SPI0_SDO_PIN, machine.SPI0_SDI_PIN, machine.GP17)
+	if err := card.Configure(); err != nil {
+		println(err.Error())
+		return
+	}
+
+	profiler, err := sensor.NewProfiler(make([]ina260.Sample, 1024), ina260.ProfilerConfig{
+		Period: 500 * time.Microsecond,
+		Format: ina260.FormatCSV,
+	})
+	if err != nil {
+		println(err.Error())
+		return
+	}
+	// Write whole blocks to the card.
+	w := bufio.NewWriterSize(&cardWriter{card: &card}, 512)
+	println("profiling")
+	if err := profiler.Run(w, 10*time.Second); err != nil {
+		println(err.Error())
+		return
+	}
+	if err := w.Flush(); err != nil {
+		println(err.Error())
+		return
+	}
+	println("done")
+}
@@ -0,0 +1,33 @@
+// Connects to an ITG-3200 I2C gyroscope and prints the rotation.
+package main
+
+import (
+	"machine"
+	"time"
+
+	"tinygo.org/x/drivers"
+	"tinygo.org/x/drivers/itg3200"
+)
+
+func main() {
+	machine.I2C0.Configure(machine.I2CConfig{})
+	gyro := itg3200.New(machine.I2C0)
+	err := gyro.Configure(itg3200.Config{
+		LowPass:           itg3200.LowPass42Hz,
+		SampleRateDivider: 9, // 100Hz
+	})
+	if err != nil {
+		println(err.Error())
+		return
+	}
+
+	for {
+		err = gyro.Update(drivers.AngularVelocity | drivers.Temperature)
+		if err != nil {
+			println(err.Error())
+		}
+		x, y, z := gyro.AngularVelocity()
+		println(x, y, z, gyro.Temperature())
+		time.Sleep(100 * time.Millisecond)
+	}
+}
@@ -0,0 +1,70 @@
+// This example sends a text message to the default channel of a Meshtastic
+// mesh every minute, and prints the text messages and positions received,
+// with the LoRa radio of the STM32WL.
+package main
+
+import (
+	"machine"
+	"time"
+
+	"tinygo.org/x/drivers/lora/meshtastic"
+	"tinygo.org/x/drivers/sx126x"
+)
+
+// nodeNum is the number of the node in the mesh, which should be unique.
+const nodeNum = 0x7A11600D
+
+func main() {
+	time.Sleep(3 * time.Second)
+
+	radio := sx126x.New(machine.SPI3)
+	radio.SetDeviceType(sx126x.DEVICE_TYPE_SX1262)
+	if err := radio.SetRadioController(sx126x.NewRadioControl()); err != nil {
+		panic(err)
+	}
+	if !radio.DetectDevice() {
+		panic("sx126x not detected")
+	}
+	radio.LoraConfig(meshtastic.LoraConfig(meshtastic.RegionEU868, meshtastic.LongFast, "", 14))
+	radio.SetSyncWord(meshtastic.SyncWordSX126x)
+
+	channel, err := meshtastic.NewChannel(meshtastic.LongFast.Name, meshtastic.DefaultKey)
+	if err != nil {
+		panic(err)
+	}
+
+	var buf [meshtastic.MaxPacketSize]byte
+	id := uint32(time.Now().UnixNano())
+	last := time.Now().Add(-time.Minute)
+	for {
+		if time.Since(last) >= time.Minute {
+			id++
+			h := meshtastic.Header{To: meshtastic.Broadcast, From: nodeNum, ID: id, HopLimit: 3, HopStart: 3}
+			pkt, err := channel.Encode(buf[:0], h, meshtastic.Text("Hello from TinyGo"))
+			if err == nil {
+				err = radio.Tx(pkt, 5000)
+			}
+			if err != nil {
+				println("tx error:", err.Error())
+			}
+			last = time.Now()
+		}
+
+		pkt, err := radio.Rx(1000)
+		if err != nil || pkt == nil {
+			continue
+		}
+		h, data, err := channel.Decode(pkt)
+		if err != nil {
+			continue
+		}
+		switch data.PortNum {
+		case meshtastic.PortText:
+			println("text from", h.From, ":", string(data.Payload))
+		case meshtastic.PortPosition:
+			if p, err := meshtastic.DecodePosition(data.Payload); err == nil {
+				println("position of", h.From, ":", p.Latitude, p.Longitude, p.Altitude)
+			}
+		}
+	}
+}
@@ -0,0 +1,98 @@
+// This example sends the readings of a sensor node to a collector node over
+// LoRa, encrypted and acknowledged. Flash it with sensor set to true on one
+// board, and to false on another. It uses a LoRa FeatherWing (SX127x)
+// connected to a PyBadge.
+package main
+
+import (
+	"machine"
+	"time"
+
+	"tinygo.org/x/drivers/lora"
+	"tinygo.org/x/drivers/lora/p2p"
+	"tinygo.org/x/drivers/sx127x"
+)
+
+const (
+	sensor    = true
+	sensorID  = 1
+	collector = 7
+)
+
+// The key shared by the nodes: change it.
+var key = []byte{
+	0x2b, 0x7e, 0x15, 0x16, 0x28, 0xae, 0xd2, 0xa6,
+	0xab, 0xf7, 0x15, 0x88, 0x09, 0xcf, 0x4f, 0x3c,
+}
+
+type reading struct {
+	Count       uint32
+	Temperature int16 // in 0.1°C
+}
+
+func main() {
+	time.Sleep(2 * time.Second)
+	machine.D11.Configure(machine.PinConfig{Mode: machine.PinOutput})
+	machine.SPI0.Configure(machine.SPIConfig{Frequency: 500000, Mode: 0})
+
+	radio := sx127x.New(machine.SPI0, machine.D11)
+	radio.SetRadioController(sx127x.NewRadioControl(machine.D10, machine.D6, machine.D9))
+	radio.Reset()
+	if !radio.DetectDevice() {
+		println("sx127x not found")
+		return
+	}
+	radio.LoraConfig(lora.Config{
+		Freq:           lora.MHz_868_1,
+		Bw:             lora.Bandwidth_125_0,
+		Sf:             lora.SpreadingFactor9,
+		Cr:             lora.CodingRate4_7,
+		HeaderType:     lora.HeaderExplicit,
+		Preamble:       12,
+		Iq:             lora.IQStandard,
+		Crc:            lora.CRCOn,
+		SyncWord:       lora.SyncPrivate,
+		LoraTxPowerDBm: 14,
+	})
+
+	address := uint8(collector)
+	if sensor {
+		address = sensorID
+	}
+	node, err := p2p.New(radio, p2p.Config{
+		Address: address,
+		Key:     key,
+		// A real node must not reuse its counters after a reset: store
+		// node.FrameCounter() in flash, or start from a random value.
+		FrameCounter: uint32(time.Now().UnixNano()),
+	})
+	if err != nil {
+		println(err.Error())
+		return
+	}
+
+	var r reading
+	for {
+		if sensor {
+			r.Count++
+			r.Temperature = 215
+			if err := node.SendValue(collector, r); err != nil {
+				println("send:", err.Error())
+			} else {
+				println("sent reading", r.Count)
+			}
+			time.Sleep(10 * time.Second)
+			continue
+		}
+		m, err := node.Receive(time.Minute)
+		if err != nil {
+			println("receive:", err.Error())
+			continue
+		}
+		if err := m.Decode(&r); err != nil {
+			println("decode:", err.Error())
+			continue
+		}
+		println("node", m.From, "reading", r.Count, "temperature", r.Temperature)
+	}
+}
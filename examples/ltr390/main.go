@@ -0,0 +1,35 @@
+// Reads the UV index and ambient light from a LTR390 sensor.
+package main
+
+import (
+	"machine"
+	"time"
+
+	"tinygo.org/x/drivers/ltr390"
+)
+
+func main() {
+	machine.I2C0.Configure(machine.I2CConfig{})
+	sensor := ltr390.New(machine.I2C0)
+	err := sensor.Configure(ltr390.Config{
+		Gain:       ltr390.Gain18,
+		Resolution: ltr390.Resolution20Bit,
+	})
+	if err != nil {
+		println(err.Error())
+		return
+	}
+
+	for {
+		uvi, err := sensor.ReadUVIndex()
+		if err != nil {
+			println(err.Error())
+		}
+		lux, err := sensor.ReadLux()
+		if err != nil {
+			println(err.Error())
+		}
+		println("UV index:", uvi/1000, ".", uvi%1000/100, "light:", lux/1000, "lx")
+		time.Sleep(time.Second)
+	}
+}
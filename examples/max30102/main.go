@@ -0,0 +1,51 @@
+package main
+
+import (
+	"machine"
+	"time"
+
+	"tinygo.org/x/drivers/max30102"
+)
+
+func main() {
+	machine.I2C0.Configure(machine.I2CConfig{Frequency: 400 * machine.KHz})
+	sensor := max30102.New(machine.I2C0)
+
+	if !sensor.Connected() {
+		println("MAX30102 not detected")
+		return
+	}
+	err := sensor.Configure(max30102.Config{
+		Mode:          max30102.ModeSpO2,
+		SampleRate:    max30102.SampleRate100,
+		PulseWidth:    max30102.PulseWidth411,
+		ADCRange:      max30102.ADCRange16384,
+		SampleAverage: max30102.SampleAverage1,
+		RedCurrent:    7000,
+		IRCurrent:     7000,
+	})
+	if err != nil {
+		println(err.Error())
+		return
+	}
+
+	est := max30102.Estimator{SampleRate: 100}
+	red := make([]uint32, 32)
+	ir := make([]uint32, 32)
+	for {
+		time.Sleep(100 * time.Millisecond)
+		n, err := sensor.ReadFIFO(red, ir)
+		if err != nil {
+			println(err.Error())
+			continue
+		}
+		for i := 0; i < n; i++ {
+			if est.Add(red[i], ir[i]) {
+				println("beat! HR:", est.HeartRate(), "bpm, SpO2:", est.SpO2(), "%")
+			}
+		}
+		if !est.FingerPresent() {
+			println("no finger")
+		}
+	}
+}
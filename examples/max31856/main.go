@@ -0,0 +1,41 @@
+// Reads a type K thermocouple with a MAX31856.
+package main
+
+import (
+	"machine"
+	"time"
+
+	"tinygo.org/x/drivers"
+	"tinygo.org/x/drivers/max31856"
+)
+
+func main() {
+	machine.SPI0.Configure(machine.SPIConfig{
+		Frequency: 1000000,
+		Mode:      1,
+	})
+
+	sensor := max31856.New(machine.SPI0, machine.D5)
+	sensor.SetDataReadyPin(machine.D6)
+	err := sensor.Configure(max31856.Config{
+		Type:       max31856.TypeK,
+		Averaging:  4,
+		Continuous: true,
+	})
+	if err != nil {
+		println(err.Error())
+	}
+
+	for {
+		if sensor.Ready() {
+			err := sensor.Update(drivers.Temperature)
+			if err != nil {
+				println(err.Error())
+				sensor.ClearFault()
+			} else {
+				println("thermocouple:", sensor.Temperature(), "m°C, cold junction:", sensor.ColdJunction(), "m°C")
+			}
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+}
@@ -0,0 +1,37 @@
+// Reads a MiCS-5524 gas sensor and prints an indicative CO concentration once
+// the heater is warm.
+package main
+
+import (
+	"machine"
+	"time"
+
+	"tinygo.org/x/drivers/mics5524"
+	"tinygo.org/x/drivers/mox"
+)
+
+// Supply voltage and load resistor of the sensor, adjust them to the values
+// of your board.
+const (
+	vcc  = 5000000 // µV
+	load = 10000   // Ω
+)
+
+func main() {
+	machine.InitADC()
+	sensor := mics5524.New(machine.ADC0, machine.NoPin)
+	sensor.Configure(mics5524.Config{})
+
+	var baseline mox.Baseline
+	for {
+		time.Sleep(time.Second)
+		if !sensor.Ready() {
+			println("warming up,", sensor.WarmUpRemaining()/time.Second, "s left")
+			continue
+		}
+		v := sensor.ReadVoltage()
+		rs := mox.Resistance(v, vcc, load)
+		r0 := baseline.Update(rs, time.Now())
+		println("CO:", v, "µV", rs, "Ω, R0", r0, "Ω, ~", int32(mox.MiCS5524CO.PPM(rs, r0)), "ppm")
+	}
+}
@@ -0,0 +1,34 @@
+// Plays a harmony a fifth above the notes received on the MIDI in port, and
+// echoes everything else to the MIDI out port. The ports are on UART1 with
+// the usual optocoupler and resistor circuits.
+package main
+
+import (
+	"machine"
+
+	"tinygo.org/x/drivers/midi"
+)
+
+func main() {
+	uart := machine.UART1
+	uart.Configure(machine.UARTConfig{BaudRate: 31250, TX: machine.GP4, RX: machine.GP5})
+
+	d := midi.New(uart)
+	d.RunningStatus = true
+	d.OnMessage = func(m midi.Message) {
+		d.Send(m)
+		if m.Command() == midi.NoteOn || m.Command() == midi.NoteOff {
+			if m.Note() < 128-7 {
+				m.Data1 += 7
+				d.Send(m)
+			}
+		}
+	}
+	d.OnSysEx = func(data []byte, done bool) {
+		println("sysex", len(data), "bytes")
+	}
+
+	for {
+		d.Poll()
+	}
+}
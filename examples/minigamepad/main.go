@@ -0,0 +1,30 @@
+package main
+
+import (
+	"machine"
+	"time"
+
+	"tinygo.org/x/drivers/minigamepad"
+)
+
+func main() {
+	machine.I2C0.Configure(machine.I2CConfig{})
+	pad := minigamepad.New(machine.I2C0)
+	if err := pad.Configure(); err != nil {
+		println(err.Error())
+		return
+	}
+
+	for {
+		if err := pad.Update(); err != nil {
+			println(err.Error())
+		} else {
+			s := pad.State
+			if s.Pressed(minigamepad.ButtonA) {
+				println("A pressed")
+			}
+			println("buttons:", s.Buttons, "stick:", s.X, s.Y)
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+}
@@ -0,0 +1,39 @@
+// Polls the first holding registers of a Modbus RTU slave at address 1 over
+// a MAX485 transceiver, and toggles its first coil.
+package main
+
+import (
+	"machine"
+	"time"
+
+	"tinygo.org/x/drivers/modbus"
+	"tinygo.org/x/drivers/rs485"
+)
+
+func main() {
+	uart := machine.UART1
+	uart.Configure(machine.UARTConfig{BaudRate: 9600, TX: machine.GP4, RX: machine.GP5})
+
+	// DE and /RE are tied together.
+	bus := rs485.New(uart, machine.GP6, machine.NoPin)
+	bus.Configure(rs485.Config{BaudRate: 9600})
+
+	master := modbus.NewMaster(bus)
+	master.Timeout = 200 * time.Millisecond
+
+	regs := make([]uint16, 4)
+	on := false
+	for {
+		if err := master.ReadHoldingRegisters(1, 0, regs); err != nil {
+			println("read:", err.Error())
+		} else {
+			println("registers:", regs[0], regs[1], regs[2], regs[3])
+		}
+
+		on = !on
+		if err := master.WriteSingleCoil(1, 0, on); err != nil {
+			println("write:", err.Error())
+		}
+		time.Sleep(time.Second)
+	}
+}
@@ -0,0 +1,46 @@
+// Makes the board a Modbus RTU slave at address 1 on a MAX485 transceiver.
+// The first input register is the uptime in seconds, and the first coil
+// drives the LED.
+package main
+
+import (
+	"machine"
+	"time"
+
+	"tinygo.org/x/drivers/modbus"
+	"tinygo.org/x/drivers/rs485"
+)
+
+func main() {
+	led := machine.LED
+	led.Configure(machine.PinConfig{Mode: machine.PinOutput})
+
+	uart := machine.UART1
+	uart.Configure(machine.UARTConfig{BaudRate: 9600, TX: machine.GP4, RX: machine.GP5})
+
+	// DE and /RE are tied together.
+	bus := rs485.New(uart, machine.GP6, machine.NoPin)
+	bus.Configure(rs485.Config{BaudRate: 9600})
+
+	coils := make([]bool, 1)
+	inputs := make([]uint16, 1)
+	holding := make([]uint16, 8)
+
+	slave := modbus.NewSlave(1)
+	slave.AddCoils(0, coils)
+	slave.AddInputRegisters(0, inputs)
+	slave.AddHoldingRegisters(0, holding)
+	slave.OnWrite = func(table modbus.Table, addr, count uint16) {
+		if table == modbus.Coils {
+			led.Set(coils[0])
+		}
+	}
+
+	start := time.Now()
+	for {
+		inputs[0] = uint16(time.Since(start) / time.Second)
+		if err := slave.PollRTU(bus); err != nil {
+			println("poll:", err.Error())
+		}
+	}
+}
@@ -0,0 +1,35 @@
+package main
+
+import (
+	"machine"
+	"time"
+
+	"tinygo.org/x/drivers/mpr121"
+)
+
+func main() {
+	machine.I2C0.Configure(machine.I2CConfig{})
+	sensor := mpr121.New(machine.I2C0)
+	err := sensor.Configure(mpr121.Config{
+		TouchThreshold:   12,
+		ReleaseThreshold: 6,
+	})
+	if err != nil {
+		println(err.Error())
+		return
+	}
+
+	sensor.SetCallbacks(func(electrode uint8) {
+		println("touched", electrode)
+	}, func(electrode uint8) {
+		println("released", electrode)
+	})
+	sensor.EnableInterrupt(machine.D9)
+
+	for {
+		if err := sensor.Update(); err != nil {
+			println(err.Error())
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
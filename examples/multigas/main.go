@@ -0,0 +1,46 @@
+// Reads the Grove multichannel gas sensor and prints an indicative CO
+// concentration once the heaters are warm.
+package main
+
+import (
+	"machine"
+	"time"
+
+	"tinygo.org/x/drivers/mox"
+	"tinygo.org/x/drivers/multigas"
+)
+
+// Supply voltage and load resistor of the GM sensors, adjust them to the
+// values of your board.
+const (
+	vcc  = 3300000 // µV
+	load = 10000   // Ω
+)
+
+func main() {
+	machine.I2C0.Configure(machine.I2CConfig{})
+	sensor := multigas.New(machine.I2C0)
+	err := sensor.Configure(multigas.Config{})
+	if err != nil {
+		println(err.Error())
+		return
+	}
+
+	var baseline mox.Baseline
+	for {
+		time.Sleep(time.Second)
+		if !sensor.Ready() {
+			println("warming up,", sensor.WarmUpRemaining()/time.Second, "s left")
+			continue
+		}
+		v, err := sensor.ReadVoltage(multigas.CO)
+		if err != nil {
+			println(err.Error())
+			continue
+		}
+		rs := mox.Resistance(v, vcc, load)
+		r0 := baseline.Update(rs, time.Now())
+		ppm := mox.GM702BCO.PPM(rs, r0)
+		println("CO:", v, "µV", rs, "Ω, R0", r0, "Ω, ~", int32(ppm), "ppm")
+	}
+}
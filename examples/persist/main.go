@@ -0,0 +1,38 @@
+// Counts the boots of the board in the SRAM of a DS1307, and tells whether
+// the last run ended cleanly, by keeping a flag set while the work is done.
+package main
+
+import (
+	"machine"
+	"time"
+
+	"tinygo.org/x/drivers/ds1307"
+	"tinygo.org/x/drivers/persist"
+)
+
+const running = 1
+
+func main() {
+	machine.I2C0.Configure(machine.I2CConfig{})
+	rtc := ds1307.New(machine.I2C0)
+
+	boots := persist.NewCell(&rtc, 0, 4)
+	state := persist.NewCell(&rtc, boots.Len(), 1)
+
+	n, err := boots.Add(1)
+	if err != nil {
+		println("could not count the boot:", err.Error())
+	}
+	println("boot", n)
+	if s, _ := state.Load(); s == running {
+		println("the last run was interrupted")
+	}
+
+	state.Store(running)
+	for i := 0; i < 10; i++ {
+		println("working...")
+		time.Sleep(time.Second)
+	}
+	state.Store(0)
+	println("done, reset the board to count another boot")
+}
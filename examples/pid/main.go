@@ -0,0 +1,60 @@
+// This example keeps a chamber at 45°C, with a thermistor on GP26 and a
+// heater switched by a MOSFET on GP8.
+package main
+
+import (
+	"machine"
+	"time"
+
+	"tinygo.org/x/drivers/pid"
+	"tinygo.org/x/drivers/thermistor"
+)
+
+const (
+	setpoint = 45000 // milli degrees Celsius
+	interval = 500 * time.Millisecond
+)
+
+var (
+	pwm    = machine.PWM4
+	heater = machine.GP8
+)
+
+func main() {
+	machine.InitADC()
+	sensor := thermistor.New(machine.ADC0)
+	sensor.Configure()
+
+	err := pwm.Configure(machine.PWMConfig{Period: 1e9 / 100}) // 100Hz
+	if err != nil {
+		println(err.Error())
+		return
+	}
+	ch, err := pwm.Channel(heater)
+	if err != nil {
+		println(err.Error())
+		return
+	}
+
+	// The output is the duty cycle, in thousandths.
+	control := pid.New(pid.Config{
+		Kp:               pid.One / 10,
+		Ki:               pid.One / 200,
+		Kd:               pid.One / 2,
+		Max:              1000,
+		DerivativeFilter: 2,
+	})
+	for {
+		temp, err := sensor.ReadTemperature()
+		if err != nil {
+			println(err.Error())
+			pwm.Set(ch, 0)
+			control.Reset()
+		} else {
+			duty := control.Update(setpoint, temp, interval)
+			pwm.Set(ch, pwm.Top()*uint32(duty)/1000)
+			println("temperature:", temp, "duty:", duty)
+		}
+		time.Sleep(interval)
+	}
+}
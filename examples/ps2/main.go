@@ -0,0 +1,39 @@
+package main
+
+import (
+	"machine"
+	"time"
+
+	"tinygo.org/x/drivers/ps2"
+)
+
+func main() {
+	port := ps2.New(machine.D2, machine.D3)
+	if err := port.Configure(); err != nil {
+		println(err.Error())
+		return
+	}
+
+	keyboard := ps2.NewKeyboard(port)
+	if err := keyboard.Configure(); err != nil {
+		println(err.Error())
+	}
+
+	for {
+		e, ok := keyboard.ReadEvent()
+		if !ok {
+			time.Sleep(time.Millisecond)
+			continue
+		}
+		if !e.Pressed {
+			continue
+		}
+		switch e.Scancode {
+		case ps2.ScancodeCapsLock, ps2.ScancodeNumLock, ps2.ScancodeScrollLock:
+			keyboard.SetLEDs()
+		}
+		if e.Rune != 0 {
+			print(string(e.Rune))
+		}
+	}
+}
@@ -0,0 +1,26 @@
+package main
+
+// Measures the speed of a PC fan from its tachometer output, which pulses
+// twice per revolution.
+
+import (
+	"machine"
+	"time"
+
+	"tinygo.org/x/drivers/pulse"
+)
+
+func main() {
+	tach := pulse.New(machine.D2)
+	if err := tach.Configure(machine.PinInputPullup); err != nil {
+		println(err.Error())
+		return
+	}
+
+	for {
+		time.Sleep(time.Second)
+		freq, duty := tach.Average()
+		rpm := freq * 60 / 2 / 1000
+		println("rpm:", rpm, "duty:", duty, "‰", "pulses:", tach.Count())
+	}
+}
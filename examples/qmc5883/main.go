@@ -0,0 +1,43 @@
+package main
+
+import (
+	"machine"
+	"time"
+
+	"tinygo.org/x/drivers/compass"
+	"tinygo.org/x/drivers/qmc5883"
+)
+
+func main() {
+	machine.I2C0.Configure(machine.I2CConfig{})
+	sensor := qmc5883.New(machine.I2C0)
+
+	if !sensor.Connected() {
+		for {
+			println("QMC5883L not connected!")
+			time.Sleep(1 * time.Second)
+		}
+	}
+
+	sensor.Configure(qmc5883.Configuration{DataRate: qmc5883.DATARATE_50HZ})
+
+	println("Calibrating: rotate the sensor in all directions for 20 seconds")
+	var cal compass.Calibrator
+	cal.Collect(sensor.ReadMagneticField, 20*time.Second, 20*time.Millisecond)
+	calibration, ok := cal.EllipsoidFit()
+	if !ok {
+		calibration = cal.MinMax()
+	}
+
+	for {
+		x, y, z, err := sensor.ReadMagneticField()
+		if err != nil {
+			println("Error reading sensor", err.Error())
+			continue
+		}
+		x, y, _ = calibration.Apply(x, y, z)
+		println("Heading:", compass.Heading(x, y))
+
+		time.Sleep(time.Millisecond * 100)
+	}
+}
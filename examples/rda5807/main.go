@@ -0,0 +1,52 @@
+// Seeks the first FM station, and prints its name and radio text. The radio
+// only uses the tuner interface, and works the same with an Si4703.
+package main
+
+import (
+	"machine"
+	"time"
+
+	"tinygo.org/x/drivers/rda5807"
+	"tinygo.org/x/drivers/tuner"
+)
+
+func main() {
+	time.Sleep(2 * time.Second)
+	machine.I2C0.Configure(machine.I2CConfig{SDA: machine.GP4, SCL: machine.GP5})
+
+	d := rda5807.New(machine.I2C0)
+	err := d.Configure(rda5807.Config{
+		DeEmphasis50us: true,
+		Volume:         8,
+	})
+	if err != nil {
+		println("rda5807:", err.Error())
+		return
+	}
+	radio(d)
+}
+
+func radio(t tuner.Tuner) {
+	t.Tune(87500)
+	freq, err := t.Seek(true)
+	if err != nil {
+		println("seek:", err.Error())
+	}
+	rssi, _ := t.RSSI()
+	println("tuned", freq, "kHz, RSSI", rssi)
+
+	name, text := "", ""
+	for {
+		if ok, _ := t.ReadRDS(); ok {
+			if n := t.RDS().StationName(); n != name {
+				name = n
+				println("station:", name)
+			}
+			if s := t.RDS().RadioText(); s != text {
+				text = s
+				println("text:", text)
+			}
+		}
+		time.Sleep(40 * time.Millisecond)
+	}
+}
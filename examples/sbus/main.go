@@ -0,0 +1,29 @@
+package main
+
+import (
+	"machine"
+	"time"
+
+	"tinygo.org/x/drivers/sbus"
+)
+
+func main() {
+	// SBUS is inverted, so an external inverter is needed on most boards.
+	uart := machine.UART1
+	uart.Configure(machine.UARTConfig{BaudRate: 100000})
+	uart.SetFormat(8, 2, machine.ParityEven)
+
+	rx := sbus.New(uart)
+	for {
+		updated, err := rx.Update()
+		if err != nil {
+			println(err.Error())
+		}
+		if rx.SignalLost() {
+			println("signal lost")
+		} else if updated {
+			println("throttle:", rx.Pulse(2), "roll:", rx.Pulse(0), "pitch:", rx.Pulse(1))
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
@@ -0,0 +1,44 @@
+// Reads CO2, temperature and humidity from a SCD30 sensor.
+package main
+
+import (
+	"machine"
+	"time"
+
+	"tinygo.org/x/drivers"
+	"tinygo.org/x/drivers/scd30"
+)
+
+func main() {
+	machine.I2C0.Configure(machine.I2CConfig{Frequency: 50000})
+	sensor := scd30.New(machine.I2C0)
+	err := sensor.Configure(scd30.Config{
+		Interval: 5 * time.Second,
+		Pressure: 1013,
+	})
+	if err != nil {
+		println(err.Error())
+		return
+	}
+	if err := sensor.SetAutoCalibration(true); err != nil {
+		println(err.Error())
+	}
+
+	for {
+		time.Sleep(time.Second)
+		ready, err := sensor.DataReady()
+		if err != nil {
+			println(err.Error())
+			continue
+		}
+		if !ready {
+			continue
+		}
+		err = sensor.Update(drivers.Concentration | drivers.Temperature | drivers.Humidity)
+		if err != nil {
+			println(err.Error())
+			continue
+		}
+		println("CO2:", sensor.CO2(), "ppm, temperature:", sensor.Temperature(), "m°C, humidity:", sensor.Humidity())
+	}
+}
@@ -244,6 +244,7 @@ func lsblk(argv []string) {
 			"-ManufacturingMonth     %02X\r\n"+
 			"-Always1                %d\r\n"+
 			"-CRC                    %02X\r\n"+
+			"-Card                   %s\r\n"+
 			"-------------------------------------\r\n\r\n",
 		"attrs.JedecID",         // attrs.JedecID,
 		cid.ProductSerialNumber, // serialNumber1,
@@ -267,6 +268,7 @@ func lsblk(argv []string) {
 		cid.ManufacturingMonth,
 		cid.Always1,
 		cid.CRC,
+		cid,
 	)
 }
 
@@ -0,0 +1,38 @@
+// This example counts the boots of a device and keeps a name, in the first
+// kilobyte of an AT24C32 EEPROM.
+package main
+
+import (
+	"machine"
+	"time"
+
+	"tinygo.org/x/drivers/at24cx"
+	"tinygo.org/x/drivers/settings"
+)
+
+func main() {
+	machine.I2C0.Configure(machine.I2CConfig{})
+	eeprom := at24cx.New(machine.I2C0)
+	eeprom.Configure(at24cx.Config{})
+
+	store := settings.New(&eeprom, 0, 1024)
+	boots := store.Uint("boots", 0)
+	name := store.String("name", "tinygo")
+	err := store.Load()
+	switch err {
+	case nil:
+	case settings.ErrNoSettings:
+		println("first boot")
+	default:
+		println("could not load the settings:", err.Error())
+	}
+
+	*boots++
+	if err := store.Commit(); err != nil {
+		println("could not commit the settings:", err.Error())
+	}
+	for {
+		println(*name, "booted", *boots, "times")
+		time.Sleep(time.Second)
+	}
+}
@@ -0,0 +1,49 @@
+// Seeks the first FM station, and prints its name and radio text.
+package main
+
+import (
+	"machine"
+	"time"
+
+	"tinygo.org/x/drivers/si4703"
+)
+
+func main() {
+	time.Sleep(2 * time.Second)
+	si4703.Reset(machine.GP6, machine.GP4)
+	machine.I2C0.Configure(machine.I2CConfig{SDA: machine.GP4, SCL: machine.GP5})
+
+	d := si4703.New(machine.I2C0)
+	err := d.Configure(si4703.Config{
+		Spacing:        si4703.Spacing100kHz,
+		DeEmphasis50us: true,
+		Volume:         8,
+	})
+	if err != nil {
+		println("si4703:", err.Error())
+		return
+	}
+
+	d.Tune(87500)
+	freq, err := d.Seek(true)
+	if err != nil {
+		println("seek:", err.Error())
+	}
+	rssi, _ := d.RSSI()
+	println("tuned", freq, "kHz, RSSI", rssi)
+
+	name, text := "", ""
+	for {
+		if ok, _ := d.ReadRDS(); ok {
+			if n := d.RDS().StationName(); n != name {
+				name = n
+				println("station:", name)
+			}
+			if t := d.RDS().RadioText(); t != text {
+				text = t
+				println("text:", text)
+			}
+		}
+		time.Sleep(40 * time.Millisecond)
+	}
+}
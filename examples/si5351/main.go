@@ -0,0 +1,36 @@
+// Makes a 7.074MHz quadrature clock on CLK0 and CLK1 for a direct
+// conversion receiver, and a 10MHz reference on CLK2.
+package main
+
+import (
+	"machine"
+	"time"
+
+	"tinygo.org/x/drivers/si5351"
+)
+
+func main() {
+	time.Sleep(2 * time.Second)
+	machine.I2C0.Configure(machine.I2CConfig{})
+
+	d := si5351.New(machine.I2C0)
+	if err := d.Configure(si5351.Config{}); err != nil {
+		println("si5351:", err.Error())
+		return
+	}
+
+	// Same PLL and frequency, CLK1 a quarter period late.
+	d.SetFrequency(0, si5351.PLLA, 7074000)
+	d.SetFrequency(1, si5351.PLLA, 7074000)
+	if err := d.SetPhase(1, 90); err != nil {
+		println("phase:", err.Error())
+	}
+	d.SetFrequency(2, si5351.PLLB, 10000000)
+	for i := uint8(0); i < 3; i++ {
+		d.Enable(i, true)
+	}
+
+	for {
+		time.Sleep(time.Second)
+	}
+}
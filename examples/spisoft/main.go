@@ -0,0 +1,26 @@
+// Connects to a MCP3008 ADC over a software SPI bus on arbitrary pins.
+package main
+
+import (
+	"machine"
+	"time"
+
+	"tinygo.org/x/drivers/mcp3008"
+	"tinygo.org/x/drivers/spisoft"
+)
+
+func main() {
+	spi := spisoft.New(machine.D2, machine.D3, machine.D4)
+	spi.Configure(spisoft.SPIConfig{
+		Frequency: 500000,
+		Mode:      0,
+	})
+
+	adc := mcp3008.New(spi, machine.D5)
+	adc.Configure()
+
+	for {
+		println(adc.CH0.Get())
+		time.Sleep(50 * time.Millisecond)
+	}
+}
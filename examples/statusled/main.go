@@ -0,0 +1,36 @@
+// Shows the state of a (simulated) network connection on a single WS2812
+// LED.
+package main
+
+import (
+	"image/color"
+	"machine"
+	"time"
+
+	"tinygo.org/x/drivers/statusled"
+	"tinygo.org/x/drivers/ws2812"
+)
+
+func main() {
+	pin := machine.D2
+	pin.Configure(machine.PinConfig{Mode: machine.PinOutput})
+	ws := ws2812.New(pin)
+
+	led := statusled.New(statusled.OutputFunc(func(c color.RGBA) error {
+		return ws.WriteColors([]color.RGBA{c})
+	}))
+	led.Brightness = 64
+	go led.Run()
+
+	led.SetPattern(statusled.Boot)
+	time.Sleep(time.Second)
+
+	for {
+		led.SetPattern(statusled.NetworkConnecting)
+		time.Sleep(5 * time.Second)
+		led.SetPattern(statusled.NetworkConnected)
+		time.Sleep(10 * time.Second)
+		led.SetPattern(statusled.ErrorCode(3))
+		time.Sleep(10 * time.Second)
+	}
+}
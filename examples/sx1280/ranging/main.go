@@ -0,0 +1,71 @@
+// Ranging between two SX1280 modules (such as the Lambda80) on Raspberry Pi
+// Picos. Connect GP15 to ground on one of them: it answers the ranging
+// requests, and the other one prints the distance between them.
+package main
+
+import (
+	"machine"
+	"time"
+
+	"tinygo.org/x/drivers/lora"
+	"tinygo.org/x/drivers/sx1280"
+)
+
+const address = 0x32101234
+
+var (
+	spi                      = machine.SPI1
+	nssPin, busyPin, dio1Pin = machine.GP13, machine.GP6, machine.GP7
+	rstPin, rolePin          = machine.GP14, machine.GP15
+)
+
+func main() {
+	time.Sleep(2 * time.Second)
+	spi.Configure(machine.SPIConfig{
+		Mode:      0,
+		Frequency: 8 * 1e6,
+		SDO:       machine.SPI1_SDO_PIN,
+		SDI:       machine.SPI1_SDI_PIN,
+		SCK:       machine.SPI1_SCK_PIN,
+	})
+	rolePin.Configure(machine.PinConfig{Mode: machine.PinInputPullup})
+
+	radio := sx1280.New(spi)
+	rc := sx1280.NewRadioControl(nssPin, busyPin, dio1Pin, rstPin, machine.NoPin, machine.NoPin)
+	if err := radio.SetRadioController(rc); err != nil {
+		println("radio controller:", err.Error())
+		return
+	}
+	if !radio.DetectDevice() {
+		println("sx1280 not found")
+		return
+	}
+	radio.LoraConfig(lora.Config{
+		Freq:           2445000000,
+		Sf:             lora.SpreadingFactor9,
+		Bw:             lora.Bandwidth_1625_0,
+		Cr:             lora.CodingRate4_5,
+		Preamble:       12,
+		Crc:            lora.CRCOn,
+		LoraTxPowerDBm: 10,
+	})
+
+	if !rolePin.Get() {
+		println("answering ranging requests")
+		for {
+			if err := radio.RespondRanging(address, 0); err != nil {
+				println(err.Error())
+			}
+		}
+	}
+
+	for {
+		d, err := radio.RangeMedian(address, 20, 100)
+		if err != nil {
+			println(err.Error())
+		} else {
+			println("distance:", d, "cm")
+		}
+		time.Sleep(time.Second)
+	}
+}
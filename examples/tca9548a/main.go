@@ -0,0 +1,35 @@
+// Reads two VL53L1X distance sensors with the same address, connected to
+// channels 0 and 1 of a TCA9548A multiplexer.
+package main
+
+import (
+	"machine"
+	"time"
+
+	"tinygo.org/x/drivers/tca9548a"
+	"tinygo.org/x/drivers/vl53l1x"
+)
+
+func main() {
+	machine.I2C0.Configure(machine.I2CConfig{Frequency: 400000})
+	mux := tca9548a.New(machine.I2C0)
+
+	var sensors [2]vl53l1x.Device
+	for i := range sensors {
+		sensors[i] = vl53l1x.New(mux.Channel(i))
+		if !sensors[i].Configure(true) {
+			println("sensor", i, "not found")
+			return
+		}
+		sensors[i].SetMeasurementTimingBudget(50000)
+		sensors[i].StartContinuous(50)
+	}
+
+	for {
+		for i := range sensors {
+			sensors[i].Read(true)
+			println("sensor", i, ":", sensors[i].Distance(), "mm")
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+}
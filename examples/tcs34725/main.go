@@ -0,0 +1,33 @@
+package main
+
+import (
+	"machine"
+	"time"
+
+	"tinygo.org/x/drivers/tcs34725"
+)
+
+func main() {
+	machine.I2C0.Configure(machine.I2CConfig{})
+	sensor := tcs34725.New(machine.I2C0)
+	sensor.SetLEDPin(machine.D2)
+
+	if !sensor.Connected() {
+		println("TCS34725 not detected")
+		return
+	}
+	sensor.Configure(tcs34725.Config{IntegrationTime: 154000, Gain: tcs34725.GAIN_4X})
+	sensor.SetLED(true)
+
+	for {
+		time.Sleep(200 * time.Millisecond)
+
+		c, r, g, b, err := sensor.ReadRawColor()
+		if err != nil {
+			println("error:", err.Error())
+			continue
+		}
+		lux, cct := sensor.Lux(c, r, g, b)
+		println("C:", c, "R:", r, "G:", g, "B:", b, "lux:", lux/1000, "CCT:", cct, "K")
+	}
+}
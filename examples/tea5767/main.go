@@ -0,0 +1,42 @@
+// Scans the FM band, and prints the stations found with their signal level.
+// The scan only uses the tuner interface, and works the same with an Si4703
+// or an RDA5807M.
+package main
+
+import (
+	"machine"
+	"time"
+
+	"tinygo.org/x/drivers/tea5767"
+	"tinygo.org/x/drivers/tuner"
+)
+
+func main() {
+	time.Sleep(2 * time.Second)
+	machine.I2C0.Configure(machine.I2CConfig{SDA: machine.GP4, SCL: machine.GP5})
+
+	d := tea5767.New(machine.I2C0)
+	err := d.Configure(tea5767.Config{
+		DeEmphasis50us: true,
+		SeekLevel:      tea5767.SeekLevelMid,
+	})
+	if err != nil {
+		println("tea5767:", err.Error())
+		return
+	}
+	scan(d)
+}
+
+func scan(t tuner.Tuner) {
+	t.Tune(87500)
+	for {
+		freq, err := t.Seek(true)
+		if err != nil {
+			println("end of band:", err.Error())
+			return
+		}
+		rssi, _ := t.RSSI()
+		stereo, _ := t.Stereo()
+		println(freq, "kHz, level", rssi, "stereo", stereo)
+	}
+}
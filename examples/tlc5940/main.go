@@ -0,0 +1,69 @@
+// This example fades the 16 channels of a TLC5940 one after the other, on a
+// Raspberry Pi Pico. SIN and SCLK are connected to GP19 and GP18 (SPI0), XLAT
+// to GP20, BLANK to GP21, VPRG to GP22 and GSCLK to GP16.
+package main
+
+import (
+	"machine"
+	"time"
+
+	"tinygo.org/x/drivers/tlc5940"
+)
+
+const gsclkPeriod = 1000 // ns, 244Hz PWM cycles
+
+var (
+	spi = machine.SPI0
+	pwm = machine.PWM0
+)
+
+func main() {
+	err := spi.Configure(machine.SPIConfig{
+		SCK:       machine.GP18,
+		SDO:       machine.GP19,
+		Frequency: 10 * machine.MHz,
+	})
+	if err != nil {
+		println(err.Error())
+		return
+	}
+	leds := tlc5940.New(spi, machine.GP20, machine.GP21, machine.GP22, 1)
+
+	// The grayscale clock, with a 50% duty cycle.
+	err = pwm.Configure(machine.PWMConfig{Period: gsclkPeriod})
+	if err != nil {
+		println(err.Error())
+		return
+	}
+	ch, err := pwm.Channel(machine.GP16)
+	if err != nil {
+		println(err.Error())
+		return
+	}
+	pwm.Set(ch, pwm.Top()/2)
+
+	// The last channel, with a brighter LED, at half the current.
+	leds.SetDotCorrection(15, tlc5940.MaxDotCorrection/2)
+	if err := leds.WriteDotCorrection(); err != nil {
+		println(err.Error())
+		return
+	}
+
+	ticker := time.NewTicker(4096 * gsclkPeriod)
+	var n int
+	for range ticker.C {
+		leds.Cycle()
+
+		// A new value every 8 cycles.
+		n++
+		if n%8 != 0 {
+			continue
+		}
+		step := n / 8 % 64
+		for i := 0; i < leds.Len(); i++ {
+			leds.Set(i, 0)
+		}
+		leds.Set(step/4%leds.Len(), uint16(step%4+1)*tlc5940.MaxValue/4)
+		leds.Update()
+	}
+}
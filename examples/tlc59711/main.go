@@ -0,0 +1,60 @@
+// This example cycles the colors of the 4 RGB LEDs of a TLC59711, on a
+// Raspberry Pi Pico. SDTI and SCKI are connected to GP19 and GP18 (SPI0).
+package main
+
+import (
+	"machine"
+	"time"
+
+	"tinygo.org/x/drivers/tlc59711"
+)
+
+var spi = machine.SPI0
+
+func main() {
+	err := spi.Configure(machine.SPIConfig{
+		SCK:       machine.GP18,
+		SDO:       machine.GP19,
+		Frequency: 1 * machine.MHz,
+	})
+	if err != nil {
+		println(err.Error())
+		return
+	}
+	leds := tlc59711.New(spi, 1)
+	// Green LEDs are usually brighter than the others.
+	leds.SetBrightness(tlc59711.MaxBrightness, 80, tlc59711.MaxBrightness)
+
+	var hue int
+	for {
+		for n := 0; n < leds.Len()/3; n++ {
+			r, g, b := wheel(hue + n*256)
+			leds.SetRGB(n, r, g, b)
+		}
+		if err := leds.Update(); err != nil {
+			println(err.Error())
+		}
+		hue = (hue + 4) % 1536
+		time.Sleep(20 * time.Millisecond)
+	}
+}
+
+// wheel returns the color at hue, from 0 to 1535, of a color wheel.
+func wheel(hue int) (r, g, b uint16) {
+	hue %= 1536
+	x := uint16(hue%256) * 257
+	switch hue / 256 {
+	case 0:
+		return 0xFFFF, x, 0
+	case 1:
+		return 0xFFFF - x, 0xFFFF, 0
+	case 2:
+		return 0, 0xFFFF, x
+	case 3:
+		return 0, 0xFFFF - x, 0xFFFF
+	case 4:
+		return x, 0, 0xFFFF
+	default:
+		return 0xFFFF, 0, 0xFFFF - x
+	}
+}
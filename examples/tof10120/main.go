@@ -0,0 +1,29 @@
+// Prints the distances sent by a TOF10120 laser sensor in active mode.
+package main
+
+import (
+	"machine"
+	"time"
+
+	"tinygo.org/x/drivers/tof10120"
+)
+
+func main() {
+	uart := machine.UART1
+	uart.Configure(machine.UARTConfig{BaudRate: 9600})
+
+	sensor := tof10120.New(uart)
+	if err := sensor.SetInterval(100 * time.Millisecond); err != nil {
+		println(err.Error())
+	}
+	for {
+		updated, err := sensor.Update()
+		if err != nil {
+			println(err.Error())
+		}
+		if updated {
+			println("distance:", sensor.Distance(), "mm")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
@@ -0,0 +1,53 @@
+package main
+
+// Four electrodes of a CAP1188 arranged as a wheel.
+
+import (
+	"machine"
+	"time"
+
+	"tinygo.org/x/drivers/cap1188"
+	"tinygo.org/x/drivers/touch/slider"
+)
+
+func main() {
+	machine.I2C0.Configure(machine.I2CConfig{})
+	sensor := cap1188.New(machine.I2C0)
+	err := sensor.Configure(cap1188.Config{
+		Channels:   0x0F,
+		MultiTouch: true,
+	})
+	if err != nil {
+		println(err.Error())
+		return
+	}
+
+	wheel := slider.New(slider.Config{
+		Electrodes: 4,
+		Wheel:      true,
+		Threshold:  8,
+	})
+
+	strengths := make([]int32, 4)
+	for {
+		for i := range strengths {
+			delta, err := sensor.Delta(uint8(i))
+			if err != nil {
+				println(err.Error())
+			}
+			strengths[i] = int32(delta)
+		}
+
+		state, gesture := wheel.Update(strengths, time.Now())
+		switch gesture {
+		case slider.GestureSwipeForward:
+			println("swipe clockwise")
+		case slider.GestureSwipeBackward:
+			println("swipe counterclockwise")
+		}
+		if state.Touched {
+			println("position", state.Position, "of", wheel.Range(), "velocity", state.Velocity)
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+}
@@ -0,0 +1,30 @@
+// Reads the distance and temperature from a US-100 ultrasonic sensor in
+// serial mode.
+package main
+
+import (
+	"machine"
+	"time"
+
+	"tinygo.org/x/drivers/us100"
+)
+
+func main() {
+	uart := machine.UART1
+	uart.Configure(machine.UARTConfig{BaudRate: 9600})
+
+	sensor := us100.New(uart)
+	for {
+		mm, err := sensor.ReadDistance()
+		if err != nil {
+			println(err.Error())
+		} else {
+			println("distance:", mm, "mm")
+		}
+		temp, err := sensor.ReadTemperature()
+		if err == nil {
+			println("temperature:", temp/1000, "°C")
+		}
+		time.Sleep(500 * time.Millisecond)
+	}
+}
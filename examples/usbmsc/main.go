@@ -0,0 +1,49 @@
+// This example shows an SD card as a USB drive while a button is held down
+// at boot, and otherwise writes a line to a log file on it every second.
+package main
+
+import (
+	"machine"
+	"machine/usb/msc"
+	"time"
+
+	"tinygo.org/x/drivers/sdcard"
+	"tinygo.org/x/drivers/sdcard/fat"
+	"tinygo.org/x/drivers/usbmsc"
+)
+
+func main() {
+	button := machine.GPIO15
+	button.Configure(machine.PinConfig{Mode: machine.PinInputPullup})
+
+	card := sdcard.New(machine.SPI1, machine.GPIO10, machine.GPIO11, machine.GPIO12, machine.GPIO13)
+	if err := card.Configure(); err != nil {
+		println("could not configure the card:", err.Error())
+		return
+	}
+	bridge := usbmsc.New(&card)
+	msc.Port(bridge.Host())
+
+	if !button.Get() {
+		bridge.Acquire(usbmsc.Host)
+		println("the card is a USB drive, reset to log")
+		select {}
+	}
+
+	bridge.Acquire(usbmsc.Firmware)
+	fsys, err := fat.Mount(bridge.Firmware())
+	if err != nil {
+		println("could not mount the card:", err.Error())
+		return
+	}
+	f, err := fsys.Create("log.txt")
+	if err != nil {
+		println("could not create the log:", err.Error())
+		return
+	}
+	for {
+		f.Write([]byte("tick\n"))
+		f.Sync()
+		time.Sleep(time.Second)
+	}
+}
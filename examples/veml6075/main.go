@@ -0,0 +1,29 @@
+// Reads UVA, UVB and the UV index from a VEML6075 sensor.
+package main
+
+import (
+	"machine"
+	"time"
+
+	"tinygo.org/x/drivers/veml6075"
+)
+
+func main() {
+	machine.I2C0.Configure(machine.I2CConfig{})
+	sensor := veml6075.New(machine.I2C0)
+	err := sensor.Configure(veml6075.Config{})
+	if err != nil {
+		println(err.Error())
+		return
+	}
+
+	for {
+		time.Sleep(sensor.IntegrationTime() * 2)
+		if err := sensor.Update(); err != nil {
+			println(err.Error())
+			continue
+		}
+		uvi := sensor.UVIndex()
+		println("UVA:", sensor.UVA(), "UVB:", sensor.UVB(), "UV index:", uvi/1000, ".", uvi%1000/100)
+	}
+}
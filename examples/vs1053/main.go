@@ -0,0 +1,59 @@
+// Plays an MP3 file written raw at the start of an SD card, for instance
+// with dd, while blinking the LED. The card is on SPI1, the VS1053 on SPI0.
+package main
+
+import (
+	"machine"
+	"time"
+
+	"tinygo.org/x/drivers/sdcard"
+	"tinygo.org/x/drivers/vs1053"
+)
+
+// songSize is the size of the file in bytes.
+const songSize = 3 << 20
+
+func main() {
+	time.Sleep(2 * time.Second)
+
+	sd := sdcard.New(machine.SPI1, machine.GP10, machine.GP11, machine.GP12, machine.GP13)
+	if err := sd.Configure(); err != nil {
+		println("sdcard:", err.Error())
+		return
+	}
+
+	spiConfig := machine.SPIConfig{
+		SCK:       machine.GP2,
+		SDO:       machine.GP3,
+		SDI:       machine.GP4,
+		Frequency: 1000000,
+	}
+	machine.SPI0.Configure(spiConfig)
+	codec := vs1053.New(machine.SPI0, machine.GP5, machine.GP6, machine.GP7, machine.GP8)
+	if err := codec.Configure(vs1053.Config{}); err != nil {
+		println("vs1053:", err.Error())
+		return
+	}
+	// The SCI and SDI run faster once the clock multiplier is set.
+	spiConfig.Frequency = 4000000
+	machine.SPI0.Configure(spiConfig)
+	codec.SetVolume(20, 20)
+
+	song := sdcard.NewCardIO(&sd).Section(0, songSize)
+	player := codec.NewPlayer(song, make([]byte, 4096))
+
+	led := machine.LED
+	led.Configure(machine.PinConfig{Mode: machine.PinOutput})
+	for {
+		done, err := player.Poll()
+		if err != nil {
+			println("play:", err.Error())
+			return
+		}
+		if done {
+			println("done")
+			return
+		}
+		led.Set(time.Now().UnixNano()/250e6%2 == 0)
+	}
+}
@@ -0,0 +1,33 @@
+package main
+
+import (
+	"machine"
+	"time"
+
+	"tinygo.org/x/drivers/weathermeter"
+)
+
+func main() {
+	wind := weathermeter.NewAnemometer(machine.D3)
+	rain := weathermeter.NewRainGauge(machine.D2)
+	vane := weathermeter.NewWindVane(machine.ADC0, 10000)
+	wind.Configure()
+	rain.Configure()
+	vane.Configure()
+
+	for i := 0; ; i++ {
+		time.Sleep(3 * time.Second)
+		now := time.Now()
+		wind.Update(now)
+		rain.Update(now)
+
+		println("wind:", wind.Speed(), "mm/s", vane.Cardinal(),
+			"gust:", wind.Gust(), "mm/s",
+			"rain today:", rain.Daily(), "µm")
+
+		// 10 minute gust period.
+		if i%200 == 199 {
+			wind.ResetGust()
+		}
+	}
+}
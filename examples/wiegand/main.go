@@ -0,0 +1,30 @@
+// This example prints the cards read and the keys pressed on a Wiegand
+// reader.
+package main
+
+import (
+	"machine"
+	"time"
+
+	"tinygo.org/x/drivers/wiegand"
+)
+
+func main() {
+	reader := wiegand.New(machine.GP2, machine.GP3)
+	if err := reader.Configure(wiegand.Config{}); err != nil {
+		println("could not configure the reader:", err.Error())
+		return
+	}
+	for {
+		if f, ok := reader.Receive(); ok {
+			if card, err := f.Card(); err == nil {
+				println("card", card.Facility(), card.Number())
+			} else if key, err := f.Key(); err == nil {
+				println("key", key)
+			} else {
+				println(f.Bits, "bit frame:", err.Error())
+			}
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
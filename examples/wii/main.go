@@ -0,0 +1,33 @@
+package main
+
+import (
+	"machine"
+	"time"
+
+	"tinygo.org/x/drivers/wii"
+)
+
+func main() {
+	machine.I2C0.Configure(machine.I2CConfig{Frequency: 100 * machine.KHz})
+	pad := wii.New(machine.I2C0)
+	if err := pad.Configure(); err != nil {
+		println(err.Error())
+		return
+	}
+	switch pad.Controller() {
+	case wii.Nunchuk:
+		println("Nunchuk connected")
+	case wii.Classic:
+		println("Classic Controller connected")
+	}
+
+	for {
+		if err := pad.Update(); err != nil {
+			println(err.Error())
+		} else {
+			s := pad.State
+			println("buttons:", s.Buttons, "stick:", s.X, s.Y, "hat:", s.Hat())
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+}
@@ -0,0 +1,74 @@
+package filter
+
+// atanTable holds atan(2^-i) in micro degrees.
+var atanTable = [...]int32{
+	45000000, 26565051, 14036243, 7125016, 3576334, 1789911, 895174, 447614,
+	223811, 111906, 55953, 27976, 13988, 6994, 3497, 1749,
+	874, 437, 219, 109, 55, 27, 14, 7,
+}
+
+// Atan2 returns the angle of the point (x, y) in micro degrees, between
+// -180° and 180° like math.Atan2, using CORDIC rotations.
+func Atan2(y, x int32) int32 {
+	switch {
+	case y == 0 && x >= 0:
+		return 0
+	case y == 0:
+		return 180e6
+	case x == 0 && y > 0:
+		return 90e6
+	case x == 0:
+		return -90e6
+	}
+	var angle int32
+	// Scaled up, so that the shifts keep the precision.
+	vx, vy := int64(x)<<24, int64(y)<<24
+	if vx < 0 {
+		// Rotate by 180° to the right half plane.
+		vx, vy = -vx, -vy
+		if y >= 0 {
+			angle = 180e6
+		} else {
+			angle = -180e6
+		}
+	}
+	for i, a := range atanTable {
+		if vy > 0 {
+			vx, vy = vx+vy>>i, vy-vx>>i
+			angle += a
+		} else {
+			vx, vy = vx-vy>>i, vy+vx>>i
+			angle -= a
+		}
+	}
+	return angle
+}
+
+// Tilt returns the pitch and roll in micro degrees of an accelerometer at
+// rest, from its acceleration on each axis in any unit, with the Z axis up
+// when level. The pitch is positive when the X axis points up, and the roll
+// when the Y axis points up.
+func Tilt(x, y, z int32) (pitch, roll int32) {
+	yz := sqrt(uint64(int64(y)*int64(y) + int64(z)*int64(z)))
+	// Atan2 takes int32: scale the three terms alike.
+	ax := int64(x)
+	for yz > 1<<30 || ax > 1<<30 || ax < -1<<30 {
+		yz >>= 1
+		ax >>= 1
+	}
+	return Atan2(int32(ax), int32(yz)), Atan2(y, z)
+}
+
+// sqrt returns the integer square root of v.
+func sqrt(v uint64) int64 {
+	var r uint64
+	for bit := uint64(1) << 62; bit != 0; bit >>= 2 {
+		if v >= r+bit {
+			v -= r + bit
+			r = r>>1 + bit
+		} else {
+			r >>= 1
+		}
+	}
+	return int64(r)
+}
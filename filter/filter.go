@@ -0,0 +1,173 @@
+// Package filter implements filters in fixed point to smooth and fuse the
+// readings of sensors, without the floating point math that is slow and
+// large on microcontrollers without an FPU.
+//
+// Values are integers in the units of the sensor drivers: millimeters of
+// altitude, micro degrees of tilt, micro degrees per second of rotation.
+// Weights are fixed point numbers with 16 fractional bits: One is 1, One/4
+// is 0.25.
+package filter // import "tinygo.org/x/drivers/filter"
+
+import "time"
+
+// One is a weight of 1.
+const One = 1 << 16
+
+// Alpha returns the weight of new readings, taken dt apart, of a filter with
+// the time constant tau: dt/(tau+dt).
+func Alpha(dt, tau time.Duration) int32 {
+	if dt <= 0 {
+		return 0
+	}
+	return int32(int64(dt) * One / int64(tau+dt))
+}
+
+// round returns v, with 16 fractional bits, rounded to an integer.
+func round(v int64) int32 {
+	return int32((v + One/2) >> 16)
+}
+
+// Exponential is an exponential moving average: each reading moves the
+// value by alpha of the way.
+type Exponential struct {
+	alpha   int32
+	value   int64 // with 16 fractional bits
+	started bool
+}
+
+// NewExponential returns an exponential moving average with the weight alpha
+// of new readings, between 0 and One. Smaller weights smooth more.
+func NewExponential(alpha int32) *Exponential {
+	return &Exponential{alpha: alpha}
+}
+
+// Update adds a reading and returns the new value. The first reading after
+// NewExponential or Reset is the value.
+func (e *Exponential) Update(x int32) int32 {
+	if !e.started {
+		e.value = int64(x) << 16
+		e.started = true
+	} else {
+		e.value += (int64(x)<<16 - e.value) * int64(e.alpha) >> 16
+	}
+	return e.Value()
+}
+
+// Value returns the current value.
+func (e *Exponential) Value() int32 {
+	return round(e.value)
+}
+
+// Reset forgets all previous readings.
+func (e *Exponential) Reset() {
+	e.value = 0
+	e.started = false
+}
+
+// Complementary fuses a rate, which is precise over a short time but drifts,
+// with an absolute reading, which is noisy but does not drift: the rotation
+// from a gyroscope with the tilt from an accelerometer, or the vertical
+// speed from an accelerometer with the altitude from a barometer.
+//
+// The rate is integrated, and the result pulled towards the absolute reading
+// by 1-alpha of the difference at each update.
+type Complementary struct {
+	alpha   int32
+	value   int64 // with 16 fractional bits
+	started bool
+}
+
+// NewComplementary returns a complementary filter that keeps alpha of the
+// integrated rate at each update, usually close to One. For a time constant
+// tau, above which the absolute readings prevail, alpha is
+// One-Alpha(dt, tau).
+func NewComplementary(alpha int32) *Complementary {
+	return &Complementary{alpha: alpha}
+}
+
+// Update adds a rate, in units per second, and an absolute reading, taken dt
+// after the previous ones, and returns the new value. The first update after
+// NewComplementary or Reset takes the absolute reading as the value.
+func (c *Complementary) Update(rate, x int32, dt time.Duration) int32 {
+	if !c.started {
+		c.value = int64(x) << 16
+		c.started = true
+		return x
+	}
+	c.value += (int64(rate) << 16) / 1e3 * dt.Microseconds() / 1e3
+	c.value += (int64(x)<<16 - c.value) * (One - int64(c.alpha)) >> 16
+	return c.Value()
+}
+
+// Value returns the current value.
+func (c *Complementary) Value() int32 {
+	return round(c.value)
+}
+
+// Reset forgets all previous readings.
+func (c *Complementary) Reset() {
+	c.value = 0
+	c.started = false
+}
+
+// Kalman is a one dimensional Kalman filter: it estimates a value from noisy
+// readings, weighting each reading by how much the value may have changed
+// since the last one and by how noisy the readings are. Unlike an
+// exponential moving average, it converges quickly after it starts, and a
+// known change (such as the altitude gained at the current vertical speed)
+// can be applied between readings with Predict.
+type Kalman struct {
+	q, r     int64 // variances
+	value    int64 // with 16 fractional bits
+	variance int64 // variance of the value
+	started  bool
+}
+
+// NewKalman returns a Kalman filter. The process variance q is how much the
+// value changes between two readings, and the measurement variance r the
+// noise of the readings, both in squared units: a barometer with a noise of
+// 0.5m has a variance of 250000 for altitudes in millimeters.
+func NewKalman(q, r int64) *Kalman {
+	return &Kalman{q: q, r: r}
+}
+
+// Predict moves the value by a known change since the last update.
+func (k *Kalman) Predict(delta int32) {
+	k.value += int64(delta) << 16
+}
+
+// Update adds a reading and returns the new value. The first reading after
+// NewKalman or Reset is the value.
+func (k *Kalman) Update(x int32) int32 {
+	if !k.started {
+		k.value = int64(x) << 16
+		k.variance = k.r
+		k.started = true
+		return x
+	}
+	p := k.variance + k.q
+	gain := int64(One)
+	if p+k.r > 0 {
+		gain = (p << 16) / (p + k.r)
+	}
+	k.value += (int64(x)<<16 - k.value) * gain >> 16
+	k.variance = p * (One - gain) >> 16
+	return k.Value()
+}
+
+// Value returns the current value.
+func (k *Kalman) Value() int32 {
+	return round(k.value)
+}
+
+// Variance returns the estimated variance of the value, in squared units.
+func (k *Kalman) Variance() int64 {
+	return k.variance
+}
+
+// Reset forgets all previous readings.
+func (k *Kalman) Reset() {
+	k.value = 0
+	k.variance = 0
+	k.started = false
+}
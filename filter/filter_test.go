@@ -0,0 +1,108 @@
+package filter
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	qt "github.com/frankban/quicktest"
+)
+
+func TestAlpha(t *testing.T) {
+	c := qt.New(t)
+	c.Assert(Alpha(10*time.Millisecond, 30*time.Millisecond), qt.Equals, int32(One/4))
+	c.Assert(Alpha(0, time.Second), qt.Equals, int32(0))
+}
+
+func TestExponential(t *testing.T) {
+	c := qt.New(t)
+
+	e := NewExponential(One / 4)
+	c.Assert(e.Update(1000), qt.Equals, int32(1000))
+	c.Assert(e.Update(2000), qt.Equals, int32(1250))
+	c.Assert(e.Update(2000), qt.Equals, int32(1438))
+	for i := 0; i < 100; i++ {
+		e.Update(2000)
+	}
+	c.Assert(e.Value(), qt.Equals, int32(2000))
+	e.Reset()
+	c.Assert(e.Update(-5), qt.Equals, int32(-5))
+}
+
+func TestComplementary(t *testing.T) {
+	c := qt.New(t)
+
+	// A gyroscope that drifts by 1°/s, while the accelerometer reads a
+	// steady 10° with ±2° of noise.
+	f := NewComplementary(One - Alpha(10*time.Millisecond, time.Second))
+	for i := 0; i < 1000; i++ {
+		noise := int32(2e6)
+		if i%2 == 0 {
+			noise = -noise
+		}
+		f.Update(1e6, 10e6+noise, 10*time.Millisecond)
+	}
+	// The drift leaves an offset of the rate times the time constant.
+	c.Assert(f.Value() > 10.9e6 && f.Value() < 11.1e6, qt.IsTrue, qt.Commentf("%d", f.Value()))
+
+	// A quick rotation follows the gyroscope, before the accelerometer
+	// catches up.
+	f = NewComplementary(One - Alpha(10*time.Millisecond, time.Second))
+	f.Update(0, 0, 0)
+	for i := 0; i < 10; i++ {
+		f.Update(90e6, 0, 10*time.Millisecond)
+	}
+	c.Assert(f.Value() > 8.5e6 && f.Value() < 9e6, qt.IsTrue, qt.Commentf("%d", f.Value()))
+}
+
+func TestKalman(t *testing.T) {
+	c := qt.New(t)
+
+	// Altitude in millimeters from a barometer with a noise of ±500mm.
+	k := NewKalman(100, 250000)
+	c.Assert(k.Update(10500), qt.Equals, int32(10500))
+	for i := 0; i < 200; i++ {
+		noise := int32(500)
+		if i%2 == 0 {
+			noise = -noise
+		}
+		k.Update(10000 + noise)
+	}
+	c.Assert(k.Value() > 9900 && k.Value() < 10100, qt.IsTrue, qt.Commentf("%d", k.Value()))
+	c.Assert(k.Variance() < 250000/10, qt.IsTrue)
+
+	// A climb at a known speed is followed without lag.
+	for i := 1; i <= 100; i++ {
+		k.Predict(100)
+		k.Update(int32(10000 + 100*i))
+	}
+	c.Assert(k.Value() > 19900 && k.Value() < 20100, qt.IsTrue, qt.Commentf("%d", k.Value()))
+}
+
+func TestAtan2(t *testing.T) {
+	c := qt.New(t)
+	for _, p := range [][2]int32{
+		{0, 1}, {1, 1}, {1, 0}, {1, -1}, {0, -1}, {-1, -1}, {-1, 0}, {-1, 1},
+		{3, 4}, {-1000000, 7}, {math.MaxInt32, math.MinInt32}, {-1, -1 << 30},
+	} {
+		want := math.Atan2(float64(p[0]), float64(p[1])) * 180 / math.Pi * 1e6
+		got := Atan2(p[0], p[1])
+		if math.Abs(float64(got)-want) > 50 && math.Abs(math.Abs(float64(got)-want)-360e6) > 50 {
+			c.Errorf("Atan2(%d, %d) = %d, want %.0f", p[0], p[1], got, want)
+		}
+	}
+}
+
+func TestTilt(t *testing.T) {
+	c := qt.New(t)
+
+	// Accelerations in micro g.
+	pitch, roll := Tilt(0, 0, 1e6)
+	c.Assert([]int32{pitch, roll}, qt.DeepEquals, []int32{0, 0})
+	pitch, roll = Tilt(500000, 0, 866025)
+	c.Assert(pitch > 29.99e6 && pitch < 30.01e6 && roll == 0, qt.IsTrue, qt.Commentf("%d %d", pitch, roll))
+	pitch, roll = Tilt(0, -707107, 707107)
+	c.Assert(pitch == 0 && roll > -45.01e6 && roll < -44.99e6, qt.IsTrue, qt.Commentf("%d %d", pitch, roll))
+	pitch, _ = Tilt(math.MaxInt32, math.MaxInt32, 0)
+	c.Assert(pitch > 44.99e6 && pitch < 45.01e6, qt.IsTrue, qt.Commentf("%d", pitch))
+}
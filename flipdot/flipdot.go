@@ -0,0 +1,178 @@
+// Package flipdot implements a driver for flip-dot displays made of AlfaZeta
+// XY5 panels, controlled over RS485.
+//
+// Every panel controller has an address set with DIP switches and shows
+// 28x7 dots; 28x14 panels have two controllers. The driver keeps a frame
+// buffer of the whole display and only sends the panels that changed since
+// the last Display, so that unchanged panels are not flipped again. The new
+// data is latched by all panels at once with a broadcast refresh.
+package flipdot // import "tinygo.org/x/drivers/flipdot"
+
+import (
+	"errors"
+	"image/color"
+	"io"
+)
+
+var errLayout = errors.New("flipdot: panel outside of the display")
+
+// Size of the dots of one panel controller.
+const (
+	PanelWidth  = 28
+	PanelHeight = 7
+)
+
+// Broadcast is the address received by all panels.
+const Broadcast = 0xFF
+
+// Frame bytes of the XY5 protocol.
+const (
+	frameStart     = 0x80
+	frameEnd       = 0x8F
+	cmdRefresh     = 0x82
+	cmdSend28Latch = 0x84 // 28 columns, shown at the next refresh
+)
+
+// Panel is a panel controller and the position of its top left dot on the
+// display.
+type Panel struct {
+	Address uint8
+	X, Y    int16
+}
+
+// Config is the layout of the display.
+type Config struct {
+	Width, Height int16
+	Panels        []Panel
+}
+
+// Device is a display made of XY5 panels.
+type Device struct {
+	bus    io.Writer
+	width  int16
+	height int16
+	panels []Panel
+
+	// One byte per column, bit 0 is the top dot.
+	frame [][PanelWidth]byte
+	shown [][PanelWidth]byte
+	valid bool // shown matches the panels
+	tx    [PanelWidth + 4]byte
+}
+
+// New returns a display on the given RS485 bus, usually a UART with an
+// automatic direction transceiver, at the baud rate set on the panels
+// (57600 by default).
+//
+// This function only creates the Device object, it does not touch the
+// device.
+func New(bus io.Writer) *Device {
+	return &Device{bus: bus}
+}
+
+// Configure sets the layout of the panels. The whole display is sent at the
+// next Display.
+func (d *Device) Configure(cfg Config) error {
+	for _, p := range cfg.Panels {
+		if p.X < 0 || p.Y < 0 || p.X+PanelWidth > cfg.Width || p.Y+PanelHeight > cfg.Height {
+			return errLayout
+		}
+	}
+	d.width = cfg.Width
+	d.height = cfg.Height
+	d.panels = cfg.Panels
+	d.frame = make([][PanelWidth]byte, len(cfg.Panels))
+	d.shown = make([][PanelWidth]byte, len(cfg.Panels))
+	d.valid = false
+	return nil
+}
+
+// Size returns the size of the display in dots.
+func (d *Device) Size() (x, y int16) {
+	return d.width, d.height
+}
+
+// SetPixel sets a dot in the frame buffer. Any color but black shows the
+// bright side of the dot.
+func (d *Device) SetPixel(x, y int16, c color.RGBA) {
+	d.SetDot(x, y, c.R != 0 || c.G != 0 || c.B != 0)
+}
+
+// SetDot sets a dot in the frame buffer.
+func (d *Device) SetDot(x, y int16, on bool) {
+	for i, p := range d.panels {
+		px, py := x-p.X, y-p.Y
+		if px < 0 || py < 0 || px >= PanelWidth || py >= PanelHeight {
+			continue
+		}
+		if on {
+			d.frame[i][px] |= 1 << py
+		} else {
+			d.frame[i][px] &^= 1 << py
+		}
+	}
+}
+
+// Dot returns whether a dot is set in the frame buffer.
+func (d *Device) Dot(x, y int16) bool {
+	for i, p := range d.panels {
+		px, py := x-p.X, y-p.Y
+		if px >= 0 && py >= 0 && px < PanelWidth && py < PanelHeight {
+			return d.frame[i][px]&(1<<py) != 0
+		}
+	}
+	return false
+}
+
+// Fill sets all dots of the frame buffer.
+func (d *Device) Fill(on bool) {
+	v := byte(0)
+	if on {
+		v = 1<<PanelHeight - 1
+	}
+	for i := range d.frame {
+		for x := range d.frame[i] {
+			d.frame[i][x] = v
+		}
+	}
+}
+
+// Invalidate makes the next Display send all panels, for instance after the
+// panels were powered up again.
+func (d *Device) Invalidate() {
+	d.valid = false
+}
+
+// Display sends the panels that changed since the last Display, and makes
+// them flip together.
+func (d *Device) Display() error {
+	sent := false
+	for i, p := range d.panels {
+		if d.valid && d.frame[i] == d.shown[i] {
+			continue
+		}
+		if err := d.send(cmdSend28Latch, p.Address, d.frame[i][:]); err != nil {
+			d.valid = false
+			return err
+		}
+		d.shown[i] = d.frame[i]
+		sent = true
+	}
+	d.valid = true
+	if !sent {
+		return nil
+	}
+	return d.send(cmdRefresh, 0, nil)
+}
+
+// send writes a frame. The refresh command has no address.
+func (d *Device) send(cmd, address uint8, data []byte) error {
+	b := append(d.tx[:0], frameStart, cmd)
+	if cmd != cmdRefresh {
+		b = append(b, address)
+	}
+	b = append(b, data...)
+	b = append(b, frameEnd)
+	_, err := d.bus.Write(b)
+	return err
+}
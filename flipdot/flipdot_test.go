@@ -0,0 +1,50 @@
+package flipdot
+
+import (
+	"bytes"
+	"image/color"
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+)
+
+func TestDisplay(t *testing.T) {
+	c := qt.New(t)
+
+	var bus bytes.Buffer
+	d := New(&bus)
+	err := d.Configure(Config{Width: 28, Height: 14, Panels: []Panel{{Address: 1}, {Address: 2, Y: 7}}})
+	c.Assert(err, qt.IsNil)
+
+	// The first Display sends all panels.
+	d.SetPixel(0, 0, color.RGBA{R: 255})
+	d.SetDot(27, 13, true)
+	c.Assert(d.Dot(27, 13), qt.IsTrue)
+	c.Assert(d.Display(), qt.IsNil)
+	var cols1, cols2 [PanelWidth]byte
+	cols1[0] = 0x01
+	cols2[27] = 0x40
+	want := append(frame(1, cols1), frame(2, cols2)...)
+	want = append(want, 0x80, 0x82, 0x8F)
+	c.Assert(bus.Bytes(), qt.DeepEquals, want)
+
+	// Nothing changed, nothing is sent.
+	bus.Reset()
+	c.Assert(d.Display(), qt.IsNil)
+	c.Assert(bus.Len(), qt.Equals, 0)
+
+	// Only the changed panel is sent.
+	d.SetDot(1, 8, true)
+	c.Assert(d.Display(), qt.IsNil)
+	c.Assert(bus.Len(), qt.Equals, 32+3)
+	c.Assert(bus.Bytes()[2], qt.Equals, byte(2))
+	c.Assert(bus.Bytes()[3+1], qt.Equals, byte(0x02))
+
+	c.Assert(d.Configure(Config{Width: 20, Height: 7, Panels: []Panel{{}}}), qt.Equals, errLayout)
+}
+
+// frame returns the frame sending cols to a panel.
+func frame(address uint8, cols [PanelWidth]byte) []byte {
+	b := append([]byte{0x80, 0x84, address}, cols[:]...)
+	return append(b, 0x8F)
+}
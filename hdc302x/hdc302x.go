@@ -0,0 +1,229 @@
+// Package hdc302x provides a driver for the HDC3020, HDC3021 and HDC3022
+// low-power humidity and temperature sensors by Texas Instruments.
+//
+// Datasheet: https://www.ti.com/lit/ds/symlink/hdc3020.pdf
+//
+// The sensor sleeps between trigger-on-demand measurements, so Read only
+// draws power during the conversion. In auto measurement mode the sensor
+// measures on its own and can raise the ALERT pin when temperature or
+// humidity leave a configured window, so the MCU only has to wake up when
+// something interesting happens.
+package hdc302x // import "tinygo.org/x/drivers/hdc302x"
+
+import (
+	"encoding/binary"
+	"errors"
+	"time"
+
+	"tinygo.org/x/drivers"
+)
+
+var errChecksum = errors.New("hdc302x: checksum mismatch")
+
+type Device struct {
+	bus     drivers.I2C
+	tx      []byte
+	rx      []byte
+	Address uint8
+
+	// Low power mode used for measurements.
+	Mode LowPowerMode
+}
+
+// New returns a HDC302x device for the provided I2C bus using the default
+// address of 0x44.
+func New(i2c drivers.I2C) *Device {
+	return &Device{
+		bus:     i2c,
+		tx:      make([]byte, 5),
+		rx:      make([]byte, 6),
+		Address: Address,
+	}
+}
+
+// Connected returns whether the sensor has been found, by checking the
+// manufacturer ID.
+func (d *Device) Connected() bool {
+	if err := d.sendCommandWithResult(CmdManufacturerID, d.rx[0:3]); err != nil {
+		return false
+	}
+	return crc8(d.rx[0:2]) == d.rx[2] && binary.BigEndian.Uint16(d.rx) == ManufacturerID
+}
+
+// Reset performs a soft reset, which also stops auto measurement mode.
+func (d *Device) Reset() error {
+	err := d.sendCommand(CmdSoftReset)
+	time.Sleep(3 * time.Millisecond)
+	return err
+}
+
+// ReadTemperatureHumidity triggers a single measurement and returns the
+// temperature in celsius milli degrees (°C/1000) and the relative humidity
+// in hundredths of a percent. The sensor goes back to sleep afterwards.
+func (d *Device) ReadTemperatureHumidity() (tempMilliCelsius int32, relativeHumidity int16, err error) {
+	cmd := [...]uint16{CmdMeasureLPM0, CmdMeasureLPM1, CmdMeasureLPM2, CmdMeasureLPM3}[d.Mode&3]
+	if err = d.sendCommand(cmd); err != nil {
+		return
+	}
+	// Conversion time for LPM0, the other modes are faster.
+	time.Sleep(15 * time.Millisecond)
+	if err = d.bus.Tx(uint16(d.Address), nil, d.rx[0:6]); err != nil {
+		return
+	}
+	return d.convert()
+}
+
+// StartAutoMeasurement puts the sensor into auto measurement mode with the
+// given rate, using the low power mode of the device.
+func (d *Device) StartAutoMeasurement(rate Rate) error {
+	if int(rate) >= len(autoModeCommands) {
+		rate = Rate10Hz
+	}
+	return d.sendCommand(autoModeCommands[rate][d.Mode&3])
+}
+
+// StopAutoMeasurement returns the sensor to sleep mode.
+func (d *Device) StopAutoMeasurement() error {
+	return d.sendCommand(CmdExitAutoMode)
+}
+
+// ReadAutoMeasurement returns the most recent measurement taken in auto
+// measurement mode, in the same units as ReadTemperatureHumidity.
+func (d *Device) ReadAutoMeasurement() (tempMilliCelsius int32, relativeHumidity int16, err error) {
+	if err = d.sendCommandWithResult(CmdReadAutoMeasurement, d.rx[0:6]); err != nil {
+		return
+	}
+	return d.convert()
+}
+
+// Thresholds is an alert window. An alert is set when the temperature or
+// humidity rises above the High values or falls below the Low values.
+// Thresholds are stored with reduced resolution: 7 bits of humidity and 9
+// bits of temperature.
+type Thresholds struct {
+	LowTemperature  int32 // milli °C
+	HighTemperature int32 // milli °C
+	LowHumidity     int16 // hundredths of a percent
+	HighHumidity    int16 // hundredths of a percent
+}
+
+// SetAlert configures the window outside of which the ALERT pin is asserted,
+// and the (narrower) window inside of which it is released again. Set clear
+// equal to set to disable hysteresis. Auto measurement mode must be active for
+// alerts to be evaluated.
+func (d *Device) SetAlert(set, clear Thresholds) error {
+	values := [...]struct {
+		cmd uint16
+		t   int32
+		rh  int16
+	}{
+		{CmdSetAlertLow, set.LowTemperature, set.LowHumidity},
+		{CmdSetAlertHigh, set.HighTemperature, set.HighHumidity},
+		{CmdClearAlertLow, clear.LowTemperature, clear.LowHumidity},
+		{CmdClearAlertHigh, clear.HighTemperature, clear.HighHumidity},
+	}
+	for _, v := range values {
+		if err := d.sendCommandWithValue(v.cmd, encodeThreshold(v.t, v.rh)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// StoreAlert makes the current alert thresholds persistent, so that they are
+// restored after a power cycle.
+func (d *Device) StoreAlert() error {
+	err := d.sendCommand(CmdStoreAlerts)
+	time.Sleep(80 * time.Millisecond)
+	return err
+}
+
+// ReadStatus returns the status register, see the Status* constants.
+func (d *Device) ReadStatus() (uint16, error) {
+	if err := d.sendCommandWithResult(CmdReadStatus, d.rx[0:3]); err != nil {
+		return 0, err
+	}
+	if crc8(d.rx[0:2]) != d.rx[2] {
+		return 0, errChecksum
+	}
+	return binary.BigEndian.Uint16(d.rx), nil
+}
+
+// ClearStatus clears the alert and reset bits of the status register.
+func (d *Device) ClearStatus() error {
+	return d.sendCommand(CmdClearStatus)
+}
+
+// SetHeater turns the built-in heater on or off. The heater can be used to
+// remove condensation from the sensor.
+func (d *Device) SetHeater(on bool) error {
+	if on {
+		return d.sendCommand(CmdHeaterEnable)
+	}
+	return d.sendCommand(CmdHeaterDisable)
+}
+
+// convert checks and converts a measurement in d.rx.
+func (d *Device) convert() (tempMilliCelsius int32, relativeHumidity int16, err error) {
+	if crc8(d.rx[0:2]) != d.rx[2] || crc8(d.rx[3:5]) != d.rx[5] {
+		return 0, 0, errChecksum
+	}
+	rawTemp := binary.BigEndian.Uint16(d.rx[0:])
+	rawHum := binary.BigEndian.Uint16(d.rx[3:])
+	// temperature = -45 + 175 * value / 2¹⁶
+	tempMilliCelsius = int32((175000*int64(rawTemp))>>16) - 45000
+	// humidity = 100 * value / 2¹⁶
+	relativeHumidity = int16((10000 * int32(rawHum)) >> 16)
+	return tempMilliCelsius, relativeHumidity, nil
+}
+
+// encodeThreshold packs a temperature and humidity into the alert threshold
+// format: the 7 most significant bits of the raw humidity followed by the 9
+// most significant bits of the raw temperature.
+func encodeThreshold(tempMilliCelsius int32, relativeHumidity int16) uint16 {
+	t := (int64(tempMilliCelsius) + 45000) << 16 / 175000
+	rh := int64(relativeHumidity) << 16 / 10000
+	if t < 0 {
+		t = 0
+	} else if t > 0xFFFF {
+		t = 0xFFFF
+	}
+	if rh < 0 {
+		rh = 0
+	} else if rh > 0xFFFF {
+		rh = 0xFFFF
+	}
+	return uint16(rh)&0xFE00 | uint16(t)>>7
+}
+
+func (d *Device) sendCommand(command uint16) error {
+	binary.BigEndian.PutUint16(d.tx[0:], command)
+	return d.bus.Tx(uint16(d.Address), d.tx[0:2], nil)
+}
+
+func (d *Device) sendCommandWithValue(command, value uint16) error {
+	binary.BigEndian.PutUint16(d.tx[0:], command)
+	binary.BigEndian.PutUint16(d.tx[2:], value)
+	d.tx[4] = crc8(d.tx[2:4])
+	return d.bus.Tx(uint16(d.Address), d.tx[0:5], nil)
+}
+
+func (d *Device) sendCommandWithResult(command uint16, result []byte) error {
+	binary.BigEndian.PutUint16(d.tx[0:], command)
+	return d.bus.Tx(uint16(d.Address), d.tx[0:2], result)
+}
+
+func crc8(buf []byte) uint8 {
+	var crc uint8 = 0xff
+	for _, b := range buf {
+		crc ^= b
+		for i := 0; i < 8; i++ {
+			if crc&0x80 != 0 {
+				crc = (crc << 1) ^ 0x31
+			} else {
+				crc <<= 1
+			}
+		}
+	}
+	return crc & 0xff
+}
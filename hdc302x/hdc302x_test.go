@@ -0,0 +1,76 @@
+package hdc302x
+
+import (
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+	"tinygo.org/x/drivers/tester"
+)
+
+func TestCRC(t *testing.T) {
+	c := qt.New(t)
+	// Example from the datasheet.
+	c.Assert(crc8([]byte{0xAB, 0xCD}), qt.Equals, uint8(0x6F))
+}
+
+// word returns a 16-bit value followed by its CRC, as sent by the sensor.
+func word(v uint16) []byte {
+	b := []byte{byte(v >> 8), byte(v)}
+	return append(b, crc8(b))
+}
+
+func newSensor(c *qt.C) (*Device, *tester.I2CDeviceCmd) {
+	bus := tester.NewI2CBus(c)
+	fdev := tester.NewI2CDeviceCmd(c, Address)
+	fdev.Commands = map[uint8]*tester.Cmd{
+		0: {
+			Command:  []byte{0x37, 0x81},
+			Mask:     []byte{0xFF, 0xFF},
+			Response: word(ManufacturerID),
+		},
+		1: {
+			Command:  []byte{0xE0, 0x00},
+			Mask:     []byte{0xFF, 0xFF},
+			Response: append(word(0x8000), word(0x8000)...),
+		},
+	}
+	bus.AddDevice(fdev)
+	return New(bus), fdev
+}
+
+func TestConnected(t *testing.T) {
+	c := qt.New(t)
+	dev, fdev := newSensor(c)
+	c.Assert(dev.Connected(), qt.IsTrue)
+
+	fdev.Commands[0].Response[2] ^= 0xFF
+	c.Assert(dev.Connected(), qt.IsFalse)
+}
+
+func TestReadAutoMeasurement(t *testing.T) {
+	c := qt.New(t)
+	dev, fdev := newSensor(c)
+	temp, rh, err := dev.ReadAutoMeasurement()
+	c.Assert(err, qt.IsNil)
+	c.Assert(temp, qt.Equals, int32(42500))
+	c.Assert(rh, qt.Equals, int16(5000))
+
+	fdev.Commands[1].Response = append(word(0), word(0xFFFF)...)
+	temp, rh, err = dev.ReadAutoMeasurement()
+	c.Assert(err, qt.IsNil)
+	c.Assert(temp, qt.Equals, int32(-45000))
+	c.Assert(rh, qt.Equals, int16(9999))
+
+	fdev.Commands[1].Response[5] ^= 0xFF
+	_, _, err = dev.ReadAutoMeasurement()
+	c.Assert(err, qt.Equals, errChecksum)
+}
+
+func TestEncodeThreshold(t *testing.T) {
+	c := qt.New(t)
+	// 25°C is 0x6666 raw, 50% is 0x8000 raw.
+	c.Assert(encodeThreshold(25000, 5000), qt.Equals, uint16(0x8000|0x6666>>7))
+	// Values out of range are clamped.
+	c.Assert(encodeThreshold(-50000, 12000), qt.Equals, uint16(0xFE00))
+	c.Assert(encodeThreshold(200000, -100), qt.Equals, uint16(0x01FF))
+}
@@ -0,0 +1,78 @@
+package hdc302x
+
+const (
+	// Address is the default I2C address (ADDR and ADDR1 pins low). The
+	// other addresses are 0x45, 0x46 and 0x47.
+	Address = 0x44
+
+	CmdMeasureLPM0         = 0x2400 // trigger-on-demand, lowest noise
+	CmdMeasureLPM1         = 0x240B
+	CmdMeasureLPM2         = 0x2416
+	CmdMeasureLPM3         = 0x24FF // trigger-on-demand, lowest power
+	CmdReadAutoMeasurement = 0xE000
+	CmdExitAutoMode        = 0x3093
+	CmdSetAlertLow         = 0x6100
+	CmdSetAlertHigh        = 0x611D
+	CmdClearAlertLow       = 0x610B
+	CmdClearAlertHigh      = 0x6116
+	CmdReadAlertLow        = 0xE102
+	CmdReadAlertHigh       = 0xE11F
+	CmdReadClearLow        = 0xE109
+	CmdReadClearHigh       = 0xE114
+	CmdStoreAlerts         = 0x6155
+	CmdReadStatus          = 0xF32D
+	CmdClearStatus         = 0x3041
+	CmdSoftReset           = 0x30A2
+	CmdHeaterEnable        = 0x306D
+	CmdHeaterDisable       = 0x3066
+	CmdManufacturerID      = 0x3781
+
+	// ManufacturerID is the Texas Instruments manufacturer ID.
+	ManufacturerID = 0x3000
+)
+
+// LowPowerMode trades measurement noise for power consumption, from LPM0
+// (lowest noise) to LPM3 (lowest power).
+type LowPowerMode uint8
+
+const (
+	LPM0 LowPowerMode = iota
+	LPM1
+	LPM2
+	LPM3
+)
+
+// Rate is the number of measurements per second in auto measurement mode.
+type Rate uint8
+
+const (
+	Rate0_5Hz Rate = iota
+	Rate1Hz
+	Rate2Hz
+	Rate4Hz
+	Rate10Hz
+)
+
+// autoModeCommands contains the commands to start auto measurement mode,
+// indexed by rate and low power mode.
+var autoModeCommands = [5][4]uint16{
+	Rate0_5Hz: {0x2032, 0x2024, 0x202F, 0x20FF},
+	Rate1Hz:   {0x2130, 0x2126, 0x212D, 0x21FF},
+	Rate2Hz:   {0x2236, 0x2220, 0x222B, 0x22FF},
+	Rate4Hz:   {0x2334, 0x2322, 0x2329, 0x23FF},
+	Rate10Hz:  {0x2737, 0x2721, 0x272A, 0x27FF},
+}
+
+// Status register bits.
+const (
+	StatusAlert        = 1 << 15 // at least one alert is active
+	StatusHeater       = 1 << 13 // heater enabled
+	StatusRHTrackAlert = 1 << 11
+	StatusTTrackAlert  = 1 << 10
+	StatusRHHighAlert  = 1 << 9
+	StatusRHLowAlert   = 1 << 8
+	StatusTHighAlert   = 1 << 7
+	StatusTLowAlert    = 1 << 6
+	StatusReset        = 1 << 4 // reset detected
+	StatusChecksum     = 1 << 0 // last written data failed the checksum
+)
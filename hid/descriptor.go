@@ -0,0 +1,110 @@
+package hid
+
+// KeyboardDescriptor is the HID report descriptor of a boot protocol
+// keyboard, matching Keyboard.Report.
+var KeyboardDescriptor = []byte{
+	0x05, 0x01, // Usage Page (Generic Desktop)
+	0x09, 0x06, // Usage (Keyboard)
+	0xA1, 0x01, // Collection (Application)
+	0x05, 0x07, //   Usage Page (Keyboard)
+	0x19, 0xE0, //   Usage Minimum (Left Control)
+	0x29, 0xE7, //   Usage Maximum (Right GUI)
+	0x15, 0x00, //   Logical Minimum (0)
+	0x25, 0x01, //   Logical Maximum (1)
+	0x75, 0x01, //   Report Size (1)
+	0x95, 0x08, //   Report Count (8)
+	0x81, 0x02, //   Input (Data, Variable, Absolute)
+	0x95, 0x01, //   Report Count (1)
+	0x75, 0x08, //   Report Size (8)
+	0x81, 0x01, //   Input (Constant)
+	0x95, 0x05, //   Report Count (5)
+	0x75, 0x01, //   Report Size (1)
+	0x05, 0x08, //   Usage Page (LEDs)
+	0x19, 0x01, //   Usage Minimum (Num Lock)
+	0x29, 0x05, //   Usage Maximum (Kana)
+	0x91, 0x02, //   Output (Data, Variable, Absolute)
+	0x95, 0x01, //   Report Count (1)
+	0x75, 0x03, //   Report Size (3)
+	0x91, 0x01, //   Output (Constant)
+	0x95, 0x06, //   Report Count (6)
+	0x75, 0x08, //   Report Size (8)
+	0x15, 0x00, //   Logical Minimum (0)
+	0x25, 0x65, //   Logical Maximum (101)
+	0x05, 0x07, //   Usage Page (Keyboard)
+	0x19, 0x00, //   Usage Minimum (0)
+	0x29, 0x65, //   Usage Maximum (101)
+	0x81, 0x00, //   Input (Data, Array)
+	0xC0, // End Collection
+}
+
+// MouseDescriptor is the HID report descriptor of a boot protocol mouse
+// with five buttons and a wheel, matching Mouse.Report.
+var MouseDescriptor = []byte{
+	0x05, 0x01, // Usage Page (Generic Desktop)
+	0x09, 0x02, // Usage (Mouse)
+	0xA1, 0x01, // Collection (Application)
+	0x09, 0x01, //   Usage (Pointer)
+	0xA1, 0x00, //   Collection (Physical)
+	0x05, 0x09, //     Usage Page (Button)
+	0x19, 0x01, //     Usage Minimum (1)
+	0x29, 0x05, //     Usage Maximum (5)
+	0x15, 0x00, //     Logical Minimum (0)
+	0x25, 0x01, //     Logical Maximum (1)
+	0x95, 0x05, //     Report Count (5)
+	0x75, 0x01, //     Report Size (1)
+	0x81, 0x02, //     Input (Data, Variable, Absolute)
+	0x95, 0x01, //     Report Count (1)
+	0x75, 0x03, //     Report Size (3)
+	0x81, 0x01, //     Input (Constant)
+	0x05, 0x01, //     Usage Page (Generic Desktop)
+	0x09, 0x30, //     Usage (X)
+	0x09, 0x31, //     Usage (Y)
+	0x09, 0x38, //     Usage (Wheel)
+	0x15, 0x81, //     Logical Minimum (-127)
+	0x25, 0x7F, //     Logical Maximum (127)
+	0x75, 0x08, //     Report Size (8)
+	0x95, 0x03, //     Report Count (3)
+	0x81, 0x06, //     Input (Data, Variable, Relative)
+	0xC0, //   End Collection
+	0xC0, // End Collection
+}
+
+// GamepadDescriptor is the HID report descriptor of a gamepad with 16
+// buttons, a hat switch and four axes, matching Gamepad.Report.
+var GamepadDescriptor = []byte{
+	0x05, 0x01, // Usage Page (Generic Desktop)
+	0x09, 0x05, // Usage (Game Pad)
+	0xA1, 0x01, // Collection (Application)
+	0x05, 0x09, //   Usage Page (Button)
+	0x19, 0x01, //   Usage Minimum (1)
+	0x29, 0x10, //   Usage Maximum (16)
+	0x15, 0x00, //   Logical Minimum (0)
+	0x25, 0x01, //   Logical Maximum (1)
+	0x75, 0x01, //   Report Size (1)
+	0x95, 0x10, //   Report Count (16)
+	0x81, 0x02, //   Input (Data, Variable, Absolute)
+	0x05, 0x01, //   Usage Page (Generic Desktop)
+	0x09, 0x39, //   Usage (Hat Switch)
+	0x15, 0x00, //   Logical Minimum (0)
+	0x25, 0x07, //   Logical Maximum (7)
+	0x35, 0x00, //   Physical Minimum (0)
+	0x46, 0x3B, 0x01, //   Physical Maximum (315)
+	0x65, 0x14, //   Unit (Degrees)
+	0x75, 0x04, //   Report Size (4)
+	0x95, 0x01, //   Report Count (1)
+	0x81, 0x42, //   Input (Data, Variable, Absolute, Null State)
+	0x65, 0x00, //   Unit (None)
+	0x75, 0x04, //   Report Size (4)
+	0x95, 0x01, //   Report Count (1)
+	0x81, 0x01, //   Input (Constant)
+	0x09, 0x30, //   Usage (X)
+	0x09, 0x31, //   Usage (Y)
+	0x09, 0x32, //   Usage (Z)
+	0x09, 0x35, //   Usage (Rz)
+	0x15, 0x81, //   Logical Minimum (-127)
+	0x25, 0x7F, //   Logical Maximum (127)
+	0x75, 0x08, //   Report Size (8)
+	0x95, 0x04, //   Report Count (4)
+	0x81, 0x02, //   Input (Data, Variable, Absolute)
+	0xC0, // End Collection
+}
@@ -0,0 +1,172 @@
+// Package hid converts events from input drivers into USB HID reports.
+//
+// The Keyboard, Mouse and Gamepad types hold the state of a boot protocol
+// keyboard, a boot protocol mouse with wheel and a 16 button gamepad with a
+// hat switch and four axes. Their Report methods encode the state in the
+// layout described by KeyboardDescriptor, MouseDescriptor and
+// GamepadDescriptor, ready to be sent by the USB stack.
+//
+// The Mapper ties it together: inputs from keypads, touch controllers,
+// encoders and joysticks are bound to keys, buttons and axes in a table, and
+// Flush only sends the reports that changed.
+//
+//	m := hid.Mapper{
+//		Bindings: []hid.Binding{
+//			{Input: 0, Key: hid.KeyA},
+//			{Input: 1, Key: hid.KeyC, Modifiers: hid.ModifierLeftCtrl},
+//			{Input: 2, MouseButtons: hid.MouseLeft},
+//		},
+//		KeyboardSender: hid.SenderFunc(func(b []byte) error {
+//			keyboardEndpoint.Send(b)
+//			return nil
+//		}),
+//	}
+//	touchSensor.SetCallbacks(m.Press, m.Release)
+package hid // import "tinygo.org/x/drivers/hid"
+
+// Sender sends a single report to the host, for example over an interrupt
+// IN endpoint of the USB stack.
+type Sender interface {
+	SendReport(report []byte) error
+}
+
+// SenderFunc adapts a function to the Sender interface.
+type SenderFunc func(report []byte) error
+
+// SendReport calls f(report).
+func (f SenderFunc) SendReport(report []byte) error {
+	return f(report)
+}
+
+// Keyboard is the state of a boot protocol keyboard: up to six keys can be
+// pressed at the same time, plus the modifiers.
+type Keyboard struct {
+	Modifiers uint8
+	Keys      [6]uint8
+}
+
+// Press adds a key to the report. It returns false when six keys are
+// already pressed.
+func (k *Keyboard) Press(key uint8) bool {
+	free := -1
+	for i, v := range k.Keys {
+		if v == key {
+			return true
+		}
+		if v == KeyNone && free < 0 {
+			free = i
+		}
+	}
+	if free < 0 {
+		return false
+	}
+	k.Keys[free] = key
+	return true
+}
+
+// Release removes a key from the report.
+func (k *Keyboard) Release(key uint8) {
+	for i, v := range k.Keys {
+		if v == key {
+			k.Keys[i] = KeyNone
+		}
+	}
+}
+
+// ReleaseAll releases all keys and modifiers.
+func (k *Keyboard) ReleaseAll() {
+	*k = Keyboard{}
+}
+
+// Report encodes the keyboard state in b, which must be at least 8 bytes,
+// and returns the report.
+func (k *Keyboard) Report(b []byte) []byte {
+	b = b[:8]
+	b[0] = k.Modifiers
+	b[1] = 0
+	copy(b[2:], k.Keys[:])
+	return b
+}
+
+// Mouse is the state of a mouse. X, Y and Wheel are relative movements
+// since the last report.
+type Mouse struct {
+	Buttons uint8
+	X, Y    int16
+	Wheel   int16
+}
+
+// Move adds a relative movement.
+func (m *Mouse) Move(dx, dy int16) {
+	m.X += dx
+	m.Y += dy
+}
+
+// Scroll adds a wheel movement.
+func (m *Mouse) Scroll(delta int16) {
+	m.Wheel += delta
+}
+
+// Pending returns whether there is movement left to report.
+func (m *Mouse) Pending() bool {
+	return m.X != 0 || m.Y != 0 || m.Wheel != 0
+}
+
+// Report encodes the mouse state in b, which must be at least 4 bytes, and
+// returns the report. Movements larger than a report can hold are kept for
+// the next report.
+func (m *Mouse) Report(b []byte) []byte {
+	b = b[:4]
+	b[0] = m.Buttons
+	b[1] = uint8(take(&m.X))
+	b[2] = uint8(take(&m.Y))
+	b[3] = uint8(take(&m.Wheel))
+	return b
+}
+
+// take returns the part of v that fits in a report and subtracts it from v.
+func take(v *int16) int8 {
+	d := *v
+	if d > 127 {
+		d = 127
+	} else if d < -127 {
+		d = -127
+	}
+	*v -= d
+	return int8(d)
+}
+
+// Gamepad is the state of a gamepad with 16 buttons, a hat switch and four
+// axes. Axis values are normalized to the full int16 range, centered at 0.
+type Gamepad struct {
+	Buttons uint16
+	Hat     uint8
+	X, Y    int16
+	Z, Rz   int16
+}
+
+// Report encodes the gamepad state in b, which must be at least 7 bytes,
+// and returns the report.
+func (g *Gamepad) Report(b []byte) []byte {
+	b = b[:7]
+	b[0] = uint8(g.Buttons)
+	b[1] = uint8(g.Buttons >> 8)
+	hat := g.Hat
+	if hat > HatUpLeft {
+		hat = HatCentered
+	}
+	b[2] = hat
+	b[3] = uint8(axis(g.X))
+	b[4] = uint8(axis(g.Y))
+	b[5] = uint8(axis(g.Z))
+	b[6] = uint8(axis(g.Rz))
+	return b
+}
+
+// axis scales a normalized axis value to the -127..127 report range.
+func axis(v int16) int8 {
+	if v == -32768 {
+		v = -32767
+	}
+	return int8(int32(v) * 127 / 32767)
+}
@@ -0,0 +1,73 @@
+package hid
+
+import (
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+)
+
+func TestKeyboard(t *testing.T) {
+	c := qt.New(t)
+
+	var k Keyboard
+	for i := uint8(0); i < 6; i++ {
+		c.Assert(k.Press(KeyA+i), qt.IsTrue)
+	}
+	c.Assert(k.Press(KeyZ), qt.IsFalse)
+	k.Release(KeyB)
+	c.Assert(k.Press(KeyZ), qt.IsTrue)
+	k.Modifiers = ModifierLeftShift
+
+	var buf [8]byte
+	c.Assert(k.Report(buf[:]), qt.DeepEquals, []byte{0x02, 0, KeyA, KeyZ, KeyC, KeyD, KeyE, KeyF})
+}
+
+func TestMouse(t *testing.T) {
+	c := qt.New(t)
+
+	var m Mouse
+	m.Move(200, -5)
+	var buf [4]byte
+	c.Assert(m.Report(buf[:]), qt.DeepEquals, []byte{0, 127, 0xFB, 0})
+	c.Assert(m.Pending(), qt.IsTrue)
+	c.Assert(m.Report(buf[:]), qt.DeepEquals, []byte{0, 73, 0, 0})
+	c.Assert(m.Pending(), qt.IsFalse)
+}
+
+func TestMapper(t *testing.T) {
+	c := qt.New(t)
+
+	var sent [][]byte
+	m := Mapper{
+		Bindings: []Binding{
+			{Input: 0, Key: KeyA},
+			{Input: 1, Key: KeyC, Modifiers: ModifierLeftCtrl},
+			{Input: 2, Modifiers: ModifierLeftCtrl},
+		},
+		KeyboardSender: SenderFunc(func(b []byte) error {
+			sent = append(sent, append([]byte(nil), b...))
+			return nil
+		}),
+	}
+
+	c.Assert(m.Flush(), qt.IsNil)
+	c.Assert(sent, qt.HasLen, 1)
+
+	m.Press(1)
+	m.Press(2)
+	m.Release(1)
+	c.Assert(m.Flush(), qt.IsNil)
+	c.Assert(sent[1], qt.DeepEquals, []byte{ModifierLeftCtrl, 0, 0, 0, 0, 0, 0, 0})
+
+	// Nothing changed, nothing sent.
+	c.Assert(m.Flush(), qt.IsNil)
+	c.Assert(sent, qt.HasLen, 2)
+
+	m.Release(2)
+	m.Tap(0)
+	c.Assert(m.Flush(), qt.IsNil)
+	c.Assert(m.Flush(), qt.IsNil)
+	c.Assert(sent, qt.HasLen, 4)
+	c.Assert(sent[2], qt.DeepEquals, []byte{0, 0, KeyA, 0, 0, 0, 0, 0})
+	c.Assert(sent[3], qt.DeepEquals, []byte{0, 0, 0, 0, 0, 0, 0, 0})
+}
@@ -0,0 +1,138 @@
+package hid
+
+// Keyboard usage IDs, from the HID Usage Tables, section 10.
+const KeyNone = 0x00
+
+const (
+	KeyA = 0x04 + iota
+	KeyB
+	KeyC
+	KeyD
+	KeyE
+	KeyF
+	KeyG
+	KeyH
+	KeyI
+	KeyJ
+	KeyK
+	KeyL
+	KeyM
+	KeyN
+	KeyO
+	KeyP
+	KeyQ
+	KeyR
+	KeyS
+	KeyT
+	KeyU
+	KeyV
+	KeyW
+	KeyX
+	KeyY
+	KeyZ
+	Key1
+	Key2
+	Key3
+	Key4
+	Key5
+	Key6
+	Key7
+	Key8
+	Key9
+	Key0
+	KeyEnter
+	KeyEscape
+	KeyBackspace
+	KeyTab
+	KeySpace
+	KeyMinus
+	KeyEqual
+	KeyLeftBracket
+	KeyRightBracket
+	KeyBackslash
+	KeyNonUSHash
+	KeySemicolon
+	KeyQuote
+	KeyGrave
+	KeyComma
+	KeyPeriod
+	KeySlash
+	KeyCapsLock
+	KeyF1
+	KeyF2
+	KeyF3
+	KeyF4
+	KeyF5
+	KeyF6
+	KeyF7
+	KeyF8
+	KeyF9
+	KeyF10
+	KeyF11
+	KeyF12
+	KeyPrintScreen
+	KeyScrollLock
+	KeyPause
+	KeyInsert
+	KeyHome
+	KeyPageUp
+	KeyDelete
+	KeyEnd
+	KeyPageDown
+	KeyRight
+	KeyLeft
+	KeyDown
+	KeyUp
+	KeyNumLock
+	KeypadSlash
+	KeypadAsterisk
+	KeypadMinus
+	KeypadPlus
+	KeypadEnter
+	Keypad1
+	Keypad2
+	Keypad3
+	Keypad4
+	Keypad5
+	Keypad6
+	Keypad7
+	Keypad8
+	Keypad9
+	Keypad0
+	KeypadPeriod
+)
+
+// Modifier bits of the keyboard report.
+const (
+	ModifierLeftCtrl   = 0x01
+	ModifierLeftShift  = 0x02
+	ModifierLeftAlt    = 0x04
+	ModifierLeftGUI    = 0x08
+	ModifierRightCtrl  = 0x10
+	ModifierRightShift = 0x20
+	ModifierRightAlt   = 0x40
+	ModifierRightGUI   = 0x80
+)
+
+// Mouse buttons.
+const (
+	MouseLeft    = 0x01
+	MouseRight   = 0x02
+	MouseMiddle  = 0x04
+	MouseBack    = 0x08
+	MouseForward = 0x10
+)
+
+// Gamepad hat switch directions. HatCentered is reported when the hat is
+// not pressed.
+const (
+	HatUp = iota
+	HatUpRight
+	HatRight
+	HatDownRight
+	HatDown
+	HatDownLeft
+	HatLeft
+	HatUpLeft
+	HatCentered = 0x0F
+)
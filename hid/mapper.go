@@ -0,0 +1,199 @@
+package hid
+
+// Binding binds an input of a driver to the keys and buttons reported while
+// it is pressed. The meaning of Input is up to the caller: the key index of
+// a keypad, the electrode of a touch controller, a gamepad button bit...
+type Binding struct {
+	Input uint8
+
+	Key       uint8
+	Modifiers uint8
+
+	MouseButtons   uint8
+	GamepadButtons uint16
+}
+
+// Axis is a gamepad axis.
+type Axis uint8
+
+const (
+	AxisX Axis = iota
+	AxisY
+	AxisZ
+	AxisRz
+)
+
+// Mapper keeps the keyboard, mouse and gamepad state for a set of bindings,
+// and sends the reports that changed. The keys and buttons of the reports
+// are derived from the bindings, while the axes, hat and mouse movement are
+// set directly.
+//
+// Mapper is not safe for concurrent use: call Press and Release from the
+// main loop (for example from driver callbacks invoked by an Update method),
+// not from interrupts.
+type Mapper struct {
+	Bindings []Binding
+
+	Keyboard Keyboard
+	Mouse    Mouse
+	Gamepad  Gamepad
+
+	// Senders for each report type, nil if not used.
+	KeyboardSender Sender
+	MouseSender    Sender
+	GamepadSender  Sender
+
+	pressed [8]uint32 // bitmap of pressed inputs
+	tapped  [8]uint32 // bitmap of inputs to release after the next flush
+
+	keyboardReport [8]byte
+	mouseButtons   uint8
+	gamepadReport  [7]byte
+	buf            [8]byte
+	sent           bool
+}
+
+// Press marks an input as pressed. It has the signature of the touch
+// callbacks of the capacitive touch drivers.
+func (m *Mapper) Press(input uint8) {
+	m.set(input, true)
+}
+
+// Release marks an input as released.
+func (m *Mapper) Release(input uint8) {
+	m.set(input, false)
+}
+
+// Set marks an input as pressed or released.
+func (m *Mapper) Set(input uint8, pressed bool) {
+	m.set(input, pressed)
+}
+
+// Tap presses an input until the next Flush, for inputs without a release
+// event such as an encoder step.
+func (m *Mapper) Tap(input uint8) {
+	m.tapped[input/32] |= 1 << (input % 32)
+	m.set(input, true)
+}
+
+// SetInputs sets the state of 32 inputs at once from a bitmask, starting at
+// input first. It fits drivers returning the state of all keys at once.
+func (m *Mapper) SetInputs(first uint8, mask uint32) {
+	for i := uint8(0); i < 32 && int(first)+int(i) < 256; i++ {
+		m.setBit(first+i, mask&(1<<i) != 0)
+	}
+	m.update()
+}
+
+// SetAxis sets a gamepad axis, normalized to the int16 range.
+func (m *Mapper) SetAxis(axis Axis, value int16) {
+	switch axis {
+	case AxisX:
+		m.Gamepad.X = value
+	case AxisY:
+		m.Gamepad.Y = value
+	case AxisZ:
+		m.Gamepad.Z = value
+	case AxisRz:
+		m.Gamepad.Rz = value
+	}
+}
+
+// SetHat sets the gamepad hat direction, or HatCentered.
+func (m *Mapper) SetHat(direction uint8) {
+	m.Gamepad.Hat = direction
+}
+
+// MoveMouse adds a relative mouse movement.
+func (m *Mapper) MoveMouse(dx, dy int16) {
+	m.Mouse.Move(dx, dy)
+}
+
+// Scroll adds a mouse wheel movement, for example from a rotary encoder.
+func (m *Mapper) Scroll(delta int16) {
+	m.Mouse.Scroll(delta)
+}
+
+// Pressed returns whether an input is pressed.
+func (m *Mapper) Pressed(input uint8) bool {
+	return m.pressed[input/32]&(1<<(input%32)) != 0
+}
+
+func (m *Mapper) set(input uint8, pressed bool) {
+	m.setBit(input, pressed)
+	m.update()
+}
+
+func (m *Mapper) setBit(input uint8, pressed bool) {
+	if pressed {
+		m.pressed[input/32] |= 1 << (input % 32)
+	} else {
+		m.pressed[input/32] &^= 1 << (input % 32)
+	}
+}
+
+// update derives the keys and buttons from the pressed inputs.
+func (m *Mapper) update() {
+	m.Keyboard.ReleaseAll()
+	m.Mouse.Buttons = 0
+	m.Gamepad.Buttons = 0
+	for _, b := range m.Bindings {
+		if !m.Pressed(b.Input) {
+			continue
+		}
+		m.Keyboard.Modifiers |= b.Modifiers
+		if b.Key != KeyNone {
+			m.Keyboard.Press(b.Key)
+		}
+		m.Mouse.Buttons |= b.MouseButtons
+		m.Gamepad.Buttons |= b.GamepadButtons
+	}
+}
+
+// Flush sends the reports that changed since the last flush, then releases
+// the tapped inputs. Call it regularly, at least once per USB polling
+// interval when inputs are tapped.
+func (m *Mapper) Flush() error {
+	first := !m.sent
+	m.sent = true
+
+	if m.KeyboardSender != nil {
+		r := m.Keyboard.Report(m.buf[:])
+		if first || string(r) != string(m.keyboardReport[:]) {
+			copy(m.keyboardReport[:], r)
+			if err := m.KeyboardSender.SendReport(r); err != nil {
+				return err
+			}
+		}
+	}
+
+	if m.MouseSender != nil && (first || m.Mouse.Pending() || m.Mouse.Buttons != m.mouseButtons) {
+		m.mouseButtons = m.Mouse.Buttons
+		if err := m.MouseSender.SendReport(m.Mouse.Report(m.buf[:])); err != nil {
+			return err
+		}
+	}
+
+	if m.GamepadSender != nil {
+		r := m.Gamepad.Report(m.buf[:])
+		if first || string(r) != string(m.gamepadReport[:]) {
+			copy(m.gamepadReport[:], r)
+			if err := m.GamepadSender.SendReport(r); err != nil {
+				return err
+			}
+		}
+	}
+
+	var tapped bool
+	for i, t := range m.tapped {
+		if t != 0 {
+			m.pressed[i] &^= t
+			m.tapped[i] = 0
+			tapped = true
+		}
+	}
+	if tapped {
+		m.update()
+	}
+	return nil
+}
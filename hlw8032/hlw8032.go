@@ -0,0 +1,227 @@
+// Package hlw8032 implements a driver for the HLW8032 single phase energy
+// metering chip, found in many smart plugs. The chip continuously sends a
+// 24 byte frame with the measured periods every 50ms over its UART.
+//
+// Datasheet: http://www.hiliwi.com/uploads/soft/20190826/HLW8032.pdf
+package hlw8032 // import "tinygo.org/x/drivers/hlw8032"
+
+import (
+	"errors"
+
+	"tinygo.org/x/drivers"
+)
+
+// FrameSize is the size of a frame sent by the chip.
+const FrameSize = 24
+
+var errNotCalibrated = errors.New("hlw8032: chip not calibrated")
+
+// State register bits, valid when the upper nibble is 0xF.
+const (
+	stateParamError      = 0x01
+	statePowerOverflow   = 0x02
+	stateCurrentOverflow = 0x04
+	stateVoltageOverflow = 0x08
+)
+
+// Frame is a frame sent by the chip.
+type Frame [FrameSize]byte
+
+// Decoder finds frames in the bytes received from the chip.
+type Decoder struct {
+	frame Frame
+	n     int
+}
+
+// Feed adds a received byte. It returns true when a complete frame with a
+// valid checksum has been received, which stays valid until the next call.
+func (p *Decoder) Feed(b byte) (*Frame, bool) {
+	switch p.n {
+	case 0:
+		// 0x55 when running normally, 0xAA when not calibrated and 0xFx
+		// when a register overflowed.
+		if b != 0x55 && b != 0xAA && b&0xF0 != 0xF0 {
+			return nil, false
+		}
+	case 1:
+		if b != 0x5A {
+			p.n = 0
+			return p.Feed(b)
+		}
+	}
+	p.frame[p.n] = b
+	p.n++
+	if p.n < FrameSize {
+		return nil, false
+	}
+	p.n = 0
+	sum := byte(0)
+	for _, c := range p.frame[2:23] {
+		sum += c
+	}
+	if sum != p.frame[23] {
+		return nil, false
+	}
+	return &p.frame, true
+}
+
+func (f *Frame) reg(i int) uint32 {
+	return uint32(f[i])<<16 | uint32(f[i+1])<<8 | uint32(f[i+2])
+}
+
+// Config holds the calibration of the measurement circuit.
+type Config struct {
+	// VoltageDivider is the ratio of the voltage divider on the voltage
+	// input, multiplied by 1000. It defaults to 1880, for the usual 4x470kΩ
+	// and 1kΩ divider.
+	VoltageDivider uint32
+
+	// Shunt is the resistance of the current shunt in µΩ. It defaults to
+	// 1000 (1mΩ).
+	Shunt uint32
+}
+
+// Device wraps a UART connection to an HLW8032.
+type Device struct {
+	uart    drivers.UART
+	decoder Decoder
+	cfg     Config
+	buf     [32]byte
+
+	state   byte
+	voltage int32
+	current int32
+	power   int32
+
+	pulses     uint64
+	lastPF     uint16
+	havePF     bool
+	powerParam uint32
+}
+
+// New creates a new HLW8032 connection. The UART must already be configured
+// at 4800 baud with even parity.
+//
+// This function only creates the Device object, it does not touch the device.
+func New(uart drivers.UART) *Device {
+	return &Device{uart: uart}
+}
+
+// Configure sets the calibration.
+func (d *Device) Configure(cfg Config) {
+	if cfg.VoltageDivider == 0 {
+		cfg.VoltageDivider = 1880
+	}
+	if cfg.Shunt == 0 {
+		cfg.Shunt = 1000
+	}
+	d.cfg = cfg
+}
+
+// Update reads the received bytes and decodes the last complete frame. The
+// chip sends a frame every 50ms, so it should be called at least that
+// often to count the energy pulses correctly.
+func (d *Device) Update(which drivers.Measurement) error {
+	if d.cfg.Shunt == 0 {
+		d.Configure(Config{})
+	}
+	for d.uart.Buffered() > 0 {
+		n, err := d.uart.Read(d.buf[:])
+		if err != nil {
+			return err
+		}
+		for _, b := range d.buf[:n] {
+			if f, ok := d.decoder.Feed(b); ok {
+				d.decode(f)
+			}
+		}
+	}
+	if d.state == 0xAA {
+		return errNotCalibrated
+	}
+	return nil
+}
+
+func (d *Device) decode(f *Frame) {
+	d.state = f[0]
+	if d.state == 0xAA {
+		return
+	}
+	overflow := byte(0)
+	if d.state&0xF0 == 0xF0 {
+		overflow = d.state
+	}
+	if overflow&stateParamError != 0 {
+		return
+	}
+	div, shunt := uint64(d.cfg.VoltageDivider), uint64(d.cfg.Shunt)
+
+	// Every value is a parameter (the period at the reference value) over
+	// the measured period. An overflowed period means a value near zero.
+	d.voltage, d.current, d.power = 0, 0, 0
+	if reg := uint64(f.reg(5)); reg != 0 && overflow&stateVoltageOverflow == 0 {
+		d.voltage = int32(uint64(f.reg(2)) * div / reg)
+	}
+	if reg := uint64(f.reg(11)); reg != 0 && overflow&stateCurrentOverflow == 0 {
+		d.current = int32(uint64(f.reg(8)) * 1000000 / (reg * shunt))
+	}
+	d.powerParam = f.reg(14)
+	if reg := uint64(f.reg(17)); reg != 0 && overflow&statePowerOverflow == 0 {
+		d.power = int32(uint64(d.powerParam) * div * 1000 / (reg * shunt))
+	}
+
+	// The 16-bit pulse counter wraps around, which is also flagged in bit 7
+	// of the data update register.
+	pf := uint16(f[21])<<8 | uint16(f[22])
+	if d.havePF {
+		d.pulses += uint64(pf - d.lastPF)
+	}
+	d.lastPF, d.havePF = pf, true
+}
+
+// Voltage returns the RMS voltage in mV.
+func (d *Device) Voltage() int32 {
+	return d.voltage
+}
+
+// Current returns the RMS current in mA.
+func (d *Device) Current() int32 {
+	return d.current
+}
+
+// ActivePower returns the active power in mW.
+func (d *Device) ActivePower() int32 {
+	return d.power
+}
+
+// ApparentPower returns the apparent power in mVA.
+func (d *Device) ApparentPower() int32 {
+	return int32(int64(d.voltage) * int64(d.current) / 1000)
+}
+
+// PowerFactor returns the power factor in thousandths.
+func (d *Device) PowerFactor() int32 {
+	s := d.ApparentPower()
+	if s == 0 {
+		return 0
+	}
+	pf := int32(int64(d.power) * 1000 / int64(s))
+	if pf > 1000 {
+		pf = 1000
+	}
+	return pf
+}
+
+// Energy returns the energy counted since the first Update in mWh.
+func (d *Device) Energy() int64 {
+	if d.cfg.Shunt == 0 {
+		return 0
+	}
+	// One pulse is PowerParam * Kv * Ki / 3.6e12 kWh.
+	return int64(d.pulses * uint64(d.powerParam) * uint64(d.cfg.VoltageDivider) / (uint64(d.cfg.Shunt) * 3600000))
+}
+
+// ResetEnergy sets the energy counter back to zero.
+func (d *Device) ResetEnergy() {
+	d.pulses = 0
+}
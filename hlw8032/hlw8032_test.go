@@ -0,0 +1,66 @@
+package hlw8032
+
+import (
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+)
+
+func frame(state byte, vp, vr, ip, ir, pp, pr uint32, pf uint16) []byte {
+	f := []byte{state, 0x5A}
+	for _, v := range []uint32{vp, vr, ip, ir, pp, pr} {
+		f = append(f, byte(v>>16), byte(v>>8), byte(v))
+	}
+	f = append(f, 0x70, byte(pf>>8), byte(pf))
+	sum := byte(0)
+	for _, b := range f[2:] {
+		sum += b
+	}
+	return append(f, sum)
+}
+
+// fakeUART returns the queued bytes.
+type fakeUART struct {
+	data []byte
+}
+
+func (u *fakeUART) Read(b []byte) (int, error) {
+	n := copy(b, u.data)
+	u.data = u.data[n:]
+	return n, nil
+}
+
+func (u *fakeUART) Write(b []byte) (int, error) {
+	return len(b), nil
+}
+
+func (u *fakeUART) Buffered() int {
+	return len(u.data)
+}
+
+func TestUpdate(t *testing.T) {
+	c := qt.New(t)
+	uart := &fakeUART{}
+	d := New(uart)
+	d.Configure(Config{})
+
+	// Garbage and a frame with a bad checksum are skipped.
+	uart.data = append([]byte{0x12, 0x55, 0x00}, frame(0x55, 230000, 1880, 1000000, 1000000, 5000000, 40870, 65500)...)
+	uart.data[len(uart.data)-1]++
+	uart.data = append(uart.data, frame(0x55, 230000, 1880, 1000000, 1000000, 5000000, 40870, 65500)...)
+	c.Assert(d.Update(0), qt.IsNil)
+	c.Assert(d.Voltage(), qt.Equals, int32(230000))
+	c.Assert(d.Current(), qt.Equals, int32(1000))
+	c.Assert(d.ActivePower(), qt.Equals, int32(229997))
+	c.Assert(d.PowerFactor(), qt.Equals, int32(999))
+	c.Assert(d.Energy(), qt.Equals, int64(0))
+
+	// About 1Wh later, with the pulse counter wrapping around.
+	uart.data = frame(0xF4, 230000, 1880, 1000000, 1000000, 5000000, 40870, 347)
+	c.Assert(d.Update(0), qt.IsNil)
+	c.Assert(d.Current(), qt.Equals, int32(0))
+	c.Assert(d.Energy(), qt.Equals, int64(1000))
+
+	uart.data = frame(0xAA, 0, 0, 0, 0, 0, 0, 0)
+	c.Assert(d.Update(0), qt.Equals, errNotCalibrated)
+}
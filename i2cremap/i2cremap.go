@@ -0,0 +1,49 @@
+// Package i2cremap provides a virtual I2C bus that rewrites the target
+// address of every transaction, so that stock drivers can talk to devices
+// whose address differs from the one the driver uses.
+//
+// This is useful behind address translators such as the LTC4316, which XOR
+// the address of every device behind them with a value set by resistors, and
+// for boards with duplicated modules that were strapped to a non-default
+// address that the driver has no setting for.
+package i2cremap // import "tinygo.org/x/drivers/i2cremap"
+
+import "tinygo.org/x/drivers"
+
+// Bus is an I2C bus that rewrites target addresses before passing the
+// transactions to the underlying bus.
+type Bus struct {
+	bus       drivers.I2C
+	translate func(addr uint16) uint16
+}
+
+// New returns a bus that uses the addresses in table instead of their keys.
+// Addresses not in the table are used as they are.
+func New(bus drivers.I2C, table map[uint16]uint16) *Bus {
+	return NewFunc(bus, func(addr uint16) uint16 {
+		if real, ok := table[addr]; ok {
+			return real
+		}
+		return addr
+	})
+}
+
+// NewXOR returns a bus that XORs all addresses with translation, like an
+// LTC4316 address translator does for the devices behind it. A driver that
+// talks to address 0x40 reaches the device at 0x40^translation.
+func NewXOR(bus drivers.I2C, translation uint16) *Bus {
+	return NewFunc(bus, func(addr uint16) uint16 {
+		return addr ^ translation
+	})
+}
+
+// NewFunc returns a bus that rewrites addresses with translate.
+func NewFunc(bus drivers.I2C, translate func(addr uint16) uint16) *Bus {
+	return &Bus{bus: bus, translate: translate}
+}
+
+// Tx performs a transaction on the underlying bus, with the address
+// rewritten.
+func (b *Bus) Tx(addr uint16, w, r []byte) error {
+	return b.bus.Tx(b.translate(addr), w, r)
+}
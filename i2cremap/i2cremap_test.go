@@ -0,0 +1,31 @@
+package i2cremap
+
+import (
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+)
+
+type recorder struct {
+	addrs []uint16
+}
+
+func (r *recorder) Tx(addr uint16, w, rd []byte) error {
+	r.addrs = append(r.addrs, addr)
+	return nil
+}
+
+func TestRemap(t *testing.T) {
+	c := qt.New(t)
+	rec := &recorder{}
+
+	table := New(rec, map[uint16]uint16{0x76: 0x77})
+	table.Tx(0x76, nil, nil)
+	table.Tx(0x40, nil, nil)
+
+	// An LTC4316 with translation byte 0x10 moves 0x40 to 0x50.
+	xor := NewXOR(rec, 0x10)
+	xor.Tx(0x40, nil, nil)
+
+	c.Assert(rec.addrs, qt.DeepEquals, []uint16{0x77, 0x40, 0x50})
+}
@@ -16,6 +16,9 @@ type I2C struct {
 	sda      machine.Pin
 	nack     bool
 	baudrate uint32
+	quarter  time.Duration // a quarter of a SCL cycle
+	stretch  time.Duration
+	timeout  bool
 }
 
 // I2CConfig is used to store config info for I2C.
@@ -23,10 +26,17 @@ type I2CConfig struct {
 	Frequency uint32
 	SCL       machine.Pin
 	SDA       machine.Pin
+
+	// StretchTimeout enables support for clock stretching: devices may hold
+	// SCL low for up to this long before a transaction fails. SCL is then
+	// released instead of driven high, so it needs a pull-up resistor.
+	// Clock stretching is not supported when it is 0.
+	StretchTimeout time.Duration
 }
 
 var (
 	errSI2CAckExpected = errors.New("I2C error: expected ACK not NACK")
+	errSI2CTimeout     = errors.New("I2C error: clock stretching timeout")
 )
 
 // New returns the i2csoft driver. For the arguments, specify the pins to be
@@ -37,6 +47,7 @@ func New(sclPin, sdaPin machine.Pin) *I2C {
 		scl:      sclPin,
 		sda:      sdaPin,
 		baudrate: 100e3,
+		quarter:  2500 * time.Nanosecond,
 	}
 }
 
@@ -46,6 +57,7 @@ func (i2c *I2C) Configure(config I2CConfig) error {
 	if config.Frequency != 0 {
 		i2c.SetBaudRate(config.Frequency)
 	}
+	i2c.stretch = config.StretchTimeout
 
 	// This exists for compatibility with machine.I2CConfig. SCL and SDA must
 	// be set at the same time. Because Pin(0) is sometimes set, it is not
@@ -65,10 +77,11 @@ func (i2c *I2C) Configure(config I2CConfig) error {
 }
 
 // SetBaudRate sets the communication speed for the I2C.
+// The actual speed is lower, as the time to toggle the pins adds to the
+// delays.
 func (i2c *I2C) SetBaudRate(br uint32) {
-	// At this time, the value of i2c.baudrate is ignored because it is fixed
-	// at 100 kHz. SetBaudrate() is exist for compatibility with machine.I2C.
 	i2c.baudrate = br
+	i2c.quarter = time.Second / time.Duration(br) / 4
 }
 
 // Tx does a single I2C transaction at the specified address.
@@ -76,6 +89,13 @@ func (i2c *I2C) SetBaudRate(br uint32) {
 // bytes and stores them in r, and generates a stop condition on the bus.
 func (i2c *I2C) Tx(addr uint16, w, r []byte) error {
 	i2c.nack = false
+	i2c.timeout = false
+	defer func() {
+		if i2c.timeout {
+			// Leave SCL released, the device may still hold it.
+			i2c.sclHigh()
+		}
+	}()
 	if len(w) != 0 {
 		// send start/address for write
 		i2c.sendAddress(addr, true)
@@ -83,6 +103,9 @@ func (i2c *I2C) Tx(addr uint16, w, r []byte) error {
 		// wait until transmission complete
 
 		// ACK received (0: ACK, 1: NACK)
+		if i2c.timeout {
+			return errSI2CTimeout
+		}
 		if i2c.nack {
 			i2c.signalStop()
 			return errSI2CAckExpected
@@ -94,6 +117,9 @@ func (i2c *I2C) Tx(addr uint16, w, r []byte) error {
 		}
 
 		i2c.signalStop()
+		if i2c.timeout {
+			return errSI2CTimeout
+		}
 	}
 	if len(r) != 0 {
 		// send start/address for read
@@ -102,6 +128,9 @@ func (i2c *I2C) Tx(addr uint16, w, r []byte) error {
 		// wait transmission complete
 
 		// ACK received (0: ACK, 1: NACK)
+		if i2c.timeout {
+			return errSI2CTimeout
+		}
 		if i2c.nack {
 			i2c.signalStop()
 			return errSI2CAckExpected
@@ -122,6 +151,9 @@ func (i2c *I2C) Tx(addr uint16, w, r []byte) error {
 		i2c.sendNack()
 
 		i2c.signalStop()
+		if i2c.timeout {
+			return errSI2CTimeout
+		}
 	}
 
 	return nil
@@ -130,13 +162,13 @@ func (i2c *I2C) Tx(addr uint16, w, r []byte) error {
 // writeByte writes a single byte to the I2C bus.
 func (i2c *I2C) writeByte(data byte) {
 	// Send data byte
-	i2c.scl.Low()
+	i2c.sclLow()
 	i2c.sda.High()
 	i2c.sda.Configure(machine.PinConfig{Mode: machine.PinOutput})
 	i2c.wait()
 
 	for i := 0; i < 8; i++ {
-		i2c.scl.Low()
+		i2c.sclLow()
 		if ((data >> (7 - i)) & 1) == 1 {
 			i2c.sda.High()
 		} else {
@@ -144,16 +176,16 @@ func (i2c *I2C) writeByte(data byte) {
 		}
 		i2c.wait()
 		i2c.wait()
-		i2c.scl.High()
+		i2c.sclHigh()
 		i2c.wait()
 		i2c.wait()
 	}
 
-	i2c.scl.Low()
+	i2c.sclLow()
 	i2c.wait()
 	i2c.wait()
 	i2c.sda.Configure(machine.PinConfig{Mode: machine.PinInput})
-	i2c.scl.High()
+	i2c.sclHigh()
 	i2c.wait()
 
 	i2c.nack = i2c.sda.Get()
@@ -170,12 +202,12 @@ func (i2c *I2C) sendAddress(address uint16, write bool) {
 		data |= 1 // set read flag
 	}
 
-	i2c.scl.High()
+	i2c.sclHigh()
 	i2c.sda.Low()
 	i2c.wait()
 	i2c.wait()
 	for i := 0; i < 8; i++ {
-		i2c.scl.Low()
+		i2c.sclLow()
 		if ((data >> (7 - i)) & 1) == 1 {
 			i2c.sda.High()
 		} else {
@@ -183,16 +215,16 @@ func (i2c *I2C) sendAddress(address uint16, write bool) {
 		}
 		i2c.wait()
 		i2c.wait()
-		i2c.scl.High()
+		i2c.sclHigh()
 		i2c.wait()
 		i2c.wait()
 	}
 
-	i2c.scl.Low()
+	i2c.sclLow()
 	i2c.wait()
 	i2c.wait()
 	i2c.sda.Configure(machine.PinConfig{Mode: machine.PinInput})
-	i2c.scl.High()
+	i2c.sclHigh()
 	i2c.wait()
 
 	i2c.nack = i2c.sda.Get()
@@ -203,12 +235,12 @@ func (i2c *I2C) sendAddress(address uint16, write bool) {
 }
 
 func (i2c *I2C) signalStop() {
-	i2c.scl.Low()
+	i2c.sclLow()
 	i2c.sda.Low()
 	i2c.sda.Configure(machine.PinConfig{Mode: machine.PinOutput})
 	i2c.wait()
 	i2c.wait()
-	i2c.scl.High()
+	i2c.sclHigh()
 	i2c.wait()
 	i2c.wait()
 	i2c.sda.High()
@@ -219,12 +251,12 @@ func (i2c *I2C) signalStop() {
 func (i2c *I2C) signalRead() {
 	i2c.wait()
 	i2c.wait()
-	i2c.scl.Low()
+	i2c.sclLow()
 	i2c.sda.Low()
 	i2c.sda.Configure(machine.PinConfig{Mode: machine.PinOutput})
 	i2c.wait()
 	i2c.wait()
-	i2c.scl.High()
+	i2c.sclHigh()
 	i2c.wait()
 	i2c.wait()
 }
@@ -232,11 +264,11 @@ func (i2c *I2C) signalRead() {
 func (i2c *I2C) readByte() byte {
 	var data byte
 	for i := 0; i < 8; i++ {
-		i2c.scl.Low()
+		i2c.sclLow()
 		i2c.sda.Configure(machine.PinConfig{Mode: machine.PinInput})
 		i2c.wait()
 		i2c.wait()
-		i2c.scl.High()
+		i2c.sclHigh()
 		if i2c.sda.Get() {
 			data |= 1 << (7 - i)
 		}
@@ -249,12 +281,12 @@ func (i2c *I2C) readByte() byte {
 func (i2c *I2C) sendNack() {
 	i2c.wait()
 	i2c.wait()
-	i2c.scl.Low()
+	i2c.sclLow()
 	i2c.sda.High()
 	i2c.sda.Configure(machine.PinConfig{Mode: machine.PinOutput})
 	i2c.wait()
 	i2c.wait()
-	i2c.scl.High()
+	i2c.sclHigh()
 	i2c.wait()
 	i2c.wait()
 }
@@ -282,7 +314,35 @@ func (i2c *I2C) ReadRegister(address uint8, register uint8, data []byte) error {
 	return i2c.Tx(uint16(address), []byte{register}, data)
 }
 
-// wait waits for half the time of the SCL operation interval.
+// wait waits for a quarter of a SCL cycle.
 func (i2c *I2C) wait() {
-	delay.Sleep(50 * time.Microsecond) // half of a 100kHz cycle (50µs)
+	delay.Sleep(i2c.quarter)
+}
+
+// sclHigh sets SCL high. With clock stretching, it releases SCL and waits
+// until the device releases it too.
+func (i2c *I2C) sclHigh() {
+	if i2c.stretch == 0 {
+		i2c.scl.High()
+		return
+	}
+	i2c.scl.Configure(machine.PinConfig{Mode: machine.PinInputPullup})
+	if i2c.timeout {
+		return
+	}
+	start := time.Now()
+	for !i2c.scl.Get() {
+		if time.Since(start) > i2c.stretch {
+			i2c.timeout = true
+			return
+		}
+	}
+}
+
+// sclLow pulls SCL low.
+func (i2c *I2C) sclLow() {
+	if i2c.stretch != 0 {
+		i2c.scl.Configure(machine.PinConfig{Mode: machine.PinOutput})
+	}
+	i2c.scl.Low()
 }
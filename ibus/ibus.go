@@ -0,0 +1,129 @@
+// Package ibus implements a decoder for the FlySky iBUS protocol used by
+// hobby RC receivers.
+//
+// iBUS is a 115200 baud UART signal with 8 data bits, no parity and one stop
+// bit. The servo output of the receiver (usually labeled "SERVO" or
+// "IBUS OUT") sends a frame every 7ms.
+//
+// Protocol reference: https://basejunction.wordpress.com/2015/08/23/en-flysky-i6-14-channels-part1/
+package ibus // import "tinygo.org/x/drivers/ibus"
+
+import (
+	"time"
+
+	"tinygo.org/x/drivers"
+)
+
+// Channels is the number of channels in a frame.
+const Channels = 14
+
+const (
+	frameSize = 32
+	header0   = 0x20 // frame length
+	header1   = 0x40 // channel data command
+)
+
+// Timeout is the time without a frame after which the signal is considered
+// lost. Most receivers simply stop sending frames when the transmitter is
+// lost, unless failsafe values are configured on the transmitter.
+const Timeout = 100 * time.Millisecond
+
+// Frame is a decoded iBUS frame.
+type Frame struct {
+	// Channel values as servo pulse widths in µs, 1000 to 2000.
+	Channels [Channels]uint16
+}
+
+// Pulse returns a channel as a servo pulse width in µs, centered at 1500.
+func (f *Frame) Pulse(channel int) uint16 {
+	return f.Channels[channel]
+}
+
+// Decoder assembles frames from received bytes.
+type Decoder struct {
+	buf [frameSize]byte
+	n   int
+}
+
+// Feed adds a received byte. It returns true with the frame when the byte
+// completes a frame with a valid checksum.
+func (d *Decoder) Feed(b byte, f *Frame) bool {
+	switch {
+	case d.n == 0 && b != header0:
+		return false
+	case d.n == 1 && b != header1:
+		d.n = 0
+		if b == header0 {
+			d.buf[0] = b
+			d.n = 1
+		}
+		return false
+	}
+	d.buf[d.n] = b
+	d.n++
+	if d.n < frameSize {
+		return false
+	}
+	d.n = 0
+
+	sum := uint16(0xFFFF)
+	for _, v := range d.buf[:frameSize-2] {
+		sum -= uint16(v)
+	}
+	if sum != uint16(d.buf[frameSize-2])|uint16(d.buf[frameSize-1])<<8 {
+		return false
+	}
+	for i := range f.Channels {
+		f.Channels[i] = (uint16(d.buf[2+2*i]) | uint16(d.buf[3+2*i])<<8) & 0x0FFF
+	}
+	return true
+}
+
+// Device reads iBUS frames from a UART.
+type Device struct {
+	uart    drivers.UART
+	decoder Decoder
+	frame   Frame
+	last    time.Time
+	buf     [32]byte
+}
+
+// New returns a new iBUS receiver. The UART must already be configured at
+// 115200 baud.
+func New(uart drivers.UART) *Device {
+	return &Device{uart: uart}
+}
+
+// Update reads the received bytes and returns whether a new frame was
+// decoded.
+func (d *Device) Update() (bool, error) {
+	updated := false
+	for d.uart.Buffered() > 0 {
+		n, err := d.uart.Read(d.buf[:])
+		if err != nil {
+			return updated, err
+		}
+		for _, b := range d.buf[:n] {
+			if d.decoder.Feed(b, &d.frame) {
+				d.last = time.Now()
+				updated = true
+			}
+		}
+	}
+	return updated, nil
+}
+
+// Frame returns the last decoded frame.
+func (d *Device) Frame() *Frame {
+	return &d.frame
+}
+
+// Pulse returns a channel of the last frame as a servo pulse width in µs.
+func (d *Device) Pulse(channel int) uint16 {
+	return d.frame.Pulse(channel)
+}
+
+// SignalLost returns whether no frame was received for Timeout.
+func (d *Device) SignalLost() bool {
+	return d.last.IsZero() || time.Since(d.last) > Timeout
+}
@@ -0,0 +1,40 @@
+package ibus
+
+import (
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+)
+
+func TestDecoder(t *testing.T) {
+	c := qt.New(t)
+
+	frame := []byte{header0, header1}
+	var want Frame
+	for i := range want.Channels {
+		want.Channels[i] = uint16(1000 + i*50)
+		frame = append(frame, byte(want.Channels[i]), byte(want.Channels[i]>>8))
+	}
+	sum := uint16(0xFFFF)
+	for _, b := range frame {
+		sum -= uint16(b)
+	}
+	frame = append(frame, byte(sum), byte(sum>>8))
+
+	var d Decoder
+	var f Frame
+	complete := 0
+	for _, b := range append([]byte{0x20, 0x01}, frame...) {
+		if d.Feed(b, &f) {
+			complete++
+		}
+	}
+	c.Assert(complete, qt.Equals, 1)
+	c.Assert(f, qt.Equals, want)
+
+	// A corrupted frame is rejected.
+	frame[5] ^= 0x01
+	for _, b := range frame {
+		c.Assert(d.Feed(b, &f), qt.IsFalse)
+	}
+}
@@ -0,0 +1,120 @@
+// Package ibutton reads the ROM ID of iButton keys, such as the DS1990A, for
+// access control applications.
+//
+// Datasheet: https://www.analog.com/media/en/technical-documentation/data-sheets/DS1990A.pdf
+package ibutton // import "tinygo.org/x/drivers/ibutton"
+
+import (
+	"encoding/hex"
+)
+
+// FamilyDS1990 is the family code of the DS1990A serial number iButton.
+const FamilyDS1990 = 0x01
+
+// Bus is a 1-Wire bus, such as onewire.Device, onewire.UARTDevice or
+// ds248x.Device.
+type Bus interface {
+	ReadAddress() ([]uint8, error)
+}
+
+// ID is the 64-bit ROM ID of an iButton: the family code, the 48-bit serial
+// number (LSB first) and the CRC.
+type ID [8]byte
+
+// Family returns the family code of the iButton.
+func (id ID) Family() uint8 {
+	return id[0]
+}
+
+// Serial returns the 48-bit serial number of the iButton.
+func (id ID) Serial() uint64 {
+	var serial uint64
+	for i := 6; i >= 1; i-- {
+		serial = serial<<8 | uint64(id[i])
+	}
+	return serial
+}
+
+// IsDS1990 returns whether the iButton is a DS1990A serial number key.
+func (id ID) IsDS1990() bool {
+	return id.Family() == FamilyDS1990
+}
+
+// String returns the ID the way it is usually engraved on the key: the CRC,
+// the serial number and the family code in hexadecimal, MSB first.
+func (id ID) String() string {
+	var b [8]byte
+	for i := range b {
+		b[i] = id[7-i]
+	}
+	return hex.EncodeToString(b[:])
+}
+
+// Event is a change of the key on the reader.
+type Event uint8
+
+const (
+	// None means nothing changed since the last poll.
+	None Event = iota
+	// Touched means a key was put on the reader.
+	Touched
+	// Released means the key was removed from the reader.
+	Released
+)
+
+// Reader polls a 1-Wire bus with a single iButton probe.
+type Reader struct {
+	bus    Bus
+	id     ID
+	misses uint8
+
+	// Debounce is the number of polls in a row without a key before the key
+	// is considered released, as the contact of a key held by hand is often
+	// lost for a moment. It defaults to 3 if zero.
+	Debounce uint8
+}
+
+// New returns a new iButton reader on the given bus.
+//
+// This function only creates the Reader object, it does not touch the bus.
+func New(bus Bus) *Reader {
+	return &Reader{
+		bus: bus,
+	}
+}
+
+// Poll reads the bus once and returns the current key, if any, and what
+// changed. A different key replacing the previous one without a release in
+// between is reported as Touched.
+func (r *Reader) Poll() (ID, Event) {
+	var id ID
+	rom, err := r.bus.ReadAddress()
+	if err == nil && len(rom) == len(id) {
+		copy(id[:], rom)
+		r.misses = 0
+		if id == r.id {
+			return id, None
+		}
+		r.id = id
+		return id, Touched
+	}
+
+	if r.id == (ID{}) {
+		return id, None
+	}
+	debounce := r.Debounce
+	if debounce == 0 {
+		debounce = 3
+	}
+	r.misses++
+	if r.misses < debounce {
+		return r.id, None
+	}
+	id, r.id, r.misses = r.id, ID{}, 0
+	return id, Released
+}
+
+// Present returns whether a key is on the reader, as of the last poll.
+func (r *Reader) Present() bool {
+	return r.id != ID{}
+}
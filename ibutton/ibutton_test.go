@@ -0,0 +1,55 @@
+package ibutton
+
+import (
+	"errors"
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+)
+
+type fakeBus struct {
+	rom []uint8
+}
+
+func (b *fakeBus) ReadAddress() ([]uint8, error) {
+	if b.rom == nil {
+		return nil, errors.New("no presence")
+	}
+	return b.rom, nil
+}
+
+func TestID(t *testing.T) {
+	c := qt.New(t)
+	id := ID{0x01, 0xA1, 0xB2, 0xC3, 0xD4, 0xE5, 0x00, 0x4F}
+	c.Assert(id.IsDS1990(), qt.IsTrue)
+	c.Assert(id.Serial(), qt.Equals, uint64(0x00E5D4C3B2A1))
+	c.Assert(id.String(), qt.Equals, "4f00e5d4c3b2a101")
+}
+
+func TestPoll(t *testing.T) {
+	c := qt.New(t)
+	bus := &fakeBus{}
+	r := New(bus)
+
+	_, ev := r.Poll()
+	c.Assert(ev, qt.Equals, None)
+
+	bus.rom = []uint8{0x01, 1, 2, 3, 4, 5, 6, 7}
+	id, ev := r.Poll()
+	c.Assert(ev, qt.Equals, Touched)
+	c.Assert(id.Serial(), qt.Equals, uint64(0x060504030201))
+	_, ev = r.Poll()
+	c.Assert(ev, qt.Equals, None)
+	c.Assert(r.Present(), qt.IsTrue)
+
+	// Short contact loss is debounced.
+	bus.rom = nil
+	for i := 0; i < 2; i++ {
+		_, ev = r.Poll()
+		c.Assert(ev, qt.Equals, None)
+	}
+	id, ev = r.Poll()
+	c.Assert(ev, qt.Equals, Released)
+	c.Assert(id.Family(), qt.Equals, uint8(0x01))
+	c.Assert(r.Present(), qt.IsFalse)
+}
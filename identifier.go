@@ -0,0 +1,18 @@
+package drivers
+
+// Identifier is implemented by drivers that can report which chip they are
+// talking to. Provisioning and test firmware can use it to log which
+// hardware variant is populated on a board.
+type Identifier interface {
+	// DriverName returns the name of the driver package, for example
+	// "bme280".
+	DriverName() string
+
+	// ChipID reads the identification value of the chip, usually the
+	// content of its WHO_AM_I or chip ID register.
+	ChipID() (uint32, error)
+
+	// RevisionID reads the silicon or product revision of the chip. It
+	// returns 0 for chips that don't report one.
+	RevisionID() (uint32, error)
+}
@@ -47,14 +47,18 @@ func New(bus drivers.I2C) Device {
 // * CurrentConvTime = CONVTIME_1100USEC
 // * Mode = MODE_CONTINUOUS | MODE_VOLTAGE | MODE_CURRENT
 func (d *Device) Configure(cfg Config) {
+	d.WriteRegister(REG_CONFIG, cfg.register())
+}
+
+// register returns the value of the configuration register for cfg.
+func (cfg Config) register() uint16 {
 	var val uint16
 
 	val = uint16(cfg.AverageMode&0x7) << 9
 	val |= uint16(cfg.VoltConvTime&0x7) << 6
 	val |= uint16(cfg.CurrentConvTime&0x7) << 3
 	val |= uint16(cfg.Mode & 0x7)
-
-	d.WriteRegister(REG_CONFIG, val)
+	return val
 }
 
 // Resets the device, setting all registers to default values
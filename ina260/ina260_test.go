@@ -1,7 +1,11 @@
 package ina260
 
 import (
+	"errors"
+	"strconv"
+	"strings"
 	"testing"
+	"time"
 
 	qt "github.com/frankban/quicktest"
 	"tinygo.org/x/drivers/tester"
@@ -78,3 +82,48 @@ func defaultRegisters() map[uint8]uint16 {
 		REG_DIE_ID:     0x2270,
 	}
 }
+
+func TestProfiler(t *testing.T) {
+	c := qt.New(t)
+	bus := tester.NewI2CBus(c)
+	fake := tester.NewI2CDevice16(c, Address)
+	fake.Registers = defaultRegisters()
+	fake.Registers[REG_CURRENT] = 0xFFFF // -1.25mA
+	fake.Registers[REG_BUSVOLTAGE] = 0x0F00
+	bus.AddDevice(fake)
+
+	dev := New(bus)
+	p, err := dev.NewProfiler(make([]Sample, 3), ProfilerConfig{
+		Period:  100 * time.Microsecond,
+		Voltage: true,
+	})
+	c.Assert(err, qt.IsNil)
+	// No conversion time fits, so the fastest one is used.
+	c.Assert(fake.Registers[REG_CONFIG], qt.Equals, uint16(0x0007))
+
+	samples, err := p.Burst()
+	c.Assert(err, qt.IsNil)
+	c.Assert(samples, qt.HasLen, 3)
+	c.Assert(samples[2].Current, qt.Equals, int32(-1250))
+	c.Assert(samples[2].Voltage, qt.Equals, int32(0x0F00*1250))
+	c.Assert(samples[2].Time > samples[0].Time, qt.IsTrue)
+
+	var out strings.Builder
+	c.Assert(p.Write(&out, samples[:1]), qt.IsNil)
+	c.Assert(out.String(), qt.Equals, strconv.Itoa(int(samples[0].Time))+",-1250,4800000\n")
+}
+
+// failBus is an I2C bus without any device answering.
+type failBus struct{}
+
+func (failBus) Tx(addr uint16, w, r []byte) error {
+	return errors.New("no ack")
+}
+
+func TestProfilerMissingSensor(t *testing.T) {
+	c := qt.New(t)
+	dev := New(failBus{})
+	p, err := dev.NewProfiler(make([]Sample, 3), ProfilerConfig{})
+	c.Assert(err, qt.ErrorMatches, "no ack")
+	c.Assert(p, qt.IsNil)
+}
@@ -0,0 +1,194 @@
+package ina260
+
+import (
+	"io"
+	"strconv"
+	"time"
+
+	"tinygo.org/x/drivers/internal/legacy"
+)
+
+// Sample is a single profiler measurement.
+type Sample struct {
+	// Time since the start of the profile, in µs.
+	Time uint32
+
+	// Current in µA and voltage in µV. Voltage is 0 when not sampled.
+	Current int32
+	Voltage int32
+}
+
+// Format is the output format of the profiler.
+type Format uint8
+
+const (
+	// FormatCSV writes one "time_us,current_uA,voltage_uV" line per sample,
+	// after a header line.
+	FormatCSV Format = iota
+
+	// FormatBinary writes 12 bytes per sample: time, current and voltage,
+	// each as a little endian 32 bit integer.
+	FormatBinary
+)
+
+// ProfilerConfig holds the settings of a power profile.
+type ProfilerConfig struct {
+	// Period between samples. The device is configured for the fastest
+	// conversion that fits. Defaults to 1ms; at 400kHz I2C, about 300µs is
+	// the fastest possible without voltage sampling.
+	Period time.Duration
+
+	// Voltage also samples the bus voltage, halving the maximum rate.
+	Voltage bool
+
+	Format Format
+}
+
+// Profiler samples the current at a high rate into a RAM buffer, and writes
+// full buffers to an io.Writer such as a file on an SD card. Sampling pauses
+// while a buffer is written, so a profile is a sequence of bursts.
+type Profiler struct {
+	dev     *Device
+	cfg     ProfilerConfig
+	buf     []Sample
+	start   time.Time
+	started bool
+	line    []byte
+}
+
+// NewProfiler returns a profiler using buf to store samples. It reconfigures
+// the device for fast continuous conversions without averaging, and returns
+// the bus error if that fails, such as when the sensor is missing.
+func (d *Device) NewProfiler(buf []Sample, cfg ProfilerConfig) (*Profiler, error) {
+	if cfg.Period == 0 {
+		cfg.Period = time.Millisecond
+	}
+
+	// Pick the longest conversion time that still fits the period, for the
+	// lowest noise.
+	convTime := byte(CONVTIME_140USEC)
+	for i, t := range []time.Duration{204, 332, 588, 1100, 2116, 4156, 8244} {
+		t *= time.Microsecond
+		if cfg.Voltage {
+			t *= 2
+		}
+		if t > cfg.Period {
+			break
+		}
+		convTime = byte(i + 1)
+	}
+
+	mode := byte(MODE_CONTINUOUS | MODE_CURRENT)
+	if cfg.Voltage {
+		mode |= MODE_VOLTAGE
+	}
+	err := d.writeRegister(REG_CONFIG, Config{
+		AverageMode:     AVGMODE_1,
+		VoltConvTime:    convTime,
+		CurrentConvTime: convTime,
+		Mode:            mode,
+	}.register())
+	if err != nil {
+		return nil, err
+	}
+	return &Profiler{dev: d, cfg: cfg, buf: buf, line: make([]byte, 0, 40)}, nil
+}
+
+// Burst fills the buffer with samples taken at the configured period and
+// returns them. Deadlines are absolute, so the time stamps stay accurate
+// when a sample takes longer than the period.
+func (p *Profiler) Burst() ([]Sample, error) {
+	if !p.started {
+		p.start = time.Now()
+		p.started = true
+	}
+	next := time.Now()
+	for i := range p.buf {
+		if wait := time.Until(next); wait > 0 {
+			time.Sleep(wait)
+		}
+		now := time.Now()
+		next = next.Add(p.cfg.Period)
+		if next.Before(now) {
+			next = now
+		}
+
+		s := Sample{Time: uint32(now.Sub(p.start) / time.Microsecond)}
+		raw, err := p.dev.readRegister(REG_CURRENT)
+		if err != nil {
+			return p.buf[:i], err
+		}
+		s.Current = int32(int16(raw)) * 1250
+		if p.cfg.Voltage {
+			raw, err = p.dev.readRegister(REG_BUSVOLTAGE)
+			if err != nil {
+				return p.buf[:i], err
+			}
+			s.Voltage = int32(int16(raw)) * 1250
+		}
+		p.buf[i] = s
+	}
+	return p.buf, nil
+}
+
+// Run samples bursts and writes them to w until the duration has passed.
+// With FormatCSV, a header line is written first.
+func (p *Profiler) Run(w io.Writer, duration time.Duration) error {
+	if p.cfg.Format == FormatCSV {
+		if _, err := io.WriteString(w, "time_us,current_uA,voltage_uV\n"); err != nil {
+			return err
+		}
+	}
+	p.started = false
+	for {
+		samples, err := p.Burst()
+		if err != nil {
+			return err
+		}
+		if err := p.Write(w, samples); err != nil {
+			return err
+		}
+		if time.Since(p.start) >= duration {
+			return nil
+		}
+	}
+}
+
+// Write writes samples to w in the configured format.
+func (p *Profiler) Write(w io.Writer, samples []Sample) error {
+	for _, s := range samples {
+		p.line = p.line[:0]
+		if p.cfg.Format == FormatBinary {
+			p.line = appendUint32(p.line, s.Time)
+			p.line = appendUint32(p.line, uint32(s.Current))
+			p.line = appendUint32(p.line, uint32(s.Voltage))
+		} else {
+			p.line = strconv.AppendUint(p.line, uint64(s.Time), 10)
+			p.line = append(p.line, ',')
+			p.line = strconv.AppendInt(p.line, int64(s.Current), 10)
+			p.line = append(p.line, ',')
+			p.line = strconv.AppendInt(p.line, int64(s.Voltage), 10)
+			p.line = append(p.line, '\n')
+		}
+		if _, err := w.Write(p.line); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func appendUint32(b []byte, v uint32) []byte {
+	return append(b, byte(v), byte(v>>8), byte(v>>16), byte(v>>24))
+}
+
+// readRegister reads a register, returning bus errors.
+func (d *Device) readRegister(reg uint8) (uint16, error) {
+	var data [2]byte
+	err := legacy.ReadRegister(d.bus, uint8(d.Address), reg, data[:])
+	return uint16(data[0])<<8 | uint16(data[1]), err
+}
+
+// writeRegister writes a register, returning bus errors.
+func (d *Device) writeRegister(reg uint8, v uint16) error {
+	return legacy.WriteRegister(d.bus, uint8(d.Address), reg, []byte{byte(v >> 8), byte(v)})
+}
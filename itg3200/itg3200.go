@@ -0,0 +1,185 @@
+// Package itg3200 provides a driver for the ITG-3200 3-axis gyroscope by
+// InvenSense.
+//
+// Datasheet: https://www.sparkfun.com/datasheets/Sensors/Gyro/PS-ITG-3200-00-01.4.pdf
+package itg3200 // import "tinygo.org/x/drivers/itg3200"
+
+import (
+	"errors"
+	"time"
+
+	"tinygo.org/x/drivers"
+	"tinygo.org/x/drivers/internal/legacy"
+)
+
+var errNotConnected = errors.New("itg3200: device not found")
+
+// LowPass is the bandwidth of the digital low-pass filter. It also sets the
+// internal sample rate: 8kHz for LowPass256Hz, 1kHz for the others.
+type LowPass uint8
+
+const (
+	LowPass256Hz LowPass = iota
+	LowPass188Hz
+	LowPass98Hz
+	LowPass42Hz
+	LowPass20Hz
+	LowPass10Hz
+	LowPass5Hz
+)
+
+// ClockSource selects the clock of the device. Using one of the gyroscope
+// oscillators is more stable than the internal oscillator.
+type ClockSource uint8
+
+const (
+	ClockInternal ClockSource = iota
+	ClockGyroX
+	ClockGyroY
+	ClockGyroZ
+)
+
+// Config holds the configuration of the device.
+type Config struct {
+	// LowPass is the filter bandwidth, LowPass256Hz by default.
+	LowPass LowPass
+
+	// SampleRateDivider divides the internal sample rate: the output rate is
+	// internal rate / (SampleRateDivider + 1).
+	SampleRateDivider uint8
+
+	// Clock is the clock source, ClockGyroX if left at ClockInternal.
+	Clock ClockSource
+
+	// DataReadyInterrupt enables the active high data ready interrupt on the
+	// INT pin.
+	DataReadyInterrupt bool
+}
+
+// Device wraps an I2C connection to an ITG-3200 device.
+type Device struct {
+	bus     drivers.I2C
+	Address uint16
+	buf     [8]byte
+	temp    int16
+	gyro    [3]int16
+}
+
+// New creates a new ITG-3200 connection. The I2C bus must already be
+// configured.
+//
+// This function only creates the Device object, it does not touch the device.
+func New(bus drivers.I2C) *Device {
+	return &Device{bus: bus, Address: Address}
+}
+
+// Connected returns whether an ITG-3200 has been found.
+func (d *Device) Connected() bool {
+	id, err := d.read8(WHO_AM_I)
+	return err == nil && id&0x7E == Address
+}
+
+// Configure resets the device and sets it up according to cfg.
+func (d *Device) Configure(cfg Config) error {
+	if !d.Connected() {
+		return errNotConnected
+	}
+	err := d.write8(PWR_MGM, pwrReset)
+	if err != nil {
+		return err
+	}
+	time.Sleep(5 * time.Millisecond)
+
+	clock := cfg.Clock
+	if clock == ClockInternal {
+		clock = ClockGyroX
+	}
+	err = d.write8(PWR_MGM, uint8(clock))
+	if err != nil {
+		return err
+	}
+	err = d.write8(SMPLRT_DIV, cfg.SampleRateDivider)
+	if err != nil {
+		return err
+	}
+	err = d.write8(DLPF_FS, fsSel2000|uint8(cfg.LowPass))
+	if err != nil {
+		return err
+	}
+	intCfg := uint8(0)
+	if cfg.DataReadyInterrupt {
+		intCfg = intAnyRead | intDataReady
+	}
+	err = d.write8(INT_CFG, intCfg)
+	if err != nil {
+		return err
+	}
+	// Wait for the gyroscope to start up.
+	time.Sleep(50 * time.Millisecond)
+	return nil
+}
+
+// DataReady returns whether a new sample is available.
+func (d *Device) DataReady() (bool, error) {
+	status, err := d.read8(INT_STATUS)
+	return status&statusReady != 0, err
+}
+
+// Sleep puts the device in the low power sleep mode, or wakes it up.
+func (d *Device) Sleep(sleep bool) error {
+	pwr, err := d.read8(PWR_MGM)
+	if err != nil {
+		return err
+	}
+	if sleep {
+		pwr |= pwrSleep
+	} else {
+		pwr &^= pwrSleep
+	}
+	return d.write8(PWR_MGM, pwr)
+}
+
+// Update reads the requested measurements from the device. It supports
+// drivers.Temperature and drivers.AngularVelocity, which are read together.
+func (d *Device) Update(which drivers.Measurement) error {
+	if which&(drivers.Temperature|drivers.AngularVelocity) == 0 {
+		return nil
+	}
+	err := legacy.ReadRegister(d.bus, uint8(d.Address), TEMP_OUT_H, d.buf[:])
+	if err != nil {
+		return err
+	}
+	d.temp = int16(uint16(d.buf[0])<<8 | uint16(d.buf[1]))
+	for i := range d.gyro {
+		d.gyro[i] = int16(uint16(d.buf[2+2*i])<<8 | uint16(d.buf[3+2*i]))
+	}
+	return nil
+}
+
+// AngularVelocity returns the last read rotation in µ°/s (micro-degrees/sec).
+func (d *Device) AngularVelocity() (x, y, z int32) {
+	return scaleGyro(d.gyro[0]), scaleGyro(d.gyro[1]), scaleGyro(d.gyro[2])
+}
+
+// Temperature returns the last read temperature in celsius milli degrees (1°C
+// is 1000).
+func (d *Device) Temperature() int32 {
+	// -13200 is 35°C, with 280 LSB per °C.
+	return 35000 + (int32(d.temp)+13200)*25/7
+}
+
+// scaleGyro converts a raw reading to µ°/s. The sensitivity is 14.375 LSB
+// per °/s, which is 8000000/115 µ°/s per LSB.
+func scaleGyro(raw int16) int32 {
+	return int32(int64(raw) * 8000000 / 115)
+}
+
+func (d *Device) read8(reg uint8) (uint8, error) {
+	err := legacy.ReadRegister(d.bus, uint8(d.Address), reg, d.buf[:1])
+	return d.buf[0], err
+}
+
+func (d *Device) write8(reg, value uint8) error {
+	d.buf[0] = value
+	return legacy.WriteRegister(d.bus, uint8(d.Address), reg, d.buf[:1])
+}
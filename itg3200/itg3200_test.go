@@ -0,0 +1,29 @@
+package itg3200
+
+import (
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+	"tinygo.org/x/drivers"
+	"tinygo.org/x/drivers/tester"
+)
+
+func TestUpdate(t *testing.T) {
+	c := qt.New(t)
+	bus := tester.NewI2CBus(c)
+	fake := tester.NewI2CDevice(c, Address)
+	fake.Registers[WHO_AM_I] = Address
+	bus.AddDevice(fake)
+
+	dev := New(bus)
+	c.Assert(dev.Connected(), qt.IsTrue)
+
+	// 35°C, then 14 LSB (0.97°/s) on X and -2875 LSB (-200°/s) on Z.
+	copy(fake.Registers[TEMP_OUT_H:], []byte{0xCC, 0x70, 0x00, 0x0E, 0x00, 0x00, 0xF4, 0xC5})
+	c.Assert(dev.Update(drivers.AngularVelocity), qt.IsNil)
+	c.Assert(dev.Temperature(), qt.Equals, int32(35000))
+	x, y, z := dev.AngularVelocity()
+	c.Assert(x, qt.Equals, int32(973913))
+	c.Assert(y, qt.Equals, int32(0))
+	c.Assert(z, qt.Equals, int32(-200000000))
+}
@@ -0,0 +1,37 @@
+package itg3200
+
+// Addresses of the ITG-3200, selected by the AD0 pin.
+const (
+	Address     = 0x68 // AD0 low
+	AddressHigh = 0x69 // AD0 high
+)
+
+// Registers
+const (
+	WHO_AM_I    = 0x00
+	SMPLRT_DIV  = 0x15
+	DLPF_FS     = 0x16
+	INT_CFG     = 0x17
+	INT_STATUS  = 0x1A
+	TEMP_OUT_H  = 0x1B
+	GYRO_XOUT_H = 0x1D
+	PWR_MGM     = 0x3E
+)
+
+// Bits of DLPF_FS. The full scale range must always be set to ±2000°/s.
+const (
+	fsSel2000 = 0b11 << 3
+)
+
+// Bits of INT_CFG and INT_STATUS.
+const (
+	intAnyRead   = 1 << 4
+	intDataReady = 1 << 0
+	statusReady  = 1 << 0
+)
+
+// Bits of PWR_MGM.
+const (
+	pwrReset = 1 << 7
+	pwrSleep = 1 << 6
+)
@@ -0,0 +1,124 @@
+package l3gd20
+
+import "errors"
+
+var ErrBadCutoff = errors.New("bad high-pass filter cutoff value")
+
+// HighPassMode is the mode of the high-pass filter, see CTRL_REG2.
+type HighPassMode uint8
+
+const (
+	// Normal mode, the filter is reset by reading the REFERENCE register.
+	HighPassNormalReset HighPassMode = 0b00
+	// The output is the difference to the value in the REFERENCE register.
+	HighPassReference HighPassMode = 0b01
+	HighPassNormal    HighPassMode = 0b10
+	// The filter is reset automatically on interrupt events.
+	HighPassAutoReset HighPassMode = 0b11
+)
+
+// FIFOMode is the mode of the 32 sample FIFO, see FIFO_CTRL_REG.
+type FIFOMode uint8
+
+const (
+	// The FIFO is not used, the output registers hold the latest sample.
+	FIFOBypass FIFOMode = 0b000
+	// Samples are collected until the FIFO is full.
+	FIFOFill FIFOMode = 0b001
+	// Samples are collected, the oldest one is dropped when the FIFO is full.
+	FIFOStream FIFOMode = 0b010
+	// Stream mode until an interrupt event, then FIFO mode.
+	FIFOStreamToFIFO FIFOMode = 0b011
+	// Bypass mode until an interrupt event, then stream mode.
+	FIFOBypassToStream FIFOMode = 0b100
+)
+
+const (
+	reg5HighPassBit = 1 << 4
+	reg5OutSelMask  = 0b11
+	reg5OutSelHPF   = 0b01
+	fifoSrcOverrun  = 1 << 6
+	fifoSrcLevel    = 0b11111
+	autoIncrement   = 1 << 7
+)
+
+// ConfigureHighPass enables the high-pass filter on the output registers and
+// the FIFO. The cutoff frequency is selected by a value from 0 to 9, its
+// frequency depends on the output data rate, see table 27 of the datasheet.
+// At the default rate of 95Hz, 0 is 7.2Hz and 9 is 0.018Hz.
+func (d *DevI2C) ConfigureHighPass(mode HighPassMode, cutoff uint8) error {
+	if cutoff > 9 {
+		return ErrBadCutoff
+	}
+	err := d.write8(CTRL_REG2, uint8(mode)<<4|cutoff)
+	if err != nil {
+		return err
+	}
+	reg5, err := d.read8(CTRL_REG5)
+	if err != nil {
+		return err
+	}
+	reg5 = reg5&^reg5OutSelMask | reg5HighPassBit | reg5OutSelHPF
+	return d.write8(CTRL_REG5, reg5)
+}
+
+// DisableHighPass disables the high-pass filter.
+func (d *DevI2C) DisableHighPass() error {
+	reg5, err := d.read8(CTRL_REG5)
+	if err != nil {
+		return err
+	}
+	return d.write8(CTRL_REG5, reg5&^(reg5HighPassBit|reg5OutSelMask))
+}
+
+// ConfigureFIFO sets the FIFO mode and the watermark level (0 to 31) at which
+// the watermark interrupt is raised on DRDY/INT2. Switching to FIFOBypass
+// disables the FIFO and empties it.
+func (d *DevI2C) ConfigureFIFO(mode FIFOMode, watermark uint8) error {
+	reg5, err := d.read8(CTRL_REG5)
+	if err != nil {
+		return err
+	}
+	if mode == FIFOBypass {
+		reg5 &^= reg5FIFOEnableBit
+	} else {
+		reg5 |= reg5FIFOEnableBit
+	}
+	err = d.write8(CTRL_REG5, reg5)
+	if err != nil {
+		return err
+	}
+	return d.write8(FIFO_CTRL_REG, uint8(mode)<<5|watermark&fifoSrcLevel)
+}
+
+// FIFOLen returns the number of samples stored in the FIFO.
+func (d *DevI2C) FIFOLen() (int, error) {
+	src, err := d.read8(FIFO_SRC_REG)
+	if err != nil {
+		return 0, err
+	}
+	if src&fifoSrcOverrun != 0 {
+		return 32, nil
+	}
+	return int(src & fifoSrcLevel), nil
+}
+
+// ReadFIFO reads up to len(dst) samples from the FIFO, in microradians per
+// second, and returns the number of samples read.
+func (d *DevI2C) ReadFIFO(dst [][3]int32) (int, error) {
+	n, err := d.FIFOLen()
+	if err != nil {
+		return 0, err
+	}
+	if n > len(dst) {
+		n = len(dst)
+	}
+	for i := 0; i < n; i++ {
+		err = d.bus.Tx(uint16(d.addr), []byte{OUT_X_L | autoIncrement}, d.databuf[:])
+		if err != nil {
+			return i, err
+		}
+		dst[i] = d.scale(d.databuf[:])
+	}
+	return n, nil
+}
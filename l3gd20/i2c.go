@@ -87,7 +87,12 @@ func (d *DevI2C) Configure(cfg Config) error {
 	return nil
 }
 
-func (d *DevI2C) Update() error {
+// Update reads the angular velocity from the device if which includes
+// drivers.AngularVelocity. Other measurements are ignored.
+func (d *DevI2C) Update(which drivers.Measurement) error {
+	if which&drivers.AngularVelocity == 0 {
+		return nil
+	}
 	err := legacy.ReadRegister(d.bus, d.addr, OUT_X_L, d.databuf[:2])
 	if err != nil {
 		return err
@@ -100,15 +105,18 @@ func (d *DevI2C) Update() error {
 	if err != nil {
 		return err
 	}
-	x := int16(binary.LittleEndian.Uint16(d.databuf[0:]))
-	y := int16(binary.LittleEndian.Uint16(d.databuf[2:]))
-	z := int16(binary.LittleEndian.Uint16(d.databuf[4:]))
-	d.data[0] = d.mul * int32(x)
-	d.data[1] = d.mul * int32(y)
-	d.data[2] = d.mul * int32(z)
+	d.data = d.scale(d.databuf[:])
 	return nil
 }
 
+// scale converts a raw little endian sample to microradians per second.
+func (d *DevI2C) scale(raw []byte) [3]int32 {
+	x := int16(binary.LittleEndian.Uint16(raw[0:]))
+	y := int16(binary.LittleEndian.Uint16(raw[2:]))
+	z := int16(binary.LittleEndian.Uint16(raw[4:]))
+	return [3]int32{d.mul * int32(x), d.mul * int32(y), d.mul * int32(z)}
+}
+
 // Reboot sets reboot bit in CTRL_REG5 to true and unsets it.
 func (d *DevI2C) Reboot() error {
 	reg5, err := d.read8(CTRL_REG5)
@@ -129,8 +137,6 @@ func (d *DevI2C) AngularVelocity() (x, y, z int32) {
 	return d.data[0], d.data[1], d.data[2]
 }
 
-// func (d DevI2C) Update(measurement)
-
 func (d DevI2C) read8(reg uint8) (byte, error) {
 	err := legacy.ReadRegister(d.bus, d.addr, reg, d.buf[:1])
 	return d.buf[0], err
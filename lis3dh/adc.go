@@ -0,0 +1,63 @@
+package lis3dh
+
+import (
+	"errors"
+
+	"tinygo.org/x/drivers/internal/legacy"
+)
+
+var errADCChannel = errors.New("lis3dh: ADC channel must be 1, 2 or 3")
+
+// EnableADC enables the auxiliary ADC inputs. When temperature is also
+// enabled, the third ADC channel is connected to the internal temperature
+// sensor instead of the ADC3 pin. Block data update is required for reading
+// these channels and is already enabled by Configure.
+func (d *Device) EnableADC(adc, temperature bool) error {
+	var cfg byte
+	if adc {
+		cfg |= TEMPCFG_ADC_EN
+	}
+	if temperature {
+		cfg |= TEMPCFG_ADC_EN | TEMPCFG_TEMP_EN
+	}
+	return legacy.WriteRegister(d.bus, uint8(d.Address), REG_TEMPCFG, []byte{cfg})
+}
+
+// ReadRawADC returns the left-aligned raw value of the ADC channel (1-3).
+func (d *Device) ReadRawADC(channel int) (int16, error) {
+	if channel < 1 || channel > 3 {
+		return 0, errADCChannel
+	}
+	data := []byte{0, 0}
+	reg := uint8(REG_OUTADC1_L + (channel-1)*2)
+	err := legacy.ReadRegister(d.bus, uint8(d.Address), reg|0x80, data)
+	if err != nil {
+		return 0, err
+	}
+	return int16((uint16(data[1]) << 8) | uint16(data[0])), nil
+}
+
+// ReadADC returns the voltage on the ADC channel (1-3) in millivolts. The
+// input range of the ADC is 900mV to 1800mV.
+func (d *Device) ReadADC(channel int) (int32, error) {
+	raw, err := d.ReadRawADC(channel)
+	if err != nil {
+		return 0, err
+	}
+	// The full input range maps linearly from 1800mV at -32512 to 900mV at
+	// 32512.
+	return 1350 - int32(raw)*450/32512, nil
+}
+
+// ReadTemperatureChange returns the temperature in milli-degrees Celsius
+// relative to an uncalibrated, part-specific reference point. It is useful
+// for tracking temperature changes, not for absolute measurements. The
+// temperature sensor must be enabled with EnableADC first.
+func (d *Device) ReadTemperatureChange() (int32, error) {
+	raw, err := d.ReadRawADC(3)
+	if err != nil {
+		return 0, err
+	}
+	// 8 bits of resolution with 1 LSB per degree.
+	return int32(raw>>8) * 1000, nil
+}
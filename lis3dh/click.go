@@ -0,0 +1,101 @@
+package lis3dh
+
+import (
+	"tinygo.org/x/drivers/internal/legacy"
+)
+
+// ClickConfig configures the click (tap) detection engine. Time values are
+// expressed in samples at the current data rate (1 LSB = 1/ODR).
+type ClickConfig struct {
+	// Single and Double enable single and double click detection on all
+	// three axes.
+	Single bool
+	Double bool
+
+	// Threshold is the acceleration a click must exceed, 7 bits. 1 LSB is
+	// 16mg at ±2g and scales with the range.
+	Threshold uint8
+
+	// TimeLimit is the maximum time the acceleration may stay above the
+	// threshold for the event to count as a click.
+	TimeLimit uint8
+
+	// Latency is the quiet time after the first click before the second
+	// click of a double click may start.
+	Latency uint8
+
+	// Window is the maximum time after the latency in which the second
+	// click of a double click must occur.
+	Window uint8
+
+	// Interrupt routes click events to the INT1 pin.
+	Interrupt bool
+}
+
+// ConfigureClick sets up single and/or double click detection.
+func (d *Device) ConfigureClick(cfg ClickConfig) error {
+	var clickCfg byte
+	if cfg.Single {
+		clickCfg |= 0x15 // ZS, YS, XS
+	}
+	if cfg.Double {
+		clickCfg |= 0x2A // ZD, YD, XD
+	}
+	regs := []struct {
+		reg   uint8
+		value byte
+	}{
+		{REG_CLICKCFG, clickCfg},
+		// LIR_Click: latch the interrupt until REG_CLICKSRC is read.
+		{REG_CLICKTHS, cfg.Threshold&0x7F | 0x80},
+		{REG_TIMELIMIT, cfg.TimeLimit & 0x7F},
+		{REG_TIMELATEN, cfg.Latency},
+		{REG_TIMEWINDO, cfg.Window},
+	}
+	for _, r := range regs {
+		err := legacy.WriteRegister(d.bus, uint8(d.Address), r.reg, []byte{r.value})
+		if err != nil {
+			return err
+		}
+	}
+	var bits byte
+	if cfg.Interrupt && clickCfg != 0 {
+		bits = CTRL3_I1_CLICK
+	}
+	return d.updateCtrl3(CTRL3_I1_CLICK, bits)
+}
+
+// ClickSource is the content of the click source register.
+type ClickSource uint8
+
+// Active returns whether any click event has been detected.
+func (s ClickSource) Active() bool {
+	return s&CLICKSRC_IA != 0
+}
+
+// Single returns whether a single click has been detected.
+func (s ClickSource) Single() bool {
+	return s&CLICKSRC_SCLICK != 0
+}
+
+// Double returns whether a double click has been detected.
+func (s ClickSource) Double() bool {
+	return s&CLICKSRC_DCLICK != 0
+}
+
+// Negative returns whether the detected click was in the negative direction.
+func (s ClickSource) Negative() bool {
+	return s&CLICKSRC_SIGN != 0
+}
+
+// Axes returns which axes took part in the detected click.
+func (s ClickSource) Axes() (x, y, z bool) {
+	return s&CLICKSRC_X != 0, s&CLICKSRC_Y != 0, s&CLICKSRC_Z != 0
+}
+
+// ReadClick reads and clears the click source register.
+func (d *Device) ReadClick() (ClickSource, error) {
+	src := []byte{0}
+	err := legacy.ReadRegister(d.bus, uint8(d.Address), REG_CLICKSRC, src)
+	return ClickSource(src[0]), err
+}
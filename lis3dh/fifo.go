@@ -0,0 +1,105 @@
+package lis3dh
+
+import (
+	"tinygo.org/x/drivers/internal/legacy"
+)
+
+// fifoSize is the number of samples the FIFO can hold.
+const fifoSize = 32
+
+// ConfigureFIFO enables the FIFO in the given mode. The watermark (0-31) is
+// the number of samples at which the watermark flag and interrupt are raised.
+// Passing FIFO_BYPASS disables the FIFO again.
+func (d *Device) ConfigureFIFO(mode FIFOMode, watermark uint8) error {
+	ctl5 := []byte{0}
+	err := legacy.ReadRegister(d.bus, uint8(d.Address), REG_CTRL5, ctl5)
+	if err != nil {
+		return err
+	}
+	if mode == FIFO_BYPASS {
+		ctl5[0] &^= CTRL5_FIFO_EN
+	} else {
+		ctl5[0] |= CTRL5_FIFO_EN
+	}
+	err = legacy.WriteRegister(d.bus, uint8(d.Address), REG_CTRL5, ctl5)
+	if err != nil {
+		return err
+	}
+	// Switching through bypass mode resets the FIFO contents.
+	err = legacy.WriteRegister(d.bus, uint8(d.Address), REG_FIFOCTRL, []byte{0})
+	if err != nil || mode == FIFO_BYPASS {
+		return err
+	}
+	return legacy.WriteRegister(d.bus, uint8(d.Address), REG_FIFOCTRL, []byte{byte(mode)<<6 | watermark&FIFOSRC_FSS})
+}
+
+// SetFIFOInterrupts routes the FIFO watermark and overrun events to the INT1
+// pin.
+func (d *Device) SetFIFOInterrupts(watermark, overrun bool) error {
+	var bits byte
+	if watermark {
+		bits |= CTRL3_I1_WTM
+	}
+	if overrun {
+		bits |= CTRL3_I1_OVERRUN
+	}
+	return d.updateCtrl3(CTRL3_I1_WTM|CTRL3_I1_OVERRUN, bits)
+}
+
+// FIFOStatus returns the number of unread samples in the FIFO and whether the
+// watermark level has been reached or the FIFO has overrun.
+func (d *Device) FIFOStatus() (samples int, watermark, overrun bool, err error) {
+	src := []byte{0}
+	err = legacy.ReadRegister(d.bus, uint8(d.Address), REG_FIFOSRC, src)
+	if err != nil {
+		return
+	}
+	samples = int(src[0] & FIFOSRC_FSS)
+	if src[0]&FIFOSRC_OVRN != 0 {
+		// The sample count saturates at 31 while one more sample is stored.
+		samples = fifoSize
+	}
+	if src[0]&FIFOSRC_EMPTY != 0 {
+		samples = 0
+	}
+	return samples, src[0]&FIFOSRC_WTM != 0, src[0]&FIFOSRC_OVRN != 0, nil
+}
+
+// ReadFIFO drains up to len(samples) raw x, y and z readings from the FIFO in
+// a single burst and returns how many were read.
+func (d *Device) ReadFIFO(samples [][3]int16) (int, error) {
+	n, _, _, err := d.FIFOStatus()
+	if err != nil {
+		return 0, err
+	}
+	if n > len(samples) {
+		n = len(samples)
+	}
+	if n == 0 {
+		return 0, nil
+	}
+
+	var data [fifoSize * 6]byte
+	err = d.bus.Tx(d.Address, []byte{REG_OUT_X_L | 0x80}, data[:n*6])
+	if err != nil {
+		return 0, err
+	}
+	for i := 0; i < n; i++ {
+		s := data[i*6:]
+		samples[i][0] = int16((uint16(s[1]) << 8) | uint16(s[0]))
+		samples[i][1] = int16((uint16(s[3]) << 8) | uint16(s[2]))
+		samples[i][2] = int16((uint16(s[5]) << 8) | uint16(s[4]))
+	}
+	return n, nil
+}
+
+// updateCtrl3 changes the bits of REG_CTRL3 selected by mask to bits.
+func (d *Device) updateCtrl3(mask, bits byte) error {
+	ctl3 := []byte{0}
+	err := legacy.ReadRegister(d.bus, uint8(d.Address), REG_CTRL3, ctl3)
+	if err != nil {
+		return err
+	}
+	ctl3[0] = ctl3[0]&^mask | bits&mask
+	return legacy.WriteRegister(d.bus, uint8(d.Address), REG_CTRL3, ctl3)
+}
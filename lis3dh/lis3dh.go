@@ -48,6 +48,23 @@ func (d *Device) Connected() bool {
 	return data[0] == 0x33
 }
 
+// DriverName returns "lis3dh".
+func (d *Device) DriverName() string {
+	return "lis3dh"
+}
+
+// ChipID returns the content of the WHO_AM_I register, 0x33 for a LIS3DH.
+func (d *Device) ChipID() (uint32, error) {
+	data := []byte{0}
+	err := legacy.ReadRegister(d.bus, uint8(d.Address), WHO_AM_I, data)
+	return uint32(data[0]), err
+}
+
+// RevisionID returns 0, the LIS3DH has no revision register.
+func (d *Device) RevisionID() (uint32, error) {
+	return 0, nil
+}
+
 // SetDataRate sets the speed of data collected by the LIS3DH.
 func (d *Device) SetDataRate(rate DataRate) {
 	ctl1 := []byte{0}
@@ -0,0 +1,94 @@
+package lis3dh
+
+import (
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+	"tinygo.org/x/drivers/tester"
+)
+
+func newSensor(c *qt.C) (*Device, *tester.I2CDevice8) {
+	bus := tester.NewI2CBus(c)
+	fake := bus.NewDevice(Address0)
+	fake.Registers[WHO_AM_I] = 0x33
+	dev := New(bus)
+	return &dev, fake
+}
+
+func TestReadFIFO(t *testing.T) {
+	c := qt.New(t)
+	dev, fake := newSensor(c)
+
+	// Two samples, read in one burst from OUT_X_L with auto-increment.
+	fake.Registers[REG_FIFOSRC] = 2
+	copy(fake.Registers[REG_OUT_X_L|0x80:], []byte{
+		0x10, 0x00, 0xF0, 0xFF, 0x00, 0x40,
+		0x00, 0x80, 0xFF, 0x7F, 0x01, 0x00,
+	})
+	samples := make([][3]int16, 4)
+	n, err := dev.ReadFIFO(samples)
+	c.Assert(err, qt.IsNil)
+	c.Assert(n, qt.Equals, 2)
+	c.Assert(samples[:2], qt.DeepEquals, [][3]int16{{16, -16, 0x4000}, {-0x8000, 0x7FFF, 1}})
+
+	// Only as many samples as fit are read.
+	n, err = dev.ReadFIFO(samples[:1])
+	c.Assert(err, qt.IsNil)
+	c.Assert(n, qt.Equals, 1)
+
+	fake.Registers[REG_FIFOSRC] = FIFOSRC_WTM | FIFOSRC_OVRN | 31
+	n, wtm, ovrn, err := dev.FIFOStatus()
+	c.Assert(err, qt.IsNil)
+	c.Assert(n, qt.Equals, 32)
+	c.Assert(wtm, qt.IsTrue)
+	c.Assert(ovrn, qt.IsTrue)
+
+	fake.Registers[REG_FIFOSRC] = FIFOSRC_EMPTY
+	n, err = dev.ReadFIFO(samples)
+	c.Assert(err, qt.IsNil)
+	c.Assert(n, qt.Equals, 0)
+}
+
+func TestConfigureFIFO(t *testing.T) {
+	c := qt.New(t)
+	dev, fake := newSensor(c)
+	c.Assert(dev.ConfigureFIFO(FIFO_STREAM, 20), qt.IsNil)
+	c.Assert(fake.Registers[REG_CTRL5]&CTRL5_FIFO_EN, qt.Not(qt.Equals), uint8(0))
+	c.Assert(fake.Registers[REG_FIFOCTRL], qt.Equals, uint8(FIFO_STREAM)<<6|20)
+
+	c.Assert(dev.ConfigureFIFO(FIFO_BYPASS, 0), qt.IsNil)
+	c.Assert(fake.Registers[REG_CTRL5]&CTRL5_FIFO_EN, qt.Equals, uint8(0))
+	c.Assert(fake.Registers[REG_FIFOCTRL], qt.Equals, uint8(0))
+}
+
+func TestReadClick(t *testing.T) {
+	c := qt.New(t)
+	dev, fake := newSensor(c)
+	fake.Registers[REG_CLICKSRC] = CLICKSRC_IA | CLICKSRC_DCLICK | CLICKSRC_SIGN | CLICKSRC_Z
+	src, err := dev.ReadClick()
+	c.Assert(err, qt.IsNil)
+	c.Assert(src.Active(), qt.IsTrue)
+	c.Assert(src.Double(), qt.IsTrue)
+	c.Assert(src.Single(), qt.IsFalse)
+	c.Assert(src.Negative(), qt.IsTrue)
+	x, y, z := src.Axes()
+	c.Assert([]bool{x, y, z}, qt.DeepEquals, []bool{false, false, true})
+}
+
+func TestReadADC(t *testing.T) {
+	c := qt.New(t)
+	dev, fake := newSensor(c)
+	// ADC1 at the bottom of its range, ADC2 in the middle.
+	copy(fake.Registers[REG_OUTADC1_L|0x80:], []byte{0x00, 0x81, 0x00, 0x00, 0x00, 0x05})
+	mv, err := dev.ReadADC(1)
+	c.Assert(err, qt.IsNil)
+	c.Assert(mv, qt.Equals, int32(1800))
+	mv, err = dev.ReadADC(2)
+	c.Assert(err, qt.IsNil)
+	c.Assert(mv, qt.Equals, int32(1350))
+	temp, err := dev.ReadTemperatureChange()
+	c.Assert(err, qt.IsNil)
+	c.Assert(temp, qt.Equals, int32(5000))
+	_, err = dev.ReadADC(4)
+	c.Assert(err, qt.Equals, errADCChannel)
+}
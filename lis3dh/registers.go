@@ -75,3 +75,46 @@ const (
 	DATARATE_LOWPOWER_1K6HZ          = 8
 	DATARATE_LOWPOWER_5KHZ           = 9
 )
+
+// FIFOMode is the operating mode of the 32 sample FIFO.
+type FIFOMode uint8
+
+// FIFO mode constants, as stored in the FM bits of REG_FIFOCTRL.
+const (
+	FIFO_BYPASS         FIFOMode = 0 // FIFO disabled, only the current sample is kept
+	FIFO_FIFO           FIFOMode = 1 // stop collecting samples once the FIFO is full
+	FIFO_STREAM         FIFOMode = 2 // discard the oldest samples once the FIFO is full
+	FIFO_STREAM_TO_FIFO FIFOMode = 3 // stream until an interrupt, then switch to FIFO mode
+)
+
+// Bits used for the FIFO, click, ADC and interrupt features.
+const (
+	// REG_CTRL3
+	CTRL3_I1_CLICK   = 0x80
+	CTRL3_I1_IA1     = 0x40
+	CTRL3_I1_ZYXDA   = 0x10
+	CTRL3_I1_WTM     = 0x04
+	CTRL3_I1_OVERRUN = 0x02
+
+	// REG_CTRL5
+	CTRL5_FIFO_EN = 0x40
+
+	// REG_TEMPCFG
+	TEMPCFG_ADC_EN  = 0x80
+	TEMPCFG_TEMP_EN = 0x40
+
+	// REG_FIFOSRC
+	FIFOSRC_WTM   = 0x80
+	FIFOSRC_OVRN  = 0x40
+	FIFOSRC_EMPTY = 0x20
+	FIFOSRC_FSS   = 0x1F
+
+	// REG_CLICKSRC
+	CLICKSRC_IA     = 0x40
+	CLICKSRC_DCLICK = 0x20
+	CLICKSRC_SCLICK = 0x10
+	CLICKSRC_SIGN   = 0x08
+	CLICKSRC_Z      = 0x04
+	CLICKSRC_Y      = 0x02
+	CLICKSRC_X      = 0x01
+)
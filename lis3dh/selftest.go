@@ -0,0 +1,85 @@
+package lis3dh
+
+import (
+	"time"
+
+	"tinygo.org/x/drivers"
+	"tinygo.org/x/drivers/internal/legacy"
+)
+
+// selfTestSamples is the number of samples averaged for each half of the
+// self test.
+const selfTestSamples = 5
+
+// SelfTest runs the built-in self test of the accelerometer, which applies an
+// electrostatic force to the sensing element. The sensor must be kept still
+// during the test, which takes around 400ms.
+//
+// For every axis the result contains the change of the output caused by the
+// self test in mg. The datasheet accepts 17 to 360 LSb in normal mode at ±2g,
+// which is 68mg to 1440mg. The configuration is restored afterwards.
+func (d *Device) SelfTest() (drivers.SelfTestResult, error) {
+	var ctrl1, ctrl4 [1]byte
+	err := legacy.ReadRegister(d.bus, uint8(d.Address), REG_CTRL1, ctrl1[:])
+	if err != nil {
+		return nil, err
+	}
+	err = legacy.ReadRegister(d.bus, uint8(d.Address), REG_CTRL4, ctrl4[:])
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		legacy.WriteRegister(d.bus, uint8(d.Address), REG_CTRL1, ctrl1[:])
+		legacy.WriteRegister(d.bus, uint8(d.Address), REG_CTRL4, ctrl4[:])
+	}()
+
+	// 50Hz, normal mode, all axes enabled.
+	err = legacy.WriteRegister(d.bus, uint8(d.Address), REG_CTRL1, []byte{0x47})
+	if err != nil {
+		return nil, err
+	}
+	// BDU, ±2g, self test disabled and then self test 0.
+	normal, err := d.selfTestAverage(0x80)
+	if err != nil {
+		return nil, err
+	}
+	enabled, err := d.selfTestAverage(0x82)
+	if err != nil {
+		return nil, err
+	}
+
+	var result drivers.SelfTestResult
+	names := [3]string{"X", "Y", "Z"}
+	for i := range names {
+		change := enabled[i] - normal[i]
+		if change < 0 {
+			change = -change
+		}
+		// 10 bit left aligned output, 4mg per LSb.
+		result.Add(names[i], change/64*4, 68, 1440)
+	}
+	return result, nil
+}
+
+// selfTestAverage writes CTRL_REG4 and returns the average raw reading of the
+// three axes, discarding the first sample.
+func (d *Device) selfTestAverage(ctrl4 byte) (avg [3]int32, err error) {
+	err = legacy.WriteRegister(d.bus, uint8(d.Address), REG_CTRL4, []byte{ctrl4})
+	if err != nil {
+		return
+	}
+	time.Sleep(90 * time.Millisecond)
+	d.ReadRawAcceleration()
+
+	for n := 0; n < selfTestSamples; n++ {
+		time.Sleep(20 * time.Millisecond)
+		x, y, z := d.ReadRawAcceleration()
+		avg[0] += int32(x)
+		avg[1] += int32(y)
+		avg[2] += int32(z)
+	}
+	for i := range avg {
+		avg[i] /= selfTestSamples
+	}
+	return
+}
@@ -0,0 +1,51 @@
+package drivers
+
+import (
+	"image/color"
+	"sync"
+)
+
+// LockedDisplayer wraps a Displayer so that it can be shared between
+// goroutines. Every method holds a mutex while it calls the wrapped display,
+// so concurrent calls can't corrupt the display buffer or interleave SPI
+// transfers.
+type LockedDisplayer struct {
+	mu sync.Mutex
+	d  Displayer
+}
+
+// NewLockedDisplayer returns a LockedDisplayer that wraps d. The display must
+// not be used directly anymore afterwards, except from within Do.
+func NewLockedDisplayer(d Displayer) *LockedDisplayer {
+	return &LockedDisplayer{d: d}
+}
+
+// Size returns the current size of the display.
+func (l *LockedDisplayer) Size() (x, y int16) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.d.Size()
+}
+
+// SetPixel modifies the internal buffer.
+func (l *LockedDisplayer) SetPixel(x, y int16, c color.RGBA) {
+	l.mu.Lock()
+	l.d.SetPixel(x, y, c)
+	l.mu.Unlock()
+}
+
+// Display sends the buffer (if any) to the screen.
+func (l *LockedDisplayer) Display() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.d.Display()
+}
+
+// Do calls f with the wrapped display while holding the lock. Use it to draw
+// a complete frame, or to call driver specific methods, without other
+// goroutines drawing in between. f must not call methods of l.
+func (l *LockedDisplayer) Do(f func(d Displayer)) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	f(l.d)
+}
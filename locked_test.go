@@ -0,0 +1,85 @@
+package drivers
+
+import (
+	"image/color"
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"testing"
+)
+
+var _ Displayer = (*LockedDisplayer)(nil)
+
+// fakeDisplay is a display of one line of pixels, which counts the calls
+// made while another call is in progress.
+type fakeDisplay struct {
+	active   int32
+	overlaps int32
+	buf      [8]color.RGBA
+	frames   int
+}
+
+func (d *fakeDisplay) enter() {
+	if atomic.AddInt32(&d.active, 1) != 1 {
+		atomic.AddInt32(&d.overlaps, 1)
+	}
+	runtime.Gosched()
+}
+
+func (d *fakeDisplay) leave() {
+	atomic.AddInt32(&d.active, -1)
+}
+
+func (d *fakeDisplay) Size() (x, y int16) {
+	d.enter()
+	defer d.leave()
+	return int16(len(d.buf)), 1
+}
+
+func (d *fakeDisplay) SetPixel(x, y int16, c color.RGBA) {
+	d.enter()
+	defer d.leave()
+	d.buf[x] = c
+}
+
+func (d *fakeDisplay) Display() error {
+	d.enter()
+	defer d.leave()
+	d.frames++
+	return nil
+}
+
+func TestLockedDisplayer(t *testing.T) {
+	fake := &fakeDisplay{}
+	l := NewLockedDisplayer(fake)
+
+	// Each goroutine draws whole lines within Do, and single pixels, while
+	// the others display.
+	var wg sync.WaitGroup
+	for g := 0; g < 4; g++ {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+			c := color.RGBA{R: uint8(g), A: 255}
+			for i := 0; i < 50; i++ {
+				l.Do(func(d Displayer) {
+					w, _ := d.Size()
+					for x := int16(0); x < w; x++ {
+						d.SetPixel(x, 0, c)
+					}
+				})
+				l.SetPixel(int16(i%8), 0, c)
+				l.Size()
+				l.Display()
+			}
+		}(g)
+	}
+	wg.Wait()
+
+	if fake.overlaps != 0 {
+		t.Errorf("%d calls made during another call", fake.overlaps)
+	}
+	if fake.frames != 4*50 {
+		t.Errorf("%d frames displayed", fake.frames)
+	}
+}
@@ -72,6 +72,14 @@ const (
 	Bandwidth_500_0        // 500.0 kHz
 )
 
+// Bandwidths of the 2.4GHz radios, such as the SX1280.
+const (
+	Bandwidth_203_125 = Bandwidth_500_0 + 1 + iota // 203.125 kHz
+	Bandwidth_406_25                               // 406.25 kHz
+	Bandwidth_812_5                                // 812.5 kHz
+	Bandwidth_1625_0                               // 1625.0 kHz
+)
+
 const (
 	SyncPublic = iota
 	SyncPrivate
@@ -0,0 +1,52 @@
+package lora
+
+// LinkStats counts the packets exchanged with another node, to measure the
+// packet error rate of the link in each direction.
+type LinkStats struct {
+	Received uint32 // packets received
+	Lost     uint32 // packets missing from the sequence of those received
+	Sent     uint32 // packets sent, retries included
+	Acked    uint32 // packets sent and acknowledged
+
+	last  uint32
+	start bool
+}
+
+// Receive records a packet received with the given sequence number, which
+// the sender increments for every packet. Packets with a lower number than
+// the last one, as after the sender restarted, start a new sequence.
+func (s *LinkStats) Receive(seq uint32) {
+	if s.start && seq > s.last {
+		s.Lost += seq - s.last - 1
+	}
+	s.Received++
+	s.last, s.start = seq, true
+}
+
+// Transmit records a packet sent attempts times, and whether it was
+// acknowledged in the end.
+func (s *LinkStats) Transmit(attempts int, acked bool) {
+	s.Sent += uint32(attempts)
+	if acked {
+		s.Acked++
+	}
+}
+
+// RxErrorRate returns the share of packets from the other node that were
+// lost, from 0 to 1.
+func (s *LinkStats) RxErrorRate() float32 {
+	if s.Received+s.Lost == 0 {
+		return 0
+	}
+	return float32(s.Lost) / float32(s.Received+s.Lost)
+}
+
+// TxErrorRate returns the share of packets sent that were not
+// acknowledged, because they or their acknowledgement were lost, from 0 to
+// 1.
+func (s *LinkStats) TxErrorRate() float32 {
+	if s.Sent == 0 {
+		return 0
+	}
+	return float32(s.Sent-s.Acked) / float32(s.Sent)
+}
@@ -0,0 +1,109 @@
+// Package meshtastic sends and receives packets compatible with the
+// Meshtastic firmware, so that TinyGo nodes can exchange text messages and
+// positions with a Meshtastic mesh.
+//
+// LoraConfig gives the radio settings of a Meshtastic modem preset, in a
+// region, on a channel. A Channel encodes and decodes the packets of the
+// channel: a 16 byte header in clear text, followed by a Data protobuf
+// encrypted with the AES key of the channel.
+//
+// Nodes do not relay packets, nor acknowledge them: they are only leaves of
+// the mesh.
+package meshtastic // import "tinygo.org/x/drivers/lora/meshtastic"
+
+import (
+	"tinygo.org/x/drivers/lora"
+)
+
+// Broadcast is the destination of the packets sent to all the nodes.
+const Broadcast = 0xFFFFFFFF
+
+// LoRa sync word of Meshtastic networks, and the value of the sync word
+// register of the SX126x for it. LoraConfig cannot set them, as the radio
+// drivers only take public and private sync words there: set the sync word
+// with SetSyncWord after LoraConfig.
+const (
+	SyncWord       = 0x2B
+	SyncWordSX126x = 0x24B4
+)
+
+// PreambleLength is the preamble length of Meshtastic packets, in symbols.
+const PreambleLength = 16
+
+// Preset is a modem preset of Meshtastic. All the nodes of a mesh use the
+// same one.
+type Preset struct {
+	Name            string // default channel name
+	Bandwidth       uint8  // lora.Bandwidth_*
+	SpreadingFactor uint8
+	CodingRate      uint8
+	bw              uint32 // Hz
+}
+
+// Modem presets. LongFast is the default of the Meshtastic firmware.
+var (
+	ShortFast    = Preset{"ShortFast", lora.Bandwidth_250_0, lora.SpreadingFactor7, lora.CodingRate4_5, 250000}
+	ShortSlow    = Preset{"ShortSlow", lora.Bandwidth_250_0, lora.SpreadingFactor8, lora.CodingRate4_5, 250000}
+	MediumFast   = Preset{"MediumFast", lora.Bandwidth_250_0, lora.SpreadingFactor9, lora.CodingRate4_5, 250000}
+	MediumSlow   = Preset{"MediumSlow", lora.Bandwidth_250_0, lora.SpreadingFactor10, lora.CodingRate4_5, 250000}
+	LongFast     = Preset{"LongFast", lora.Bandwidth_250_0, lora.SpreadingFactor11, lora.CodingRate4_5, 250000}
+	LongModerate = Preset{"LongMod", lora.Bandwidth_125_0, lora.SpreadingFactor11, lora.CodingRate4_8, 125000}
+	LongSlow     = Preset{"LongSlow", lora.Bandwidth_125_0, lora.SpreadingFactor12, lora.CodingRate4_8, 125000}
+)
+
+// Region is a frequency band of Meshtastic, in Hz.
+type Region struct {
+	Start, End uint32
+}
+
+// Regions.
+var (
+	RegionUS    = Region{902000000, 928000000}
+	RegionEU433 = Region{433000000, 434000000}
+	RegionEU868 = Region{869400000, 869650000}
+	RegionCN    = Region{470000000, 510000000}
+	RegionANZ   = Region{915000000, 928000000}
+	RegionKR    = Region{920000000, 923000000}
+	RegionIN    = Region{865000000, 867000000}
+)
+
+// Frequency returns the frequency in Hz of a channel of the region: the
+// band is divided in slots of the bandwidth of the preset, and the name of
+// the channel picks one of them. An empty name is the name of the preset.
+func (r Region) Frequency(p Preset, channel string) uint32 {
+	if channel == "" {
+		channel = p.Name
+	}
+	slots := (r.End - r.Start) / p.bw
+	if slots == 0 {
+		slots = 1
+	}
+	// djb2
+	h := uint32(5381)
+	for i := 0; i < len(channel); i++ {
+		h = h*33 + uint32(channel[i])
+	}
+	return r.Start + p.bw/2 + h%slots*p.bw
+}
+
+// LoraConfig returns the radio configuration of a channel of the region,
+// with a preset. See SyncWord.
+func LoraConfig(r Region, p Preset, channel string, txPowerDBm int8) lora.Config {
+	ldr := uint8(lora.LowDataRateOptimizeOff)
+	// Symbols longer than 16ms.
+	if uint32(1)<<p.SpreadingFactor*1000/(p.bw/1000) > 16000 {
+		ldr = lora.LowDataRateOptimizeOn
+	}
+	return lora.Config{
+		Freq:           r.Frequency(p, channel),
+		Cr:             p.CodingRate,
+		Sf:             p.SpreadingFactor,
+		Bw:             p.Bandwidth,
+		Ldr:            ldr,
+		Preamble:       PreambleLength,
+		HeaderType:     lora.HeaderExplicit,
+		Crc:            lora.CRCOn,
+		Iq:             lora.IQStandard,
+		LoraTxPowerDBm: txPowerDBm,
+	}
+}
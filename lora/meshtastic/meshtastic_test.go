@@ -0,0 +1,89 @@
+package meshtastic
+
+import (
+	"bytes"
+	"testing"
+
+	"tinygo.org/x/drivers/lora"
+)
+
+func TestLoraConfig(t *testing.T) {
+	cfg := LoraConfig(RegionUS, LongFast, "", 20)
+	if cfg.Freq != 906875000 || cfg.Sf != lora.SpreadingFactor11 || cfg.Bw != lora.Bandwidth_250_0 {
+		t.Errorf("LongFast in the US: %+v", cfg)
+	}
+	if cfg.Ldr != lora.LowDataRateOptimizeOff || cfg.Preamble != 16 {
+		t.Errorf("LongFast: ldr %d, preamble %d", cfg.Ldr, cfg.Preamble)
+	}
+	if cfg := LoraConfig(RegionEU868, LongSlow, "", 14); cfg.Freq != 869462500 || cfg.Ldr != lora.LowDataRateOptimizeOn {
+		t.Errorf("LongSlow in Europe: %+v", cfg)
+	}
+}
+
+func TestChannelHash(t *testing.T) {
+	c, err := NewChannel(LongFast.Name, DefaultKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.Hash() != 8 {
+		t.Errorf("hash of LongFast: %d", c.Hash())
+	}
+}
+
+func TestData(t *testing.T) {
+	d := Text("hi")
+	d.ReplyID = 0x01020304
+	got := d.appendProto(nil)
+	want := []byte{0x08, 0x01, 0x12, 0x02, 'h', 'i', 0x3D, 0x04, 0x03, 0x02, 0x01}
+	if !bytes.Equal(got, want) {
+		t.Errorf("encoded %X, want %X", got, want)
+	}
+	var back Data
+	if err := back.parseProto(got); err != nil || back.PortNum != PortText || string(back.Payload) != "hi" || back.ReplyID != d.ReplyID {
+		t.Errorf("decoded %+v, %v", back, err)
+	}
+	if err := back.parseProto(got[:4]); err != errProto {
+		t.Errorf("truncated: %v", err)
+	}
+}
+
+func TestPosition(t *testing.T) {
+	p := Position{Latitude: 525200000, Longitude: -134050000, Altitude: -12, Time: 1700000000}
+	d := p.Data()
+	if d.PortNum != PortPosition {
+		t.Errorf("port %d", d.PortNum)
+	}
+	got, err := DecodePosition(d.Payload)
+	if err != nil || got != p {
+		t.Errorf("decoded %+v, %v", got, err)
+	}
+}
+
+func TestPacket(t *testing.T) {
+	for _, key := range [][]byte{nil, DefaultKey} {
+		c, _ := NewChannel("LongFast", key)
+		h := Header{To: Broadcast, From: 0x12345678, ID: 0xCAFE, HopLimit: 3, HopStart: 3, WantAck: true}
+		pkt, err := c.Encode(nil, h, Text("hello mesh"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if pkt[12] != 0x6B || pkt[13] != c.Hash() {
+			t.Errorf("flags %02X, channel %02X", pkt[12], pkt[13])
+		}
+		if encrypted := !bytes.Contains(pkt, []byte("hello mesh")); encrypted != (key != nil) {
+			t.Errorf("key %v: encrypted %v", key != nil, encrypted)
+		}
+		gotH, d, err := c.Decode(pkt)
+		h.Channel = c.Hash()
+		if err != nil || gotH != h || string(d.Payload) != "hello mesh" {
+			t.Errorf("key %v: decoded %+v, %+v, %v", key != nil, gotH, d, err)
+		}
+	}
+
+	c, _ := NewChannel("LongFast", DefaultKey)
+	other, _ := NewChannel("Private", DefaultKey)
+	pkt, _ := other.Encode(nil, Header{To: Broadcast}, Text("hello"))
+	if _, _, err := c.Decode(pkt); err != errOtherChannel {
+		t.Errorf("packet of another channel: %v", err)
+	}
+}
@@ -0,0 +1,139 @@
+package meshtastic
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"encoding/binary"
+	"errors"
+)
+
+var (
+	errTooShort     = errors.New("meshtastic: packet too short")
+	errOtherChannel = errors.New("meshtastic: packet of another channel")
+	errTooLong      = errors.New("meshtastic: packet too long")
+)
+
+// HeaderSize is the size of the header of a packet.
+const HeaderSize = 16
+
+// MaxPacketSize is the size of the largest packet.
+const MaxPacketSize = 255
+
+// DefaultKey is the key of the default channels, shared by all the
+// Meshtastic nodes: their packets are encrypted, but not private.
+var DefaultKey = []byte{
+	0xd4, 0xf1, 0xbb, 0x3a, 0x20, 0x29, 0x07, 0x59,
+	0xf0, 0xbc, 0xff, 0xab, 0xcf, 0x4e, 0x69, 0x01,
+}
+
+// Header is the header of a packet, sent in clear text.
+type Header struct {
+	To       uint32 // node number, or Broadcast
+	From     uint32 // node number
+	ID       uint32 // random, unique for the sender
+	HopLimit uint8  // hops left, 3 by default
+	HopStart uint8  // hop limit of the sender
+	WantAck  bool
+	ViaMQTT  bool
+	Channel  uint8 // hash of the channel, set by Encode
+	NextHop  uint8
+	Relay    uint8
+}
+
+// Channel is a Meshtastic channel: a name and an AES key.
+type Channel struct {
+	block cipher.Block
+	hash  uint8
+}
+
+// NewChannel returns a channel. The name of the default channel is the name
+// of the preset, and its key DefaultKey; a nil key disables encryption.
+func NewChannel(name string, key []byte) (*Channel, error) {
+	c := &Channel{}
+	if key != nil {
+		block, err := aes.NewCipher(key)
+		if err != nil {
+			return nil, err
+		}
+		c.block = block
+	}
+	// The XOR of the bytes of the name and of the key.
+	for i := 0; i < len(name); i++ {
+		c.hash ^= name[i]
+	}
+	for _, b := range key {
+		c.hash ^= b
+	}
+	return c, nil
+}
+
+// Hash returns the hash of the channel, in the header of its packets.
+func (c *Channel) Hash() uint8 {
+	return c.hash
+}
+
+// Encode appends a packet to dst.
+func (c *Channel) Encode(dst []byte, h Header, d *Data) ([]byte, error) {
+	flags := h.HopLimit&0x07 | h.HopStart&0x07<<5
+	if h.WantAck {
+		flags |= 0x08
+	}
+	if h.ViaMQTT {
+		flags |= 0x10
+	}
+	start := len(dst)
+	dst = appendUint32(dst, h.To)
+	dst = appendUint32(dst, h.From)
+	dst = appendUint32(dst, h.ID)
+	dst = append(dst, flags, c.hash, h.NextHop, h.Relay)
+	n := len(dst)
+	dst = d.appendProto(dst)
+	if len(dst)-start > MaxPacketSize {
+		return dst[:start], errTooLong
+	}
+	c.crypt(h.ID, h.From, dst[n:])
+	return dst, nil
+}
+
+// Decode decodes a packet of the channel, decrypting it in place. The
+// payload of the data points into pkt.
+func (c *Channel) Decode(pkt []byte) (Header, Data, error) {
+	if len(pkt) < HeaderSize {
+		return Header{}, Data{}, errTooShort
+	}
+	h := Header{
+		To:       binary.LittleEndian.Uint32(pkt[0:]),
+		From:     binary.LittleEndian.Uint32(pkt[4:]),
+		ID:       binary.LittleEndian.Uint32(pkt[8:]),
+		HopLimit: pkt[12] & 0x07,
+		WantAck:  pkt[12]&0x08 != 0,
+		ViaMQTT:  pkt[12]&0x10 != 0,
+		HopStart: pkt[12] >> 5,
+		Channel:  pkt[13],
+		NextHop:  pkt[14],
+		Relay:    pkt[15],
+	}
+	if h.Channel != c.hash {
+		return h, Data{}, errOtherChannel
+	}
+	c.crypt(h.ID, h.From, pkt[HeaderSize:])
+	var d Data
+	err := d.parseProto(pkt[HeaderSize:])
+	return h, d, err
+}
+
+// crypt encrypts or decrypts a payload with AES-CTR, with a nonce made of
+// the packet ID and the sender.
+func (c *Channel) crypt(id, from uint32, data []byte) {
+	if c.block == nil {
+		return
+	}
+	var iv [aes.BlockSize]byte
+	binary.LittleEndian.PutUint32(iv[0:], id)
+	binary.LittleEndian.PutUint32(iv[8:], from)
+	cipher.NewCTR(c.block, iv[:]).XORKeyStream(data, data)
+}
+
+func appendUint32(dst []byte, v uint32) []byte {
+	return append(dst, byte(v), byte(v>>8), byte(v>>16), byte(v>>24))
+}
@@ -0,0 +1,199 @@
+package meshtastic
+
+import (
+	"encoding/binary"
+	"errors"
+)
+
+var errProto = errors.New("meshtastic: invalid protobuf")
+
+// PortNum is the application of a payload.
+type PortNum uint32
+
+// Applications.
+const (
+	PortUnknown   PortNum = 0
+	PortText      PortNum = 1  // UTF-8 text message
+	PortRemoteHW  PortNum = 2  // remote GPIO
+	PortPosition  PortNum = 3  // Position protobuf
+	PortNodeInfo  PortNum = 4  // User protobuf
+	PortRouting   PortNum = 5  // Routing protobuf, acknowledgements
+	PortAdmin     PortNum = 6  // AdminMessage protobuf
+	PortWaypoint  PortNum = 8  // Waypoint protobuf
+	PortTelemetry PortNum = 67 // Telemetry protobuf
+	PortPrivate   PortNum = 256
+)
+
+// Data is the payload of a packet, the Data protobuf of Meshtastic.
+type Data struct {
+	PortNum      PortNum
+	Payload      []byte
+	WantResponse bool
+	Dest         uint32
+	Source       uint32
+	RequestID    uint32
+	ReplyID      uint32
+	Emoji        uint32
+}
+
+// Text returns the data of a text message.
+func Text(s string) *Data {
+	return &Data{PortNum: PortText, Payload: []byte(s)}
+}
+
+// Protobuf wire types.
+const (
+	wireVarint  = 0
+	wireFixed64 = 1
+	wireBytes   = 2
+	wireFixed32 = 5
+)
+
+func (d *Data) appendProto(dst []byte) []byte {
+	if d.PortNum != 0 {
+		dst = appendVarint(dst, 1<<3|wireVarint, uint64(d.PortNum))
+	}
+	if len(d.Payload) != 0 {
+		dst = appendVarint(dst, 2<<3|wireBytes, uint64(len(d.Payload)))
+		dst = append(dst, d.Payload...)
+	}
+	if d.WantResponse {
+		dst = appendVarint(dst, 3<<3|wireVarint, 1)
+	}
+	for i, v := range [...]uint32{d.Dest, d.Source, d.RequestID, d.ReplyID, d.Emoji} {
+		if v != 0 {
+			dst = append(dst, byte(4+i)<<3|wireFixed32)
+			dst = appendUint32(dst, v)
+		}
+	}
+	return dst
+}
+
+func (d *Data) parseProto(b []byte) error {
+	return parseProto(b, func(field uint64, v uint64, bytes []byte) {
+		switch field {
+		case 1:
+			d.PortNum = PortNum(v)
+		case 2:
+			d.Payload = bytes
+		case 3:
+			d.WantResponse = v != 0
+		case 4:
+			d.Dest = uint32(v)
+		case 5:
+			d.Source = uint32(v)
+		case 6:
+			d.RequestID = uint32(v)
+		case 7:
+			d.ReplyID = uint32(v)
+		case 8:
+			d.Emoji = uint32(v)
+		}
+	})
+}
+
+// Position is a position, the Position protobuf of Meshtastic.
+type Position struct {
+	Latitude  int32 // in 1e-7 degrees
+	Longitude int32 // in 1e-7 degrees
+	Altitude  int32 // in meters above the sea level
+	Time      uint32
+}
+
+// Data returns the data of a position message.
+func (p *Position) Data() *Data {
+	var b []byte
+	b = append(b, 1<<3|wireFixed32)
+	b = appendUint32(b, uint32(p.Latitude))
+	b = append(b, 2<<3|wireFixed32)
+	b = appendUint32(b, uint32(p.Longitude))
+	// Negative int32 are sign extended to 64 bits.
+	b = appendVarint(b, 3<<3|wireVarint, uint64(int64(p.Altitude)))
+	if p.Time != 0 {
+		b = append(b, 4<<3|wireFixed32)
+		b = appendUint32(b, p.Time)
+	}
+	return &Data{PortNum: PortPosition, Payload: b}
+}
+
+// DecodePosition decodes the payload of a position message.
+func DecodePosition(b []byte) (Position, error) {
+	var p Position
+	err := parseProto(b, func(field uint64, v uint64, bytes []byte) {
+		switch field {
+		case 1:
+			p.Latitude = int32(v)
+		case 2:
+			p.Longitude = int32(v)
+		case 3:
+			p.Altitude = int32(v)
+		case 4:
+			p.Time = uint32(v)
+		}
+	})
+	return p, err
+}
+
+// appendVarint appends a field key and a varint.
+func appendVarint(dst []byte, key byte, v uint64) []byte {
+	dst = append(dst, key)
+	for v >= 0x80 {
+		dst = append(dst, byte(v)|0x80)
+		v >>= 7
+	}
+	return append(dst, byte(v))
+}
+
+func varint(b []byte) (uint64, int) {
+	var v uint64
+	for i := 0; i < len(b) && i < 10; i++ {
+		v |= uint64(b[i]&0x7F) << (7 * i)
+		if b[i] < 0x80 {
+			return v, i + 1
+		}
+	}
+	return 0, 0
+}
+
+// parseProto calls fn with the number and the value of each field of a
+// protobuf message: v holds numbers, and bytes the content of the other
+// fields.
+func parseProto(b []byte, fn func(field uint64, v uint64, bytes []byte)) error {
+	for len(b) > 0 {
+		key, n := varint(b)
+		if n == 0 {
+			return errProto
+		}
+		b = b[n:]
+		var v uint64
+		var bytes []byte
+		switch key & 7 {
+		case wireVarint:
+			v, n = varint(b)
+			if n == 0 {
+				return errProto
+			}
+		case wireFixed64:
+			if len(b) < 8 {
+				return errProto
+			}
+			v, n = binary.LittleEndian.Uint64(b), 8
+		case wireBytes:
+			l, m := varint(b)
+			if m == 0 || uint64(len(b)-m) < l {
+				return errProto
+			}
+			bytes, n = b[m:m+int(l)], m+int(l)
+		case wireFixed32:
+			if len(b) < 4 {
+				return errProto
+			}
+			v, n = uint64(binary.LittleEndian.Uint32(b)), 4
+		default:
+			return errProto
+		}
+		fn(key>>3, v, bytes)
+		b = b[n:]
+	}
+	return nil
+}
@@ -0,0 +1,116 @@
+package p2p
+
+import (
+	"crypto/cipher"
+	"crypto/subtle"
+)
+
+// AES-CCM (RFC 3610) with a 13 byte nonce, a 2 byte length and an 8 byte
+// tag, as in IEEE 802.15.4.
+const (
+	ccmNonceSize = 13
+	ccmTagSize   = 8
+)
+
+// ccmSeal appends the encryption of plaintext and its tag, over plaintext
+// and adata, to dst.
+func ccmSeal(b cipher.Block, nonce []byte, dst, plaintext, adata []byte) []byte {
+	tag := ccmMAC(b, nonce, plaintext, adata)
+	n := len(dst)
+	dst = append(dst, plaintext...)
+	ccmCTR(b, nonce, dst[n:])
+	var s0 [16]byte
+	ccmCounter(b, nonce, 0, &s0)
+	for i := 0; i < ccmTagSize; i++ {
+		dst = append(dst, tag[i]^s0[i])
+	}
+	return dst
+}
+
+// ccmOpen decrypts ciphertext, followed by its tag, in place and returns the
+// plaintext, or false when the tag does not match.
+func ccmOpen(b cipher.Block, nonce []byte, ciphertext, adata []byte) ([]byte, bool) {
+	if len(ciphertext) < ccmTagSize {
+		return nil, false
+	}
+	n := len(ciphertext) - ccmTagSize
+	plaintext := ciphertext[:n]
+	var want [ccmTagSize]byte
+	copy(want[:], ciphertext[n:])
+	ccmCTR(b, nonce, plaintext)
+	tag := ccmMAC(b, nonce, plaintext, adata)
+	var s0 [16]byte
+	ccmCounter(b, nonce, 0, &s0)
+	for i := range want {
+		want[i] ^= s0[i]
+	}
+	if subtle.ConstantTimeCompare(tag[:ccmTagSize], want[:]) != 1 {
+		// Do not leave unauthenticated data around.
+		ccmCTR(b, nonce, plaintext)
+		return nil, false
+	}
+	return plaintext, true
+}
+
+// ccmMAC returns the CBC-MAC of the message and of the additional data.
+func ccmMAC(b cipher.Block, nonce []byte, msg, adata []byte) [16]byte {
+	var x, blk [16]byte
+	blk[0] = (ccmTagSize-2)/2<<3 | 1
+	if len(adata) > 0 {
+		blk[0] |= 0x40
+	}
+	copy(blk[1:], nonce)
+	blk[14], blk[15] = byte(len(msg)>>8), byte(len(msg))
+	b.Encrypt(x[:], blk[:])
+
+	if len(adata) > 0 {
+		// The length of the data, then the data, padded with zeros.
+		blk = [16]byte{byte(len(adata) >> 8), byte(len(adata))}
+		n := copy(blk[2:], adata)
+		ccmMACBlock(b, &x, &blk)
+		for adata = adata[n:]; len(adata) > 0; adata = adata[n:] {
+			blk = [16]byte{}
+			n = copy(blk[:], adata)
+			ccmMACBlock(b, &x, &blk)
+		}
+	}
+	for len(msg) > 0 {
+		blk = [16]byte{}
+		n := copy(blk[:], msg)
+		ccmMACBlock(b, &x, &blk)
+		msg = msg[n:]
+	}
+	return x
+}
+
+func ccmMACBlock(b cipher.Block, x, blk *[16]byte) {
+	for i := range x {
+		x[i] ^= blk[i]
+	}
+	b.Encrypt(x[:], x[:])
+}
+
+// ccmCTR encrypts or decrypts data in place with the counter blocks from 1.
+func ccmCTR(b cipher.Block, nonce []byte, data []byte) {
+	var s [16]byte
+	for i := 0; len(data) > 0; i++ {
+		ccmCounter(b, nonce, uint16(i+1), &s)
+		n := len(data)
+		if n > 16 {
+			n = 16
+		}
+		for j := 0; j < n; j++ {
+			data[j] ^= s[j]
+		}
+		data = data[n:]
+	}
+}
+
+// ccmCounter sets s to the encryption of the counter block i.
+func ccmCounter(b cipher.Block, nonce []byte, i uint16, s *[16]byte) {
+	var a [16]byte
+	a[0] = 1 // the size of the counter, minus 1
+	copy(a[1:], nonce)
+	a[14], a[15] = byte(i>>8), byte(i)
+	b.Encrypt(s[:], a[:])
+}
@@ -0,0 +1,388 @@
+// Package p2p is a small reliability layer over the LoRa radios, for
+// private networks of nodes that talk to each other without LoRaWAN.
+//
+// Every node has an address. Messages sent to a node are acknowledged, and
+// sent again with an exponential backoff until they are; the receiver drops
+// the copies it already got. Messages can also be broadcast, without
+// acknowledgement. With a key shared by the network, messages and
+// acknowledgements are encrypted and authenticated with AES-CCM.
+//
+// The node counts the frames exchanged with the last nodes it talked to,
+// for the packet error rate of each link: see LinkStats.
+//
+// A frame is made of an 8 byte header (flags, network, destination,
+// source, and a frame counter), the payload, and with a key an 8 byte tag.
+package p2p // import "tinygo.org/x/drivers/lora/p2p"
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"encoding/binary"
+	"errors"
+	"time"
+
+	"tinygo.org/x/drivers/lora"
+)
+
+// Broadcast is the destination of the messages sent to all the nodes.
+const Broadcast = 0xFF
+
+// MaxPayload is the longest payload of a message, 8 bytes less with a key.
+const MaxPayload = 255 - headerSize
+
+var (
+	// ErrNoAck is returned by Send when the destination did not
+	// acknowledge the message after all the retries.
+	ErrNoAck = errors.New("p2p: no acknowledgement")
+
+	// ErrTimeout is returned by Receive when no message arrived in time.
+	ErrTimeout = errors.New("p2p: receive timeout")
+
+	errTooLong = errors.New("p2p: payload too long")
+	errAddress = errors.New("p2p: invalid node address")
+)
+
+// Frame flags.
+const (
+	flagAck       = 0x01 // acknowledgement
+	flagWantAck   = 0x02 // acknowledgement requested
+	flagEncrypted = 0x04
+)
+
+const (
+	headerSize = 8
+	queueSize  = 4 // messages received while waiting for an acknowledgement
+	peerSize   = 8 // nodes whose last frame and link stats are remembered
+)
+
+// Config is the configuration of a node.
+type Config struct {
+	// Address is the address of the node, from 0 to 254.
+	Address uint8
+
+	// Network tells apart networks sharing the radio channel. Frames of
+	// other networks are ignored.
+	Network uint8
+
+	// Key is the AES key (16, 24 or 32 bytes) shared by the nodes of the
+	// network. Frames are sent in clear text without it.
+	Key []byte
+
+	// FrameCounter is the counter of the first frame sent. With a key, a
+	// counter must never be used twice: save FrameCounter before the node
+	// is powered off, or start from a random value.
+	FrameCounter uint32
+
+	// Retries is the number of times a message is sent again when it is
+	// not acknowledged, 3 when 0.
+	Retries int
+
+	// AckTimeout is how long the first acknowledgement is waited for,
+	// doubled on every retry. 1s when 0, which suits the default
+	// modulation; use more for high spreading factors.
+	AckTimeout time.Duration
+
+	// TxTimeout is the timeout of a transmission in ms, 2000 when 0.
+	TxTimeout uint32
+}
+
+// Message is a message received.
+type Message struct {
+	From      uint8
+	To        uint8 // the address of the node, or Broadcast
+	Counter   uint32
+	Payload   []byte
+	Encrypted bool
+}
+
+// Decode decodes the payload, encoded by SendValue, into the fixed-size
+// value pointed to by v.
+func (m *Message) Decode(v interface{}) error {
+	return binary.Read(bytes.NewReader(m.Payload), binary.LittleEndian, v)
+}
+
+// Node is a node of the network.
+type Node struct {
+	radio   lora.Radio
+	cfg     Config
+	block   cipher.Block
+	counter uint32
+	buf     [255]byte
+	ack     [headerSize + ccmTagSize]byte
+	queue   [queueSize]Message
+	queued  int
+	peers   [peerSize]peer
+	next    uint8 // in peers
+}
+
+// peer is a node the node exchanged messages with.
+type peer struct {
+	addr     uint8
+	counter  uint32 // of the last frame received
+	received bool
+	used     bool
+	stats    lora.LinkStats
+}
+
+// New returns a node on a configured radio.
+func New(radio lora.Radio, cfg Config) (*Node, error) {
+	if cfg.Address == Broadcast {
+		return nil, errAddress
+	}
+	if cfg.Retries == 0 {
+		cfg.Retries = 3
+	}
+	if cfg.AckTimeout == 0 {
+		cfg.AckTimeout = time.Second
+	}
+	if cfg.TxTimeout == 0 {
+		cfg.TxTimeout = 2000
+	}
+	n := &Node{radio: radio, cfg: cfg, counter: cfg.FrameCounter}
+	if cfg.Key != nil {
+		block, err := aes.NewCipher(cfg.Key)
+		if err != nil {
+			return nil, err
+		}
+		n.block = block
+	}
+	return n, nil
+}
+
+// FrameCounter returns the counter of the next frame sent.
+func (n *Node) FrameCounter() uint32 {
+	return n.counter
+}
+
+// Send sends a message to a node, and waits for its acknowledgement. It
+// returns ErrNoAck when the node does not acknowledge it. Messages sent to
+// Broadcast are sent once, and not acknowledged.
+func (n *Node) Send(to uint8, payload []byte) error {
+	flags := byte(flagWantAck)
+	if to == Broadcast {
+		flags = 0
+	}
+	frame, err := n.frame(n.buf[:0], flags, to, n.counter, payload)
+	if err != nil {
+		return err
+	}
+	counter := n.counter
+	n.counter++
+
+	if to == Broadcast {
+		return n.radio.Tx(frame, n.cfg.TxTimeout)
+	}
+	wait := n.cfg.AckTimeout
+	for attempt := 1; attempt <= n.cfg.Retries+1; attempt++ {
+		if err := n.radio.Tx(frame, n.cfg.TxTimeout); err != nil {
+			return err
+		}
+		// A random part keeps two nodes from colliding on every retry.
+		acked, err := n.listen(wait+n.jitter(wait), true, to, counter)
+		if err != nil {
+			return err
+		}
+		if acked {
+			n.peer(to).stats.Transmit(attempt, true)
+			return nil
+		}
+		wait *= 2
+	}
+	n.peer(to).stats.Transmit(n.cfg.Retries+1, false)
+	return ErrNoAck
+}
+
+// SendValue sends a fixed-size value, such as a struct of sensor readings,
+// encoded with encoding/binary in little endian. See Message.Decode.
+func (n *Node) SendValue(to uint8, v interface{}) error {
+	var buf bytes.Buffer
+	if err := binary.Write(&buf, binary.LittleEndian, v); err != nil {
+		return err
+	}
+	return n.Send(to, buf.Bytes())
+}
+
+// LinkStats returns the counts of the frames exchanged with a node, and
+// false when the node is not one of the last ones it exchanged messages
+// with. Frames lost are found from the gaps in the frame counters of the
+// messages received; messages sent and not acknowledged were lost, or their
+// acknowledgement was.
+func (n *Node) LinkStats(addr uint8) (lora.LinkStats, bool) {
+	for _, p := range n.peers {
+		if p.used && p.addr == addr {
+			return p.stats, true
+		}
+	}
+	return lora.LinkStats{}, false
+}
+
+// Receive waits for a message to the node, or broadcast, and returns it.
+// The messages that need it are acknowledged. It returns ErrTimeout when
+// none arrived in time.
+func (n *Node) Receive(timeout time.Duration) (Message, error) {
+	if n.queued == 0 {
+		if _, err := n.listen(timeout, false, 0, 0); err != nil {
+			return Message{}, err
+		}
+		if n.queued == 0 {
+			return Message{}, ErrTimeout
+		}
+	}
+	m := n.queue[0]
+	copy(n.queue[:], n.queue[1:n.queued])
+	n.queued--
+	return m, nil
+}
+
+// listen receives frames for the given time. With waitAck, it returns
+// early with true when the acknowledgement of the frame counter sent to
+// node from arrives; without, when a message was queued.
+func (n *Node) listen(d time.Duration, waitAck bool, from uint8, counter uint32) (bool, error) {
+	deadline := time.Now().Add(d)
+	for {
+		left := time.Until(deadline)
+		if left <= 0 {
+			return false, nil
+		}
+		ms := uint32((left + time.Millisecond - 1) / time.Millisecond)
+		pkt, err := n.radio.Rx(ms)
+		if err != nil {
+			return false, err
+		}
+		if pkt == nil {
+			continue
+		}
+		m, ok := n.open(pkt)
+		if !ok {
+			continue
+		}
+		if pkt[0]&flagAck != 0 {
+			if waitAck && m.From == from && m.Counter == counter {
+				return true, nil
+			}
+			continue
+		}
+		dup := n.seenFrame(m.From, m.Counter)
+		if !dup && n.queued == queueSize {
+			// Not acknowledged, so that it is sent again.
+			continue
+		}
+		if pkt[0]&flagWantAck != 0 && m.To == n.cfg.Address {
+			// Duplicates are acknowledged again, as the first
+			// acknowledgement was lost.
+			if err := n.sendAck(m.From, m.Counter); err != nil {
+				return false, err
+			}
+		}
+		if dup {
+			continue
+		}
+		p := n.peer(m.From)
+		p.counter, p.received = m.Counter, true
+		p.stats.Receive(m.Counter)
+		// The radio may reuse its buffer.
+		m.Payload = append([]byte(nil), m.Payload...)
+		n.queue[n.queued] = m
+		n.queued++
+		if !waitAck {
+			return false, nil
+		}
+	}
+}
+
+// sendAck acknowledges a frame.
+func (n *Node) sendAck(to uint8, counter uint32) error {
+	frame, err := n.frame(n.ack[:0], flagAck, to, counter, nil)
+	if err != nil {
+		return err
+	}
+	return n.radio.Tx(frame, n.cfg.TxTimeout)
+}
+
+// seenFrame returns whether the frame is the last one received from src.
+func (n *Node) seenFrame(src uint8, counter uint32) bool {
+	for _, p := range n.peers {
+		if p.used && p.addr == src {
+			return p.received && p.counter == counter
+		}
+	}
+	return false
+}
+
+// peer returns the entry of a node, replacing the oldest one when it has
+// none.
+func (n *Node) peer(addr uint8) *peer {
+	for i := range n.peers {
+		if p := &n.peers[i]; p.used && p.addr == addr {
+			return p
+		}
+	}
+	p := &n.peers[n.next]
+	*p = peer{addr: addr, used: true}
+	n.next = (n.next + 1) % peerSize
+	return p
+}
+
+// frame appends a frame to dst.
+func (n *Node) frame(dst []byte, flags, to uint8, counter uint32, payload []byte) ([]byte, error) {
+	max := MaxPayload
+	if n.block != nil {
+		flags |= flagEncrypted
+		max -= ccmTagSize
+	}
+	if len(payload) > max {
+		return nil, errTooLong
+	}
+	dst = append(dst, flags, n.cfg.Network, to, n.cfg.Address,
+		byte(counter), byte(counter>>8), byte(counter>>16), byte(counter>>24))
+	if n.block == nil {
+		return append(dst, payload...), nil
+	}
+	hdr := dst[len(dst)-headerSize:]
+	var nonce [ccmNonceSize]byte
+	copy(nonce[:], hdr)
+	return ccmSeal(n.block, nonce[:], dst, payload, hdr), nil
+}
+
+// open checks a frame received, and decrypts it in place.
+func (n *Node) open(pkt []byte) (Message, bool) {
+	if len(pkt) < headerSize || pkt[1] != n.cfg.Network || pkt[3] == n.cfg.Address {
+		return Message{}, false
+	}
+	if pkt[2] != n.cfg.Address && pkt[2] != Broadcast {
+		return Message{}, false
+	}
+	m := Message{
+		From:    pkt[3],
+		To:      pkt[2],
+		Counter: binary.LittleEndian.Uint32(pkt[4:8]),
+		Payload: pkt[headerSize:],
+	}
+	encrypted := pkt[0]&flagEncrypted != 0
+	if encrypted != (n.block != nil) {
+		// Clear text frames are not accepted with a key.
+		return Message{}, false
+	}
+	if encrypted {
+		var nonce [ccmNonceSize]byte
+		copy(nonce[:], pkt[:headerSize])
+		payload, ok := ccmOpen(n.block, nonce[:], pkt[headerSize:], pkt[:headerSize])
+		if !ok {
+			return Message{}, false
+		}
+		m.Payload = payload
+		m.Encrypted = true
+	}
+	return m, true
+}
+
+// jitter returns a pseudo-random duration up to d/2.
+func (n *Node) jitter(d time.Duration) time.Duration {
+	// xorshift32, seeded by the address and the frame counter.
+	x := n.counter*2654435761 ^ uint32(n.cfg.Address)<<24 ^ uint32(time.Now().UnixNano())
+	x ^= x << 13
+	x ^= x >> 17
+	x ^= x << 5
+	return time.Duration(uint64(x) * uint64(d/2) >> 32)
+}
@@ -0,0 +1,227 @@
+package p2p
+
+import (
+	"bytes"
+	"crypto/aes"
+	"encoding/hex"
+	"sync"
+	"testing"
+	"time"
+
+	"tinygo.org/x/drivers/lora"
+)
+
+// air carries the frames sent by a radio to all the others.
+type air struct {
+	mu     sync.Mutex
+	radios []*fakeRadio
+	drop   func(pkt []byte) bool // drops a frame when it returns true
+}
+
+type fakeRadio struct {
+	air *air
+	rx  chan []byte
+}
+
+func (a *air) radio() *fakeRadio {
+	r := &fakeRadio{air: a, rx: make(chan []byte, 16)}
+	a.radios = append(a.radios, r)
+	return r
+}
+
+func (r *fakeRadio) Tx(pkt []uint8, timeoutMs uint32) error {
+	r.air.mu.Lock()
+	defer r.air.mu.Unlock()
+	if r.air.drop != nil && r.air.drop(pkt) {
+		return nil
+	}
+	for _, other := range r.air.radios {
+		if other != r {
+			other.rx <- append([]byte(nil), pkt...)
+		}
+	}
+	return nil
+}
+
+func (r *fakeRadio) Rx(timeoutMs uint32) ([]uint8, error) {
+	select {
+	case pkt := <-r.rx:
+		return pkt, nil
+	case <-time.After(time.Duration(timeoutMs) * time.Millisecond):
+		return nil, nil
+	}
+}
+
+func (r *fakeRadio) Reset()                         {}
+func (r *fakeRadio) SetFrequency(freq uint32)       {}
+func (r *fakeRadio) SetIqMode(mode uint8)           {}
+func (r *fakeRadio) SetCodingRate(cr uint8)         {}
+func (r *fakeRadio) SetBandwidth(bw uint8)          {}
+func (r *fakeRadio) SetCrc(enable bool)             {}
+func (r *fakeRadio) SetSpreadingFactor(sf uint8)    {}
+func (r *fakeRadio) SetPreambleLength(plen uint16)  {}
+func (r *fakeRadio) SetTxPower(txpow int8)          {}
+func (r *fakeRadio) SetSyncWord(syncWord uint16)    {}
+func (r *fakeRadio) SetPublicNetwork(enable bool)   {}
+func (r *fakeRadio) SetHeaderType(headerType uint8) {}
+func (r *fakeRadio) LoraConfig(cnf lora.Config)     {}
+
+var key = []byte("0123456789abcdef")
+
+func newNodes(t *testing.T, a *air, cfg1, cfg2 Config) (*Node, *Node) {
+	cfg1.AckTimeout, cfg2.AckTimeout = 20*time.Millisecond, 20*time.Millisecond
+	n1, err := New(a.radio(), cfg1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	n2, err := New(a.radio(), cfg2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return n1, n2
+}
+
+// receive receives the messages of n until stop is closed.
+func receive(n *Node, stop chan struct{}) chan Message {
+	msgs := make(chan Message, 16)
+	go func() {
+		defer close(msgs)
+		for {
+			select {
+			case <-stop:
+				return
+			default:
+			}
+			if m, err := n.Receive(10 * time.Millisecond); err == nil {
+				msgs <- m
+			}
+		}
+	}()
+	return msgs
+}
+
+func collect(stop chan struct{}, msgs chan Message) []Message {
+	time.Sleep(50 * time.Millisecond)
+	close(stop)
+	var got []Message
+	for m := range msgs {
+		got = append(got, m)
+	}
+	return got
+}
+
+func TestSend(t *testing.T) {
+	for _, k := range [][]byte{nil, key} {
+		a := &air{}
+		n1, n2 := newNodes(t, a, Config{Address: 1, Key: k}, Config{Address: 7, Key: k})
+		stop := make(chan struct{})
+		msgs := receive(n2, stop)
+
+		// The first frame and the first acknowledgement are lost.
+		lost := map[byte]bool{}
+		a.drop = func(pkt []byte) bool {
+			if lost[pkt[0]&flagAck] {
+				return false
+			}
+			lost[pkt[0]&flagAck] = true
+			return true
+		}
+		if err := n1.Send(7, []byte("hello")); err != nil {
+			t.Fatalf("key %v: Send: %v", k != nil, err)
+		}
+		got := collect(stop, msgs)
+		if len(got) != 1 || string(got[0].Payload) != "hello" || got[0].From != 1 || got[0].Encrypted != (k != nil) {
+			t.Errorf("key %v: received %+v", k != nil, got)
+		}
+		if n1.FrameCounter() != 1 {
+			t.Errorf("key %v: frame counter %d", k != nil, n1.FrameCounter())
+		}
+		// Sent twice, as the first frame was lost, and then resent as
+		// its acknowledgement was.
+		if s, ok := n1.LinkStats(7); !ok || s.Sent != 3 || s.Acked != 1 {
+			t.Errorf("key %v: sender link stats %+v, %v", k != nil, s, ok)
+		}
+		if s, ok := n2.LinkStats(1); !ok || s.Received != 1 || s.Lost != 0 {
+			t.Errorf("key %v: receiver link stats %+v, %v", k != nil, s, ok)
+		}
+	}
+}
+
+func TestNoAck(t *testing.T) {
+	a := &air{}
+	n1, _ := newNodes(t, a, Config{Address: 1, Retries: 2}, Config{Address: 7})
+	sent := 0
+	a.drop = func(pkt []byte) bool {
+		sent++
+		return true
+	}
+	if err := n1.Send(7, []byte("hello")); err != ErrNoAck {
+		t.Errorf("Send to nobody: %v", err)
+	}
+	if sent != 3 {
+		t.Errorf("sent %d times", sent)
+	}
+	if err := n1.Send(Broadcast, []byte("hello")); err != nil {
+		t.Errorf("Broadcast: %v", err)
+	}
+}
+
+func TestWrongKey(t *testing.T) {
+	a := &air{}
+	n1, n2 := newNodes(t, a, Config{Address: 1, Key: key, Retries: 1},
+		Config{Address: 7, Key: []byte("fedcba9876543210")})
+	stop := make(chan struct{})
+	msgs := receive(n2, stop)
+	if err := n1.Send(7, []byte("hello")); err != ErrNoAck {
+		t.Errorf("Send with the wrong key: %v", err)
+	}
+	if got := collect(stop, msgs); len(got) != 0 {
+		t.Errorf("received %+v", got)
+	}
+}
+
+func TestSendValue(t *testing.T) {
+	type reading struct {
+		Temperature int16
+		Humidity    uint8
+		Battery     uint16
+	}
+	a := &air{}
+	n1, n2 := newNodes(t, a, Config{Address: 1, Key: key}, Config{Address: 7, Key: key})
+	stop := make(chan struct{})
+	msgs := receive(n2, stop)
+	want := reading{-125, 48, 3300}
+	if err := n1.SendValue(Broadcast, want); err != nil {
+		t.Fatal(err)
+	}
+	got := collect(stop, msgs)
+	if len(got) != 1 || got[0].To != Broadcast {
+		t.Fatalf("received %+v", got)
+	}
+	var r reading
+	if err := got[0].Decode(&r); err != nil || r != want {
+		t.Errorf("decoded %+v, %v", r, err)
+	}
+}
+
+// Packet vector #1 of RFC 3610.
+func TestCCM(t *testing.T) {
+	key, _ := hex.DecodeString("C0C1C2C3C4C5C6C7C8C9CACBCCCDCECF")
+	nonce, _ := hex.DecodeString("00000003020100A0A1A2A3A4A5")
+	hdr, _ := hex.DecodeString("0001020304050607")
+	msg, _ := hex.DecodeString("08090A0B0C0D0E0F101112131415161718191A1B1C1D1E")
+	want, _ := hex.DecodeString("588C979A61C663D2F066D0C2C0F989806D5F6B61DAC38417E8D12CFDF926E0")
+	b, _ := aes.NewCipher(key)
+	got := ccmSeal(b, nonce, nil, msg, hdr)
+	if !bytes.Equal(got, want) {
+		t.Fatalf("sealed %X", got)
+	}
+	plain, ok := ccmOpen(b, nonce, got, hdr)
+	if !ok || !bytes.Equal(plain, msg) {
+		t.Errorf("opened %X, %v", plain, ok)
+	}
+	got[3] ^= 1
+	if _, ok := ccmOpen(b, nonce, got, hdr); ok {
+		t.Error("corrupted message opened")
+	}
+}
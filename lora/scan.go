@@ -0,0 +1,66 @@
+package lora
+
+import "time"
+
+// RSSIMonitor is a radio that can measure the signal level on a channel,
+// as the SX126x, SX127x and SX1280 can.
+type RSSIMonitor interface {
+	// StartRSSI tunes the radio to a frequency in Hz and starts receiving,
+	// without handling packets.
+	StartRSSI(freq uint32)
+
+	// InstantRSSI returns the signal level received, in dBm.
+	InstantRSSI() int16
+
+	// StopRSSI puts the radio back in standby.
+	StopRSSI()
+}
+
+// ChannelNoise is the signal level measured on a channel while no packet
+// was sent by the node.
+type ChannelNoise struct {
+	Freq  uint32 // Hz
+	Floor int16  // average level, in dBm
+	Peak  int16  // highest level, in dBm, raised by other transmitters
+}
+
+// ScanChannels measures the level of each frequency samples times, every
+// interval, and appends the results to dst. A longer scan catches more of
+// the transmitters that are only on the air now and then.
+func ScanChannels(m RSSIMonitor, freqs []uint32, samples int, interval time.Duration, dst []ChannelNoise) []ChannelNoise {
+	if samples < 1 {
+		samples = 1
+	}
+	for _, freq := range freqs {
+		m.StartRSSI(freq)
+		ch := ChannelNoise{Freq: freq, Peak: -32768}
+		sum := 0
+		for i := 0; i < samples; i++ {
+			if i > 0 {
+				time.Sleep(interval)
+			}
+			rssi := m.InstantRSSI()
+			sum += int(rssi)
+			if rssi > ch.Peak {
+				ch.Peak = rssi
+			}
+		}
+		ch.Floor = int16(sum / samples)
+		dst = append(dst, ch)
+	}
+	m.StopRSSI()
+	return dst
+}
+
+// Quietest returns the index of the channel with the lowest noise floor,
+// and of these the one with the lowest peak, or -1 when there is none.
+func Quietest(channels []ChannelNoise) int {
+	best := -1
+	for i, ch := range channels {
+		if best < 0 || ch.Floor < channels[best].Floor ||
+			ch.Floor == channels[best].Floor && ch.Peak < channels[best].Peak {
+			best = i
+		}
+	}
+	return best
+}
@@ -0,0 +1,75 @@
+package lora
+
+import "testing"
+
+// fakeMonitor returns the levels of a channel in turn.
+type fakeMonitor struct {
+	levels map[uint32][]int16
+	freq   uint32
+	n      int
+	stops  int
+}
+
+func (m *fakeMonitor) StartRSSI(freq uint32) {
+	m.freq, m.n = freq, 0
+}
+
+func (m *fakeMonitor) InstantRSSI() int16 {
+	l := m.levels[m.freq]
+	m.n++
+	return l[(m.n-1)%len(l)]
+}
+
+func (m *fakeMonitor) StopRSSI() {
+	m.stops++
+}
+
+func TestScanChannels(t *testing.T) {
+	m := &fakeMonitor{levels: map[uint32][]int16{
+		868100000: {-110, -60, -110, -112},
+		868300000: {-105, -104, -106, -105},
+		868500000: {-110, -111, -110, -109},
+	}}
+	freqs := []uint32{868100000, 868300000, 868500000}
+	got := ScanChannels(m, freqs, 4, 0, nil)
+	want := []ChannelNoise{
+		{868100000, -98, -60},
+		{868300000, -105, -104},
+		{868500000, -110, -109},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("got %+v", got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("channel %d: got %+v, want %+v", i, got[i], want[i])
+		}
+	}
+	if m.stops != 1 {
+		t.Errorf("stopped %d times", m.stops)
+	}
+	if i := Quietest(got); i != 2 {
+		t.Errorf("quietest channel %d", i)
+	}
+	if i := Quietest(nil); i != -1 {
+		t.Errorf("quietest of none %d", i)
+	}
+}
+
+func TestLinkStats(t *testing.T) {
+	var s LinkStats
+	for _, seq := range []uint32{10, 11, 13, 14, 18, 2, 3} {
+		s.Receive(seq)
+	}
+	if s.Received != 7 || s.Lost != 4 {
+		t.Errorf("received %d, lost %d", s.Received, s.Lost)
+	}
+	if r := s.RxErrorRate(); r < 0.36 || r > 0.37 {
+		t.Errorf("rx error rate %v", r)
+	}
+	s.Transmit(1, true)
+	s.Transmit(3, true)
+	if r := s.TxErrorRate(); r != 0.5 {
+		t.Errorf("tx error rate %v", r)
+	}
+}
@@ -0,0 +1,213 @@
+// Package ltr390 provides a driver for the LTR-390UV ambient light and UV
+// sensor by Lite-On.
+//
+// Datasheet: https://optoelectronics.liteon.com/upload/download/DS86-2015-0004/LTR-390UV_Final_%20DS_V1%201.pdf
+package ltr390 // import "tinygo.org/x/drivers/ltr390"
+
+import (
+	"errors"
+	"time"
+
+	"tinygo.org/x/drivers"
+	"tinygo.org/x/drivers/internal/legacy"
+)
+
+var (
+	errNotConnected = errors.New("ltr390: device not found")
+	errTimeout      = errors.New("ltr390: timeout waiting for measurement")
+)
+
+// Gain of the sensor. Higher gains are needed in low light.
+type Gain uint8
+
+const (
+	Gain1 Gain = iota + 1
+	Gain3
+	Gain6
+	Gain9
+	Gain18
+)
+
+// Resolution of the measurement. Higher resolutions take longer: from 400ms
+// for 20 bit down to 12.5ms for 13 bit.
+type Resolution uint8
+
+const (
+	Resolution20Bit Resolution = iota + 1
+	Resolution19Bit
+	Resolution18Bit
+	Resolution17Bit
+	Resolution16Bit
+	Resolution13Bit
+)
+
+// gains maps the gain register value to the actual factor.
+var gains = [...]uint64{1, 3, 6, 9, 18}
+
+// rates maps the resolution register value to the shortest measurement rate
+// that is not below its conversion time.
+var rates = [...]uint8{
+	0b100, // 500ms for 400ms
+	0b011, // 200ms
+	0b010, // 100ms
+	0b001, // 50ms
+	0b000, // 25ms
+	0b000, // 25ms for 12.5ms
+}
+
+// Config holds the measurement settings.
+type Config struct {
+	// Gain defaults to Gain3.
+	Gain Gain
+
+	// Resolution defaults to Resolution18Bit (100ms).
+	Resolution Resolution
+
+	// WindowFactor compensates for a window in front of the sensor, in
+	// thousandths. 1000 (the default) means no window.
+	WindowFactor uint32
+}
+
+// Device wraps an I2C connection to a LTR390 device.
+type Device struct {
+	bus     drivers.I2C
+	Address uint16
+	cfg     Config
+	gain    uint8 // register values
+	res     uint8
+	uvMode  bool
+	buf     [3]byte
+}
+
+// New creates a new LTR390 connection. The I2C bus must already be
+// configured.
+//
+// This function only creates the Device object, it does not touch the device.
+func New(bus drivers.I2C) *Device {
+	return &Device{bus: bus, Address: Address}
+}
+
+// Connected returns whether a LTR390 has been found.
+func (d *Device) Connected() bool {
+	id, err := d.read8(PART_ID)
+	return err == nil && id&0xF0 == partID&0xF0
+}
+
+// Configure resets the sensor and starts measuring ambient light.
+func (d *Device) Configure(cfg Config) error {
+	if cfg.Gain == 0 {
+		cfg.Gain = Gain3
+	}
+	if cfg.Resolution == 0 {
+		cfg.Resolution = Resolution18Bit
+	}
+	if cfg.WindowFactor == 0 {
+		cfg.WindowFactor = 1000
+	}
+	d.cfg = cfg
+	d.gain = uint8(cfg.Gain) - 1
+	d.res = uint8(cfg.Resolution) - 1
+	if !d.Connected() {
+		return errNotConnected
+	}
+	// The sensor does not ACK the reset command, ignore the error.
+	d.write8(MAIN_CTRL, ctrlSoftReset)
+	time.Sleep(10 * time.Millisecond)
+
+	if err := d.write8(MEAS_RATE, d.res<<4|rates[d.res]); err != nil {
+		return err
+	}
+	if err := d.write8(GAIN, d.gain); err != nil {
+		return err
+	}
+	return d.setMode(false)
+}
+
+// ReadUVIndex measures the UV index, in thousandths. It switches the sensor
+// to UV mode if needed, which takes one conversion.
+func (d *Device) ReadUVIndex() (int32, error) {
+	raw, err := d.read(true)
+	if err != nil {
+		return 0, err
+	}
+	// The sensitivity is 2300 counts per UVI at gain 18 and 20 bit
+	// resolution (400ms), and scales with the gain and integration time.
+	uvi := uint64(raw) * 1000 * 18 << d.res / (2300 * gains[d.gain])
+	return int32(uvi * uint64(d.cfg.WindowFactor) / 1000), nil
+}
+
+// ReadLux measures the ambient light in milli lux. It switches the sensor to
+// ambient light mode if needed, which takes one conversion.
+func (d *Device) ReadLux() (int32, error) {
+	raw, err := d.read(false)
+	if err != nil {
+		return 0, err
+	}
+	// lux = 0.6 * ALS / (gain * integration time / 100ms)
+	mlux := uint64(raw) * 600 << d.res / (4 * gains[d.gain])
+	return int32(mlux * uint64(d.cfg.WindowFactor) / 1000), nil
+}
+
+// read returns the raw value of the UV or ambient light channel.
+func (d *Device) read(uv bool) (uint32, error) {
+	if uv != d.uvMode {
+		if err := d.setMode(uv); err != nil {
+			return 0, err
+		}
+		// Discard the result of the conversion that was running.
+		if err := d.waitReady(); err != nil {
+			return 0, err
+		}
+	}
+	if err := d.waitReady(); err != nil {
+		return 0, err
+	}
+	reg := uint8(ALS_DATA)
+	if uv {
+		reg = UVS_DATA
+	}
+	err := legacy.ReadRegister(d.bus, uint8(d.Address), reg, d.buf[:])
+	if err != nil {
+		return 0, err
+	}
+	return uint32(d.buf[0]) | uint32(d.buf[1])<<8 | uint32(d.buf[2]&0x0F)<<16, nil
+}
+
+// waitReady waits until a new conversion has completed. Reading the status
+// register clears the ready flag.
+func (d *Device) waitReady() error {
+	// The longest conversion takes 400ms.
+	for start := time.Now(); time.Since(start) < time.Second; {
+		status, err := d.read8(MAIN_STATUS)
+		if err != nil {
+			return err
+		}
+		if status&statusReady != 0 {
+			return nil
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	return errTimeout
+}
+
+func (d *Device) setMode(uv bool) error {
+	ctrl := uint8(ctrlEnable)
+	if uv {
+		ctrl |= ctrlUVSMode
+	}
+	if err := d.write8(MAIN_CTRL, ctrl); err != nil {
+		return err
+	}
+	d.uvMode = uv
+	return nil
+}
+
+func (d *Device) read8(reg uint8) (uint8, error) {
+	err := legacy.ReadRegister(d.bus, uint8(d.Address), reg, d.buf[:1])
+	return d.buf[0], err
+}
+
+func (d *Device) write8(reg, value uint8) error {
+	d.buf[0] = value
+	return legacy.WriteRegister(d.bus, uint8(d.Address), reg, d.buf[:1])
+}
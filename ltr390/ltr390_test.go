@@ -0,0 +1,35 @@
+package ltr390
+
+import (
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+	"tinygo.org/x/drivers/tester"
+)
+
+func TestRead(t *testing.T) {
+	c := qt.New(t)
+	bus := tester.NewI2CBus(c)
+	fake := tester.NewI2CDevice(c, Address)
+	fake.Registers[PART_ID] = partID
+	fake.Registers[MAIN_STATUS] = statusReady
+	bus.AddDevice(fake)
+
+	dev := New(bus)
+	c.Assert(dev.Configure(Config{}), qt.IsNil)
+	c.Assert(fake.Registers[GAIN], qt.Equals, uint8(1))
+	c.Assert(fake.Registers[MEAS_RATE], qt.Equals, uint8(0x22))
+
+	// 1150 counts at gain 3 and 100ms is UVI 12.
+	copy(fake.Registers[UVS_DATA:], []byte{0x7E, 0x04, 0x00})
+	uvi, err := dev.ReadUVIndex()
+	c.Assert(err, qt.IsNil)
+	c.Assert(uvi, qt.Equals, int32(12000))
+	c.Assert(fake.Registers[MAIN_CTRL], qt.Equals, uint8(ctrlEnable|ctrlUVSMode))
+
+	copy(fake.Registers[ALS_DATA:], []byte{0xE8, 0x03, 0x00})
+	lux, err := dev.ReadLux()
+	c.Assert(err, qt.IsNil)
+	c.Assert(lux, qt.Equals, int32(200000))
+	c.Assert(fake.Registers[MAIN_CTRL], qt.Equals, uint8(ctrlEnable))
+}
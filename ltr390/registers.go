@@ -0,0 +1,24 @@
+package ltr390
+
+// Address is the I2C address of the LTR390.
+const Address = 0x53
+
+// Registers
+const (
+	MAIN_CTRL   = 0x00
+	MEAS_RATE   = 0x04
+	GAIN        = 0x05
+	PART_ID     = 0x06
+	MAIN_STATUS = 0x07
+	ALS_DATA    = 0x0D
+	UVS_DATA    = 0x10
+)
+
+// Bits
+const (
+	ctrlEnable    = 1 << 1
+	ctrlUVSMode   = 1 << 3
+	ctrlSoftReset = 1 << 4
+	statusReady   = 1 << 3
+	partID        = 0xB2
+)
@@ -0,0 +1,157 @@
+package max30102
+
+// Estimator is a simple beat detector and SpO2 estimator for the raw red and
+// IR samples read from the FIFO. It is meant for prototypes: the results are
+// indicative only and must not be used for any medical purpose.
+//
+// Every sample has its DC level removed and is low-pass filtered. A beat is
+// detected when the filtered IR signal crosses zero upwards after a valley
+// deep enough to not be noise. The heart rate is the average of the last four
+// beat intervals. The SpO2 is estimated once per beat from the ratio of the
+// relative pulse amplitudes of the red and IR signals, using the common
+// linear approximation SpO2 = 110 - 25 * R.
+type Estimator struct {
+	// SampleRate is the number of samples per second fed to Add.
+	SampleRate uint32
+
+	// FingerThreshold is the minimum IR level at which a finger is
+	// considered present. Defaults to 50000.
+	FingerThreshold uint32
+
+	irDC, redDC  int64 // DC level, 8 bits fraction
+	irLP         int32 // low-pass filtered IR AC component
+	valley       int32 // lowest filtered value since the last beat
+	sinceBeat    uint32
+	intervals    [4]uint32
+	numIntervals int
+
+	// Per beat accumulators for the SpO2 ratio.
+	irMin, irMax, redMin, redMax int32
+
+	heartRate int32
+	spo2      int32
+	primed    bool
+}
+
+// Add adds a sample and returns whether it completed a heart beat.
+func (e *Estimator) Add(red, ir uint32) bool {
+	threshold := e.FingerThreshold
+	if threshold == 0 {
+		threshold = 50000
+	}
+	if ir < threshold {
+		e.Reset()
+		return false
+	}
+
+	if !e.primed {
+		e.irDC = int64(ir) << 8
+		e.redDC = int64(red) << 8
+		e.primed = true
+	}
+	// Exponential moving average with alpha 1/16 tracks the DC level.
+	e.irDC += (int64(ir)<<8 - e.irDC) >> 4
+	e.redDC += (int64(red)<<8 - e.redDC) >> 4
+	irAC := int32(int64(ir) - e.irDC>>8)
+	redAC := int32(int64(red) - e.redDC>>8)
+
+	// Low-pass filter with alpha 1/4 to remove high frequency noise.
+	prev := e.irLP
+	e.irLP += (irAC - e.irLP) >> 2
+
+	if e.irLP < e.valley {
+		e.valley = e.irLP
+	}
+	e.track(irAC, redAC)
+	e.sinceBeat++
+
+	// A beat is an upward zero crossing after a valley, no sooner than
+	// 0.3s after the previous one (200 bpm).
+	rate := e.SampleRate
+	if rate == 0 {
+		rate = 100
+	}
+	if prev < 0 && e.irLP >= 0 && e.valley < -20 && e.sinceBeat > rate*3/10 {
+		e.beat(rate)
+		return true
+	}
+	return false
+}
+
+// track keeps the extremes of the AC signals during a beat.
+func (e *Estimator) track(irAC, redAC int32) {
+	if irAC < e.irMin {
+		e.irMin = irAC
+	}
+	if irAC > e.irMax {
+		e.irMax = irAC
+	}
+	if redAC < e.redMin {
+		e.redMin = redAC
+	}
+	if redAC > e.redMax {
+		e.redMax = redAC
+	}
+}
+
+// beat processes a detected heart beat.
+func (e *Estimator) beat(rate uint32) {
+	// Ignore implausibly long intervals (below 30 bpm), such as the first
+	// one after the finger was placed.
+	if e.sinceBeat < rate*2 {
+		copy(e.intervals[1:], e.intervals[:len(e.intervals)-1])
+		e.intervals[0] = e.sinceBeat
+		if e.numIntervals < len(e.intervals) {
+			e.numIntervals++
+		}
+		var sum uint32
+		for _, v := range e.intervals[:e.numIntervals] {
+			sum += v
+		}
+		e.heartRate = int32(uint32(e.numIntervals) * rate * 60 / sum)
+	}
+
+	irDC := e.irDC >> 8
+	redDC := e.redDC >> 8
+	irAC := int64(e.irMax - e.irMin)
+	redAC := int64(e.redMax - e.redMin)
+	if irAC > 0 && irDC > 0 && redDC > 0 {
+		// R = (ACred/DCred) / (ACir/DCir), scaled by 1000.
+		r := redAC * irDC * 1000 / (redDC * irAC)
+		spo2 := 110 - 25*r/1000
+		if spo2 > 100 {
+			spo2 = 100
+		}
+		if spo2 < 0 {
+			spo2 = 0
+		}
+		e.spo2 = int32(spo2)
+	}
+
+	e.sinceBeat = 0
+	e.valley = 0
+	e.irMin, e.irMax, e.redMin, e.redMax = 0, 0, 0, 0
+}
+
+// HeartRate returns the heart rate in beats per minute, or 0 when not known
+// yet.
+func (e *Estimator) HeartRate() int32 {
+	return e.heartRate
+}
+
+// SpO2 returns the estimated oxygen saturation in percent, or 0 when not
+// known yet.
+func (e *Estimator) SpO2() int32 {
+	return e.spo2
+}
+
+// FingerPresent returns whether the last sample had a finger on the sensor.
+func (e *Estimator) FingerPresent() bool {
+	return e.primed
+}
+
+// Reset forgets all state, for example after the finger was removed.
+func (e *Estimator) Reset() {
+	sampleRate, threshold := e.SampleRate, e.FingerThreshold
+	*e = Estimator{SampleRate: sampleRate, FingerThreshold: threshold}
+}
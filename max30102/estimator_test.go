@@ -0,0 +1,32 @@
+package max30102
+
+import (
+	"math"
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+)
+
+func TestEstimator(t *testing.T) {
+	c := qt.New(t)
+
+	// 75 bpm at 100 samples per second, with red pulsing relatively half as
+	// strong as IR (R = 0.5, SpO2 = 97.5%).
+	e := Estimator{SampleRate: 100}
+	beats := 0
+	for i := 0; i < 1000; i++ {
+		s := math.Sin(2 * math.Pi * 1.25 * float64(i) / 100)
+		ir := uint32(100000 + 1000*s)
+		red := uint32(80000 + 400*s)
+		if e.Add(red, ir) {
+			beats++
+		}
+	}
+	c.Assert(beats >= 10 && beats <= 13, qt.IsTrue, qt.Commentf("beats: %d", beats))
+	c.Assert(e.HeartRate() >= 73 && e.HeartRate() <= 77, qt.IsTrue, qt.Commentf("HR: %d", e.HeartRate()))
+	c.Assert(e.SpO2() >= 96 && e.SpO2() <= 99, qt.IsTrue, qt.Commentf("SpO2: %d", e.SpO2()))
+
+	e.Add(80000, 1000)
+	c.Assert(e.FingerPresent(), qt.IsFalse)
+	c.Assert(e.HeartRate(), qt.Equals, int32(0))
+}
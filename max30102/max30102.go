@@ -0,0 +1,263 @@
+// Package max30102 implements a driver for the MAX30102 pulse oximeter and
+// heart-rate sensor.
+//
+// Datasheet: https://datasheets.maximintegrated.com/en/ds/MAX30102.pdf
+package max30102 // import "tinygo.org/x/drivers/max30102"
+
+import (
+	"errors"
+	"time"
+
+	"tinygo.org/x/drivers"
+)
+
+var errTimeout = errors.New("max30102: timeout")
+
+// fifoDepth is the number of samples the FIFO can hold.
+const fifoDepth = 32
+
+// Config contains the measurement settings. The zero value is SpO2 mode at
+// 100 samples per second, 411µs pulses, 4096nA range, no averaging and 7mA
+// for both LEDs.
+type Config struct {
+	Mode          Mode
+	SampleRate    SampleRate
+	PulseWidth    PulseWidth
+	ADCRange      ADCRange
+	SampleAverage SampleAverage
+
+	// LED currents in µA, between 0 and 51000 in steps of 200µA.
+	RedCurrent uint32
+	IRCurrent  uint32
+}
+
+// Device wraps an I2C connection to a MAX30102 device.
+type Device struct {
+	bus     drivers.I2C
+	Address uint8
+	mode    Mode
+	buf     [1 + fifoDepth*6]byte
+}
+
+// New creates a new MAX30102 connection. The I2C bus must already be
+// configured.
+//
+// This function only creates the Device object, it does not touch the device.
+func New(bus drivers.I2C) *Device {
+	return &Device{bus: bus, Address: Address}
+}
+
+// Connected returns whether a MAX30102 has been found.
+func (d *Device) Connected() bool {
+	id, err := d.read8(PART_ID)
+	return err == nil && id == 0x15
+}
+
+// DriverName returns "max30102".
+func (d *Device) DriverName() string {
+	return "max30102"
+}
+
+// ChipID returns the part ID, 0x15 for a MAX30102.
+func (d *Device) ChipID() (uint32, error) {
+	id, err := d.read8(PART_ID)
+	return uint32(id), err
+}
+
+// RevisionID returns the revision ID register.
+func (d *Device) RevisionID() (uint32, error) {
+	rev, err := d.read8(REV_ID)
+	return uint32(rev), err
+}
+
+// Configure resets the device and starts measuring.
+func (d *Device) Configure(cfg Config) error {
+	if cfg.Mode == 0 {
+		cfg.Mode = ModeSpO2
+	}
+	if cfg.SampleRate == 0 && cfg.PulseWidth == 0 && cfg.ADCRange == 0 {
+		cfg.SampleRate = SampleRate100
+		cfg.PulseWidth = PulseWidth411
+		cfg.ADCRange = ADCRange4096
+	}
+	if cfg.RedCurrent == 0 && cfg.IRCurrent == 0 {
+		cfg.RedCurrent = 7000
+		cfg.IRCurrent = 7000
+	}
+	d.mode = cfg.Mode
+
+	if err := d.Reset(); err != nil {
+		return err
+	}
+	// Roll over when full so the newest samples are kept, almost full
+	// interrupt with 4 samples left.
+	if err := d.write8(FIFO_CONFIG, uint8(cfg.SampleAverage)<<5|0x10|0x04); err != nil {
+		return err
+	}
+	spo2 := uint8(cfg.ADCRange)<<5 | uint8(cfg.SampleRate)<<2 | uint8(cfg.PulseWidth)
+	if err := d.write8(SPO2_CONFIG, spo2); err != nil {
+		return err
+	}
+	if err := d.SetLEDCurrent(cfg.RedCurrent, cfg.IRCurrent); err != nil {
+		return err
+	}
+	if err := d.ClearFIFO(); err != nil {
+		return err
+	}
+	return d.write8(MODE_CONFIG, uint8(cfg.Mode))
+}
+
+// Reset resets all registers to their power-on state.
+func (d *Device) Reset() error {
+	if err := d.write8(MODE_CONFIG, 0x40); err != nil {
+		return err
+	}
+	timeout := time.Now().Add(100 * time.Millisecond)
+	for {
+		mode, err := d.read8(MODE_CONFIG)
+		if err != nil {
+			return err
+		}
+		if mode&0x40 == 0 {
+			return nil
+		}
+		if time.Now().After(timeout) {
+			return errTimeout
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+// SetLEDCurrent sets the red and IR LED currents in µA.
+func (d *Device) SetLEDCurrent(red, ir uint32) error {
+	if err := d.write8(LED1_PA, currentToReg(red)); err != nil {
+		return err
+	}
+	return d.write8(LED2_PA, currentToReg(ir))
+}
+
+func currentToReg(uA uint32) uint8 {
+	if uA > 51000 {
+		uA = 51000
+	}
+	return uint8(uA / 200)
+}
+
+// Shutdown puts the device in power-save mode, or wakes it up again.
+func (d *Device) Shutdown(shutdown bool) error {
+	mode := uint8(d.mode)
+	if shutdown {
+		mode |= 0x80
+	}
+	return d.write8(MODE_CONFIG, mode)
+}
+
+// SetInterrupts enables the interrupts in mask (INT_A_FULL, INT_PPG_RDY,
+// INT_ALC_OVF). The INT pin is active low and open drain.
+func (d *Device) SetInterrupts(mask uint8) error {
+	return d.write8(INT_ENABLE_1, mask&0xE0)
+}
+
+// InterruptStatus reads and clears the interrupt status.
+func (d *Device) InterruptStatus() (uint8, error) {
+	return d.read8(INT_STATUS_1)
+}
+
+// ClearFIFO discards all samples in the FIFO.
+func (d *Device) ClearFIFO() error {
+	w := [...]byte{FIFO_WR_PTR, 0, 0, 0}
+	return d.bus.Tx(uint16(d.Address), w[:], nil)
+}
+
+// Available returns the number of samples in the FIFO.
+func (d *Device) Available() (int, error) {
+	var ptrs [3]byte
+	if err := d.readn(FIFO_WR_PTR, ptrs[:]); err != nil {
+		return 0, err
+	}
+	if ptrs[1] != 0 {
+		// The FIFO overflowed, so it is full.
+		return fifoDepth, nil
+	}
+	return int(ptrs[0]-ptrs[2]) & (fifoDepth - 1), nil
+}
+
+// ReadFIFO reads up to len(red) samples from the FIFO in a single burst and
+// returns the number of samples read. In SpO2 mode, ir must be at least as
+// long as red; in heart rate mode it may be nil. Samples are 18 bit values,
+// left-aligned to the ADC resolution.
+func (d *Device) ReadFIFO(red, ir []uint32) (int, error) {
+	n, err := d.Available()
+	if err != nil {
+		return 0, err
+	}
+	if n > len(red) {
+		n = len(red)
+	}
+	if n == 0 {
+		return 0, nil
+	}
+	size := 3
+	if d.mode == ModeSpO2 {
+		size = 6
+	}
+	data := d.buf[:n*size]
+	if err := d.readn(FIFO_DATA, data); err != nil {
+		return 0, err
+	}
+	for i := 0; i < n; i++ {
+		s := data[i*size:]
+		red[i] = (uint32(s[0])<<16 | uint32(s[1])<<8 | uint32(s[2])) & 0x3FFFF
+		if size == 6 {
+			ir[i] = (uint32(s[3])<<16 | uint32(s[4])<<8 | uint32(s[5])) & 0x3FFFF
+		}
+	}
+	return n, nil
+}
+
+// ReadTemperature returns the die temperature in celsius milli degrees
+// (°C/1000). The die temperature can be used to compensate the SpO2
+// estimation, as the LED wavelengths drift with temperature.
+func (d *Device) ReadTemperature() (int32, error) {
+	if err := d.write8(TEMP_CONFIG, 0x01); err != nil {
+		return 0, err
+	}
+	timeout := time.Now().Add(100 * time.Millisecond)
+	for {
+		cfg, err := d.read8(TEMP_CONFIG)
+		if err != nil {
+			return 0, err
+		}
+		if cfg&0x01 == 0 {
+			break
+		}
+		if time.Now().After(timeout) {
+			return 0, errTimeout
+		}
+		time.Sleep(time.Millisecond)
+	}
+	var t [2]byte
+	if err := d.readn(TEMP_INTG, t[:]); err != nil {
+		return 0, err
+	}
+	// Integer part in two's complement, fraction in 0.0625°C steps.
+	return int32(int8(t[0]))*1000 + int32(t[1]&0x0F)*625/10, nil
+}
+
+func (d *Device) read8(reg uint8) (uint8, error) {
+	d.buf[0] = reg
+	err := d.bus.Tx(uint16(d.Address), d.buf[:1], d.buf[1:2])
+	return d.buf[1], err
+}
+
+func (d *Device) readn(reg uint8, data []byte) error {
+	var w [1]byte
+	w[0] = reg
+	return d.bus.Tx(uint16(d.Address), w[:], data)
+}
+
+func (d *Device) write8(reg, value uint8) error {
+	d.buf[0] = reg
+	d.buf[1] = value
+	return d.bus.Tx(uint16(d.Address), d.buf[:2], nil)
+}
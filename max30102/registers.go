@@ -0,0 +1,89 @@
+package max30102
+
+// The I2C address which this device listens to.
+const Address = 0x57
+
+// Registers.
+const (
+	INT_STATUS_1   = 0x00
+	INT_STATUS_2   = 0x01
+	INT_ENABLE_1   = 0x02
+	INT_ENABLE_2   = 0x03
+	FIFO_WR_PTR    = 0x04
+	OVF_COUNTER    = 0x05
+	FIFO_RD_PTR    = 0x06
+	FIFO_DATA      = 0x07
+	FIFO_CONFIG    = 0x08
+	MODE_CONFIG    = 0x09
+	SPO2_CONFIG    = 0x0A
+	LED1_PA        = 0x0C // red
+	LED2_PA        = 0x0D // IR
+	MULTI_LED_CTRL = 0x11
+	TEMP_INTG      = 0x1F
+	TEMP_FRAC      = 0x20
+	TEMP_CONFIG    = 0x21
+	REV_ID         = 0xFE
+	PART_ID        = 0xFF
+)
+
+// Interrupt bits in INT_STATUS_1 and INT_ENABLE_1.
+const (
+	INT_A_FULL  = 0x80 // FIFO almost full
+	INT_PPG_RDY = 0x40 // new sample in the FIFO
+	INT_ALC_OVF = 0x20 // ambient light cancellation overflow
+	INT_PWR_RDY = 0x01 // power ready, status only
+)
+
+// Mode selects which LEDs are used.
+type Mode uint8
+
+const (
+	ModeHeartRate Mode = 0x02 // red LED only
+	ModeSpO2      Mode = 0x03 // red and IR LED
+)
+
+// SampleRate is the number of samples per second.
+type SampleRate uint8
+
+const (
+	SampleRate50   SampleRate = 0
+	SampleRate100  SampleRate = 1
+	SampleRate200  SampleRate = 2
+	SampleRate400  SampleRate = 3
+	SampleRate800  SampleRate = 4
+	SampleRate1000 SampleRate = 5
+	SampleRate1600 SampleRate = 6
+	SampleRate3200 SampleRate = 7
+)
+
+// PulseWidth is the LED pulse width, which also sets the ADC resolution.
+type PulseWidth uint8
+
+const (
+	PulseWidth69  PulseWidth = 0 // 69µs, 15 bits
+	PulseWidth118 PulseWidth = 1 // 118µs, 16 bits
+	PulseWidth215 PulseWidth = 2 // 215µs, 17 bits
+	PulseWidth411 PulseWidth = 3 // 411µs, 18 bits
+)
+
+// ADCRange is the full scale range of the ADC.
+type ADCRange uint8
+
+const (
+	ADCRange2048  ADCRange = 0 // 2048nA
+	ADCRange4096  ADCRange = 1 // 4096nA
+	ADCRange8192  ADCRange = 2 // 8192nA
+	ADCRange16384 ADCRange = 3 // 16384nA
+)
+
+// SampleAverage is the number of samples averaged per FIFO entry.
+type SampleAverage uint8
+
+const (
+	SampleAverage1  SampleAverage = 0
+	SampleAverage2  SampleAverage = 1
+	SampleAverage4  SampleAverage = 2
+	SampleAverage8  SampleAverage = 3
+	SampleAverage16 SampleAverage = 4
+	SampleAverage32 SampleAverage = 5
+)
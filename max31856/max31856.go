@@ -0,0 +1,214 @@
+//go:build tinygo
+
+// Package max31856 implements a driver for the MAX31856 precision
+// thermocouple to digital converter with linearization.
+//
+// The chip linearizes the thermocouple voltage and compensates the cold
+// junction itself for the thermocouple types B, E, J, K, N, R, S and T, so
+// the temperatures are read directly in m°C.
+//
+// Datasheet: https://www.analog.com/media/en/technical-documentation/data-sheets/MAX31856.pdf
+package max31856 // import "tinygo.org/x/drivers/max31856"
+
+import (
+	"machine"
+	"time"
+
+	"tinygo.org/x/drivers"
+)
+
+// Device wraps an SPI connection to a MAX31856.
+type Device struct {
+	bus  drivers.SPI
+	cs   machine.Pin
+	drdy machine.Pin
+	cfg  Config
+	cr0  uint8
+	tx   [7]byte
+	rx   [7]byte
+
+	temperature  int32
+	coldJunction int32
+	raw          int32
+	fault        Fault
+}
+
+// New creates a new MAX31856 connection. The SPI bus must already be
+// configured in mode 1 or 3, at up to 5MHz.
+//
+// This function only creates the Device object, it does not touch the device.
+func New(bus drivers.SPI, cs machine.Pin) *Device {
+	return &Device{
+		bus:  bus,
+		cs:   cs,
+		drdy: machine.NoPin,
+	}
+}
+
+// SetDataReadyPin sets the pin connected to DRDY, which goes low when a
+// conversion result is available. Without it the conversion time is waited
+// for in one-shot mode, and the last result is read in continuous mode.
+func (d *Device) SetDataReadyPin(pin machine.Pin) {
+	d.drdy = pin
+	d.drdy.Configure(machine.PinConfig{Mode: machine.PinInputPullup})
+}
+
+// Configure sets up the converter.
+func (d *Device) Configure(cfg Config) error {
+	d.cs.Configure(machine.PinConfig{Mode: machine.PinOutput})
+	d.cs.High()
+	d.cfg = cfg
+
+	avg := uint8(0)
+	for n := cfg.Averaging; n > 1 && avg < 4; n >>= 1 {
+		avg++
+	}
+	tc := tcTypes[0]
+	if int(cfg.Type) < len(tcTypes) {
+		tc = tcTypes[cfg.Type]
+	}
+
+	d.cr0 = 0
+	switch cfg.OpenCircuit {
+	case OpenCircuitFast:
+		d.cr0 |= 0x10
+	case OpenCircuitMedium:
+		d.cr0 |= 0x20
+	case OpenCircuitSlow:
+		d.cr0 |= 0x30
+	}
+	if cfg.Filter50Hz {
+		d.cr0 |= cr0FILTER50
+	}
+
+	// The noise filter can only be changed while conversions are stopped.
+	if err := d.writeRegister(regCR0, d.cr0); err != nil {
+		return err
+	}
+	if err := d.writeRegister(regCR1, avg<<4|tc); err != nil {
+		return err
+	}
+	// Report the open circuit and over/under voltage faults on the FAULT pin.
+	if err := d.writeRegister(regMASK, ^uint8(FaultOpen|FaultOverUnderVoltage)); err != nil {
+		return err
+	}
+	if cfg.Continuous {
+		d.cr0 |= cr0CMODE
+	}
+	return d.writeRegister(regCR0, d.cr0|cr0FAULTCLR)
+}
+
+// Ready returns whether a new conversion result is available. It always
+// returns true if no DRDY pin has been set.
+func (d *Device) Ready() bool {
+	return d.drdy == machine.NoPin || !d.drdy.Get()
+}
+
+// Update reads the thermocouple and cold junction temperatures. In one-shot
+// mode it starts a conversion and waits for it, which takes up to 185ms
+// plus 40ms for every additional averaged sample. If the fault status
+// register is not clear the temperatures are still updated and the Fault is
+// returned.
+func (d *Device) Update(which drivers.Measurement) error {
+	if which&(drivers.Temperature|drivers.Voltage) == 0 {
+		return nil
+	}
+	if d.cr0&cr0CMODE == 0 {
+		if err := d.writeRegister(regCR0, d.cr0|cr0ONESHOT); err != nil {
+			return err
+		}
+		d.waitConversion()
+	}
+
+	// Cold junction, thermocouple and fault status are consecutive.
+	d.tx[0] = regCJTH
+	d.cs.Low()
+	err := d.bus.Tx(d.tx[:], d.rx[:])
+	d.cs.High()
+	if err != nil {
+		return err
+	}
+
+	d.coldJunction = coldJunctionTemp(d.rx[1:3])
+	d.raw = thermocoupleRaw(d.rx[3:6])
+	d.temperature = d.raw * 125 / 16
+	d.fault = Fault(d.rx[6])
+	if d.fault != 0 {
+		return d.fault
+	}
+	return nil
+}
+
+// Temperature returns the linearized thermocouple temperature in m°C, as of
+// the last Update.
+func (d *Device) Temperature() int32 {
+	return d.temperature
+}
+
+// ColdJunction returns the cold junction (chip) temperature in m°C, as of
+// the last Update.
+func (d *Device) ColdJunction() int32 {
+	return d.coldJunction
+}
+
+// Voltage returns the input voltage in µV in the voltage modes, as of the
+// last Update.
+func (d *Device) Voltage() int32 {
+	gain := int64(8)
+	if d.cfg.Type == VoltageGain32 {
+		gain = 32
+	}
+	// V = code / (gain * 1.6 * 2^17)
+	return int32(int64(d.raw) * 625000 / (gain * 131072))
+}
+
+// Fault returns the fault status as of the last Update.
+func (d *Device) Fault() Fault {
+	return d.fault
+}
+
+// ClearFault clears the fault status, which is latched in the default
+// comparator mode once the fault condition is gone.
+func (d *Device) ClearFault() error {
+	d.fault = 0
+	return d.writeRegister(regCR0, d.cr0|cr0FAULTCLR)
+}
+
+// SetColdJunctionOffset sets an offset added to the cold junction
+// temperature, in m°C from -8000 to 7937.
+func (d *Device) SetColdJunctionOffset(offset int32) error {
+	return d.writeRegister(regCJTO, uint8(int8(offset*16/1000)))
+}
+
+// waitConversion waits for a one-shot conversion to complete.
+func (d *Device) waitConversion() {
+	base, extra := 155*time.Millisecond, 33*time.Millisecond
+	if d.cfg.Filter50Hz {
+		base, extra = 185*time.Millisecond, 40*time.Millisecond
+	}
+	n := time.Duration(d.cfg.Averaging)
+	if n > 16 {
+		n = 16
+	}
+	if n > 1 {
+		base += extra * (n - 1)
+	}
+	if d.drdy == machine.NoPin {
+		time.Sleep(base)
+		return
+	}
+	deadline := time.Now().Add(base + 50*time.Millisecond)
+	for d.drdy.Get() && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func (d *Device) writeRegister(reg, value uint8) error {
+	d.tx[0] = reg | regWrite
+	d.tx[1] = value
+	d.cs.Low()
+	err := d.bus.Tx(d.tx[:2], nil)
+	d.tx[1] = 0
+	d.cs.High()
+	return err
+}
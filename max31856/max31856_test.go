@@ -0,0 +1,20 @@
+package max31856
+
+import (
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+)
+
+func TestConversions(t *testing.T) {
+	c := qt.New(t)
+
+	// Examples from tables 5 and 6 of the datasheet.
+	c.Assert(thermocoupleRaw([]byte{0x64, 0x00, 0x00})*125/16, qt.Equals, int32(1600000))
+	c.Assert(thermocoupleRaw([]byte{0x00, 0x01, 0x00})*125/16, qt.Equals, int32(62))
+	c.Assert(thermocoupleRaw([]byte{0xF0, 0x60, 0x00})*125/16, qt.Equals, int32(-250000))
+	c.Assert(coldJunctionTemp([]byte{0x19, 0x00}), qt.Equals, int32(25000))
+	c.Assert(coldJunctionTemp([]byte{0xEC, 0x00}), qt.Equals, int32(-20000))
+
+	c.Assert((FaultOpen | FaultTCRange).Error(), qt.Equals, "max31856: open circuit, thermocouple out of range")
+}
@@ -0,0 +1,34 @@
+package max31856
+
+// Registers. The address is ORed with regWrite for writes.
+const (
+	regCR0    = 0x00
+	regCR1    = 0x01
+	regMASK   = 0x02
+	regCJHF   = 0x03
+	regCJLF   = 0x04
+	regLTHFTH = 0x05
+	regLTHFTL = 0x06
+	regLTLFTH = 0x07
+	regLTLFTL = 0x08
+	regCJTO   = 0x09
+	regCJTH   = 0x0A
+	regCJTL   = 0x0B
+	regLTCBH  = 0x0C
+	regLTCBM  = 0x0D
+	regLTCBL  = 0x0E
+	regSR     = 0x0F
+
+	regWrite = 0x80
+)
+
+// CR0 bits
+const (
+	cr0CMODE    = 0x80 // automatic conversion mode
+	cr0ONESHOT  = 0x40 // one-shot conversion
+	cr0OCFAULT  = 0x30 // open circuit fault detection
+	cr0CJ       = 0x08 // cold junction sensor disabled
+	cr0FAULT    = 0x04 // interrupt fault mode
+	cr0FAULTCLR = 0x02 // clear the fault status
+	cr0FILTER50 = 0x01 // 50Hz noise rejection
+)
@@ -0,0 +1,104 @@
+package max31856
+
+// ThermocoupleType is the type of the thermocouple, or one of the voltage
+// modes.
+type ThermocoupleType uint8
+
+const (
+	TypeK ThermocoupleType = iota // default
+	TypeB
+	TypeE
+	TypeJ
+	TypeN
+	TypeR
+	TypeS
+	TypeT
+	// VoltageGain8 and VoltageGain32 measure the voltage at the inputs
+	// without linearization, see Voltage.
+	VoltageGain8
+	VoltageGain32
+)
+
+// tcTypes are the TC TYPE bits of CR1 for each ThermocoupleType.
+var tcTypes = [...]uint8{3, 0, 1, 2, 4, 5, 6, 7, 8, 12}
+
+// OpenCircuit selects the open circuit detection. The longer tests are
+// needed for thermocouples with a high resistance or a filter on the
+// inputs, see table 4 of the datasheet.
+type OpenCircuit uint8
+
+const (
+	OpenCircuitFast OpenCircuit = iota // default, Rs < 5kΩ
+	OpenCircuitDisabled
+	OpenCircuitMedium // 40kΩ > Rs > 5kΩ, time constant < 2ms
+	OpenCircuitSlow   // 40kΩ > Rs > 5kΩ, time constant > 2ms
+)
+
+// Config holds the converter configuration.
+type Config struct {
+	Type        ThermocoupleType
+	OpenCircuit OpenCircuit
+
+	// Averaging is the number of samples averaged for each result: 1
+	// (default), 2, 4, 8 or 16.
+	Averaging uint8
+
+	// Filter50Hz selects the 50Hz noise rejection filter instead of the 60Hz
+	// one.
+	Filter50Hz bool
+
+	// Continuous enables the automatic conversion mode, with a new result
+	// about every 100ms. Otherwise Update starts a one-shot conversion and
+	// waits for it.
+	Continuous bool
+}
+
+// Fault is the fault status register. Any fault also makes Update return
+// it as an error.
+type Fault uint8
+
+const (
+	FaultOpen             Fault = 0x01 // thermocouple open circuit
+	FaultOverUnderVoltage Fault = 0x02 // input over or under voltage
+	FaultTCLow            Fault = 0x04 // thermocouple below the low threshold
+	FaultTCHigh           Fault = 0x08 // thermocouple above the high threshold
+	FaultCJLow            Fault = 0x10 // cold junction below the low threshold
+	FaultCJHigh           Fault = 0x20 // cold junction above the high threshold
+	FaultTCRange          Fault = 0x40 // thermocouple out of its normal range
+	FaultCJRange          Fault = 0x80 // cold junction out of its normal range
+)
+
+var faultNames = [8]string{
+	"open circuit",
+	"over/under voltage",
+	"thermocouple low",
+	"thermocouple high",
+	"cold junction low",
+	"cold junction high",
+	"thermocouple out of range",
+	"cold junction out of range",
+}
+
+// Error returns the list of faults.
+func (f Fault) Error() string {
+	s := "max31856:"
+	for i, name := range faultNames {
+		if f&(1<<i) != 0 {
+			s += " " + name + ","
+		}
+	}
+	return s[:len(s)-1]
+}
+
+// thermocoupleRaw returns the 19-bit signed linearized temperature of the
+// LTCBH, LTCBM and LTCBL registers, in 1/128°C.
+func thermocoupleRaw(b []byte) int32 {
+	return int32(uint32(b[0])<<24|uint32(b[1])<<16|uint32(b[2])<<8) >> 13
+}
+
+// coldJunctionTemp returns the temperature of the CJTH and CJTL registers in
+// m°C. It has 14 bits, in 1/64°C.
+func coldJunctionTemp(b []byte) int32 {
+	raw := int32(int16(uint16(b[0])<<8|uint16(b[1]))) >> 2
+	return raw * 125 / 8
+}
@@ -0,0 +1,98 @@
+//go:build tinygo
+
+// Package mics5524 provides a driver for the MiCS-5524 MOX gas sensor by
+// SGX Sensortech, as found on the Adafruit MiCS5524 breakout. The sensor
+// reacts to carbon monoxide, ethanol, hydrogen, ammonia and methane, and
+// reports them as a single analog voltage.
+//
+// Use the mox package for baseline tracking and indicative ppm estimates.
+//
+// Datasheet: https://www.sgxsensortech.com/content/uploads/2014/08/1084_Datasheet-MiCS-5524-rev-8.pdf
+package mics5524 // import "tinygo.org/x/drivers/mics5524"
+
+import (
+	"machine"
+	"time"
+
+	"tinygo.org/x/drivers/mox"
+)
+
+// Config holds the configuration of the device.
+type Config struct {
+	// Vref is the reference voltage of the ADC in mV. Defaults to 3300.
+	Vref uint32
+
+	// WarmUp is the time the heater needs before the readings are stable.
+	// Defaults to 3 minutes.
+	WarmUp time.Duration
+}
+
+// Device holds the pins of the sensor.
+type Device struct {
+	adc    machine.ADC
+	enable machine.Pin
+	vref   uint32
+	warmUp mox.WarmUp
+}
+
+// New returns a new MiCS-5524. enable is the EN pin, which switches the heater
+// off when high; it may be machine.NoPin when EN is tied to ground.
+//
+// This function only creates the Device object, it does not touch the device.
+func New(output, enable machine.Pin) *Device {
+	return &Device{
+		adc:    machine.ADC{Pin: output},
+		enable: enable,
+	}
+}
+
+// Configure sets up the pins, switches the heater on and starts the warm-up
+// timer. The ADC must already be initialized with machine.InitADC.
+func (d *Device) Configure(cfg Config) {
+	if cfg.Vref == 0 {
+		cfg.Vref = 3300
+	}
+	if cfg.WarmUp == 0 {
+		cfg.WarmUp = 3 * time.Minute
+	}
+	d.vref = cfg.Vref
+	d.warmUp.Duration = cfg.WarmUp
+	d.adc.Configure(machine.ADCConfig{})
+	if d.enable != machine.NoPin {
+		d.enable.Configure(machine.PinConfig{Mode: machine.PinOutput})
+	}
+	d.SetHeater(true)
+}
+
+// SetHeater switches the heater on or off. The warm-up timer restarts when it
+// is switched on. Without an enable pin the heater is always on and only
+// switching it on has an effect, by restarting the timer.
+func (d *Device) SetHeater(on bool) {
+	if d.enable != machine.NoPin {
+		d.enable.Set(!on)
+	} else if !on {
+		return
+	}
+	if on {
+		d.warmUp.Start(time.Now())
+	} else {
+		d.warmUp.Stop()
+	}
+}
+
+// Ready returns whether the heater has been on long enough for stable
+// readings.
+func (d *Device) Ready() bool {
+	return d.warmUp.Ready(time.Now())
+}
+
+// WarmUpRemaining returns how long the heater still needs to warm up.
+func (d *Device) WarmUpRemaining() time.Duration {
+	return d.warmUp.Remaining(time.Now())
+}
+
+// ReadVoltage returns the output voltage in µV. It rises with the gas
+// concentration.
+func (d *Device) ReadVoltage() uint32 {
+	return voltage(d.adc.Get(), d.vref)
+}
@@ -0,0 +1,6 @@
+package mics5524
+
+// voltage converts a 16 bit ADC reading to µV, for a reference voltage in mV.
+func voltage(raw uint16, vref uint32) uint32 {
+	return uint32(uint64(raw) * uint64(vref) * 1000 / 0xFFFF)
+}
@@ -0,0 +1,16 @@
+package mics5524
+
+import (
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+)
+
+func TestVoltage(t *testing.T) {
+	c := qt.New(t)
+	c.Assert(voltage(0, 3300), qt.Equals, uint32(0))
+	c.Assert(voltage(0xFFFF, 3300), qt.Equals, uint32(3300000))
+	c.Assert(voltage(0x8000, 3300), qt.Equals, uint32(1650025))
+	// Full scale of a 5V board does not overflow.
+	c.Assert(voltage(0xFFFF, 5000), qt.Equals, uint32(5000000))
+}
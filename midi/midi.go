@@ -0,0 +1,187 @@
+// Package midi implements the MIDI 1.0 protocol: the channel voice messages
+// of keyboards, synthesizers and controllers, running status, and system
+// exclusive (SysEx) messages.
+//
+// A Device sends and receives messages on a UART, for the DIN-5 connectors:
+// configure the UART at 31250 baud. The messages can also be carried by USB
+// with the event packets of USB-MIDI, see USBPacket and Parser.FeedUSB.
+//
+// Specification: https://midi.org/midi-1-0-core-specifications
+package midi // import "tinygo.org/x/drivers/midi"
+
+import (
+	"tinygo.org/x/drivers"
+)
+
+// Status bytes of the channel messages, without the channel.
+const (
+	NoteOff         = 0x80
+	NoteOn          = 0x90
+	PolyPressure    = 0xA0
+	ControlChange   = 0xB0
+	ProgramChange   = 0xC0
+	ChannelPressure = 0xD0
+	PitchBend       = 0xE0
+)
+
+// Status bytes of the system messages.
+const (
+	SysExStart    = 0xF0
+	TimeCode      = 0xF1
+	SongPosition  = 0xF2
+	SongSelect    = 0xF3
+	TuneRequest   = 0xF6
+	SysExEnd      = 0xF7
+	TimingClock   = 0xF8
+	Start         = 0xFA
+	Continue      = 0xFB
+	Stop          = 0xFC
+	ActiveSensing = 0xFE
+	SystemReset   = 0xFF
+)
+
+// Message is a MIDI message other than SysEx: a status byte and up to two
+// data bytes.
+type Message struct {
+	Status byte
+	Data1  byte
+	Data2  byte
+}
+
+// Command returns the status byte of a channel message without the
+// channel, or the whole status byte of a system message.
+func (m Message) Command() byte {
+	if m.Status >= 0xF0 {
+		return m.Status
+	}
+	return m.Status & 0xF0
+}
+
+// Channel returns the channel of a channel message, from 0 to 15.
+func (m Message) Channel() uint8 {
+	return m.Status & 0x0F
+}
+
+// Note returns the note number of a note or polyphonic pressure message.
+func (m Message) Note() uint8 {
+	return m.Data1
+}
+
+// Velocity returns the velocity of a note message. A note on message with a
+// velocity of 0 is a note off.
+func (m Message) Velocity() uint8 {
+	return m.Data2
+}
+
+// Controller returns the controller number of a control change message.
+func (m Message) Controller() uint8 {
+	return m.Data1
+}
+
+// Value returns the value of a control change or polyphonic pressure
+// message.
+func (m Message) Value() uint8 {
+	return m.Data2
+}
+
+// Program returns the program number of a program change message.
+func (m Message) Program() uint8 {
+	return m.Data1
+}
+
+// Bend returns the value of a pitch bend message, from -8192 to 8191.
+func (m Message) Bend() int16 {
+	return int16(m.Data1) | int16(m.Data2)<<7 - 8192
+}
+
+// IsNoteOff returns whether the message releases a note: a note off, or a
+// note on with a velocity of 0.
+func (m Message) IsNoteOff() bool {
+	return m.Command() == NoteOff || m.Command() == NoteOn && m.Data2 == 0
+}
+
+// Len returns the length of the message in bytes.
+func (m Message) Len() int {
+	return 1 + dataLen(m.Status)
+}
+
+// dataLen returns the number of data bytes following a status byte.
+func dataLen(status byte) int {
+	switch {
+	case status >= 0xF0:
+		switch status {
+		case TimeCode, SongSelect:
+			return 1
+		case SongPosition:
+			return 2
+		}
+		return 0
+	case status&0xF0 == ProgramChange, status&0xF0 == ChannelPressure:
+		return 1
+	}
+	return 2
+}
+
+// NewNoteOn returns a note on message.
+func NewNoteOn(channel, note, velocity uint8) Message {
+	return Message{NoteOn | channel&0x0F, note & 0x7F, velocity & 0x7F}
+}
+
+// NewNoteOff returns a note off message.
+func NewNoteOff(channel, note, velocity uint8) Message {
+	return Message{NoteOff | channel&0x0F, note & 0x7F, velocity & 0x7F}
+}
+
+// NewControlChange returns a control change message.
+func NewControlChange(channel, controller, value uint8) Message {
+	return Message{ControlChange | channel&0x0F, controller & 0x7F, value & 0x7F}
+}
+
+// NewProgramChange returns a program change message.
+func NewProgramChange(channel, program uint8) Message {
+	return Message{ProgramChange | channel&0x0F, program & 0x7F, 0}
+}
+
+// NewChannelPressure returns a channel pressure (aftertouch) message.
+func NewChannelPressure(channel, pressure uint8) Message {
+	return Message{ChannelPressure | channel&0x0F, pressure & 0x7F, 0}
+}
+
+// NewPolyPressure returns a polyphonic key pressure message.
+func NewPolyPressure(channel, note, pressure uint8) Message {
+	return Message{PolyPressure | channel&0x0F, note & 0x7F, pressure & 0x7F}
+}
+
+// NewPitchBend returns a pitch bend message, with a value from -8192 to
+// 8191.
+func NewPitchBend(channel uint8, value int16) Message {
+	v := uint16(value+8192) & 0x3FFF
+	return Message{PitchBend | channel&0x0F, byte(v & 0x7F), byte(v >> 7)}
+}
+
+// Device sends and receives MIDI messages on a UART.
+type Device struct {
+	uart drivers.UART
+	Parser
+	Writer
+}
+
+// New returns a MIDI port on a UART configured at 31250 baud.
+func New(uart drivers.UART) *Device {
+	return &Device{uart: uart, Writer: Writer{w: uart}}
+}
+
+// Poll reads the bytes received so far, and calls the callbacks of the
+// Parser for the messages they complete. Call it regularly, the UART buffer
+// holds less than 100ms of MIDI data.
+func (d *Device) Poll() error {
+	var buf [16]byte
+	for d.uart.Buffered() > 0 {
+		n, err := d.uart.Read(buf[:])
+		d.Feed(buf[:n])
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
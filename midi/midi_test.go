@@ -0,0 +1,139 @@
+package midi
+
+import (
+	"bytes"
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+)
+
+// fakePort is a UART with the received bytes in rx.
+type fakePort struct {
+	rx bytes.Buffer
+	tx bytes.Buffer
+}
+
+func (p *fakePort) Read(b []byte) (int, error)  { return p.rx.Read(b) }
+func (p *fakePort) Write(b []byte) (int, error) { return p.tx.Write(b) }
+func (p *fakePort) Buffered() int               { return p.rx.Len() }
+
+func TestMessages(t *testing.T) {
+	c := qt.New(t)
+	c.Assert(NewNoteOn(2, 60, 100), qt.Equals, Message{0x92, 60, 100})
+	c.Assert(NewPitchBend(0, 0), qt.Equals, Message{0xE0, 0x00, 0x40})
+	c.Assert(NewPitchBend(0, -8192), qt.Equals, Message{0xE0, 0x00, 0x00})
+	c.Assert(NewPitchBend(0, 8191), qt.Equals, Message{0xE0, 0x7F, 0x7F})
+	c.Assert(NewPitchBend(0, 1000).Bend(), qt.Equals, int16(1000))
+	c.Assert(NewNoteOn(0, 60, 0).IsNoteOff(), qt.IsTrue)
+	c.Assert(NewProgramChange(1, 5).Len(), qt.Equals, 2)
+	c.Assert(Message{Status: TimingClock}.Len(), qt.Equals, 1)
+}
+
+func TestParser(t *testing.T) {
+	c := qt.New(t)
+	var msgs []Message
+	var sysex []byte
+	var chunks int
+	var p Parser
+	p.OnMessage = func(m Message) { msgs = append(msgs, m) }
+	p.OnSysEx = func(data []byte, done bool) {
+		sysex = append(sysex, data...)
+		chunks++
+		if done {
+			sysex = append(sysex, 0xFF)
+		}
+	}
+
+	// Running status, with a clock in the middle of a message.
+	p.Feed([]byte{0x90, 60, 100, 62, 0xF8, 100, 64, 0, 0xC1, 7, 8})
+	c.Assert(msgs, qt.DeepEquals, []Message{
+		{0x90, 60, 100},
+		{TimingClock, 0, 0},
+		{0x90, 62, 100},
+		{0x90, 64, 0},
+		{0xC1, 7, 0},
+		{0xC1, 8, 0},
+	})
+
+	// System common messages cancel the running status.
+	msgs = nil
+	p.Feed([]byte{0xF2, 1, 2, 3, 0xF6})
+	c.Assert(msgs, qt.DeepEquals, []Message{{SongPosition, 1, 2}, {TuneRequest, 0, 0}})
+
+	// SysEx longer than the buffer, ended by SysExEnd, then one ended by a
+	// status byte.
+	msgs = nil
+	p.Feed([]byte{0xF0})
+	for i := 0; i < 70; i++ {
+		p.FeedByte(byte(i))
+	}
+	p.Feed([]byte{0xFE, 0xF7, 0xF0, 1, 2, 0xB0, 7, 127})
+	c.Assert(chunks, qt.Equals, 3)
+	c.Assert(len(sysex), qt.Equals, 70+1+2+1)
+	c.Assert(sysex[69], qt.Equals, byte(69))
+	c.Assert(sysex[70], qt.Equals, byte(0xFF))
+	c.Assert(msgs, qt.DeepEquals, []Message{{ActiveSensing, 0, 0}, {0xB0, 7, 127}})
+}
+
+func TestWriter(t *testing.T) {
+	c := qt.New(t)
+	var buf bytes.Buffer
+	w := NewWriter(&buf)
+	w.Send(NewNoteOn(0, 60, 100))
+	w.Send(NewNoteOn(0, 62, 100))
+	c.Assert(buf.Bytes(), qt.DeepEquals, []byte{0x90, 60, 100, 0x90, 62, 100})
+
+	// The receiver already has the running status of the previous notes.
+	buf.Reset()
+	w.RunningStatus = true
+	w.Send(NewNoteOn(0, 60, 100))
+	w.Send(Message{Status: TimingClock})
+	w.Send(NewNoteOn(0, 60, 0))
+	w.Send(NewProgramChange(0, 3))
+	w.Send(NewProgramChange(0, 4))
+	w.SendSysEx([]byte{0x7D, 1})
+	w.Send(NewProgramChange(0, 5))
+	c.Assert(buf.Bytes(), qt.DeepEquals, []byte{
+		60, 100, 0xF8, 60, 0, 0xC0, 3, 4, 0xF0, 0x7D, 1, 0xF7, 0xC0, 5,
+	})
+}
+
+func TestDevice(t *testing.T) {
+	c := qt.New(t)
+	port := &fakePort{}
+	d := New(port)
+	var got []Message
+	d.OnMessage = func(m Message) { got = append(got, m) }
+	port.rx.Write([]byte{0xB3, 1, 64, 2, 65})
+	c.Assert(d.Poll(), qt.IsNil)
+	c.Assert(got, qt.DeepEquals, []Message{{0xB3, 1, 64}, {0xB3, 2, 65}})
+
+	c.Assert(d.Send(NewNoteOff(3, 60, 64)), qt.IsNil)
+	c.Assert(port.tx.Bytes(), qt.DeepEquals, []byte{0x83, 60, 64})
+}
+
+func TestUSB(t *testing.T) {
+	c := qt.New(t)
+	c.Assert(USBPacket(1, NewNoteOn(0, 60, 100)), qt.Equals, [4]byte{0x19, 0x90, 60, 100})
+	c.Assert(USBPacket(0, NewProgramChange(2, 9)), qt.Equals, [4]byte{0x0C, 0xC2, 9, 0})
+	c.Assert(USBPacket(0, Message{Status: TimingClock}), qt.Equals, [4]byte{0x0F, 0xF8, 0, 0})
+	c.Assert(USBPacket(0, Message{SongSelect, 3, 0}), qt.Equals, [4]byte{0x02, 0xF3, 3, 0})
+
+	packets := AppendUSBSysEx(nil, 0, []byte{0x7D, 1, 2})
+	c.Assert(packets, qt.DeepEquals, []byte{
+		0x04, 0xF0, 0x7D, 1,
+		0x06, 2, 0xF7, 0,
+	})
+	c.Assert(AppendUSBSysEx(nil, 0, nil), qt.DeepEquals, []byte{0x06, 0xF0, 0xF7, 0})
+	c.Assert(AppendUSBSysEx(nil, 0, []byte{1}), qt.DeepEquals, []byte{0x07, 0xF0, 1, 0xF7})
+	c.Assert(len(AppendUSBSysEx(nil, 0, []byte{1, 2})), qt.Equals, 8)
+
+	var p Parser
+	var msgs []Message
+	var sysex []byte
+	p.OnMessage = func(m Message) { msgs = append(msgs, m) }
+	p.OnSysEx = func(data []byte, done bool) { sysex = append(sysex, data...) }
+	p.FeedUSB(append(packets, 0x09, 0x90, 60, 100, 0x0F, 0xF8, 0, 0))
+	c.Assert(sysex, qt.DeepEquals, []byte{0x7D, 1, 2})
+	c.Assert(msgs, qt.DeepEquals, []Message{{0x90, 60, 100}, {TimingClock, 0, 0}})
+}
@@ -0,0 +1,90 @@
+package midi
+
+// Parser decodes a MIDI byte stream, with running status and real-time
+// messages interleaved anywhere, and calls its callbacks for each message.
+type Parser struct {
+	// OnMessage is called for each message other than SysEx.
+	OnMessage func(m Message)
+
+	// OnSysEx is called with the data of SysEx messages, without the start
+	// and end bytes. Long messages are delivered in chunks of up to 64
+	// bytes; done is set on the last one.
+	OnSysEx func(data []byte, done bool)
+
+	status byte // running status, or current system common message
+	data   [2]byte
+	n      int // data bytes received
+	sysex  bool
+	buf    [64]byte
+	bufLen int
+}
+
+// Feed parses the bytes of b.
+func (p *Parser) Feed(b []byte) {
+	for _, c := range b {
+		p.FeedByte(c)
+	}
+}
+
+// FeedByte parses a byte.
+func (p *Parser) FeedByte(c byte) {
+	switch {
+	case c >= 0xF8:
+		// Real-time messages do not interrupt anything.
+		p.emit(Message{Status: c})
+	case c >= 0x80:
+		if p.sysex {
+			// A status byte ends a SysEx message, even without SysExEnd.
+			p.flushSysEx(true)
+		}
+		p.n = 0
+		p.status = 0
+		switch {
+		case c == SysExStart:
+			p.sysex = true
+		case c == SysExEnd:
+		case c >= 0xF0 && dataLen(c) == 0:
+			if c == TuneRequest {
+				p.emit(Message{Status: c})
+			}
+		default:
+			p.status = c
+		}
+	case p.sysex:
+		p.buf[p.bufLen] = c
+		p.bufLen++
+		if p.bufLen == len(p.buf) {
+			p.flushSysEx(false)
+		}
+	case p.status != 0:
+		p.data[p.n] = c
+		p.n++
+		if p.n < dataLen(p.status) {
+			break
+		}
+		m := Message{Status: p.status, Data1: p.data[0]}
+		if p.n == 2 {
+			m.Data2 = p.data[1]
+		}
+		p.n = 0
+		p.emit(m)
+		if p.status >= 0xF0 {
+			// No running status for system common messages.
+			p.status = 0
+		}
+	}
+}
+
+func (p *Parser) emit(m Message) {
+	if p.OnMessage != nil {
+		p.OnMessage(m)
+	}
+}
+
+func (p *Parser) flushSysEx(done bool) {
+	if p.OnSysEx != nil {
+		p.OnSysEx(p.buf[:p.bufLen], done)
+	}
+	p.bufLen = 0
+	p.sysex = !done
+}
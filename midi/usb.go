@@ -0,0 +1,77 @@
+package midi
+
+// USB-MIDI carries the messages in 4 byte event packets: a header with the
+// cable number and a code index number (CIN), which gives the kind of the
+// message, then the bytes of the message padded with zeros. A SysEx message
+// takes several packets of up to 3 bytes.
+//
+// These helpers convert packets for the MIDI port of TinyGo's
+// machine/usb/adc/midi package, or any other USB stack.
+
+// Code index numbers of the SysEx packets.
+const (
+	cinSysEx  = 0x4 // SysEx starts or continues
+	cinSysEx1 = 0x5 // SysEx ends with the next byte, or single byte message
+	cinSysEx2 = 0x6 // SysEx ends with the next two bytes
+	cinSysEx3 = 0x7 // SysEx ends with the next three bytes
+)
+
+// cinLen is the number of message bytes in a packet, by code index number.
+var cinLen = [16]uint8{0, 0, 2, 3, 3, 1, 2, 3, 3, 3, 3, 3, 2, 2, 3, 1}
+
+// USBPacket returns the USB-MIDI event packet of a message, for a cable
+// number from 0 to 15.
+func USBPacket(cable uint8, m Message) [4]byte {
+	var cin byte
+	switch {
+	case m.Status < 0xF0:
+		cin = m.Status >> 4
+	case m.Status >= 0xF8 || m.Status == TuneRequest:
+		cin = 0xF
+	default:
+		cin = byte(m.Len()) // two and three byte system common messages
+	}
+	p := [4]byte{cable<<4 | cin, m.Status, m.Data1, m.Data2}
+	for i := m.Len() + 1; i < 4; i++ {
+		p[i] = 0
+	}
+	return p
+}
+
+// AppendUSBSysEx appends the USB-MIDI event packets of a SysEx message, with
+// the start and end bytes around data, to dst.
+func AppendUSBSysEx(dst []byte, cable uint8, data []byte) []byte {
+	var msg [3]byte
+	n := 0
+	put := func(c byte, last bool) {
+		msg[n] = c
+		n++
+		if n < 3 && !last {
+			return
+		}
+		cin := byte(cinSysEx)
+		if last {
+			cin = cinSysEx1 + byte(n-1)
+		}
+		for i := n; i < 3; i++ {
+			msg[i] = 0
+		}
+		dst = append(dst, cable<<4|cin, msg[0], msg[1], msg[2])
+		n = 0
+	}
+	put(SysExStart, false)
+	for _, c := range data {
+		put(c, false)
+	}
+	put(SysExEnd, true)
+	return dst
+}
+
+// FeedUSB parses USB-MIDI event packets, of 4 bytes each, from any cable.
+func (p *Parser) FeedUSB(packets []byte) {
+	for len(packets) >= 4 {
+		n := cinLen[packets[0]&0x0F]
+		p.Feed(packets[1 : 1+n])
+		packets = packets[4:]
+	}
+}
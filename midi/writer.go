@@ -0,0 +1,61 @@
+package midi
+
+import (
+	"io"
+)
+
+// Writer encodes MIDI messages to a byte stream.
+type Writer struct {
+	// RunningStatus omits the status byte of a channel message when it is
+	// the same as the one of the previous message, which makes streams of
+	// notes and controller changes a third shorter. Some receivers do not
+	// support it.
+	RunningStatus bool
+
+	w       io.Writer
+	running byte
+	buf     [3]byte
+}
+
+// NewWriter returns a Writer on w.
+func NewWriter(w io.Writer) *Writer {
+	return &Writer{w: w}
+}
+
+// Send writes a message.
+func (w *Writer) Send(m Message) error {
+	buf := w.buf[:0]
+	switch {
+	case m.Status >= 0xF8:
+		// Real-time messages do not change the running status.
+	case m.Status >= 0xF0:
+		w.running = 0
+	case !w.RunningStatus || m.Status != w.running:
+		w.running = m.Status
+	default:
+		// Same status as the previous message.
+		buf = append(buf, m.Data1, m.Data2)[:m.Len()-1]
+		_, err := w.w.Write(buf)
+		return err
+	}
+	buf = append(buf, m.Status, m.Data1, m.Data2)[:m.Len()]
+	_, err := w.w.Write(buf)
+	return err
+}
+
+// SendSysEx writes a SysEx message, with the start and end bytes around
+// data. The data bytes must be lower than 0x80, the first one is usually
+// the manufacturer ID.
+func (w *Writer) SendSysEx(data []byte) error {
+	w.running = 0
+	w.buf[0] = SysExStart
+	if _, err := w.w.Write(w.buf[:1]); err != nil {
+		return err
+	}
+	if _, err := w.w.Write(data); err != nil {
+		return err
+	}
+	w.buf[0] = SysExEnd
+	_, err := w.w.Write(w.buf[:1])
+	return err
+}
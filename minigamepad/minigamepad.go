@@ -0,0 +1,187 @@
+// Package minigamepad implements a driver for the Adafruit Mini I2C
+// STEMMA QT Gamepad, which has a seesaw microcontroller reading an analog
+// stick and six buttons.
+//
+// The state is normalized like the wii package: Buttons uses the same bit
+// layout and the stick is scaled to the int16 range.
+//
+// Product page: https://learn.adafruit.com/gamepad-qt
+package minigamepad // import "tinygo.org/x/drivers/minigamepad"
+
+import (
+	"errors"
+	"time"
+
+	"tinygo.org/x/drivers"
+)
+
+// The I2C address which this device listens to by default.
+const Address = 0x50
+
+var errNotFound = errors.New("minigamepad: device not found")
+
+// Buttons, in the same bit layout as the wii package.
+const (
+	ButtonA      = 1 << 0
+	ButtonB      = 1 << 1
+	ButtonX      = 1 << 2
+	ButtonY      = 1 << 3
+	ButtonSelect = 1 << 8
+	ButtonStart  = 1 << 9
+)
+
+// seesaw modules and registers.
+const (
+	moduleStatus  = 0x00
+	moduleGPIO    = 0x01
+	moduleADC     = 0x09
+	statusHWID    = 0x01
+	statusSWReset = 0x7F
+	gpioDirClr    = 0x03
+	gpioBulk      = 0x04
+	gpioBulkSet   = 0x05
+	gpioPullEnSet = 0x0B
+	adcChannel0   = 0x07
+	hwidATtinyMin = 0x84 // ATtiny806, the first of the ATtiny8x6/8x7/16x6/16x7 IDs
+	hwidATtinyMax = 0x89 // ATtiny1617
+)
+
+// Button pins on the seesaw.
+var buttonPins = [...]struct {
+	pin    uint8
+	button uint16
+}{
+	{5, ButtonA}, {1, ButtonB}, {6, ButtonX}, {2, ButtonY},
+	{0, ButtonSelect}, {16, ButtonStart},
+}
+
+// Stick pins on the seesaw.
+const (
+	pinStickX = 14
+	pinStickY = 15
+)
+
+// State is the normalized state of the gamepad.
+type State struct {
+	Buttons uint16
+
+	// Stick from -32767 to 32767, positive to the right and up.
+	X, Y int16
+}
+
+// Pressed returns whether all the buttons in mask are pressed.
+func (s State) Pressed(mask uint16) bool {
+	return s.Buttons&mask == mask
+}
+
+// Device wraps an I2C connection to a mini gamepad.
+type Device struct {
+	bus     drivers.I2C
+	Address uint8
+	mask    uint32
+	State   State
+}
+
+// New creates a new connection. The I2C bus must already be configured.
+//
+// This function only creates the Device object, it does not touch the device.
+func New(bus drivers.I2C) *Device {
+	var mask uint32
+	for _, b := range buttonPins {
+		mask |= 1 << b.pin
+	}
+	return &Device{bus: bus, Address: Address, mask: mask}
+}
+
+// Configure resets the seesaw and sets up the button pins.
+func (d *Device) Configure() error {
+	if err := d.write(moduleStatus, statusSWReset, []byte{0xFF}); err != nil {
+		return err
+	}
+	time.Sleep(10 * time.Millisecond)
+
+	var id [1]byte
+	if err := d.read(moduleStatus, statusHWID, id[:]); err != nil {
+		return err
+	}
+	if id[0] < hwidATtinyMin || id[0] > hwidATtinyMax {
+		return errNotFound
+	}
+
+	mask := []byte{byte(d.mask >> 24), byte(d.mask >> 16), byte(d.mask >> 8), byte(d.mask)}
+	if err := d.write(moduleGPIO, gpioDirClr, mask); err != nil {
+		return err
+	}
+	if err := d.write(moduleGPIO, gpioPullEnSet, mask); err != nil {
+		return err
+	}
+	// Pull-up rather than pull-down.
+	return d.write(moduleGPIO, gpioBulkSet, mask)
+}
+
+// Update reads the buttons and stick and updates State.
+func (d *Device) Update() error {
+	var data [4]byte
+	if err := d.read(moduleGPIO, gpioBulk, data[:]); err != nil {
+		return err
+	}
+	pins := uint32(data[0])<<24 | uint32(data[1])<<16 | uint32(data[2])<<8 | uint32(data[3])
+
+	var s State
+	for _, b := range buttonPins {
+		// Buttons are active low.
+		if pins&(1<<b.pin) == 0 {
+			s.Buttons |= b.button
+		}
+	}
+
+	x, err := d.readADC(pinStickX)
+	if err != nil {
+		return err
+	}
+	y, err := d.readADC(pinStickY)
+	if err != nil {
+		return err
+	}
+	// Both axes are inverted on the board.
+	s.X = stick(511 - int32(x))
+	s.Y = stick(511 - int32(y))
+	d.State = s
+	return nil
+}
+
+func (d *Device) readADC(pin uint8) (uint16, error) {
+	var data [2]byte
+	if err := d.read(moduleADC, adcChannel0+pin, data[:]); err != nil {
+		return 0, err
+	}
+	return uint16(data[0])<<8 | uint16(data[1]), nil
+}
+
+// stick scales a centered 10 bit value to the int16 range.
+func stick(v int32) int16 {
+	v = v * 32767 / 511
+	if v > 32767 {
+		v = 32767
+	} else if v < -32767 {
+		v = -32767
+	}
+	return int16(v)
+}
+
+func (d *Device) read(module, reg uint8, data []byte) error {
+	if err := d.bus.Tx(uint16(d.Address), []byte{module, reg}, nil); err != nil {
+		return err
+	}
+	// The seesaw needs time to prepare the response.
+	time.Sleep(time.Millisecond)
+	return d.bus.Tx(uint16(d.Address), nil, data)
+}
+
+func (d *Device) write(module, reg uint8, data []byte) error {
+	buf := make([]byte, 2+len(data))
+	buf[0] = module
+	buf[1] = reg
+	copy(buf[2:], data)
+	return d.bus.Tx(uint16(d.Address), buf, nil)
+}
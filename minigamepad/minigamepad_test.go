@@ -0,0 +1,90 @@
+package minigamepad
+
+import (
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+	"tinygo.org/x/drivers/tester"
+)
+
+// fakeSeesaw is a seesaw, whose registers are addressed by a module and a
+// register and are read after the address is written.
+type fakeSeesaw struct {
+	*tester.I2CDevice8
+	c    *qt.C
+	regs map[[2]uint8][]byte
+	addr [2]uint8
+}
+
+func newFakeSeesaw(c *qt.C) *fakeSeesaw {
+	return &fakeSeesaw{
+		I2CDevice8: tester.NewI2CDevice8(c, Address),
+		c:          c,
+		regs:       map[[2]uint8][]byte{},
+	}
+}
+
+func (f *fakeSeesaw) Tx(w, r []byte) error {
+	switch {
+	case len(w) == 2 && len(r) == 0:
+		f.addr = [2]uint8{w[0], w[1]}
+	case len(w) > 2 && len(r) == 0:
+		f.regs[[2]uint8{w[0], w[1]}] = append([]byte(nil), w[2:]...)
+	case len(w) == 0:
+		copy(r, f.regs[f.addr])
+	default:
+		f.c.Fatalf("unexpected Tx(% x, %d)", w, len(r))
+	}
+	return nil
+}
+
+// setADC sets the 10 bit reading of an ADC pin.
+func (f *fakeSeesaw) setADC(pin uint8, v uint16) {
+	f.regs[[2]uint8{moduleADC, adcChannel0 + pin}] = []byte{byte(v >> 8), byte(v)}
+}
+
+func TestConfigure(t *testing.T) {
+	c := qt.New(t)
+	bus := tester.NewI2CBus(c)
+	fake := newFakeSeesaw(c)
+	bus.AddDevice(fake)
+	dev := New(bus)
+
+	c.Assert(dev.Configure(), qt.Equals, errNotFound)
+
+	fake.regs[[2]uint8{moduleStatus, statusHWID}] = []byte{0x87}
+	c.Assert(dev.Configure(), qt.IsNil)
+	c.Assert(fake.regs[[2]uint8{moduleStatus, statusSWReset}], qt.DeepEquals, []byte{0xFF})
+	// Pins 0, 1, 2, 5, 6 and 16 are inputs with pull-ups.
+	mask := []byte{0x00, 0x01, 0x00, 0x67}
+	c.Assert(fake.regs[[2]uint8{moduleGPIO, gpioDirClr}], qt.DeepEquals, mask)
+	c.Assert(fake.regs[[2]uint8{moduleGPIO, gpioPullEnSet}], qt.DeepEquals, mask)
+	c.Assert(fake.regs[[2]uint8{moduleGPIO, gpioBulkSet}], qt.DeepEquals, mask)
+}
+
+func TestUpdate(t *testing.T) {
+	c := qt.New(t)
+	bus := tester.NewI2CBus(c)
+	fake := newFakeSeesaw(c)
+	bus.AddDevice(fake)
+	dev := New(bus)
+
+	// A (pin 5) and Start (pin 16) pressed, the buttons are active low.
+	fake.regs[[2]uint8{moduleGPIO, gpioBulk}] = []byte{0xFF, 0xFE, 0xFF, 0xDF}
+	// The stick pushed fully right and down, the axes are inverted.
+	fake.setADC(pinStickX, 0)
+	fake.setADC(pinStickY, 1023)
+	c.Assert(dev.Update(), qt.IsNil)
+	c.Assert(dev.State.Buttons, qt.Equals, uint16(ButtonA|ButtonStart))
+	c.Assert(dev.State.Pressed(ButtonA|ButtonStart), qt.IsTrue)
+	c.Assert(dev.State.Pressed(ButtonA|ButtonB), qt.IsFalse)
+	c.Assert(dev.State.X, qt.Equals, int16(32767))
+	c.Assert(dev.State.Y, qt.Equals, int16(-32767))
+
+	// Released and centered.
+	fake.regs[[2]uint8{moduleGPIO, gpioBulk}] = []byte{0xFF, 0xFF, 0xFF, 0xFF}
+	fake.setADC(pinStickX, 511)
+	fake.setADC(pinStickY, 400)
+	c.Assert(dev.Update(), qt.IsNil)
+	c.Assert(dev.State, qt.Equals, State{X: 0, Y: 111 * 32767 / 511})
+}
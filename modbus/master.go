@@ -0,0 +1,227 @@
+package modbus
+
+import (
+	"time"
+
+	"tinygo.org/x/drivers"
+)
+
+// Master sends requests to Modbus RTU slaves and waits for their response.
+// Only one request is in flight at a time, so a Master must not be used
+// concurrently.
+type Master struct {
+	port drivers.UART
+
+	// Timeout is the time to wait for a complete response. Defaults to 1
+	// second if zero.
+	Timeout time.Duration
+
+	// TurnaroundDelay is the time given to the slaves to process a
+	// broadcast request before the next request is sent. Defaults to 100ms
+	// if zero.
+	TurnaroundDelay time.Duration
+
+	buf [maxADU]byte
+}
+
+// NewMaster returns a master on a configured serial port, usually an RS485
+// transceiver from the rs485 package.
+func NewMaster(port drivers.UART) *Master {
+	return &Master{port: port}
+}
+
+// ReadCoils reads len(dst) coils starting at addr.
+func (m *Master) ReadCoils(slave uint8, addr uint16, dst []bool) error {
+	return m.readBits(FuncReadCoils, slave, addr, dst)
+}
+
+// ReadDiscreteInputs reads len(dst) discrete inputs starting at addr.
+func (m *Master) ReadDiscreteInputs(slave uint8, addr uint16, dst []bool) error {
+	return m.readBits(FuncReadDiscreteInputs, slave, addr, dst)
+}
+
+// ReadHoldingRegisters reads len(dst) holding registers starting at addr.
+func (m *Master) ReadHoldingRegisters(slave uint8, addr uint16, dst []uint16) error {
+	return m.readRegisters(FuncReadHoldingRegisters, slave, addr, dst)
+}
+
+// ReadInputRegisters reads len(dst) input registers starting at addr.
+func (m *Master) ReadInputRegisters(slave uint8, addr uint16, dst []uint16) error {
+	return m.readRegisters(FuncReadInputRegisters, slave, addr, dst)
+}
+
+// WriteSingleCoil turns a coil on or off.
+func (m *Master) WriteSingleCoil(slave uint8, addr uint16, on bool) error {
+	value := uint16(0)
+	if on {
+		value = 0xFF00
+	}
+	return m.write(m.header(slave, FuncWriteSingleCoil, addr, value))
+}
+
+// WriteSingleRegister writes a holding register.
+func (m *Master) WriteSingleRegister(slave uint8, addr uint16, value uint16) error {
+	return m.write(m.header(slave, FuncWriteSingleRegister, addr, value))
+}
+
+// WriteMultipleCoils writes len(values) coils starting at addr.
+func (m *Master) WriteMultipleCoils(slave uint8, addr uint16, values []bool) error {
+	if len(values) == 0 || len(values) > 1968 {
+		return errCount
+	}
+	n := (len(values) + 7) / 8
+	req := m.header(slave, FuncWriteMultipleCoils, addr, uint16(len(values)))
+	req = append(req, byte(n))
+	for i := 0; i < n; i++ {
+		req = append(req, 0)
+	}
+	for i, v := range values {
+		if v {
+			req[7+i/8] |= 1 << (i % 8)
+		}
+	}
+	return m.write(req)
+}
+
+// WriteMultipleRegisters writes len(values) holding registers starting at
+// addr.
+func (m *Master) WriteMultipleRegisters(slave uint8, addr uint16, values []uint16) error {
+	if len(values) == 0 || len(values) > 123 {
+		return errCount
+	}
+	req := m.header(slave, FuncWriteMultipleRegisters, addr, uint16(len(values)))
+	req = append(req, byte(2*len(values)))
+	for _, v := range values {
+		req = append(req, byte(v>>8), byte(v))
+	}
+	return m.write(req)
+}
+
+func (m *Master) readBits(fn, slave uint8, addr uint16, dst []bool) error {
+	if len(dst) == 0 || len(dst) > 2000 {
+		return errCount
+	}
+	n := (len(dst) + 7) / 8
+	resp, err := m.transaction(m.header(slave, fn, addr, uint16(len(dst))), 3+n)
+	if err != nil || resp == nil {
+		return err
+	}
+	if resp[2] != byte(n) {
+		return errResponse
+	}
+	for i := range dst {
+		dst[i] = resp[3+i/8]&(1<<(i%8)) != 0
+	}
+	return nil
+}
+
+func (m *Master) readRegisters(fn, slave uint8, addr uint16, dst []uint16) error {
+	if len(dst) == 0 || len(dst) > 125 {
+		return errCount
+	}
+	resp, err := m.transaction(m.header(slave, fn, addr, uint16(len(dst))), 3+2*len(dst))
+	if err != nil || resp == nil {
+		return err
+	}
+	if resp[2] != byte(2*len(dst)) {
+		return errResponse
+	}
+	for i := range dst {
+		dst[i] = uint16(resp[3+2*i])<<8 | uint16(resp[4+2*i])
+	}
+	return nil
+}
+
+// write sends a write request, which is answered with its first 6 bytes:
+// the whole request for single writes, the address and quantity for
+// multiple writes.
+func (m *Master) write(req []byte) error {
+	var sent [6]byte
+	copy(sent[:], req)
+	resp, err := m.transaction(req, 6)
+	if err != nil || resp == nil {
+		return err
+	}
+	if string(resp[:6]) != string(sent[:]) {
+		return errResponse
+	}
+	return nil
+}
+
+// header starts a request with the two 16-bit fields most functions have.
+func (m *Master) header(slave, fn uint8, a, b uint16) []byte {
+	return append(m.buf[:0], slave, fn, byte(a>>8), byte(a), byte(b>>8), byte(b))
+}
+
+// transaction sends a request and reads a response of n bytes without the
+// CRC. Broadcast requests have no response, and return a nil response.
+func (m *Master) transaction(req []byte, n int) ([]byte, error) {
+	slave, fn := req[0], req[1]
+	req = appendCRC(req)
+
+	// A late response to a previous request would be taken for this one.
+	var discard [16]byte
+	for m.port.Buffered() > 0 {
+		m.port.Read(discard[:])
+	}
+	if _, err := m.port.Write(req); err != nil {
+		return nil, err
+	}
+	if slave == BroadcastAddress {
+		delay := m.TurnaroundDelay
+		if delay == 0 {
+			delay = 100 * time.Millisecond
+		}
+		time.Sleep(delay)
+		return nil, nil
+	}
+
+	timeout := m.Timeout
+	if timeout == 0 {
+		timeout = time.Second
+	}
+	deadline := time.Now().Add(timeout)
+	resp := m.buf[:0]
+	// An exception response is 5 bytes long, which is shorter than any
+	// normal response, so read that much first.
+	if err := m.read(&resp, 5, deadline); err != nil {
+		return nil, err
+	}
+	if resp[0] != slave || resp[1]&0x7F != fn {
+		return nil, errResponse
+	}
+	if resp[1]&0x80 != 0 {
+		if !checkCRC(resp) {
+			return nil, errCRC
+		}
+		return nil, ExceptionError(resp[2])
+	}
+	if err := m.read(&resp, n+2, deadline); err != nil {
+		return nil, err
+	}
+	if !checkCRC(resp) {
+		return nil, errCRC
+	}
+	return resp, nil
+}
+
+// read reads into buf until it is n bytes long.
+func (m *Master) read(buf *[]byte, n int, deadline time.Time) error {
+	b := *buf
+	for len(b) < n {
+		if m.port.Buffered() == 0 {
+			if time.Now().After(deadline) {
+				return errTimeout
+			}
+			drivers.Yield()
+			continue
+		}
+		k, err := m.port.Read(b[len(b):n])
+		if err != nil {
+			return err
+		}
+		b = b[:len(b)+k]
+	}
+	*buf = b
+	return nil
+}
@@ -0,0 +1,76 @@
+package modbus
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	qt "github.com/frankban/quicktest"
+)
+
+// fakePort answers requests with canned responses, keyed by the request
+// without its CRC.
+type fakePort struct {
+	responses map[string][]byte
+	requests  [][]byte
+	rx        bytes.Buffer
+}
+
+func (p *fakePort) Write(b []byte) (int, error) {
+	p.requests = append(p.requests, append([]byte(nil), b...))
+	if resp, ok := p.responses[string(b[:len(b)-2])]; ok {
+		p.rx.Write(appendCRC(append([]byte(nil), resp...)))
+	}
+	return len(b), nil
+}
+
+func (p *fakePort) Read(b []byte) (int, error) {
+	return p.rx.Read(b)
+}
+
+func (p *fakePort) Buffered() int {
+	return p.rx.Len()
+}
+
+func TestCRC16(t *testing.T) {
+	c := qt.New(t)
+	c.Assert(appendCRC([]byte{0x01, 0x03, 0x00, 0x00, 0x00, 0x01}), qt.DeepEquals,
+		[]byte{0x01, 0x03, 0x00, 0x00, 0x00, 0x01, 0x84, 0x0A})
+}
+
+func TestMaster(t *testing.T) {
+	c := qt.New(t)
+
+	port := &fakePort{responses: map[string][]byte{
+		"\x11\x03\x00\x6B\x00\x03":                     {0x11, 0x03, 0x06, 0x02, 0x2B, 0x00, 0x00, 0x00, 0x64},
+		"\x11\x01\x00\x13\x00\x0A":                     {0x11, 0x01, 0x02, 0xCD, 0x01},
+		"\x11\x06\x00\x01\x00\x03":                     {0x11, 0x06, 0x00, 0x01, 0x00, 0x03},
+		"\x11\x10\x00\x01\x00\x02\x04\x00\x0A\x01\x02": {0x11, 0x10, 0x00, 0x01, 0x00, 0x02},
+		"\x11\x04\x00\x08\x00\x01":                     {0x11, 0x84, 0x02},
+	}}
+	m := NewMaster(port)
+	m.Timeout = 10 * time.Millisecond
+
+	regs := make([]uint16, 3)
+	c.Assert(m.ReadHoldingRegisters(0x11, 0x6B, regs), qt.IsNil)
+	c.Assert(regs, qt.DeepEquals, []uint16{0x022B, 0, 0x64})
+
+	coils := make([]bool, 10)
+	c.Assert(m.ReadCoils(0x11, 0x13, coils), qt.IsNil)
+	c.Assert(coils, qt.DeepEquals, []bool{true, false, true, true, false, false, true, true, true, false})
+
+	c.Assert(m.WriteSingleRegister(0x11, 1, 3), qt.IsNil)
+	c.Assert(m.WriteMultipleRegisters(0x11, 1, []uint16{0x000A, 0x0102}), qt.IsNil)
+
+	err := m.ReadInputRegisters(0x11, 8, regs[:1])
+	c.Assert(err, qt.Equals, ExceptionError(IllegalDataAddress))
+	c.Assert(err.Error(), qt.Equals, "modbus: illegal data address")
+
+	c.Assert(m.ReadInputRegisters(0x12, 8, regs[:1]), qt.Equals, errTimeout)
+	c.Assert(m.ReadHoldingRegisters(0x11, 0, make([]uint16, 126)), qt.Equals, errCount)
+
+	m.TurnaroundDelay = time.Millisecond
+	c.Assert(m.WriteSingleCoil(BroadcastAddress, 2, true), qt.IsNil)
+	last := port.requests[len(port.requests)-1]
+	c.Assert(last[:6], qt.DeepEquals, []byte{0, 0x05, 0, 2, 0xFF, 0})
+}
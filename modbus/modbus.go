@@ -0,0 +1,97 @@
+// Package modbus implements the Modbus RTU protocol, used by industrial
+// sensors, energy meters and variable frequency drives, over a serial line
+// such as an RS485 bus. A Master queries such devices, and a Slave makes a
+// TinyGo device one of them, over a serial line or a Modbus TCP connection.
+//
+// Modbus TCP: https://modbus.org/docs/Modbus_Messaging_Implementation_Guide_V1_0b.pdf
+//
+// Specification: https://modbus.org/docs/Modbus_Application_Protocol_V1_1b3.pdf
+// Serial line: https://modbus.org/docs/Modbus_over_serial_line_V1_02.pdf
+package modbus // import "tinygo.org/x/drivers/modbus"
+
+import (
+	"errors"
+	"strconv"
+)
+
+var (
+	errTimeout  = errors.New("modbus: timeout")
+	errCRC      = errors.New("modbus: CRC mismatch")
+	errResponse = errors.New("modbus: unexpected response")
+	errCount    = errors.New("modbus: invalid quantity")
+)
+
+// Function codes.
+const (
+	FuncReadCoils              = 0x01
+	FuncReadDiscreteInputs     = 0x02
+	FuncReadHoldingRegisters   = 0x03
+	FuncReadInputRegisters     = 0x04
+	FuncWriteSingleCoil        = 0x05
+	FuncWriteSingleRegister    = 0x06
+	FuncWriteMultipleCoils     = 0x0F
+	FuncWriteMultipleRegisters = 0x10
+)
+
+// Exception codes.
+const (
+	IllegalFunction     = 0x01
+	IllegalDataAddress  = 0x02
+	IllegalDataValue    = 0x03
+	ServerDeviceFailure = 0x04
+	Acknowledge         = 0x05
+	ServerDeviceBusy    = 0x06
+)
+
+// BroadcastAddress is received by all slaves, which do not answer it.
+const BroadcastAddress = 0
+
+// maxADU is the maximum size of a frame: address, PDU and CRC.
+const maxADU = 256
+
+// ExceptionError is the exception code of an exception response.
+type ExceptionError uint8
+
+var exceptionNames = [...]string{
+	IllegalFunction:     "illegal function",
+	IllegalDataAddress:  "illegal data address",
+	IllegalDataValue:    "illegal data value",
+	ServerDeviceFailure: "server device failure",
+	Acknowledge:         "acknowledge",
+	ServerDeviceBusy:    "server device busy",
+}
+
+func (e ExceptionError) Error() string {
+	if int(e) < len(exceptionNames) && exceptionNames[e] != "" {
+		return "modbus: " + exceptionNames[e]
+	}
+	return "modbus: exception " + strconv.Itoa(int(e))
+}
+
+// CRC16 returns the Modbus CRC of data. It is sent low byte first.
+func CRC16(data []byte) uint16 {
+	crc := uint16(0xFFFF)
+	for _, b := range data {
+		crc ^= uint16(b)
+		for i := 0; i < 8; i++ {
+			if crc&1 != 0 {
+				crc = crc>>1 ^ 0xA001
+			} else {
+				crc >>= 1
+			}
+		}
+	}
+	return crc
+}
+
+// appendCRC appends the CRC of frame to it.
+func appendCRC(frame []byte) []byte {
+	crc := CRC16(frame)
+	return append(frame, byte(crc), byte(crc>>8))
+}
+
+// checkCRC returns whether the frame ends with a valid CRC.
+func checkCRC(frame []byte) bool {
+	n := len(frame)
+	return n >= 4 && CRC16(frame[:n-2]) == uint16(frame[n-2])|uint16(frame[n-1])<<8
+}
@@ -0,0 +1,307 @@
+package modbus
+
+import (
+	"encoding/binary"
+	"io"
+	"time"
+
+	"tinygo.org/x/drivers"
+)
+
+// Table is one of the four data tables of a slave.
+type Table uint8
+
+const (
+	Coils Table = iota
+	DiscreteInputs
+	InputRegisters
+	HoldingRegisters
+)
+
+type bitBank struct {
+	start uint16
+	bits  []bool
+}
+
+type regBank struct {
+	start uint16
+	regs  []uint16
+}
+
+// Slave answers Modbus requests from the data of banks of registers and
+// coils registered by the application, over a serial line with PollRTU or
+// over a TCP connection with ServeTCP.
+//
+// The banks are slices owned by the application, which updates the inputs
+// and reads the values written by the master directly. They are not locked:
+// when they are used by other goroutines, do it between calls to PollRTU,
+// or use OnWrite.
+type Slave struct {
+	// Address is the slave address on a serial line, from 1 to 247.
+	Address uint8
+
+	// FrameGap is the silence that ends a frame on a serial line. The
+	// specification requires 3.5 characters, but at least 1.75ms. Defaults
+	// to 4ms if zero, which is right at 9600 baud and works at higher baud
+	// rates.
+	FrameGap time.Duration
+
+	// OnWrite is called when a request changed coils or holding registers,
+	// before the response is sent.
+	OnWrite func(table Table, addr, count uint16)
+
+	coils    []bitBank
+	discrete []bitBank
+	input    []regBank
+	holding  []regBank
+
+	// Serial line state of PollRTU.
+	rx       [maxADU]byte
+	rxLen    int
+	lastByte time.Time
+	tx       [maxADU]byte
+}
+
+// NewSlave returns a slave with the given serial line address and no data.
+func NewSlave(address uint8) *Slave {
+	return &Slave{Address: address}
+}
+
+// AddCoils makes bits available as the coils starting at start.
+func (s *Slave) AddCoils(start uint16, bits []bool) {
+	s.coils = append(s.coils, bitBank{start, bits})
+}
+
+// AddDiscreteInputs makes bits available as the discrete inputs starting at
+// start.
+func (s *Slave) AddDiscreteInputs(start uint16, bits []bool) {
+	s.discrete = append(s.discrete, bitBank{start, bits})
+}
+
+// AddInputRegisters makes regs available as the input registers starting at
+// start.
+func (s *Slave) AddInputRegisters(start uint16, regs []uint16) {
+	s.input = append(s.input, regBank{start, regs})
+}
+
+// AddHoldingRegisters makes regs available as the holding registers
+// starting at start.
+func (s *Slave) AddHoldingRegisters(start uint16, regs []uint16) {
+	s.holding = append(s.holding, regBank{start, regs})
+}
+
+// PollRTU reads the bytes received on a serial port, and answers the
+// request once a complete frame addressed to this slave has been received.
+// It returns immediately, and must be called in a loop at least every
+// FrameGap, as the end of a frame is detected by the silence after it.
+// Frames with a wrong CRC are ignored, as required.
+func (s *Slave) PollRTU(port drivers.UART) error {
+	gap := s.FrameGap
+	if gap == 0 {
+		gap = 4 * time.Millisecond
+	}
+	now := time.Now()
+	if port.Buffered() > 0 {
+		if s.rxLen == len(s.rx) {
+			// Too long to be a frame, drop it.
+			s.rxLen = 0
+		}
+		n, err := port.Read(s.rx[s.rxLen:])
+		s.rxLen += n
+		s.lastByte = now
+		return err
+	}
+	if s.rxLen == 0 || now.Sub(s.lastByte) < gap {
+		return nil
+	}
+
+	frame := s.rx[:s.rxLen]
+	s.rxLen = 0
+	if len(frame) < 4 || !checkCRC(frame) {
+		return nil
+	}
+	addr := frame[0]
+	if addr != s.Address && addr != BroadcastAddress {
+		return nil
+	}
+	resp := append(s.tx[:0], addr)
+	resp = s.handle(frame[1:len(frame)-2], resp)
+	if addr == BroadcastAddress {
+		return nil
+	}
+	_, err := port.Write(appendCRC(resp))
+	return err
+}
+
+// ServeTCP answers Modbus TCP requests on a connection until it fails or is
+// closed. Requests for the unit identifiers 0 and 255, or for Address, are
+// answered.
+func (s *Slave) ServeTCP(conn io.ReadWriter) error {
+	var req, resp [7 + maxADU]byte
+	for {
+		// MBAP header: transaction, protocol, length and unit identifier.
+		if _, err := io.ReadFull(conn, req[:7]); err != nil {
+			return err
+		}
+		length := int(binary.BigEndian.Uint16(req[4:6]))
+		if length < 2 || length > maxADU {
+			return errResponse
+		}
+		if _, err := io.ReadFull(conn, req[7:6+length]); err != nil {
+			return err
+		}
+		unit := req[6]
+		if binary.BigEndian.Uint16(req[2:4]) != 0 || (unit != 0 && unit != 0xFF && unit != s.Address) {
+			continue
+		}
+		out := append(resp[:0], req[:7]...)
+		out = s.handle(req[7:6+length], out)
+		binary.BigEndian.PutUint16(out[4:6], uint16(len(out)-6))
+		if _, err := conn.Write(out); err != nil {
+			return err
+		}
+	}
+}
+
+// handle answers the request PDU pdu, appending the response PDU to resp.
+func (s *Slave) handle(pdu []byte, resp []byte) []byte {
+	if len(pdu) < 1 {
+		return resp
+	}
+	fn := pdu[0]
+	switch fn {
+	case FuncReadCoils, FuncReadDiscreteInputs, FuncReadHoldingRegisters, FuncReadInputRegisters,
+		FuncWriteSingleCoil, FuncWriteSingleRegister, FuncWriteMultipleCoils, FuncWriteMultipleRegisters:
+		// All of them start with an address and a count or value.
+		if len(pdu) < 5 {
+			return append(resp, fn|0x80, IllegalDataValue)
+		}
+	default:
+		return append(resp, fn|0x80, IllegalFunction)
+	}
+	addr := binary.BigEndian.Uint16(pdu[1:3])
+	count := binary.BigEndian.Uint16(pdu[3:5])
+
+	switch fn {
+	case FuncReadCoils, FuncReadDiscreteInputs:
+		banks := s.coils
+		if fn == FuncReadDiscreteInputs {
+			banks = s.discrete
+		}
+		if count == 0 || count > 2000 {
+			return append(resp, fn|0x80, IllegalDataValue)
+		}
+		bits := findBits(banks, addr, count)
+		if bits == nil {
+			return append(resp, fn|0x80, IllegalDataAddress)
+		}
+		n := (len(bits) + 7) / 8
+		resp = append(resp, fn, byte(n))
+		start := len(resp)
+		for i := 0; i < n; i++ {
+			resp = append(resp, 0)
+		}
+		for i, b := range bits {
+			if b {
+				resp[start+i/8] |= 1 << (i % 8)
+			}
+		}
+		return resp
+
+	case FuncReadHoldingRegisters, FuncReadInputRegisters:
+		banks := s.holding
+		if fn == FuncReadInputRegisters {
+			banks = s.input
+		}
+		if count == 0 || count > 125 {
+			return append(resp, fn|0x80, IllegalDataValue)
+		}
+		regs := findRegs(banks, addr, count)
+		if regs == nil {
+			return append(resp, fn|0x80, IllegalDataAddress)
+		}
+		resp = append(resp, fn, byte(2*len(regs)))
+		for _, r := range regs {
+			resp = append(resp, byte(r>>8), byte(r))
+		}
+		return resp
+
+	case FuncWriteSingleCoil:
+		if count != 0xFF00 && count != 0 {
+			return append(resp, fn|0x80, IllegalDataValue)
+		}
+		bits := findBits(s.coils, addr, 1)
+		if bits == nil {
+			return append(resp, fn|0x80, IllegalDataAddress)
+		}
+		bits[0] = count == 0xFF00
+		s.written(Coils, addr, 1)
+		return append(resp, pdu[:5]...)
+
+	case FuncWriteSingleRegister:
+		regs := findRegs(s.holding, addr, 1)
+		if regs == nil {
+			return append(resp, fn|0x80, IllegalDataAddress)
+		}
+		regs[0] = count
+		s.written(HoldingRegisters, addr, 1)
+		return append(resp, pdu[:5]...)
+
+	case FuncWriteMultipleCoils:
+		if count == 0 || count > 1968 || len(pdu) < 6 || int(pdu[5]) != (int(count)+7)/8 || len(pdu) < 6+int(pdu[5]) {
+			return append(resp, fn|0x80, IllegalDataValue)
+		}
+		bits := findBits(s.coils, addr, count)
+		if bits == nil {
+			return append(resp, fn|0x80, IllegalDataAddress)
+		}
+		for i := range bits {
+			bits[i] = pdu[6+i/8]&(1<<(i%8)) != 0
+		}
+		s.written(Coils, addr, count)
+		return append(resp, pdu[:5]...)
+
+	case FuncWriteMultipleRegisters:
+		if count == 0 || count > 123 || len(pdu) < 6 || int(pdu[5]) != 2*int(count) || len(pdu) < 6+int(pdu[5]) {
+			return append(resp, fn|0x80, IllegalDataValue)
+		}
+		regs := findRegs(s.holding, addr, count)
+		if regs == nil {
+			return append(resp, fn|0x80, IllegalDataAddress)
+		}
+		for i := range regs {
+			regs[i] = binary.BigEndian.Uint16(pdu[6+2*i:])
+		}
+		s.written(HoldingRegisters, addr, count)
+		return append(resp, pdu[:5]...)
+	}
+	return append(resp, fn|0x80, IllegalFunction)
+}
+
+func (s *Slave) written(table Table, addr, count uint16) {
+	if s.OnWrite != nil {
+		s.OnWrite(table, addr, count)
+	}
+}
+
+// findBits returns the count bits starting at addr, if they are all in one
+// bank.
+func findBits(banks []bitBank, addr, count uint16) []bool {
+	for _, b := range banks {
+		if addr >= b.start && int(addr-b.start)+int(count) <= len(b.bits) {
+			return b.bits[addr-b.start : int(addr-b.start)+int(count)]
+		}
+	}
+	return nil
+}
+
+// findRegs returns the count registers starting at addr, if they are all in
+// one bank.
+func findRegs(banks []regBank, addr, count uint16) []uint16 {
+	for _, b := range banks {
+		if addr >= b.start && int(addr-b.start)+int(count) <= len(b.regs) {
+			return b.regs[addr-b.start : int(addr-b.start)+int(count)]
+		}
+	}
+	return nil
+}
@@ -0,0 +1,126 @@
+package modbus
+
+import (
+	"bytes"
+	"io"
+	"testing"
+	"time"
+
+	qt "github.com/frankban/quicktest"
+)
+
+// linePort is a serial line on which frames are received and sent.
+type linePort struct {
+	rx bytes.Buffer
+	tx bytes.Buffer
+}
+
+func (p *linePort) Read(b []byte) (int, error)  { return p.rx.Read(b) }
+func (p *linePort) Write(b []byte) (int, error) { return p.tx.Write(b) }
+func (p *linePort) Buffered() int               { return p.rx.Len() }
+
+func newTestSlave() (*Slave, []bool, []uint16) {
+	s := NewSlave(0x11)
+	s.FrameGap = time.Millisecond
+	coils := make([]bool, 16)
+	holding := []uint16{0x022B, 0, 0x64}
+	s.AddCoils(0x10, coils)
+	s.AddDiscreteInputs(0, []bool{true, false, true})
+	s.AddInputRegisters(8, []uint16{10, 20})
+	s.AddHoldingRegisters(0x6B, holding)
+	return s, coils, holding
+}
+
+func TestSlaveHandle(t *testing.T) {
+	c := qt.New(t)
+	s, coils, holding := newTestSlave()
+
+	var written []uint16
+	s.OnWrite = func(table Table, addr, count uint16) {
+		written = append(written, uint16(table), addr, count)
+	}
+
+	for _, tc := range []struct {
+		req, resp string
+	}{
+		{"\x03\x00\x6B\x00\x03", "\x03\x06\x02\x2B\x00\x00\x00\x64"},
+		{"\x04\x00\x09\x00\x01", "\x04\x02\x00\x14"},
+		{"\x02\x00\x00\x00\x03", "\x02\x01\x05"},
+		{"\x05\x00\x12\xFF\x00", "\x05\x00\x12\xFF\x00"},
+		{"\x0F\x00\x13\x00\x0A\x02\xCD\x01", "\x0F\x00\x13\x00\x0A"},
+		{"\x01\x00\x10\x00\x10", "\x01\x02\x6C\x0E"},
+		{"\x06\x00\x6C\x00\x03", "\x06\x00\x6C\x00\x03"},
+		{"\x10\x00\x6B\x00\x02\x04\x00\x0A\x01\x02", "\x10\x00\x6B\x00\x02"},
+		// Exceptions.
+		{"\x03\x00\x6C\x00\x03", "\x83\x02"},
+		{"\x03\x00\x6B\x00\x00", "\x83\x03"},
+		{"\x05\x00\x10\x12\x34", "\x85\x03"},
+		{"\x10\x00\x6B\x00\x02\x03\x00\x0A\x01", "\x90\x03"},
+		{"\x2B\x0E\x01\x00\x00", "\xAB\x01"},
+		{"\x07", "\x87\x01"},
+		{"\x03\x00\x6B", "\x83\x03"},
+	} {
+		c.Check(string(s.handle([]byte(tc.req), nil)), qt.Equals, tc.resp, qt.Commentf("request % X", tc.req))
+	}
+	c.Assert(coils[2], qt.IsTrue)
+	c.Assert(coils[3:13], qt.DeepEquals, []bool{true, false, true, true, false, false, true, true, true, false})
+	c.Assert(holding, qt.DeepEquals, []uint16{10, 0x0102, 0x64})
+	c.Assert(written, qt.DeepEquals, []uint16{
+		uint16(Coils), 0x12, 1,
+		uint16(Coils), 0x13, 10,
+		uint16(HoldingRegisters), 0x6C, 1,
+		uint16(HoldingRegisters), 0x6B, 2,
+	})
+}
+
+func TestSlaveRTU(t *testing.T) {
+	c := qt.New(t)
+	s, _, holding := newTestSlave()
+	port := &linePort{}
+
+	poll := func() {
+		c.Assert(s.PollRTU(port), qt.IsNil)
+		time.Sleep(2 * time.Millisecond)
+		c.Assert(s.PollRTU(port), qt.IsNil)
+	}
+
+	// Request for another slave, then with a bad CRC: no response.
+	port.rx.Write(appendCRC([]byte{0x12, 0x03, 0x00, 0x6B, 0x00, 0x01}))
+	poll()
+	port.rx.Write([]byte{0x11, 0x03, 0x00, 0x6B, 0x00, 0x01, 0x00, 0x00})
+	poll()
+	c.Assert(port.tx.Len(), qt.Equals, 0)
+
+	port.rx.Write(appendCRC([]byte{0x11, 0x03, 0x00, 0x6B, 0x00, 0x01}))
+	poll()
+	c.Assert(port.tx.Bytes(), qt.DeepEquals, appendCRC([]byte{0x11, 0x03, 0x02, 0x02, 0x2B}))
+	port.tx.Reset()
+
+	// Broadcast writes are applied but not answered.
+	port.rx.Write(appendCRC([]byte{0x00, 0x06, 0x00, 0x6D, 0x12, 0x34}))
+	poll()
+	c.Assert(port.tx.Len(), qt.Equals, 0)
+	c.Assert(holding[2], qt.Equals, uint16(0x1234))
+}
+
+// tcpConn is a connection with requests to read and responses written.
+type tcpConn struct {
+	bytes.Buffer
+	out bytes.Buffer
+}
+
+func (c *tcpConn) Write(b []byte) (int, error) { return c.out.Write(b) }
+
+func TestSlaveTCP(t *testing.T) {
+	c := qt.New(t)
+	s, _, _ := newTestSlave()
+
+	conn := &tcpConn{}
+	conn.Buffer.WriteString("\x00\x01\x00\x00\x00\x06\xFF\x03\x00\x6B\x00\x02")
+	conn.Buffer.WriteString("\x00\x02\x00\x00\x00\x06\x07\x03\x00\x6B\x00\x02") // other unit
+	conn.Buffer.WriteString("\x00\x03\x00\x00\x00\x06\x11\x04\x00\x00\x00\x01")
+	c.Assert(s.ServeTCP(conn), qt.Equals, io.EOF)
+	c.Assert(conn.out.String(), qt.Equals,
+		"\x00\x01\x00\x00\x00\x07\xFF\x03\x04\x02\x2B\x00\x00"+
+			"\x00\x03\x00\x00\x00\x03\x11\x84\x02")
+}
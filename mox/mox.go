@@ -0,0 +1,162 @@
+// Package mox contains helpers for metal oxide (MOX) gas sensors, such as the
+// GM-x02B sensors of the Grove multichannel gas sensor and the MiCS-5524.
+//
+// MOX sensors report a resistance that changes with the concentration of
+// gases around them, but also with temperature, humidity and age. These
+// helpers track the resistance in clean air (the baseline, R0) and convert
+// the ratio between the current resistance (Rs) and R0 into an estimated
+// concentration using a curve read from the datasheet.
+//
+// The estimated concentrations are indicative only. Without a calibration
+// against a reference instrument, they are good for detecting changes and
+// trends, not for absolute measurements.
+package mox // import "tinygo.org/x/drivers/mox"
+
+import (
+	"math"
+	"time"
+)
+
+// Resistance returns the resistance of the sensor in ohms, for a sensor in
+// series with a load resistor of load ohms, powered with vcc and with vout
+// measured across the load resistor. vout and vcc must use the same unit.
+func Resistance(vout, vcc, load uint32) uint32 {
+	if vout == 0 {
+		return math.MaxUint32
+	}
+	if vout >= vcc {
+		return 0
+	}
+	return uint32(uint64(load) * uint64(vcc-vout) / uint64(vout))
+}
+
+// Baseline tracks the resistance of a sensor in clean air. It follows rising
+// resistance quickly and falling resistance slowly, as reducing gases lower
+// the resistance for short periods while drift changes it over hours or
+// days.
+//
+// For oxidizing gases such as NO2, which raise the resistance, set Inverted.
+type Baseline struct {
+	// Rise and Fall are the time constants to follow a rising and a falling
+	// resistance. They default to 1 minute and 24 hours.
+	Rise time.Duration
+	Fall time.Duration
+
+	// Inverted swaps the role of Rise and Fall.
+	Inverted bool
+
+	r0   float32
+	last time.Time
+}
+
+// Update adds a resistance reading taken at time now and returns the new
+// baseline. The first reading sets the baseline directly.
+func (b *Baseline) Update(r uint32, now time.Time) uint32 {
+	if b.last.IsZero() {
+		b.r0 = float32(r)
+		b.last = now
+		return r
+	}
+	dt := now.Sub(b.last)
+	b.last = now
+
+	rise, fall := b.Rise, b.Fall
+	if rise == 0 {
+		rise = time.Minute
+	}
+	if fall == 0 {
+		fall = 24 * time.Hour
+	}
+	if b.Inverted {
+		rise, fall = fall, rise
+	}
+	tau := fall
+	if float32(r) > b.r0 {
+		tau = rise
+	}
+	alpha := float32(dt) / float32(tau+dt)
+	b.r0 += alpha * (float32(r) - b.r0)
+	return uint32(b.r0)
+}
+
+// R0 returns the current baseline in ohms.
+func (b *Baseline) R0() uint32 {
+	return uint32(b.r0)
+}
+
+// Set sets the baseline, for example to a value stored before the last
+// power cycle or measured during a calibration in clean air.
+func (b *Baseline) Set(r0 uint32, now time.Time) {
+	b.r0 = float32(r0)
+	b.last = now
+}
+
+// Curve approximates the sensitivity curve of a sensor for one gas as a power
+// law, ppm = A * (Rs/R0)^B, which is a straight line on the log-log graphs
+// found in datasheets.
+type Curve struct {
+	A float32
+	B float32
+}
+
+// Indicative curves, fitted to the graphs in the datasheets.
+var (
+	// MiCS5524CO is carbon monoxide for the MiCS-5524, valid from 1 to 1000ppm.
+	MiCS5524CO = Curve{A: 4.4, B: -1.18}
+
+	// GM702BCO is carbon monoxide for the GM-702B, valid from 5 to 5000ppm.
+	GM702BCO = Curve{A: 8.3, B: -1.54}
+
+	// GM102BNO2 is nitrogen dioxide for the GM-102B, valid from 0.1 to 10ppm.
+	GM102BNO2 = Curve{A: 0.22, B: 1.03}
+)
+
+// PPM returns the estimated concentration in ppm (parts per million) for a
+// sensor resistance rs and a baseline r0.
+func (c Curve) PPM(rs, r0 uint32) float32 {
+	if r0 == 0 {
+		return 0
+	}
+	ratio := float64(rs) / float64(r0)
+	return c.A * float32(math.Pow(ratio, float64(c.B)))
+}
+
+// WarmUp tracks whether the heater of a sensor has been on long enough for
+// stable readings.
+type WarmUp struct {
+	// Duration is the time the heater needs. MOX sensors usually need a few
+	// minutes after a power cycle, and up to 48 hours on first use.
+	Duration time.Duration
+
+	start time.Time
+	on    bool
+}
+
+// Start records that the heater has been switched on at time now.
+func (w *WarmUp) Start(now time.Time) {
+	w.start = now
+	w.on = true
+}
+
+// Stop records that the heater has been switched off.
+func (w *WarmUp) Stop() {
+	w.on = false
+}
+
+// Ready returns whether the heater has been on for at least Duration.
+func (w *WarmUp) Ready(now time.Time) bool {
+	return w.on && now.Sub(w.start) >= w.Duration
+}
+
+// Remaining returns how long the heater still needs, 0 once it is ready.
+// It returns Duration if the heater is off.
+func (w *WarmUp) Remaining(now time.Time) time.Duration {
+	if !w.on {
+		return w.Duration
+	}
+	left := w.Duration - now.Sub(w.start)
+	if left < 0 {
+		return 0
+	}
+	return left
+}
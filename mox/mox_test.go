@@ -0,0 +1,59 @@
+package mox
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	qt "github.com/frankban/quicktest"
+)
+
+func TestResistance(t *testing.T) {
+	c := qt.New(t)
+	c.Assert(Resistance(1650, 3300, 10000), qt.Equals, uint32(10000))
+	c.Assert(Resistance(1100, 3300, 10000), qt.Equals, uint32(20000))
+	c.Assert(Resistance(0, 3300, 10000), qt.Equals, uint32(math.MaxUint32))
+	c.Assert(Resistance(3300, 3300, 10000), qt.Equals, uint32(0))
+}
+
+func TestBaseline(t *testing.T) {
+	c := qt.New(t)
+	start := time.Now()
+	b := Baseline{Rise: time.Minute, Fall: 24 * time.Hour}
+	c.Assert(b.Update(100000, start), qt.Equals, uint32(100000))
+
+	// A short drop in resistance caused by gas hardly moves the baseline.
+	now := start
+	for i := 0; i < 60; i++ {
+		now = now.Add(time.Second)
+		b.Update(20000, now)
+	}
+	c.Assert(b.R0() > 99000, qt.IsTrue, qt.Commentf("R0 = %d", b.R0()))
+
+	// A rise is followed within a few time constants.
+	for i := 0; i < 300; i++ {
+		now = now.Add(time.Second)
+		b.Update(120000, now)
+	}
+	c.Assert(b.R0() > 119000, qt.IsTrue, qt.Commentf("R0 = %d", b.R0()))
+}
+
+func TestCurve(t *testing.T) {
+	c := qt.New(t)
+	curve := Curve{A: 10, B: -1}
+	c.Assert(curve.PPM(50000, 100000), qt.Equals, float32(20))
+	c.Assert(curve.PPM(100000, 0), qt.Equals, float32(0))
+}
+
+func TestWarmUp(t *testing.T) {
+	c := qt.New(t)
+	start := time.Now()
+	w := WarmUp{Duration: time.Minute}
+	c.Assert(w.Ready(start), qt.IsFalse)
+	c.Assert(w.Remaining(start), qt.Equals, time.Minute)
+	w.Start(start)
+	c.Assert(w.Remaining(start.Add(20*time.Second)), qt.Equals, 40*time.Second)
+	c.Assert(w.Ready(start.Add(time.Minute)), qt.IsTrue)
+	w.Stop()
+	c.Assert(w.Ready(start.Add(time.Minute)), qt.IsFalse)
+}
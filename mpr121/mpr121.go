@@ -0,0 +1,233 @@
+// Package mpr121 implements a driver for the MPR121 12-channel capacitive
+// touch controller.
+//
+// Datasheet: https://www.nxp.com/docs/en/data-sheet/MPR121.pdf
+package mpr121 // import "tinygo.org/x/drivers/mpr121"
+
+import (
+	"errors"
+	"sync/atomic"
+
+	"tinygo.org/x/drivers"
+	"tinygo.org/x/drivers/internal/legacy"
+)
+
+var errNotFound = errors.New("mpr121: device not found")
+
+// Config holds the electrode configuration.
+type Config struct {
+	// Electrodes is the number of electrodes to enable, starting at ELE0.
+	// Defaults to all 12.
+	Electrodes uint8
+
+	// TouchThreshold and ReleaseThreshold are applied to all electrodes,
+	// in counts below the baseline. Defaults to 12 and 6. Use
+	// SetThresholds to configure electrodes individually.
+	TouchThreshold   uint8
+	ReleaseThreshold uint8
+
+	// SupplyVoltage in mV, used to derive the auto-configuration limits.
+	// Defaults to 3300.
+	SupplyVoltage uint32
+
+	// DisableAutoConfig disables the automatic charge current and time
+	// search for every electrode.
+	DisableAutoConfig bool
+}
+
+// Device wraps an I2C connection to a MPR121 device.
+type Device struct {
+	bus        drivers.I2C
+	Address    uint8
+	electrodes uint8
+	ecr        uint8
+
+	irq     bool // interrupt enabled
+	pending uint32
+	last    uint16
+
+	onTouch   func(electrode uint8)
+	onRelease func(electrode uint8)
+}
+
+// New creates a new MPR121 connection. The I2C bus must already be
+// configured.
+//
+// This function only creates the Device object, it does not touch the device.
+func New(bus drivers.I2C) *Device {
+	return &Device{bus: bus, Address: Address}
+}
+
+// Configure resets the device and starts measuring.
+func (d *Device) Configure(cfg Config) error {
+	if cfg.Electrodes == 0 || cfg.Electrodes > Electrodes {
+		cfg.Electrodes = Electrodes
+	}
+	if cfg.TouchThreshold == 0 {
+		cfg.TouchThreshold = 12
+	}
+	if cfg.ReleaseThreshold == 0 {
+		cfg.ReleaseThreshold = 6
+	}
+	if cfg.SupplyVoltage == 0 {
+		cfg.SupplyVoltage = 3300
+	}
+	d.electrodes = cfg.Electrodes
+	d.ecr = 0 // the device is in stop mode after reset
+
+	if err := d.write(SOFTRESET, 0x63); err != nil {
+		return err
+	}
+	// CONFIG2 reads 0x24 after reset.
+	v, err := d.read(CONFIG2)
+	if err != nil {
+		return err
+	}
+	if v != 0x24 {
+		return errNotFound
+	}
+
+	for i := uint8(0); i < Electrodes; i++ {
+		if err := d.SetThresholds(i, cfg.TouchThreshold, cfg.ReleaseThreshold); err != nil {
+			return err
+		}
+	}
+
+	// Baseline filter settings from application note AN3944.
+	regs := [...][2]uint8{
+		{MHDR, 0x01}, {NHDR, 0x01}, {NCLR, 0x0E}, {FDLR, 0x00},
+		{MHDF, 0x01}, {NHDF, 0x05}, {NCLF, 0x01}, {FDLF, 0x00},
+		{NHDT, 0x00}, {NCLT, 0x00}, {FDLT, 0x00},
+		{DEBOUNCE, 0x00},
+		{CONFIG1, 0x10}, // 16µA charge current
+		{CONFIG2, 0x20}, // 0.5µs encoding, 1ms period
+	}
+	for _, r := range regs {
+		if err := d.write(r[0], r[1]); err != nil {
+			return err
+		}
+	}
+
+	if !cfg.DisableAutoConfig {
+		// Limits from AN3889: USL = (Vdd - 0.7) / Vdd * 256,
+		// TL = USL * 0.9, LSL = USL * 0.65.
+		usl := (cfg.SupplyVoltage - 700) * 256 / cfg.SupplyVoltage
+		regs := [...][2]uint8{
+			{UPLIMIT, uint8(usl)},
+			{TARGETLIMIT, uint8(usl * 9 / 10)},
+			{LOWLIMIT, uint8(usl * 65 / 100)},
+			{AUTOCONFIG0, 0x0B}, // 6 samples, retry, auto-reconfigure, auto-configure
+		}
+		for _, r := range regs {
+			if err := d.write(r[0], r[1]); err != nil {
+				return err
+			}
+		}
+	}
+
+	// Start in run mode with baseline tracking initialized from the first
+	// measurement.
+	d.ecr = 0x80 | cfg.Electrodes
+	return d.write(ECR, d.ecr)
+}
+
+// SetThresholds sets the touch and release thresholds of an electrode.
+func (d *Device) SetThresholds(electrode, touch, release uint8) error {
+	// Thresholds can only be written in stop mode.
+	if d.ecr != 0 {
+		if err := d.write(ECR, 0); err != nil {
+			return err
+		}
+	}
+	if err := d.write(TOUCH_THRESH_0+2*electrode, touch); err != nil {
+		return err
+	}
+	if err := d.write(RELEASE_THRESH_0+2*electrode, release); err != nil {
+		return err
+	}
+	if d.ecr != 0 {
+		return d.write(ECR, d.ecr)
+	}
+	return nil
+}
+
+// Calibrate restarts the baseline tracking and auto-configuration, for
+// example after the electrode surroundings have changed. No electrode should
+// be touched while calibrating.
+func (d *Device) Calibrate() error {
+	if err := d.write(ECR, 0); err != nil {
+		return err
+	}
+	return d.write(ECR, d.ecr)
+}
+
+// Touched returns the touch status of all electrodes as a bitmask.
+func (d *Device) Touched() (uint16, error) {
+	var data [2]byte
+	if err := legacy.ReadRegister(d.bus, d.Address, TOUCH_STATUS_L, data[:]); err != nil {
+		return 0, err
+	}
+	return uint16(data[0]) | uint16(data[1]&0x0F)<<8, nil
+}
+
+// FilteredData returns the 10 bit filtered measurement of an electrode.
+func (d *Device) FilteredData(electrode uint8) (uint16, error) {
+	var data [2]byte
+	if err := legacy.ReadRegister(d.bus, d.Address, FILTERED_DATA_0+2*electrode, data[:]); err != nil {
+		return 0, err
+	}
+	return uint16(data[0]) | uint16(data[1]&0x03)<<8, nil
+}
+
+// Baseline returns the 10 bit baseline value of an electrode. Only the upper
+// 8 bits are stored by the device.
+func (d *Device) Baseline(electrode uint8) (uint16, error) {
+	v, err := d.read(BASELINE_0 + electrode)
+	return uint16(v) << 2, err
+}
+
+// SetCallbacks sets the functions called by Update when an electrode is
+// touched or released. Either may be nil.
+func (d *Device) SetCallbacks(touched, released func(electrode uint8)) {
+	d.onTouch = touched
+	d.onRelease = released
+}
+
+// Update reads the touch status and calls the touched and released callbacks
+// for every electrode that changed. It should be called regularly from the
+// main loop; the callbacks are never called from an interrupt. When an IRQ
+// pin is enabled, the bus is only accessed after the pin fired.
+func (d *Device) Update() error {
+	if d.irq && atomic.SwapUint32(&d.pending, 0) == 0 {
+		return nil
+	}
+	touched, err := d.Touched()
+	if err != nil {
+		return err
+	}
+	changed := touched ^ d.last
+	d.last = touched
+	for i := uint8(0); i < Electrodes; i++ {
+		if changed&(1<<i) == 0 {
+			continue
+		}
+		if touched&(1<<i) != 0 {
+			if d.onTouch != nil {
+				d.onTouch(i)
+			}
+		} else if d.onRelease != nil {
+			d.onRelease(i)
+		}
+	}
+	return nil
+}
+
+func (d *Device) read(reg uint8) (uint8, error) {
+	var data [1]byte
+	err := legacy.ReadRegister(d.bus, d.Address, reg, data[:])
+	return data[0], err
+}
+
+func (d *Device) write(reg, value uint8) error {
+	return legacy.WriteRegister(d.bus, d.Address, reg, []byte{value})
+}
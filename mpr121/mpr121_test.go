@@ -0,0 +1,81 @@
+package mpr121
+
+import (
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+	"tinygo.org/x/drivers/tester"
+)
+
+func TestUpdate(t *testing.T) {
+	c := qt.New(t)
+	bus := tester.NewI2CBus(c)
+	fake := bus.NewDevice(Address)
+	fake.Registers[CONFIG2] = 0x24
+
+	dev := New(bus)
+	c.Assert(dev.Configure(Config{Electrodes: 8}), qt.IsNil)
+	c.Assert(fake.Registers[ECR], qt.Equals, uint8(0x88))
+	c.Assert(fake.Registers[TOUCH_THRESH_0+2*11], qt.Equals, uint8(12))
+	c.Assert(fake.Registers[RELEASE_THRESH_0+2*11], qt.Equals, uint8(6))
+	// USL = (3300 - 700) / 3300 * 256.
+	c.Assert(fake.Registers[UPLIMIT], qt.Equals, uint8(201))
+
+	var touched, released []uint8
+	dev.SetCallbacks(func(e uint8) {
+		touched = append(touched, e)
+	}, func(e uint8) {
+		released = append(released, e)
+	})
+
+	// ELE0, ELE3 and ELE9 touched; the over-current flag in bit 15 is not
+	// an electrode.
+	fake.Registers[TOUCH_STATUS_L] = 0x09
+	fake.Registers[TOUCH_STATUS_H] = 0x82
+	mask, err := dev.Touched()
+	c.Assert(err, qt.IsNil)
+	c.Assert(mask, qt.Equals, uint16(0x0209))
+	c.Assert(dev.Update(), qt.IsNil)
+	c.Assert(touched, qt.DeepEquals, []uint8{0, 3, 9})
+	c.Assert(released, qt.IsNil)
+
+	// ELE3 released, ELE11 touched.
+	touched = nil
+	fake.Registers[TOUCH_STATUS_L] = 0x01
+	fake.Registers[TOUCH_STATUS_H] = 0x0A
+	c.Assert(dev.Update(), qt.IsNil)
+	c.Assert(touched, qt.DeepEquals, []uint8{11})
+	c.Assert(released, qt.DeepEquals, []uint8{3})
+
+	// Nothing changed.
+	touched, released = nil, nil
+	c.Assert(dev.Update(), qt.IsNil)
+	c.Assert(touched, qt.IsNil)
+	c.Assert(released, qt.IsNil)
+}
+
+func TestNotFound(t *testing.T) {
+	c := qt.New(t)
+	bus := tester.NewI2CBus(c)
+	bus.NewDevice(Address)
+
+	dev := New(bus)
+	c.Assert(dev.Configure(Config{}), qt.Equals, errNotFound)
+}
+
+func TestFilteredData(t *testing.T) {
+	c := qt.New(t)
+	bus := tester.NewI2CBus(c)
+	fake := bus.NewDevice(Address)
+	fake.Registers[FILTERED_DATA_0+2*5] = 0x34
+	fake.Registers[FILTERED_DATA_0+2*5+1] = 0xFE // only 2 bits are used
+	fake.Registers[BASELINE_0+5] = 0x8C
+
+	dev := New(bus)
+	v, err := dev.FilteredData(5)
+	c.Assert(err, qt.IsNil)
+	c.Assert(v, qt.Equals, uint16(0x234))
+	v, err = dev.Baseline(5)
+	c.Assert(err, qt.IsNil)
+	c.Assert(v, qt.Equals, uint16(0x230))
+}
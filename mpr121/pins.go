@@ -0,0 +1,19 @@
+//go:build tinygo
+
+package mpr121
+
+import (
+	"machine"
+	"sync/atomic"
+)
+
+// EnableInterrupt uses the IRQ pin to avoid reading the touch status when
+// nothing changed. The pin is active low and open drain.
+func (d *Device) EnableInterrupt(irq machine.Pin) error {
+	d.irq = true
+	atomic.StoreUint32(&d.pending, 1)
+	irq.Configure(machine.PinConfig{Mode: machine.PinInputPullup})
+	return irq.SetInterrupt(machine.PinFalling, func(machine.Pin) {
+		atomic.StoreUint32(&d.pending, 1)
+	})
+}
@@ -0,0 +1,41 @@
+package mpr121
+
+// The I2C address which this device listens to by default (ADDR pin to
+// ground). Other options are 0x5B, 0x5C and 0x5D.
+const Address = 0x5A
+
+// Registers.
+const (
+	TOUCH_STATUS_L   = 0x00
+	TOUCH_STATUS_H   = 0x01
+	OOR_STATUS_L     = 0x02
+	OOR_STATUS_H     = 0x03
+	FILTERED_DATA_0  = 0x04 // two bytes per electrode
+	BASELINE_0       = 0x1E // one byte per electrode
+	MHDR             = 0x2B
+	NHDR             = 0x2C
+	NCLR             = 0x2D
+	FDLR             = 0x2E
+	MHDF             = 0x2F
+	NHDF             = 0x30
+	NCLF             = 0x31
+	FDLF             = 0x32
+	NHDT             = 0x33
+	NCLT             = 0x34
+	FDLT             = 0x35
+	TOUCH_THRESH_0   = 0x41 // touch and release thresholds interleaved
+	RELEASE_THRESH_0 = 0x42
+	DEBOUNCE         = 0x5B
+	CONFIG1          = 0x5C
+	CONFIG2          = 0x5D
+	ECR              = 0x5E
+	AUTOCONFIG0      = 0x7B
+	AUTOCONFIG1      = 0x7C
+	UPLIMIT          = 0x7D
+	LOWLIMIT         = 0x7E
+	TARGETLIMIT      = 0x7F
+	SOFTRESET        = 0x80
+)
+
+// Electrodes is the number of electrodes.
+const Electrodes = 12
@@ -33,6 +33,23 @@ func (d Device) Connected() bool {
 	return data[0] == 0x68
 }
 
+// DriverName returns "mpu6050".
+func (d Device) DriverName() string {
+	return "mpu6050"
+}
+
+// ChipID returns the content of the WHO_AM_I register, 0x68 for a MPU6050.
+func (d Device) ChipID() (uint32, error) {
+	data := []byte{0}
+	err := legacy.ReadRegister(d.bus, uint8(d.Address), WHO_AM_I, data)
+	return uint32(data[0]), err
+}
+
+// RevisionID returns 0, the MPU6050 has no documented revision register.
+func (d Device) RevisionID() (uint32, error) {
+	return 0, nil
+}
+
 // Configure sets up the device for communication.
 func (d Device) Configure() error {
 	return d.SetClockSource(CLOCK_INTERNAL)
@@ -0,0 +1,104 @@
+package mpu6050
+
+import (
+	"math"
+	"time"
+
+	"tinygo.org/x/drivers"
+	"tinygo.org/x/drivers/internal/legacy"
+)
+
+// selfTestSamples is the number of samples averaged for each half of the
+// self test.
+const selfTestSamples = 20
+
+// SelfTest runs the built-in self test of the accelerometer and the gyroscope,
+// as described in section 4 of the register map. The sensor must be kept
+// still during the test, which takes around 200ms.
+//
+// For every axis the result contains the deviation of the self test response
+// from the factory trim value in ‰. The datasheet accepts deviations up to
+// ±14%. The full scale ranges are restored afterwards.
+func (d Device) SelfTest() (drivers.SelfTestResult, error) {
+	var cfg [2]byte
+	err := legacy.ReadRegister(d.bus, uint8(d.Address), GYRO_CONFIG, cfg[:])
+	if err != nil {
+		return nil, err
+	}
+	defer legacy.WriteRegister(d.bus, uint8(d.Address), GYRO_CONFIG, cfg[:])
+
+	// Self test is specified at ±8g and ±250°/s.
+	normal, err := d.selfTestAverage(0x00, 0x10)
+	if err != nil {
+		return nil, err
+	}
+	enabled, err := d.selfTestAverage(0xE0, 0xF0)
+	if err != nil {
+		return nil, err
+	}
+
+	var st [4]byte
+	err = legacy.ReadRegister(d.bus, uint8(d.Address), SELF_TEST_X, st[:])
+	if err != nil {
+		return nil, err
+	}
+
+	var result drivers.SelfTestResult
+	names := [6]string{"accel X", "accel Y", "accel Z", "gyro X", "gyro Y", "gyro Z"}
+	for i := 0; i < 6; i++ {
+		var ft float64
+		if i < 3 {
+			// 5 bit code, split over SELF_TEST_[XYZ] and SELF_TEST_A.
+			code := (st[i]>>3)&0x1C | (st[3]>>(4-2*i))&0x03
+			if code != 0 {
+				ft = 4096 * 0.34 * math.Pow(0.92/0.34, (float64(code)-1)/30)
+			}
+		} else {
+			code := st[i-3] & 0x1F
+			if code != 0 {
+				ft = 25 * 131 * math.Pow(1.046, float64(code)-1)
+			}
+			if i == 4 {
+				ft = -ft
+			}
+		}
+		str := float64(enabled[i] - normal[i])
+		// A missing factory trim can't be compared against and fails.
+		change := int32(math.MaxInt32)
+		if ft != 0 {
+			change = int32((str - ft) / ft * 1000)
+		}
+		result.Add(names[i], change, -140, 140)
+	}
+	return result, nil
+}
+
+// selfTestAverage writes the given gyroscope and accelerometer configuration
+// and returns the average raw readings of the accelerometer and gyroscope
+// axes.
+func (d Device) selfTestAverage(gyro, accel byte) (avg [6]int32, err error) {
+	err = legacy.WriteRegister(d.bus, uint8(d.Address), GYRO_CONFIG, []byte{gyro, accel})
+	if err != nil {
+		return
+	}
+	// Let the output settle after changing the configuration.
+	time.Sleep(50 * time.Millisecond)
+
+	var data [14]byte
+	for n := 0; n < selfTestSamples; n++ {
+		err = legacy.ReadRegister(d.bus, uint8(d.Address), ACCEL_XOUT_H, data[:])
+		if err != nil {
+			return
+		}
+		for i := 0; i < 3; i++ {
+			avg[i] += int32(int16(uint16(data[2*i])<<8 | uint16(data[2*i+1])))
+			// The gyroscope follows the temperature reading.
+			avg[i+3] += int32(int16(uint16(data[8+2*i])<<8 | uint16(data[9+2*i])))
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	for i := range avg {
+		avg[i] /= selfTestSamples
+	}
+	return
+}
@@ -0,0 +1,144 @@
+// Package multigas provides a driver for the Seeed Grove Multichannel Gas
+// Sensor V2, which combines four MOX gas sensors: GM-102B (NO2), GM-302B
+// (ethanol), GM-502B (VOC) and GM-702B (CO). An on-board microcontroller
+// samples the sensors and reports the readings over I2C.
+//
+// Use the mox package for baseline tracking and indicative ppm estimates.
+//
+// Wiki: https://wiki.seeedstudio.com/Grove-Multichannel-Gas-Sensor-V2/
+package multigas // import "tinygo.org/x/drivers/multigas"
+
+import (
+	"time"
+
+	"tinygo.org/x/drivers"
+	"tinygo.org/x/drivers/mox"
+)
+
+// Address is the default I2C address.
+const Address = 0x08
+
+// Commands
+const (
+	cmdChangeAddress = 0x55
+	cmdWarmUp        = 0xFE
+	cmdWarmDown      = 0xFF
+)
+
+// Channel selects one of the four sensors.
+type Channel uint8
+
+const (
+	NO2     Channel = 0x01 // GM-102B
+	Ethanol Channel = 0x03 // GM-302B
+	VOC     Channel = 0x05 // GM-502B
+	CO      Channel = 0x07 // GM-702B
+)
+
+// Reference voltage and resolution of the on-board ADC.
+const (
+	vref       = 3300000 // µV
+	resolution = 1023
+)
+
+// Config holds the configuration of the device.
+type Config struct {
+	// WarmUp is the time the heaters need before the readings are stable.
+	// Defaults to 3 minutes.
+	WarmUp time.Duration
+}
+
+// Device wraps an I2C connection to the sensor board.
+type Device struct {
+	bus     drivers.I2C
+	Address uint16
+	warmUp  mox.WarmUp
+	buf     [4]byte
+}
+
+// New creates a new multichannel gas sensor connection. The I2C bus must
+// already be configured.
+//
+// This function only creates the Device object, it does not touch the device.
+func New(bus drivers.I2C) *Device {
+	return &Device{bus: bus, Address: Address}
+}
+
+// Configure switches the heaters on and starts the warm-up timer.
+func (d *Device) Configure(cfg Config) error {
+	if cfg.WarmUp == 0 {
+		cfg.WarmUp = 3 * time.Minute
+	}
+	d.warmUp.Duration = cfg.WarmUp
+	return d.SetHeater(true)
+}
+
+// SetHeater switches the heaters of all sensors on or off. The warm-up timer
+// restarts when they are switched on.
+func (d *Device) SetHeater(on bool) error {
+	cmd := byte(cmdWarmDown)
+	if on {
+		cmd = cmdWarmUp
+	}
+	if err := d.command(cmd); err != nil {
+		return err
+	}
+	if on {
+		d.warmUp.Start(time.Now())
+	} else {
+		d.warmUp.Stop()
+	}
+	return nil
+}
+
+// Ready returns whether the heaters have been on long enough for stable
+// readings.
+func (d *Device) Ready() bool {
+	return d.warmUp.Ready(time.Now())
+}
+
+// WarmUpRemaining returns how long the heaters still need to warm up.
+func (d *Device) WarmUpRemaining() time.Duration {
+	return d.warmUp.Remaining(time.Now())
+}
+
+// ReadRaw returns the 10 bit ADC reading of a sensor.
+func (d *Device) ReadRaw(ch Channel) (uint32, error) {
+	if err := d.command(byte(ch)); err != nil {
+		return 0, err
+	}
+	err := d.bus.Tx(d.Address, nil, d.buf[:])
+	if err != nil {
+		return 0, err
+	}
+	return uint32(d.buf[0]) | uint32(d.buf[1])<<8 | uint32(d.buf[2])<<16 | uint32(d.buf[3])<<24, nil
+}
+
+// ReadVoltage returns the output voltage of a sensor in µV. The output rises
+// with the concentration of reducing gases (ethanol, VOC, CO) and falls with
+// NO2.
+func (d *Device) ReadVoltage(ch Channel) (uint32, error) {
+	raw, err := d.ReadRaw(ch)
+	if err != nil {
+		return 0, err
+	}
+	return raw * vref / resolution, nil
+}
+
+// SetAddress changes the I2C address of the board. The new address is stored
+// in the board and used from now on.
+func (d *Device) SetAddress(addr uint8) error {
+	d.buf[0] = cmdChangeAddress
+	d.buf[1] = addr
+	err := d.bus.Tx(d.Address, d.buf[:2], nil)
+	if err != nil {
+		return err
+	}
+	d.Address = uint16(addr)
+	return nil
+}
+
+func (d *Device) command(cmd byte) error {
+	d.buf[0] = cmd
+	return d.bus.Tx(d.Address, d.buf[:1], nil)
+}
@@ -0,0 +1,64 @@
+package multigas
+
+import (
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+	"tinygo.org/x/drivers/tester"
+)
+
+// fakeBoard answers the 4 byte reading of the last channel selected.
+type fakeBoard struct {
+	*tester.I2CDevice8
+	c       *qt.C
+	heater  bool
+	channel Channel
+	raw     map[Channel]uint32
+}
+
+func (f *fakeBoard) Tx(w, r []byte) error {
+	switch {
+	case len(w) == 1 && w[0] == cmdWarmUp:
+		f.heater = true
+	case len(w) == 1 && w[0] == cmdWarmDown:
+		f.heater = false
+	case len(w) == 1:
+		f.channel = Channel(w[0])
+	case len(w) == 0 && len(r) == 4:
+		v := f.raw[f.channel]
+		r[0], r[1], r[2], r[3] = byte(v), byte(v>>8), byte(v>>16), byte(v>>24)
+	default:
+		f.c.Fatalf("unexpected Tx(% x, %d)", w, len(r))
+	}
+	return nil
+}
+
+func TestReadVoltage(t *testing.T) {
+	c := qt.New(t)
+	bus := tester.NewI2CBus(c)
+	fake := &fakeBoard{
+		I2CDevice8: tester.NewI2CDevice8(c, Address),
+		c:          c,
+		raw:        map[Channel]uint32{NO2: 0x0123, Ethanol: 1023, VOC: 0, CO: 310},
+	}
+	bus.AddDevice(fake)
+
+	dev := New(bus)
+	c.Assert(dev.Configure(Config{}), qt.IsNil)
+	c.Assert(fake.heater, qt.IsTrue)
+	c.Assert(dev.Ready(), qt.IsFalse)
+
+	raw, err := dev.ReadRaw(NO2)
+	c.Assert(err, qt.IsNil)
+	c.Assert(raw, qt.Equals, uint32(0x0123))
+
+	for ch, want := range map[Channel]uint32{Ethanol: 3300000, VOC: 0, CO: 1000000} {
+		v, err := dev.ReadVoltage(ch)
+		c.Assert(err, qt.IsNil)
+		c.Assert(v, qt.Equals, want, qt.Commentf("channel %#x", ch))
+	}
+
+	c.Assert(dev.SetHeater(false), qt.IsNil)
+	c.Assert(fake.heater, qt.IsFalse)
+	c.Assert(dev.WarmUpRemaining() > 0, qt.IsTrue)
+}
@@ -0,0 +1,110 @@
+package net
+
+import (
+	"sync"
+	"time"
+)
+
+// LockedAdapter wraps an Adapter so that it can be shared between
+// goroutines. Every method holds a mutex while it calls the wrapped adapter,
+// so concurrent calls can't interleave commands or corrupt the response
+// buffer of the driver.
+//
+// Sequences of calls, such as StartSocketSend followed by Write, are only
+// atomic when they are done within Do.
+type LockedAdapter struct {
+	mu sync.Mutex
+	a  Adapter
+}
+
+// NewLockedAdapter returns a LockedAdapter that wraps a. The adapter must not
+// be used directly anymore afterwards, except from within Do.
+func NewLockedAdapter(a Adapter) *LockedAdapter {
+	return &LockedAdapter{a: a}
+}
+
+// Do calls f with the wrapped adapter while holding the lock. f must not call
+// methods of l.
+func (l *LockedAdapter) Do(f func(a Adapter)) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	f(l.a)
+}
+
+func (l *LockedAdapter) ConnectToAccessPoint(ssid, pass string, timeout time.Duration) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.a.ConnectToAccessPoint(ssid, pass, timeout)
+}
+
+func (l *LockedAdapter) Disconnect() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.a.Disconnect()
+}
+
+func (l *LockedAdapter) GetClientIP() (string, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.a.GetClientIP()
+}
+
+func (l *LockedAdapter) GetDNS(domain string) (string, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.a.GetDNS(domain)
+}
+
+func (l *LockedAdapter) ConnectTCPSocket(addr, port string) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.a.ConnectTCPSocket(addr, port)
+}
+
+func (l *LockedAdapter) ConnectSSLSocket(addr, port string) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.a.ConnectSSLSocket(addr, port)
+}
+
+func (l *LockedAdapter) ConnectUDPSocket(addr, sendport, listenport string) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.a.ConnectUDPSocket(addr, sendport, listenport)
+}
+
+func (l *LockedAdapter) DisconnectSocket() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.a.DisconnectSocket()
+}
+
+func (l *LockedAdapter) StartSocketSend(size int) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.a.StartSocketSend(size)
+}
+
+func (l *LockedAdapter) Write(b []byte) (n int, err error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.a.Write(b)
+}
+
+func (l *LockedAdapter) ReadSocket(b []byte) (n int, err error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.a.ReadSocket(b)
+}
+
+func (l *LockedAdapter) IsSocketDataAvailable() bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.a.IsSocketDataAvailable()
+}
+
+func (l *LockedAdapter) Response(timeout int) ([]byte, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.a.Response(timeout)
+}
@@ -0,0 +1,130 @@
+package net
+
+import (
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+var _ Adapter = (*LockedAdapter)(nil)
+
+// fakeAdapter logs the calls made to it, and counts those made while another
+// call is in progress.
+type fakeAdapter struct {
+	active   int32
+	overlaps int32
+	calls    []string
+}
+
+func (a *fakeAdapter) call(name string) {
+	if atomic.AddInt32(&a.active, 1) != 1 {
+		atomic.AddInt32(&a.overlaps, 1)
+	}
+	runtime.Gosched()
+	a.calls = append(a.calls, name)
+	atomic.AddInt32(&a.active, -1)
+}
+
+func (a *fakeAdapter) ConnectToAccessPoint(ssid, pass string, timeout time.Duration) error {
+	a.call("ConnectToAccessPoint")
+	return nil
+}
+
+func (a *fakeAdapter) Disconnect() error {
+	a.call("Disconnect")
+	return nil
+}
+
+func (a *fakeAdapter) GetClientIP() (string, error) {
+	a.call("GetClientIP")
+	return "192.168.1.2", nil
+}
+
+func (a *fakeAdapter) GetDNS(domain string) (string, error) {
+	a.call("GetDNS")
+	return "10.0.0.1", nil
+}
+
+func (a *fakeAdapter) ConnectTCPSocket(addr, port string) error {
+	a.call("ConnectTCPSocket")
+	return nil
+}
+
+func (a *fakeAdapter) ConnectSSLSocket(addr, port string) error {
+	a.call("ConnectSSLSocket")
+	return nil
+}
+
+func (a *fakeAdapter) ConnectUDPSocket(addr, sendport, listenport string) error {
+	a.call("ConnectUDPSocket")
+	return nil
+}
+
+func (a *fakeAdapter) DisconnectSocket() error {
+	a.call("DisconnectSocket")
+	return nil
+}
+
+func (a *fakeAdapter) StartSocketSend(size int) error {
+	a.call("StartSocketSend")
+	return nil
+}
+
+func (a *fakeAdapter) Write(b []byte) (n int, err error) {
+	a.call("Write")
+	return len(b), nil
+}
+
+func (a *fakeAdapter) ReadSocket(b []byte) (n int, err error) {
+	a.call("ReadSocket")
+	return 0, nil
+}
+
+func (a *fakeAdapter) IsSocketDataAvailable() bool {
+	a.call("IsSocketDataAvailable")
+	return false
+}
+
+func (a *fakeAdapter) Response(timeout int) ([]byte, error) {
+	a.call("Response")
+	return nil, nil
+}
+
+func TestLockedAdapter(t *testing.T) {
+	fake := &fakeAdapter{}
+	l := NewLockedAdapter(fake)
+
+	// Some goroutines send within Do, the others poll for data.
+	var wg sync.WaitGroup
+	for g := 0; g < 4; g++ {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+			for i := 0; i < 50; i++ {
+				if g%2 == 0 {
+					l.Do(func(a Adapter) {
+						a.StartSocketSend(4)
+						a.Write([]byte("ping"))
+					})
+				} else if !l.IsSocketDataAvailable() {
+					l.ReadSocket(make([]byte, 4))
+				}
+			}
+		}(g)
+	}
+	wg.Wait()
+
+	if fake.overlaps != 0 {
+		t.Errorf("%d calls made during another call", fake.overlaps)
+	}
+	if len(fake.calls) != 4*2*50 {
+		t.Fatalf("%d calls", len(fake.calls))
+	}
+	for i, name := range fake.calls {
+		if name == "StartSocketSend" && fake.calls[i+1] != "Write" {
+			t.Fatalf("StartSocketSend followed by %s", fake.calls[i+1])
+		}
+	}
+}
@@ -0,0 +1,140 @@
+package onewire
+
+import "errors"
+
+// OneWire ROM commands
+const (
+	READ_ROM   uint8 = 0x33
+	MATCH_ROM  uint8 = 0x55
+	SKIP_ROM   uint8 = 0xCC
+	SEARCH_ROM uint8 = 0xF0
+)
+
+// Errors list
+var (
+	errNoPresence  = errors.New("Error: OneWire. No devices on the bus.")
+	errReadAddress = errors.New("Error: OneWire. Read address error: CRC mismatch.")
+)
+
+// Bus is the bit level access to a 1-Wire bus. It is implemented by the GPIO
+// and UART transports of this package and by bridge chips such as the
+// DS2484, which can use the functions below for the ROM commands.
+type Bus interface {
+	Reset() error
+	WriteBit(data uint8)
+	ReadBit() uint8
+	Write(data uint8)
+	Read() uint8
+}
+
+// ReadAddress receives the ROM ID of the only device on the bus.
+func ReadAddress(b Bus) ([]uint8, error) {
+	var romid = make([]uint8, 8)
+	if err := b.Reset(); err != nil {
+		return nil, err
+	}
+	b.Write(READ_ROM)
+	for i := 0; i < 8; i++ {
+		romid[i] = b.Read()
+	}
+	if CRC8(romid, 7) != romid[7] {
+		return nil, errReadAddress
+	}
+	return romid, nil
+}
+
+// Select addresses the device with the given ROM ID, or all devices if romid
+// is empty.
+func Select(b Bus, romid []uint8) error {
+	if err := b.Reset(); err != nil {
+		return err
+	}
+	if len(romid) == 0 {
+		b.Write(SKIP_ROM)
+		return nil
+	}
+	b.Write(MATCH_ROM)
+	for i := 0; i < 8; i++ {
+		b.Write(romid[i])
+	}
+	return nil
+}
+
+// Search searches for all devices on the bus one bit at a time.
+// Note: max 32 slave devices per bus
+func Search(d Bus, cmd uint8) ([][]uint8, error) {
+	var (
+		bit, bit_c  uint8 = 0, 0
+		bitOffset   uint8 = 0
+		lastZero    uint8 = 0
+		lastFork    uint8 = 0
+		lastAddress       = make([]uint8, 8)
+		romIDs            = make([][]uint8, 32) //
+		romIndex    uint8 = 0
+	)
+
+	for i := range romIDs {
+		romIDs[i] = make([]uint8, 8)
+	}
+
+	for ok := true; ok; ok = (lastFork != 0) {
+		if err := d.Reset(); err != nil {
+			return nil, err
+		}
+
+		// send search command to bus
+		d.Write(cmd)
+
+		lastZero = 0
+
+		for bitOffset = 0; bitOffset < 64; bitOffset++ {
+			bit = d.ReadBit()   // read first address bit
+			bit_c = d.ReadBit() // read second (complementary) address bit
+
+			if bit == 1 && bit_c == 1 { // no device
+				return nil, errNoPresence
+			}
+
+			if bit == 0 && bit_c == 0 { // collision
+				if bitOffset == lastFork {
+					bit = 1
+				}
+				if bitOffset < lastFork {
+					bit = (lastAddress[bitOffset>>3] >> (bitOffset & 0x07)) & 1
+				}
+				if bit == 0 {
+					lastZero = bitOffset
+				}
+			}
+
+			if bit == 0 {
+				lastAddress[bitOffset>>3] &= ^(1 << (bitOffset & 0x07))
+			} else {
+				lastAddress[bitOffset>>3] |= (1 << (bitOffset & 0x07))
+			}
+			d.WriteBit(bit)
+		}
+		lastFork = lastZero
+		copy(romIDs[romIndex], lastAddress)
+		romIndex++
+	}
+	return romIDs[:romIndex:romIndex], nil
+}
+
+// CRC8 computes a Dallas Semiconductor 8 bit CRC.
+func CRC8(buffer []uint8, size int) (crc uint8) {
+	// Dow-CRC using polynomial X^8 + X^5 + X^4 + X^0
+	// Tiny 2x16 entry CRC table created by Arjen Lentz
+	// See http://lentz.com.au/blog/calculating-crc-with-a-tiny-32-entry-lookup-table
+	crc8_table := [...]uint8{
+		0x00, 0x5E, 0xBC, 0xE2, 0x61, 0x3F, 0xDD, 0x83,
+		0xC2, 0x9C, 0x7E, 0x20, 0xA3, 0xFD, 0x1F, 0x41,
+		0x00, 0x9D, 0x23, 0xBE, 0x46, 0xDB, 0x65, 0xF8,
+		0x8C, 0x11, 0xAF, 0x32, 0xCA, 0x57, 0xE9, 0x74,
+	}
+	for i := 0; i < size; i++ {
+		crc = buffer[i] ^ crc // just re-using crc as intermediate
+		crc = crc8_table[crc&0x0f] ^ crc8_table[16+((crc>>4)&0x0f)]
+	}
+	return crc
+}
@@ -0,0 +1,168 @@
+package onewire
+
+import (
+	"errors"
+	"time"
+
+	"tinygo.org/x/drivers"
+)
+
+// UART is the serial port used by UARTDevice. It is implemented by the
+// machine.UART type.
+type UART interface {
+	drivers.UART
+	SetBaudRate(br uint32)
+}
+
+// UARTDevice is a 1-Wire bus driven by a UART instead of a bit-banged pin. TX
+// and RX are tied together and to the bus, usually through an open drain
+// buffer or a diode on TX, and pulled up as usual. All timing is done by the
+// UART hardware, which makes it reliable on chips where interrupts or a slow
+// clock break the microsecond delays of Device.
+//
+// A reset pulse is a 0xF0 byte sent at 9600 baud: the start bit and the four
+// low bits hold the bus low for 520µs, and a device answering with a presence
+// pulse changes the echoed byte. Every bit slot is a byte sent at 115200
+// baud: 0xFF only pulls the bus low for the start bit, which is a 1 or a read
+// slot, and 0x00 holds it low for 78µs, which is a 0. A device answering 0 in
+// a read slot corrupts the echo of 0xFF.
+type UARTDevice struct {
+	uart UART
+
+	// Timeout is the time to wait for the echo of a byte. It defaults to
+	// 10ms if zero.
+	Timeout time.Duration
+}
+
+var errNoEcho = errors.New("Error: OneWire. No echo from the UART, check the wiring.")
+
+// NewUART creates a new UART 1-Wire connection. The UART must be configured
+// beforehand, its baud rate is changed as needed.
+func NewUART(uart UART) UARTDevice {
+	return UARTDevice{
+		uart: uart,
+	}
+}
+
+// Reset sends a reset pulse and checks for a presence pulse.
+func (d UARTDevice) Reset() error {
+	d.flush()
+	d.uart.SetBaudRate(9600)
+	var buf [1]byte
+	buf[0] = 0xF0
+	d.uart.Write(buf[:])
+	err := d.echo(buf[:])
+	d.uart.SetBaudRate(115200)
+	if err != nil {
+		return err
+	}
+	if buf[0] == 0xF0 {
+		return errNoPresence
+	}
+	return nil
+}
+
+// WriteBit transmits a bit to 1-Wire bus.
+func (d UARTDevice) WriteBit(data uint8) {
+	var buf [1]byte
+	if data&1 == 1 {
+		buf[0] = 0xFF
+	}
+	d.uart.Write(buf[:])
+	d.echo(buf[:])
+}
+
+// Write transmits a byte as bit array to 1-Wire bus. (LSB first)
+func (d UARTDevice) Write(data uint8) {
+	d.transfer(data)
+}
+
+// ReadBit receives a bit from 1-Wire bus.
+func (d UARTDevice) ReadBit() (data uint8) {
+	buf := [1]byte{0xFF}
+	d.uart.Write(buf[:])
+	if d.echo(buf[:]) == nil && buf[0] == 0xFF {
+		data = 1
+	}
+	return data
+}
+
+// Read receives a byte from 1-Wire bus. (LSB first)
+func (d UARTDevice) Read() (data uint8) {
+	return d.transfer(0xFF)
+}
+
+// ReadAddress receives a 64-bit unique ROM ID from Device. (LSB first)
+// Note: use this if there is only one slave device on the bus.
+func (d UARTDevice) ReadAddress() ([]uint8, error) {
+	return ReadAddress(d)
+}
+
+// Select selects the address of the device for communication
+func (d UARTDevice) Select(romid []uint8) error {
+	return Select(d, romid)
+}
+
+// Search searches for all devices on the bus.
+// Note: max 32 slave devices per bus
+func (d UARTDevice) Search(cmd uint8) ([][]uint8, error) {
+	return Search(d, cmd)
+}
+
+// Crc8 compute a Dallas Semiconductor 8 bit CRC.
+func (d UARTDevice) Сrc8(buffer []uint8, size int) (crc uint8) {
+	return CRC8(buffer, size)
+}
+
+// transfer sends the 8 bit slots of a byte in one go and returns the bits
+// read back. Sending 0xFF reads a byte.
+func (d UARTDevice) transfer(data uint8) uint8 {
+	var buf [8]byte
+	for i := range buf {
+		if data&(1<<i) != 0 {
+			buf[i] = 0xFF
+		}
+	}
+	d.uart.Write(buf[:])
+	if d.echo(buf[:]) != nil {
+		return 0xFF
+	}
+	data = 0
+	for i := range buf {
+		if buf[i] == 0xFF {
+			data |= 1 << i
+		}
+	}
+	return data
+}
+
+// echo reads back the bytes that were just sent into buf.
+func (d UARTDevice) echo(buf []byte) error {
+	timeout := d.Timeout
+	if timeout == 0 {
+		timeout = 10 * time.Millisecond
+	}
+	start := time.Now()
+	for n := 0; n < len(buf); {
+		if d.uart.Buffered() == 0 {
+			if time.Since(start) > timeout {
+				return errNoEcho
+			}
+			continue
+		}
+		m, err := d.uart.Read(buf[n:])
+		if err != nil {
+			return err
+		}
+		n += m
+	}
+	return nil
+}
+
+// flush discards any stale bytes in the receive buffer.
+func (d UARTDevice) flush() {
+	var buf [8]byte
+	for d.uart.Buffered() > 0 {
+		d.uart.Read(buf[:])
+	}
+}
@@ -0,0 +1,57 @@
+package pcf8523
+
+import (
+	"errors"
+	"io"
+)
+
+// TimerMemory is the 2 bytes of the value registers of timers A and B of
+// the PCF8523, used as battery-backed memory while the timers are not used.
+// It implements persist.Memory, to keep a boot counter or crash flags.
+//
+// The timers must stay disabled (TAC and TBC in Tmr_CLKOUT_ctrl, the
+// default), or the bytes would count down. Reset clears them.
+type TimerMemory struct {
+	d *Device
+}
+
+// TimerMemory returns the timer value registers as a memory.
+func (d *Device) TimerMemory() TimerMemory {
+	return TimerMemory{d: d}
+}
+
+// timerMemoryRegisters are the registers of the bytes of TimerMemory.
+var timerMemoryRegisters = [2]uint8{rTimerARegister, rTimerBRegister}
+
+// Size returns the size of the memory, 2 bytes.
+func (m TimerMemory) Size() int64 {
+	return int64(len(timerMemoryRegisters))
+}
+
+// ReadAt reads len(data) bytes at offset off of the memory.
+func (m TimerMemory) ReadAt(data []byte, off int64) (int, error) {
+	if off < 0 || off+int64(len(data)) > m.Size() {
+		return 0, io.EOF
+	}
+	for i := range data {
+		err := m.d.bus.Tx(uint16(m.d.Address), []byte{timerMemoryRegisters[off+int64(i)]}, data[i:i+1])
+		if err != nil {
+			return i, err
+		}
+	}
+	return len(data), nil
+}
+
+// WriteAt writes len(data) bytes at offset off of the memory.
+func (m TimerMemory) WriteAt(data []byte, off int64) (int, error) {
+	if off < 0 || off+int64(len(data)) > m.Size() {
+		return 0, errors.New("writing outside of the timer registers")
+	}
+	for i, b := range data {
+		err := m.d.bus.Tx(uint16(m.d.Address), []byte{timerMemoryRegisters[off+int64(i)], b}, nil)
+		if err != nil {
+			return i, err
+		}
+	}
+	return len(data), nil
+}
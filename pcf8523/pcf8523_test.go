@@ -4,9 +4,12 @@ import (
 	"encoding/hex"
 	"testing"
 	"time"
+	"tinygo.org/x/drivers/persist"
 	"tinygo.org/x/drivers/tester"
 )
 
+var _ persist.Memory = TimerMemory{}
+
 func TestDecToBcd_RoundTrip(t *testing.T) {
 
 	for i := 0; i < 60; i++ {
@@ -89,3 +92,26 @@ func assertEquals[T comparable](t testing.TB, a, b T) {
 		t.Fatalf("%v != %v", a, b)
 	}
 }
+
+func TestTimerMemory(t *testing.T) {
+	bus := tester.NewI2CBus(t)
+	fake := bus.NewDevice(DefaultAddress)
+
+	dev := New(bus)
+	mem := dev.TimerMemory()
+
+	_, err := mem.WriteAt([]byte{0x12, 0x34}, 0)
+	assertNoError(t, err)
+	assertEquals(t, fake.Registers[rTimerARegister], 0x12)
+	assertEquals(t, fake.Registers[rTimerBRegister], 0x34)
+
+	buf := make([]byte, 1)
+	_, err = mem.ReadAt(buf, 1)
+	assertNoError(t, err)
+	assertEquals(t, buf[0], 0x34)
+
+	_, err = mem.WriteAt([]byte{1, 2}, 1)
+	if err == nil {
+		t.Fatal("wrote past the end of the timer registers")
+	}
+}
@@ -0,0 +1,131 @@
+// Package persist keeps small values, such as boot counters and crash flags,
+// in a battery-backed memory: the RAM of an RTC, or registers of it that are
+// otherwise unused. They survive resets and power cycles as long as the
+// battery lasts, and unlike flash, writing them does not wear anything out.
+//
+// The memories are those of the DS1307 (56 bytes of SRAM), the DS3231 (7
+// bytes in its alarm registers) and the PCF8523 (2 bytes in its timer
+// registers), or of any other device implementing Memory.
+package persist // import "tinygo.org/x/drivers/persist"
+
+import (
+	"errors"
+	"io"
+)
+
+var (
+	// ErrNotSet is returned by Cell.Load for a cell that was never stored,
+	// or whose memory was lost with the battery.
+	ErrNotSet = errors.New("persist: value not set")
+
+	errCellSize  = errors.New("persist: cell size must be 1 to 4 bytes")
+	errCellRange = errors.New("persist: cell outside of the memory")
+)
+
+// Memory is a small memory of Size bytes, that keeps its contents across
+// resets.
+type Memory interface {
+	io.ReaderAt
+	io.WriterAt
+	Size() int64
+}
+
+// Cell is an unsigned value of 1 to 4 bytes at an offset of a memory,
+// followed by a check byte. The check byte tells apart a value stored from
+// the random contents of a memory after its battery was changed, or from a
+// value half written when the power failed.
+type Cell struct {
+	mem  Memory
+	off  int64
+	size int
+	buf  [5]byte
+}
+
+// NewCell returns the cell of size bytes at offset off of mem. It takes
+// size+1 bytes of the memory, so the next cell starts at off+size+1.
+//
+// This function only creates the Cell object, it does not touch the memory.
+func NewCell(mem Memory, off int64, size int) *Cell {
+	return &Cell{mem: mem, off: off, size: size}
+}
+
+// Len returns the number of bytes of memory taken by the cell.
+func (c *Cell) Len() int64 {
+	return int64(c.size) + 1
+}
+
+func (c *Cell) check() error {
+	if c.size < 1 || c.size > 4 {
+		return errCellSize
+	}
+	if c.off < 0 || c.off+c.Len() > c.mem.Size() {
+		return errCellRange
+	}
+	return nil
+}
+
+// Load returns the value of the cell. It returns 0 and ErrNotSet if the
+// cell does not hold a value stored.
+func (c *Cell) Load() (uint32, error) {
+	if err := c.check(); err != nil {
+		return 0, err
+	}
+	b := c.buf[:c.Len()]
+	if _, err := c.mem.ReadAt(b, c.off); err != nil {
+		return 0, err
+	}
+	if crc8(b[:c.size]) != b[c.size] {
+		return 0, ErrNotSet
+	}
+	var v uint32
+	for _, x := range b[:c.size] {
+		v = v<<8 | uint32(x)
+	}
+	return v, nil
+}
+
+// Store stores v in the cell, truncated to its size.
+func (c *Cell) Store(v uint32) error {
+	if err := c.check(); err != nil {
+		return err
+	}
+	b := c.buf[:c.Len()]
+	for i := c.size - 1; i >= 0; i-- {
+		b[i] = uint8(v)
+		v >>= 8
+	}
+	b[c.size] = crc8(b[:c.size])
+	_, err := c.mem.WriteAt(b, c.off)
+	return err
+}
+
+// Add adds delta to the value of the cell, counting from 0 if it was not
+// set, and returns the new value. It wraps around at the size of the cell.
+func (c *Cell) Add(delta uint32) (uint32, error) {
+	v, err := c.Load()
+	if err != nil && err != ErrNotSet {
+		return 0, err
+	}
+	v += delta
+	if c.size < 4 {
+		v &= 1<<(8*c.size) - 1
+	}
+	return v, c.Store(v)
+}
+
+// crc8 computes the CRC-8 with the polynomial 0x31 and the initial value
+// 0xFF, so that memories of all zeros or all ones never hold a value.
+func crc8(data []byte) uint8 {
+	crc := uint8(0xFF)
+	for _, b := range data {
+		crc ^= b
+		for i := 0; i < 8; i++ {
+			if crc&0x80 != 0 {
+				crc = crc<<1 ^ 0x31
+			} else {
+				crc <<= 1
+			}
+		}
+	}
+	return crc
+}
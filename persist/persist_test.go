@@ -0,0 +1,76 @@
+package persist
+
+import (
+	"io"
+	"testing"
+)
+
+// ram is a Memory of a few bytes.
+type ram []byte
+
+func (r ram) ReadAt(p []byte, off int64) (int, error) {
+	if off+int64(len(p)) > int64(len(r)) {
+		return 0, io.EOF
+	}
+	return copy(p, r[off:]), nil
+}
+
+func (r ram) WriteAt(p []byte, off int64) (int, error) {
+	if off+int64(len(p)) > int64(len(r)) {
+		return 0, io.ErrShortWrite
+	}
+	return copy(r[off:], p), nil
+}
+
+func (r ram) Size() int64 { return int64(len(r)) }
+
+func TestCell(t *testing.T) {
+	for _, fill := range []byte{0x00, 0xFF} {
+		mem := make(ram, 7)
+		for i := range mem {
+			mem[i] = fill
+		}
+		boots := NewCell(mem, 0, 2)
+		flags := NewCell(mem, boots.Len(), 1)
+		if v, err := boots.Load(); v != 0 || err != ErrNotSet {
+			t.Errorf("memory of %#x: %d, %v", fill, v, err)
+		}
+		for i := uint32(1); i <= 3; i++ {
+			if v, err := boots.Add(1); v != i || err != nil {
+				t.Fatalf("boot %d: %d, %v", i, v, err)
+			}
+		}
+		if err := flags.Store(0x81); err != nil {
+			t.Fatal(err)
+		}
+		if v, err := boots.Load(); v != 3 || err != nil {
+			t.Errorf("boots %d, %v", v, err)
+		}
+		if v, err := flags.Load(); v != 0x81 || err != nil {
+			t.Errorf("flags %#x, %v", v, err)
+		}
+	}
+
+	// A value half written.
+	mem := make(ram, 7)
+	c := NewCell(mem, 2, 4)
+	c.Store(0x12345678)
+	mem[3] ^= 0x40
+	if _, err := c.Load(); err != ErrNotSet {
+		t.Errorf("corrupted value: %v", err)
+	}
+
+	// Counters wrap around at their size.
+	c = NewCell(mem, 0, 1)
+	c.Store(255)
+	if v, err := c.Add(1); v != 0 || err != nil {
+		t.Errorf("wrap around: %d, %v", v, err)
+	}
+
+	if err := NewCell(mem, 4, 4).Store(1); err != errCellRange {
+		t.Errorf("cell past the end: %v", err)
+	}
+	if err := NewCell(mem, 0, 5).Store(1); err != errCellSize {
+		t.Errorf("cell of 5 bytes: %v", err)
+	}
+}
@@ -0,0 +1,130 @@
+// Package pid implements a PID controller in fixed point, to drive a motor,
+// servo or heater from the measurements of a sensor: a temperature chamber,
+// the tilt of a balance bot, the position of a linear actuator.
+//
+// The setpoint, the measurements and the output are integers in the units of
+// the sensor and actuator drivers, for instance milli degrees Celsius in and
+// a PWM duty cycle out. The gains are fixed point numbers with 16 fractional
+// bits: One is a gain of 1, One/2 a gain of 0.5.
+//
+// The controller keeps the integral term within the output limits, and stops
+// integrating while the output is saturated, so that it does not wind up
+// while the actuator cannot follow. The derivative term is computed on the
+// measurement rather than on the error, so that setpoint changes do not kick
+// the output, and may be filtered to attenuate the noise of the sensor.
+package pid // import "tinygo.org/x/drivers/pid"
+
+import (
+	"math"
+	"time"
+)
+
+// One is a gain of 1.
+const One = 1 << 16
+
+// Config is the configuration of a controller.
+type Config struct {
+	// Gains, with 16 fractional bits. Ki is per second and Kd in seconds:
+	// the output changes by Ki times the error each second, and by Kd times
+	// the change of the measurement per second.
+	Kp, Ki, Kd int32
+
+	// Limits of the output. Both 0 means the range of an int32.
+	Min, Max int32
+
+	// DerivativeFilter is the strength of the low pass filter on the
+	// derivative term: each update moves it by 1/2^DerivativeFilter of the
+	// way to the new value. 0 means no filtering.
+	DerivativeFilter uint8
+}
+
+// Controller is a PID controller. Call Update at a regular interval, with the
+// setpoint and the last measurement, and apply its output.
+type Controller struct {
+	cfg Config
+
+	integral   int64 // integral term, with 16 fractional bits
+	derivative int64 // filtered derivative term, with 16 fractional bits
+	last       int32 // last measurement
+	started    bool
+}
+
+// New returns a controller with the given gains and limits.
+func New(cfg Config) *Controller {
+	return &Controller{cfg: cfg}
+}
+
+// SetGains changes the gains, keeping the integral term so that the output
+// does not jump.
+func (c *Controller) SetGains(kp, ki, kd int32) {
+	c.cfg.Kp, c.cfg.Ki, c.cfg.Kd = kp, ki, kd
+}
+
+// SetLimits changes the output limits.
+func (c *Controller) SetLimits(min, max int32) {
+	c.cfg.Min, c.cfg.Max = min, max
+	c.integral = c.clamp(c.integral)
+}
+
+// Reset clears the integral and derivative terms, for instance after the
+// actuator was turned off for a while.
+func (c *Controller) Reset() {
+	c.integral = 0
+	c.derivative = 0
+	c.started = false
+}
+
+// Integral returns the integral term, in the units of the output.
+func (c *Controller) Integral() int32 {
+	return int32(c.integral >> 16)
+}
+
+// Update returns the output for the measurement, dt after the previous
+// update. The first update after New or Reset has no derivative term.
+func (c *Controller) Update(setpoint, measurement int32, dt time.Duration) int32 {
+	err := int64(setpoint) - int64(measurement)
+	us := dt.Microseconds()
+	p := int64(c.cfg.Kp) * err
+
+	if c.started && us > 0 {
+		// The derivative of the measurement, with the sign of the derivative
+		// of the error.
+		d := int64(c.cfg.Kd) * (int64(c.last) - int64(measurement)) * 1e6 / us
+		c.derivative += (d - c.derivative) >> c.cfg.DerivativeFilter
+	}
+	c.last = measurement
+	c.started = true
+
+	// Integrate, unless the output is already saturated in the direction
+	// the error pushes it.
+	if us > 0 {
+		i := c.clamp(c.integral + int64(c.cfg.Ki)*err*us/1e6)
+		out := p + i + c.derivative
+		min, max := c.limits()
+		if !(out > max && i > c.integral || out < min && i < c.integral) {
+			c.integral = i
+		}
+	}
+
+	return int32(c.clamp(p+c.integral+c.derivative) >> 16)
+}
+
+// limits returns the output limits, with 16 fractional bits.
+func (c *Controller) limits() (min, max int64) {
+	if c.cfg.Min == 0 && c.cfg.Max == 0 {
+		return math.MinInt32 << 16, math.MaxInt32 << 16
+	}
+	return int64(c.cfg.Min) << 16, int64(c.cfg.Max) << 16
+}
+
+// clamp returns v, with 16 fractional bits, within the output limits.
+func (c *Controller) clamp(v int64) int64 {
+	min, max := c.limits()
+	if v > max {
+		return max
+	}
+	if v < min {
+		return min
+	}
+	return v
+}
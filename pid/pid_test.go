@@ -0,0 +1,77 @@
+package pid
+
+import (
+	"testing"
+	"time"
+
+	qt "github.com/frankban/quicktest"
+)
+
+func TestProportional(t *testing.T) {
+	c := qt.New(t)
+
+	pid := New(Config{Kp: One / 2})
+	c.Assert(pid.Update(1000, 400, time.Second), qt.Equals, int32(300))
+	c.Assert(pid.Update(1000, 1600, time.Second), qt.Equals, int32(-300))
+
+	pid = New(Config{Kp: 10 * One, Min: 0, Max: 255})
+	c.Assert(pid.Update(1000, 0, time.Second), qt.Equals, int32(255))
+	c.Assert(pid.Update(0, 1000, time.Second), qt.Equals, int32(0))
+}
+
+func TestIntegral(t *testing.T) {
+	c := qt.New(t)
+
+	// An error of 100 with a gain of 2 per second adds 20 every 100ms.
+	pid := New(Config{Ki: 2 * One})
+	for i := 1; i <= 5; i++ {
+		c.Assert(pid.Update(100, 0, 100*time.Millisecond), qt.Equals, int32(20*i))
+	}
+	pid.Reset()
+	c.Assert(pid.Integral(), qt.Equals, int32(0))
+
+	// The output stays saturated for a while: the integral must not grow
+	// past what keeps it there, so that it comes off the limit as soon as
+	// the error changes sign.
+	pid = New(Config{Kp: One, Ki: One, Max: 100})
+	for i := 0; i < 100; i++ {
+		pid.Update(1000, 0, time.Second)
+	}
+	c.Assert(pid.Integral() <= 100, qt.IsTrue)
+	c.Assert(pid.Update(0, 200, time.Second) < 100, qt.IsTrue)
+}
+
+func TestDerivative(t *testing.T) {
+	c := qt.New(t)
+
+	pid := New(Config{Kd: One})
+	c.Assert(pid.Update(0, 0, 100*time.Millisecond), qt.Equals, int32(0))
+	// Rising by 10 in 100ms is 100 per second.
+	c.Assert(pid.Update(0, 10, 100*time.Millisecond), qt.Equals, int32(-100))
+	// A setpoint change does not kick the output.
+	c.Assert(pid.Update(1000, 10, 100*time.Millisecond), qt.Equals, int32(0))
+
+	pid = New(Config{Kd: One, DerivativeFilter: 2})
+	pid.Update(0, 0, 100*time.Millisecond)
+	c.Assert(pid.Update(0, 10, 100*time.Millisecond), qt.Equals, int32(-25))
+	c.Assert(pid.Update(0, 20, 100*time.Millisecond), qt.Equals, int32(-44))
+}
+
+// A heater in a chamber: the temperature in milli degrees rises with the
+// power, a duty cycle from 0 to 1000, and falls towards the room
+// temperature.
+func TestChamber(t *testing.T) {
+	c := qt.New(t)
+
+	const room = 20000
+	temp := int32(room)
+	pid := New(Config{Kp: 2 * One, Ki: One / 4, Kd: One / 2, Max: 1000, DerivativeFilter: 1})
+	for i := 0; i < 3000; i++ {
+		power := pid.Update(45000, temp, 100*time.Millisecond)
+		c.Assert(power >= 0 && power <= 1000, qt.IsTrue)
+		temp += power/10 - (temp-room)/500
+		if i > 2000 && (temp < 44900 || temp > 45100) {
+			c.Fatalf("temperature %d after %d steps", temp, i)
+		}
+	}
+}
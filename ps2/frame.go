@@ -0,0 +1,55 @@
+package ps2
+
+import (
+	"errors"
+	"time"
+)
+
+var (
+	errTimeout  = errors.New("ps2: timeout")
+	errNoAck    = errors.New("ps2: command not acknowledged")
+	errSelfTest = errors.New("ps2: self test failed")
+)
+
+// Responses and commands common to keyboards and mice.
+const (
+	Ack          = 0xFA
+	Resend       = 0xFE
+	SelfTestPass = 0xAA
+	CmdReset     = 0xFF
+)
+
+// Port is a PS/2 port that keyboards and mice are connected to. It is
+// implemented by Device.
+type Port interface {
+	Receive() (byte, bool)
+	ReceiveTimeout(timeout time.Duration) (byte, error)
+	Command(b byte) error
+	Reset() error
+}
+
+// decodeFrame checks the start, parity and stop bits of a frame and returns
+// the data byte.
+func decodeFrame(frame uint16) (byte, bool) {
+	if frame&0x001 != 0 || frame&0x400 == 0 {
+		return 0, false
+	}
+	b := byte(frame >> 1)
+	parity := frame>>9&1 != 0
+	return b, parity == oddParity(b)
+}
+
+// oddParity returns the parity bit that makes the number of ones odd.
+func oddParity(b byte) bool {
+	b ^= b >> 4
+	b ^= b >> 2
+	b ^= b >> 1
+	return b&1 == 0
+}
+
+func boolBit(b bool) uint16 {
+	if b {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,199 @@
+package ps2
+
+// Keyboard commands.
+const (
+	CmdSetLEDs          = 0xED
+	CmdSetTypematic     = 0xF3
+	CmdEnableScanning   = 0xF4
+	CmdDisableScanning  = 0xF5
+	CmdSetScancodeSet   = 0xF0
+	ScancodeExtended    = 0xE0
+	ScancodeRelease     = 0xF0
+	ScancodePausePrefix = 0xE1
+)
+
+// Scancodes (set 2) of the modifier and lock keys. Extended keys have
+// ScancodeExtended in the high byte.
+const (
+	ScancodeLeftShift  = 0x12
+	ScancodeRightShift = 0x59
+	ScancodeLeftCtrl   = 0x14
+	ScancodeRightCtrl  = 0xE014
+	ScancodeLeftAlt    = 0x11
+	ScancodeRightAlt   = 0xE011
+	ScancodeLeftGUI    = 0xE01F
+	ScancodeRightGUI   = 0xE027
+	ScancodeCapsLock   = 0x58
+	ScancodeNumLock    = 0x77
+	ScancodeScrollLock = 0x7E
+	ScancodeUp         = 0xE075
+	ScancodeDown       = 0xE072
+	ScancodeLeft       = 0xE06B
+	ScancodeRight      = 0xE074
+	ScancodePause      = 0xE177
+)
+
+// Modifier bits of a key event.
+const (
+	ModShift = 1 << iota
+	ModCtrl
+	ModAlt
+	ModGUI
+	ModCapsLock
+	ModNumLock
+	ModScrollLock
+)
+
+// KeyEvent is a key press or release.
+type KeyEvent struct {
+	// Scancode in set 2, with ScancodeExtended in the high byte for
+	// extended keys.
+	Scancode uint16
+
+	Pressed bool
+
+	// Modifiers active after this event.
+	Modifiers uint8
+
+	// Rune is the character of the key according to the keymap, taking
+	// shift and caps lock into account, or 0 for keys without one.
+	Rune rune
+}
+
+// Keyboard decodes scancode set 2 sequences from a PS/2 port into key
+// events.
+type Keyboard struct {
+	port   Port
+	Keymap *Keymap
+
+	modifiers uint8
+	extended  bool
+	release   bool
+	skip      uint8
+}
+
+// NewKeyboard returns a keyboard on a configured port, using the US keymap.
+func NewKeyboard(port Port) *Keyboard {
+	return &Keyboard{port: port, Keymap: &KeymapUS}
+}
+
+// Configure resets the keyboard.
+func (k *Keyboard) Configure() error {
+	if err := k.port.Reset(); err != nil {
+		return err
+	}
+	return k.SetLEDs()
+}
+
+// SetLEDs updates the lock LEDs to match the lock state.
+func (k *Keyboard) SetLEDs() error {
+	var leds byte
+	if k.modifiers&ModScrollLock != 0 {
+		leds |= 0x01
+	}
+	if k.modifiers&ModNumLock != 0 {
+		leds |= 0x02
+	}
+	if k.modifiers&ModCapsLock != 0 {
+		leds |= 0x04
+	}
+	if err := k.port.Command(CmdSetLEDs); err != nil {
+		return err
+	}
+	return k.port.Command(leds)
+}
+
+// Modifiers returns the current modifier and lock state.
+func (k *Keyboard) Modifiers() uint8 {
+	return k.modifiers
+}
+
+// ReadEvent decodes the received bytes up to the next key event. It returns
+// false when no complete event has been received yet.
+func (k *Keyboard) ReadEvent() (KeyEvent, bool) {
+	for {
+		b, ok := k.port.Receive()
+		if !ok {
+			return KeyEvent{}, false
+		}
+		if e, ok := k.Feed(b); ok {
+			return e, true
+		}
+	}
+}
+
+// Feed decodes a single received byte and returns an event when it
+// completes one. ReadEvent calls it; it is exported for keyboards connected
+// through other means.
+func (k *Keyboard) Feed(b byte) (KeyEvent, bool) {
+	if k.skip > 0 {
+		// Rest of the pause key sequence, which has no release.
+		k.skip--
+		if k.skip == 0 {
+			return KeyEvent{Scancode: ScancodePause, Pressed: true, Modifiers: k.modifiers}, true
+		}
+		return KeyEvent{}, false
+	}
+	switch b {
+	case ScancodeExtended:
+		k.extended = true
+		return KeyEvent{}, false
+	case ScancodeRelease:
+		k.release = true
+		return KeyEvent{}, false
+	case ScancodePausePrefix:
+		k.skip = 7
+		return KeyEvent{}, false
+	case Ack, Resend, SelfTestPass, 0x00, 0xFF:
+		// Responses and buffer overrun codes, not key events.
+		return KeyEvent{}, false
+	}
+
+	code := uint16(b)
+	if k.extended {
+		code |= ScancodeExtended << 8
+	}
+	pressed := !k.release
+	k.extended = false
+	k.release = false
+
+	// Fake shifts sent around some extended keys.
+	if code == 0xE012 || code == 0xE059 {
+		return KeyEvent{}, false
+	}
+
+	var mod uint8
+	switch code {
+	case ScancodeLeftShift, ScancodeRightShift:
+		mod = ModShift
+	case ScancodeLeftCtrl, ScancodeRightCtrl:
+		mod = ModCtrl
+	case ScancodeLeftAlt, ScancodeRightAlt:
+		mod = ModAlt
+	case ScancodeLeftGUI, ScancodeRightGUI:
+		mod = ModGUI
+	}
+	if mod != 0 {
+		if pressed {
+			k.modifiers |= mod
+		} else {
+			k.modifiers &^= mod
+		}
+	}
+	if pressed {
+		switch code {
+		case ScancodeCapsLock:
+			k.modifiers ^= ModCapsLock
+		case ScancodeNumLock:
+			k.modifiers ^= ModNumLock
+		case ScancodeScrollLock:
+			k.modifiers ^= ModScrollLock
+		}
+	}
+
+	e := KeyEvent{Scancode: code, Pressed: pressed, Modifiers: k.modifiers}
+	if k.Keymap != nil {
+		e.Rune = k.Keymap.Rune(code, k.modifiers)
+	}
+	return e, true
+}
@@ -0,0 +1,68 @@
+package ps2
+
+// Keymap translates scancodes to characters.
+type Keymap struct {
+	Normal  [0x80]rune
+	Shifted [0x80]rune
+
+	// Keypad maps the keypad scancodes used when num lock is on.
+	Keypad [0x80]rune
+}
+
+// Rune returns the character of a scancode with the given modifiers, or 0.
+func (m *Keymap) Rune(code uint16, modifiers uint8) rune {
+	switch code {
+	case 0xE04A:
+		return '/'
+	case 0xE05A:
+		return '\n'
+	}
+	if code >= 0x80 {
+		return 0
+	}
+	if r := m.Keypad[code]; r != 0 {
+		if modifiers&ModNumLock != 0 {
+			return r
+		}
+		return 0
+	}
+	shift := modifiers&ModShift != 0
+	r := m.Normal[code]
+	if modifiers&ModCapsLock != 0 && r >= 'a' && r <= 'z' {
+		shift = !shift
+	}
+	if shift && m.Shifted[code] != 0 {
+		return m.Shifted[code]
+	}
+	return r
+}
+
+// KeymapUS is the US keyboard layout.
+var KeymapUS = Keymap{
+	Normal: [0x80]rune{
+		0x0D: '\t', 0x0E: '`', 0x15: 'q', 0x16: '1', 0x1A: 'z', 0x1B: 's',
+		0x1C: 'a', 0x1D: 'w', 0x1E: '2', 0x21: 'c', 0x22: 'x', 0x23: 'd',
+		0x24: 'e', 0x25: '4', 0x26: '3', 0x29: ' ', 0x2A: 'v', 0x2B: 'f',
+		0x2C: 't', 0x2D: 'r', 0x2E: '5', 0x31: 'n', 0x32: 'b', 0x33: 'h',
+		0x34: 'g', 0x35: 'y', 0x36: '6', 0x3A: 'm', 0x3B: 'j', 0x3C: 'u',
+		0x3D: '7', 0x3E: '8', 0x41: ',', 0x42: 'k', 0x43: 'i', 0x44: 'o',
+		0x45: '0', 0x46: '9', 0x49: '.', 0x4A: '/', 0x4B: 'l', 0x4C: ';',
+		0x4D: 'p', 0x4E: '-', 0x52: '\'', 0x54: '[', 0x55: '=', 0x5A: '\n',
+		0x5B: ']', 0x5D: '\\', 0x66: '\b', 0x76: 0x1B,
+		0x79: '+', 0x7B: '-', 0x7C: '*',
+	},
+	Shifted: [0x80]rune{
+		0x0E: '~', 0x15: 'Q', 0x16: '!', 0x1A: 'Z', 0x1B: 'S', 0x1C: 'A',
+		0x1D: 'W', 0x1E: '@', 0x21: 'C', 0x22: 'X', 0x23: 'D', 0x24: 'E',
+		0x25: '$', 0x26: '#', 0x2A: 'V', 0x2B: 'F', 0x2C: 'T', 0x2D: 'R',
+		0x2E: '%', 0x31: 'N', 0x32: 'B', 0x33: 'H', 0x34: 'G', 0x35: 'Y',
+		0x36: '^', 0x3A: 'M', 0x3B: 'J', 0x3C: 'U', 0x3D: '&', 0x3E: '*',
+		0x41: '<', 0x42: 'K', 0x43: 'I', 0x44: 'O', 0x45: ')', 0x46: '(',
+		0x49: '>', 0x4A: '?', 0x4B: 'L', 0x4C: ':', 0x4D: 'P', 0x4E: '_',
+		0x52: '"', 0x54: '{', 0x55: '+', 0x5B: '}', 0x5D: '|',
+	},
+	Keypad: [0x80]rune{
+		0x69: '1', 0x6B: '4', 0x6C: '7', 0x70: '0', 0x71: '.', 0x72: '2',
+		0x73: '5', 0x74: '6', 0x75: '8', 0x7A: '3', 0x7D: '9',
+	},
+}
@@ -0,0 +1,127 @@
+package ps2
+
+import "time"
+
+// Mouse commands.
+const (
+	CmdSetSampleRate  = 0xF3
+	CmdGetDeviceID    = 0xF2
+	CmdEnableDataRep  = 0xF4
+	CmdDisableDataRep = 0xF5
+	CmdSetResolution  = 0xE8
+)
+
+// MouseEvent is a decoded movement packet.
+type MouseEvent struct {
+	// Buttons pressed: bit 0 left, bit 1 right, bit 2 middle.
+	Buttons uint8
+
+	// Movement since the last packet. Y is positive upwards.
+	DX, DY int16
+
+	// Wheel movement, only for mice with a wheel.
+	Wheel int8
+}
+
+// Mouse decodes movement packets from a PS/2 port.
+type Mouse struct {
+	port Port
+
+	packet [4]byte
+	n      int
+	size   int
+}
+
+// NewMouse returns a mouse on a configured port.
+func NewMouse(port Port) *Mouse {
+	return &Mouse{port: port, size: 3}
+}
+
+// Configure resets the mouse, enables the wheel when present and starts
+// streaming movement packets.
+func (m *Mouse) Configure() error {
+	if err := m.port.Reset(); err != nil {
+		return err
+	}
+	// The mouse sends its ID after the self test.
+	m.port.ReceiveTimeout(50 * time.Millisecond)
+
+	// Magic sample rate sequence to enable the wheel (IntelliMouse).
+	for _, rate := range []byte{200, 100, 80} {
+		if err := m.port.Command(CmdSetSampleRate); err != nil {
+			return err
+		}
+		if err := m.port.Command(rate); err != nil {
+			return err
+		}
+	}
+	if err := m.port.Command(CmdGetDeviceID); err != nil {
+		return err
+	}
+	id, err := m.port.ReceiveTimeout(50 * time.Millisecond)
+	if err != nil {
+		return err
+	}
+	m.size = 3
+	if id == 3 {
+		m.size = 4
+	}
+	m.n = 0
+	return m.port.Command(CmdEnableDataRep)
+}
+
+// HasWheel returns whether the mouse reports wheel movement.
+func (m *Mouse) HasWheel() bool {
+	return m.size == 4
+}
+
+// ReadEvent decodes the received bytes up to the next packet. It returns
+// false when no complete packet has been received yet.
+func (m *Mouse) ReadEvent() (MouseEvent, bool) {
+	for {
+		b, ok := m.port.Receive()
+		if !ok {
+			return MouseEvent{}, false
+		}
+		if e, ok := m.Feed(b); ok {
+			return e, true
+		}
+	}
+}
+
+// Feed decodes a single received byte and returns an event when it
+// completes a packet.
+func (m *Mouse) Feed(b byte) (MouseEvent, bool) {
+	// Bit 3 of the first byte is always set, use it to resynchronize.
+	if m.n == 0 && b&0x08 == 0 {
+		return MouseEvent{}, false
+	}
+	m.packet[m.n] = b
+	m.n++
+	if m.n < m.size {
+		return MouseEvent{}, false
+	}
+	m.n = 0
+
+	p := m.packet
+	e := MouseEvent{
+		Buttons: p[0] & 0x07,
+		DX:      int16(p[1]),
+		DY:      int16(p[2]),
+	}
+	// 9 bit two's complement, with the sign bits in the first byte.
+	if p[0]&0x10 != 0 {
+		e.DX -= 256
+	}
+	if p[0]&0x20 != 0 {
+		e.DY -= 256
+	}
+	// Drop the movement on overflow.
+	if p[0]&0xC0 != 0 {
+		e.DX, e.DY = 0, 0
+	}
+	if m.size == 4 {
+		e.Wheel = int8(p[3]<<4) >> 4
+	}
+	return e, true
+}
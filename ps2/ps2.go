@@ -0,0 +1,220 @@
+//go:build tinygo
+
+// Package ps2 implements a PS/2 host for keyboards and mice.
+//
+// The device drives the clock line; every falling edge triggers a pin
+// interrupt that samples the data line, so no timing sensitive polling is
+// needed while receiving. Received bytes are buffered until read.
+//
+// Both lines are open drain and need pull-up resistors to the supply of the
+// keyboard or mouse (usually 5V, so use a level shifter for 3.3V boards).
+//
+// Protocol reference: https://www.burtonsys.com/ps2_chapweske.htm
+package ps2 // import "tinygo.org/x/drivers/ps2"
+
+import (
+	"machine"
+	"time"
+)
+
+// bufferSize is the size of the receive buffer, a power of two.
+const bufferSize = 32
+
+// Device is a PS/2 port.
+type Device struct {
+	clock machine.Pin
+	data  machine.Pin
+
+	// Receive state, only touched by the interrupt handler.
+	bits    uint8
+	frame   uint16
+	lastBit time.Time
+
+	// Receive buffer, written by the interrupt handler.
+	buffer [bufferSize]byte
+	head   uint8
+	tail   uint8
+
+	writing bool
+	errors  uint32
+}
+
+// New returns a new PS/2 port on the given pins.
+func New(clock, data machine.Pin) *Device {
+	return &Device{clock: clock, data: data}
+}
+
+// Configure sets up the pins and starts receiving.
+func (d *Device) Configure() error {
+	d.clock.Configure(machine.PinConfig{Mode: machine.PinInputPullup})
+	d.data.Configure(machine.PinConfig{Mode: machine.PinInputPullup})
+	return d.clock.SetInterrupt(machine.PinFalling, d.handleClock)
+}
+
+// handleClock samples a bit on a falling clock edge.
+func (d *Device) handleClock(machine.Pin) {
+	if d.writing {
+		return
+	}
+	now := time.Now()
+	// Bits are at most 100µs apart, a longer gap starts a new frame.
+	if d.bits != 0 && now.Sub(d.lastBit) > 2*time.Millisecond {
+		d.bits = 0
+	}
+	d.lastBit = now
+	if d.data.Get() {
+		d.frame |= 1 << d.bits
+	} else {
+		d.frame &^= 1 << d.bits
+	}
+	d.bits++
+	if d.bits < 11 {
+		return
+	}
+	d.bits = 0
+	if b, ok := decodeFrame(d.frame); ok {
+		next := (d.head + 1) % bufferSize
+		if next != d.tail {
+			d.buffer[d.head] = b
+			d.head = next
+		}
+	} else {
+		d.errors++
+	}
+}
+
+// Buffered returns the number of received bytes waiting to be read.
+func (d *Device) Buffered() int {
+	return int(d.head-d.tail) % bufferSize
+}
+
+// Receive returns the next received byte, or false when none is available.
+func (d *Device) Receive() (byte, bool) {
+	if d.head == d.tail {
+		return 0, false
+	}
+	b := d.buffer[d.tail]
+	d.tail = (d.tail + 1) % bufferSize
+	return b, true
+}
+
+// ReceiveTimeout waits up to timeout for a byte.
+func (d *Device) ReceiveTimeout(timeout time.Duration) (byte, error) {
+	deadline := time.Now().Add(timeout)
+	for {
+		if b, ok := d.Receive(); ok {
+			return b, nil
+		}
+		if time.Now().After(deadline) {
+			return 0, errTimeout
+		}
+		time.Sleep(100 * time.Microsecond)
+	}
+}
+
+// Errors returns the number of frames dropped because of framing or parity
+// errors.
+func (d *Device) Errors() uint32 {
+	return d.errors
+}
+
+// Write sends a byte to the device. The device clocks the bits, so this
+// blocks for about a millisecond.
+func (d *Device) Write(b byte) error {
+	d.writing = true
+	defer func() {
+		d.writing = false
+		d.bits = 0
+		d.clock.Configure(machine.PinConfig{Mode: machine.PinInputPullup})
+		d.data.Configure(machine.PinConfig{Mode: machine.PinInputPullup})
+	}()
+
+	// Request to send: hold the clock low for at least 100µs, pull data
+	// low, then release the clock.
+	d.clock.Configure(machine.PinConfig{Mode: machine.PinOutput})
+	d.clock.Low()
+	time.Sleep(120 * time.Microsecond)
+	d.data.Configure(machine.PinConfig{Mode: machine.PinOutput})
+	d.data.Low()
+	d.clock.Configure(machine.PinConfig{Mode: machine.PinInputPullup})
+
+	// Data bits LSB first, then parity. The device reads each bit on the
+	// rising edge, so change data while the clock is low.
+	frame := uint16(b) | boolBit(oddParity(b))<<8
+	for i := 0; i < 9; i++ {
+		if err := d.waitClock(false, 15*time.Millisecond); err != nil {
+			return err
+		}
+		d.data.Set(frame&(1<<i) != 0)
+		if err := d.waitClock(true, time.Millisecond); err != nil {
+			return err
+		}
+	}
+
+	// Release data for the stop bit and wait for the device to acknowledge
+	// by pulling data low.
+	d.data.Configure(machine.PinConfig{Mode: machine.PinInputPullup})
+	if err := d.waitClock(false, time.Millisecond); err != nil {
+		return err
+	}
+	if err := d.waitClock(true, time.Millisecond); err != nil {
+		return err
+	}
+	if err := d.waitClock(false, time.Millisecond); err != nil {
+		return err
+	}
+	if d.data.Get() {
+		return errNoAck
+	}
+	return d.waitClock(true, time.Millisecond)
+}
+
+// Command sends a command byte and waits for the device to acknowledge it.
+// Pending received bytes are discarded first.
+func (d *Device) Command(b byte) error {
+	d.tail = d.head
+	for retry := 0; retry < 3; retry++ {
+		if err := d.Write(b); err != nil {
+			return err
+		}
+		resp, err := d.ReceiveTimeout(25 * time.Millisecond)
+		if err != nil {
+			return err
+		}
+		switch resp {
+		case Ack:
+			return nil
+		case Resend:
+			continue
+		default:
+			return errNoAck
+		}
+	}
+	return errNoAck
+}
+
+// Reset resets the device and waits for its self test to pass.
+func (d *Device) Reset() error {
+	if err := d.Command(CmdReset); err != nil {
+		return err
+	}
+	// The self test takes up to 750ms.
+	b, err := d.ReceiveTimeout(time.Second)
+	if err != nil {
+		return err
+	}
+	if b != SelfTestPass {
+		return errSelfTest
+	}
+	return nil
+}
+
+func (d *Device) waitClock(level bool, timeout time.Duration) error {
+	deadline := time.Now().Add(timeout)
+	for d.clock.Get() != level {
+		if time.Now().After(deadline) {
+			return errTimeout
+		}
+	}
+	return nil
+}
@@ -0,0 +1,60 @@
+package ps2
+
+import (
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+)
+
+func TestDecodeFrame(t *testing.T) {
+	c := qt.New(t)
+
+	// 0x1C ('a') has three ones, so the parity bit is 0.
+	b, ok := decodeFrame(0x1C<<1 | 1<<10)
+	c.Assert(ok, qt.IsTrue)
+	c.Assert(b, qt.Equals, byte(0x1C))
+
+	_, ok = decodeFrame(0x1C<<1 | 1<<9 | 1<<10)
+	c.Assert(ok, qt.IsFalse)
+	_, ok = decodeFrame(0x1C << 1)
+	c.Assert(ok, qt.IsFalse)
+}
+
+func TestKeyboard(t *testing.T) {
+	c := qt.New(t)
+
+	k := NewKeyboard(nil)
+	var runes []rune
+	// Shift down, a, a up, shift up, b, b up, extended up arrow.
+	for _, b := range []byte{0x12, 0x1C, 0xF0, 0x1C, 0xF0, 0x12, 0x32, 0xF0, 0x32, 0xE0, 0x75} {
+		if e, ok := k.Feed(b); ok && e.Pressed && e.Rune != 0 {
+			runes = append(runes, e.Rune)
+		}
+	}
+	c.Assert(string(runes), qt.Equals, "Ab")
+
+	e, ok := k.Feed(0xE0)
+	c.Assert(ok, qt.IsFalse)
+	e, ok = k.Feed(0xF0)
+	c.Assert(ok, qt.IsFalse)
+	e, ok = k.Feed(0x75)
+	c.Assert(ok, qt.IsTrue)
+	c.Assert(e, qt.Equals, KeyEvent{Scancode: ScancodeUp})
+
+	k.Feed(ScancodeCapsLock)
+	e, _ = k.Feed(0x1C)
+	c.Assert(e.Rune, qt.Equals, 'A')
+}
+
+func TestMouse(t *testing.T) {
+	c := qt.New(t)
+
+	m := NewMouse(nil)
+	_, ok := m.Feed(0x00) // out of sync, dropped
+	c.Assert(ok, qt.IsFalse)
+	m.Feed(0x08 | 0x20 | 0x01)
+	m.Feed(10)
+	e, ok := m.Feed(0xFB)
+	c.Assert(ok, qt.IsTrue)
+	c.Assert(e, qt.Equals, MouseEvent{Buttons: 1, DX: 10, DY: -5})
+}
@@ -0,0 +1,24 @@
+//go:build tinygo
+
+package pulse
+
+import (
+	"machine"
+	"time"
+)
+
+type pin = machine.Pin
+
+// New returns a counter on the given pin.
+func New(pin machine.Pin) *Counter {
+	return &Counter{pin: pin, Timeout: 2 * time.Second}
+}
+
+// Configure sets up the pin and its interrupt. The pin mode is
+// machine.PinInput, machine.PinInputPullup or machine.PinInputPulldown.
+func (c *Counter) Configure(mode machine.PinMode) error {
+	c.pin.Configure(machine.PinConfig{Mode: mode})
+	return c.pin.SetInterrupt(machine.PinToggle, func(p machine.Pin) {
+		c.Edge(p.Get(), time.Now().UnixNano())
+	})
+}
@@ -0,0 +1,7 @@
+//go:build !tinygo
+
+package pulse
+
+// pin stands in for machine.Pin, so that the measurement code can be tested
+// with go test.
+type pin uint8
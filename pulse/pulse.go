@@ -0,0 +1,168 @@
+// Package pulse measures the frequency, duty cycle and pulse width of a
+// digital signal, as produced by anemometers, tachometers, flow meters and
+// sensors with a PWM or frequency output.
+//
+// Edges are timestamped in a pin interrupt. Targets with timer input capture
+// can feed more precise timestamps through Edge instead.
+package pulse // import "tinygo.org/x/drivers/pulse"
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+// Counter measures a signal on a pin.
+type Counter struct {
+	pin pin
+
+	// Timeout is the time without a rising edge after which the frequency
+	// is reported as zero. Defaults to 2 seconds.
+	Timeout time.Duration
+
+	// MinPulse ignores edges closer than this to the previous edge, to
+	// debounce mechanical contacts such as reed switches. Zero disables
+	// debouncing.
+	MinPulse time.Duration
+
+	// Written by Edge, protected by seq: odd while an update is in
+	// progress.
+	seq       uint32
+	count     uint32
+	lastEdge  int64
+	firstRise int64
+	lastRise  int64
+	lastFall  int64
+	period    int64
+	high      int64
+	sumHigh   int64
+	sumTime   int64
+
+	// Window state of Average.
+	avgCount   uint32
+	avgRise    int64
+	avgSumHigh int64
+	avgSumTime int64
+}
+
+// Edge records an edge at time t in nanoseconds. It is called from the pin
+// interrupt, or by a timer capture interrupt handler.
+func (c *Counter) Edge(rising bool, t int64) {
+	if c.MinPulse > 0 && c.lastEdge != 0 && t-c.lastEdge < int64(c.MinPulse) {
+		return
+	}
+	atomic.AddUint32(&c.seq, 1)
+	c.lastEdge = t
+	if rising {
+		if c.lastRise != 0 {
+			c.period = t - c.lastRise
+			c.sumTime += c.period
+			if c.lastFall > c.lastRise {
+				c.high = c.lastFall - c.lastRise
+				c.sumHigh += c.high
+			}
+		}
+		if c.firstRise == 0 {
+			c.firstRise = t
+		}
+		c.lastRise = t
+		c.count++
+	} else {
+		c.lastFall = t
+	}
+	atomic.AddUint32(&c.seq, 1)
+}
+
+// snapshot is a consistent copy of the measurements.
+type snapshot struct {
+	count            uint32
+	firstRise        int64
+	lastRise         int64
+	period, high     int64
+	sumHigh, sumTime int64
+}
+
+func (c *Counter) snapshot() snapshot {
+	for {
+		seq := atomic.LoadUint32(&c.seq)
+		if seq&1 != 0 {
+			continue
+		}
+		s := snapshot{c.count, c.firstRise, c.lastRise, c.period, c.high, c.sumHigh, c.sumTime}
+		if atomic.LoadUint32(&c.seq) == seq {
+			return s
+		}
+	}
+}
+
+// Count returns the number of rising edges since the counter was
+// configured.
+func (c *Counter) Count() uint32 {
+	return c.snapshot().count
+}
+
+// stale returns whether the signal stopped.
+func (c *Counter) stale(s snapshot) bool {
+	return s.period == 0 || time.Now().UnixNano()-s.lastRise > int64(c.Timeout)
+}
+
+// Frequency returns the instantaneous frequency in mHz, from the last
+// period.
+func (c *Counter) Frequency() uint32 {
+	s := c.snapshot()
+	if c.stale(s) {
+		return 0
+	}
+	return uint32(int64(time.Second) * 1000 / s.period)
+}
+
+// Period returns the last period.
+func (c *Counter) Period() time.Duration {
+	s := c.snapshot()
+	if c.stale(s) {
+		return 0
+	}
+	return time.Duration(s.period)
+}
+
+// PulseWidth returns the width of the last high pulse.
+func (c *Counter) PulseWidth() time.Duration {
+	return time.Duration(c.snapshot().high)
+}
+
+// DutyCycle returns the duty cycle of the last period in ‰ (1/1000).
+func (c *Counter) DutyCycle() uint32 {
+	s := c.snapshot()
+	if c.stale(s) {
+		return 0
+	}
+	return uint32(s.high * 1000 / s.period)
+}
+
+// Average returns the average frequency in mHz and duty cycle in ‰ since
+// the previous call to Average. Calling it at a fixed interval gives
+// windowed averages. The frequency is calculated from the time between the
+// first and last rising edge of the window, so it is exact even for slow
+// signals.
+func (c *Counter) Average() (frequency, duty uint32) {
+	s := c.snapshot()
+	prevCount, prevRise := c.avgCount, c.avgRise
+	if prevRise == 0 {
+		// First window, starting at the first rising edge.
+		prevCount, prevRise = 1, s.firstRise
+	}
+	edges := s.count - prevCount
+	elapsed := s.lastRise - prevRise
+	sumHigh := s.sumHigh - c.avgSumHigh
+	sumTime := s.sumTime - c.avgSumTime
+	c.avgCount, c.avgRise = s.count, s.lastRise
+	c.avgSumHigh, c.avgSumTime = s.sumHigh, s.sumTime
+
+	if c.stale(s) || edges == 0 || elapsed <= 0 {
+		return 0, 0
+	}
+	frequency = uint32(int64(edges) * int64(time.Second) * 1000 / elapsed)
+	if sumTime > 0 {
+		duty = uint32(sumHigh * 1000 / sumTime)
+	}
+	return frequency, duty
+}
@@ -0,0 +1,50 @@
+package pulse
+
+import (
+	"testing"
+	"time"
+
+	qt "github.com/frankban/quicktest"
+)
+
+func TestCounter(t *testing.T) {
+	c := qt.New(t)
+
+	p := &Counter{Timeout: time.Hour}
+
+	// 50Hz with 25% duty cycle, ending just now.
+	now := time.Now().UnixNano()
+	start := now - 100*int64(20*time.Millisecond)
+	for i := int64(0); i <= 100; i++ {
+		t := start + i*int64(20*time.Millisecond)
+		p.Edge(true, t)
+		if i < 100 {
+			p.Edge(false, t+int64(5*time.Millisecond))
+		}
+	}
+	c.Assert(p.Count(), qt.Equals, uint32(101))
+	c.Assert(p.Frequency(), qt.Equals, uint32(50000))
+	c.Assert(p.DutyCycle(), qt.Equals, uint32(250))
+	c.Assert(p.PulseWidth(), qt.Equals, 5*time.Millisecond)
+
+	f, d := p.Average()
+	c.Assert(f, qt.Equals, uint32(50000))
+	c.Assert(d, qt.Equals, uint32(250))
+
+	// No new edges: nothing in the window.
+	f, _ = p.Average()
+	c.Assert(f, qt.Equals, uint32(0))
+}
+
+func TestDebounce(t *testing.T) {
+	c := qt.New(t)
+
+	p := &Counter{Timeout: 2 * time.Second, MinPulse: time.Millisecond}
+	p.Edge(true, 1000)
+	p.Edge(false, 2000) // bounce, ignored
+	p.Edge(true, 3000)  // bounce, ignored
+	p.Edge(false, int64(10*time.Millisecond))
+	p.Edge(true, int64(20*time.Millisecond))
+	c.Assert(p.Count(), qt.Equals, uint32(2))
+	c.Assert(p.PulseWidth(), qt.Equals, 10*time.Millisecond-1000)
+}
@@ -0,0 +1,124 @@
+// Package qmc5883 implements a driver for the QMC5883L 3-axis magnetic
+// sensor, found on most cheap "HMC5883L" compass modules.
+//
+// Datasheet: https://datasheet.lcsc.com/lcsc/QST-QMC5883L-TR_C192585.pdf
+package qmc5883 // import "tinygo.org/x/drivers/qmc5883"
+
+import (
+	"math"
+	"time"
+
+	"tinygo.org/x/drivers"
+	"tinygo.org/x/drivers/internal/legacy"
+)
+
+// Device wraps an I2C connection to a QMC5883L device.
+type Device struct {
+	bus     drivers.I2C
+	Address uint8
+	r       Range
+}
+
+// Configuration for QMC5883L device.
+type Configuration struct {
+	DataRate     DataRate
+	Range        Range
+	Oversampling Oversampling
+}
+
+// New creates a new QMC5883L connection. The I2C bus must already be
+// configured.
+//
+// This function only creates the Device object, it does not touch the device.
+func New(bus drivers.I2C) Device {
+	return Device{bus: bus, Address: Address}
+}
+
+// Connected returns whether a QMC5883L has been found.
+func (d *Device) Connected() bool {
+	data := []byte{0}
+	err := legacy.ReadRegister(d.bus, d.Address, CHIP_ID, data)
+	return err == nil && data[0] == 0xFF
+}
+
+// Configure resets the device and starts continuous measurements. The zero
+// value of Configuration is 10Hz, ±2 gauss and 512 times oversampling.
+func (d *Device) Configure(cfg Configuration) error {
+	// soft reset
+	err := legacy.WriteRegister(d.bus, d.Address, CONTROL2, []byte{0x80})
+	if err != nil {
+		return err
+	}
+	time.Sleep(10 * time.Millisecond)
+
+	// The datasheet recommends a SET/RESET period of 0x01.
+	err = legacy.WriteRegister(d.bus, d.Address, SET_RESET, []byte{0x01})
+	if err != nil {
+		return err
+	}
+
+	d.r = cfg.Range
+	ctrl := byte(cfg.Oversampling) | byte(cfg.Range) | byte(cfg.DataRate) | byte(MODE_CONTINUOUS)
+	return legacy.WriteRegister(d.bus, d.Address, CONTROL1, []byte{ctrl})
+}
+
+// ReadRawMagneticField returns the raw x, y and z axis from the QMC5883L.
+func (d *Device) ReadRawMagneticField() (x, y, z int16, err error) {
+	data := []byte{0, 0, 0, 0, 0, 0}
+	err = legacy.ReadRegister(d.bus, d.Address, DATA_X_LSB, data)
+	if err != nil {
+		return
+	}
+	x = int16(uint16(data[0]) | uint16(data[1])<<8)
+	y = int16(uint16(data[2]) | uint16(data[3])<<8)
+	z = int16(uint16(data[4]) | uint16(data[5])<<8)
+	return
+}
+
+// ReadMagneticField reads the current magnetic field from the device and returns
+// it in mG (milligauss). 1 mG = 0.1 µT (microtesla).
+func (d *Device) ReadMagneticField() (x, y, z int32, err error) {
+	rx, ry, rz, err := d.ReadRawMagneticField()
+	if err != nil {
+		return
+	}
+	// 12000 LSB/G at ±2G, 3000 LSB/G at ±8G
+	div := int32(12)
+	if d.r == RANGE_8G {
+		div = 3
+	}
+	return int32(rx) / div, int32(ry) / div, int32(rz) / div, nil
+}
+
+// DataReady returns whether a new measurement is available.
+func (d *Device) DataReady() (bool, error) {
+	data := []byte{0}
+	err := legacy.ReadRegister(d.bus, d.Address, STATUS, data)
+	return data[0]&STATUS_DRDY != 0, err
+}
+
+// Overflow returns whether an axis of the last measurement was out of the
+// range, which makes it invalid. Use RANGE_8G for stronger fields.
+func (d *Device) Overflow() (bool, error) {
+	data := []byte{0}
+	err := legacy.ReadRegister(d.bus, d.Address, STATUS, data)
+	return data[0]&STATUS_OVL != 0, err
+}
+
+// ReadCompass reads the current compass heading from the device and returns
+// it in degrees. When the z axis is pointing straight to Earth and the x axis
+// is pointing to North, the heading would be zero.
+//
+// The heading is not tilt compensated nor calibrated, see the compass
+// package for that.
+func (d *Device) ReadCompass() (h int32, err error) {
+	x, y, _, err := d.ReadMagneticField()
+	if err != nil {
+		return
+	}
+	rh := math.Atan2(float64(y), float64(x)) * 180 / math.Pi
+	if rh < 0 {
+		rh += 360
+	}
+	return int32(rh), nil
+}
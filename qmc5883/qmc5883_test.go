@@ -0,0 +1,80 @@
+package qmc5883
+
+import (
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+	"tinygo.org/x/drivers/tester"
+)
+
+func TestConfigure(t *testing.T) {
+	c := qt.New(t)
+	bus := tester.NewI2CBus(c)
+	fake := bus.NewDevice(Address)
+	fake.Registers[CHIP_ID] = 0xFF
+
+	dev := New(bus)
+	c.Assert(dev.Connected(), qt.IsTrue)
+	c.Assert(dev.Configure(Configuration{
+		DataRate:     DATARATE_50HZ,
+		Range:        RANGE_8G,
+		Oversampling: OSR_256,
+	}), qt.IsNil)
+	c.Assert(fake.Registers[CONTROL2], qt.Equals, uint8(0x80))
+	c.Assert(fake.Registers[SET_RESET], qt.Equals, uint8(0x01))
+	c.Assert(fake.Registers[CONTROL1], qt.Equals, uint8(0x55))
+
+	fake.Registers[CHIP_ID] = 0x00
+	c.Assert(dev.Connected(), qt.IsFalse)
+}
+
+func TestStatus(t *testing.T) {
+	c := qt.New(t)
+	bus := tester.NewI2CBus(c)
+	fake := bus.NewDevice(Address)
+	dev := New(bus)
+
+	ready, err := dev.DataReady()
+	c.Assert(err, qt.IsNil)
+	c.Assert(ready, qt.IsFalse)
+
+	fake.Registers[STATUS] = STATUS_DRDY | STATUS_OVL
+	ready, _ = dev.DataReady()
+	c.Assert(ready, qt.IsTrue)
+	ovl, err := dev.Overflow()
+	c.Assert(err, qt.IsNil)
+	c.Assert(ovl, qt.IsTrue)
+
+	fake.Registers[STATUS] = STATUS_DRDY | STATUS_DOR
+	ovl, _ = dev.Overflow()
+	c.Assert(ovl, qt.IsFalse)
+}
+
+func TestReadMagneticField(t *testing.T) {
+	c := qt.New(t)
+	bus := tester.NewI2CBus(c)
+	fake := bus.NewDevice(Address)
+	dev := New(bus)
+	c.Assert(dev.Configure(Configuration{}), qt.IsNil)
+
+	// Little endian: x = 12000, y = -6000, z = 258.
+	copy(fake.Registers[DATA_X_LSB:], []byte{0xE0, 0x2E, 0x90, 0xE8, 0x02, 0x01})
+	x, y, z, err := dev.ReadRawMagneticField()
+	c.Assert(err, qt.IsNil)
+	c.Assert([]int16{x, y, z}, qt.DeepEquals, []int16{12000, -6000, 258})
+
+	mx, my, mz, err := dev.ReadMagneticField()
+	c.Assert(err, qt.IsNil)
+	c.Assert([]int32{mx, my, mz}, qt.DeepEquals, []int32{1000, -500, 21})
+
+	// 3000 LSB/G at ±8G.
+	c.Assert(dev.Configure(Configuration{Range: RANGE_8G}), qt.IsNil)
+	mx, my, mz, _ = dev.ReadMagneticField()
+	c.Assert([]int32{mx, my, mz}, qt.DeepEquals, []int32{4000, -2000, 86})
+
+	// x = 12000, y = -12000.
+	copy(fake.Registers[DATA_X_LSB:], []byte{0xE0, 0x2E, 0x20, 0xD1, 0x00, 0x00})
+	h, err := dev.ReadCompass()
+	c.Assert(err, qt.IsNil)
+	c.Assert(h, qt.Equals, int32(315))
+}
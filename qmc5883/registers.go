@@ -0,0 +1,60 @@
+package qmc5883
+
+// Constants/addresses used for I2C.
+
+// The I2C address which this device listens to.
+const Address = 0x0D
+
+// Registers.
+const (
+	DATA_X_LSB = 0x00
+	STATUS     = 0x06
+	TEMP_LSB   = 0x07
+	CONTROL1   = 0x09
+	CONTROL2   = 0x0A
+	SET_RESET  = 0x0B
+	CHIP_ID    = 0x0D
+)
+
+// Status register bits.
+const (
+	STATUS_DRDY = 0x01 // data ready
+	STATUS_OVL  = 0x02 // overflow
+	STATUS_DOR  = 0x04 // data skipped for reading
+)
+
+// Mode of operation.
+type Mode uint8
+
+const (
+	MODE_STANDBY    Mode = 0x00
+	MODE_CONTINUOUS Mode = 0x01
+)
+
+// DataRate is the output data rate in continuous mode.
+type DataRate uint8
+
+const (
+	DATARATE_10HZ  DataRate = 0x00 << 2
+	DATARATE_50HZ  DataRate = 0x01 << 2
+	DATARATE_100HZ DataRate = 0x02 << 2
+	DATARATE_200HZ DataRate = 0x03 << 2
+)
+
+// Range is the full scale range of the magnetometer.
+type Range uint8
+
+const (
+	RANGE_2G Range = 0x00 << 4 // ±2 gauss, 12000 LSB/G
+	RANGE_8G Range = 0x01 << 4 // ±8 gauss, 3000 LSB/G
+)
+
+// Oversampling is the over sample ratio of the internal filter.
+type Oversampling uint8
+
+const (
+	OSR_512 Oversampling = 0x00 << 6
+	OSR_256 Oversampling = 0x01 << 6
+	OSR_128 Oversampling = 0x02 << 6
+	OSR_64  Oversampling = 0x03 << 6
+)
@@ -0,0 +1,305 @@
+// Package rda5807 implements a driver for the RDA5807M FM radio receiver,
+// with seek, tuning and RDS decoding.
+//
+// The Device implements tuner.Tuner, like the Si4703 driver, so a radio can
+// use either chip.
+package rda5807 // import "tinygo.org/x/drivers/rda5807"
+
+import (
+	"errors"
+	"time"
+
+	"tinygo.org/x/drivers"
+	"tinygo.org/x/drivers/tuner"
+)
+
+var (
+	errNotFound = errors.New("rda5807: device not found")
+	errTimeout  = errors.New("rda5807: tuning timeout")
+	errRange    = errors.New("rda5807: frequency out of band")
+
+	// ErrSeekFailed is returned by Seek when no station was found before
+	// the end of the band.
+	ErrSeekFailed = tuner.ErrSeekFailed
+)
+
+// Band is the FM band of the region.
+type Band uint8
+
+const (
+	BandWorld      Band = iota // 87-108MHz, United States and Europe
+	BandJapan                  // 76-91MHz
+	BandJapanWide              // 76-108MHz
+	BandEastEurope             // 65-76MHz
+)
+
+// Spacing is the channel spacing of the region.
+type Spacing uint8
+
+const (
+	Spacing100kHz Spacing = iota // Europe, Japan
+	Spacing200kHz                // United States, Australia
+	Spacing50kHz
+	Spacing25kHz
+)
+
+// Config is the configuration of the receiver.
+type Config struct {
+	Band    Band
+	Spacing Spacing
+
+	// DeEmphasis50us selects the de-emphasis of Europe, Australia and
+	// Japan; it is 75µs otherwise, as in the United States.
+	DeEmphasis50us bool
+
+	// BassBoost raises the bass of the audio outputs.
+	BassBoost bool
+
+	// Volume is from 1 to 15, 15 when 0. See SetVolume.
+	Volume uint8
+}
+
+// Device wraps an I2C connection to an RDA5807M device.
+type Device struct {
+	bus     drivers.I2C
+	regs    [16]uint16
+	bottom  uint32 // kHz
+	top     uint32 // kHz
+	spacing uint32 // kHz
+	volume  uint8
+	muted   bool
+	buf     [12]byte
+	rds     tuner.RDS
+}
+
+// New creates a new RDA5807M connection. The I2C bus must already be
+// configured.
+//
+// This function only creates the Device object, it does not touch the device.
+func New(bus drivers.I2C) *Device {
+	return &Device{
+		bus: bus,
+	}
+}
+
+// Configure resets the receiver, powers it up, and enables RDS. It takes
+// about 100ms.
+func (d *Device) Configure(cfg Config) error {
+	if err := d.bus.Tx(Address, []byte{REG_CHIPID}, d.buf[:2]); err != nil {
+		return err
+	}
+	if d.buf[0] != CHIP_ID {
+		return errNotFound
+	}
+	d.regs[REG_CTRL] = CTRL_SOFTRESET | CTRL_ENABLE
+	if err := d.writeRegister(REG_CTRL); err != nil {
+		return err
+	}
+	time.Sleep(10 * time.Millisecond)
+
+	d.volume = cfg.Volume
+	if d.volume == 0 {
+		d.volume = 15
+	}
+	d.regs[REG_CTRL] = CTRL_DHIZ | CTRL_DMUTE | CTRL_RDS | CTRL_NEWMETHOD | CTRL_ENABLE
+	if cfg.BassBoost {
+		d.regs[REG_CTRL] |= CTRL_BASS
+	}
+	d.regs[REG_CHANNEL] = uint16(cfg.Band&0x03)<<2 | uint16(cfg.Spacing&0x03)
+	d.regs[REG_CONFIG] = CONFIG_SOFTMUTE
+	if cfg.DeEmphasis50us {
+		d.regs[REG_CONFIG] |= CONFIG_DE
+	}
+	// The default seek threshold, and the antenna on the LNAP input.
+	d.regs[REG_VOLUME] = VOLUME_INTMODE | 8<<8 | VOLUME_LNAP | uint16(d.volume&0x0F)
+	for reg := REG_CTRL; reg <= REG_VOLUME; reg++ {
+		if err := d.writeRegister(reg); err != nil {
+			return err
+		}
+	}
+	time.Sleep(100 * time.Millisecond)
+
+	d.bottom, d.top = 87000, 108000
+	switch cfg.Band & 0x03 {
+	case BandJapan:
+		d.bottom, d.top = 76000, 91000
+	case BandJapanWide:
+		d.bottom = 76000
+	case BandEastEurope:
+		d.bottom, d.top = 65000, 76000
+	}
+	d.spacing = [4]uint32{100, 200, 50, 25}[cfg.Spacing&0x03]
+	return nil
+}
+
+// Tune tunes to a frequency in kHz, for instance 101100 for 101.1MHz.
+func (d *Device) Tune(khz uint32) error {
+	if khz < d.bottom || khz > d.top {
+		return errRange
+	}
+	channel := (khz - d.bottom) / d.spacing
+	d.regs[REG_CHANNEL] = d.regs[REG_CHANNEL]&0x000F | uint16(channel)<<6 | CHANNEL_TUNE
+	err := d.writeRegister(REG_CHANNEL)
+	if err == nil {
+		_, err = d.complete(200 * time.Millisecond)
+	}
+	// The TUNE bit is cleared by the RDA5807M at the end.
+	d.regs[REG_CHANNEL] &^= CHANNEL_TUNE
+	return err
+}
+
+// Seek tunes to the next station up or down the band, and returns its
+// frequency in kHz. It stops at the end of the band, with ErrSeekFailed.
+func (d *Device) Seek(up bool) (uint32, error) {
+	d.regs[REG_CTRL] |= CTRL_SEEK | CTRL_SKMODE
+	d.regs[REG_CTRL] &^= CTRL_SEEKUP
+	if up {
+		d.regs[REG_CTRL] |= CTRL_SEEKUP
+	}
+	err := d.writeRegister(REG_CTRL)
+	var status uint16
+	if err == nil {
+		status, err = d.complete(10 * time.Second)
+	}
+	// The SEEK bit is cleared by the RDA5807M at the end.
+	d.regs[REG_CTRL] &^= CTRL_SEEK
+	if err != nil {
+		return 0, err
+	}
+	if status&STATUS_SF != 0 {
+		return d.Frequency(), ErrSeekFailed
+	}
+	return d.Frequency(), nil
+}
+
+// complete waits for the end of a tune or seek, and returns the status at
+// the end.
+func (d *Device) complete(timeout time.Duration) (uint16, error) {
+	deadline := time.Now().Add(timeout)
+	for {
+		if err := d.read(); err != nil {
+			return 0, err
+		}
+		if d.regs[REG_STATUS]&STATUS_STC != 0 {
+			break
+		}
+		if time.Now().After(deadline) {
+			return 0, errTimeout
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	d.rds.Reset()
+	return d.regs[REG_STATUS], nil
+}
+
+// Frequency returns the frequency tuned in kHz, as of the last tune or
+// seek.
+func (d *Device) Frequency() uint32 {
+	return d.bottom + uint32(d.regs[REG_STATUS]&0x03FF)*d.spacing
+}
+
+// RSSI returns the received signal strength, from 0 to 127 on a logarithmic
+// scale.
+func (d *Device) RSSI() (uint8, error) {
+	err := d.read()
+	return uint8(d.regs[REG_RSSI] >> 9), err
+}
+
+// Stereo returns whether the station is received in stereo.
+func (d *Device) Stereo() (bool, error) {
+	err := d.read()
+	return d.regs[REG_STATUS]&STATUS_ST != 0, err
+}
+
+// SetVolume sets the volume from 0 (muted) to 15.
+func (d *Device) SetVolume(volume uint8) error {
+	d.volume = volume & 0x0F
+	d.regs[REG_VOLUME] = d.regs[REG_VOLUME]&^0x000F | uint16(d.volume)
+	if err := d.writeRegister(REG_VOLUME); err != nil {
+		return err
+	}
+	// The lowest volume of the RDA5807M is not silent.
+	return d.setMute()
+}
+
+// Mute mutes or unmutes the audio outputs.
+func (d *Device) Mute(mute bool) error {
+	d.muted = mute
+	return d.setMute()
+}
+
+func (d *Device) setMute() error {
+	// DMUTE disables the mute.
+	d.regs[REG_CTRL] |= CTRL_DMUTE
+	if d.muted || d.volume == 0 {
+		d.regs[REG_CTRL] &^= CTRL_DMUTE
+	}
+	return d.writeRegister(REG_CTRL)
+}
+
+// SetMono forces mono audio, which is less noisy for weak stations.
+func (d *Device) SetMono(mono bool) error {
+	d.regs[REG_CTRL] &^= CTRL_MONO
+	if mono {
+		d.regs[REG_CTRL] |= CTRL_MONO
+	}
+	return d.writeRegister(REG_CTRL)
+}
+
+// SetBassBoost raises the bass of the audio outputs, or not.
+func (d *Device) SetBassBoost(boost bool) error {
+	d.regs[REG_CTRL] &^= CTRL_BASS
+	if boost {
+		d.regs[REG_CTRL] |= CTRL_BASS
+	}
+	return d.writeRegister(REG_CTRL)
+}
+
+// ReadRDS checks for a new RDS group, and decodes it, see RDS. It returns
+// whether a group was received. Groups arrive about 11 times per second, so
+// call it at least every 80ms to get them all.
+func (d *Device) ReadRDS() (bool, error) {
+	if err := d.read(); err != nil {
+		return false, err
+	}
+	status := d.regs[REG_STATUS]
+	if status&STATUS_RDSR == 0 {
+		return false, nil
+	}
+	// Block error rates: 3 means uncorrectable.
+	blerA, blerB := d.regs[REG_RSSI]>>2&0x03, d.regs[REG_RSSI]&0x03
+	if blerB == 3 {
+		// The group type is unknown.
+		return false, nil
+	}
+	// The errors of the blocks C and D are not reported: trust them while
+	// the RDS decoder is synchronized.
+	synced := status&STATUS_RDSS != 0
+	d.rds.Decode(d.regs[REG_RDSA], d.regs[REG_RDSB], d.regs[REG_RDSC], d.regs[REG_RDSD],
+		blerA < 3, synced, synced)
+	return true, nil
+}
+
+// RDS returns the data decoded by ReadRDS since the last tune or seek.
+func (d *Device) RDS() *tuner.RDS {
+	return &d.rds
+}
+
+// read reads the registers 0x0A to 0x0F, the status and RDS data.
+func (d *Device) read() error {
+	if err := d.bus.Tx(SequentialAddress, nil, d.buf[:12]); err != nil {
+		return err
+	}
+	for i := 0; i < 6; i++ {
+		d.regs[REG_STATUS+i] = uint16(d.buf[2*i])<<8 | uint16(d.buf[2*i+1])
+	}
+	return nil
+}
+
+// writeRegister writes a register from d.regs.
+func (d *Device) writeRegister(reg int) error {
+	d.buf[0] = byte(reg)
+	d.buf[1] = byte(d.regs[reg] >> 8)
+	d.buf[2] = byte(d.regs[reg])
+	return d.bus.Tx(Address, d.buf[:3], nil)
+}
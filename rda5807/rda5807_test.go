@@ -0,0 +1,139 @@
+package rda5807
+
+import (
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+	"tinygo.org/x/drivers/tuner"
+)
+
+var _ tuner.Tuner = (*Device)(nil)
+
+// fakeRadio is an RDA5807M on an I2C bus, with a station every 1MHz from
+// 88.1MHz.
+type fakeRadio struct {
+	regs [16]uint16
+}
+
+func (r *fakeRadio) ReadRegister(addr uint8, reg uint8, buf []byte) error  { panic("no registers") }
+func (r *fakeRadio) WriteRegister(addr uint8, reg uint8, buf []byte) error { panic("no registers") }
+
+func (r *fakeRadio) Tx(addr uint16, w, rd []byte) error {
+	switch {
+	case addr == SequentialAddress:
+		for i := 0; i+1 < len(rd); i += 2 {
+			reg := (REG_STATUS + i/2) & 0x0F
+			rd[i], rd[i+1] = byte(r.regs[reg]>>8), byte(r.regs[reg])
+		}
+	case len(w) == 1:
+		rd[0], rd[1] = byte(r.regs[w[0]]>>8), byte(r.regs[w[0]])
+	default:
+		r.regs[w[0]] = uint16(w[1])<<8 | uint16(w[2])
+		r.update(w[0])
+	}
+	return nil
+}
+
+// update runs the tune and seek commands, at 100kHz spacing.
+func (r *fakeRadio) update(reg uint8) {
+	status := &r.regs[REG_STATUS]
+	switch {
+	case reg == REG_CHANNEL && r.regs[REG_CHANNEL]&CHANNEL_TUNE != 0:
+		*status = *status&^0x03FF | r.regs[REG_CHANNEL]>>6
+		*status |= STATUS_STC
+		*status &^= STATUS_SF
+		r.regs[REG_CHANNEL] &^= CHANNEL_TUNE
+	case reg == REG_CTRL && r.regs[REG_CTRL]&CTRL_SEEK != 0:
+		ch := *status & 0x03FF
+		*status &^= STATUS_SF
+		for {
+			if r.regs[REG_CTRL]&CTRL_SEEKUP != 0 {
+				ch++
+			} else {
+				ch--
+			}
+			if ch > 210 {
+				*status |= STATUS_SF
+				break
+			}
+			if (ch-11)%10 == 0 {
+				*status = *status&^0x03FF | ch
+				break
+			}
+		}
+		*status |= STATUS_STC
+		r.regs[REG_CTRL] &^= CTRL_SEEK
+	}
+}
+
+func TestTune(t *testing.T) {
+	c := qt.New(t)
+	radio := &fakeRadio{}
+	radio.regs[REG_CHIPID] = 0x5804
+	d := New(radio)
+	c.Assert(d.Configure(Config{Volume: 10, DeEmphasis50us: true}), qt.IsNil)
+	c.Assert(radio.regs[REG_CTRL], qt.Equals, uint16(0xC00D))
+	c.Assert(radio.regs[REG_CONFIG]&CONFIG_DE, qt.Equals, uint16(CONFIG_DE))
+	c.Assert(radio.regs[REG_VOLUME]&0x000F, qt.Equals, uint16(10))
+
+	c.Assert(d.Tune(101100), qt.IsNil)
+	c.Assert(radio.regs[REG_CHANNEL]>>6, qt.Equals, uint16(141))
+	c.Assert(d.Frequency(), qt.Equals, uint32(101100))
+	c.Assert(d.Tune(120000), qt.Equals, errRange)
+
+	freq, err := d.Seek(true)
+	c.Assert(err, qt.IsNil)
+	c.Assert(freq, qt.Equals, uint32(102100))
+	freq, err = d.Seek(false)
+	c.Assert(err, qt.IsNil)
+	c.Assert(freq, qt.Equals, uint32(101100))
+	d.Tune(107900)
+	_, err = d.Seek(true)
+	c.Assert(err, qt.Equals, ErrSeekFailed)
+	c.Assert(d.regs[REG_CTRL]&CTRL_SEEK, qt.Equals, uint16(0))
+}
+
+func TestVolume(t *testing.T) {
+	c := qt.New(t)
+	radio := &fakeRadio{}
+	radio.regs[REG_CHIPID] = 0x5804
+	d := New(radio)
+	c.Assert(d.Configure(Config{}), qt.IsNil)
+	c.Assert(radio.regs[REG_VOLUME]&0x000F, qt.Equals, uint16(15))
+
+	c.Assert(d.SetVolume(0), qt.IsNil)
+	c.Assert(radio.regs[REG_CTRL]&CTRL_DMUTE, qt.Equals, uint16(0))
+	c.Assert(d.SetVolume(3), qt.IsNil)
+	c.Assert(radio.regs[REG_CTRL]&CTRL_DMUTE, qt.Equals, uint16(CTRL_DMUTE))
+	c.Assert(d.Mute(true), qt.IsNil)
+	c.Assert(d.SetVolume(5), qt.IsNil)
+	c.Assert(radio.regs[REG_CTRL]&CTRL_DMUTE, qt.Equals, uint16(0))
+	c.Assert(radio.regs[REG_VOLUME]&0x000F, qt.Equals, uint16(5))
+}
+
+func TestReadRDS(t *testing.T) {
+	c := qt.New(t)
+	radio := &fakeRadio{}
+	d := New(radio)
+	ok, err := d.ReadRDS()
+	c.Assert(err, qt.IsNil)
+	c.Assert(ok, qt.IsFalse)
+
+	name := "RADIO 1 "
+	for seg := uint16(0); seg < 4; seg++ {
+		radio.regs[REG_STATUS] = STATUS_RDSR | STATUS_RDSS
+		radio.regs[REG_RDSA] = 0xC201
+		radio.regs[REG_RDSB] = 10<<5 | seg
+		radio.regs[REG_RDSD] = uint16(name[2*seg])<<8 | uint16(name[2*seg+1])
+		ok, err = d.ReadRDS()
+		c.Assert(err, qt.IsNil)
+		c.Assert(ok, qt.IsTrue)
+	}
+	c.Assert(d.RDS().StationName(), qt.Equals, name)
+	c.Assert(d.RDS().PTY, qt.Equals, uint8(10))
+
+	// Block B uncorrectable.
+	radio.regs[REG_RSSI] = 0x0003
+	ok, _ = d.ReadRDS()
+	c.Assert(ok, qt.IsFalse)
+}
@@ -0,0 +1,55 @@
+package rda5807
+
+// The I2C addresses of the RDA5807M. Registers are written one at a time at
+// Address, and read in sequence from REG_STATUS at SequentialAddress.
+const (
+	Address           = 0x11
+	SequentialAddress = 0x10
+)
+
+// Registers
+const (
+	REG_CHIPID  = 0x00
+	REG_CTRL    = 0x02
+	REG_CHANNEL = 0x03
+	REG_CONFIG  = 0x04
+	REG_VOLUME  = 0x05
+	REG_STATUS  = 0x0A
+	REG_RSSI    = 0x0B
+	REG_RDSA    = 0x0C
+	REG_RDSB    = 0x0D
+	REG_RDSC    = 0x0E
+	REG_RDSD    = 0x0F
+)
+
+// Register bits
+const (
+	CTRL_DHIZ      = 0x8000
+	CTRL_DMUTE     = 0x4000
+	CTRL_MONO      = 0x2000
+	CTRL_BASS      = 0x1000
+	CTRL_SEEKUP    = 0x0200
+	CTRL_SEEK      = 0x0100
+	CTRL_SKMODE    = 0x0080
+	CTRL_RDS       = 0x0008
+	CTRL_NEWMETHOD = 0x0004
+	CTRL_SOFTRESET = 0x0002
+	CTRL_ENABLE    = 0x0001
+
+	CHANNEL_TUNE = 0x0010
+
+	CONFIG_DE       = 0x0800
+	CONFIG_SOFTMUTE = 0x0200
+
+	VOLUME_INTMODE = 0x8000
+	VOLUME_LNAP    = 0x0080
+
+	STATUS_RDSR = 0x8000
+	STATUS_STC  = 0x4000
+	STATUS_SF   = 0x2000
+	STATUS_RDSS = 0x1000
+	STATUS_ST   = 0x0400
+
+	// CHIP_ID is the high byte of REG_CHIPID.
+	CHIP_ID = 0x58
+)
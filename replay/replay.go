@@ -0,0 +1,245 @@
+// Package replay feeds recorded sensor traces through the same API as the
+// sensor drivers, so that application logic such as thresholds and state
+// machines can be tested on the host against real field data.
+//
+// A trace is a CSV file with a header row. The first column is the time of
+// the reading in milliseconds since the start of the recording, the other
+// columns are named after the value they contain and hold integers in the
+// units used by the drivers:
+//
+//	time,temperature,humidity,accel_x,accel_y,accel_z,motion
+//	0,21500,45000,12000,-3000,1001000,0
+//	100,21510,45020,11000,-2000,999000,1
+//
+// Known column names are temperature (m°C), humidity (hundredths of a
+// percent), pressure (mPa), distance (mm), luminosity (mlx), voltage (mV),
+// concentration (ppm), accel_x/y/z (µg), gyro_x/y/z (µ°/s), mag_x/y/z (nT)
+// and motion (0 or 1, as reported by PIR sensors such as the HC-SR501). Any
+// other column can be read with Value. Empty cells keep the previous value.
+package replay // import "tinygo.org/x/drivers/replay"
+
+import (
+	"encoding/csv"
+	"errors"
+	"io"
+	"strconv"
+	"time"
+
+	"tinygo.org/x/drivers"
+)
+
+var (
+	errNoTime  = errors.New("replay: first column must be time")
+	errColumns = errors.New("replay: wrong number of columns")
+)
+
+// measurements maps the known column names to the measurement that updates
+// them.
+var measurements = map[string]drivers.Measurement{
+	"temperature":   drivers.Temperature,
+	"humidity":      drivers.Humidity,
+	"pressure":      drivers.Pressure,
+	"distance":      drivers.Distance,
+	"luminosity":    drivers.Luminosity,
+	"voltage":       drivers.Voltage,
+	"concentration": drivers.Concentration,
+	"accel_x":       drivers.Acceleration,
+	"accel_y":       drivers.Acceleration,
+	"accel_z":       drivers.Acceleration,
+	"gyro_x":        drivers.AngularVelocity,
+	"gyro_y":        drivers.AngularVelocity,
+	"gyro_z":        drivers.AngularVelocity,
+	"mag_x":         drivers.MagneticField,
+	"mag_y":         drivers.MagneticField,
+	"mag_z":         drivers.MagneticField,
+}
+
+// row is a single line of a trace. set marks the cells that were not empty.
+type row struct {
+	time   time.Duration
+	values []int32
+	set    []bool
+}
+
+// Trace is a parsed sensor recording.
+type Trace struct {
+	columns []string
+	rows    []row
+}
+
+// Parse reads a trace in CSV format.
+func Parse(r io.Reader) (*Trace, error) {
+	cr := csv.NewReader(r)
+	cr.TrimLeadingSpace = true
+	header, err := cr.Read()
+	if err != nil {
+		return nil, err
+	}
+	if len(header) == 0 || header[0] != "time" {
+		return nil, errNoTime
+	}
+	t := &Trace{columns: header[1:]}
+	for {
+		record, err := cr.Read()
+		if err == io.EOF {
+			return t, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		if len(record) != len(header) {
+			return nil, errColumns
+		}
+		ms, err := strconv.ParseInt(record[0], 10, 64)
+		if err != nil {
+			return nil, err
+		}
+		rw := row{
+			time:   time.Duration(ms) * time.Millisecond,
+			values: make([]int32, len(t.columns)),
+			set:    make([]bool, len(t.columns)),
+		}
+		for i, cell := range record[1:] {
+			if cell == "" {
+				continue
+			}
+			v, err := strconv.ParseInt(cell, 10, 32)
+			if err != nil {
+				return nil, err
+			}
+			rw.values[i] = int32(v)
+			rw.set[i] = true
+		}
+		t.rows = append(t.rows, rw)
+	}
+}
+
+// Len returns the number of readings in the trace.
+func (t *Trace) Len() int {
+	return len(t.rows)
+}
+
+// Sensor replays a trace. Every call to Update moves to the next reading.
+type Sensor struct {
+	trace  *Trace
+	next   int
+	time   time.Duration
+	values []int32
+}
+
+// New creates a Sensor that replays t from the start.
+func New(t *Trace) *Sensor {
+	return &Sensor{
+		trace:  t,
+		values: make([]int32, len(t.columns)),
+	}
+}
+
+// Update moves to the next reading of the trace and updates the values of
+// the requested measurements. Columns that don't belong to a measurement are
+// always updated. It returns io.EOF once all readings have been replayed.
+func (s *Sensor) Update(which drivers.Measurement) error {
+	if s.next >= len(s.trace.rows) {
+		return io.EOF
+	}
+	rw := &s.trace.rows[s.next]
+	s.next++
+	s.time = rw.time
+	for i, name := range s.trace.columns {
+		if !rw.set[i] {
+			continue
+		}
+		if m, ok := measurements[name]; ok && which&m == 0 {
+			continue
+		}
+		s.values[i] = rw.values[i]
+	}
+	return nil
+}
+
+// Rewind starts the replay from the first reading again.
+func (s *Sensor) Rewind() {
+	s.next = 0
+	s.time = 0
+	for i := range s.values {
+		s.values[i] = 0
+	}
+}
+
+// Time returns the timestamp of the last reading, relative to the start of
+// the recording.
+func (s *Sensor) Time() time.Duration {
+	return s.time
+}
+
+// Value returns the last value of a column, and whether the trace has such a
+// column.
+func (s *Sensor) Value(column string) (int32, bool) {
+	for i, name := range s.trace.columns {
+		if name == column {
+			return s.values[i], true
+		}
+	}
+	return 0, false
+}
+
+// value returns the last value of a column, or 0 if there is no such column.
+func (s *Sensor) value(column string) int32 {
+	v, _ := s.Value(column)
+	return v
+}
+
+// Temperature returns the last temperature in celsius milli degrees.
+func (s *Sensor) Temperature() int32 {
+	return s.value("temperature")
+}
+
+// Humidity returns the last relative humidity in hundredths of a percent.
+func (s *Sensor) Humidity() int32 {
+	return s.value("humidity")
+}
+
+// Pressure returns the last pressure in milli pascals.
+func (s *Sensor) Pressure() int32 {
+	return s.value("pressure")
+}
+
+// Distance returns the last distance in millimeters.
+func (s *Sensor) Distance() int32 {
+	return s.value("distance")
+}
+
+// Luminosity returns the last illuminance in milli lux.
+func (s *Sensor) Luminosity() int32 {
+	return s.value("luminosity")
+}
+
+// Voltage returns the last voltage in millivolts.
+func (s *Sensor) Voltage() int32 {
+	return s.value("voltage")
+}
+
+// Concentration returns the last gas concentration in ppm.
+func (s *Sensor) Concentration() int32 {
+	return s.value("concentration")
+}
+
+// Acceleration returns the last acceleration in µg (micro-gravity).
+func (s *Sensor) Acceleration() (x, y, z int32) {
+	return s.value("accel_x"), s.value("accel_y"), s.value("accel_z")
+}
+
+// AngularVelocity returns the last rotation in µ°/s (micro-degrees/sec).
+func (s *Sensor) AngularVelocity() (x, y, z int32) {
+	return s.value("gyro_x"), s.value("gyro_y"), s.value("gyro_z")
+}
+
+// MagneticField returns the last magnetic field in nT (nanotesla).
+func (s *Sensor) MagneticField() (x, y, z int32) {
+	return s.value("mag_x"), s.value("mag_y"), s.value("mag_z")
+}
+
+// Motion returns whether the last reading of the motion column was not 0.
+func (s *Sensor) Motion() bool {
+	return s.value("motion") != 0
+}
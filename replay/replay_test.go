@@ -0,0 +1,63 @@
+package replay
+
+import (
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	qt "github.com/frankban/quicktest"
+	"tinygo.org/x/drivers"
+)
+
+const trace = `time,temperature,accel_x,accel_y,accel_z,motion
+0,21500,1000,2000,1000000,0
+100,21600,,,,1
+250,,3000,4000,990000,0
+`
+
+func TestReplay(t *testing.T) {
+	c := qt.New(t)
+	tr, err := Parse(strings.NewReader(trace))
+	c.Assert(err, qt.IsNil)
+	c.Assert(tr.Len(), qt.Equals, 3)
+
+	s := New(tr)
+	c.Assert(s.Update(drivers.AllMeasurements), qt.IsNil)
+	c.Assert(s.Temperature(), qt.Equals, int32(21500))
+	x, y, z := s.Acceleration()
+	c.Assert([]int32{x, y, z}, qt.DeepEquals, []int32{1000, 2000, 1000000})
+	c.Assert(s.Motion(), qt.IsFalse)
+
+	// Empty cells keep the previous value.
+	c.Assert(s.Update(drivers.AllMeasurements), qt.IsNil)
+	c.Assert(s.Time(), qt.Equals, 100*time.Millisecond)
+	c.Assert(s.Temperature(), qt.Equals, int32(21600))
+	x, _, _ = s.Acceleration()
+	c.Assert(x, qt.Equals, int32(1000))
+	c.Assert(s.Motion(), qt.IsTrue)
+
+	// Measurements that are not requested are not updated, other columns
+	// always are.
+	c.Assert(s.Update(drivers.Temperature), qt.IsNil)
+	x, _, _ = s.Acceleration()
+	c.Assert(x, qt.Equals, int32(1000))
+	c.Assert(s.Motion(), qt.IsFalse)
+
+	c.Assert(s.Update(drivers.AllMeasurements), qt.Equals, io.EOF)
+
+	s.Rewind()
+	c.Assert(s.Update(drivers.AllMeasurements), qt.IsNil)
+	c.Assert(s.Temperature(), qt.Equals, int32(21500))
+
+	_, ok := s.Value("humidity")
+	c.Assert(ok, qt.IsFalse)
+}
+
+func TestParseErrors(t *testing.T) {
+	c := qt.New(t)
+	_, err := Parse(strings.NewReader("temperature\n21000\n"))
+	c.Assert(err, qt.Equals, errNoTime)
+	_, err = Parse(strings.NewReader("time,temperature\n0,abc\n"))
+	c.Assert(err, qt.Not(qt.IsNil))
+}
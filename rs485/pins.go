@@ -0,0 +1,28 @@
+//go:build tinygo
+
+package rs485
+
+import (
+	"machine"
+
+	"tinygo.org/x/drivers"
+)
+
+// New returns an RS485 transceiver on a configured UART. de is the pin
+// connected to DE, and re the pin connected to /RE, or machine.NoPin when
+// /RE is tied to DE or to ground.
+//
+// This function only creates the Device object and sets up the pins so that
+// the transceiver does not drive the bus.
+func New(uart drivers.UART, de, re machine.Pin) *Device {
+	de.Configure(machine.PinConfig{Mode: machine.PinOutput})
+	de.Low()
+	d := &Device{uart: uart, de: de.Set}
+	if re != machine.NoPin {
+		re.Configure(machine.PinConfig{Mode: machine.PinOutput})
+		re.Low()
+		d.re = re.Set
+	}
+	d.Configure(Config{})
+	return d
+}
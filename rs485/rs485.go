@@ -0,0 +1,102 @@
+// Package rs485 drives a half-duplex RS485 transceiver such as the MAX485,
+// SP3485 or THVD1410 on a UART.
+//
+// The transceiver only drives the bus while its DE (driver enable) input is
+// high, and only receives while its /RE (receiver enable) input is low. DE
+// must be raised before the first byte is sent and must only be lowered
+// after the stop bit of the last byte has left the UART, or the end of the
+// frame is cut off. As the UART write functions return while the last bytes
+// are still in the transmit FIFO, the time they take on the wire is derived
+// from the baud rate.
+//
+// Device implements drivers.UART, so it can be passed to drivers of RS485
+// devices, such as the Modbus master of the modbus package.
+package rs485 // import "tinygo.org/x/drivers/rs485"
+
+import (
+	"time"
+
+	"tinygo.org/x/drivers"
+)
+
+// Config is the timing of the transceiver.
+type Config struct {
+	// BaudRate is the baud rate the UART was configured with. Defaults to
+	// 9600.
+	BaudRate uint32
+
+	// TurnaroundDelay is an extra time the driver stays enabled after the
+	// last byte, for slow transceivers or long cables.
+	TurnaroundDelay time.Duration
+}
+
+// Device is a UART with a half-duplex RS485 transceiver.
+type Device struct {
+	uart     drivers.UART
+	de       func(level bool) // DE pin
+	re       func(level bool) // /RE pin, nil when tied to DE or ground
+	charTime time.Duration
+	delay    time.Duration
+}
+
+// Configure sets the timing. The transceiver is left receiving.
+func (d *Device) Configure(cfg Config) {
+	if cfg.BaudRate == 0 {
+		cfg.BaudRate = 9600
+	}
+	// A start bit, 8 data bits, a parity bit and a stop bit, rounded up.
+	d.charTime = (11*time.Second + time.Duration(cfg.BaudRate) - 1) / time.Duration(cfg.BaudRate)
+	d.delay = cfg.TurnaroundDelay
+	d.receive()
+}
+
+// Write sends p on the bus. It returns once the last byte has been sent and
+// the transceiver is back to receiving.
+func (d *Device) Write(p []byte) (int, error) {
+	if d.re != nil {
+		d.re(true)
+	}
+	d.de(true)
+	start := time.Now()
+	n, err := d.uart.Write(p)
+	// The bytes can't leave the UART faster than the baud rate, and the
+	// last one is on the wire for at most one more character time.
+	done := start.Add(time.Duration(n+1)*d.charTime + d.delay)
+	for time.Now().Before(done) {
+	}
+	d.receive()
+	return n, err
+}
+
+// Read reads the bytes received so far into p.
+func (d *Device) Read(p []byte) (int, error) {
+	return d.uart.Read(p)
+}
+
+// Buffered returns the number of received bytes waiting to be read.
+func (d *Device) Buffered() int {
+	return d.uart.Buffered()
+}
+
+// Flush discards the received bytes waiting to be read, such as the reply to
+// a request that timed out.
+func (d *Device) Flush() {
+	var buf [16]byte
+	for d.uart.Buffered() > 0 {
+		d.uart.Read(buf[:])
+	}
+}
+
+// CharTime returns the time a byte takes on the wire at the configured baud
+// rate.
+func (d *Device) CharTime() time.Duration {
+	return d.charTime
+}
+
+// receive disables the driver and enables the receiver.
+func (d *Device) receive() {
+	d.de(false)
+	if d.re != nil {
+		d.re(false)
+	}
+}
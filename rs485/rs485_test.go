@@ -0,0 +1,42 @@
+package rs485
+
+import (
+	"testing"
+	"time"
+
+	qt "github.com/frankban/quicktest"
+)
+
+// fakeUART records whether the driver was enabled for every write.
+type fakeUART struct {
+	de      *bool
+	enabled []bool
+}
+
+func (u *fakeUART) Write(b []byte) (int, error) {
+	u.enabled = append(u.enabled, *u.de)
+	return len(b), nil
+}
+
+func (u *fakeUART) Read(b []byte) (int, error) { return 0, nil }
+
+func (u *fakeUART) Buffered() int { return 0 }
+
+func TestWrite(t *testing.T) {
+	c := qt.New(t)
+
+	de := true
+	uart := &fakeUART{de: &de}
+	d := &Device{uart: uart, de: func(level bool) { de = level }}
+	d.Configure(Config{BaudRate: 115200})
+	c.Assert(de, qt.IsFalse)
+	c.Assert(d.CharTime(), qt.Equals, 95487*time.Nanosecond)
+
+	start := time.Now()
+	n, err := d.Write(make([]byte, 10))
+	c.Assert(err, qt.IsNil)
+	c.Assert(n, qt.Equals, 10)
+	c.Assert(uart.enabled, qt.DeepEquals, []bool{true})
+	c.Assert(de, qt.IsFalse)
+	c.Assert(time.Since(start) >= 11*d.CharTime(), qt.IsTrue)
+}
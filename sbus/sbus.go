@@ -0,0 +1,160 @@
+// Package sbus implements a decoder for the FrSky SBUS protocol used by
+// hobby RC receivers.
+//
+// SBUS is a 100000 baud UART signal with 8 data bits, even parity and two
+// stop bits, and it is inverted: most microcontrollers need an external
+// inverter (a single transistor is enough) unless their UART supports
+// inverting the RX line.
+//
+// Protocol reference: https://github.com/bolderflight/sbus
+package sbus // import "tinygo.org/x/drivers/sbus"
+
+import (
+	"time"
+
+	"tinygo.org/x/drivers"
+)
+
+// Channels is the number of proportional channels. Two more digital
+// channels are reported in the flags.
+const Channels = 16
+
+const (
+	frameSize   = 25
+	headerByte  = 0x0F
+	footerByte  = 0x00
+	footerByte2 = 0x04 // some receivers cycle the footer
+)
+
+// Flags of a frame.
+const (
+	FlagChannel17 = 0x01
+	FlagChannel18 = 0x02
+	FlagFrameLost = 0x04
+	FlagFailsafe  = 0x08
+)
+
+// Timeout is the time without a frame after which the signal is considered
+// lost. Receivers send a frame every 7 to 14ms.
+const Timeout = 100 * time.Millisecond
+
+// Frame is a decoded SBUS frame.
+type Frame struct {
+	// Raw 11 bit channel values, 172 to 1811 for the usual 1000 to 2000µs
+	// range.
+	Raw   [Channels]uint16
+	Flags uint8
+}
+
+// Pulse returns a channel as a servo pulse width in µs, centered at 1500.
+func (f *Frame) Pulse(channel int) uint16 {
+	return uint16(uint32(f.Raw[channel])*5/8 + 880)
+}
+
+// Failsafe returns whether the receiver lost the transmitter and the
+// channels hold the failsafe values.
+func (f *Frame) Failsafe() bool {
+	return f.Flags&FlagFailsafe != 0
+}
+
+// Decoder assembles frames from received bytes.
+type Decoder struct {
+	buf [frameSize]byte
+	n   int
+}
+
+// Feed adds a received byte. It returns true with the frame when the byte
+// completes a valid frame.
+func (d *Decoder) Feed(b byte, f *Frame) bool {
+	if d.n == 0 && b != headerByte {
+		return false
+	}
+	d.buf[d.n] = b
+	d.n++
+	if d.n < frameSize {
+		return false
+	}
+	d.n = 0
+	end := d.buf[frameSize-1]
+	if end != footerByte && end&0x0F != footerByte2 {
+		// Out of sync; the header value can appear in the data, so
+		// look for the next one.
+		for i := 1; i < frameSize; i++ {
+			if d.buf[i] == headerByte {
+				d.n = copy(d.buf[:], d.buf[i:])
+				break
+			}
+		}
+		return false
+	}
+	decode(d.buf[1:23], f)
+	f.Flags = d.buf[23]
+	return true
+}
+
+// decode unpacks 16 channels of 11 bits, LSB first.
+func decode(data []byte, f *Frame) {
+	var acc uint32
+	var bits uint
+	ch := 0
+	for _, b := range data {
+		acc |= uint32(b) << bits
+		bits += 8
+		if bits >= 11 {
+			f.Raw[ch] = uint16(acc & 0x7FF)
+			ch++
+			acc >>= 11
+			bits -= 11
+		}
+	}
+}
+
+// Device reads SBUS frames from a UART.
+type Device struct {
+	uart    drivers.UART
+	decoder Decoder
+	frame   Frame
+	last    time.Time
+	buf     [32]byte
+}
+
+// New returns a new SBUS receiver. The UART must already be configured at
+// 100000 baud, 8E2.
+func New(uart drivers.UART) *Device {
+	return &Device{uart: uart}
+}
+
+// Update reads the received bytes and returns whether a new frame was
+// decoded.
+func (d *Device) Update() (bool, error) {
+	updated := false
+	for d.uart.Buffered() > 0 {
+		n, err := d.uart.Read(d.buf[:])
+		if err != nil {
+			return updated, err
+		}
+		for _, b := range d.buf[:n] {
+			if d.decoder.Feed(b, &d.frame) {
+				d.last = time.Now()
+				updated = true
+			}
+		}
+	}
+	return updated, nil
+}
+
+// Frame returns the last decoded frame.
+func (d *Device) Frame() *Frame {
+	return &d.frame
+}
+
+// Pulse returns a channel of the last frame as a servo pulse width in µs.
+func (d *Device) Pulse(channel int) uint16 {
+	return d.frame.Pulse(channel)
+}
+
+// SignalLost returns whether no frame was received for Timeout, or the
+// receiver reports failsafe.
+func (d *Device) SignalLost() bool {
+	return d.last.IsZero() || time.Since(d.last) > Timeout || d.frame.Failsafe()
+}
@@ -0,0 +1,49 @@
+package sbus
+
+import (
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+)
+
+// encode packs 16 channels of 11 bits, as a receiver does.
+func encode(raw [Channels]uint16, flags uint8) []byte {
+	frame := []byte{headerByte}
+	var acc uint32
+	var bits uint
+	for _, v := range raw {
+		acc |= uint32(v&0x7FF) << bits
+		bits += 11
+		for bits >= 8 {
+			frame = append(frame, byte(acc))
+			acc >>= 8
+			bits -= 8
+		}
+	}
+	return append(frame, flags, footerByte)
+}
+
+func TestDecoder(t *testing.T) {
+	c := qt.New(t)
+
+	var raw [Channels]uint16
+	for i := range raw {
+		raw[i] = uint16(172 + i*100)
+	}
+	raw[0] = 992
+
+	var d Decoder
+	var f Frame
+	// Garbage before the frame is skipped.
+	stream := append([]byte{0x12, 0x34}, encode(raw, FlagFailsafe)...)
+	complete := 0
+	for _, b := range stream {
+		if d.Feed(b, &f) {
+			complete++
+		}
+	}
+	c.Assert(complete, qt.Equals, 1)
+	c.Assert(f.Raw, qt.Equals, raw)
+	c.Assert(f.Failsafe(), qt.IsTrue)
+	c.Assert(f.Pulse(0), qt.Equals, uint16(1500))
+}
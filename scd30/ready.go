@@ -0,0 +1,13 @@
+//go:build tinygo
+
+package scd30
+
+import "machine"
+
+// SetReadyPin sets the pin connected to the RDY output of the sensor, which
+// is high while a new measurement is available. DataReady then checks the
+// pin instead of asking the sensor over I2C.
+func (d *Device) SetReadyPin(pin machine.Pin) {
+	pin.Configure(machine.PinConfig{Mode: machine.PinInput})
+	d.ready = pin.Get
+}
@@ -0,0 +1,18 @@
+package scd30
+
+const (
+	// Address is default I2C address.
+	Address = 0x61
+
+	CmdStartContinuous     = 0x0010
+	CmdStopContinuous      = 0x0104
+	CmdMeasurementInterval = 0x4600
+	CmdDataReady           = 0x0202
+	CmdReadMeasurement     = 0x0300
+	CmdAutoCalibration     = 0x5306
+	CmdForcedRecalibration = 0x5204
+	CmdTemperatureOffset   = 0x5403
+	CmdAltitude            = 0x5102
+	CmdFirmwareVersion     = 0xD100
+	CmdSoftReset           = 0xD304
+)
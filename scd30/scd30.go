@@ -0,0 +1,246 @@
+// Package scd30 provides a driver for the SCD30 CO2, temperature and humidity
+// sensor by Sensirion.
+//
+// The SCD30 uses a different protocol than the SCD4x: measurements run
+// continuously at a configurable interval, results are IEEE754 floats, and
+// the ambient pressure compensation is passed when starting the measurement.
+//
+// Interface description: https://sensirion.com/media/documents/D7CEEF4A/6165372F/Sensirion_CO2_Sensors_SCD30_Interface_Description.pdf
+package scd30 // import "tinygo.org/x/drivers/scd30"
+
+import (
+	"encoding/binary"
+	"errors"
+	"math"
+	"time"
+
+	"tinygo.org/x/drivers"
+)
+
+var (
+	errCRC      = errors.New("scd30: CRC mismatch")
+	errInterval = errors.New("scd30: interval must be between 2s and 1800s")
+	errPressure = errors.New("scd30: pressure must be 0 or between 700 and 1400 mbar")
+)
+
+// Config holds the measurement settings.
+type Config struct {
+	// Interval between measurements, from 2s to 1800s. Defaults to 2s.
+	Interval time.Duration
+
+	// Pressure is the ambient pressure in mbar used to compensate the CO2
+	// reading, from 700 to 1400. 0 disables the compensation.
+	Pressure uint16
+}
+
+// Device wraps an I2C connection to a SCD30 device.
+type Device struct {
+	bus     drivers.I2C
+	Address uint8
+	ready   func() bool // RDY pin, nil when not connected
+	tx      [5]byte
+	rx      [18]byte
+
+	// most recent readings
+	co2         float32
+	temperature float32
+	humidity    float32
+}
+
+// New returns a SCD30 device for the provided I2C bus using the default
+// address of 0x61. The bus must be configured at 100kHz or less, and the
+// device uses clock stretching.
+//
+// This function only creates the Device object, it does not touch the device.
+func New(bus drivers.I2C) *Device {
+	return &Device{
+		bus:     bus,
+		Address: Address,
+	}
+}
+
+// Configure sets the measurement interval and starts continuous measurement.
+func (d *Device) Configure(cfg Config) error {
+	if cfg.Interval == 0 {
+		cfg.Interval = 2 * time.Second
+	}
+	if err := d.SetInterval(cfg.Interval); err != nil {
+		return err
+	}
+	return d.StartContinuous(cfg.Pressure)
+}
+
+// StartContinuous starts continuous measurement. pressure is the ambient
+// pressure in mbar for the CO2 compensation, 0 disables it. The setting is
+// stored in the sensor and restored after a power cycle.
+func (d *Device) StartContinuous(pressure uint16) error {
+	if pressure != 0 && (pressure < 700 || pressure > 1400) {
+		return errPressure
+	}
+	return d.sendCommandWithValue(CmdStartContinuous, pressure)
+}
+
+// SetPressure updates the ambient pressure compensation, for example from a
+// barometer reading. It restarts the continuous measurement.
+func (d *Device) SetPressure(pressure uint16) error {
+	return d.StartContinuous(pressure)
+}
+
+// StopContinuous stops the measurement.
+func (d *Device) StopContinuous() error {
+	return d.sendCommand(CmdStopContinuous)
+}
+
+// SetInterval sets the time between two measurements.
+func (d *Device) SetInterval(interval time.Duration) error {
+	s := interval / time.Second
+	if s < 2 || s > 1800 {
+		return errInterval
+	}
+	return d.sendCommandWithValue(CmdMeasurementInterval, uint16(s))
+}
+
+// SetAutoCalibration enables or disables the automatic self-calibration
+// (ASC). With ASC, the sensor needs to see fresh air (around 400ppm) for at
+// least one hour a day and must run continuously for 7 days for the first
+// calibration. The setting is stored in the sensor.
+func (d *Device) SetAutoCalibration(enable bool) error {
+	v := uint16(0)
+	if enable {
+		v = 1
+	}
+	return d.sendCommandWithValue(CmdAutoCalibration, v)
+}
+
+// AutoCalibration returns whether the automatic self-calibration is enabled.
+func (d *Device) AutoCalibration() (bool, error) {
+	v, err := d.readValue(CmdAutoCalibration)
+	return v == 1, err
+}
+
+// ForceRecalibration calibrates the sensor against a known CO2 concentration
+// in ppm, from 400 to 2000. The sensor must have been measuring in a stable
+// environment for at least 2 minutes.
+func (d *Device) ForceRecalibration(ppm uint16) error {
+	return d.sendCommandWithValue(CmdForcedRecalibration, ppm)
+}
+
+// SetTemperatureOffset sets the offset, in celsius milli degrees, that the
+// sensor subtracts from the temperature to compensate for self heating. The
+// resolution is 10 m°C.
+func (d *Device) SetTemperatureOffset(offset uint32) error {
+	return d.sendCommandWithValue(CmdTemperatureOffset, uint16(offset/10))
+}
+
+// SetAltitude sets the altitude above sea level in meters, used for the CO2
+// compensation when no pressure is given to StartContinuous.
+func (d *Device) SetAltitude(meters uint16) error {
+	return d.sendCommandWithValue(CmdAltitude, meters)
+}
+
+// FirmwareVersion returns the firmware version, major in the high byte and
+// minor in the low byte.
+func (d *Device) FirmwareVersion() (uint16, error) {
+	return d.readValue(CmdFirmwareVersion)
+}
+
+// Reset restarts the sensor. Stored settings are kept.
+func (d *Device) Reset() error {
+	err := d.sendCommand(CmdSoftReset)
+	time.Sleep(2 * time.Second)
+	return err
+}
+
+// DataReady returns whether a new measurement is available.
+func (d *Device) DataReady() (bool, error) {
+	if d.ready != nil {
+		return d.ready(), nil
+	}
+	v, err := d.readValue(CmdDataReady)
+	return v == 1, err
+}
+
+// Update reads the last measurement. CO2, temperature and humidity are
+// always read together. Call it only when DataReady returns true, the sensor
+// NACKs the read otherwise.
+func (d *Device) Update(which drivers.Measurement) error {
+	if which&(drivers.Concentration|drivers.Temperature|drivers.Humidity) == 0 {
+		return nil
+	}
+	if err := d.sendCommandWithResult(CmdReadMeasurement, d.rx[:]); err != nil {
+		return err
+	}
+	var values [3]float32
+	for i := range values {
+		w := d.rx[6*i : 6*i+6]
+		if crc8(w[0:2]) != w[2] || crc8(w[3:5]) != w[5] {
+			return errCRC
+		}
+		bits := uint32(w[0])<<24 | uint32(w[1])<<16 | uint32(w[3])<<8 | uint32(w[4])
+		values[i] = math.Float32frombits(bits)
+	}
+	d.co2, d.temperature, d.humidity = values[0], values[1], values[2]
+	return nil
+}
+
+// CO2 returns the last CO2 concentration in ppm (parts per million).
+func (d *Device) CO2() int32 {
+	return int32(d.co2 + 0.5)
+}
+
+// Temperature returns the last temperature in celsius milli degrees.
+func (d *Device) Temperature() int32 {
+	return int32(d.temperature * 1000)
+}
+
+// Humidity returns the last relative humidity in hundredths of a percent.
+func (d *Device) Humidity() int32 {
+	return int32(d.humidity * 100)
+}
+
+func (d *Device) sendCommand(command uint16) error {
+	binary.BigEndian.PutUint16(d.tx[0:], command)
+	return d.bus.Tx(uint16(d.Address), d.tx[0:2], nil)
+}
+
+func (d *Device) sendCommandWithValue(command, value uint16) error {
+	binary.BigEndian.PutUint16(d.tx[0:], command)
+	binary.BigEndian.PutUint16(d.tx[2:], value)
+	d.tx[4] = crc8(d.tx[2:4])
+	return d.bus.Tx(uint16(d.Address), d.tx[0:5], nil)
+}
+
+// sendCommandWithResult sends a command and reads the response. The sensor
+// needs at least 3ms between the two transfers.
+func (d *Device) sendCommandWithResult(command uint16, result []byte) error {
+	if err := d.sendCommand(command); err != nil {
+		return err
+	}
+	time.Sleep(3 * time.Millisecond)
+	return d.bus.Tx(uint16(d.Address), nil, result)
+}
+
+func (d *Device) readValue(command uint16) (uint16, error) {
+	if err := d.sendCommandWithResult(command, d.rx[0:3]); err != nil {
+		return 0, err
+	}
+	if crc8(d.rx[0:2]) != d.rx[2] {
+		return 0, errCRC
+	}
+	return binary.BigEndian.Uint16(d.rx[0:2]), nil
+}
+
+func crc8(buf []byte) uint8 {
+	var crc uint8 = 0xff
+	for _, b := range buf {
+		crc ^= b
+		for i := 0; i < 8; i++ {
+			if crc&0x80 != 0 {
+				crc = (crc << 1) ^ 0x31
+			} else {
+				crc <<= 1
+			}
+		}
+	}
+	return crc
+}
@@ -0,0 +1,61 @@
+package scd30
+
+import (
+	"math"
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+	"tinygo.org/x/drivers"
+	"tinygo.org/x/drivers/tester"
+)
+
+func TestCRC(t *testing.T) {
+	c := qt.New(t)
+	// Example from the interface description.
+	c.Assert(crc8([]byte{0xBE, 0xEF}), qt.Equals, uint8(0x92))
+}
+
+func TestUpdate(t *testing.T) {
+	c := qt.New(t)
+	bus := tester.NewI2CBus(c)
+	fdev := tester.NewI2CDeviceCmd(c, Address)
+	fdev.Commands = map[uint8]*tester.Cmd{
+		0: {
+			Command:  []byte{0x03, 0x00},
+			Mask:     []byte{0xFF, 0xFF},
+			Response: measurement(412.4, 23.5, 45.25),
+		},
+		1: {
+			Command:  []byte{0x02, 0x02},
+			Mask:     []byte{0xFF, 0xFF},
+			Response: []byte{0x00, 0x01, 0xB0},
+		},
+	}
+	bus.AddDevice(fdev)
+
+	dev := New(bus)
+	ready, err := dev.DataReady()
+	c.Assert(err, qt.IsNil)
+	c.Assert(ready, qt.IsTrue)
+
+	c.Assert(dev.Update(drivers.Concentration), qt.IsNil)
+	c.Assert(dev.CO2(), qt.Equals, int32(412))
+	c.Assert(dev.Temperature(), qt.Equals, int32(23500))
+	c.Assert(dev.Humidity(), qt.Equals, int32(4525))
+
+	fdev.Commands[0].Response[2] ^= 0xFF
+	c.Assert(dev.Update(drivers.Concentration), qt.Equals, errCRC)
+}
+
+// measurement returns the response to CmdReadMeasurement for the given
+// values.
+func measurement(values ...float32) []byte {
+	var buf []byte
+	for _, v := range values {
+		bits := math.Float32bits(v)
+		hi := []byte{byte(bits >> 24), byte(bits >> 16)}
+		lo := []byte{byte(bits >> 8), byte(bits)}
+		buf = append(buf, hi[0], hi[1], crc8(hi), lo[0], lo[1], crc8(lo))
+	}
+	return buf
+}
@@ -0,0 +1,227 @@
+package sdcard
+
+import (
+	"fmt"
+	"time"
+
+	"tinygo.org/x/drivers"
+)
+
+// asyncOp is the non-blocking operation in progress.
+type asyncOp uint8
+
+const (
+	asyncNone      asyncOp = iota
+	asyncWrite             // waiting for the card to program the block
+	asyncWriteData         // sending the block in the background
+	asyncRead              // waiting for the data of the block
+	asyncReadData          // receiving the block in the background
+)
+
+// The non-blocking operations split a block transfer so that no single call
+// waits for the card: StartWriteBlock and StartReadBlock only send the
+// command (and the data), Poll then checks the card once per call and
+// returns drivers.ErrWouldBlock until the operation has completed. Every
+// call takes at most the time of a 512 byte SPI transfer, which makes them
+// usable from control loops that must not stall while the card is busy.
+//
+// When the bus is a drivers.AsyncSPI, such as an SPI with DMA, the 512 byte
+// transfers themselves run in the background, and every call returns
+// after a few bytes.
+//
+// Only one operation can be in progress, and the blocking methods must not
+// be used until Poll has returned something other than
+// drivers.ErrWouldBlock.
+
+// StartWriteBlock starts writing 512 bytes from src to a block. src must
+// stay unchanged until Poll returns something other than
+// drivers.ErrWouldBlock. It returns drivers.ErrWouldBlock without doing
+// anything when the card is still busy with a previous write.
+func (d *Device) StartWriteBlock(block uint32, src []byte) error {
+	if len(src) < 512 {
+		return fmt.Errorf("len(src) must be greater than or equal to 512")
+	}
+	if err := d.startCheck(); err != nil {
+		return err
+	}
+	d.cache.drop(int64(block), int64(block)+1)
+
+	addr := block
+	if !d.blockAddressed() {
+		addr <<= 9
+	}
+	if r := d.cmd(CMD24_WRITE_BLOCK, addr, 0xFF); r != 0 {
+		d.cs.High()
+		return commandError(r, "CMD24 error")
+	}
+	d.bus.Transfer(byte(0xFE))
+
+	d.asyncBlock = block
+	if bus, ok := d.bus.(drivers.AsyncSPI); ok {
+		if err := bus.StartTx(src[:512], nil); err != nil {
+			d.cs.High()
+			return err
+		}
+		d.async = asyncWriteData
+		d.asyncCRC = 0xFFFF
+		if d.crcEnabled {
+			// While the block is sent.
+			d.asyncCRC = crc16(src[:512])
+		}
+		return nil
+	}
+	if err := d.bus.Tx(src[:512], nil); err != nil {
+		d.cs.High()
+		return err
+	}
+	crc := d.writeCRC(src[:512])
+	if err := d.dataResponse(block, crc); err != nil {
+		d.cs.High()
+		return err
+	}
+	d.async = asyncWrite
+	d.asyncDeadline = time.Now().Add(d.writeTimeout())
+	return nil
+}
+
+// StartReadBlock starts reading a block into dst, which must be at least
+// 512 bytes long and must stay valid until Poll returns nil. It returns
+// drivers.ErrWouldBlock without doing anything when the card is still busy
+// with a previous write.
+func (d *Device) StartReadBlock(block uint32, dst []byte) error {
+	if len(dst) < 512 {
+		return fmt.Errorf("len(dst) must be greater than or equal to 512")
+	}
+	if err := d.startCheck(); err != nil {
+		return err
+	}
+
+	addr := block
+	if !d.blockAddressed() {
+		addr <<= 9
+	}
+	if r := d.cmd(CMD17_READ_SINGLE_BLOCK, addr, 0xFF); r != 0 {
+		d.cs.High()
+		return commandError(r, "CMD17 error")
+	}
+
+	d.async = asyncRead
+	d.asyncDst = dst
+	d.asyncBlock = block
+	d.asyncDeadline = time.Now().Add(d.readTimeout())
+	return nil
+}
+
+// Poll advances the operation in progress. It returns drivers.ErrWouldBlock
+// while the card is busy, nil when the operation has completed (or when no
+// operation is in progress), or the error that ended the operation.
+func (d *Device) Poll() error {
+	switch d.async {
+	case asyncWriteData:
+		if d.bus.(drivers.AsyncSPI).IsBusy() {
+			return drivers.ErrWouldBlock
+		}
+		d.bus.Transfer(byte(d.asyncCRC >> 8))
+		d.bus.Transfer(byte(d.asyncCRC))
+		if err := d.dataResponse(d.asyncBlock, d.asyncCRC); err != nil {
+			return d.asyncDone(err)
+		}
+		d.async = asyncWrite
+		d.asyncDeadline = time.Now().Add(d.writeTimeout())
+		return drivers.ErrWouldBlock
+
+	case asyncWrite:
+		r, err := d.bus.Transfer(byte(0xFF))
+		if err != nil {
+			return d.asyncDone(err)
+		}
+		if r != 0xFF {
+			if time.Now().After(d.asyncDeadline) {
+				d.stats.timeout()
+				return d.asyncDone(errWriteTimeout)
+			}
+			return drivers.ErrWouldBlock
+		}
+		return d.asyncDone(nil)
+
+	case asyncRead:
+		status, err := d.bus.Transfer(byte(0xFF))
+		if err != nil {
+			return d.asyncDone(err)
+		}
+		if status == 0xFF {
+			if time.Now().After(d.asyncDeadline) {
+				d.stats.timeout()
+				return d.asyncDone(errReadTimeout)
+			}
+			return drivers.ErrWouldBlock
+		}
+		if status != 0xFE {
+			return d.asyncDone(fmt.Errorf("SD_CARD_START_BLOCK %02X", status))
+		}
+		if bus, ok := d.bus.(drivers.AsyncSPI); ok {
+			if err := bus.StartTx(dummy[:512], d.asyncDst[:512]); err != nil {
+				return d.asyncDone(err)
+			}
+			d.async = asyncReadData
+			return drivers.ErrWouldBlock
+		}
+		if err := d.bus.Tx(dummy[:512], d.asyncDst[:512]); err != nil {
+			return d.asyncDone(err)
+		}
+		return d.asyncDone(d.readCRC(d.asyncBlock, d.asyncDst[:512]))
+
+	case asyncReadData:
+		if d.bus.(drivers.AsyncSPI).IsBusy() {
+			return drivers.ErrWouldBlock
+		}
+		return d.asyncDone(d.readCRC(d.asyncBlock, d.asyncDst[:512]))
+	}
+	return nil
+}
+
+// Wait waits for the operation in progress to complete, and returns its
+// error. It yields to the other goroutines while the card is busy.
+func (d *Device) Wait() error {
+	for {
+		err := d.Poll()
+		if err != drivers.ErrWouldBlock {
+			return err
+		}
+		drivers.Yield()
+	}
+}
+
+// Pending returns whether a non-blocking operation is in progress.
+func (d *Device) Pending() bool {
+	return d.async != asyncNone
+}
+
+// startCheck makes sure a new operation can start without waiting.
+func (d *Device) startCheck() error {
+	if d.async != asyncNone {
+		return drivers.ErrWouldBlock
+	}
+	if err := d.checkPresent(); err != nil {
+		return err
+	}
+	// The card holds its data out line low while busy programming.
+	d.cs.Low()
+	r, err := d.bus.Transfer(byte(0xFF))
+	if err != nil {
+		d.cs.High()
+		return err
+	}
+	if r != 0xFF {
+		d.cs.High()
+		return drivers.ErrWouldBlock
+	}
+	return nil
+}
+
+func (d *Device) asyncDone(err error) error {
+	d.cs.High()
+	d.async = asyncNone
+	d.asyncDst = nil
+	return err
+}
@@ -0,0 +1,120 @@
+package sdcard
+
+import (
+	"bytes"
+	"testing"
+
+	"tinygo.org/x/drivers"
+)
+
+// dmaCard is an spiCard on a bus with DMA, whose background transfers take
+// a few calls of IsBusy.
+type dmaCard struct {
+	*spiCard
+	w, r  []byte
+	busy  int
+	large int // blocks transferred with Tx instead of StartTx
+}
+
+func (c *dmaCard) Tx(w, r []byte) error {
+	if len(w) >= 512 || len(r) >= 512 {
+		c.large++
+	}
+	return c.spiCard.Tx(w, r)
+}
+
+func (c *dmaCard) StartTx(w, r []byte) error {
+	c.w, c.r, c.busy = w, r, 3
+	return nil
+}
+
+func (c *dmaCard) IsBusy() bool {
+	if c.busy == 0 {
+		return false
+	}
+	c.busy--
+	if c.busy == 0 {
+		c.spiCard.Tx(c.w, c.r)
+	}
+	return true
+}
+
+func TestAsync(t *testing.T) {
+	for _, dma := range []bool{false, true} {
+		d, card := newSPICard()
+		bus := &dmaCard{spiCard: card}
+		if dma {
+			d.bus = bus
+		}
+		src := make([]byte, 512)
+		for i := range src {
+			src[i] = byte(i * 3)
+		}
+		buf := make([]byte, 512)
+
+		if err := d.StartWriteBlock(2, src); err != nil {
+			t.Fatalf("dma %v: StartWriteBlock: %v", dma, err)
+		}
+		if err := d.StartReadBlock(2, buf); err != drivers.ErrWouldBlock {
+			t.Errorf("dma %v: StartReadBlock during a write: %v", dma, err)
+		}
+		polls := 0
+		for err := d.Poll(); err != nil; err = d.Poll() {
+			if err != drivers.ErrWouldBlock {
+				t.Fatalf("dma %v: Poll: %v", dma, err)
+			}
+			polls++
+		}
+		if want := 3; dma && polls < want+1 || !dma && polls != want {
+			t.Errorf("dma %v: write completed after %d polls", dma, polls)
+		}
+		if !bytes.Equal(card.mem[2*512:3*512], src) {
+			t.Errorf("dma %v: wrong data written", dma)
+		}
+
+		if err := d.StartReadBlock(2, buf); err != nil {
+			t.Fatalf("dma %v: StartReadBlock: %v", dma, err)
+		}
+		if err := d.Wait(); err != nil {
+			t.Fatalf("dma %v: Wait: %v", dma, err)
+		}
+		if !bytes.Equal(buf, src) {
+			t.Errorf("dma %v: wrong data read", dma)
+		}
+		if d.Pending() {
+			t.Errorf("dma %v: still pending", dma)
+		}
+		if bus.large != 0 {
+			t.Errorf("dma %v: %d blocks transferred without DMA", dma, bus.large)
+		}
+	}
+}
+
+// muteBus is a bus without a card, which reads all ones.
+type muteBus struct{}
+
+func (muteBus) Tx(w, r []byte) error {
+	for i := range r {
+		r[i] = 0xFF
+	}
+	return nil
+}
+
+func (muteBus) Transfer(b byte) (byte, error) { return 0xFF, nil }
+
+func TestAsyncCommandError(t *testing.T) {
+	// Commands left unanswered give the same error as the blocking
+	// methods.
+	d, _ := newSPICard()
+	d.bus = muteBus{}
+	buf := make([]byte, 512)
+	if err := d.StartWriteBlock(2, buf); err != errCmdTimeout {
+		t.Errorf("StartWriteBlock: %v", err)
+	}
+	if err := d.StartReadBlock(2, buf); err != errCmdTimeout {
+		t.Errorf("StartReadBlock: %v", err)
+	}
+	if err := d.ReadData(2, buf); err != errCmdTimeout {
+		t.Errorf("ReadData: %v", err)
+	}
+}
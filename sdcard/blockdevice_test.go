@@ -0,0 +1,26 @@
+package sdcard
+
+import "testing"
+
+// blockDevice is the BlockDevice interface of tinyfs.
+type blockDevice interface {
+	ReadAt(buf []byte, off int64) (n int, err error)
+	WriteAt(buf []byte, off int64) (n int, err error)
+	Size() int64
+	WriteBlockSize() int64
+	EraseBlockSize() int64
+	EraseBlocks(start, len int64) error
+}
+
+var _ blockDevice = (*Device)(nil)
+
+func TestSize(t *testing.T) {
+	// 8GB SDHC card.
+	dev := &Device{CSD: &CSD{CSD_STRUCTURE: 1, C_SIZE: 15159}}
+	if got, want := dev.Size(), int64(15160)*512*1024; got != want {
+		t.Errorf("got %d, want %d", got, want)
+	}
+	if got := (&Device{}).Size(); got != 0 {
+		t.Errorf("unconfigured: got %d, want 0", got)
+	}
+}
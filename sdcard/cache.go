@@ -0,0 +1,128 @@
+package sdcard
+
+// blockIO is the block level access of Device and SDIOCard used by the
+// block cache.
+type blockIO interface {
+	readBlock(block int64, dst []byte) error
+	writeBlock(block int64, src []byte) error
+	WriteBlocks(startBlock int64, src []byte) error
+}
+
+// blockCache holds the last block touched by an unaligned read or write in
+// the internal 512 byte buffer of the Device, so that patching a few bytes
+// of a block does not cost a block read and write every time.
+type blockCache struct {
+	buf   []byte
+	block int64
+	valid bool // buf holds block
+	dirty bool // buf was changed and must be written back
+}
+
+// readAt reads len(p) bytes at addr. Whole blocks are read directly into p,
+// unless they are cached.
+func (c *blockCache) readAt(dev blockIO, p []byte, addr int64) (int, error) {
+	n := 0
+	for n < len(p) {
+		pos := addr + int64(n)
+		block := pos / 512
+		start := int(pos % 512)
+		if start == 0 && len(p)-n >= 512 && !(c.valid && c.block == block) {
+			if err := dev.readBlock(block, p[n:n+512]); err != nil {
+				return n, err
+			}
+			n += 512
+			continue
+		}
+		if err := c.load(dev, block); err != nil {
+			return n, err
+		}
+		n += copy(p[n:], c.buf[start:])
+	}
+	return n, nil
+}
+
+// writeAt writes len(p) bytes at addr. Runs of whole blocks are written to
+// the card directly, partial blocks are only changed in the cache.
+func (c *blockCache) writeAt(dev blockIO, p []byte, addr int64) (int, error) {
+	n := 0
+	for n < len(p) {
+		pos := addr + int64(n)
+		block := pos / 512
+		start := int(pos % 512)
+		if start == 0 && len(p)-n >= 512 {
+			full := (len(p) - n) &^ 511
+			// The cached block would be overwritten.
+			c.drop(block, block+int64(full/512))
+			if err := dev.WriteBlocks(block, p[n:n+full]); err != nil {
+				return n, err
+			}
+			n += full
+			continue
+		}
+		if err := c.load(dev, block); err != nil {
+			return n, err
+		}
+		n += copy(c.buf[start:], p[n:])
+		c.dirty = true
+	}
+	return n, nil
+}
+
+// load makes block the cached block, writing back the previous one if it
+// was changed.
+func (c *blockCache) load(dev blockIO, block int64) error {
+	if c.valid && c.block == block {
+		return nil
+	}
+	if err := c.sync(dev); err != nil {
+		return err
+	}
+	c.valid = false
+	if err := dev.readBlock(block, c.buf); err != nil {
+		return err
+	}
+	c.block = block
+	c.valid = true
+	return nil
+}
+
+// sync writes the cached block back to the card if it was changed.
+func (c *blockCache) sync(dev blockIO) error {
+	if !c.valid || !c.dirty {
+		return nil
+	}
+	if err := dev.writeBlock(c.block, c.buf); err != nil {
+		return err
+	}
+	c.dirty = false
+	return nil
+}
+
+// drop forgets the cached block, including changes not written back, if it
+// is between start and end (excluded).
+func (c *blockCache) drop(start, end int64) {
+	if c.valid && c.block >= start && c.block < end {
+		c.reset()
+	}
+}
+
+// reset forgets the cached block.
+func (c *blockCache) reset() {
+	c.valid = false
+	c.dirty = false
+}
+
+func (d *Device) readBlock(block int64, dst []byte) error {
+	return d.ReadData(uint32(block), dst)
+}
+
+func (d *Device) writeBlock(block int64, src []byte) error {
+	return d.WriteData(uint32(block), src)
+}
+
+// Sync writes the block cached by an unaligned WriteAt back to the card.
+// Call it before removing the card or powering it down, and before using the
+// block level methods such as ReadData on the same blocks.
+func (d *Device) Sync() error {
+	return d.cache.sync(d)
+}
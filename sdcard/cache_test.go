@@ -0,0 +1,87 @@
+package sdcard
+
+import (
+	"bytes"
+	"testing"
+)
+
+// memBlocks is a card in memory that counts the block operations.
+type memBlocks struct {
+	data          []byte
+	reads, writes int
+}
+
+func (m *memBlocks) readBlock(block int64, dst []byte) error {
+	m.reads++
+	copy(dst[:512], m.data[block*512:])
+	return nil
+}
+
+func (m *memBlocks) writeBlock(block int64, src []byte) error {
+	m.writes++
+	copy(m.data[block*512:], src[:512])
+	return nil
+}
+
+func (m *memBlocks) WriteBlocks(startBlock int64, src []byte) error {
+	m.writes += len(src) / 512
+	copy(m.data[startBlock*512:], src)
+	return nil
+}
+
+func TestBlockCache(t *testing.T) {
+	card := &memBlocks{data: make([]byte, 4*512)}
+	c := &blockCache{buf: make([]byte, 512)}
+
+	// Patching a few bytes twice reads the block once and writes nothing.
+	c.writeAt(card, []byte("abc"), 10)
+	c.writeAt(card, []byte("de"), 13)
+	if card.reads != 1 || card.writes != 0 {
+		t.Errorf("after patch: %d reads, %d writes", card.reads, card.writes)
+	}
+	buf := make([]byte, 5)
+	c.readAt(card, buf, 10)
+	if string(buf) != "abcde" || card.reads != 1 {
+		t.Errorf("read cached: %q, %d reads", buf, card.reads)
+	}
+	if err := c.sync(card); err != nil || card.writes != 1 {
+		t.Fatalf("sync: %v, %d writes", err, card.writes)
+	}
+	if string(card.data[10:15]) != "abcde" {
+		t.Errorf("card after sync: %q", card.data[10:15])
+	}
+	c.sync(card)
+	if card.writes != 1 {
+		t.Errorf("clean sync wrote")
+	}
+
+	// An unaligned write across blocks writes the whole middle block
+	// directly and caches the last one.
+	src := bytes.Repeat([]byte{0x55}, 1024)
+	c.writeAt(card, src, 1000)
+	if !bytes.Equal(card.data[1024:1536], src[24:536]) {
+		t.Errorf("middle block not written")
+	}
+	if !c.valid || !c.dirty || c.block != 3 {
+		t.Errorf("cache: block %d, valid %v, dirty %v", c.block, c.valid, c.dirty)
+	}
+	if !bytes.Equal(card.data[1000:1024], src[:24]) {
+		t.Errorf("first block not written back")
+	}
+	// Block 3 is written back when block 0 is cached again.
+	c.readAt(card, buf, 0)
+	if !bytes.Equal(card.data[1536:2024], src[536:]) {
+		t.Errorf("evicted block not written back")
+	}
+
+	// A whole block write over the cached block drops the cached changes.
+	c.writeAt(card, []byte("x"), 0)
+	c.writeAt(card, make([]byte, 512), 0)
+	if c.valid || card.data[0] != 0 {
+		t.Errorf("cached block not dropped")
+	}
+	c.sync(card)
+	if card.data[0] != 0 {
+		t.Errorf("dropped block written back")
+	}
+}
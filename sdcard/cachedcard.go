@@ -0,0 +1,276 @@
+package sdcard
+
+// BlockCard is a card of this package with block level access, as used by
+// CachedCard: a *Device, *SDIOCard or *MemoryCard. It has unexported
+// methods, so it is not implemented by other types.
+type BlockCard interface {
+	blockIO
+	Size() int64
+	EraseBlocks(start, len int64) error
+	Sync() error
+}
+
+// CacheStats counts the block accesses of a CachedCard.
+type CacheStats struct {
+	Hits       uint32 // blocks found in the cache
+	Misses     uint32 // blocks read from the card into the cache
+	ReadAheads uint32 // blocks read in advance
+	WriteBacks uint32 // changed blocks written to the card
+	Bypassed   uint32 // blocks of large transfers, not cached
+}
+
+// cacheEntry is a block of the cache.
+type cacheEntry struct {
+	block int64
+	used  uint32 // time of the last access, for the LRU replacement
+	valid bool
+	dirty bool
+}
+
+// CachedCard is a card behind a cache of the blocks read and written last,
+// with the same block device methods as the card. File systems read and
+// write their metadata (the FAT, directories, the superblock) over and over,
+// which the cache mostly serves without any transfer.
+//
+// Blocks written are only written to the card when they leave the cache, or
+// by Flush or Sync: call one of them before the card is removed or powered
+// down. Transfers
+// of two blocks or more, usually file data, go to the card directly, so as
+// not to push the metadata out of the cache.
+//
+// The card must not be used other than through the CachedCard, and a
+// CachedCard must not be used concurrently.
+type CachedCard struct {
+	dev       BlockCard
+	buf       []byte
+	entries   []cacheEntry
+	clock     uint32
+	readAhead bool
+	stats     CacheStats
+}
+
+// NewCachedCard returns a cache of the given number of blocks, 512 bytes
+// each, for a configured *Device or *SDIOCard.
+func NewCachedCard(card BlockCard, blocks int) *CachedCard {
+	if blocks < 1 {
+		blocks = 1
+	}
+	return &CachedCard{
+		dev:     card,
+		buf:     make([]byte, blocks*512),
+		entries: make([]cacheEntry, blocks),
+	}
+}
+
+// SetReadAhead sets whether the block following a block missing from the
+// cache is read along with it, for file systems that read metadata
+// sequentially.
+func (c *CachedCard) SetReadAhead(enable bool) {
+	c.readAhead = enable
+}
+
+// Stats returns the counts of block accesses since the cache was created or
+// since ResetStats.
+func (c *CachedCard) Stats() CacheStats {
+	return c.stats
+}
+
+// ResetStats sets the counts of Stats back to 0.
+func (c *CachedCard) ResetStats() {
+	c.stats = CacheStats{}
+}
+
+// ReadAt reads len(buf) bytes at addr, which may start and end anywhere.
+func (c *CachedCard) ReadAt(buf []byte, addr int64) (int, error) {
+	large := len(buf) >= 2*512
+	n := 0
+	for n < len(buf) {
+		pos := addr + int64(n)
+		block := pos / 512
+		start := int(pos % 512)
+		if large && start == 0 && len(buf)-n >= 512 && c.find(block) < 0 {
+			if err := c.dev.readBlock(block, buf[n:n+512]); err != nil {
+				return n, err
+			}
+			c.stats.Bypassed++
+			n += 512
+			continue
+		}
+		i, err := c.load(block, true)
+		if err != nil {
+			return n, err
+		}
+		n += copy(buf[n:], c.data(i)[start:])
+	}
+	return n, nil
+}
+
+// WriteAt writes len(buf) bytes at addr, which may start and end anywhere.
+// The blocks written stay in the cache until Flush.
+func (c *CachedCard) WriteAt(buf []byte, addr int64) (int, error) {
+	large := len(buf) >= 2*512
+	n := 0
+	for n < len(buf) {
+		pos := addr + int64(n)
+		block := pos / 512
+		start := int(pos % 512)
+		whole := start == 0 && len(buf)-n >= 512
+		if large && whole && c.find(block) < 0 {
+			if err := c.dev.writeBlock(block, buf[n:n+512]); err != nil {
+				return n, err
+			}
+			c.stats.Bypassed++
+			n += 512
+			continue
+		}
+		// A block written whole does not need to be read first.
+		i, err := c.load(block, !whole)
+		if err != nil {
+			return n, err
+		}
+		n += copy(c.data(i)[start:], buf[n:])
+		c.entries[i].dirty = true
+	}
+	return n, nil
+}
+
+// Flush writes the blocks changed in the cache to the card, in ascending
+// order.
+func (c *CachedCard) Flush() error {
+	for {
+		next := -1
+		for i, e := range c.entries {
+			if e.valid && e.dirty && (next < 0 || e.block < c.entries[next].block) {
+				next = i
+			}
+		}
+		if next < 0 {
+			return nil
+		}
+		if err := c.writeBack(next); err != nil {
+			return err
+		}
+	}
+}
+
+// Sync writes the blocks changed in the cache to the card, as Flush does,
+// then syncs the card. File systems call it when their files are synced or
+// closed.
+func (c *CachedCard) Sync() error {
+	if err := c.Flush(); err != nil {
+		return err
+	}
+	return c.dev.Sync()
+}
+
+// Size returns the size of the card in bytes.
+func (c *CachedCard) Size() int64 {
+	return c.dev.Size()
+}
+
+// WriteBlockSize returns the block size in which data can be written to
+// memory.
+func (c *CachedCard) WriteBlockSize() int64 {
+	return 512
+}
+
+// EraseBlockSize returns the smallest erasable area on the card in bytes.
+func (c *CachedCard) EraseBlockSize() int64 {
+	return 512
+}
+
+// EraseBlocks erases the given number of blocks, and drops them from the
+// cache, with their changes.
+func (c *CachedCard) EraseBlocks(start, len int64) error {
+	for i := range c.entries {
+		if e := &c.entries[i]; e.valid && e.block >= start && e.block < start+len {
+			*e = cacheEntry{}
+		}
+	}
+	return c.dev.EraseBlocks(start, len)
+}
+
+// find returns the entry holding block, or -1.
+func (c *CachedCard) find(block int64) int {
+	for i, e := range c.entries {
+		if e.valid && e.block == block {
+			return i
+		}
+	}
+	return -1
+}
+
+// load returns the entry holding block, after reading it from the card
+// when read is set and it is not cached.
+func (c *CachedCard) load(block int64, read bool) (int, error) {
+	c.clock++
+	if i := c.find(block); i >= 0 {
+		c.stats.Hits++
+		c.entries[i].used = c.clock
+		return i, nil
+	}
+	i, err := c.fill(block, read)
+	if err != nil || !read {
+		return i, err
+	}
+	c.stats.Misses++
+	if c.readAhead && (block+1)*512 < c.Size() && c.find(block+1) < 0 && len(c.entries) > 1 {
+		// Keep the block asked for from being replaced.
+		c.entries[i].used = c.clock
+		c.clock++
+		j, err := c.fill(block+1, true)
+		if err != nil {
+			return i, err
+		}
+		c.stats.ReadAheads++
+		// Less recently used than the block asked for.
+		c.entries[j].used = c.clock - 1
+		c.entries[i].used = c.clock
+	}
+	return i, nil
+}
+
+// fill replaces the least recently used entry by block.
+func (c *CachedCard) fill(block int64, read bool) (int, error) {
+	lru := 0
+	for i, e := range c.entries {
+		if !e.valid {
+			lru = i
+			break
+		}
+		if e.used < c.entries[lru].used {
+			lru = i
+		}
+	}
+	if err := c.writeBack(lru); err != nil {
+		return lru, err
+	}
+	e := &c.entries[lru]
+	e.valid = false
+	if read {
+		if err := c.dev.readBlock(block, c.data(lru)); err != nil {
+			return lru, err
+		}
+	}
+	*e = cacheEntry{block: block, used: c.clock, valid: true}
+	return lru, nil
+}
+
+// writeBack writes an entry to the card if it was changed.
+func (c *CachedCard) writeBack(i int) error {
+	e := &c.entries[i]
+	if !e.valid || !e.dirty {
+		return nil
+	}
+	if err := c.dev.writeBlock(e.block, c.data(i)); err != nil {
+		return err
+	}
+	e.dirty = false
+	c.stats.WriteBacks++
+	return nil
+}
+
+// data returns the buffer of an entry.
+func (c *CachedCard) data(i int) []byte {
+	return c.buf[i*512 : (i+1)*512]
+}
@@ -0,0 +1,131 @@
+package sdcard
+
+import (
+	"bytes"
+	"testing"
+)
+
+// sizedBlocks is a memBlocks with the methods used by CachedCard.
+type sizedBlocks struct {
+	memBlocks
+	syncs int
+}
+
+var (
+	_ BlockCard = (*Device)(nil)
+	_ BlockCard = (*SDIOCard)(nil)
+	_ BlockCard = (*MemoryCard)(nil)
+)
+
+func (m *sizedBlocks) Size() int64 {
+	return int64(len(m.data))
+}
+
+func (m *sizedBlocks) EraseBlocks(start, len int64) error {
+	for i := range m.data[start*512 : (start+len)*512] {
+		m.data[start*512+int64(i)] = 0
+	}
+	return nil
+}
+
+func (m *sizedBlocks) Sync() error {
+	m.syncs++
+	return nil
+}
+
+func TestCachedCard(t *testing.T) {
+	card := &sizedBlocks{memBlocks: memBlocks{data: make([]byte, 16*512)}}
+	for i := range card.data {
+		card.data[i] = byte(i / 512)
+	}
+	c := NewCachedCard(card, 2)
+
+	// Reading block 3 twice reads it from the card once.
+	buf := make([]byte, 4)
+	c.ReadAt(buf, 3*512+10)
+	c.ReadAt(buf, 3*512+100)
+	if buf[0] != 3 || card.reads != 1 {
+		t.Errorf("read: %v, %d reads", buf, card.reads)
+	}
+	if s := c.Stats(); s.Hits != 1 || s.Misses != 1 {
+		t.Errorf("stats %+v", s)
+	}
+
+	// Writes stay in the cache until Flush, which writes in order.
+	c.WriteAt([]byte("xy"), 5*512)
+	c.WriteAt([]byte("ab"), 3*512)
+	if card.writes != 0 {
+		t.Errorf("%d writes before Flush", card.writes)
+	}
+	if err := c.Flush(); err != nil || card.writes != 2 {
+		t.Fatalf("Flush: %v, %d writes", err, card.writes)
+	}
+	if string(card.data[3*512:3*512+2]) != "ab" || string(card.data[5*512:5*512+2]) != "xy" {
+		t.Error("card not written by Flush")
+	}
+	c.Flush()
+	if card.writes != 2 {
+		t.Error("clean Flush wrote")
+	}
+
+	// Sync flushes the cache and syncs the card.
+	c.WriteAt([]byte("zz"), 5*512)
+	if err := c.Sync(); err != nil || card.writes != 3 || card.syncs != 1 {
+		t.Fatalf("Sync: %v, %d writes, %d syncs", err, card.writes, card.syncs)
+	}
+
+	// Block 3 was used less recently than block 5, and makes room for 7.
+	c.WriteAt([]byte("cd"), 3*512)
+	c.ReadAt(buf, 5*512)
+	c.ReadAt(buf, 7*512)
+	if card.writes != 4 || c.Stats().WriteBacks != 4 {
+		t.Errorf("replaced changed block: %d writes", card.writes)
+	}
+	if string(card.data[3*512:3*512+2]) != "cd" {
+		t.Error("replaced block not written")
+	}
+
+	// A block written whole is not read.
+	reads := card.reads
+	c.WriteAt(bytes.Repeat([]byte{0xAA}, 512), 9*512)
+	if card.reads != reads {
+		t.Error("block written whole was read")
+	}
+
+	// Large transfers go around the cache, except for the blocks cached.
+	c.ResetStats()
+	big := make([]byte, 4*512)
+	if n, err := c.ReadAt(big, 8*512); n != len(big) || err != nil {
+		t.Fatalf("large read: %d, %v", n, err)
+	}
+	if big[0] != 8 || big[512] != 0xAA || big[3*512] != 11 {
+		t.Errorf("large read: %d %d %d", big[0], big[512], big[3*512])
+	}
+	if s := c.Stats(); s.Bypassed != 3 || s.Hits != 1 {
+		t.Errorf("large read stats %+v", s)
+	}
+
+	// Erasing drops the blocks from the cache, with their changes.
+	if err := c.EraseBlocks(9, 1); err != nil {
+		t.Fatal(err)
+	}
+	c.Flush()
+	c.ReadAt(buf, 9*512)
+	if buf[0] != 0 {
+		t.Errorf("erased block read as %v", buf)
+	}
+}
+
+func TestCachedCardReadAhead(t *testing.T) {
+	card := &sizedBlocks{memBlocks: memBlocks{data: make([]byte, 4*512)}}
+	c := NewCachedCard(card, 4)
+	c.SetReadAhead(true)
+	buf := make([]byte, 16)
+	for addr := int64(0); addr < 4*512; addr += 256 {
+		c.ReadAt(buf, addr)
+	}
+	s := c.Stats()
+	if s.Misses != 2 || s.ReadAheads != 2 || card.reads != 4 {
+		t.Errorf("stats %+v, %d reads", s, card.reads)
+	}
+}
@@ -0,0 +1,125 @@
+package sdcard
+
+import (
+	"errors"
+	"io"
+)
+
+var errNegativeOffset = errors.New("sdcard: negative offset")
+
+// blockDev is the part of Device used by CardIO.
+type blockDev interface {
+	ReadAt(buf []byte, addr int64) (int, error)
+	WriteAt(buf []byte, addr int64) (int, error)
+	Size() int64
+	Sync() error
+}
+
+// CardIO exposes a card through the standard io interfaces: io.ReaderAt,
+// io.WriterAt, io.ReadWriteSeeker, and io.SectionReader with Section. Reads
+// and writes may start and end anywhere, the block alignment is handled by
+// the ReadAt and WriteAt methods of Device. Reads and writes past the end of
+// the card return io.EOF. Partial blocks written are held in the block cache
+// of the Device until Sync is called.
+//
+// A CardIO must not be used concurrently.
+type CardIO struct {
+	dev blockDev
+	off int64
+}
+
+// NewCardIO returns a CardIO for a configured card, positioned at the
+// beginning of the card.
+func NewCardIO(dev *Device) *CardIO {
+	return &CardIO{dev: dev}
+}
+
+// Size returns the size of the card in bytes.
+func (c *CardIO) Size() int64 {
+	return c.dev.Size()
+}
+
+// ReadAt implements io.ReaderAt.
+func (c *CardIO) ReadAt(p []byte, off int64) (int, error) {
+	if off < 0 {
+		return 0, errNegativeOffset
+	}
+	p, short := c.clamp(p, off)
+	if len(p) == 0 {
+		return 0, io.EOF
+	}
+	n, err := c.dev.ReadAt(p, off)
+	if err == nil && short {
+		err = io.EOF
+	}
+	return n, err
+}
+
+// WriteAt implements io.WriterAt.
+func (c *CardIO) WriteAt(p []byte, off int64) (int, error) {
+	if off < 0 {
+		return 0, errNegativeOffset
+	}
+	p, short := c.clamp(p, off)
+	if len(p) == 0 {
+		return 0, io.EOF
+	}
+	n, err := c.dev.WriteAt(p, off)
+	if err == nil && short {
+		err = io.EOF
+	}
+	return n, err
+}
+
+// Sync writes data of partial block writes still held by the card driver to
+// the card.
+func (c *CardIO) Sync() error {
+	return c.dev.Sync()
+}
+
+// Read implements io.Reader, reading from the current position.
+func (c *CardIO) Read(p []byte) (int, error) {
+	n, err := c.ReadAt(p, c.off)
+	c.off += int64(n)
+	return n, err
+}
+
+// Write implements io.Writer, writing at the current position.
+func (c *CardIO) Write(p []byte) (int, error) {
+	n, err := c.WriteAt(p, c.off)
+	c.off += int64(n)
+	return n, err
+}
+
+// Seek implements io.Seeker.
+func (c *CardIO) Seek(offset int64, whence int) (int64, error) {
+	switch whence {
+	case io.SeekCurrent:
+		offset += c.off
+	case io.SeekEnd:
+		offset += c.Size()
+	}
+	if offset < 0 {
+		return c.off, errNegativeOffset
+	}
+	c.off = offset
+	return offset, nil
+}
+
+// Section returns an io.SectionReader reading n bytes of the card starting
+// at off, for instance a partition.
+func (c *CardIO) Section(off, n int64) *io.SectionReader {
+	return io.NewSectionReader(c, off, n)
+}
+
+// clamp shortens p so that it ends at the end of the card.
+func (c *CardIO) clamp(p []byte, off int64) ([]byte, bool) {
+	size := c.Size()
+	if off >= size {
+		return p[:0], len(p) > 0
+	}
+	if remain := size - off; int64(len(p)) > remain {
+		return p[:remain], true
+	}
+	return p, false
+}
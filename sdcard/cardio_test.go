@@ -0,0 +1,59 @@
+package sdcard
+
+import (
+	"bytes"
+	"io"
+	"testing"
+)
+
+// memCard is a card in memory.
+type memCard []byte
+
+func (m memCard) ReadAt(buf []byte, addr int64) (int, error) {
+	return copy(buf, m[addr:]), nil
+}
+
+func (m memCard) WriteAt(buf []byte, addr int64) (int, error) {
+	return copy(m[addr:], buf), nil
+}
+
+func (m memCard) Size() int64 {
+	return int64(len(m))
+}
+
+func (m memCard) Sync() error {
+	return nil
+}
+
+func TestCardIO(t *testing.T) {
+	card := make(memCard, 2048)
+	c := &CardIO{dev: card}
+
+	if _, err := c.Seek(510, io.SeekStart); err != nil {
+		t.Fatal(err)
+	}
+	if n, err := c.Write([]byte("hello")); n != 5 || err != nil {
+		t.Fatalf("Write: %d, %v", n, err)
+	}
+	if !bytes.Equal(card[510:515], []byte("hello")) {
+		t.Errorf("card: %q", card[510:515])
+	}
+
+	s := c.Section(512, 16)
+	buf := make([]byte, 3)
+	if _, err := io.ReadFull(s, buf); err != nil || string(buf) != "llo" {
+		t.Errorf("Section: %q, %v", buf, err)
+	}
+
+	// Reads past the end are short.
+	buf = make([]byte, 8)
+	if n, err := c.ReadAt(buf, 2044); n != 4 || err != io.EOF {
+		t.Errorf("ReadAt at the end: %d, %v", n, err)
+	}
+	if n, err := c.ReadAt(buf, 2048); n != 0 || err != io.EOF {
+		t.Errorf("ReadAt past the end: %d, %v", n, err)
+	}
+	if pos, _ := c.Seek(-8, io.SeekEnd); pos != 2040 {
+		t.Errorf("Seek from the end: %d", pos)
+	}
+}
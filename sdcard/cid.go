@@ -1,7 +1,13 @@
 package sdcard
 
-import "fmt"
+import (
+	"fmt"
+	"strings"
+	"time"
+)
 
+// CID is the card identification register. The date is stored as in the
+// register: the year as an offset from 2000, and the month from 1 to 12.
 type CID struct {
 	//// byte 0
 	ManufacturerID byte
@@ -37,13 +43,103 @@ type CID struct {
 func NewCID(buf []byte) *CID {
 	return &CID{
 		ManufacturerID:      buf[0],
-		OEMApplicationID:    (uint16(buf[0]) << 8) | uint16(buf[1]),
+		OEMApplicationID:    (uint16(buf[1]) << 8) | uint16(buf[2]),
 		ProductName:         string(buf[3:8]),
 		ProductVersion:      fmt.Sprintf("%d.%d", (buf[8]&0xF0)>>4, buf[8]&0x0F),
 		ProductSerialNumber: (uint32(buf[9]) << 24) | (uint32(buf[10]) << 16) | (uint32(buf[11]) << 8) | uint32(buf[12]),
-		ManufacturingYear:   (buf[13] & 0xF0) | (buf[14] & 0x0F),
-		ManufacturingMonth:  (buf[14] & 0xF0) >> 4,
+		ManufacturingYear:   (buf[13]&0x0F)<<4 | buf[14]>>4,
+		ManufacturingMonth:  buf[14] & 0x0F,
 		Always1:             (buf[15] & 0x80) >> 7,
 		CRC:                 buf[15] & 0x7F,
 	}
 }
+
+// ManufacturingDate returns the year and month the card was made in.
+func (c *CID) ManufacturingDate() (year int, month time.Month) {
+	return 2000 + int(c.ManufacturingYear), time.Month(c.ManufacturingMonth)
+}
+
+// OEMApplicationIDString returns the OEM/application ID as its two ASCII
+// characters, such as "SD" for SanDisk cards.
+func (c *CID) OEMApplicationIDString() string {
+	return printable([]byte{byte(c.OEMApplicationID >> 8), byte(c.OEMApplicationID)})
+}
+
+// ManufacturerName returns the name of the manufacturer of the card, or its
+// ID in hexadecimal when unknown. The IDs are assigned by the SD
+// Association, which doesn't publish them: the names are those commonly
+// found on the cards.
+func (c *CID) ManufacturerName() string {
+	switch c.ManufacturerID {
+	case 0x01:
+		return "Panasonic"
+	case 0x02:
+		return "Toshiba"
+	case 0x03:
+		return "SanDisk"
+	case 0x1B:
+		return "Samsung"
+	case 0x1D:
+		return "ADATA"
+	case 0x27:
+		return "Phison"
+	case 0x28:
+		return "Lexar"
+	case 0x31:
+		return "Silicon Power"
+	case 0x41:
+		return "Kingston"
+	case 0x74:
+		return "Transcend"
+	case 0x76:
+		return "Patriot"
+	case 0x82:
+		return "Sony"
+	}
+	return fmt.Sprintf("0x%02X", c.ManufacturerID)
+}
+
+// String returns a summary of the CID for diagnostics, such as
+// "SanDisk SC16G 8.0 2019-04 SN 0x1234ABCD".
+func (c *CID) String() string {
+	year, month := c.ManufacturingDate()
+	return fmt.Sprintf("%s %s %s %04d-%02d SN 0x%08X", c.ManufacturerName(),
+		printable([]byte(strings.TrimRight(c.ProductName, " \x00"))), c.ProductVersion,
+		year, int(month), c.ProductSerialNumber)
+}
+
+// printable returns b as a string, with the bytes that are not printable
+// ASCII characters replaced with '?'.
+func printable(b []byte) string {
+	for i, c := range b {
+		if c < ' ' || c > '~' {
+			b[i] = '?'
+		}
+	}
+	return string(b)
+}
+
+// DriverName returns "sdcard".
+func (d *Device) DriverName() string {
+	return "sdcard"
+}
+
+// ChipID reads the CID of the card and returns the manufacturer ID in bits
+// 23..16 and the OEM/application ID in bits 15..0.
+func (d *Device) ChipID() (uint32, error) {
+	var buf [16]byte
+	if err := d.ReadCID(buf[:]); err != nil {
+		return 0, err
+	}
+	return uint32(buf[0])<<16 | uint32(buf[1])<<8 | uint32(buf[2]), nil
+}
+
+// RevisionID reads the CID of the card and returns the product revision, with
+// the major revision in bits 7..4 and the minor revision in bits 3..0.
+func (d *Device) RevisionID() (uint32, error) {
+	var buf [16]byte
+	if err := d.ReadCID(buf[:]); err != nil {
+		return 0, err
+	}
+	return uint32(buf[8]), nil
+}
@@ -0,0 +1,30 @@
+package sdcard
+
+import (
+	"testing"
+	"time"
+)
+
+func TestCID(t *testing.T) {
+	buf := []byte{0x03, 'S', 'D', 'S', 'C', '1', '6', 'G', 0x80,
+		0x12, 0x34, 0xAB, 0xCD, 0x01, 0x34, 0}
+	buf[15] = crc7(buf[:15])<<1 | 1
+	cid := NewCID(buf)
+	if cid.OEMApplicationID != 0x5344 || cid.OEMApplicationIDString() != "SD" {
+		t.Errorf("OEM ID %04X", cid.OEMApplicationID)
+	}
+	if year, month := cid.ManufacturingDate(); year != 2019 || month != time.April {
+		t.Errorf("date %d-%d", year, month)
+	}
+	if s := cid.String(); s != "SanDisk SC16G 8.0 2019-04 SN 0x1234ABCD" {
+		t.Errorf("String() = %q", s)
+	}
+
+	buf[0], buf[1], buf[7] = 0xEE, 0x01, 0
+	if s := NewCID(buf).String(); s != "0xEE SC16 8.0 2019-04 SN 0x1234ABCD" {
+		t.Errorf("String() = %q", s)
+	}
+	if s := NewCID(buf).OEMApplicationIDString(); s != "?D" {
+		t.Errorf("OEMApplicationIDString() = %q", s)
+	}
+}
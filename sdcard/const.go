@@ -9,6 +9,7 @@ const (
 	CMD6_SWITCH_FUNC                = 6
 	CMD7_SELECT_DESELECT_CARD       = 7
 	CMD8_SEND_IF_COND               = 8
+	CMD8_SEND_EXT_CSD               = 8 // MMC
 	CMD9_SEND_CSD                   = 9
 	CMD10_SEND_CID                  = 10
 	CMD12_STOP_TRANSMISSION         = 12
@@ -17,6 +18,7 @@ const (
 	CMD16_SET_BLOCKLEN              = 16
 	CMD17_READ_SINGLE_BLOCK         = 17
 	CMD18_READ_MULTIPLE_BLOCK       = 18
+	CMD22_ADDRESS_EXTENSION         = 22
 	CMD24_WRITE_BLOCK               = 24
 	CMD25_WRITE_MULTIPLE_BLOCK      = 25
 	CMD27_PROGRAM_CSD               = 27
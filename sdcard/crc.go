@@ -0,0 +1,135 @@
+package sdcard
+
+import "fmt"
+
+// CRCError is returned when the CRC16 of a data block does not match, in
+// CRC mode (see EnableCRC). For a read Got is the CRC calculated over the
+// received data and Want the one sent by the card; for a write the card
+// rejected the block and both are the CRC that was sent. Block is 0 for the
+// CID and CSD registers and for WriteMulti.
+type CRCError struct {
+	Block uint32
+	Got   uint16
+	Want  uint16
+}
+
+func (e *CRCError) Error() string {
+	return fmt.Sprintf("SD_CARD_ERROR_CRC block %d: got %04X, want %04X", e.Block, e.Got, e.Want)
+}
+
+// EnableCRC turns the CRC protection of the SPI mode on or off using CMD59.
+// When on, every command carries a valid CRC7, every data block read is
+// checked against its CRC16 and every data block written carries a real
+// CRC16, so that corrupted transfers are reported as a *CRCError instead of
+// silently returning or storing wrong data. It is off after power up, and
+// turned on again by Configure once enabled.
+func (d *Device) EnableCRC(enable bool) error {
+	arg := uint32(0)
+	if enable {
+		arg = 1
+	}
+	buf := [5]byte{0x40 | CMD59_CRC_ON_OFF, 0, 0, 0, byte(arg)}
+	r := d.cmd(CMD59_CRC_ON_OFF, arg, crc7(buf[:])<<1|1)
+	d.cs.High()
+	if r != 0 {
+		return fmt.Errorf("SD_CARD_ERROR_CMD59")
+	}
+	d.crcEnabled = enable
+	return nil
+}
+
+// CRCEnabled returns whether the CRC protection is on.
+func (d *Device) CRCEnabled() bool {
+	return d.crcEnabled
+}
+
+// readCRC reads the CRC16 trailing a data block and, in CRC mode, checks it
+// against data.
+func (d Device) readCRC(block uint32, data []byte) error {
+	hi, err := d.bus.Transfer(byte(0xFF))
+	if err != nil {
+		return err
+	}
+	lo, err := d.bus.Transfer(byte(0xFF))
+	if err != nil {
+		return err
+	}
+	if d.crcEnabled {
+		want := uint16(hi)<<8 | uint16(lo)
+		if got := crc16(data); got != want {
+			d.stats.crcError()
+			return &CRCError{Block: block, Got: got, Want: want}
+		}
+	}
+	if len(data) == 512 {
+		d.stats.blockRead()
+	}
+	return nil
+}
+
+// writeCRC sends the CRC16 trailing a data block: the real one in CRC mode,
+// a dummy one otherwise. It returns the CRC sent.
+func (d Device) writeCRC(data []byte) uint16 {
+	crc := uint16(0xFFFF)
+	if d.crcEnabled {
+		crc = crc16(data)
+	}
+	d.bus.Transfer(byte(crc >> 8))
+	d.bus.Transfer(byte(crc))
+	return crc
+}
+
+// dataResponse checks the data response token of a written block.
+func (d Device) dataResponse(block uint32, crc uint16) error {
+	r, err := d.bus.Transfer(byte(0xFF))
+	if err != nil {
+		return err
+	}
+	switch r & 0x1F {
+	case 0x05:
+		d.stats.blockWritten()
+		return nil
+	case 0x0B:
+		d.stats.crcError()
+		return &CRCError{Block: block, Got: crc, Want: crc}
+	}
+	return fmt.Errorf("SD_CARD_ERROR_WRITE")
+}
+
+// crc7 calculates the CRC7 used by SD commands and registers.
+func crc7(data []byte) byte {
+	crc := byte(0)
+	for _, b := range data {
+		for i := 0; i < 8; i++ {
+			crc <<= 1
+			if (b^crc)&0x80 != 0 {
+				crc ^= 0x09
+			}
+			b <<= 1
+		}
+	}
+	return crc & 0x7F
+}
+
+// CRC16 calculates the CRC16 (CCITT, polynomial 0x1021, initial value 0)
+// used by SD data blocks, which is also the one of XMODEM.
+func CRC16(data []byte) uint16 {
+	return crc16(data)
+}
+
+// crc16 calculates the CRC16 (CCITT, polynomial 0x1021) used by SD data
+// blocks.
+func crc16(data []byte) uint16 {
+	crc := uint16(0)
+	for _, b := range data {
+		crc ^= uint16(b) << 8
+		for i := 0; i < 8; i++ {
+			if crc&0x8000 != 0 {
+				crc = crc<<1 ^ 0x1021
+			} else {
+				crc <<= 1
+			}
+		}
+	}
+	return crc
+}
@@ -0,0 +1,24 @@
+package sdcard
+
+import "testing"
+
+func TestCRC7(t *testing.T) {
+	// CMD0 and CMD8 use fixed CRCs in initCard.
+	if crc := crc7([]byte{0x40, 0, 0, 0, 0})<<1 | 1; crc != 0x95 {
+		t.Errorf("CMD0: got %02X, want 95", crc)
+	}
+	if crc := crc7([]byte{0x48, 0, 0, 0x01, 0xAA})<<1 | 1; crc != 0x87 {
+		t.Errorf("CMD8: got %02X, want 87", crc)
+	}
+}
+
+func TestCRC16(t *testing.T) {
+	// Example from the physical layer specification: 512 bytes of 0xFF.
+	var buf [512]byte
+	for i := range buf {
+		buf[i] = 0xFF
+	}
+	if crc := crc16(buf[:]); crc != 0x7FA1 {
+		t.Errorf("got %04X, want 7FA1", crc)
+	}
+}
@@ -2,6 +2,7 @@ package sdcard
 
 import (
 	"fmt"
+	"time"
 )
 
 type CSD struct {
@@ -15,7 +16,8 @@ type CSD struct {
 	WRITE_BLK_MISALIGN byte   //  1 R  [78:78]     0x00 : Write Block Misalignment
 	READ_BLK_MISALIGN  byte   //  1 R  [77:77]     0x00 : Read Block Misalignment
 	DSR_IMP            byte   //  1 R  [76:76]     0x00 : DSR Implemented
-	C_SIZE             uint32 // 22 R  [69:48] 0xXXXXXX : Device Size
+	C_SIZE             uint32 // 22 R  [69:48] 0xXXXXXX : Device Size ([73:62] in version 1.0 and MMC, [75:48] in version 3.0)
+	C_SIZE_MULT        byte   //  3 R  [49:47]     0xXX : Device Size Multiplier (version 1.0 and MMC)
 	ERASE_BLK_EN       byte   //  1 R  [46:46]     0x01 : Erase Single Block Enable
 	SECTOR_SIZE        byte   //  7 R  [45:39]     0x7F : Erase Sector Size
 	WP_GRP_SIZE        byte   //  7 R  [38:32]     0x00 : Write Protect Group Size
@@ -29,10 +31,13 @@ type CSD struct {
 	TMP_WRITE_PROTECT  byte   //  1 RW [12:12]     0x00 : Temporary Write Protection
 	FILE_FORMAT        byte   //  2 R  [11:10]     0x00 : File Format
 	CRC                byte   //  7 RW [7:1]       0xXX : CRC
+
+	// MMC is set for the CSD of an MMC card or eMMC chip, see NewMMCCSD.
+	MMC bool
 }
 
 func NewCSD(buf []byte) *CSD {
-	return &CSD{
+	c := &CSD{
 		CSD_STRUCTURE:      (buf[0] & 0xC0) >> 6,
 		TAAC:               buf[1],
 		NSAC:               buf[2],
@@ -44,6 +49,7 @@ func NewCSD(buf []byte) *CSD {
 		READ_BLK_MISALIGN:  (buf[6] & 0x20) >> 5,
 		DSR_IMP:            (buf[6] & 0x10) >> 4,
 		C_SIZE:             uint32(buf[7]&0x3F)<<16 | uint32(buf[8])<<8 | uint32(buf[9]),
+		C_SIZE_MULT:        (buf[9]&0x03)<<1 | (buf[10]&0x80)>>7,
 		ERASE_BLK_EN:       (buf[10] & 0x40) >> 6,
 		SECTOR_SIZE:        (buf[10]&0x3F)<<1 | (buf[11]&0x80)>>7,
 		WP_GRP_SIZE:        buf[11] & 0x7F,
@@ -58,6 +64,28 @@ func NewCSD(buf []byte) *CSD {
 		FILE_FORMAT:        (buf[14] & 0x0C) >> 2,
 		CRC:                (buf[15] & 0xFE) >> 1,
 	}
+	switch c.CSD_STRUCTURE {
+	case 0x00:
+		c.C_SIZE = csdV1Size(buf)
+	case 0x02:
+		// CSD version 3.0 of SDUC cards, larger than 2TB.
+		c.C_SIZE |= uint32(buf[6]&0x0F)<<24 | uint32(buf[7]&0xC0)<<16
+	}
+	return c
+}
+
+// NewMMCCSD parses the CSD of an MMC card or eMMC chip. All its versions
+// have the layout of the version 1.0 of SD cards.
+func NewMMCCSD(buf []byte) *CSD {
+	c := NewCSD(buf)
+	c.C_SIZE = csdV1Size(buf)
+	c.MMC = true
+	return c
+}
+
+// csdV1Size returns the 12-bit C_SIZE of the version 1.0 layout.
+func csdV1Size(buf []byte) uint32 {
+	return uint32(buf[6]&0x03)<<10 | uint32(buf[7])<<2 | uint32(buf[8])>>6
 }
 
 func (c *CSD) Dump() {
@@ -72,6 +100,7 @@ func (c *CSD) Dump() {
 	fmt.Printf("READ_BLK_MISALIGN:  %X\r\n", c.READ_BLK_MISALIGN)
 	fmt.Printf("DSR_IMP:            %X\r\n", c.DSR_IMP)
 	fmt.Printf("C_SIZE:             %X\r\n", c.C_SIZE)
+	fmt.Printf("C_SIZE_MULT:        %X\r\n", c.C_SIZE_MULT)
 	fmt.Printf("ERASE_BLK_EN:       %X\r\n", c.ERASE_BLK_EN)
 	fmt.Printf("SECTOR_SIZE:        %X\r\n", c.SECTOR_SIZE)
 	fmt.Printf("WP_GRP_SIZE:        %X\r\n", c.WP_GRP_SIZE)
@@ -88,19 +117,99 @@ func (c *CSD) Dump() {
 }
 
 func (c *CSD) Sectors() (int64, error) {
-	sectors := int64(0)
-	if c.CSD_STRUCTURE == 0x01 {
-		// CSD version 2.0
-		sectors = (int64(c.C_SIZE) + 1) * 1024
-	} else if c.CSD_STRUCTURE == 0x00 {
-		// CSD version 1.0 (old, <=2GB)
-		return 0, fmt.Errorf("CSD format version 1.0 is not supported")
-	} else {
+	if !c.MMC && c.CSD_STRUCTURE > 0x02 {
 		return 0, fmt.Errorf("unknown CSD format")
 	}
-	return sectors, nil
+	return int64(c.Size() / 512), nil
 }
 
+// Size returns the capacity of the card in bytes. MMC cards and eMMC chips
+// larger than 2GB report it in their extended CSD instead, and have a C_SIZE
+// of 0xFFF.
 func (c *CSD) Size() uint64 {
-	return uint64(c.C_SIZE) * 512 * 1024
+	if c.MMC || c.CSD_STRUCTURE == 0x00 {
+		// CSD version 1.0 (old, <=2GB) and MMC
+		return (uint64(c.C_SIZE) + 1) << (c.C_SIZE_MULT + 2) << c.READ_BL_LEN
+	}
+	// CSD version 2.0 and 3.0
+	return (uint64(c.C_SIZE) + 1) * 512 * 1024
+}
+
+// TransferSpeed returns the maximum clock frequency of the card in Hz, given
+// by TRAN_SPEED. It is 25MHz for SD cards in default speed mode, and 20MHz
+// or 26MHz for MMC cards.
+func (c *CSD) TransferSpeed() uint32 {
+	unit := uint32(10000) // 100kbit/s, divided by 10 for the mantissa
+	for i := byte(0); i < c.TRAN_SPEED&0x07 && i < 3; i++ {
+		unit *= 10
+	}
+	mantissa := uint32(taacValues[c.TRAN_SPEED>>3&0x0F])
+	if c.MMC && mantissa == 25 {
+		// The only mantissa that differs for MMC.
+		mantissa = 26
+	}
+	return unit * mantissa
+}
+
+// WriteProtectGroupSizeInSectors returns the size of a write protect group
+// in 512-byte sectors, or 0 if the card does not support write protect
+// groups, like all high capacity SD cards.
+func (c *CSD) WriteProtectGroupSizeInSectors() uint32 {
+	if c.WP_GRP_ENABLE == 0 {
+		return 0
+	}
+	blocks := uint32(1) << c.WRITE_BL_LEN / 512
+	if blocks == 0 {
+		blocks = 1
+	}
+	if c.MMC {
+		// The same bits hold ERASE_GRP_SIZE, ERASE_GRP_MULT and a 5-bit
+		// WP_GRP_SIZE on MMC.
+		bits := uint32(c.ERASE_BLK_EN)<<14 | uint32(c.SECTOR_SIZE)<<7 | uint32(c.WP_GRP_SIZE)
+		size, mult, wp := bits>>10&0x1F, bits>>5&0x1F, bits&0x1F
+		return (size + 1) * (mult + 1) * (wp + 1) * blocks
+	}
+	return (uint32(c.SECTOR_SIZE) + 1) * (uint32(c.WP_GRP_SIZE) + 1) * blocks
+}
+
+// EraseSectorSizeInSectors returns the size of an erase sector in 512-byte
+// sectors: the unit the card erases, and the granularity of erases of cards
+// with ERASE_BLK_EN cleared. It is 128 sectors (64KiB) for high capacity SD
+// cards, and the erase group for MMC.
+func (c *CSD) EraseSectorSizeInSectors() uint32 {
+	blocks := uint32(1) << c.WRITE_BL_LEN / 512
+	if blocks == 0 {
+		blocks = 1
+	}
+	if c.MMC {
+		bits := uint32(c.ERASE_BLK_EN)<<14 | uint32(c.SECTOR_SIZE)<<7 | uint32(c.WP_GRP_SIZE)
+		size, mult := bits>>10&0x1F, bits>>5&0x1F
+		return (size + 1) * (mult + 1) * blocks
+	}
+	return (uint32(c.SECTOR_SIZE) + 1) * blocks
+}
+
+// taacValues are the mantissas of the TAAC and TRAN_SPEED fields, multiplied
+// by 10.
+var taacValues = [16]int64{0, 10, 12, 13, 15, 20, 25, 30, 35, 40, 45, 50, 55, 60, 70, 80}
+
+// WriteTimeout returns the maximum time the card takes to write a block. It
+// is 100 times the typical write time given by TAAC and R2W_FACTOR, limited
+// to 250ms as required by the SD specification.
+func (c *CSD) WriteTimeout() time.Duration {
+	const max = 250 * time.Millisecond
+	if c.CSD_STRUCTURE != 0x00 {
+		// TAAC is fixed for CSD version 2.0 and later.
+		return max
+	}
+	unit := time.Duration(1)
+	for i := byte(0); i < c.TAAC&0x07; i++ {
+		unit *= 10
+	}
+	taac := unit * time.Duration(taacValues[c.TAAC>>3&0x0F]) / 10
+	timeout := 100 * taac << (c.R2W_FACTOR & 0x07)
+	if timeout > max {
+		return max
+	}
+	return timeout
 }
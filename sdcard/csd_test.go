@@ -0,0 +1,61 @@
+package sdcard
+
+import (
+	"testing"
+	"time"
+)
+
+func TestWriteTimeout(t *testing.T) {
+	for _, tc := range []struct {
+		csd  CSD
+		want time.Duration
+	}{
+		// 1.3µs * 100 * 4
+		{CSD{CSD_STRUCTURE: 0, TAAC: 0x1B, R2W_FACTOR: 2}, 520 * time.Microsecond},
+		// 1.5ms * 100 * 4, limited to 250ms
+		{CSD{CSD_STRUCTURE: 0, TAAC: 0x26, R2W_FACTOR: 2}, 250 * time.Millisecond},
+		{CSD{CSD_STRUCTURE: 1, TAAC: 0x0E, R2W_FACTOR: 2}, 250 * time.Millisecond},
+	} {
+		if got := tc.csd.WriteTimeout(); got != tc.want {
+			t.Errorf("TAAC %02X: got %v, want %v", tc.csd.TAAC, got, tc.want)
+		}
+	}
+}
+
+func TestTransferSpeed(t *testing.T) {
+	for _, tc := range []struct {
+		tranSpeed byte
+		want      uint32
+	}{
+		{0x32, 25000000},  // default speed
+		{0x5A, 50000000},  // high speed
+		{0x2A, 20000000},  // MMC
+		{0x0B, 100000000}, // SDR50/SDR104 reports 100Mbit/s
+		{0x48, 400000},
+	} {
+		c := CSD{TRAN_SPEED: tc.tranSpeed}
+		if got := c.TransferSpeed(); got != tc.want {
+			t.Errorf("TRAN_SPEED %02X: got %d, want %d", tc.tranSpeed, got, tc.want)
+		}
+	}
+	if got := (&CSD{TRAN_SPEED: 0x32, MMC: true}).TransferSpeed(); got != 26000000 {
+		t.Errorf("MMC TRAN_SPEED 32: got %d, want 26000000", got)
+	}
+}
+
+func TestCSDSize(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		csd  *CSD
+		want uint64
+	}{
+		{"SDHC", &CSD{CSD_STRUCTURE: 1, C_SIZE: 15159}, 15160 * 512 * 1024},
+		{"SDUC", &CSD{CSD_STRUCTURE: 2, C_SIZE: 0xFFFFFF}, 8 << 40},
+		{"SD v1", &CSD{CSD_STRUCTURE: 0, C_SIZE: 3838, C_SIZE_MULT: 7, READ_BL_LEN: 10}, 3839 * 512 * 1024},
+		{"MMC", NewMMCCSD([]byte{0x90, 0x27, 0x01, 0x2A, 0x0F, 0x59, 0x03, 0xBF, 0x80, 0xFF, 0x80, 0, 0, 0, 0, 0}), 3839 * 512 * 512},
+	} {
+		if got := tc.csd.Size(); got != tc.want {
+			t.Errorf("%s: got %d, want %d", tc.name, got, tc.want)
+		}
+	}
+}
@@ -0,0 +1,88 @@
+package sdcard
+
+import (
+	"context"
+	"time"
+
+	"tinygo.org/x/drivers"
+)
+
+// The Ctx variants of the methods that can wait for the card for seconds
+// (the initialization, the writes and the erases) stop waiting when their
+// context is canceled or past its deadline, and return its error. While
+// they wait they yield to the other goroutines, which can cancel them on
+// cooperative schedulers. A canceled operation may leave the card busy;
+// the next command waits for it.
+
+// ConfigureCtx is Configure with a context.
+func (d *Device) ConfigureCtx(ctx context.Context) error {
+	defer d.withContext(ctx)()
+	return d.initCard()
+}
+
+// ReadDataCtx is ReadData with a context.
+func (d *Device) ReadDataCtx(ctx context.Context, block uint32, dst []byte) error {
+	defer d.withContext(ctx)()
+	return d.ReadData(block, dst)
+}
+
+// WriteDataCtx is WriteData with a context.
+func (d *Device) WriteDataCtx(ctx context.Context, block uint32, src []byte) error {
+	defer d.withContext(ctx)()
+	return d.WriteData(block, src)
+}
+
+// WriteBlocksCtx is WriteBlocks with a context.
+func (d *Device) WriteBlocksCtx(ctx context.Context, startBlock int64, src []byte) error {
+	defer d.withContext(ctx)()
+	return d.WriteBlocks(startBlock, src)
+}
+
+// EraseCtx is Erase with a context.
+func (d *Device) EraseCtx(ctx context.Context, startBlock, endBlock int64) error {
+	defer d.withContext(ctx)()
+	return d.Erase(startBlock, endBlock)
+}
+
+// SecureEraseCtx is SecureErase with a context.
+func (d *Device) SecureEraseCtx(ctx context.Context, startBlock, endBlock int64) error {
+	defer d.withContext(ctx)()
+	return d.SecureErase(startBlock, endBlock)
+}
+
+// withContext sets the context of the operation in progress, and returns
+// the function that clears it.
+func (d *Device) withContext(ctx context.Context) func() {
+	d.ctx = ctx
+	return func() { d.ctx = nil }
+}
+
+// canceled yields to the other goroutines, then returns the error of the
+// context of the operation, if any.
+func (d Device) canceled() error {
+	return canceled(d.ctx)
+}
+
+// ConfigureCtx is Configure with a context.
+func (c *SDIOCard) ConfigureCtx(ctx context.Context) error {
+	c.ctx = ctx
+	defer func() { c.ctx = nil }()
+	return c.Configure()
+}
+
+func canceled(ctx context.Context) error {
+	if ctx == nil {
+		return nil
+	}
+	drivers.Yield()
+	// The deadline is checked here as well, as the timer of the context may
+	// not get to run while the caller is busy waiting.
+	if deadline, ok := ctx.Deadline(); ok && time.Now().After(deadline) {
+		return context.DeadlineExceeded
+	}
+	return ctx.Err()
+}
+
+func isContextError(err error) bool {
+	return err == context.Canceled || err == context.DeadlineExceeded
+}
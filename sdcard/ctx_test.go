@@ -0,0 +1,36 @@
+package sdcard
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestConfigureCtx(t *testing.T) {
+	host := &fakeHost{mem: make([]byte, 64*512), stuck: true}
+	c := NewSDIO(host)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := c.ConfigureCtx(ctx); err != context.Canceled {
+		t.Errorf("canceled: %v", err)
+	}
+
+	ctx, cancel = context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	if err := c.ConfigureCtx(ctx); err != context.DeadlineExceeded {
+		t.Errorf("deadline: %v", err)
+	}
+	if d := time.Since(start); d > time.Second {
+		t.Errorf("returned after %v", d)
+	}
+	if c.ctx != nil {
+		t.Errorf("context kept after the call")
+	}
+
+	host.stuck = false
+	if err := c.ConfigureCtx(context.Background()); err != nil {
+		t.Errorf("ConfigureCtx: %v", err)
+	}
+}
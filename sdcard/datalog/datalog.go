@@ -0,0 +1,287 @@
+// Package datalog logs timestamped records of sensor values to an SD card,
+// as CSV text or in a compact binary format, the data logger of many
+// devices in the field.
+//
+// The records go to segments given by a Sink: the files of a FAT file
+// system (FileSink), or regions of the blocks of a card written with its
+// streaming API (RegionSink). A new segment is started once the current one
+// reaches a size or an age, and starts with a header naming the fields.
+//
+//	fsys, err := fat.Mount(card)
+//	...
+//	log := datalog.New(datalog.NewFileSink(fsys, "logs/TEMP", "CSV"), datalog.Config{
+//		Fields: []string{"temperature", "humidity"},
+//		MaxAge: 24 * time.Hour,
+//	})
+//	for range time.Tick(time.Minute) {
+//		log.Sample(sensor, drivers.Temperature|drivers.Humidity, func(v []int32) {
+//			v[0], v[1] = sensor.Temperature(), sensor.Humidity()
+//		})
+//	}
+//
+// The records are synced to the card at sync points, after each record by
+// default: a power loss only loses the records since the last one.
+package datalog // import "tinygo.org/x/drivers/sdcard/datalog"
+
+import (
+	"encoding/binary"
+	"errors"
+	"io"
+	"strconv"
+	"time"
+
+	"tinygo.org/x/drivers"
+)
+
+// Format is the format of the records.
+type Format uint8
+
+const (
+	// CSV writes a line of comma separated values per record, after a line
+	// with the names of the fields. The first value is the time of the
+	// record, in UTC with milliseconds: 2024-03-01 12:30:00.250.
+	CSV Format = iota
+
+	// Binary writes records of fixed size after a header, all in little
+	// endian. The header is "TGLG", the version of the format (1), the
+	// number of fields, and the name of each field as its length and bytes.
+	// A record is the byte 0xA5, the time in milliseconds since 1970 as an
+	// int64, the values as int32, and a CRC-8 (polynomial 0x31, initial
+	// value 0xFF) of all of them, so that a reader can tell records from
+	// blocks left over or padded with zeros.
+	Binary
+)
+
+const (
+	binaryMagic   = "TGLG"
+	binaryVersion = 1
+	recordMarker  = 0xA5
+)
+
+var (
+	errFieldCount = errors.New("datalog: number of values differs from the fields")
+	errNameLength = errors.New("datalog: field name longer than 255 bytes")
+)
+
+// Config is the configuration of a Logger.
+type Config struct {
+	Format Format
+
+	// Fields are the names of the values of the records.
+	Fields []string
+
+	// MaxSize is the size in bytes after which a new segment is started, 0
+	// for no limit. Segments of a RegionSink also end when they are full.
+	MaxSize int64
+
+	// MaxAge is the time after which a new segment is started, 0 for no
+	// limit. It is measured with the times of the records.
+	MaxAge time.Duration
+
+	// SyncInterval is the time between sync points, measured with the
+	// times of the records. 0 syncs after every record.
+	SyncInterval time.Duration
+}
+
+// Sink gives the segments the records are written to.
+type Sink interface {
+	// Next returns the writer of a new segment, and its size in bytes, 0
+	// when unlimited. The Logger closes it before asking for the next one.
+	// Writers with a Sync method are synced at sync points.
+	Next() (io.WriteCloser, int64, error)
+}
+
+type syncer interface {
+	Sync() error
+}
+
+// Logger writes records to the segments of a sink.
+type Logger struct {
+	sink Sink
+	cfg  Config
+
+	w        io.WriteCloser
+	limit    int64 // size of the segment, 0 when unlimited
+	size     int64 // written to the segment
+	start    time.Time
+	lastSync time.Time
+	pending  bool // records written since the last sync point
+
+	buf    []byte
+	values []int32
+}
+
+// New returns a Logger writing to sink. The first segment is started with
+// the first record.
+//
+// This function only creates the Logger object, it does not touch the
+// device.
+func New(sink Sink, cfg Config) *Logger {
+	return &Logger{
+		sink:   sink,
+		cfg:    cfg,
+		values: make([]int32, len(cfg.Fields)),
+	}
+}
+
+// Log writes a record with the time t and a value for each field.
+func (l *Logger) Log(t time.Time, values ...int32) error {
+	if len(values) != len(l.cfg.Fields) {
+		return errFieldCount
+	}
+	l.buf = l.record(l.buf[:0], t, values)
+	if l.w != nil && l.full(t, int64(len(l.buf))) {
+		if err := l.closeSegment(); err != nil {
+			return err
+		}
+	}
+	if l.w == nil {
+		if err := l.openSegment(t); err != nil {
+			return err
+		}
+	}
+	n, err := l.w.Write(l.buf)
+	l.size += int64(n)
+	if err != nil {
+		return err
+	}
+	l.pending = true
+	if t.Sub(l.lastSync) >= l.cfg.SyncInterval {
+		if err := l.Sync(); err != nil {
+			return err
+		}
+		l.lastSync = t
+	}
+	return nil
+}
+
+// Sample updates the measurements which of sensor, and logs the values
+// stored by read in its argument, one for each field, with the current time.
+func (l *Logger) Sample(sensor drivers.Sensor, which drivers.Measurement, read func(values []int32)) error {
+	if err := sensor.Update(which); err != nil {
+		return err
+	}
+	read(l.values)
+	return l.Log(time.Now(), l.values...)
+}
+
+// Sync makes the records written so far safe from a power loss.
+func (l *Logger) Sync() error {
+	if !l.pending {
+		return nil
+	}
+	if s, ok := l.w.(syncer); ok {
+		if err := s.Sync(); err != nil {
+			return err
+		}
+	}
+	l.pending = false
+	return nil
+}
+
+// Close ends the current segment. The next record starts a new one.
+func (l *Logger) Close() error {
+	if l.w == nil {
+		return nil
+	}
+	return l.closeSegment()
+}
+
+// full returns whether a record of n bytes at t belongs to a new segment.
+func (l *Logger) full(t time.Time, n int64) bool {
+	if l.limit > 0 && l.size+n > l.limit {
+		return true
+	}
+	if l.cfg.MaxSize > 0 && l.size+n > l.cfg.MaxSize {
+		return true
+	}
+	return l.cfg.MaxAge > 0 && t.Sub(l.start) >= l.cfg.MaxAge
+}
+
+func (l *Logger) openSegment(t time.Time) error {
+	w, limit, err := l.sink.Next()
+	if err != nil {
+		return err
+	}
+	header, err := l.header()
+	if err != nil {
+		w.Close()
+		return err
+	}
+	n, err := w.Write(header)
+	if err != nil {
+		w.Close()
+		return err
+	}
+	l.w, l.limit, l.size, l.start = w, limit, int64(n), t
+	return nil
+}
+
+func (l *Logger) closeSegment() error {
+	err := l.w.Close()
+	l.w = nil
+	l.pending = false
+	return err
+}
+
+// header returns the header of a segment.
+func (l *Logger) header() ([]byte, error) {
+	var b []byte
+	if l.cfg.Format == CSV {
+		b = append(b, "time"...)
+		for _, f := range l.cfg.Fields {
+			b = append(b, ',')
+			b = append(b, f...)
+		}
+		return append(b, '\n'), nil
+	}
+	b = append(b, binaryMagic...)
+	b = append(b, binaryVersion, byte(len(l.cfg.Fields)))
+	for _, f := range l.cfg.Fields {
+		if len(f) > 255 {
+			return nil, errNameLength
+		}
+		b = append(b, byte(len(f)))
+		b = append(b, f...)
+	}
+	return b, nil
+}
+
+// record appends the record of t and values to b.
+func (l *Logger) record(b []byte, t time.Time, values []int32) []byte {
+	if l.cfg.Format == CSV {
+		b = t.UTC().AppendFormat(b, "2006-01-02 15:04:05.000")
+		for _, v := range values {
+			b = append(b, ',')
+			b = strconv.AppendInt(b, int64(v), 10)
+		}
+		return append(b, '\n')
+	}
+	start := len(b)
+	var v [8]byte
+	binary.LittleEndian.PutUint64(v[:], uint64(t.UnixMilli()))
+	b = append(b, recordMarker)
+	b = append(b, v[:]...)
+	for _, value := range values {
+		binary.LittleEndian.PutUint32(v[:], uint32(value))
+		b = append(b, v[:4]...)
+	}
+	return append(b, crc8(b[start:]))
+}
+
+// crc8 returns the CRC-8 of data, with the polynomial 0x31 and the initial
+// value 0xFF.
+func crc8(data []byte) byte {
+	crc := byte(0xFF)
+	for _, b := range data {
+		crc ^= b
+		for i := 0; i < 8; i++ {
+			if crc&0x80 != 0 {
+				crc = crc<<1 ^ 0x31
+			} else {
+				crc <<= 1
+			}
+		}
+	}
+	return crc
+}
@@ -0,0 +1,320 @@
+package datalog
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"sort"
+	"testing"
+	"time"
+
+	"tinygo.org/x/drivers/sdcard"
+	"tinygo.org/x/drivers/sdcard/fat"
+)
+
+var _ Sink = (*FileSink)(nil)
+var _ Sink = (*RegionSink)(nil)
+
+// memSink keeps the segments in memory.
+type memSink struct {
+	segments []*segment
+	limit    int64
+}
+
+type segment struct {
+	bytes.Buffer
+	syncs  int
+	closed bool
+}
+
+func (s *segment) Sync() error {
+	s.syncs++
+	return nil
+}
+
+func (s *segment) Close() error {
+	s.closed = true
+	return nil
+}
+
+func (m *memSink) Next() (io.WriteCloser, int64, error) {
+	s := &segment{}
+	m.segments = append(m.segments, s)
+	return s, m.limit, nil
+}
+
+var t0 = time.Date(2024, 3, 1, 12, 30, 0, 250e6, time.UTC)
+
+func TestCSV(t *testing.T) {
+	sink := &memSink{}
+	l := New(sink, Config{Fields: []string{"temp", "hum"}})
+	if err := l.Log(t0, 2150, -40); err != nil {
+		t.Fatal(err)
+	}
+	if err := l.Log(t0.Add(time.Second), 2160, 41); err != nil {
+		t.Fatal(err)
+	}
+	if err := l.Log(t0, 1); err != errFieldCount {
+		t.Errorf("Log with a missing value: got %v", err)
+	}
+	want := "time,temp,hum\n" +
+		"2024-03-01 12:30:00.250,2150,-40\n" +
+		"2024-03-01 12:30:01.250,2160,41\n"
+	if len(sink.segments) != 1 || sink.segments[0].String() != want {
+		t.Fatalf("got segments %q", sink.segments[0].String())
+	}
+	if s := sink.segments[0]; s.syncs != 2 {
+		t.Errorf("got %d syncs, want 2", s.syncs)
+	}
+	if err := l.Close(); err != nil || !sink.segments[0].closed {
+		t.Errorf("Close: %v", err)
+	}
+}
+
+func TestBinary(t *testing.T) {
+	sink := &memSink{}
+	l := New(sink, Config{Format: Binary, Fields: []string{"a", "bc"}})
+	if err := l.Log(t0, 1, -2); err != nil {
+		t.Fatal(err)
+	}
+	data := sink.segments[0].Bytes()
+	header := "TGLG\x01\x02\x01a\x02bc"
+	if string(data[:len(header)]) != header {
+		t.Fatalf("got header %q", data[:len(header)])
+	}
+	rec := data[len(header):]
+	if len(rec) != 1+8+2*4+1 || rec[0] != recordMarker {
+		t.Fatalf("got record % x", rec)
+	}
+	le := binary.LittleEndian
+	if ms := int64(le.Uint64(rec[1:])); ms != t0.UnixMilli() {
+		t.Errorf("got time %d", ms)
+	}
+	if a, b := int32(le.Uint32(rec[9:])), int32(le.Uint32(rec[13:])); a != 1 || b != -2 {
+		t.Errorf("got values %d, %d", a, b)
+	}
+	if crc8(rec[:17]) != rec[17] {
+		t.Error("bad CRC")
+	}
+	// The CRC-8/NRSC-5 check value.
+	if c := crc8([]byte("123456789")); c != 0xF7 {
+		t.Errorf("crc8 = %#x", c)
+	}
+}
+
+func TestRotate(t *testing.T) {
+	// Each record is 30 bytes, after a header of 7.
+	sink := &memSink{}
+	l := New(sink, Config{Fields: []string{"v"}, MaxSize: 100})
+	for i := 0; i < 7; i++ {
+		if err := l.Log(t0, 10000); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if len(sink.segments) != 3 || sink.segments[0].Len() != 97 || sink.segments[2].Len() != 37 {
+		t.Fatalf("got %d segments", len(sink.segments))
+	}
+	if !sink.segments[0].closed || sink.segments[2].closed {
+		t.Error("segments not closed in order")
+	}
+
+	// The size of the segments given by the sink is a limit too.
+	sink = &memSink{limit: 70}
+	l = New(sink, Config{Fields: []string{"v"}, MaxSize: 100})
+	for i := 0; i < 3; i++ {
+		l.Log(t0, 10000)
+	}
+	if len(sink.segments) != 2 {
+		t.Errorf("got %d segments with a limit", len(sink.segments))
+	}
+
+	sink = &memSink{}
+	l = New(sink, Config{Fields: []string{"v"}, MaxAge: time.Hour})
+	for _, d := range []time.Duration{0, 30 * time.Minute, time.Hour, 90 * time.Minute, 2 * time.Hour} {
+		l.Log(t0.Add(d), 1)
+	}
+	if len(sink.segments) != 3 || bytes.Count(sink.segments[1].Bytes(), []byte("\n")) != 3 {
+		t.Errorf("got %d segments by age", len(sink.segments))
+	}
+}
+
+func TestSyncInterval(t *testing.T) {
+	sink := &memSink{}
+	l := New(sink, Config{Fields: []string{"v"}, SyncInterval: time.Minute})
+	for i := 0; i < 11; i++ {
+		l.Log(t0.Add(time.Duration(i)*20*time.Second), 1)
+	}
+	// At 0s, 60s, 120s and 180s.
+	if s := sink.segments[0].syncs; s != 4 {
+		t.Errorf("got %d syncs, want 4", s)
+	}
+	if err := l.Sync(); err != nil || sink.segments[0].syncs != 5 {
+		t.Errorf("Sync: %v", err)
+	}
+	l.Sync()
+	if sink.segments[0].syncs != 5 {
+		t.Error("synced without new records")
+	}
+}
+
+// stream writes blocks of a card as the BlockWriter of a Device does.
+type stream struct {
+	card       *sdcard.MemoryCard
+	block, end int64
+	buf        []byte
+}
+
+func (s *stream) Write(p []byte) (int, error) {
+	s.buf = append(s.buf, p...)
+	for len(s.buf) >= 512 {
+		if s.block == s.end {
+			return 0, io.ErrShortWrite
+		}
+		s.card.WriteBlocks(s.block, s.buf[:512])
+		s.buf = s.buf[512:]
+		s.block++
+	}
+	return len(p), nil
+}
+
+func (s *stream) Close() error {
+	if len(s.buf) > 0 {
+		s.buf = append(s.buf, make([]byte, 512-len(s.buf))...)
+		s.Write(nil)
+	}
+	return nil
+}
+
+func TestRegionSink(t *testing.T) {
+	card := sdcard.NewMemoryCard(1 << 20)
+	opened := 0
+	open := func(start, n int64) (io.WriteCloser, error) {
+		opened++
+		return &stream{card: card, block: start, end: start + n}, nil
+	}
+	card.WriteBlocks(100, bytes.Repeat([]byte{0xEE}, 6*512))
+	sink := newRegionSink(open, card.EraseBlocks, 100, 7, 3)
+	sink.SetSegment(4)
+	l := New(sink, Config{Format: Binary, Fields: []string{"v"}})
+
+	// 8 bytes of header and 14 per record: a segment of 2 blocks holds 72
+	// records.
+	for i := 0; i < 100; i++ {
+		if err := l.Log(t0.Add(time.Duration(i)*time.Second), int32(i)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	l.Close()
+	if sink.Segment() != 0 || opened != 100 {
+		t.Fatalf("got segment %d, opened %d times", sink.Segment(), opened)
+	}
+
+	// Segment 1, blocks 102 and 103, then segment 2.
+	data := make([]byte, 6*512)
+	card.ReadBlocks(100, data)
+	if !bytes.Equal(data[:512], bytes.Repeat([]byte{0xEE}, 512)) {
+		t.Error("segment 0 was written")
+	}
+	for seg, records := range [][2]int{{0, 72}, {72, 28}} {
+		b := data[1024+seg*1024:]
+		if string(b[:4]) != binaryMagic {
+			t.Fatalf("segment %d: no header", seg+1)
+		}
+		b = b[8:]
+		for i := records[0]; i < records[0]+records[1]; i++ {
+			if b[0] != recordMarker || crc8(b[:13]) != b[13] || int32(binary.LittleEndian.Uint32(b[9:])) != int32(i) {
+				t.Fatalf("segment %d: bad record %d", seg+1, i)
+			}
+			b = b[14:]
+		}
+		if seg == 1 && !bytes.Equal(b[:512], make([]byte, 512)) {
+			t.Error("segment 2 not erased after its records")
+		}
+	}
+
+	if _, _, err := newRegionSink(open, card.EraseBlocks, 0, 2, 3).Next(); err != errSegments {
+		t.Errorf("segments smaller than a block: got %v", err)
+	}
+}
+
+// format formats card with a FAT16 file system of 1 sector per cluster.
+func format(card *sdcard.MemoryCard) {
+	var bs [512]byte
+	le := binary.LittleEndian
+	sectors := uint32(card.Size() / 512)
+	bs[0], bs[1], bs[2] = 0xEB, 0x3C, 0x90
+	le.PutUint16(bs[11:], 512)
+	bs[13] = 1
+	le.PutUint16(bs[14:], 1) // reserved sectors
+	bs[16] = 2               // FATs
+	le.PutUint16(bs[17:], 512)
+	bs[21] = 0xF8
+	le.PutUint16(bs[22:], uint16((sectors*2+511)/512))
+	le.PutUint32(bs[32:], sectors)
+	copy(bs[54:], "FAT16   ")
+	bs[510], bs[511] = 0x55, 0xAA
+	card.WriteAt(bs[:], 0)
+	for i := int64(0); i < 2; i++ {
+		card.WriteAt([]byte{0xF8, 0xFF, 0xFF, 0xFF}, (1+i*int64(le.Uint16(bs[22:])))*512)
+	}
+	card.Sync()
+}
+
+func TestFileSink(t *testing.T) {
+	card := sdcard.NewMemoryCard(4 << 20)
+	format(card)
+	fsys, err := fat.Mount(card)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := fsys.Mkdir("logs"); err != nil {
+		t.Fatal(err)
+	}
+	old, err := fsys.Create("logs/Temp00041.csv")
+	if err != nil {
+		t.Fatal(err)
+	}
+	old.Close()
+
+	sink := NewFileSink(fsys, "logs/TEMP", "CSV")
+	sink.SetKeep(2)
+	l := New(sink, Config{Fields: []string{"v"}, MaxAge: time.Hour})
+	for i := 0; i < 3; i++ {
+		if err := l.Log(t0.Add(time.Duration(i)*time.Hour), int32(i)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := l.Close(); err != nil {
+		t.Fatal(err)
+	}
+	entries, err := fsys.ReadDir("logs")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, e := range entries {
+		names = append(names, e.Name())
+	}
+	sort.Strings(names)
+	if len(names) != 2 || names[0] != "TEMP00043.CSV" || names[1] != "TEMP00044.CSV" {
+		t.Fatalf("got files %q", names)
+	}
+	f, err := fsys.Open("logs/TEMP00044.CSV")
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, _ := io.ReadAll(f)
+	if string(data) != "time,v\n2024-03-01 14:30:00.250,2\n" {
+		t.Errorf("got %q", data)
+	}
+
+	// The directory is created.
+	l = New(NewFileSink(fsys, "new/LOG", "BIN"), Config{Format: Binary})
+	if err := l.Log(t0); err != nil {
+		t.Fatal(err)
+	}
+	l.Close()
+	if _, err := fsys.Stat("new/LOG00001.BIN"); err != nil {
+		t.Error(err)
+	}
+}
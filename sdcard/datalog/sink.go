@@ -0,0 +1,230 @@
+package datalog
+
+import (
+	"errors"
+	"io"
+	"io/fs"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+
+	"tinygo.org/x/drivers/sdcard"
+	"tinygo.org/x/drivers/sdcard/fat"
+)
+
+var errSegments = errors.New("datalog: region smaller than its segments")
+
+// FileSink writes the segments to numbered files of a FAT file system:
+// TEMP00001.CSV, TEMP00002.CSV and so on. The numbering goes on from the
+// highest file found, so that the files of the previous runs are kept.
+type FileSink struct {
+	fsys    *fat.FS
+	dir     string
+	base    string
+	ext     string
+	keep    int
+	scanned bool
+	files   []int // numbers of the files, in order
+}
+
+// NewFileSink returns a sink writing to the files named prefix, a path with
+// the start of the file names, followed by a number and the extension ext.
+// The directory is created if needed.
+//
+// This function only creates the FileSink object, it does not touch the
+// device.
+func NewFileSink(fsys *fat.FS, prefix, ext string) *FileSink {
+	return &FileSink{fsys: fsys, dir: path.Dir(prefix), base: path.Base(prefix), ext: ext}
+}
+
+// SetKeep sets the number of files kept: once a new file is created, the
+// oldest ones are removed. 0, the default, keeps all of them.
+func (s *FileSink) SetKeep(n int) {
+	s.keep = n
+}
+
+// Next creates the next file.
+func (s *FileSink) Next() (io.WriteCloser, int64, error) {
+	if !s.scanned {
+		if err := s.scan(); err != nil {
+			return nil, 0, err
+		}
+		s.scanned = true
+	}
+	num := 1
+	if len(s.files) > 0 {
+		num = s.files[len(s.files)-1] + 1
+	}
+	f, err := s.fsys.Create(s.name(num))
+	if err != nil {
+		return nil, 0, err
+	}
+	s.files = append(s.files, num)
+	for s.keep > 0 && len(s.files) > s.keep {
+		if err := s.fsys.Remove(s.name(s.files[0])); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			f.Close()
+			return nil, 0, err
+		}
+		s.files = s.files[1:]
+	}
+	return f, 0, nil
+}
+
+// scan finds the files written before, and creates the directory if it
+// doesn't exist.
+func (s *FileSink) scan() error {
+	entries, err := s.fsys.ReadDir(s.dir)
+	if errors.Is(err, fs.ErrNotExist) {
+		return s.fsys.Mkdir(s.dir)
+	}
+	if err != nil {
+		return err
+	}
+	for _, e := range entries {
+		if num, ok := s.number(e.Name()); ok && !e.IsDir() {
+			s.files = append(s.files, num)
+		}
+	}
+	sort.Ints(s.files)
+	return nil
+}
+
+func (s *FileSink) name(num int) string {
+	digits := strconv.Itoa(num)
+	if len(digits) < 5 {
+		digits = "0000"[len(digits)-1:] + digits
+	}
+	return path.Join(s.dir, s.base+digits+"."+s.ext)
+}
+
+// number returns the number of the file name of a segment. The names are
+// matched without case, as by the file system.
+func (s *FileSink) number(name string) (int, bool) {
+	suffix := "." + s.ext
+	if len(name) <= len(s.base)+len(suffix) ||
+		!strings.EqualFold(name[:len(s.base)], s.base) ||
+		!strings.EqualFold(name[len(name)-len(suffix):], suffix) {
+		return 0, false
+	}
+	digits := name[len(s.base) : len(name)-len(suffix)]
+	num, err := strconv.Atoi(digits)
+	if err != nil || num <= 0 || digits[0] == '+' || digits[0] == '-' {
+		return 0, false
+	}
+	return num, true
+}
+
+// RegionSink writes the segments to a region of the blocks of a card, split
+// in segments of the same size used as a ring: once the last one is
+// written, the first one is overwritten. The blocks are written with the
+// streaming API of the card, without a file system, for the highest rate.
+//
+// Each segment is erased before it is written, so that it ends with erased
+// blocks rather than the records of the previous round. A sync point ends
+// the stream, padding the block being written; the next record writes it
+// again. Between sync points, the card is busy with the stream and must not
+// be used otherwise.
+type RegionSink struct {
+	open     func(start, n int64) (io.WriteCloser, error)
+	erase    func(start, n int64) error
+	start    int64
+	blocks   int64 // per segment
+	segments int64
+	segment  int64 // next to write
+}
+
+// NewRegionSink returns a sink writing to blocks blocks of card from start,
+// split in segments segments.
+//
+// This function only creates the RegionSink object, it does not touch the
+// device.
+func NewRegionSink(card *sdcard.Device, start, blocks, segments int64) *RegionSink {
+	open := func(start, n int64) (io.WriteCloser, error) {
+		return card.OpenBlockRangeWriter(start, n)
+	}
+	return newRegionSink(open, card.EraseBlocks, start, blocks, segments)
+}
+
+func newRegionSink(open func(start, n int64) (io.WriteCloser, error), erase func(start, n int64) error, start, blocks, segments int64) *RegionSink {
+	s := &RegionSink{open: open, erase: erase, start: start, segments: segments}
+	if segments > 0 {
+		s.blocks = blocks / segments
+	}
+	return s
+}
+
+// Segment returns the index of the next segment to be written, to be kept
+// by the application to go on after a reset with SetSegment.
+func (s *RegionSink) Segment() int64 {
+	return s.segment
+}
+
+// SetSegment sets the index of the next segment to be written.
+func (s *RegionSink) SetSegment(i int64) {
+	if s.segments > 0 {
+		s.segment = i % s.segments
+	}
+}
+
+// Next erases the next segment of the ring, and returns a writer for it.
+func (s *RegionSink) Next() (io.WriteCloser, int64, error) {
+	if s.blocks == 0 {
+		return nil, 0, errSegments
+	}
+	start := s.start + s.segment*s.blocks
+	if err := s.erase(start, s.blocks); err != nil {
+		return nil, 0, err
+	}
+	s.segment = (s.segment + 1) % s.segments
+	return &segmentWriter{sink: s, start: start}, s.blocks * 512, nil
+}
+
+// segmentWriter writes a segment as a stream of blocks, opened when written
+// and closed at sync points.
+type segmentWriter struct {
+	sink  *RegionSink
+	start int64
+	w     io.WriteCloser
+	n     int64 // bytes written
+	tail  [512]byte
+	tn    int // bytes of the last, partial block in tail
+}
+
+func (w *segmentWriter) Write(p []byte) (int, error) {
+	if w.w == nil {
+		// The stream starts again with the partial block, padded when it
+		// was ended.
+		block := w.n / 512
+		sw, err := w.sink.open(w.start+block, w.sink.blocks-block)
+		if err != nil {
+			return 0, err
+		}
+		w.w = sw
+		if _, err := w.w.Write(w.tail[:w.tn]); err != nil {
+			return 0, err
+		}
+	}
+	n, err := w.w.Write(p)
+	w.n += int64(n)
+	for q := p[:n]; len(q) > 0; {
+		k := copy(w.tail[w.tn:], q)
+		q = q[k:]
+		w.tn = (w.tn + k) % 512
+	}
+	return n, err
+}
+
+// Sync ends the stream, so that the blocks written are programmed.
+func (w *segmentWriter) Sync() error {
+	if w.w == nil {
+		return nil
+	}
+	err := w.w.Close()
+	w.w = nil
+	return err
+}
+
+func (w *segmentWriter) Close() error {
+	return w.Sync()
+}
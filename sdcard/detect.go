@@ -0,0 +1,64 @@
+package sdcard
+
+import "errors"
+
+// ErrCardRemoved is returned by the data methods when the card is not in the
+// slot, or was removed since the last Configure. Configure the card again
+// once it is back.
+var ErrCardRemoved = errors.New("sdcard: card removed")
+
+// cardDetect is the state of the card-detect switch. It is shared by the
+// copies of a Device, as most of its methods have value receivers.
+type cardDetect struct {
+	present  func() bool
+	inserted bool // state at the last Detect
+	removed  bool // removed since the last Configure
+}
+
+// SetPresence sets the function telling whether a card is in the slot,
+// usually reading the card-detect switch of the slot, see SetCardDetect.
+// Without it the card is assumed to always be there.
+func (d *Device) SetPresence(present func() bool) {
+	d.detect = &cardDetect{present: present, inserted: present()}
+}
+
+// Detect returns whether a card is in the slot. It calls OnRemove or
+// OnInsert when this changed since the previous call, so it can be polled to
+// handle hot-plugging. The card must be configured again after it was
+// inserted.
+func (d *Device) Detect() bool {
+	if d.detect == nil {
+		return true
+	}
+	in := d.detect.present()
+	if in == d.detect.inserted {
+		return in
+	}
+	d.detect.inserted = in
+	if !in {
+		d.detect.removed = true
+		d.cache.reset()
+		if d.OnRemove != nil {
+			d.OnRemove()
+		}
+	} else if d.OnInsert != nil {
+		d.OnInsert()
+	}
+	return in
+}
+
+// checkPresent returns ErrCardRemoved if the card is not in the slot, or
+// was removed since the last Configure: it may be another card.
+func (d Device) checkPresent() error {
+	if d.detect == nil {
+		return nil
+	}
+	if !d.detect.present() {
+		d.detect.removed = true
+		return ErrCardRemoved
+	}
+	if d.detect.removed {
+		return ErrCardRemoved
+	}
+	return nil
+}
@@ -0,0 +1,37 @@
+package sdcard
+
+import "testing"
+
+func TestDetect(t *testing.T) {
+	in := true
+	d := &Device{cache: blockCache{buf: make([]byte, 512)}}
+	d.SetPresence(func() bool { return in })
+	inserts, removes := 0, 0
+	d.OnInsert = func() { inserts++ }
+	d.OnRemove = func() { removes++ }
+
+	if !d.Detect() || inserts != 0 || removes != 0 {
+		t.Fatalf("card present: inserts %d, removes %d", inserts, removes)
+	}
+
+	in = false
+	buf := make([]byte, 512)
+	if err := d.ReadData(0, buf); err != ErrCardRemoved {
+		t.Errorf("ReadData without card: %v", err)
+	}
+	if d.Detect() || removes != 1 {
+		t.Errorf("card removed: removes %d", removes)
+	}
+
+	// Another card may have been inserted, which must be configured first.
+	in = true
+	if !d.Detect() || inserts != 1 {
+		t.Errorf("card inserted: inserts %d", inserts)
+	}
+	if _, err := d.ReadAt(buf[:10], 3); err != ErrCardRemoved {
+		t.Errorf("ReadAt before Configure: %v", err)
+	}
+	if err := d.WriteBlocks(0, buf); err != ErrCardRemoved {
+		t.Errorf("WriteBlocks before Configure: %v", err)
+	}
+}
@@ -0,0 +1,135 @@
+package sdcard
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"tinygo.org/x/drivers"
+)
+
+// ErrSecureEraseUnsupported is returned by SecureErase for cards that do
+// not support it.
+var ErrSecureEraseUnsupported = errors.New("sdcard: secure erase not supported")
+
+// Erase erases the blocks from startBlock to endBlock, both included, using
+// CMD32, CMD33 and CMD38. Erased blocks read as all 0x00 or all 0xFF,
+// depending on the card.
+//
+// Standard capacity cards with ERASE_BLK_EN cleared in the CSD can only
+// erase whole sectors of SECTOR_SIZE+1 blocks; they round the range to
+// sectors.
+func (d Device) Erase(startBlock, endBlock int64) error {
+	if endBlock < startBlock {
+		return fmt.Errorf("endBlock must not be lower than startBlock")
+	}
+	if err := d.checkPresent(); err != nil {
+		return err
+	}
+	return d.erase(startBlock, endBlock, false, d.eraseTimeout(endBlock-startBlock+1))
+}
+
+// SecureErase erases the blocks from startBlock to endBlock, both included,
+// with the secure erase of the content protection of the card (CMD32, CMD33
+// and ACMD38), for devices handling sensitive data. Only cards in secured
+// mode, as reported by their SD Status register, support it: others return
+// ErrSecureEraseUnsupported, and should use Erase.
+//
+// A secure erase can take much longer than an erase: it waits for the erase
+// time given by the SD Status for the allocation units of the range.
+func (d Device) SecureErase(startBlock, endBlock int64) error {
+	if endBlock < startBlock {
+		return fmt.Errorf("endBlock must not be lower than startBlock")
+	}
+	if err := d.checkPresent(); err != nil {
+		return err
+	}
+	if d.kind == CardMMC {
+		return ErrSecureEraseUnsupported
+	}
+	status, err := d.ReadSDStatus()
+	if err != nil {
+		return err
+	}
+	if !status.SecuredMode() {
+		return ErrSecureEraseUnsupported
+	}
+	return d.erase(startBlock, endBlock, true, d.secureEraseTimeout(&status, endBlock-startBlock+1))
+}
+
+// erase erases a range of blocks with CMD38, or ACMD38 when secure is set,
+// and waits up to timeout for the card to be done.
+func (d Device) erase(startBlock, endBlock int64, secure bool, timeout time.Duration) error {
+	start, end := uint32(startBlock), uint32(endBlock)
+	// use address if not SDHC card
+	if !d.blockAddressed() {
+		start <<= 9
+		end <<= 9
+	}
+	defer d.cs.High()
+
+	if d.cmd(CMD32_ERASE_WR_BLK_START_ADDR, start, 0xFF) != 0 {
+		return fmt.Errorf("CMD32 error")
+	}
+	if d.cmd(CMD33_ERASE_WR_BLK_END_ADDR, end, 0xFF) != 0 {
+		return fmt.Errorf("CMD33 error")
+	}
+	if secure {
+		if d.acmd(ACMD38_SECURE_ERASE, 0) != 0 {
+			return fmt.Errorf("ACMD38 error")
+		}
+	} else if d.cmd(CMD38_ERASE, 0, 0xFF) != 0 {
+		return fmt.Errorf("CMD38 error")
+	}
+
+	// The card holds the data line low until the erase is done.
+	begin := time.Now()
+	for {
+		r, err := d.bus.Transfer(byte(0xFF))
+		if err != nil {
+			return err
+		}
+		if r == 0xFF {
+			return nil
+		}
+		if time.Since(begin) > timeout {
+			return fmt.Errorf("SD_CARD_ERROR_ERASE_TIMEOUT")
+		}
+		if err := d.canceled(); err != nil {
+			return err
+		}
+		drivers.Yield()
+	}
+}
+
+// eraseTimeout returns the time an erase of the given number of blocks may
+// take: the write timeout of the CSD for every block, and at least one
+// second.
+func (d Device) eraseTimeout(blocks int64) time.Duration {
+	perBlock := 250 * time.Millisecond
+	if d.CSD != nil {
+		perBlock = d.CSD.WriteTimeout()
+	}
+	timeout := perBlock * time.Duration(blocks)
+	if timeout < time.Second {
+		timeout = time.Second
+	}
+	return timeout
+}
+
+// secureEraseTimeout returns the time a secure erase of the given number of
+// blocks may take: the erase time given by the SD Status for the allocation
+// units they span, plus one as the range may not be aligned, and at least
+// one second. Cards not giving it get the timeout of Erase.
+func (d Device) secureEraseTimeout(status *SDStatus, blocks int64) time.Duration {
+	au := int64(status.AUSize())
+	if au == 0 || status.EraseSize() == 0 {
+		return d.eraseTimeout(blocks)
+	}
+	aus := (blocks*512+au-1)/au + 1
+	timeout := status.EraseTime(int(aus))
+	if timeout < time.Second {
+		timeout = time.Second
+	}
+	return timeout
+}
@@ -0,0 +1,61 @@
+package sdcard
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestSecureErase(t *testing.T) {
+	d, card := newSPICard()
+	if err := d.Erase(2, 5); err != nil {
+		t.Fatal(err)
+	}
+	if want := []uint32{2, 5, 38}; !reflect.DeepEqual(card.erase, want) {
+		t.Errorf("Erase: commands %v, want %v", card.erase, want)
+	}
+
+	card.erase = nil
+	if err := d.SecureErase(2, 5); err != ErrSecureEraseUnsupported {
+		t.Errorf("card not in secured mode: %v", err)
+	}
+	if card.erase != nil {
+		t.Errorf("erased %v", card.erase)
+	}
+
+	card.status[0] = 0x20 // secured mode
+	if err := d.SecureErase(2, 5); err != nil {
+		t.Fatal(err)
+	}
+	if want := []uint32{2, 5, 138}; !reflect.DeepEqual(card.erase, want) {
+		t.Errorf("SecureErase: commands %v, want %v", card.erase, want)
+	}
+}
+
+func TestSecureEraseTimeout(t *testing.T) {
+	d := Device{CSD: &CSD{CSD_STRUCTURE: 1}}
+	var s SDStatus
+	// Allocation units of 4MB, 2 of them erased in 3s, plus 1s.
+	s[10] = 0x90
+	s[11], s[12] = 0, 2
+	s[13] = 3<<2 | 1
+	for _, tc := range []struct {
+		blocks int64
+		want   time.Duration
+	}{
+		{1, 4 * time.Second},              // 2 units
+		{8192, 4 * time.Second},           // 2 units
+		{8193, 5500 * time.Millisecond},   // 3 units
+		{65536, 14500 * time.Millisecond}, // 9 units
+	} {
+		if got := d.secureEraseTimeout(&s, tc.blocks); got != tc.want {
+			t.Errorf("%d blocks: got %v, want %v", tc.blocks, got, tc.want)
+		}
+	}
+
+	// Without erase times in the SD Status, the timeout of Erase.
+	s[11], s[12] = 0, 0
+	if got, want := d.secureEraseTimeout(&s, 8), 2*time.Second; got != want {
+		t.Errorf("without erase times: got %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,561 @@
+package fat
+
+import (
+	"encoding/binary"
+	"errors"
+	"path"
+	"strings"
+	"time"
+	"unicode/utf16"
+)
+
+var errEndOfDir = errors.New("fat: end of directory")
+
+// Attributes of directory entries.
+const (
+	attrReadOnly = 0x01
+	attrHidden   = 0x02
+	attrSystem   = 0x04
+	attrVolume   = 0x08
+	attrDir      = 0x10
+	attrArchive  = 0x20
+	attrLFN      = 0x0F // long file name entry
+)
+
+const (
+	entrySize   = 32
+	entryFree   = 0xE5 // first byte of a deleted entry
+	lfnChars    = 13   // characters of a long name entry
+	maxNameSize = 255
+)
+
+// Offsets of the characters in a long name entry.
+var lfnOffsets = [lfnChars]uint8{1, 3, 5, 7, 9, 14, 16, 18, 20, 22, 24, 28, 30}
+
+// dirEntry is a file or directory found in a directory.
+type dirEntry struct {
+	name  string
+	short [11]byte
+	attr  uint8
+	first uint32 // first cluster, 0 when empty
+	size  uint32
+	date  uint16
+	time  uint16
+	dir   uint32 // directory holding the entry
+	index uint32 // of the short entry in dir
+	slots uint32 // long name entries before it
+	root  bool
+}
+
+func (e *dirEntry) isDir() bool {
+	return e.attr&attrDir != 0
+}
+
+// cluster returns the first cluster of a directory, 0 being the root.
+func (e *dirEntry) cluster(fsys *FS) uint32 {
+	if e.first == 0 {
+		return fsys.root()
+	}
+	return e.first
+}
+
+func (e *dirEntry) modTime() time.Time {
+	return time.Date(1980+int(e.date>>9), time.Month(e.date>>5&0x0F), int(e.date&0x1F),
+		int(e.time>>11), int(e.time>>5&0x3F), int(e.time&0x1F)*2, 0, time.UTC)
+}
+
+// root returns the cluster of the root directory: 0 for the fixed root
+// directory of FAT16.
+func (fsys *FS) root() uint32 {
+	return fsys.rootCluster
+}
+
+func (fsys *FS) rootEntry() dirEntry {
+	return dirEntry{attr: attrDir, root: true, first: fsys.root()}
+}
+
+// entryAddr returns the offset of entry i of a directory on the device, or
+// errEndOfDir.
+func (fsys *FS) entryAddr(dir, i uint32) (int64, error) {
+	if dir == 0 {
+		if i >= fsys.rootEntries {
+			return 0, errEndOfDir
+		}
+		return fsys.rootStart + int64(i)*entrySize, nil
+	}
+	per := fsys.clusterSize / entrySize
+	c := dir
+	for n := i / per; n > 0; n-- {
+		next, err := fsys.next(c)
+		if err != nil {
+			return 0, err
+		}
+		if next >= clusterBad {
+			return 0, errEndOfDir
+		}
+		c = next
+	}
+	return fsys.clusterAddr(c) + int64(i%per)*entrySize, nil
+}
+
+// scan calls fn with the entries of a directory, except volume labels,
+// until it returns true.
+func (fsys *FS) scan(dir uint32, fn func(e *dirEntry) bool) error {
+	var b [entrySize]byte
+	var lfn [20 * lfnChars]uint16
+	var count, expect uint8 // long name entries, and the next one expected
+	var sum uint8
+	var start uint32
+	for i := uint32(0); ; i++ {
+		addr, err := fsys.entryAddr(dir, i)
+		if err == errEndOfDir {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if _, err := fsys.dev.ReadAt(b[:], addr); err != nil {
+			return err
+		}
+		if b[0] == 0 {
+			return nil
+		}
+		if b[0] == entryFree {
+			count = 0
+			continue
+		}
+		if b[11]&0x3F == attrLFN {
+			seq := b[0] & 0x1F
+			if b[0]&0x40 != 0 {
+				count, expect, sum, start = seq, seq, b[13], i
+			}
+			if count == 0 || seq == 0 || seq > 20 || seq != expect || b[13] != sum {
+				count = 0
+				continue
+			}
+			for k, off := range lfnOffsets {
+				lfn[int(seq-1)*lfnChars+k] = binary.LittleEndian.Uint16(b[off:])
+			}
+			expect--
+			continue
+		}
+		if b[11]&attrVolume != 0 {
+			count = 0
+			continue
+		}
+		e := dirEntry{
+			attr:  b[11],
+			first: uint32(binary.LittleEndian.Uint16(b[20:]))<<16 | uint32(binary.LittleEndian.Uint16(b[26:])),
+			size:  binary.LittleEndian.Uint32(b[28:]),
+			time:  binary.LittleEndian.Uint16(b[22:]),
+			date:  binary.LittleEndian.Uint16(b[24:]),
+			dir:   dir,
+			index: i,
+		}
+		copy(e.short[:], b[:11])
+		if !fsys.fat32 {
+			e.first &= 0xFFFF
+		}
+		if count != 0 && expect == 0 && checksum(e.short[:]) == sum {
+			name := lfn[:int(count)*lfnChars]
+			for k, c := range name {
+				if c == 0 {
+					name = name[:k]
+					break
+				}
+			}
+			e.name = string(utf16.Decode(name))
+			e.slots = i - start
+		} else {
+			e.name = shortDisplayName(e.short[:], b[12])
+		}
+		count = 0
+		if fn(&e) {
+			return nil
+		}
+	}
+}
+
+// lookup returns the entry of a directory with a name, long or short.
+func (fsys *FS) lookup(dir uint32, name string) (dirEntry, error) {
+	var found dirEntry
+	ok := false
+	err := fsys.scan(dir, func(e *dirEntry) bool {
+		if strings.EqualFold(e.name, name) || strings.EqualFold(shortDisplayName(e.short[:], 0), name) {
+			found, ok = *e, true
+		}
+		return ok
+	})
+	if err == nil && !ok {
+		err = errNotExist
+	}
+	return found, err
+}
+
+// split returns the directory holding the last element of a path, and that
+// element, "" for the root directory.
+func (fsys *FS) split(name string) (uint32, string, error) {
+	name = path.Clean("/" + name)[1:]
+	dir := fsys.root()
+	if name == "" {
+		return dir, "", nil
+	}
+	elems := strings.Split(name, "/")
+	for _, elem := range elems[:len(elems)-1] {
+		e, err := fsys.lookup(dir, elem)
+		if err != nil {
+			return 0, "", err
+		}
+		if !e.isDir() {
+			return 0, "", errNotDir
+		}
+		dir = e.cluster(fsys)
+	}
+	return dir, elems[len(elems)-1], nil
+}
+
+// find returns the entry of a path.
+func (fsys *FS) find(name string) (dirEntry, error) {
+	dir, base, err := fsys.split(name)
+	if err != nil {
+		return dirEntry{}, err
+	}
+	if base == "" {
+		return fsys.rootEntry(), nil
+	}
+	return fsys.lookup(dir, base)
+}
+
+// create adds an entry to a directory. The name must not exist.
+func (fsys *FS) create(dir uint32, name string, attr uint8, first uint32) (dirEntry, error) {
+	if !validName(name) {
+		return dirEntry{}, errName
+	}
+	short, caseFlags, exact := shortName(name)
+	var long []uint16
+	if !exact {
+		long = utf16.Encode([]rune(name))
+		if err := fsys.uniqueShortName(dir, &short); err != nil {
+			return dirEntry{}, err
+		}
+	}
+	slots := uint32((len(long) + lfnChars - 1) / lfnChars)
+	index, err := fsys.freeEntries(dir, slots+1)
+	if err != nil {
+		return dirEntry{}, err
+	}
+
+	var b [entrySize]byte
+	sum := checksum(short[:])
+	for k := slots; k >= 1; k-- {
+		b = [entrySize]byte{}
+		b[0] = uint8(k)
+		if k == slots {
+			b[0] |= 0x40
+		}
+		b[11], b[13] = attrLFN, sum
+		for j, off := range lfnOffsets {
+			c := uint16(0xFFFF) // padding after the terminating 0
+			switch n := int(k-1)*lfnChars + j; {
+			case n < len(long):
+				c = long[n]
+			case n == len(long):
+				c = 0
+			}
+			binary.LittleEndian.PutUint16(b[off:], c)
+		}
+		if err := fsys.writeEntry(dir, index+slots-k, b[:]); err != nil {
+			return dirEntry{}, err
+		}
+	}
+
+	e := dirEntry{name: name, short: short, attr: attr, first: first, dir: dir, index: index + slots, slots: slots}
+	e.date, e.time = fatTime(time.Now())
+	b = [entrySize]byte{}
+	copy(b[:], short[:])
+	b[11], b[12] = attr, caseFlags
+	binary.LittleEndian.PutUint16(b[14:], e.time) // creation
+	binary.LittleEndian.PutUint16(b[16:], e.date)
+	binary.LittleEndian.PutUint16(b[18:], e.date) // last access
+	e.encode(b[:])
+	return e, fsys.writeEntry(dir, e.index, b[:])
+}
+
+// encode sets the cluster, size and modification time of a short entry.
+func (e *dirEntry) encode(b []byte) {
+	binary.LittleEndian.PutUint16(b[20:], uint16(e.first>>16))
+	binary.LittleEndian.PutUint16(b[22:], e.time)
+	binary.LittleEndian.PutUint16(b[24:], e.date)
+	binary.LittleEndian.PutUint16(b[26:], uint16(e.first))
+	binary.LittleEndian.PutUint32(b[28:], e.size)
+}
+
+// update writes the cluster, size and modification time of an entry.
+func (fsys *FS) update(e *dirEntry) error {
+	addr, err := fsys.entryAddr(e.dir, e.index)
+	if err != nil {
+		return err
+	}
+	var b [entrySize]byte
+	if _, err := fsys.dev.ReadAt(b[:], addr); err != nil {
+		return err
+	}
+	e.encode(b[:])
+	b[11] = e.attr
+	_, err = fsys.dev.WriteAt(b[:], addr)
+	return err
+}
+
+// remove deletes an entry, with its long name.
+func (fsys *FS) remove(e *dirEntry) error {
+	for i := e.index - e.slots; i <= e.index; i++ {
+		addr, err := fsys.entryAddr(e.dir, i)
+		if err != nil {
+			return err
+		}
+		if _, err := fsys.dev.WriteAt([]byte{entryFree}, addr); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (fsys *FS) writeEntry(dir, i uint32, b []byte) error {
+	addr, err := fsys.entryAddr(dir, i)
+	if err != nil {
+		return err
+	}
+	_, err = fsys.dev.WriteAt(b, addr)
+	return err
+}
+
+// freeEntries returns the index of n free consecutive entries of a
+// directory, which grows when it has none.
+func (fsys *FS) freeEntries(dir, n uint32) (uint32, error) {
+	var b [1]byte
+	run, start := uint32(0), uint32(0)
+	for i := uint32(0); ; i++ {
+		addr, err := fsys.entryAddr(dir, i)
+		if err == errEndOfDir {
+			if dir == 0 {
+				return 0, errRootFull
+			}
+			var last, c uint32
+			last, err = fsys.last(dir)
+			if err != nil {
+				return 0, err
+			}
+			c, err = fsys.alloc(last)
+			if err != nil {
+				return 0, err
+			}
+			if err = fsys.zeroCluster(c); err != nil {
+				return 0, err
+			}
+			addr, err = fsys.entryAddr(dir, i)
+		}
+		if err != nil {
+			return 0, err
+		}
+		if _, err := fsys.dev.ReadAt(b[:], addr); err != nil {
+			return 0, err
+		}
+		if b[0] != 0 && b[0] != entryFree {
+			run = 0
+			continue
+		}
+		if run == 0 {
+			start = i
+		}
+		run++
+		if run == n {
+			return start, nil
+		}
+	}
+}
+
+// last returns the last cluster of a chain.
+func (fsys *FS) last(c uint32) (uint32, error) {
+	for n := uint32(0); n <= fsys.clusters; n++ {
+		next, err := fsys.next(c)
+		if err != nil {
+			return 0, err
+		}
+		if next >= clusterBad {
+			return c, nil
+		}
+		if next < 2 {
+			return 0, errCorrupt
+		}
+		c = next
+	}
+	return 0, errCorrupt
+}
+
+// uniqueShortName replaces the end of the base of a short name by ~N, so
+// that it is unique in the directory.
+func (fsys *FS) uniqueShortName(dir uint32, short *[11]byte) error {
+	base := 8
+	for base > 0 && short[base-1] == ' ' {
+		base--
+	}
+	for n := 1; n < 1000000; n++ {
+		tail := "~" + itoa(n)
+		pos := base
+		if pos > 8-len(tail) {
+			pos = 8 - len(tail)
+		}
+		copy(short[pos:], tail)
+		for i := pos + len(tail); i < 8; i++ {
+			short[i] = ' '
+		}
+		taken := false
+		err := fsys.scan(dir, func(e *dirEntry) bool {
+			taken = e.short == *short
+			return taken
+		})
+		if err != nil {
+			return err
+		}
+		if !taken {
+			return nil
+		}
+	}
+	return errName
+}
+
+func itoa(n int) string {
+	var b [8]byte
+	i := len(b)
+	for {
+		i--
+		b[i] = byte('0' + n%10)
+		n /= 10
+		if n == 0 {
+			return string(b[i:])
+		}
+	}
+}
+
+// validName returns whether a name can be a long file name.
+func validName(name string) bool {
+	if name == "" || name == "." || name == ".." || len(name) > maxNameSize {
+		return false
+	}
+	if last := name[len(name)-1]; last == ' ' || last == '.' {
+		return false
+	}
+	for _, c := range name {
+		if c < 0x20 || strings.ContainsRune(`"*/:<>?\|`, c) {
+			return false
+		}
+	}
+	return true
+}
+
+// shortName returns the short name of a name, the flags of the lower case
+// parts of the short name, and whether the short name is exactly the name,
+// so that it needs no long name entries.
+func shortName(name string) (short [11]byte, caseFlags uint8, exact bool) {
+	for i := range short {
+		short[i] = ' '
+	}
+	base, ext := name, ""
+	if i := strings.LastIndexByte(name, '.'); i > 0 {
+		base, ext = name[:i], name[i+1:]
+	}
+	exact = len(base) <= 8 && len(ext) <= 3 && base != ""
+	b, lower, upper := shortPart(base, short[:8])
+	if !b || lower && upper {
+		exact = false
+	} else if lower {
+		caseFlags |= 0x08
+	}
+	e, lower, upper := shortPart(ext, short[8:])
+	if !e || lower && upper {
+		exact = false
+	} else if lower {
+		caseFlags |= 0x10
+	}
+	if short[0] == entryFree {
+		short[0] = 0x05
+	}
+	return short, caseFlags, exact
+}
+
+// shortPart fills dst with the upper case valid characters of a part of a
+// name, and returns whether they were all valid and kept, and whether it
+// had lower and upper case letters.
+func shortPart(s string, dst []byte) (ok, lower, upper bool) {
+	ok = true
+	n := 0
+	for _, c := range s {
+		switch {
+		case c >= 'a' && c <= 'z':
+			lower = true
+			c -= 'a' - 'A'
+		case c >= 'A' && c <= 'Z':
+			upper = true
+		case c == ' ' || c == '.':
+			// Dropped.
+			ok = false
+			continue
+		case c >= 0x80 || strings.ContainsRune("+,;=[]", c):
+			ok = false
+			c = '_'
+		}
+		if n == len(dst) {
+			ok = false
+			break
+		}
+		dst[n] = byte(c)
+		n++
+	}
+	return ok, lower, upper
+}
+
+// shortDisplayName returns the name of a short entry, in lower case where
+// caseFlags says so.
+func shortDisplayName(short []byte, caseFlags uint8) string {
+	var b [12]byte
+	n := 0
+	dot := false
+	for i, c := range short[:11] {
+		if c == ' ' {
+			continue
+		}
+		if i == 0 && c == 0x05 {
+			c = entryFree
+		}
+		if i >= 8 && !dot {
+			b[n] = '.'
+			n++
+			dot = true
+		}
+		if c >= 'A' && c <= 'Z' && (i < 8 && caseFlags&0x08 != 0 || i >= 8 && caseFlags&0x10 != 0) {
+			c += 'a' - 'A'
+		}
+		b[n] = c
+		n++
+	}
+	return string(b[:n])
+}
+
+// checksum returns the checksum of a short name, in its long name entries.
+func checksum(short []byte) uint8 {
+	var sum uint8
+	for _, c := range short[:11] {
+		sum = (sum&1)<<7 + sum>>1 + c
+	}
+	return sum
+}
+
+// fatTime returns the date and time of a directory entry. Times before 1980
+// are 1980-01-01.
+func fatTime(t time.Time) (uint16, uint16) {
+	if t.Year() < 1980 {
+		return 1<<5 | 1, 0
+	}
+	date := uint16(t.Year()-1980)<<9 | uint16(t.Month())<<5 | uint16(t.Day())
+	return date, uint16(t.Hour())<<11 | uint16(t.Minute())<<5 | uint16(t.Second()/2)
+}
@@ -0,0 +1,387 @@
+// Package fat implements the FAT16 and FAT32 file systems, with long file
+// names, to read and write the files of cards formatted on a computer.
+//
+//	fsys, err := fat.Mount(card)
+//	if err != nil {
+//		return err
+//	}
+//	f, err := fsys.Create("/logs/Temperature log.csv")
+//	...
+//	f.Write(line)
+//	f.Close()
+//
+// Mount takes any block device: a *sdcard.Device, a *sdcard.SDIOCard, a
+// partition, or a cache in front of them. On a card with an MBR, it mounts
+// the first FAT partition.
+//
+// Files are opened with Open, Create and OpenFile, and read and written
+// through a File, with the methods of an os.File. Changes to a file are
+// only complete on the card after Close or Sync: call them before the card
+// is removed or powered down.
+//
+// FAT12, used by floppy disks and cards smaller than 16MB, and exFAT, used
+// by cards larger than 32GB, are not supported.
+package fat // import "tinygo.org/x/drivers/sdcard/fat"
+
+import (
+	"encoding/binary"
+	"errors"
+	"io/fs"
+
+	"tinygo.org/x/drivers/sdcard/partition"
+)
+
+var (
+	errNotFAT   = errors.New("fat: no FAT16 or FAT32 file system")
+	errFAT12    = errors.New("fat: FAT12 is not supported")
+	errCorrupt  = errors.New("fat: corrupted cluster chain")
+	errNoSpace  = errors.New("fat: no space left")
+	errRootFull = errors.New("fat: root directory full")
+	errNotEmpty = errors.New("fat: directory not empty")
+	errName     = errors.New("fat: invalid file name")
+	errTooLarge = errors.New("fat: file too large")
+	errIsDir    = errors.New("fat: is a directory")
+	errNotDir   = errors.New("fat: not a directory")
+)
+
+// BlockDevice is the BlockDevice interface of TinyFS, implemented by the
+// cards of the sdcard package.
+type BlockDevice interface {
+	ReadAt(buf []byte, off int64) (n int, err error)
+	WriteAt(buf []byte, off int64) (n int, err error)
+	Size() int64
+	WriteBlockSize() int64
+	EraseBlockSize() int64
+	EraseBlocks(start, len int64) error
+}
+
+// syncer is implemented by devices holding writes in a cache.
+type syncer interface {
+	Sync() error
+}
+
+// FS is a mounted FAT file system. It must not be used concurrently.
+type FS struct {
+	dev         BlockDevice
+	fat32       bool
+	clusterSize uint32 // in bytes
+	fatStart    int64  // offset of the first FAT
+	fatSize     int64  // size of a FAT in bytes
+	fats        int    // copies of the FAT
+	rootStart   int64  // offset of the root directory of FAT16
+	rootEntries uint32 // entries of the root directory of FAT16
+	rootCluster uint32 // first cluster of the root directory of FAT32
+	dataStart   int64  // offset of cluster 2
+	clusters    uint32 // number of data clusters
+	fsInfo      int64  // offset of the FSInfo sector of FAT32, or 0
+	infoStale   bool   // the free count of FSInfo was invalidated
+	nextFree    uint32 // where to look for a free cluster
+
+	// One sector of the FAT.
+	fat      [512]byte
+	fatOff   int64 // offset in the FAT, or -1
+	fatDirty bool
+}
+
+// Mount mounts the FAT file system of a device, or of its first FAT
+// partition.
+func Mount(dev BlockDevice) (*FS, error) {
+	var bs [512]byte
+	if _, err := dev.ReadAt(bs[:], 0); err != nil {
+		return nil, err
+	}
+	fsys, err := mount(dev, bs[:])
+	if err != errNotFAT {
+		return fsys, err
+	}
+	parts, perr := partition.Read(dev)
+	if perr != nil {
+		return nil, errNotFAT
+	}
+	for i := range parts {
+		if !parts[i].IsFAT() {
+			continue
+		}
+		if _, err := parts[i].ReadAt(bs[:], 0); err != nil {
+			return nil, err
+		}
+		return mount(&parts[i], bs[:])
+	}
+	return nil, errNotFAT
+}
+
+// mount reads the BIOS parameter block in the boot sector bs.
+func mount(dev BlockDevice, bs []byte) (*FS, error) {
+	le := binary.LittleEndian
+	sector := uint32(le.Uint16(bs[11:]))
+	perCluster := uint32(bs[13])
+	reserved := uint32(le.Uint16(bs[14:]))
+	fats := uint32(bs[16])
+	rootEntries := uint32(le.Uint16(bs[17:]))
+	total := uint32(le.Uint16(bs[19:]))
+	fatSectors := uint32(le.Uint16(bs[22:]))
+	if bs[510] != 0x55 || bs[511] != 0xAA || bs[0] != 0xEB && bs[0] != 0xE9 ||
+		sector < 512 || sector > 4096 || sector&(sector-1) != 0 ||
+		perCluster == 0 || perCluster&(perCluster-1) != 0 || reserved == 0 || fats == 0 {
+		return nil, errNotFAT
+	}
+	if total == 0 {
+		total = le.Uint32(bs[32:])
+	}
+	if fatSectors == 0 {
+		fatSectors = le.Uint32(bs[36:])
+	}
+	rootSectors := (rootEntries*32 + sector - 1) / sector
+	meta := reserved + fats*fatSectors + rootSectors
+	if fatSectors == 0 || total <= meta {
+		return nil, errNotFAT
+	}
+	fsys := &FS{
+		dev:         dev,
+		clusterSize: sector * perCluster,
+		fatStart:    int64(reserved) * int64(sector),
+		fatSize:     int64(fatSectors) * int64(sector),
+		fats:        int(fats),
+		rootStart:   int64(reserved+fats*fatSectors) * int64(sector),
+		rootEntries: rootEntries,
+		dataStart:   int64(meta) * int64(sector),
+		clusters:    (total - meta) / perCluster,
+		nextFree:    2,
+		fatOff:      -1,
+	}
+	switch {
+	case fsys.clusters < 4085:
+		return nil, errFAT12
+	case fsys.clusters >= 65525:
+		fsys.fat32 = true
+		fsys.rootCluster = le.Uint32(bs[44:])
+		if info := le.Uint16(bs[48:]); info != 0 && info != 0xFFFF {
+			fsys.fsInfo = int64(info) * int64(sector)
+		}
+	}
+	// The FAT must hold an entry for every cluster.
+	if fsys.fatSize/fsys.entrySize() < int64(fsys.clusters)+2 {
+		return nil, errNotFAT
+	}
+	return fsys, nil
+}
+
+// Type returns "FAT16" or "FAT32".
+func (fsys *FS) Type() string {
+	if fsys.fat32 {
+		return "FAT32"
+	}
+	return "FAT16"
+}
+
+// Sync writes the changes to the FAT to the device, and the data the
+// device holds in its cache.
+func (fsys *FS) Sync() error {
+	if err := fsys.flushFAT(); err != nil {
+		return err
+	}
+	if s, ok := fsys.dev.(syncer); ok {
+		return s.Sync()
+	}
+	return nil
+}
+
+// FreeSpace returns the space left on the file system in bytes. It reads
+// the whole FAT.
+func (fsys *FS) FreeSpace() (int64, error) {
+	free := int64(0)
+	for c := uint32(2); c < fsys.clusters+2; c++ {
+		next, err := fsys.next(c)
+		if err != nil {
+			return 0, err
+		}
+		if next == 0 {
+			free++
+		}
+	}
+	return free * int64(fsys.clusterSize), nil
+}
+
+// Cluster values of the FAT, masked to 28 bits on FAT32.
+const (
+	clusterFree = 0
+	clusterBad  = 0x0FFFFFF7
+	clusterEnd  = 0x0FFFFFFF // end of chain
+)
+
+func (fsys *FS) entrySize() int64 {
+	if fsys.fat32 {
+		return 4
+	}
+	return 2
+}
+
+// loadFAT loads the sector of the FAT holding the entry of cluster c, and
+// returns the offset of the entry in it.
+func (fsys *FS) loadFAT(c uint32) (int, error) {
+	off := int64(c) * fsys.entrySize()
+	sector := off &^ 511
+	if sector != fsys.fatOff {
+		if err := fsys.flushFAT(); err != nil {
+			return 0, err
+		}
+		fsys.fatOff = -1
+		if _, err := fsys.dev.ReadAt(fsys.fat[:], fsys.fatStart+sector); err != nil {
+			return 0, err
+		}
+		fsys.fatOff = sector
+	}
+	return int(off - sector), nil
+}
+
+// next returns the FAT entry of cluster c: the cluster following it,
+// clusterFree, or at least clusterBad.
+func (fsys *FS) next(c uint32) (uint32, error) {
+	if c < 2 || c >= fsys.clusters+2 {
+		return 0, errCorrupt
+	}
+	i, err := fsys.loadFAT(c)
+	if err != nil {
+		return 0, err
+	}
+	if fsys.fat32 {
+		return binary.LittleEndian.Uint32(fsys.fat[i:]) & 0x0FFFFFFF, nil
+	}
+	v := uint32(binary.LittleEndian.Uint16(fsys.fat[i:]))
+	if v >= 0xFFF7 {
+		v |= 0x0FFF0000
+	}
+	return v, nil
+}
+
+// setNext sets the FAT entry of cluster c.
+func (fsys *FS) setNext(c, v uint32) error {
+	if err := fsys.invalidateInfo(); err != nil {
+		return err
+	}
+	i, err := fsys.loadFAT(c)
+	if err != nil {
+		return err
+	}
+	if fsys.fat32 {
+		// The top 4 bits are reserved.
+		old := binary.LittleEndian.Uint32(fsys.fat[i:])
+		binary.LittleEndian.PutUint32(fsys.fat[i:], old&0xF0000000|v&0x0FFFFFFF)
+	} else {
+		binary.LittleEndian.PutUint16(fsys.fat[i:], uint16(v))
+	}
+	fsys.fatDirty = true
+	return nil
+}
+
+// flushFAT writes the sector of the FAT loaded to all the copies of the
+// FAT.
+func (fsys *FS) flushFAT() error {
+	if !fsys.fatDirty {
+		return nil
+	}
+	for i := 0; i < fsys.fats; i++ {
+		off := fsys.fatStart + int64(i)*fsys.fatSize + fsys.fatOff
+		if _, err := fsys.dev.WriteAt(fsys.fat[:], off); err != nil {
+			return err
+		}
+	}
+	fsys.fatDirty = false
+	return nil
+}
+
+// invalidateInfo sets the free cluster count of the FSInfo sector of
+// FAT32 to unknown before the FAT is first changed, as it is not kept up
+// to date.
+func (fsys *FS) invalidateInfo() error {
+	if fsys.fsInfo == 0 || fsys.infoStale {
+		return nil
+	}
+	var sig [4]byte
+	if _, err := fsys.dev.ReadAt(sig[:], fsys.fsInfo); err != nil {
+		return err
+	}
+	if string(sig[:]) == "RRaA" {
+		unknown := []byte{0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF}
+		if _, err := fsys.dev.WriteAt(unknown, fsys.fsInfo+488); err != nil {
+			return err
+		}
+	}
+	fsys.infoStale = true
+	return nil
+}
+
+// alloc allocates a cluster at the end of the chain ending with prev, or
+// of a new chain when prev is 0.
+func (fsys *FS) alloc(prev uint32) (uint32, error) {
+	c := fsys.nextFree
+	for n := uint32(0); n < fsys.clusters; n++ {
+		if c >= fsys.clusters+2 {
+			c = 2
+		}
+		v, err := fsys.next(c)
+		if err != nil {
+			return 0, err
+		}
+		if v == clusterFree {
+			if err := fsys.setNext(c, clusterEnd); err != nil {
+				return 0, err
+			}
+			if prev != 0 {
+				if err := fsys.setNext(prev, c); err != nil {
+					return 0, err
+				}
+			}
+			fsys.nextFree = c + 1
+			return c, nil
+		}
+		c++
+	}
+	return 0, errNoSpace
+}
+
+// free frees the chain starting at cluster c.
+func (fsys *FS) free(c uint32) error {
+	for n := uint32(0); c >= 2 && c < clusterBad; n++ {
+		if n > fsys.clusters {
+			return errCorrupt
+		}
+		next, err := fsys.next(c)
+		if err != nil {
+			return err
+		}
+		if err := fsys.setNext(c, clusterFree); err != nil {
+			return err
+		}
+		if c < fsys.nextFree {
+			fsys.nextFree = c
+		}
+		c = next
+	}
+	return nil
+}
+
+// clusterAddr returns the offset of cluster c on the device.
+func (fsys *FS) clusterAddr(c uint32) int64 {
+	return fsys.dataStart + int64(c-2)*int64(fsys.clusterSize)
+}
+
+// zeroCluster fills cluster c with zeros.
+func (fsys *FS) zeroCluster(c uint32) error {
+	var zero [512]byte
+	addr := fsys.clusterAddr(c)
+	for off := int64(0); off < int64(fsys.clusterSize); off += 512 {
+		if _, err := fsys.dev.WriteAt(zero[:], addr+off); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// pathError returns err for an operation on a path, as the os package does.
+func pathError(op, name string, err error) error {
+	if err == nil {
+		return nil
+	}
+	return &fs.PathError{Op: op, Path: name, Err: err}
+}
@@ -0,0 +1,375 @@
+package fat
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"strings"
+	"testing"
+)
+
+var _ BlockDevice = (*memDev)(nil)
+
+// memDev is a sparse card in memory.
+type memDev struct {
+	sectors map[int64]*[512]byte
+	size    int64
+}
+
+func newMemDev(size int64) *memDev {
+	return &memDev{sectors: map[int64]*[512]byte{}, size: size}
+}
+
+func (m *memDev) ReadAt(buf []byte, off int64) (int, error) {
+	for n := 0; n < len(buf); {
+		s := m.sectors[(off+int64(n))/512]
+		in := int((off + int64(n)) % 512)
+		var k int
+		if s == nil {
+			k = copy(buf[n:], make([]byte, 512-in))
+		} else {
+			k = copy(buf[n:], s[in:])
+		}
+		n += k
+	}
+	return len(buf), nil
+}
+
+func (m *memDev) WriteAt(buf []byte, off int64) (int, error) {
+	for n := 0; n < len(buf); {
+		i := (off + int64(n)) / 512
+		s := m.sectors[i]
+		if s == nil {
+			s = new([512]byte)
+			m.sectors[i] = s
+		}
+		n += copy(s[(off+int64(n))%512:], buf[n:])
+	}
+	return len(buf), nil
+}
+
+func (m *memDev) Size() int64                        { return m.size }
+func (m *memDev) WriteBlockSize() int64              { return 512 }
+func (m *memDev) EraseBlockSize() int64              { return 512 }
+func (m *memDev) EraseBlocks(start, len int64) error { return nil }
+
+// syncDev is a card with a write cache.
+type syncDev struct {
+	*memDev
+	syncs int
+}
+
+func (m *syncDev) Sync() error {
+	m.syncs++
+	return nil
+}
+
+// format formats a device starting at sector start as a PC would, with
+// sectors of 512 bytes.
+func format(m *memDev, start, sectors uint32, perCluster uint8, fat32 bool) {
+	var bs [512]byte
+	le := binary.LittleEndian
+	bs[0], bs[1], bs[2] = 0xEB, 0x58, 0x90
+	copy(bs[3:], "MSWIN4.1")
+	le.PutUint16(bs[11:], 512)
+	bs[13] = perCluster
+	bs[16] = 2 // FATs
+	bs[21] = 0xF8
+	reserved, rootEntries := uint32(1), uint32(512)
+	if fat32 {
+		reserved, rootEntries = 32, 0
+	}
+	le.PutUint16(bs[14:], uint16(reserved))
+	le.PutUint16(bs[17:], uint16(rootEntries))
+	le.PutUint32(bs[32:], sectors)
+	size := uint32(2)
+	if fat32 {
+		size = 4
+	}
+	fatSectors := (sectors/uint32(perCluster)*size + 511) / 512
+	if fat32 {
+		le.PutUint32(bs[36:], fatSectors)
+		le.PutUint32(bs[44:], 2) // root cluster
+		le.PutUint16(bs[48:], 1) // FSInfo
+		copy(bs[82:], "FAT32   ")
+	} else {
+		le.PutUint16(bs[22:], uint16(fatSectors))
+		copy(bs[54:], "FAT16   ")
+	}
+	bs[510], bs[511] = 0x55, 0xAA
+	base := int64(start) * 512
+	m.WriteAt(bs[:], base)
+
+	// The first two entries of the FATs, and the root directory.
+	for i := uint32(0); i < 2; i++ {
+		fat := base + int64(reserved+i*fatSectors)*512
+		if fat32 {
+			m.WriteAt([]byte{0xF8, 0xFF, 0xFF, 0x0F, 0xFF, 0xFF, 0xFF, 0x0F, 0xFF, 0xFF, 0xFF, 0x0F}, fat)
+		} else {
+			m.WriteAt([]byte{0xF8, 0xFF, 0xFF, 0xFF}, fat)
+		}
+	}
+	if fat32 {
+		var info [512]byte
+		copy(info[:], "RRaA")
+		copy(info[484:], "rrAa")
+		le.PutUint32(info[488:], sectors/uint32(perCluster)-3)
+		le.PutUint32(info[492:], 3)
+		info[510], info[511] = 0x55, 0xAA
+		m.WriteAt(info[:], base+512)
+	}
+}
+
+func mountNew(t *testing.T, fat32 bool) (*FS, *memDev) {
+	t.Helper()
+	var m *memDev
+	if fat32 {
+		// 33MB in sectors of 512 bytes is the smallest FAT32.
+		m = newMemDev(34 << 20)
+		format(m, 0, 34<<11, 1, true)
+	} else {
+		m = newMemDev(16 << 20)
+		format(m, 0, 16<<11, 4, false)
+	}
+	fsys, err := Mount(m)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return fsys, m
+}
+
+func TestMount(t *testing.T) {
+	for _, fat32 := range []bool{false, true} {
+		fsys, _ := mountNew(t, fat32)
+		if want := map[bool]string{false: "FAT16", true: "FAT32"}[fat32]; fsys.Type() != want {
+			t.Errorf("type %s, want %s", fsys.Type(), want)
+		}
+		if list, err := fsys.ReadDir("/"); err != nil || len(list) != 0 {
+			t.Errorf("%s: root %v, %v", fsys.Type(), list, err)
+		}
+	}
+
+	// A card with an MBR, and the FAT16 partition second.
+	m := newMemDev(20 << 20)
+	mbr := make([]byte, 512)
+	mbr[446+4] = 0x83
+	binary.LittleEndian.PutUint32(mbr[446+8:], 1)
+	binary.LittleEndian.PutUint32(mbr[446+12:], 2047)
+	mbr[462+4] = 0x06
+	binary.LittleEndian.PutUint32(mbr[462+8:], 2048)
+	binary.LittleEndian.PutUint32(mbr[462+12:], 16<<11)
+	mbr[510], mbr[511] = 0x55, 0xAA
+	m.WriteAt(mbr, 0)
+	format(m, 2048, 16<<11, 4, false)
+	card := &syncDev{memDev: m}
+	fsys, err := Mount(card)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if f, err := fsys.Create("a.txt"); err != nil || f.Close() != nil {
+		t.Fatal(err)
+	}
+	// Closing the file syncs the card through the partition.
+	if card.syncs == 0 {
+		t.Error("card not synced")
+	}
+	// The root directory of the partition, after the FATs.
+	var name [8]byte
+	m.ReadAt(name[:], (2048+1+2*32)*512)
+	if string(name[:]) != "A       " {
+		t.Errorf("root directory entry %q", name)
+	}
+
+	if _, err := Mount(newMemDev(1 << 20)); err != errNotFAT {
+		t.Errorf("blank card: %v", err)
+	}
+}
+
+func TestFiles(t *testing.T) {
+	for _, fat32 := range []bool{false, true} {
+		fsys, _ := mountNew(t, fat32)
+		typ := fsys.Type()
+		if err := fsys.Mkdir("logs"); err != nil {
+			t.Fatal(err)
+		}
+		if err := fsys.Mkdir("/logs/2024 March"); err != nil {
+			t.Fatal(err)
+		}
+
+		// A file of several clusters, with a long name.
+		data := bytes.Repeat([]byte("0123456789abcdef"), 1000)
+		f, err := fsys.Create("/logs/2024 March/Temperature log.csv")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if n, err := f.Write(data); n != len(data) || err != nil {
+			t.Fatalf("%s: Write: %d, %v", typ, n, err)
+		}
+		if err := f.Close(); err != nil {
+			t.Fatal(err)
+		}
+
+		f, err = fsys.Open("LOGS/2024 march/temperature LOG.CSV")
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, err := io.ReadAll(f)
+		if err != nil || !bytes.Equal(got, data) {
+			t.Errorf("%s: read %d bytes, %v", typ, len(got), err)
+		}
+		if fi, _ := f.Stat(); fi.Name() != "Temperature log.csv" || fi.Size() != int64(len(data)) {
+			t.Errorf("%s: stat %s %d", typ, fi.Name(), fi.Size())
+		}
+		if _, err := f.Write([]byte("x")); !errors.Is(err, fs.ErrPermission) {
+			t.Errorf("%s: write to read-only file: %v", typ, err)
+		}
+		f.Close()
+
+		// Append, seek and overwrite.
+		f, _ = fsys.OpenFile("/logs/2024 March/Temperature log.csv", os.O_RDWR|os.O_APPEND)
+		f.Write([]byte("end"))
+		f.Close()
+		f, _ = fsys.OpenFile("/logs/2024 March/Temperature log.csv", os.O_RDWR)
+		f.Seek(-3, io.SeekEnd)
+		f.Write([]byte("END"))
+		buf := make([]byte, 5)
+		f.ReadAt(buf, int64(len(data))-2)
+		if string(buf) != "efEND" {
+			t.Errorf("%s: after append and overwrite: %q", typ, buf)
+		}
+		if err := f.Truncate(100); err != nil || f.Size() != 100 {
+			t.Errorf("%s: truncate: %v, %d", typ, err, f.Size())
+		}
+		f.Close()
+
+		// Short names, in lower case.
+		for _, name := range []string{"readme.txt", "CONFIG.INI", "a b.txt", "Mixed.txt", "very long name.text"} {
+			f, err := fsys.OpenFile("logs/"+name, os.O_WRONLY|os.O_CREATE|os.O_EXCL)
+			if err != nil {
+				t.Fatalf("%s: create %s: %v", typ, name, err)
+			}
+			f.Close()
+		}
+		if _, err := fsys.OpenFile("logs/readme.txt", os.O_WRONLY|os.O_CREATE|os.O_EXCL); !errors.Is(err, fs.ErrExist) {
+			t.Errorf("%s: exclusive create: %v", typ, err)
+		}
+		list, err := fsys.ReadDir("logs")
+		if err != nil {
+			t.Fatal(err)
+		}
+		var names []string
+		for _, fi := range list {
+			names = append(names, fi.Name())
+		}
+		if got, want := strings.Join(names, ","), "2024 March,readme.txt,CONFIG.INI,a b.txt,Mixed.txt,very long name.text"; got != want {
+			t.Errorf("%s: logs holds %s, want %s", typ, got, want)
+		}
+		if !list[0].IsDir() {
+			t.Errorf("%s: 2024 March is not a directory", typ)
+		}
+		if _, err := fsys.Stat("logs/VERYLO~1.TEX"); err != nil {
+			t.Errorf("%s: stat short name: %v", typ, err)
+		}
+
+		// Removing files frees their clusters.
+		free, _ := fsys.FreeSpace()
+		if err := fsys.Remove("logs"); err == nil {
+			t.Errorf("%s: removed a directory that is not empty", typ)
+		}
+		if err := fsys.Remove("logs/2024 March/Temperature log.csv"); err != nil {
+			t.Fatal(err)
+		}
+		if err := fsys.Remove("logs/2024 March"); err != nil {
+			t.Fatal(err)
+		}
+		if after, _ := fsys.FreeSpace(); after != free+2*int64(fsys.clusterSize) {
+			t.Errorf("%s: free space %d, then %d", typ, free, after)
+		}
+		if _, err := fsys.Stat("logs/2024 March"); !errors.Is(err, fs.ErrNotExist) {
+			t.Errorf("%s: stat of removed directory: %v", typ, err)
+		}
+	}
+}
+
+func TestGrowDir(t *testing.T) {
+	for _, fat32 := range []bool{false, true} {
+		fsys, _ := mountNew(t, fat32)
+		typ := fsys.Type()
+		free, _ := fsys.FreeSpace()
+		if err := fsys.Mkdir("/d"); err != nil {
+			t.Fatal(err)
+		}
+
+		// Each long name takes 4 entries, so that the directory grows to
+		// several clusters.
+		const files = 40
+		for i := 0; i < files; i++ {
+			f, err := fsys.Create(fmt.Sprintf("/d/A long file name number %d with.ext", i))
+			if err != nil {
+				t.Fatalf("%s: create file %d: %v", typ, i, err)
+			}
+			f.Close()
+		}
+		list, err := fsys.ReadDir("/d")
+		if err != nil || len(list) != files {
+			t.Fatalf("%s: /d holds %d files, %v", typ, len(list), err)
+		}
+		if list[files-1].Name() != "A long file name number 39 with.ext" {
+			t.Errorf("%s: last file %s", typ, list[files-1].Name())
+		}
+
+		// Only the clusters of the directory are used, the files are
+		// empty.
+		perCluster := int64(fsys.clusterSize) / 32
+		clusters := (2 + 4*files + perCluster - 1) / perCluster
+		if after, _ := fsys.FreeSpace(); after != free-clusters*int64(fsys.clusterSize) {
+			t.Errorf("%s: free space %d, then %d, want %d clusters used", typ, free, after, clusters)
+		}
+	}
+}
+
+// A directory written as by a PC: a long name, a deleted entry, and a short
+// name with lower case flags.
+func TestReadPC(t *testing.T) {
+	fsys, m := mountNew(t, false)
+	dir := make([]byte, 5*32)
+	lfn := func(e []byte, seq byte, sum byte, name string) {
+		e[0], e[11], e[13] = seq, 0x0F, sum
+		chars := append([]rune(name), 0)
+		for len(chars) < 13 {
+			chars = append(chars, 0xFFFF)
+		}
+		for i, off := range []int{1, 3, 5, 7, 9, 14, 16, 18, 20, 22, 24, 28, 30} {
+			binary.LittleEndian.PutUint16(e[off:], uint16(chars[i]))
+		}
+	}
+	short := "LONGFI~1TXT"
+	var sum byte
+	for i := 0; i < 11; i++ {
+		sum = (sum>>1 | sum<<7) + short[i]
+	}
+	lfn(dir[0:], 0x42, sum, "e.txt")
+	lfn(dir[32:], 0x01, sum, "Long file nam")
+	copy(dir[64:], short)
+	dir[64+11] = 0x20
+	binary.LittleEndian.PutUint32(dir[64+28:], 0)
+	copy(dir[96:], "\xE5ELETED TXT")
+	copy(dir[128:], "NOTES   MD ")
+	dir[128+11], dir[128+12] = 0x20, 0x08
+	m.WriteAt(dir, fsys.rootStart)
+
+	list, err := fsys.ReadDir("/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(list) != 2 || list[0].Name() != "Long file name.txt" || list[1].Name() != "notes.MD" {
+		for _, fi := range list {
+			t.Log(fi.Name())
+		}
+		t.Errorf("root directory of %d entries", len(list))
+	}
+}
@@ -0,0 +1,533 @@
+package fat
+
+import (
+	"io"
+	"io/fs"
+	"os"
+	"time"
+)
+
+var (
+	errNotExist = fs.ErrNotExist
+	errExist    = fs.ErrExist
+	errClosed   = fs.ErrClosed
+	errInvalid  = fs.ErrInvalid
+)
+
+// maxFileSize is the size limit of FAT files, 4GB - 1.
+const maxFileSize = 1<<32 - 1
+
+// File is an open file or directory.
+type File struct {
+	fsys   *FS
+	path   string
+	entry  dirEntry
+	flag   int
+	pos    int64
+	cur    uint32 // cluster of the chain last used
+	curIdx uint32 // index of cur in the chain
+	dirty  bool   // the entry needs an update
+	closed bool
+	list   []fs.FileInfo // of a directory, for ReadDir
+}
+
+// Open opens a file or directory for reading.
+func (fsys *FS) Open(name string) (*File, error) {
+	return fsys.OpenFile(name, os.O_RDONLY)
+}
+
+// Create creates a file, or truncates it if it exists, and opens it for
+// reading and writing.
+func (fsys *FS) Create(name string) (*File, error) {
+	return fsys.OpenFile(name, os.O_RDWR|os.O_CREATE|os.O_TRUNC)
+}
+
+// OpenFile opens a file with the flags of os.OpenFile: O_RDONLY, O_WRONLY or
+// O_RDWR, and O_APPEND, O_CREATE, O_EXCL and O_TRUNC.
+func (fsys *FS) OpenFile(name string, flag int) (*File, error) {
+	f, err := fsys.openFile(name, flag)
+	return f, pathError("open", name, err)
+}
+
+func (fsys *FS) openFile(name string, flag int) (*File, error) {
+	dir, base, err := fsys.split(name)
+	if err != nil {
+		return nil, err
+	}
+	writable := flag&(os.O_WRONLY|os.O_RDWR) != 0
+	var e dirEntry
+	if base == "" {
+		e = fsys.rootEntry()
+	} else {
+		e, err = fsys.lookup(dir, base)
+	}
+	switch {
+	case err == errNotExist && flag&os.O_CREATE != 0:
+		e, err = fsys.create(dir, base, attrArchive, 0)
+		if err != nil {
+			return nil, err
+		}
+	case err != nil:
+		return nil, err
+	case flag&(os.O_CREATE|os.O_EXCL) == os.O_CREATE|os.O_EXCL:
+		return nil, errExist
+	case e.isDir() && writable:
+		return nil, errIsDir
+	case e.attr&attrReadOnly != 0 && writable:
+		return nil, fs.ErrPermission
+	}
+	f := &File{fsys: fsys, path: name, entry: e, flag: flag}
+	if flag&os.O_TRUNC != 0 && writable && e.first != 0 {
+		if err := fsys.free(e.first); err != nil {
+			return nil, err
+		}
+		f.entry.first, f.entry.size = 0, 0
+		f.touch()
+		if err := f.Sync(); err != nil {
+			return nil, err
+		}
+	}
+	return f, nil
+}
+
+// Stat returns the FileInfo of a file or directory.
+func (fsys *FS) Stat(name string) (fs.FileInfo, error) {
+	e, err := fsys.find(name)
+	if err != nil {
+		return nil, pathError("stat", name, err)
+	}
+	return e.info(), nil
+}
+
+// ReadDir returns the entries of a directory.
+func (fsys *FS) ReadDir(name string) ([]fs.FileInfo, error) {
+	f, err := fsys.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return f.ReadDir(-1)
+}
+
+// Mkdir creates a directory.
+func (fsys *FS) Mkdir(name string) error {
+	return pathError("mkdir", name, fsys.mkdir(name))
+}
+
+func (fsys *FS) mkdir(name string) error {
+	dir, base, err := fsys.split(name)
+	if err != nil {
+		return err
+	}
+	if base == "" {
+		return errExist
+	}
+	if _, err := fsys.lookup(dir, base); err != errNotExist {
+		if err == nil {
+			err = errExist
+		}
+		return err
+	}
+	c, err := fsys.alloc(0)
+	if err != nil {
+		return err
+	}
+	if err := fsys.zeroCluster(c); err != nil {
+		return err
+	}
+	e, err := fsys.create(dir, base, attrDir, c)
+	if err != nil {
+		fsys.free(c)
+		return err
+	}
+	// The . and .. entries, .. being 0 for the root directory.
+	parent := dir
+	if parent == fsys.root() {
+		parent = 0
+	}
+	for i, dot := range [2]string{".          ", "..         "} {
+		var b [entrySize]byte
+		copy(b[:], dot)
+		b[11] = attrDir
+		d := dirEntry{first: c, date: e.date, time: e.time}
+		if i == 1 {
+			d.first = parent
+		}
+		d.encode(b[:])
+		if err := fsys.writeEntry(c, uint32(i), b[:]); err != nil {
+			return err
+		}
+	}
+	return fsys.flushFAT()
+}
+
+// Remove removes a file or an empty directory.
+func (fsys *FS) Remove(name string) error {
+	return pathError("remove", name, fsys.removePath(name))
+}
+
+func (fsys *FS) removePath(name string) error {
+	e, err := fsys.find(name)
+	if err != nil {
+		return err
+	}
+	if e.root {
+		return errInvalid
+	}
+	if e.isDir() {
+		empty := true
+		err := fsys.scan(e.cluster(fsys), func(d *dirEntry) bool {
+			empty = d.name == "." || d.name == ".."
+			return !empty
+		})
+		if err != nil {
+			return err
+		}
+		if !empty {
+			return errNotEmpty
+		}
+	}
+	if err := fsys.remove(&e); err != nil {
+		return err
+	}
+	if err := fsys.free(e.first); err != nil {
+		return err
+	}
+	return fsys.flushFAT()
+}
+
+// Name returns the name of the file as given to Open.
+func (f *File) Name() string {
+	return f.path
+}
+
+// Stat returns the FileInfo of the file.
+func (f *File) Stat() (fs.FileInfo, error) {
+	if f.closed {
+		return nil, pathError("stat", f.path, errClosed)
+	}
+	return f.entry.info(), nil
+}
+
+// Size returns the size of the file.
+func (f *File) Size() int64 {
+	return int64(f.entry.size)
+}
+
+// Read reads from the current position.
+func (f *File) Read(p []byte) (int, error) {
+	n, err := f.ReadAt(p, f.pos)
+	f.pos += int64(n)
+	return n, err
+}
+
+// ReadAt implements io.ReaderAt.
+func (f *File) ReadAt(p []byte, off int64) (int, error) {
+	if err := f.check(false); err != nil {
+		return 0, pathError("read", f.path, err)
+	}
+	if off < 0 {
+		return 0, pathError("read", f.path, errInvalid)
+	}
+	if off >= int64(f.entry.size) {
+		return 0, io.EOF
+	}
+	n := 0
+	for n < len(p) && off < int64(f.entry.size) {
+		c, err := f.cluster(uint32(off/int64(f.fsys.clusterSize)), false)
+		if err != nil {
+			return n, pathError("read", f.path, err)
+		}
+		in := off % int64(f.fsys.clusterSize)
+		m := min64(min64(int64(len(p)-n), int64(f.fsys.clusterSize)-in), int64(f.entry.size)-off)
+		if _, err := f.fsys.dev.ReadAt(p[n:n+int(m)], f.fsys.clusterAddr(c)+in); err != nil {
+			return n, pathError("read", f.path, err)
+		}
+		n += int(m)
+		off += m
+	}
+	if n < len(p) {
+		return n, io.EOF
+	}
+	return n, nil
+}
+
+// Write writes at the current position, or at the end of the file with
+// O_APPEND.
+func (f *File) Write(p []byte) (int, error) {
+	if f.flag&os.O_APPEND != 0 {
+		f.pos = int64(f.entry.size)
+	}
+	n, err := f.WriteAt(p, f.pos)
+	f.pos += int64(n)
+	return n, err
+}
+
+// WriteAt implements io.WriterAt. Writing past the end of the file fills
+// the gap with zeros.
+func (f *File) WriteAt(p []byte, off int64) (int, error) {
+	n, err := f.writeAt(p, off)
+	return n, pathError("write", f.path, err)
+}
+
+func (f *File) writeAt(p []byte, off int64) (int, error) {
+	if err := f.check(true); err != nil {
+		return 0, err
+	}
+	if off < 0 {
+		return 0, errInvalid
+	}
+	if off+int64(len(p)) > maxFileSize {
+		return 0, errTooLarge
+	}
+	var zero [512]byte
+	for size := int64(f.entry.size); size < off; {
+		n, err := f.write(zero[:min64(512, off-size)], size)
+		if err != nil {
+			return 0, err
+		}
+		size += int64(n)
+	}
+	return f.write(p, off)
+}
+
+// write writes p at off, which is at most the size of the file.
+func (f *File) write(p []byte, off int64) (int, error) {
+	n := 0
+	for n < len(p) {
+		c, err := f.cluster(uint32(off/int64(f.fsys.clusterSize)), true)
+		if err != nil {
+			return n, err
+		}
+		in := off % int64(f.fsys.clusterSize)
+		m := min64(int64(len(p)-n), int64(f.fsys.clusterSize)-in)
+		if _, err := f.fsys.dev.WriteAt(p[n:n+int(m)], f.fsys.clusterAddr(c)+in); err != nil {
+			return n, err
+		}
+		n += int(m)
+		off += m
+		if off > int64(f.entry.size) {
+			f.entry.size = uint32(off)
+		}
+		f.touch()
+	}
+	return n, nil
+}
+
+// Seek implements io.Seeker.
+func (f *File) Seek(offset int64, whence int) (int64, error) {
+	if err := f.check(false); err != nil {
+		return 0, pathError("seek", f.path, err)
+	}
+	switch whence {
+	case io.SeekCurrent:
+		offset += f.pos
+	case io.SeekEnd:
+		offset += int64(f.entry.size)
+	}
+	if offset < 0 {
+		return 0, pathError("seek", f.path, errInvalid)
+	}
+	f.pos = offset
+	return offset, nil
+}
+
+// Truncate changes the size of the file. Growing it fills it with zeros.
+func (f *File) Truncate(size int64) error {
+	return pathError("truncate", f.path, f.truncate(size))
+}
+
+func (f *File) truncate(size int64) error {
+	if err := f.check(true); err != nil {
+		return err
+	}
+	if size < 0 || size > maxFileSize {
+		return errInvalid
+	}
+	if size >= int64(f.entry.size) {
+		_, err := f.writeAt(nil, size)
+		return err
+	}
+	if size == 0 {
+		if err := f.fsys.free(f.entry.first); err != nil {
+			return err
+		}
+		f.entry.first = 0
+	} else {
+		last, err := f.cluster(uint32((size-1)/int64(f.fsys.clusterSize)), false)
+		if err != nil {
+			return err
+		}
+		next, err := f.fsys.next(last)
+		if err != nil {
+			return err
+		}
+		if err := f.fsys.setNext(last, clusterEnd); err != nil {
+			return err
+		}
+		if err := f.fsys.free(next); err != nil {
+			return err
+		}
+	}
+	f.entry.size = uint32(size)
+	f.cur, f.curIdx = 0, 0
+	f.touch()
+	return nil
+}
+
+// Sync writes the size and modification time of the file to its directory
+// entry, and the changes to the device.
+func (f *File) Sync() error {
+	if f.closed {
+		return pathError("sync", f.path, errClosed)
+	}
+	if f.dirty && !f.entry.root {
+		if err := f.fsys.update(&f.entry); err != nil {
+			return pathError("sync", f.path, err)
+		}
+		f.dirty = false
+	}
+	return pathError("sync", f.path, f.fsys.Sync())
+}
+
+// Close syncs the file and closes it.
+func (f *File) Close() error {
+	if f.closed {
+		return pathError("close", f.path, errClosed)
+	}
+	err := f.Sync()
+	f.closed = true
+	return err
+}
+
+// ReadDir returns the next n entries of the directory, or all the entries
+// left when n <= 0, as os.File.ReadDir does. The . and .. entries are left
+// out.
+func (f *File) ReadDir(n int) ([]fs.FileInfo, error) {
+	if f.closed {
+		return nil, pathError("readdir", f.path, errClosed)
+	}
+	if !f.entry.isDir() {
+		return nil, pathError("readdir", f.path, errNotDir)
+	}
+	if f.list == nil {
+		f.list = []fs.FileInfo{}
+		err := f.fsys.scan(f.entry.cluster(f.fsys), func(e *dirEntry) bool {
+			if e.name != "." && e.name != ".." {
+				f.list = append(f.list, e.info())
+			}
+			return false
+		})
+		if err != nil {
+			return nil, pathError("readdir", f.path, err)
+		}
+	}
+	list := f.list[f.pos:]
+	if n > 0 {
+		if len(list) == 0 {
+			return nil, io.EOF
+		}
+		if n < len(list) {
+			list = list[:n]
+		}
+	}
+	f.pos += int64(len(list))
+	return list, nil
+}
+
+func (f *File) check(write bool) error {
+	switch {
+	case f.closed:
+		return errClosed
+	case f.entry.isDir():
+		return errIsDir
+	case write && f.flag&(os.O_WRONLY|os.O_RDWR) == 0:
+		return fs.ErrPermission
+	case !write && f.flag&os.O_WRONLY != 0:
+		return fs.ErrPermission
+	}
+	return nil
+}
+
+// touch records a change of the file.
+func (f *File) touch() {
+	f.entry.date, f.entry.time = fatTime(time.Now())
+	f.entry.attr |= attrArchive
+	f.dirty = true
+}
+
+// cluster returns the cluster at an index of the chain of the file, and
+// allocates it with alloc when the chain is too short.
+func (f *File) cluster(idx uint32, alloc bool) (uint32, error) {
+	fsys := f.fsys
+	if f.entry.first == 0 {
+		if !alloc {
+			return 0, errCorrupt
+		}
+		c, err := fsys.alloc(0)
+		if err != nil {
+			return 0, err
+		}
+		f.entry.first = c
+		f.touch()
+	}
+	if f.cur == 0 || idx < f.curIdx {
+		f.cur, f.curIdx = f.entry.first, 0
+	}
+	for f.curIdx < idx {
+		next, err := fsys.next(f.cur)
+		if err != nil {
+			return 0, err
+		}
+		if next >= clusterBad {
+			if !alloc {
+				return 0, errCorrupt
+			}
+			if next, err = fsys.alloc(f.cur); err != nil {
+				return 0, err
+			}
+		} else if next < 2 {
+			return 0, errCorrupt
+		}
+		f.cur = next
+		f.curIdx++
+	}
+	return f.cur, nil
+}
+
+func min64(a, b int64) int64 {
+	if b < a {
+		return b
+	}
+	return a
+}
+
+// fileInfo implements fs.FileInfo.
+type fileInfo struct {
+	name    string
+	size    int64
+	mode    fs.FileMode
+	modTime time.Time
+}
+
+func (e *dirEntry) info() fs.FileInfo {
+	fi := &fileInfo{name: e.name, size: int64(e.size), mode: 0666, modTime: e.modTime()}
+	if e.root {
+		fi.name = "/"
+	}
+	if e.attr&attrReadOnly != 0 {
+		fi.mode = 0444
+	}
+	if e.isDir() {
+		fi.mode |= fs.ModeDir | 0111
+		fi.size = 0
+	}
+	return fi
+}
+
+func (fi *fileInfo) Name() string       { return fi.name }
+func (fi *fileInfo) Size() int64        { return fi.size }
+func (fi *fileInfo) Mode() fs.FileMode  { return fi.mode }
+func (fi *fileInfo) ModTime() time.Time { return fi.modTime }
+func (fi *fileInfo) IsDir() bool        { return fi.mode.IsDir() }
+func (fi *fileInfo) Sys() interface{}   { return nil }
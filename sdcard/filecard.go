@@ -0,0 +1,66 @@
+//go:build !tinygo
+
+package sdcard
+
+import (
+	"io"
+	"os"
+)
+
+// fileBlockStore holds the blocks of a MemoryCard in an image file.
+type fileBlockStore struct {
+	f *os.File
+}
+
+// NewFileCard returns a MemoryCard whose blocks are kept in the image file
+// f, to test code on the real images of a card, such as made by mkfs.fat,
+// and to look at the images on a PC afterwards. The card has the given size,
+// rounded up as by NewMemoryCard, or the size of the image when 0.
+//
+// The file grows as blocks past its end are written, and the blocks past
+// its end read as zeros. Erasing the blocks at its end truncates it.
+func NewFileCard(f *os.File, size int64) (*MemoryCard, error) {
+	if size == 0 {
+		fi, err := f.Stat()
+		if err != nil {
+			return nil, err
+		}
+		size = fi.Size()
+	}
+	return newMemoryCard(size, fileBlockStore{f}), nil
+}
+
+func (s fileBlockStore) readBlocks(start int64, dst []byte) error {
+	n, err := s.f.ReadAt(dst, start*512)
+	if err == io.EOF {
+		zero(dst[n:])
+		err = nil
+	}
+	return err
+}
+
+func (s fileBlockStore) writeBlocks(start int64, src []byte) error {
+	_, err := s.f.WriteAt(src, start*512)
+	return err
+}
+
+func (s fileBlockStore) erase(start, end int64) error {
+	fi, err := s.f.Stat()
+	if err != nil {
+		return err
+	}
+	size := fi.Size()
+	if (end+1)*512 >= size {
+		if start*512 < size {
+			return s.f.Truncate(start * 512)
+		}
+		return nil
+	}
+	var zeros [512]byte
+	for n := start; n <= end; n++ {
+		if _, err := s.f.WriteAt(zeros[:], n*512); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,58 @@
+//go:build !tinygo
+
+package sdcard
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestFileCard(t *testing.T) {
+	f, err := os.Create(filepath.Join(t.TempDir(), "card.img"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	c, err := NewFileCard(f, 1<<20)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.Size() != 1<<20 {
+		t.Errorf("size %d", c.Size())
+	}
+
+	// The file grows as blocks are written.
+	data := bytes.Repeat([]byte{0xA5}, 3*512)
+	if err := c.WriteBlocks(10, data); err != nil {
+		t.Fatal(err)
+	}
+	if fi, _ := f.Stat(); fi.Size() != 13*512 {
+		t.Errorf("image of %d bytes", fi.Size())
+	}
+	buf := make([]byte, 8*512)
+	if err := c.ReadBlocks(8, buf); err != nil {
+		t.Fatal(err)
+	}
+	if buf[2*512-1] != 0 || !bytes.Equal(buf[2*512:5*512], data) || buf[5*512] != 0 {
+		t.Errorf("read back % X", buf)
+	}
+
+	// Erasing a block in the middle zeroes it, at the end truncates.
+	c.EraseBlocks(11, 1)
+	c.ReadBlocks(10, buf[:3*512])
+	if buf[511] != 0xA5 || buf[512] != 0 || buf[1023] != 0 || buf[1024] != 0xA5 {
+		t.Errorf("after erase % X", buf[:3*512])
+	}
+	c.EraseBlocks(12, 100)
+	if fi, _ := f.Stat(); fi.Size() != 12*512 {
+		t.Errorf("image of %d bytes after erase", fi.Size())
+	}
+
+	// A card of the size of an image, rounded up.
+	c, err = NewFileCard(f, 0)
+	if err != nil || c.Size() != 512*1024 {
+		t.Errorf("size of the image: %d, %v", c.Size(), err)
+	}
+}
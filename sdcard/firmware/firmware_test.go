@@ -0,0 +1,173 @@
+package firmware
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+)
+
+// mem is a card in memory.
+type mem []byte
+
+func (m mem) ReadAt(buf []byte, off int64) (int, error) {
+	return copy(buf, m[off:]), nil
+}
+
+func (m mem) WriteAt(buf []byte, off int64) (int, error) {
+	return copy(m[off:], buf), nil
+}
+
+// flash records the firmware written to the slots.
+type flash [2][]byte
+
+func (f *flash) write(slot Slot, off uint32, chunk []byte) error {
+	if int(off) != len(f[slot]) {
+		return errors.New("write out of order")
+	}
+	f[slot] = append(f[slot], chunk...)
+	return nil
+}
+
+func firmwareData(n int) []byte {
+	data := make([]byte, n)
+	for i := range data {
+		data[i] = byte(i * 7)
+	}
+	return data
+}
+
+func TestVerify(t *testing.T) {
+	data := firmwareData(1300)
+	for _, sha := range []bool{false, true} {
+		img := MakeImage(42, data, sha)
+		h, err := Verify(bytes.NewReader(img))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if h.Version != 42 || h.Length != 1300 || h.HasSHA256 != sha {
+			t.Errorf("header %+v", h)
+		}
+
+		// A bit flipped in the firmware.
+		img[HeaderSize+1000] ^= 0x10
+		if _, err := Verify(bytes.NewReader(img)); err != ErrCorrupt {
+			t.Errorf("corrupt firmware: %v", err)
+		}
+		img[HeaderSize+1000] ^= 0x10
+
+		// A truncated image.
+		if _, err := Verify(bytes.NewReader(img[:len(img)-1])); err != ErrCorrupt {
+			t.Errorf("truncated image: %v", err)
+		}
+
+		// A header that doesn't match its CRC.
+		img[12]++
+		if _, err := Verify(bytes.NewReader(img)); err != ErrCorrupt {
+			t.Errorf("corrupt header: %v", err)
+		}
+	}
+
+	// With a good CRC-32, the SHA-256 still catches a changed firmware.
+	img := MakeImage(1, data, true)
+	h, _ := ReadHeader(bytes.NewReader(img))
+	h.SHA256[0] ^= 1
+	h.put(img)
+	if _, err := Verify(bytes.NewReader(img)); err != ErrCorrupt {
+		t.Errorf("SHA-256 mismatch: %v", err)
+	}
+
+	if _, err := Verify(bytes.NewReader(make([]byte, 1024))); err != ErrNoImage {
+		t.Errorf("blank card: %v", err)
+	}
+	if _, err := Verify(bytes.NewReader([]byte("TGFW"))); err != ErrNoImage {
+		t.Errorf("short file: %v", err)
+	}
+}
+
+func TestUpdate(t *testing.T) {
+	card := make(mem, 8*512)
+	var fl flash
+	u := New(card, 2*512)
+
+	// A card without a state runs slot A, of unknown version.
+	if slot, err := u.Boot(3); slot != SlotA || err != nil {
+		t.Fatalf("Boot: %s, %v", slot, err)
+	}
+
+	v1 := firmwareData(2000)
+	h, err := u.Install(bytes.NewReader(MakeImage(1, v1, false)), fl.write)
+	if err != nil || h.Version != 1 {
+		t.Fatalf("Install: %v", err)
+	}
+	if !bytes.Equal(fl[SlotB], v1) || fl[SlotA] != nil {
+		t.Fatalf("flash A %d bytes, B %d bytes", len(fl[SlotA]), len(fl[SlotB]))
+	}
+	if st, _ := u.State(); st != (State{Active: SlotA, Pending: true, Versions: [2]uint32{0, 1}}) {
+		t.Errorf("state after install %+v", st)
+	}
+
+	// The new firmware is tried, and confirmed. The state is read again
+	// from the card.
+	u = New(card, 2*512)
+	if slot, _ := u.Boot(3); slot != SlotB {
+		t.Errorf("booted %s", slot)
+	}
+	if _, err := u.Install(bytes.NewReader(MakeImage(2, v1, false)), fl.write); err != ErrUnconfirmed {
+		t.Errorf("install while trying: %v", err)
+	}
+	u.Confirm()
+	u = New(card, 2*512)
+	if slot, _ := u.Boot(3); slot != SlotB {
+		t.Errorf("booted %s after confirm", slot)
+	}
+	if _, err := u.Install(bytes.NewReader(MakeImage(1, v1, false)), fl.write); err != ErrNotNewer {
+		t.Errorf("same version: %v", err)
+	}
+
+	// A firmware that fails is tried 3 times, then dropped.
+	v2 := firmwareData(100)
+	if _, err := u.Install(bytes.NewReader(MakeImage(2, v2, true)), fl.write); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(fl[SlotA], v2) {
+		t.Errorf("flash A holds %d bytes", len(fl[SlotA]))
+	}
+	for i := 0; i < 3; i++ {
+		u = New(card, 2*512)
+		if slot, _ := u.Boot(3); slot != SlotA {
+			t.Errorf("try %d booted %s", i, slot)
+		}
+	}
+	u = New(card, 2*512)
+	if slot, _ := u.Boot(3); slot != SlotB {
+		t.Errorf("booted %s after 3 tries", slot)
+	}
+	if st, _ := u.State(); st != (State{Active: SlotB, Versions: [2]uint32{2, 1}}) {
+		t.Errorf("state after rollback %+v", st)
+	}
+
+	// A corrupt image doesn't touch the flash.
+	fl = flash{}
+	img := MakeImage(3, v2, false)
+	img[HeaderSize] ^= 1
+	if _, err := u.Install(bytes.NewReader(img), fl.write); err != ErrCorrupt || fl[SlotA] != nil {
+		t.Errorf("corrupt image: %v, wrote %d bytes", err, len(fl[SlotA]))
+	}
+
+	// The state written last is torn by a power loss: the previous one is
+	// used.
+	u.SetAllowDowngrade(true)
+	if _, err := u.Install(bytes.NewReader(MakeImage(1, v2, false)), fl.write); err != nil {
+		t.Fatal(err)
+	}
+	seq := u.seq
+	card[2*512+int(seq%2)*512+16] ^= 0xFF
+	u = New(card, 2*512)
+	if st, _ := u.State(); st.Pending || st.Versions[SlotA] != 0 || u.seq != seq-1 {
+		t.Errorf("state after torn write %+v", st)
+	}
+
+	if _, err := New(card, 100).State(); err != errStateOffset {
+		t.Errorf("unaligned state: %v", err)
+	}
+}
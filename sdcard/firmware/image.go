@@ -0,0 +1,185 @@
+// Package firmware updates the firmware of a device from an image on an SD
+// card, such as a file of a FAT file system or a range of blocks of the card.
+//
+// An image is a header block followed by the firmware. The header holds a
+// magic number, the version and length of the firmware, and its CRC-32 and
+// optionally its SHA-256. MakeImage makes one, for instance in the tool
+// that builds the firmware.
+//
+// The device has two slots for the firmware, A and B, in its flash. An
+// Updater writes a new firmware to the slot that is not running, through a
+// function provided by the application, and tracks the slots in a block
+// reserved on the card. The new firmware is tried at the next boot, and is
+// kept once it confirmed that it works; otherwise the device goes back to
+// the previous one:
+//
+//	// In the bootloader.
+//	slot, err := u.Boot(3)
+//	jumpTo(slot)
+//
+//	// In the application, once it runs fine.
+//	u.Confirm()
+//
+//	// To update.
+//	f, err := fsys.Open("firmware.img")
+//	...
+//	_, err = u.Install(f, writeFlash)
+package firmware // import "tinygo.org/x/drivers/sdcard/firmware"
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"hash"
+	"hash/crc32"
+	"io"
+)
+
+// HeaderSize is the size of the header of an image. The firmware follows
+// it, aligned on a block of the card.
+const HeaderSize = 512
+
+const (
+	imageMagic = "TGFW"
+
+	flagSHA256 = 1 << 0
+
+	headerLen = 64 // used bytes of the header block
+)
+
+var (
+	// ErrNoImage is returned when the data is not a firmware image.
+	ErrNoImage = errors.New("firmware: no firmware image")
+
+	// ErrCorrupt is returned when the header or the firmware of an image
+	// don't match their checksums, or the image is shorter than its header
+	// tells.
+	ErrCorrupt = errors.New("firmware: corrupt image")
+)
+
+// Header is the header of an image.
+type Header struct {
+	Version uint32 // higher for newer firmware
+	Length  uint32 // of the firmware, in bytes
+	CRC32   uint32 // IEEE CRC-32 of the firmware
+
+	// SHA256 is the SHA-256 of the firmware, checked along with the CRC-32
+	// when HasSHA256 is set.
+	SHA256    [32]byte
+	HasSHA256 bool
+}
+
+// MakeImage returns the image of firmware data with a version, whose
+// SHA-256 is checked when withSHA256 is set.
+func MakeImage(version uint32, data []byte, withSHA256 bool) []byte {
+	h := Header{
+		Version:   version,
+		Length:    uint32(len(data)),
+		CRC32:     crc32.ChecksumIEEE(data),
+		HasSHA256: withSHA256,
+	}
+	if withSHA256 {
+		h.SHA256 = sha256.Sum256(data)
+	}
+	img := make([]byte, HeaderSize+len(data))
+	h.put(img)
+	copy(img[HeaderSize:], data)
+	return img
+}
+
+// put encodes h in buf.
+func (h *Header) put(buf []byte) {
+	le := binary.LittleEndian
+	copy(buf[0:4], imageMagic)
+	var flags uint32
+	if h.HasSHA256 {
+		flags |= flagSHA256
+	}
+	le.PutUint32(buf[4:], flags)
+	le.PutUint32(buf[8:], h.Version)
+	le.PutUint32(buf[12:], h.Length)
+	le.PutUint32(buf[16:], h.CRC32)
+	copy(buf[20:52], h.SHA256[:])
+	le.PutUint32(buf[headerLen-4:], crc32.ChecksumIEEE(buf[:headerLen-4]))
+}
+
+// ReadHeader reads the header of the image in r, without checking the
+// firmware.
+func ReadHeader(r io.ReaderAt) (Header, error) {
+	var buf [headerLen]byte
+	if _, err := r.ReadAt(buf[:], 0); err != nil {
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
+			return Header{}, ErrNoImage
+		}
+		return Header{}, err
+	}
+	if string(buf[0:4]) != imageMagic {
+		return Header{}, ErrNoImage
+	}
+	le := binary.LittleEndian
+	if le.Uint32(buf[headerLen-4:]) != crc32.ChecksumIEEE(buf[:headerLen-4]) {
+		return Header{}, ErrCorrupt
+	}
+	h := Header{
+		Version:   le.Uint32(buf[8:]),
+		Length:    le.Uint32(buf[12:]),
+		CRC32:     le.Uint32(buf[16:]),
+		HasSHA256: le.Uint32(buf[4:])&flagSHA256 != 0,
+	}
+	copy(h.SHA256[:], buf[20:52])
+	return h, nil
+}
+
+// Verify reads the image in r, and checks its header and firmware. It
+// returns the header of a valid image.
+func Verify(r io.ReaderAt) (Header, error) {
+	h, err := ReadHeader(r)
+	if err != nil {
+		return h, err
+	}
+	return h, h.read(r, make([]byte, 512), nil)
+}
+
+// read reads the firmware of the image in r in chunks of the size of buf,
+// calls fn with each chunk, and checks the checksums once all was read. fn
+// may be nil.
+func (h *Header) read(r io.ReaderAt, buf []byte, fn func(off uint32, chunk []byte) error) error {
+	crc := crc32.NewIEEE()
+	var sum hash.Hash
+	if h.HasSHA256 {
+		sum = sha256.New()
+	}
+	for off := uint32(0); off < h.Length; {
+		chunk := buf
+		if left := h.Length - off; left < uint32(len(chunk)) {
+			chunk = chunk[:left]
+		}
+		n, err := r.ReadAt(chunk, HeaderSize+int64(off))
+		if n < len(chunk) {
+			if err == nil || err == io.EOF {
+				err = ErrCorrupt
+			}
+			return err
+		}
+		crc.Write(chunk)
+		if sum != nil {
+			sum.Write(chunk)
+		}
+		if fn != nil {
+			if err := fn(off, chunk); err != nil {
+				return err
+			}
+		}
+		off += uint32(len(chunk))
+	}
+	if crc.Sum32() != h.CRC32 {
+		return ErrCorrupt
+	}
+	if sum != nil {
+		var s [32]byte
+		if string(sum.Sum(s[:0])) != string(h.SHA256[:]) {
+			return ErrCorrupt
+		}
+	}
+	return nil
+}
@@ -0,0 +1,248 @@
+package firmware
+
+import (
+	"encoding/binary"
+	"errors"
+	"hash/crc32"
+	"io"
+)
+
+// StateSize is the size of the area of the card reserved for the state of
+// the slots: two blocks, written in turn so that a power loss while one is
+// written leaves the other.
+const StateSize = 2 * 512
+
+const stateMagic = "TGUS"
+
+var (
+	// ErrNotNewer is returned by Install when the image is not newer than
+	// the active firmware, see SetAllowDowngrade.
+	ErrNotNewer = errors.New("firmware: image is not newer than the active firmware")
+
+	// ErrUnconfirmed is returned by Install while the pending firmware is
+	// tried, as it would be overwritten: it must be confirmed first.
+	ErrUnconfirmed = errors.New("firmware: pending firmware not confirmed")
+
+	errStateOffset = errors.New("firmware: state offset is not a multiple of 512")
+)
+
+// Slot is a firmware slot of the flash of the device.
+type Slot uint8
+
+const (
+	SlotA Slot = iota
+	SlotB
+)
+
+// Other returns the other slot.
+func (s Slot) Other() Slot {
+	return s ^ 1
+}
+
+func (s Slot) String() string {
+	if s == SlotA {
+		return "A"
+	}
+	return "B"
+}
+
+// State is the state of the slots.
+type State struct {
+	// Active is the slot of the firmware known to work.
+	Active Slot
+
+	// Pending is set when the other slot holds a new firmware, to try at the
+	// next boots until it is confirmed.
+	Pending bool
+
+	// Tries counts the boots of the pending firmware.
+	Tries uint8
+
+	// Versions holds the versions of the firmware of the slots, 0 when
+	// unknown.
+	Versions [2]uint32
+}
+
+// Storage is the card, partition or file holding the state of the slots.
+type Storage interface {
+	io.ReaderAt
+	io.WriterAt
+}
+
+// Updater installs firmware images and tracks the slots.
+type Updater struct {
+	dev       Storage
+	off       int64
+	loaded    bool
+	seq       uint32 // sequence number of the last state written
+	state     State
+	downgrade bool
+	buf       [512]byte
+}
+
+// New returns an Updater keeping the state of the slots in the StateSize
+// bytes of dev at offset off, a multiple of 512, which must be reserved for
+// it. A card without a state runs slot A.
+//
+// This function only creates the Updater object, it does not touch the
+// device.
+func New(dev Storage, off int64) *Updater {
+	return &Updater{dev: dev, off: off}
+}
+
+// SetAllowDowngrade sets whether Install accepts images that are not newer
+// than the active firmware.
+func (u *Updater) SetAllowDowngrade(allow bool) {
+	u.downgrade = allow
+}
+
+// State returns the state of the slots.
+func (u *Updater) State() (State, error) {
+	err := u.load()
+	return u.state, err
+}
+
+// Boot returns the slot to boot, for the bootloader. A pending firmware is
+// tried maxTries times; when it wasn't confirmed by then, it is dropped and
+// the active slot is booted again.
+func (u *Updater) Boot(maxTries int) (Slot, error) {
+	if err := u.load(); err != nil {
+		return SlotA, err
+	}
+	if !u.state.Pending {
+		return u.state.Active, nil
+	}
+	if int(u.state.Tries) >= maxTries {
+		u.state.Pending = false
+		u.state.Tries = 0
+		return u.state.Active, u.save()
+	}
+	u.state.Tries++
+	return u.state.Active.Other(), u.save()
+}
+
+// Confirm makes the pending firmware the active one, for the application to
+// call once it found that it works. It does nothing when there is no
+// pending firmware.
+func (u *Updater) Confirm() error {
+	if err := u.load(); err != nil {
+		return err
+	}
+	if !u.state.Pending {
+		return nil
+	}
+	u.state.Active = u.state.Active.Other()
+	u.state.Pending = false
+	u.state.Tries = 0
+	return u.save()
+}
+
+// Install verifies the image in r, and writes its firmware to the slot that
+// is not active with write, in chunks of 512 bytes (the last one may be
+// shorter) at increasing offsets. write erases the flash as needed. Once the
+// firmware was written and checked again, it is pending, to be tried at the
+// next boot.
+//
+// It returns ErrNotNewer when the version of the image is not higher than
+// the one of the active firmware, ErrUnconfirmed while the pending firmware
+// is tried, ErrNoImage or ErrCorrupt when the image is not valid, and the
+// errors of write.
+func (u *Updater) Install(r io.ReaderAt, write func(slot Slot, off uint32, chunk []byte) error) (Header, error) {
+	if err := u.load(); err != nil {
+		return Header{}, err
+	}
+	h, err := ReadHeader(r)
+	if err != nil {
+		return h, err
+	}
+	if u.state.Pending && u.state.Tries > 0 {
+		return h, ErrUnconfirmed
+	}
+	if !u.downgrade && h.Version <= u.state.Versions[u.state.Active] {
+		return h, ErrNotNewer
+	}
+	// Check the whole image before touching the flash.
+	if err := h.read(r, u.buf[:], nil); err != nil {
+		return h, err
+	}
+
+	// The slot is overwritten, so it can't be booted until it is complete.
+	// This drops a pending firmware that was not booted yet.
+	slot := u.state.Active.Other()
+	if u.state.Pending || u.state.Versions[slot] != 0 {
+		u.state.Pending = false
+		u.state.Tries = 0
+		u.state.Versions[slot] = 0
+		if err := u.save(); err != nil {
+			return h, err
+		}
+	}
+	err = h.read(r, u.buf[:], func(off uint32, chunk []byte) error {
+		return write(slot, off, chunk)
+	})
+	if err != nil {
+		return h, err
+	}
+	u.state.Pending = true
+	u.state.Versions[slot] = h.Version
+	return h, u.save()
+}
+
+// load reads the state of the slots, if not done yet.
+func (u *Updater) load() error {
+	if u.loaded {
+		return nil
+	}
+	if u.off%512 != 0 {
+		return errStateOffset
+	}
+	var found bool
+	for i := int64(0); i < 2; i++ {
+		if _, err := u.dev.ReadAt(u.buf[:], u.off+i*512); err != nil {
+			return err
+		}
+		seq, st, ok := decodeState(u.buf[:])
+		if ok && (!found || int32(seq-u.seq) > 0) {
+			u.seq, u.state, found = seq, st, true
+		}
+	}
+	u.loaded = true
+	return nil
+}
+
+// save writes the state of the slots over the oldest of the two copies.
+func (u *Updater) save() error {
+	u.seq++
+	u.buf = [512]byte{}
+	encodeState(u.buf[:], u.seq, &u.state)
+	_, err := u.dev.WriteAt(u.buf[:], u.off+int64(u.seq%2)*512)
+	return err
+}
+
+func encodeState(buf []byte, seq uint32, st *State) {
+	le := binary.LittleEndian
+	copy(buf[0:4], stateMagic)
+	le.PutUint32(buf[4:], seq)
+	buf[8] = byte(st.Active)
+	if st.Pending {
+		buf[9] = 1
+	}
+	buf[10] = st.Tries
+	le.PutUint32(buf[12:], st.Versions[0])
+	le.PutUint32(buf[16:], st.Versions[1])
+	le.PutUint32(buf[20:], crc32.ChecksumIEEE(buf[:20]))
+}
+
+func decodeState(buf []byte) (seq uint32, st State, ok bool) {
+	le := binary.LittleEndian
+	if string(buf[0:4]) != stateMagic || le.Uint32(buf[20:]) != crc32.ChecksumIEEE(buf[:20]) {
+		return 0, st, false
+	}
+	st = State{
+		Active:   Slot(buf[8] & 1),
+		Pending:  buf[9] != 0,
+		Tries:    buf[10],
+		Versions: [2]uint32{le.Uint32(buf[12:]), le.Uint32(buf[16:])},
+	}
+	return le.Uint32(buf[4:]), st, true
+}
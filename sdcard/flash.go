@@ -0,0 +1,131 @@
+package sdcard
+
+import "errors"
+
+var (
+	errEraseRange    = errors.New("sdcard: erase beyond the end of the card")
+	errNotConfigured = errors.New("sdcard: card not configured")
+)
+
+// FlashDevice is the block device wrapped by a FlashCard: a *Device,
+// *SDIOCard, *MemoryCard or *CachedCard, erased in blocks of 512 bytes.
+type FlashDevice interface {
+	ReadAt(buf []byte, addr int64) (int, error)
+	WriteAt(buf []byte, addr int64) (int, error)
+	Size() int64
+	EraseBlocks(start, len int64) error
+	Sync() error
+}
+
+// FlashCard is a card with the erase geometry of a flash chip, for flash file
+// systems such as littlefs: EraseBlockSize is the erase sector of the card
+// rather than a single 512 byte block, so that the file system erases whole
+// sectors, aligned on sectors. The size is rounded down to whole erase
+// blocks.
+//
+// Erased blocks read as all 0x00 or all 0xFF depending on the card; littlefs
+// does not rely on either.
+type FlashCard struct {
+	dev    FlashDevice
+	blocks int64 // 512 byte blocks per erase block
+	size   int64
+}
+
+// NewFlashCard returns the card with erase blocks of the given size, a
+// multiple of 512 bytes; 0 means 64KiB, the erase sector of high capacity
+// cards. Use DefaultFlashEraseSize for the size that suits the card.
+func NewFlashCard(card FlashDevice, eraseBlockSize int64) *FlashCard {
+	blocks := eraseBlockSize / 512
+	if blocks < 1 {
+		blocks = 128
+	}
+	return &FlashCard{
+		dev:    card,
+		blocks: blocks,
+		size:   card.Size() / (blocks * 512) * (blocks * 512),
+	}
+}
+
+// FlashCard returns the card with the erase block size of
+// DefaultFlashEraseSize, reading the SD Status register for the allocation
+// unit. MMC cards, which have no SD Status, use their erase group.
+func (d *Device) FlashCard() (*FlashCard, error) {
+	if d.CSD == nil {
+		return nil, errNotConfigured
+	}
+	var status *SDStatus
+	if !d.CSD.MMC {
+		s, err := d.ReadSDStatus()
+		if err != nil {
+			return nil, err
+		}
+		status = &s
+	}
+	return NewFlashCard(d, DefaultFlashEraseSize(d.CSD, status)), nil
+}
+
+// DefaultFlashEraseSize returns the erase block size in bytes for a
+// FlashCard on a card with the given CSD and SD Status, which may be nil.
+//
+// It is the erase sector of the CSD, made smaller if needed to divide the
+// allocation unit of the SD Status, so that no erase block spans two
+// allocation units: cards manage their flash by allocation unit, and writes
+// within one are the fastest.
+func DefaultFlashEraseSize(csd *CSD, status *SDStatus) int64 {
+	size := int64(csd.EraseSectorSizeInSectors()) * 512
+	if status != nil {
+		if au := int64(status.AUSize()); au != 0 {
+			size = gcd(size, au)
+		}
+	}
+	return size
+}
+
+func gcd(a, b int64) int64 {
+	for b != 0 {
+		a, b = b, a%b
+	}
+	return a
+}
+
+// ReadAt reads len(buf) bytes at addr.
+func (f *FlashCard) ReadAt(buf []byte, addr int64) (int, error) {
+	return f.dev.ReadAt(buf, addr)
+}
+
+// WriteAt writes len(buf) bytes at addr, which must have been erased.
+func (f *FlashCard) WriteAt(buf []byte, addr int64) (int, error) {
+	return f.dev.WriteAt(buf, addr)
+}
+
+// Size returns the size of the card in bytes, rounded down to whole erase
+// blocks.
+func (f *FlashCard) Size() int64 {
+	return f.size
+}
+
+// WriteBlockSize returns the block size in which data can be written to
+// memory.
+func (f *FlashCard) WriteBlockSize() int64 {
+	return 512
+}
+
+// EraseBlockSize returns the size of the erase blocks in bytes.
+func (f *FlashCard) EraseBlockSize() int64 {
+	return f.blocks * 512
+}
+
+// EraseBlocks erases len erase blocks from start, in erase blocks, with a
+// single erase command.
+func (f *FlashCard) EraseBlocks(start, len int64) error {
+	if start < 0 || (start+len)*f.EraseBlockSize() > f.size {
+		return errEraseRange
+	}
+	return f.dev.EraseBlocks(start*f.blocks, len*f.blocks)
+}
+
+// Sync writes the data held in a cache of the card, such as a CachedCard,
+// to the card.
+func (f *FlashCard) Sync() error {
+	return f.dev.Sync()
+}
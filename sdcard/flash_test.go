@@ -0,0 +1,62 @@
+package sdcard
+
+import (
+	"bytes"
+	"testing"
+)
+
+var (
+	_ blockDevice = (*FlashCard)(nil)
+	_ FlashDevice = (*Device)(nil)
+	_ FlashDevice = (*SDIOCard)(nil)
+	_ FlashDevice = (*CachedCard)(nil)
+)
+
+func TestDefaultFlashEraseSize(t *testing.T) {
+	var au4M, au16K SDStatus
+	au4M[10] = 0x90
+	au16K[10] = 0x10
+	for _, tc := range []struct {
+		name   string
+		csd    CSD
+		status *SDStatus
+		want   int64
+	}{
+		{"SDHC", CSD{CSD_STRUCTURE: 1, SECTOR_SIZE: 0x7F, WRITE_BL_LEN: 9}, &au4M, 64 << 10},
+		{"SDHC without status", CSD{CSD_STRUCTURE: 1, SECTOR_SIZE: 0x7F, WRITE_BL_LEN: 9}, nil, 64 << 10},
+		// Sectors of 96 blocks of 1KiB, in allocation units of 16KiB.
+		{"SD v1", CSD{SECTOR_SIZE: 95, WRITE_BL_LEN: 10}, &au16K, 16 << 10},
+		// Erase groups of 32*16 blocks.
+		{"MMC", CSD{MMC: true, ERASE_BLK_EN: 1, SECTOR_SIZE: 0x7B, WP_GRP_SIZE: 0x60, WRITE_BL_LEN: 9}, nil, 256 << 10},
+	} {
+		if got := DefaultFlashEraseSize(&tc.csd, tc.status); got != tc.want {
+			t.Errorf("%s: got %d, want %d", tc.name, got, tc.want)
+		}
+	}
+}
+
+func TestFlashCard(t *testing.T) {
+	card := &sizedBlocks{memBlocks: memBlocks{data: bytes.Repeat([]byte{0xA5}, 10*512)}}
+	f := NewFlashCard(NewCachedCard(card, 2), 4*512)
+	if f.Size() != 8*512 || f.EraseBlockSize() != 4*512 {
+		t.Fatalf("size %d, erase blocks of %d", f.Size(), f.EraseBlockSize())
+	}
+
+	if err := f.EraseBlocks(1, 1); err != nil {
+		t.Fatal(err)
+	}
+	for i, b := range card.data {
+		if erased := i >= 4*512 && i < 8*512; erased != (b == 0) {
+			t.Fatalf("byte %d is %02X after erasing the second erase block", i, b)
+		}
+	}
+	if err := f.EraseBlocks(1, 2); err != errEraseRange {
+		t.Errorf("erase past the end: %v", err)
+	}
+
+	// Sync writes the blocks cached under the FlashCard.
+	f.WriteAt([]byte("littlefs"), 512)
+	if err := f.Sync(); err != nil || card.syncs != 1 || string(card.data[512:520]) != "littlefs" {
+		t.Errorf("Sync: %v, %d syncs, %q", err, card.syncs, card.data[512:520])
+	}
+}
@@ -0,0 +1,226 @@
+package sdcard
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"tinygo.org/x/drivers"
+)
+
+// The password protection of CMD42 locks a card: a locked card answers the
+// initialization and status commands, but rejects every read, write and
+// erase until it is unlocked with its password, or force erased. The
+// password is stored by the card, so it follows the card to other devices.
+//
+// CMD42 sends a data block, whose length is set with CMD16, holding a mode
+// byte, the length of the password and the password. It is restored to 512
+// bytes afterwards.
+
+var (
+	// ErrLockFailed is returned when the card rejects a lock or unlock
+	// command, usually because of a wrong password.
+	ErrLockFailed = errors.New("sdcard: lock/unlock failed")
+
+	errPassword = errors.New("sdcard: password longer than 16 bytes")
+)
+
+// Mode bits of the CMD42 data block.
+const (
+	lockSetPwd = 1 << 0
+	lockClrPwd = 1 << 1
+	lockLock   = 1 << 2
+	lockErase  = 1 << 3
+)
+
+const (
+	maxPassword = 16
+
+	// lockTimeout is the busy time of CMD42 except for a force erase, which
+	// takes up to 3 minutes.
+	lockTimeout  = 600 * time.Millisecond
+	forceTimeout = 3 * time.Minute
+)
+
+// lockData writes the CMD42 data block to buf, with the passwords pwd
+// concatenated, and returns its length.
+func lockData(buf []byte, mode byte, pwd ...[]byte) (int, error) {
+	buf[0] = mode
+	n := 2
+	for _, p := range pwd {
+		if len(p) > maxPassword {
+			return 0, errPassword
+		}
+		n += copy(buf[n:], p)
+	}
+	buf[1] = byte(n - 2)
+	if mode&lockErase != 0 {
+		n = 1
+	}
+	return n, nil
+}
+
+// SetPassword sets the password of the card, of up to 16 bytes, replacing
+// old, which is empty if the card has no password. The card is not locked
+// until Lock is called, or it is powered up again.
+func (d *Device) SetPassword(old, new []byte) error {
+	return d.lockUnlock(lockSetPwd, lockTimeout, old, new)
+}
+
+// ClearPassword removes the password of an unlocked card.
+func (d *Device) ClearPassword(pwd []byte) error {
+	return d.lockUnlock(lockClrPwd, lockTimeout, pwd)
+}
+
+// Lock locks the card with its password.
+func (d *Device) Lock(pwd []byte) error {
+	return d.lockUnlock(lockLock, lockTimeout, pwd)
+}
+
+// Unlock unlocks a locked card with its password. The card locks itself
+// again at the next power up.
+func (d *Device) Unlock(pwd []byte) error {
+	return d.lockUnlock(0, lockTimeout, pwd)
+}
+
+// ForceErase erases all the data of the card, and removes its password, for
+// instance when the password is lost. It can take minutes. Cards with
+// permanent write protection cannot be force erased.
+func (d *Device) ForceErase() error {
+	return d.lockUnlock(lockErase, forceTimeout)
+}
+
+// IsLocked returns whether the card is locked.
+func (d *Device) IsLocked() (bool, error) {
+	status, err := d.Status()
+	return status.Locked(), err
+}
+
+func (d *Device) lockUnlock(mode byte, timeout time.Duration, pwd ...[]byte) error {
+	var buf [2 + 2*maxPassword]byte
+	n, err := lockData(buf[:], mode, pwd...)
+	if err != nil {
+		return err
+	}
+	if err := d.checkPresent(); err != nil {
+		return err
+	}
+	d.cache.reset()
+
+	err = d.sendLockData(buf[:n], timeout)
+	// Restore the block length before anything else.
+	if d.cmd(CMD16_SET_BLOCKLEN, 0x0200, 0xFF) != 0 && err == nil {
+		err = fmt.Errorf("SD_CARD_ERROR_CMD16")
+	}
+	d.cs.High()
+	if err != nil {
+		return err
+	}
+
+	status, err := d.Status()
+	if err != nil {
+		return err
+	}
+	if status&StatusLockUnlockFail != 0 {
+		return ErrLockFailed
+	}
+	return nil
+}
+
+// sendLockData sends CMD42 with its data block, and waits until the card is
+// done with it.
+func (d Device) sendLockData(data []byte, timeout time.Duration) error {
+	if d.cmd(CMD16_SET_BLOCKLEN, uint32(len(data)), 0xFF) != 0 {
+		return fmt.Errorf("SD_CARD_ERROR_CMD16")
+	}
+	if d.cmd(CMD42_LOCK_UNLOCK, 0, 0xFF) != 0 {
+		return fmt.Errorf("CMD42 error")
+	}
+	d.bus.Transfer(byte(0xFE))
+	if err := d.bus.Tx(data, nil); err != nil {
+		return err
+	}
+	crc := d.writeCRC(data)
+	if err := d.dataResponse(0, crc); err != nil {
+		return err
+	}
+
+	begin := time.Now()
+	for {
+		r, err := d.bus.Transfer(byte(0xFF))
+		if err != nil {
+			return err
+		}
+		if r == 0xFF {
+			return nil
+		}
+		if time.Since(begin) > timeout {
+			return fmt.Errorf("SD_CARD_ERROR_WRITE_TIMEOUT")
+		}
+		drivers.Yield()
+	}
+}
+
+// SetPassword sets the password of the card, of up to 16 bytes, replacing
+// old, which is empty if the card has no password. The card is not locked
+// until Lock is called, or it is powered up again.
+func (c *SDIOCard) SetPassword(old, new []byte) error {
+	return c.lockUnlock(lockSetPwd, lockTimeout, old, new)
+}
+
+// ClearPassword removes the password of an unlocked card.
+func (c *SDIOCard) ClearPassword(pwd []byte) error {
+	return c.lockUnlock(lockClrPwd, lockTimeout, pwd)
+}
+
+// Lock locks the card with its password.
+func (c *SDIOCard) Lock(pwd []byte) error {
+	return c.lockUnlock(lockLock, lockTimeout, pwd)
+}
+
+// Unlock unlocks a locked card with its password. The card locks itself
+// again at the next power up.
+func (c *SDIOCard) Unlock(pwd []byte) error {
+	return c.lockUnlock(0, lockTimeout, pwd)
+}
+
+// ForceErase erases all the data of the card, and removes its password, for
+// instance when the password is lost. It can take minutes.
+func (c *SDIOCard) ForceErase() error {
+	return c.lockUnlock(lockErase, forceTimeout)
+}
+
+// IsLocked returns whether the card is locked.
+func (c *SDIOCard) IsLocked() (bool, error) {
+	status, err := c.Status()
+	return status.Locked(), err
+}
+
+func (c *SDIOCard) lockUnlock(mode byte, timeout time.Duration, pwd ...[]byte) error {
+	var buf [2 + 2*maxPassword]byte
+	n, err := lockData(buf[:], mode, pwd...)
+	if err != nil {
+		return err
+	}
+	c.cache.reset()
+
+	if _, err := c.cmd(CMD16_SET_BLOCKLEN, uint32(n), SDIOResponseR1); err != nil {
+		return err
+	}
+	req := SDIORequest{Cmd: CMD42_LOCK_UNLOCK, Response: SDIOResponseR1b, Data: buf[:n], Write: true, BusyTimeout: timeout}
+	err = c.do(&req)
+	// The failure of CMD42 is reported in the status of the next command.
+	status, serr := c.Status()
+	if _, cerr := c.cmd(CMD16_SET_BLOCKLEN, 0x0200, SDIOResponseR1); err == nil {
+		err = cerr
+	}
+	switch {
+	case err != nil:
+		return err
+	case serr != nil:
+		return serr
+	case status&StatusLockUnlockFail != 0:
+		return ErrLockFailed
+	}
+	return nil
+}
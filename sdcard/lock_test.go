@@ -0,0 +1,110 @@
+package sdcard
+
+import (
+	"bytes"
+	"testing"
+)
+
+// lock runs the CMD42 data block on the card, and returns whether it
+// succeeded.
+func (h *fakeHost) lock(data []byte) bool {
+	if uint32(len(data)) != h.blockLen {
+		return false
+	}
+	mode := data[0]
+	if mode&lockErase != 0 {
+		if !h.locked {
+			return false
+		}
+		for i := range h.mem {
+			h.mem[i] = 0
+		}
+		h.pwd, h.locked = nil, false
+		return true
+	}
+	pwd := data[2 : 2+data[1]]
+	switch {
+	case mode&lockSetPwd != 0:
+		if !bytes.HasPrefix(pwd, h.pwd) || len(pwd) == len(h.pwd) {
+			return false
+		}
+		h.pwd = append([]byte(nil), pwd[len(h.pwd):]...)
+		return true
+	case !bytes.Equal(pwd, h.pwd) || h.pwd == nil:
+		return false
+	case mode&lockClrPwd != 0:
+		h.pwd = nil
+	default:
+		h.locked = mode&lockLock != 0
+	}
+	return true
+}
+
+func TestLockData(t *testing.T) {
+	var buf [34]byte
+	n, err := lockData(buf[:], lockSetPwd, []byte("old"), []byte("new1"))
+	if err != nil || string(buf[:n]) != "\x01\x07oldnew1" {
+		t.Errorf("set password: %q, %v", buf[:n], err)
+	}
+	n, _ = lockData(buf[:], lockErase)
+	if n != 1 || buf[0] != 0x08 {
+		t.Errorf("force erase: %q", buf[:n])
+	}
+	if _, err := lockData(buf[:], lockLock, make([]byte, 17)); err != errPassword {
+		t.Errorf("long password: %v", err)
+	}
+}
+
+func TestSDIOCardLock(t *testing.T) {
+	host := &fakeHost{mem: make([]byte, 64*512)}
+	host.mem[0] = 0x42
+	c := NewSDIO(host)
+	if err := c.Configure(); err != nil {
+		t.Fatal(err)
+	}
+	buf := make([]byte, 512)
+
+	if err := c.Lock([]byte("secret")); err != ErrLockFailed {
+		t.Errorf("Lock without password: %v", err)
+	}
+	if err := c.SetPassword(nil, []byte("secret")); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Lock([]byte("secret")); err != nil {
+		t.Fatal(err)
+	}
+	if locked, err := c.IsLocked(); !locked || err != nil {
+		t.Errorf("IsLocked: %v, %v", locked, err)
+	}
+	if host.blockLen != 512 {
+		t.Errorf("block length not restored: %d", host.blockLen)
+	}
+	if err := c.ReadData(0, buf); err == nil {
+		t.Errorf("ReadData of a locked card succeeded")
+	}
+	if err := c.Unlock([]byte("wrong")); err != ErrLockFailed {
+		t.Errorf("Unlock with a wrong password: %v", err)
+	}
+	if err := c.Unlock([]byte("secret")); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.ReadData(0, buf); err != nil || buf[0] != 0x42 {
+		t.Errorf("ReadData of an unlocked card: %v", err)
+	}
+
+	if err := c.SetPassword([]byte("secret"), []byte("other")); err != nil {
+		t.Fatal(err)
+	}
+	if string(host.pwd) != "other" {
+		t.Errorf("password not changed: %q", host.pwd)
+	}
+	if err := c.Lock([]byte("other")); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.ForceErase(); err != nil {
+		t.Fatal(err)
+	}
+	if locked, _ := c.IsLocked(); locked || host.pwd != nil || host.mem[0] != 0 {
+		t.Errorf("force erase: locked %v, password %q", locked, host.pwd)
+	}
+}
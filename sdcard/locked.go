@@ -0,0 +1,79 @@
+package sdcard
+
+import "sync"
+
+// LockedCard wraps a Device so that it can be shared between goroutines,
+// such as a logger and a file server. Every method holds a mutex while it
+// calls the Device, so concurrent calls can't interleave SPI transfers or
+// corrupt the command and block buffers of the Device. (The mutex has
+// nothing to do with the password lock of the card, see Lock.)
+//
+// Sequences of calls, such as a non-blocking read started with
+// StartReadBlock and completed with Wait, or the writes of a BlockWriter,
+// are only atomic when they are done within Do.
+type LockedCard struct {
+	mu  sync.Mutex
+	dev *Device
+}
+
+// NewLockedCard returns a LockedCard that wraps dev. The Device must not be
+// used directly anymore afterwards, except from within Do.
+func NewLockedCard(dev *Device) *LockedCard {
+	return &LockedCard{dev: dev}
+}
+
+// Do calls f with the wrapped Device while holding the lock. Use it for the
+// methods of Device that LockedCard doesn't have. f must not call methods
+// of l.
+func (l *LockedCard) Do(f func(d *Device)) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	f(l.dev)
+}
+
+// Configure initializes the card, see Device.Configure. No other goroutine
+// uses the card until it is done.
+func (l *LockedCard) Configure() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.dev.Configure()
+}
+
+func (l *LockedCard) ReadAt(buf []byte, addr int64) (int, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.dev.ReadAt(buf, addr)
+}
+
+func (l *LockedCard) WriteAt(buf []byte, addr int64) (int, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.dev.WriteAt(buf, addr)
+}
+
+func (l *LockedCard) Size() int64 {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.dev.Size()
+}
+
+func (l *LockedCard) WriteBlockSize() int64 {
+	return l.dev.WriteBlockSize()
+}
+
+func (l *LockedCard) EraseBlockSize() int64 {
+	return l.dev.EraseBlockSize()
+}
+
+func (l *LockedCard) EraseBlocks(start, len int64) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.dev.EraseBlocks(start, len)
+}
+
+// Sync writes the block cached by an unaligned WriteAt back to the card.
+func (l *LockedCard) Sync() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.dev.Sync()
+}
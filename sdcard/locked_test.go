@@ -0,0 +1,61 @@
+package sdcard
+
+import (
+	"bytes"
+	"sync"
+	"testing"
+)
+
+func TestLockedCard(t *testing.T) {
+	d, card := newSPICard()
+	l := NewLockedCard(d)
+
+	// Each goroutine patches a few bytes at a time of its own blocks, which
+	// goes through the block cache shared by all of them.
+	var wg sync.WaitGroup
+	errs := make(chan error, 4)
+	for g := 0; g < 4; g++ {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+			base := int64(g) * 2 * 512
+			for off := int64(0); off < 2*512; off += 128 {
+				p := bytes.Repeat([]byte{byte(g + 1)}, 128)
+				if _, err := l.WriteAt(p, base+off); err != nil {
+					errs <- err
+					return
+				}
+				got := make([]byte, 128)
+				if _, err := l.ReadAt(got, base+off); err != nil {
+					errs <- err
+					return
+				}
+				if !bytes.Equal(got, p) {
+					t.Errorf("goroutine %d: read back % x at %d", g, got[:4], base+off)
+					return
+				}
+			}
+		}(g)
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		t.Fatal(err)
+	}
+	if err := l.Sync(); err != nil {
+		t.Fatal(err)
+	}
+
+	var status CardStatus
+	var err error
+	l.Do(func(d *Device) { status, err = d.Status() })
+	if err != nil || status&StatusErrors != 0 {
+		t.Errorf("Status: %v, %v", status, err)
+	}
+	for g := 0; g < 4; g++ {
+		want := bytes.Repeat([]byte{byte(g + 1)}, 2*512)
+		if got := card.mem[g*2*512 : (g+1)*2*512]; !bytes.Equal(got, want) {
+			t.Errorf("blocks of goroutine %d not written", g)
+		}
+	}
+}
@@ -0,0 +1,244 @@
+package sdcard
+
+import "fmt"
+
+// MemoryCard is a card in memory, with the block device methods of Device,
+// its CID and CSD, to test the code using a card on the host. Only the
+// blocks written take memory, so it may be as large as a real card. Erased
+// blocks, and blocks never written, read as zeros.
+//
+// The blocks may also be kept in an image file, see NewFileCard.
+type MemoryCard struct {
+	CID *CID
+	CSD *CSD
+
+	cid, csd [16]byte
+	blocks   blockStore
+	nblocks  int64
+	cache    blockCache
+}
+
+// blockStore holds the blocks of a MemoryCard.
+type blockStore interface {
+	readBlocks(start int64, dst []byte) error
+	writeBlocks(start int64, src []byte) error
+	erase(start, end int64) error
+}
+
+// memBlockStore holds the blocks written to a MemoryCard in memory.
+type memBlockStore map[int64]*[512]byte
+
+// NewMemoryCard returns a high capacity SD card of size bytes, rounded up to
+// a multiple of 512KiB as in the CSD of such cards.
+func NewMemoryCard(size int64) *MemoryCard {
+	return newMemoryCard(size, memBlockStore{})
+}
+
+func newMemoryCard(size int64, blocks blockStore) *MemoryCard {
+	const unit = 512 * 1024
+	cSize := (size + unit - 1) / unit
+	if cSize < 1 {
+		cSize = 1
+	}
+	c := &MemoryCard{
+		blocks:  blocks,
+		nblocks: cSize * unit / 512,
+		cache:   blockCache{buf: make([]byte, 512)},
+	}
+
+	// A CSD version 2.0, as in the SD specification.
+	csd := &c.csd
+	csd[0] = 0x40
+	csd[1] = 0x0E // TAAC: 1ms
+	csd[3] = 0x32 // TRAN_SPEED: 25MHz
+	csd[4] = 0x5B // CCC: 0x5B5
+	csd[5] = 0x59 // READ_BL_LEN: 512
+	csd[7] = byte((cSize - 1) >> 16 & 0x3F)
+	csd[8] = byte((cSize - 1) >> 8)
+	csd[9] = byte(cSize - 1)
+	csd[10] = 0x7F // ERASE_BLK_EN, SECTOR_SIZE: 64KiB
+	csd[11] = 0x80
+	csd[12] = 0x0A // R2W_FACTOR: 4, WRITE_BL_LEN: 512
+	csd[13] = 0x40
+	csd[15] = crc7(csd[:15])<<1 | 1
+	c.CSD = NewCSD(csd[:])
+
+	// A CID of manufacturer 0, made in January 2024.
+	cid := &c.cid
+	copy(cid[1:3], "TG")
+	copy(cid[3:8], "MEMCD")
+	cid[8] = 0x10 // revision 1.0
+	cid[9], cid[10], cid[11], cid[12] = 0x12, 0x34, 0x56, 0x78
+	cid[13], cid[14] = 0x01, 0x81 // 2024-01, from 2000
+	cid[15] = crc7(cid[:15])<<1 | 1
+	c.CID = NewCID(cid[:])
+	return c
+}
+
+// Configure does nothing: the card is ready once created.
+func (c *MemoryCard) Configure() error {
+	return nil
+}
+
+// Kind returns CardSD.
+func (c *MemoryCard) Kind() CardKind {
+	return CardSD
+}
+
+// ReadCSD reads the CSD register, as sent by the card.
+func (c *MemoryCard) ReadCSD(csd []byte) error {
+	copy(csd, c.csd[:])
+	return nil
+}
+
+// ReadCID reads the CID register, as sent by the card.
+func (c *MemoryCard) ReadCID(cid []byte) error {
+	copy(cid, c.cid[:])
+	return nil
+}
+
+// ReadData reads 512 bytes from the card into dst.
+func (c *MemoryCard) ReadData(block uint32, dst []byte) error {
+	if len(dst) < 512 {
+		return fmt.Errorf("len(dst) must be greater than or equal to 512")
+	}
+	return c.ReadBlocks(int64(block), dst[:512])
+}
+
+// ReadBlocks reads dst, which must be a multiple of 512 bytes long, from
+// consecutive blocks starting at startBlock.
+func (c *MemoryCard) ReadBlocks(startBlock int64, dst []byte) error {
+	if err := c.check(startBlock, len(dst)); err != nil {
+		return err
+	}
+	return c.blocks.readBlocks(startBlock, dst)
+}
+
+// WriteData writes 512 bytes from src to the card.
+func (c *MemoryCard) WriteData(block uint32, src []byte) error {
+	if len(src) < 512 {
+		return fmt.Errorf("len(src) must be greater than or equal to 512")
+	}
+	return c.WriteBlocks(int64(block), src[:512])
+}
+
+// WriteBlocks writes src, which must be a multiple of 512 bytes long, to
+// consecutive blocks starting at startBlock.
+func (c *MemoryCard) WriteBlocks(startBlock int64, src []byte) error {
+	if err := c.check(startBlock, len(src)); err != nil {
+		return err
+	}
+	return c.blocks.writeBlocks(startBlock, src)
+}
+
+// Erase erases the blocks from startBlock to endBlock, both included.
+func (c *MemoryCard) Erase(startBlock, endBlock int64) error {
+	if endBlock < startBlock {
+		return fmt.Errorf("endBlock must not be lower than startBlock")
+	}
+	if startBlock < 0 || endBlock >= c.nblocks {
+		return errEraseRange
+	}
+	return c.blocks.erase(startBlock, endBlock)
+}
+
+// check returns an error unless length bytes starting at block are whole
+// blocks of the card.
+func (c *MemoryCard) check(block int64, length int) error {
+	if length == 0 || length%512 != 0 {
+		return errSDIOBlock
+	}
+	if block < 0 || block+int64(length/512) > c.nblocks {
+		return errSDIORange
+	}
+	return nil
+}
+
+func (m memBlockStore) readBlocks(start int64, dst []byte) error {
+	for i := 0; i < len(dst); i += 512 {
+		if b := m[start+int64(i/512)]; b != nil {
+			copy(dst[i:i+512], b[:])
+		} else {
+			zero(dst[i : i+512])
+		}
+	}
+	return nil
+}
+
+func (m memBlockStore) writeBlocks(start int64, src []byte) error {
+	for i := 0; i < len(src); i += 512 {
+		n := start + int64(i/512)
+		b := m[n]
+		if b == nil {
+			b = new([512]byte)
+			m[n] = b
+		}
+		copy(b[:], src[i:i+512])
+	}
+	return nil
+}
+
+func (m memBlockStore) erase(start, end int64) error {
+	for n := range m {
+		if n >= start && n <= end {
+			delete(m, n)
+		}
+	}
+	return nil
+}
+
+func zero(buf []byte) {
+	for i := range buf {
+		buf[i] = 0
+	}
+}
+
+// ReadAt reads the given number of bytes from the card. The data may start
+// and end anywhere; partial blocks are read through the block cache.
+func (c *MemoryCard) ReadAt(buf []byte, addr int64) (int, error) {
+	return c.cache.readAt(c, buf, addr)
+}
+
+// WriteAt writes the given number of bytes to the card. Partial blocks are
+// changed in the block cache, and written back by Sync, as with Device.
+func (c *MemoryCard) WriteAt(buf []byte, addr int64) (int, error) {
+	return c.cache.writeAt(c, buf, addr)
+}
+
+func (c *MemoryCard) readBlock(block int64, dst []byte) error {
+	return c.ReadBlocks(block, dst[:512])
+}
+
+func (c *MemoryCard) writeBlock(block int64, src []byte) error {
+	return c.WriteBlocks(block, src[:512])
+}
+
+// Sync writes the block cached by an unaligned WriteAt back to the card.
+func (c *MemoryCard) Sync() error {
+	return c.cache.sync(c)
+}
+
+// Size returns the number of bytes in this card.
+func (c *MemoryCard) Size() int64 {
+	return c.nblocks * 512
+}
+
+// WriteBlockSize returns the block size in which data can be written to
+// memory.
+func (c *MemoryCard) WriteBlockSize() int64 {
+	return 512
+}
+
+// EraseBlockSize returns the smallest erasable area on this card in bytes.
+func (c *MemoryCard) EraseBlockSize() int64 {
+	return 512
+}
+
+// EraseBlocks erases the given number of blocks.
+func (c *MemoryCard) EraseBlocks(start, len int64) error {
+	if len <= 0 {
+		return nil
+	}
+	c.cache.drop(start, start+len)
+	return c.Erase(start, start+len-1)
+}
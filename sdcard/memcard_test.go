@@ -0,0 +1,69 @@
+package sdcard
+
+import (
+	"bytes"
+	"testing"
+)
+
+var _ blockDevice = (*MemoryCard)(nil)
+
+func TestMemoryCard(t *testing.T) {
+	c := NewMemoryCard(8 << 30)
+	if c.Size() != 8<<30 || int64(c.CSD.Size()) != c.Size() {
+		t.Errorf("size %d, CSD size %d", c.Size(), c.CSD.Size())
+	}
+	if c.CSD.CSD_STRUCTURE != 1 || c.CSD.TransferSpeed() != 25000000 || c.CSD.EraseSectorSizeInSectors() != 128 {
+		t.Errorf("CSD %+v", c.CSD)
+	}
+	if c.CID.ProductName != "MEMCD" || c.CID.ProductVersion != "1.0" || c.CID.ProductSerialNumber != 0x12345678 {
+		t.Errorf("CID %+v", c.CID)
+	}
+	var reg [16]byte
+	c.ReadCSD(reg[:])
+	if reg[15] != crc7(reg[:15])<<1|1 {
+		t.Errorf("CSD CRC %02X", reg[15])
+	}
+	if NewMemoryCard(1000).Size() != 512*1024 {
+		t.Errorf("size not rounded up")
+	}
+
+	// Unaligned writes, and whole blocks past 4GB.
+	data := bytes.Repeat([]byte("0123456789"), 200)
+	if _, err := c.WriteAt(data, 5<<30+100); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Sync(); err != nil {
+		t.Fatal(err)
+	}
+	got := make([]byte, len(data)+200)
+	if _, err := c.ReadAt(got, 5<<30); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got[100:len(data)+100], data) || got[99] != 0 || got[len(data)+100] != 0 {
+		t.Errorf("read back %q", got)
+	}
+	if err := c.EraseBlocks(5<<21+1, 2); err != nil {
+		t.Fatal(err)
+	}
+	c.ReadAt(got, 5<<30)
+	if got[511] != data[411] || got[512] != 0 || got[3*512-1] != 0 || got[3*512] == 0 {
+		t.Errorf("after erase %q", got)
+	}
+
+	buf := make([]byte, 1024)
+	if err := c.ReadBlocks(c.Size()/512-1, buf); err != errSDIORange {
+		t.Errorf("read past the end: %v", err)
+	}
+	if err := c.WriteBlocks(0, buf[:100]); err != errSDIOBlock {
+		t.Errorf("partial block: %v", err)
+	}
+	if err := c.Erase(0, c.Size()/512); err != errEraseRange {
+		t.Errorf("erase past the end: %v", err)
+	}
+
+	// It can be wrapped like a card.
+	f := NewFlashCard(NewCachedCard(c, 4), 0)
+	if f.EraseBlockSize() != 64<<10 || f.Size() != c.Size() {
+		t.Errorf("flash card of %d bytes, erase blocks of %d bytes", f.Size(), f.EraseBlockSize())
+	}
+}
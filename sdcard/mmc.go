@@ -0,0 +1,62 @@
+package sdcard
+
+import "fmt"
+
+// CardKind is the family of a card, known once it is configured.
+type CardKind uint8
+
+const (
+	CardUnknown CardKind = iota
+	CardSD               // SD card, initialized with ACMD41
+	CardMMC              // MMC card or eMMC chip, initialized with CMD1
+)
+
+// String returns "SD", "MMC" or "unknown".
+func (k CardKind) String() string {
+	switch k {
+	case CardSD:
+		return "SD"
+	case CardMMC:
+		return "MMC"
+	}
+	return "unknown"
+}
+
+// Kind returns the family of the card. MMC cards larger than 2GB are
+// addressed in blocks, and reported as SD_CARD_TYPE_SDHC.
+func (d *Device) Kind() CardKind {
+	return d.kind
+}
+
+// mmcSectorMode returns whether the access mode bits of the OCR of an MMC
+// card are set to sector mode: the card is addressed in blocks.
+func (o OCR) mmcSectorMode() bool {
+	return o>>29&0x03 == 0x02
+}
+
+// extCSDSectors returns SEC_COUNT, the number of blocks of MMC cards larger
+// than 2GB, from the 512 byte extended CSD.
+func extCSDSectors(ext []byte) uint32 {
+	return uint32(ext[212]) | uint32(ext[213])<<8 | uint32(ext[214])<<16 | uint32(ext[215])<<24
+}
+
+// readExtCSD reads the extended CSD of an MMC card using CMD8, and keeps its
+// number of blocks.
+func (d *Device) readExtCSD() error {
+	defer d.cs.High()
+	if d.cmd(CMD8_SEND_EXT_CSD, 0, 0xFF) != 0 {
+		return fmt.Errorf("SD_CARD_ERROR_CMD8")
+	}
+	if err := d.waitStartBlock(); err != nil {
+		return err
+	}
+	buf := d.dummybuf
+	if err := d.bus.Tx(dummy[:512], buf); err != nil {
+		return err
+	}
+	if err := d.readCRC(0, buf); err != nil {
+		return err
+	}
+	d.sectors = extCSDSectors(buf)
+	return nil
+}
@@ -0,0 +1,84 @@
+package sdcard
+
+import "fmt"
+
+// OCR is the operation conditions register of the card, read with ReadOCR.
+type OCR uint32
+
+// ReadOCR reads the OCR using CMD58.
+func (d Device) ReadOCR() (OCR, error) {
+	defer d.cs.High()
+	if d.cmd(CMD58_READ_OCR, 0, 0xFF) != 0 {
+		return 0, fmt.Errorf("SD_CARD_ERROR_CMD58")
+	}
+	var ocr OCR
+	for i := 0; i < 4; i++ {
+		b, err := d.bus.Transfer(byte(0xFF))
+		if err != nil {
+			return 0, err
+		}
+		ocr = ocr<<8 | OCR(b)
+	}
+	return ocr, nil
+}
+
+// PowerUp returns whether the card has finished its power up routine. The
+// other bits are only valid once it has.
+func (o OCR) PowerUp() bool {
+	return o&(1<<31) != 0
+}
+
+// CCS returns the card capacity status: true for high and extended capacity
+// cards (SDHC and SDXC), which are addressed in blocks instead of bytes.
+func (o OCR) CCS() bool {
+	return o&(1<<30) != 0
+}
+
+// CO2T returns whether the card is an SDUC card, larger than 2TB, which is
+// only set when the host announced SDUC support in ACMD41.
+func (o OCR) CO2T() bool {
+	return o&(1<<27) != 0
+}
+
+// UHSII returns whether the card supports UHS-II.
+func (o OCR) UHSII() bool {
+	return o&(1<<29) != 0
+}
+
+// S18A returns whether the card accepted switching to 1.8V signaling.
+func (o OCR) S18A() bool {
+	return o&(1<<24) != 0
+}
+
+// VoltageWindow returns the lowest and highest supply voltage supported by
+// the card in mV, or 0, 0 if the voltage window is empty. Bits 15 to 23 each
+// stand for 100mV, from 2.7V to 3.6V.
+func (o OCR) VoltageWindow() (min, max int) {
+	for bit := 15; bit <= 23; bit++ {
+		if o&(1<<bit) == 0 {
+			continue
+		}
+		if min == 0 {
+			min = 2700 + (bit-15)*100
+		}
+		max = 2800 + (bit-15)*100
+	}
+	return min, max
+}
+
+// Supports returns whether the card supports the given supply voltage in
+// mV.
+func (o OCR) Supports(mv int) bool {
+	if mv < 2700 || mv > 3600 {
+		return false
+	}
+	bit := 15 + (mv-2700)/100
+	if bit > 23 {
+		bit = 23
+	}
+	if o&(1<<bit) != 0 {
+		return true
+	}
+	// A voltage on a boundary is in two ranges.
+	return mv%100 == 0 && mv > 2700 && o&(1<<(bit-1)) != 0
+}
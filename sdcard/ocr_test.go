@@ -0,0 +1,22 @@
+package sdcard
+
+import "testing"
+
+func TestOCR(t *testing.T) {
+	// Powered up SDHC card supporting 3.2V to 3.5V.
+	ocr := OCR(0xC0000000 | 0x7<<20)
+	if !ocr.PowerUp() || !ocr.CCS() || ocr.UHSII() {
+		t.Errorf("status bits of %08X", uint32(ocr))
+	}
+	if min, max := ocr.VoltageWindow(); min != 3200 || max != 3500 {
+		t.Errorf("VoltageWindow: got %d-%d, want 3200-3500", min, max)
+	}
+	for _, tc := range []struct {
+		mv   int
+		want bool
+	}{{3100, false}, {3200, true}, {3300, true}, {3500, true}, {3550, false}} {
+		if got := ocr.Supports(tc.mv); got != tc.want {
+			t.Errorf("Supports(%d): got %v, want %v", tc.mv, got, tc.want)
+		}
+	}
+}
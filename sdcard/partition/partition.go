@@ -0,0 +1,187 @@
+// Package partition reads the MBR partition table found at the start of
+// most SD cards, and gives access to each partition as a block device of
+// its own, which can be passed to the file systems of TinyFS.
+//
+//	parts, err := partition.Read(card)
+//	if err != nil {
+//		return err
+//	}
+//	for i := range parts {
+//		if parts[i].IsFAT() {
+//			fs := fatfs.New(&parts[i])
+//			...
+//		}
+//	}
+//
+// Only the four primary partitions are read: the logical partitions of an
+// extended partition are not, and neither are GPT tables.
+package partition // import "tinygo.org/x/drivers/sdcard/partition"
+
+import (
+	"encoding/binary"
+	"errors"
+)
+
+var (
+	// ErrNoMBR is returned by Read when the first block of the card is not
+	// an MBR, as when the card is formatted without partitions.
+	ErrNoMBR = errors.New("partition: no MBR")
+
+	errOutOfRange = errors.New("partition: access out of the partition")
+	errUnaligned  = errors.New("partition: erase blocks not aligned with the card")
+)
+
+// Partition types of the MBR.
+const (
+	TypeEmpty       = 0x00
+	TypeFAT12       = 0x01
+	TypeFAT16Small  = 0x04 // FAT16 of less than 32MB
+	TypeExtended    = 0x05
+	TypeFAT16       = 0x06
+	TypeExFAT       = 0x07 // also NTFS
+	TypeFAT32       = 0x0B
+	TypeFAT32LBA    = 0x0C
+	TypeFAT16LBA    = 0x0E
+	TypeExtendedLBA = 0x0F
+	TypeLinux       = 0x83
+	TypeGPT         = 0xEE // protective MBR of a GPT disk
+)
+
+// BlockDevice is the BlockDevice interface of TinyFS, implemented by the
+// cards of the sdcard package and by partitions.
+type BlockDevice interface {
+	ReadAt(buf []byte, off int64) (n int, err error)
+	WriteAt(buf []byte, off int64) (n int, err error)
+	Size() int64
+	WriteBlockSize() int64
+	EraseBlockSize() int64
+	EraseBlocks(start, len int64) error
+}
+
+// syncer is implemented by cards holding writes in a cache.
+type syncer interface {
+	Sync() error
+}
+
+// Partition is a partition of the card, and a block device limited to it.
+type Partition struct {
+	Type     uint8
+	Bootable bool
+	Start    uint32 // first block (LBA) on the card
+	Length   uint32 // in blocks
+
+	dev BlockDevice
+}
+
+// Read reads the partition table in the first block of a card, and returns
+// its four entries. Unused entries have the type TypeEmpty and a length of
+// 0.
+func Read(dev BlockDevice) ([4]Partition, error) {
+	var parts [4]Partition
+	var mbr [512]byte
+	if _, err := dev.ReadAt(mbr[:], 0); err != nil {
+		return parts, err
+	}
+	if mbr[510] != 0x55 || mbr[511] != 0xAA {
+		return parts, ErrNoMBR
+	}
+	blocks := dev.Size() / 512
+	for i := range parts {
+		e := mbr[446+16*i : 446+16*(i+1)]
+		// The boot sector of a FAT file system also ends with 55AA, but
+		// does not have valid status bytes there.
+		if e[0] != 0x00 && e[0] != 0x80 {
+			return [4]Partition{}, ErrNoMBR
+		}
+		p := Partition{
+			Type:     e[4],
+			Bootable: e[0] == 0x80,
+			Start:    binary.LittleEndian.Uint32(e[8:]),
+			Length:   binary.LittleEndian.Uint32(e[12:]),
+			dev:      dev,
+		}
+		if p.Type == TypeEmpty || p.Length == 0 {
+			p = Partition{dev: dev}
+		} else if int64(p.Start)+int64(p.Length) > blocks {
+			return [4]Partition{}, ErrNoMBR
+		}
+		parts[i] = p
+	}
+	return parts, nil
+}
+
+// IsFAT returns whether the partition type is one of the FAT file systems,
+// exFAT excepted.
+func (p *Partition) IsFAT() bool {
+	switch p.Type {
+	case TypeFAT12, TypeFAT16Small, TypeFAT16, TypeFAT32, TypeFAT32LBA, TypeFAT16LBA:
+		return true
+	}
+	return false
+}
+
+// ReadAt reads from the partition, at an offset from its start.
+func (p *Partition) ReadAt(buf []byte, off int64) (int, error) {
+	if err := p.check(off, len(buf)); err != nil {
+		return 0, err
+	}
+	return p.dev.ReadAt(buf, p.offset()+off)
+}
+
+// WriteAt writes to the partition, at an offset from its start.
+func (p *Partition) WriteAt(buf []byte, off int64) (int, error) {
+	if err := p.check(off, len(buf)); err != nil {
+		return 0, err
+	}
+	return p.dev.WriteAt(buf, p.offset()+off)
+}
+
+// Size returns the size of the partition in bytes.
+func (p *Partition) Size() int64 {
+	return int64(p.Length) * 512
+}
+
+// WriteBlockSize returns the write block size of the card.
+func (p *Partition) WriteBlockSize() int64 {
+	return p.dev.WriteBlockSize()
+}
+
+// EraseBlockSize returns the erase block size of the card.
+func (p *Partition) EraseBlockSize() int64 {
+	return p.dev.EraseBlockSize()
+}
+
+// EraseBlocks erases the given number of erase blocks, from the start of
+// the partition.
+func (p *Partition) EraseBlocks(start, len int64) error {
+	size := p.dev.EraseBlockSize()
+	if p.offset()%size != 0 {
+		return errUnaligned
+	}
+	if err := p.check(start*size, int(len*size)); err != nil {
+		return err
+	}
+	return p.dev.EraseBlocks(p.offset()/size+start, len)
+}
+
+// Sync writes the data the card holds in its cache, when it has one, such
+// as the partial blocks cached by a *sdcard.Device. File systems call it
+// when their files are synced or closed.
+func (p *Partition) Sync() error {
+	if s, ok := p.dev.(syncer); ok {
+		return s.Sync()
+	}
+	return nil
+}
+
+// offset returns the offset of the partition on the card in bytes.
+func (p *Partition) offset() int64 {
+	return int64(p.Start) * 512
+}
+
+func (p *Partition) check(off int64, n int) error {
+	if off < 0 || off+int64(n) > p.Size() {
+		return errOutOfRange
+	}
+	return nil
+}
@@ -0,0 +1,137 @@
+package partition
+
+import (
+	"encoding/binary"
+	"testing"
+)
+
+var _ BlockDevice = (*Partition)(nil)
+
+// memDev is a card in memory.
+type memDev struct {
+	data   []byte
+	erased [2]int64
+}
+
+func (m *memDev) ReadAt(buf []byte, off int64) (int, error) {
+	return copy(buf, m.data[off:]), nil
+}
+
+func (m *memDev) WriteAt(buf []byte, off int64) (int, error) {
+	return copy(m.data[off:], buf), nil
+}
+
+func (m *memDev) Size() int64           { return int64(len(m.data)) }
+func (m *memDev) WriteBlockSize() int64 { return 512 }
+func (m *memDev) EraseBlockSize() int64 { return 512 }
+
+func (m *memDev) EraseBlocks(start, len int64) error {
+	m.erased = [2]int64{start, len}
+	return nil
+}
+
+func entry(mbr []byte, i int, status, typ byte, start, length uint32) {
+	e := mbr[446+16*i:]
+	e[0], e[4] = status, typ
+	binary.LittleEndian.PutUint32(e[8:], start)
+	binary.LittleEndian.PutUint32(e[12:], length)
+}
+
+func newCard() *memDev {
+	m := &memDev{data: make([]byte, 64*512)}
+	mbr := m.data[:512]
+	mbr[510], mbr[511] = 0x55, 0xAA
+	entry(mbr, 0, 0x80, TypeFAT32LBA, 8, 32)
+	entry(mbr, 1, 0x00, TypeLinux, 40, 24)
+	return m
+}
+
+func TestRead(t *testing.T) {
+	card := newCard()
+	parts, err := Read(card)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p := &parts[0]
+	if p.Type != TypeFAT32LBA || !p.Bootable || p.Start != 8 || p.Length != 32 || !p.IsFAT() {
+		t.Errorf("partition 0: %+v", p)
+	}
+	if parts[1].Type != TypeLinux || parts[1].IsFAT() || parts[1].Size() != 24*512 {
+		t.Errorf("partition 1: %+v", parts[1])
+	}
+	if parts[2].Type != TypeEmpty || parts[3].Length != 0 {
+		t.Errorf("unused partitions: %+v %+v", parts[2], parts[3])
+	}
+
+	// Accesses are relative to the partition, and limited to it.
+	if _, err := p.WriteAt([]byte("FAT"), 3); err != nil {
+		t.Fatal(err)
+	}
+	if string(card.data[8*512+3:8*512+6]) != "FAT" {
+		t.Error("written at the wrong place")
+	}
+	buf := make([]byte, 3)
+	if _, err := p.ReadAt(buf, 3); err != nil || string(buf) != "FAT" {
+		t.Errorf("read %q, %v", buf, err)
+	}
+	if _, err := p.ReadAt(buf, p.Size()-2); err != errOutOfRange {
+		t.Errorf("read past the end: %v", err)
+	}
+	if err := p.EraseBlocks(30, 2); err != nil || card.erased != [2]int64{38, 2} {
+		t.Errorf("erased %v, %v", card.erased, err)
+	}
+	if err := p.EraseBlocks(31, 2); err != errOutOfRange {
+		t.Errorf("erase past the end: %v", err)
+	}
+}
+
+// syncDev is a card with a write cache.
+type syncDev struct {
+	memDev
+	syncs int
+}
+
+func (m *syncDev) Sync() error {
+	m.syncs++
+	return nil
+}
+
+func TestSync(t *testing.T) {
+	card := &syncDev{memDev: *newCard()}
+	parts, err := Read(card)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := parts[0].Sync(); err != nil || card.syncs != 1 {
+		t.Errorf("synced %d times, %v", card.syncs, err)
+	}
+
+	// Cards without a cache have nothing to sync.
+	parts, _ = Read(newCard())
+	if err := parts[0].Sync(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestNoMBR(t *testing.T) {
+	// No signature.
+	card := newCard()
+	card.data[511] = 0
+	if _, err := Read(card); err != ErrNoMBR {
+		t.Errorf("no signature: %v", err)
+	}
+
+	// The boot sector of a card formatted without partitions.
+	card = newCard()
+	copy(card.data[446:], "\x29NO NAME    FAT32   ")
+	if _, err := Read(card); err != ErrNoMBR {
+		t.Errorf("boot sector: %v", err)
+	}
+
+	// A partition past the end of the card.
+	card = newCard()
+	entry(card.data, 2, 0x00, TypeFAT16, 60, 8)
+	if _, err := Read(card); err != ErrNoMBR {
+		t.Errorf("partition too large: %v", err)
+	}
+}
@@ -0,0 +1,149 @@
+package sdcard
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// CMD27 programs the CSD. Only the bits of byte 14 are writable:
+// FILE_FORMAT_GRP, COPY, PERM_WRITE_PROTECT, TMP_WRITE_PROTECT and
+// FILE_FORMAT. The other bytes must be sent as read, or the card rejects the
+// CSD with CSD_OVERWRITE. The CRC7 of byte 15 is part of the data, so it is
+// computed again.
+//
+// TMP_WRITE_PROTECT makes the whole card read-only, until cleared, for any
+// host that honors it. PERM_WRITE_PROTECT does the same for good: it can
+// never be cleared.
+
+var errCSDLength = errors.New("sdcard: CSD shorter than 16 bytes")
+
+const (
+	csdTempWriteProtect   = 1 << 4 // of byte 14
+	programCSDBusyTimeout = 250 * time.Millisecond
+)
+
+// csdData copies the first 16 bytes of csd to buf, with its CRC7 computed
+// again.
+func csdData(buf *[16]byte, csd []byte) error {
+	if len(csd) < 16 {
+		return errCSDLength
+	}
+	copy(buf[:], csd)
+	buf[15] = crc7(buf[:15])<<1 | 1
+	return nil
+}
+
+// setTempWriteProtect sets or clears TMP_WRITE_PROTECT in csd.
+func setTempWriteProtect(csd []byte, protect bool) {
+	if protect {
+		csd[14] |= csdTempWriteProtect
+	} else {
+		csd[14] &^= csdTempWriteProtect
+	}
+}
+
+// WriteCSD programs the writable bits of the CSD with CMD27, see
+// SetTemporaryWriteProtect. csd is the CSD as read by ReadCSD, with only
+// the bits of byte 14 changed; its CRC is computed again. Setting
+// PERM_WRITE_PROTECT makes the card read-only forever.
+func (d *Device) WriteCSD(csd []byte) error {
+	var buf [16]byte
+	if err := csdData(&buf, csd); err != nil {
+		return err
+	}
+	if err := d.checkPresent(); err != nil {
+		return err
+	}
+	err := d.sendCSD(buf[:])
+	d.cs.High()
+	if err != nil {
+		return err
+	}
+	status, err := d.Status()
+	if err != nil {
+		return err
+	}
+	if status&StatusErrors != 0 {
+		return &CardStatusError{Cmd: CMD27_PROGRAM_CSD, Status: status}
+	}
+	d.CSD = NewCSD(buf[:])
+	if d.kind == CardMMC {
+		d.CSD = NewMMCCSD(buf[:])
+	}
+	return nil
+}
+
+// SetTemporaryWriteProtect sets or clears the temporary write protection of
+// the whole card, the TMP_WRITE_PROTECT bit of the CSD. Writes and erases
+// fail while it is set, also in other devices.
+func (d *Device) SetTemporaryWriteProtect(protect bool) error {
+	var buf [16]byte
+	if err := d.ReadCSD(buf[:]); err != nil {
+		return err
+	}
+	setTempWriteProtect(buf[:], protect)
+	return d.WriteCSD(buf[:])
+}
+
+// sendCSD sends CMD27 with its data block, and waits until the card
+// programmed it.
+func (d Device) sendCSD(data []byte) error {
+	if r := d.cmd(CMD27_PROGRAM_CSD, 0, 0xFF); r != 0 {
+		return commandError(r, "CMD27 error")
+	}
+	d.bus.Transfer(byte(0xFE))
+	if err := d.bus.Tx(data, nil); err != nil {
+		return err
+	}
+	crc := d.writeCRC(data)
+	if err := d.dataResponse(0, crc); err != nil {
+		return d.writeError(err)
+	}
+	if err := d.waitNotBusy(programCSDBusyTimeout); err != nil {
+		if isContextError(err) {
+			return err
+		}
+		return fmt.Errorf("SD_CARD_ERROR_WRITE_TIMEOUT")
+	}
+	return nil
+}
+
+// WriteCSD programs the writable bits of the CSD with CMD27, see
+// SetTemporaryWriteProtect. csd is the CSD as read when the card was
+// configured, with only the bits of byte 14 changed; its CRC is computed
+// again. Setting PERM_WRITE_PROTECT makes the card read-only forever.
+func (c *SDIOCard) WriteCSD(csd []byte) error {
+	var buf [16]byte
+	if err := csdData(&buf, csd); err != nil {
+		return err
+	}
+	req := SDIORequest{Cmd: CMD27_PROGRAM_CSD, Response: SDIOResponseR1b, Data: buf[:], Write: true, BusyTimeout: programCSDBusyTimeout}
+	if err := c.do(&req); err != nil {
+		return err
+	}
+	// A read-only bit that differs is reported in the status of the next
+	// command.
+	status, err := c.Status()
+	if err != nil {
+		return err
+	}
+	if status&StatusErrors != 0 {
+		return &CardStatusError{Cmd: CMD27_PROGRAM_CSD, Status: status}
+	}
+	c.csd = buf
+	c.CSD = NewCSD(buf[:])
+	if c.kind == CardMMC {
+		c.CSD = NewMMCCSD(buf[:])
+	}
+	return nil
+}
+
+// SetTemporaryWriteProtect sets or clears the temporary write protection of
+// the whole card, the TMP_WRITE_PROTECT bit of the CSD. Writes and erases
+// fail while it is set, also in other devices.
+func (c *SDIOCard) SetTemporaryWriteProtect(protect bool) error {
+	buf := c.csd
+	setTempWriteProtect(buf[:], protect)
+	return c.WriteCSD(buf[:])
+}
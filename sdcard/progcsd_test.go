@@ -0,0 +1,71 @@
+package sdcard
+
+import "testing"
+
+// programCSD programs the CSD sent with CMD27, if only its writable bits
+// differ from the CSD of the card.
+func (h *fakeHost) programCSD(data []byte) {
+	var csd [16]byte
+	if h.csd != nil {
+		copy(csd[:], h.csd)
+	} else {
+		req := SDIORequest{Cmd: CMD9_SEND_CSD}
+		h.Do(&req)
+		longResponse(&req, csd[:])
+	}
+	if string(data[:14]) != string(csd[:14]) || crc7(data[:15])<<1|1 != data[15] {
+		h.csdOverwrite = true
+		return
+	}
+	h.csd = append([]byte(nil), data...)
+}
+
+func TestProgramCSD(t *testing.T) {
+	d, card := newSPICard()
+	card.csd = [16]byte{0x40, 0x0E, 0x00, 0x32, 0x5B, 0x59, 0x00, 0x00, 0x1D, 0x8A, 0x7F, 0x80, 0x0A, 0x40, 0x40}
+	card.csd[15] = crc7(card.csd[:15])<<1 | 1
+
+	if err := d.SetTemporaryWriteProtect(true); err != nil {
+		t.Fatal(err)
+	}
+	if card.csd[14] != 0x50 || d.CSD.TMP_WRITE_PROTECT != 1 || d.CSD.COPY != 1 {
+		t.Errorf("CSD byte 14 %02X, TMP_WRITE_PROTECT %d", card.csd[14], d.CSD.TMP_WRITE_PROTECT)
+	}
+	if err := d.SetTemporaryWriteProtect(false); err != nil || card.csd[14] != 0x40 {
+		t.Errorf("clear: %v, CSD byte 14 %02X", err, card.csd[14])
+	}
+
+	var csd [16]byte
+	d.ReadCSD(csd[:])
+	csd[9]++ // C_SIZE is read-only
+	if _, ok := d.WriteCSD(csd[:]).(*CardStatusError); !ok {
+		t.Error("read-only bits changed: no status error")
+	}
+	if err := d.WriteCSD(csd[:15]); err != errCSDLength {
+		t.Errorf("short CSD: %v", err)
+	}
+}
+
+func TestSDIOCardProgramCSD(t *testing.T) {
+	host := &fakeHost{mem: make([]byte, 64*512)}
+	c := NewSDIO(host)
+	if err := c.Configure(); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.SetTemporaryWriteProtect(true); err != nil {
+		t.Fatal(err)
+	}
+	if host.csd == nil || host.csd[14]&0x10 == 0 || c.CSD.TMP_WRITE_PROTECT != 1 {
+		t.Errorf("TMP_WRITE_PROTECT not set: %x", host.csd)
+	}
+	if err := c.SetTemporaryWriteProtect(false); err != nil || host.csd[14]&0x10 != 0 || c.CSD.TMP_WRITE_PROTECT != 0 {
+		t.Errorf("clear: %v, CSD %x", err, host.csd)
+	}
+
+	csd := c.csd
+	csd[3] = 0x5A // TRAN_SPEED is read-only
+	err := c.WriteCSD(csd[:])
+	if e, ok := err.(*CardStatusError); !ok || e.Status&StatusCSDOverwrite == 0 {
+		t.Errorf("read-only bits changed: %v", err)
+	}
+}
@@ -1,5 +1,5 @@
 // package sdcard provides a TinyGo driver for sdcard/mmc devices
-// using a SPI connection.
+// using a SPI connection, or the native SD bus in 4-bit mode with SDIOCard.
 //
 // To use a file system on the SDcard, please see the TinyFS repo:
 //
@@ -7,9 +7,11 @@
 package sdcard
 
 import (
+	"context"
 	"fmt"
-	"machine"
 	"time"
+
+	"tinygo.org/x/drivers"
 )
 
 const (
@@ -27,56 +29,91 @@ const (
 	SD_CARD_TYPE_SD1  = 1 // Standard capacity V1 SD card
 	SD_CARD_TYPE_SD2  = 2 // Standard capacity V2 SD card
 	SD_CARD_TYPE_SDHC = 3 // High Capacity SD card
+	SD_CARD_TYPE_SDUC = 4 // Ultra Capacity SD card, larger than 2TB
 )
 
 var (
 	dummy [512]byte
 )
 
+// chipSelect is the CS pin of the card, a machine.Pin.
+type chipSelect interface {
+	High()
+	Low()
+}
+
 type Device struct {
-	bus        *machine.SPI
-	sck        machine.Pin
-	sdo        machine.Pin
-	sdi        machine.Pin
-	cs         machine.Pin
+	bus        drivers.SPI
+	cs         chipSelect
+	setFreq    func(hz uint32) error // sets the SPI clock, may be nil
+	maxFreq    uint32
 	cmdbuf     []byte
 	dummybuf   []byte
 	tokenbuf   []byte
 	sdCardType byte
+	kind       CardKind
+	sectors    uint32 // from the extended CSD of large MMC cards
+	preErase   bool
+	verify     bool   // check multiple block writes with ACMD22
+	stats      *Stats // nil until ResetStats, see stats.go
+	trace      func(cmd byte, arg uint32, r1 Response1, err error)
+	crcEnabled bool
+	timeouts   Timeouts
+	cache      blockCache
+	detect     *cardDetect
+	ctx        context.Context // of the operation in progress, see ctx.go
 	CID        *CID
 	CSD        *CSD
-}
 
-func New(b *machine.SPI, sck, sdo, sdi, cs machine.Pin) Device {
-	return Device{
-		bus:        b,
-		cs:         cs,
-		sck:        sck,
-		sdo:        sdo,
-		sdi:        sdi,
-		cmdbuf:     make([]byte, 6),
-		dummybuf:   make([]byte, 512),
-		tokenbuf:   make([]byte, 1),
-		sdCardType: 0,
-	}
+	// OnInsert and OnRemove are called by Detect when a card is inserted
+	// in the slot or removed from it.
+	OnInsert func()
+	OnRemove func()
+
+	// State of a non-blocking operation, see async.go.
+	async         asyncOp
+	asyncDst      []byte
+	asyncBlock    uint32
+	asyncCRC      uint16
+	asyncDeadline time.Time
 }
 
+// initFrequency is the SPI clock during the initialization of the card,
+// which must be at most 400kHz.
+const initFrequency = 250000
+
 func (d *Device) Configure() error {
 	return d.initCard()
 }
 
+// SetMaxFrequency limits the SPI clock used after the initialization, which
+// is otherwise the maximum of the card (usually 25MHz). Use it when long
+// wires or a slow level shifter do not work at the card speed. It takes
+// effect at the next Configure.
+func (d *Device) SetMaxFrequency(hz uint32) {
+	d.maxFreq = hz
+}
+
+// setFrequency sets the SPI clock, if the bus allows it.
+func (d *Device) setFrequency(hz uint32) error {
+	if d.setFreq == nil {
+		return nil
+	}
+	return d.setFreq(hz)
+}
+
 func (d *Device) initCard() error {
-	d.bus.Configure(machine.SPIConfig{
-		SCK:       d.sck,
-		SDO:       d.sdo,
-		SDI:       d.sdi,
-		Frequency: 250000,
-		LSBFirst:  false,
-		Mode:      0, // phase=0, polarity=0
-	})
-
-	// set pin modes
-	d.cs.Configure(machine.PinConfig{Mode: machine.PinOutput})
+	d.cache.reset()
+	d.kind = CardUnknown
+	if d.detect != nil {
+		if !d.detect.present() {
+			return ErrCardRemoved
+		}
+		d.detect.removed = false
+	}
+	if err := d.setFrequency(initFrequency); err != nil {
+		return err
+	}
 	d.cs.High()
 
 	for i := range dummy {
@@ -91,13 +128,15 @@ func (d *Device) initCard() error {
 
 	// CMD0: init card; sould return _R1_IDLE_STATE (allow 5 attempts)
 	ok := false
-	tm := setTimeout(0, 2*time.Second)
+	tm := setTimeout(0, d.initTimeout())
 	for !tm.expired() {
-		// Wait up to 2 seconds to be the same as the Arduino
 		if d.cmd(CMD0_GO_IDLE_STATE, 0, 0x95) == _R1_IDLE_STATE {
 			ok = true
 			break
 		}
+		if err := d.canceled(); err != nil {
+			return err
+		}
 	}
 	if !ok {
 		return fmt.Errorf("no SD card")
@@ -106,8 +145,8 @@ func (d *Device) initCard() error {
 	// CMD8: determine card version
 	r := d.cmd(CMD8_SEND_IF_COND, 0x01AA, 0x87)
 	if (r & _R1_ILLEGAL_COMMAND) == _R1_ILLEGAL_COMMAND {
+		// Version 1 SD card, or MMC card.
 		d.sdCardType = SD_CARD_TYPE_SD1
-		return fmt.Errorf("init_card_v1 not impl\r\n")
 	} else {
 		// r7 response
 		status := byte(0)
@@ -143,12 +182,36 @@ func (d *Device) initCard() error {
 
 	// check for timeout
 	ok = false
-	tm = setTimeout(0, 2*time.Second)
+	d.kind = CardSD
+	tm = setTimeout(0, d.initTimeout())
 	for !tm.expired() {
-		if d.acmd(ACMD41_SD_APP_OP_COND, arg) == 0 {
+		r := d.acmd(ACMD41_SD_APP_OP_COND, arg)
+		if r == 0 {
 			ok = true
 			break
 		}
+		if d.sdCardType == SD_CARD_TYPE_SD1 && r&_R1_ILLEGAL_COMMAND != 0 {
+			// MMC cards do not know ACMD41, and are initialized with CMD1.
+			d.kind = CardMMC
+			break
+		}
+		if err := d.canceled(); err != nil {
+			return err
+		}
+	}
+	if d.kind == CardMMC {
+		for !tm.expired() {
+			if d.cmd(CMD1_SEND_OP_CND, 0, 0xFF) == 0 {
+				ok = true
+				break
+			}
+			if err := d.canceled(); err != nil {
+				return err
+			}
+		}
+		if !ok {
+			return fmt.Errorf("SD_CARD_ERROR_CMD1")
+		}
 	}
 
 	if !ok {
@@ -157,20 +220,29 @@ func (d *Device) initCard() error {
 
 	// if SD2 read OCR register to check for SDHC card
 	if d.sdCardType == SD_CARD_TYPE_SD2 {
-		if d.cmd(CMD58_READ_OCR, 0, 0xFF) != 0 {
-			return fmt.Errorf("SD_CARD_ERROR_CMD58")
+		ocr, err := d.ReadOCR()
+		if err != nil {
+			return err
 		}
-
-		status, err := d.bus.Transfer(byte(0xFF))
+		if ocr.PowerUp() && ocr.CCS() {
+			d.sdCardType = SD_CARD_TYPE_SDHC
+		}
+	}
+	// MMC cards larger than 2GB are addressed in blocks, like SDHC cards.
+	if d.kind == CardMMC {
+		ocr, err := d.ReadOCR()
 		if err != nil {
 			return err
 		}
-		if (status & 0xC0) == 0xC0 {
+		if ocr.mmcSectorMode() {
 			d.sdCardType = SD_CARD_TYPE_SDHC
 		}
-		// discard rest of ocr - contains allowed voltage range
-		for i := 1; i < 4; i++ {
-			d.bus.Transfer(byte(0xFF))
+	}
+
+	// CMD0 turned the CRC protection off.
+	if d.crcEnabled {
+		if err := d.EnableCRC(true); err != nil {
+			return err
 		}
 	}
 
@@ -192,19 +264,33 @@ func (d *Device) initCard() error {
 		return err
 	}
 	d.CSD = NewCSD(buf[:])
+	if d.CSD.CSD_STRUCTURE == 0x02 {
+		d.sdCardType = SD_CARD_TYPE_SDUC
+	}
+	d.sectors = 0
+	if d.kind == CardMMC {
+		d.CSD = NewMMCCSD(buf[:])
+		if d.CSD.C_SIZE == 0xFFF {
+			if err := d.readExtCSD(); err != nil {
+				return err
+			}
+		}
+	}
 
 	d.cs.High()
 
-	d.bus.Configure(machine.SPIConfig{
-		SCK:       d.sck,
-		SDO:       d.sdo,
-		SDI:       d.sdi,
-		Frequency: 4000000,
-		LSBFirst:  false,
-		Mode:      0, // phase=0, polarity=0
-	})
+	hz := d.CSD.TransferSpeed()
+	if d.maxFreq != 0 && hz > d.maxFreq {
+		hz = d.maxFreq
+	}
+	d.ResetStats()
+	return d.setFrequency(hz)
+}
 
-	return nil
+// blockAddressed returns whether the card is addressed in blocks instead of
+// bytes.
+func (d Device) blockAddressed() bool {
+	return d.sdCardType == SD_CARD_TYPE_SDHC || d.sdCardType == SD_CARD_TYPE_SDUC
 }
 
 func (d Device) acmd(cmd byte, arg uint32) byte {
@@ -216,7 +302,7 @@ func (d Device) cmd(cmd byte, arg uint32, crc byte) byte {
 	d.cs.Low()
 
 	if cmd != 12 {
-		d.waitNotBusy(300 * time.Millisecond)
+		d.waitNotBusy(d.busyTimeout())
 	}
 
 	// create and send the command
@@ -227,7 +313,10 @@ func (d Device) cmd(cmd byte, arg uint32, crc byte) byte {
 	buf[3] = byte(arg >> 8)
 	buf[4] = byte(arg)
 	buf[5] = crc
-	d.bus.Tx(buf, nil)
+	if d.crcEnabled {
+		buf[5] = crc7(buf[:5])<<1 | 1
+	}
+	err := d.bus.Tx(buf, nil)
 
 	if cmd == 12 {
 		// skip 1 byte
@@ -239,12 +328,25 @@ func (d Device) cmd(cmd byte, arg uint32, crc byte) byte {
 		d.bus.Tx([]byte{0xFF}, d.tokenbuf)
 		response := d.tokenbuf[0]
 		if (response & 0x80) == 0 {
+			if response&^_R1_IDLE_STATE != 0 {
+				d.stats.commandError()
+			}
+			if d.trace != nil {
+				d.trace(cmd, arg, Response1(response), err)
+			}
 			return response
 		}
 	}
 
 	// TODO
 	//// timeout
+	d.stats.timeout()
+	if d.trace != nil {
+		if err == nil {
+			err = errCmdTimeout
+		}
+		d.trace(cmd, arg, R1NoResponse, err)
+	}
 	d.cs.High()
 	d.bus.Transfer(byte(0xFF))
 
@@ -261,14 +363,19 @@ func (d Device) waitNotBusy(timeout time.Duration) error {
 		if r == 0xFF {
 			return nil
 		}
+		if err := d.canceled(); err != nil {
+			return err
+		}
+		drivers.Yield()
 	}
-	return nil
+	d.stats.timeout()
+	return errWriteTimeout
 }
 
 func (d Device) waitStartBlock() error {
 	status := byte(0xFF)
 
-	tm := setTimeout(0, 300*time.Millisecond)
+	tm := setTimeout(0, d.readTimeout())
 	for !tm.expired() {
 		var err error
 		status, err = d.bus.Transfer(byte(0xFF))
@@ -279,11 +386,22 @@ func (d Device) waitStartBlock() error {
 		if status != 0xFF {
 			break
 		}
+		if err := d.canceled(); err != nil {
+			d.cs.High()
+			return err
+		}
+		drivers.Yield()
 	}
 
-	if status != 254 {
+	if status == 0xFF {
+		d.stats.timeout()
 		d.cs.High()
-		return fmt.Errorf("SD_CARD_START_BLOCK")
+		return errReadTimeout
+	}
+	if status != 0xFE {
+		// A data error token.
+		d.cs.High()
+		return fmt.Errorf("SD_CARD_START_BLOCK %02X", status)
 	}
 
 	return nil
@@ -314,11 +432,10 @@ func (d Device) readRegister(cmd uint8, dst []byte) error {
 		}
 		dst[i] = r
 	}
-	d.bus.Transfer(byte(0xFF))
-	d.bus.Transfer(byte(0xFF))
+	err := d.readCRC(0, dst[:16])
 	d.cs.High()
 
-	return nil
+	return err
 }
 
 // ReadData reads 512 bytes from sdcard into dst.
@@ -326,16 +443,29 @@ func (d Device) ReadData(block uint32, dst []byte) error {
 	if len(dst) < 512 {
 		return fmt.Errorf("len(dst) must be greater than or equal to 512")
 	}
+	if err := d.checkPresent(); err != nil {
+		return err
+	}
+	for attempt := 0; ; attempt++ {
+		err := d.readData(block, dst)
+		if !d.retry(attempt, err) {
+			return err
+		}
+	}
+}
 
+func (d Device) readData(block uint32, dst []byte) error {
+	addr := block
 	// use address if not SDHC card
-	if d.sdCardType != SD_CARD_TYPE_SDHC {
-		block <<= 9
+	if !d.blockAddressed() {
+		addr <<= 9
 	}
-	if d.cmd(CMD17_READ_SINGLE_BLOCK, block, 0xFF) != 0 {
-		return fmt.Errorf("CMD17 error")
+	if r := d.cmd(CMD17_READ_SINGLE_BLOCK, addr, 0xFF); r != 0 {
+		d.cs.High()
+		return commandError(r, "CMD17 error")
 	}
 	if err := d.waitStartBlock(); err != nil {
-		return fmt.Errorf("waitStartBlock()")
+		return err
 	}
 
 	err := d.bus.Tx(dummy[:512], dst)
@@ -343,24 +473,22 @@ func (d Device) ReadData(block uint32, dst []byte) error {
 		return err
 	}
 
-	// skip CRC (2byte)
-	d.bus.Transfer(byte(0xFF))
-	d.bus.Transfer(byte(0xFF))
+	err = d.readCRC(block, dst[:512])
 
 	// TODO: probably not necessary
 	d.cs.High()
 
-	return nil
+	return err
 }
 
 // WriteMultiStart starts the continuous write mode using CMD25.
 func (d Device) WriteMultiStart(block uint32) error {
 	// use address if not SDHC card
-	if d.sdCardType != SD_CARD_TYPE_SDHC {
+	if !d.blockAddressed() {
 		block <<= 9
 	}
-	if d.cmd(CMD25_WRITE_MULTIPLE_BLOCK, block, 0xFF) != 0 {
-		return fmt.Errorf("CMD25 error")
+	if r := d.cmd(CMD25_WRITE_MULTIPLE_BLOCK, block, 0xFF); r != 0 {
+		return commandError(r, "CMD25 error")
 	}
 
 	// skip 1 byte
@@ -375,30 +503,25 @@ func (d Device) WriteMulti(buf []byte) error {
 	// send Data Token for CMD25
 	d.bus.Transfer(byte(0xFC))
 
-	for i := 0; i < 512; i++ {
-		_, err := d.bus.Transfer(buf[i])
-		if err != nil {
-			return err
-		}
+	err := d.bus.Tx(buf[:512], nil)
+	if err != nil {
+		return err
 	}
 
-	// send dummy CRC (2 byte)
-	d.bus.Transfer(byte(0xFF))
-	d.bus.Transfer(byte(0xFF))
+	crc := d.writeCRC(buf[:512])
 
 	// Data Resp.
-	r, err := d.bus.Transfer(byte(0xFF))
-	if err != nil {
+	if err := d.dataResponse(0, crc); err != nil {
 		return err
 	}
-	if (r & 0x1F) != 0x05 {
-		return fmt.Errorf("SD_CARD_ERROR_WRITE")
-	}
 
 	// wait no busy
-	err = d.waitNotBusy(600 * time.Millisecond)
+	err = d.waitNotBusy(d.writeTimeout())
 	if err != nil {
-		return fmt.Errorf("SD_CARD_ERROR_WRITE_TIMEOUT")
+		if isContextError(err) {
+			return err
+		}
+		return errWriteTimeout
 	}
 
 	return nil
@@ -414,7 +537,7 @@ func (d Device) WriteMultiStop() error {
 	// skip 1 byte
 	d.bus.Transfer(byte(0xFF))
 
-	err := d.waitNotBusy(600 * time.Millisecond)
+	err := d.waitNotBusy(d.writeTimeout())
 	if err != nil {
 		return nil
 	}
@@ -422,18 +545,89 @@ func (d Device) WriteMultiStop() error {
 	return nil
 }
 
+// SetPreErase enables the ACMD23 pre-erase hint for WriteBlocks. It tells
+// the card how many blocks are about to be written, which lets some cards
+// erase them in advance and write faster.
+func (d *Device) SetPreErase(enable bool) {
+	d.preErase = enable
+}
+
+// WriteBlocks writes src, which must be a multiple of 512 bytes long, to
+// consecutive blocks starting at startBlock using a single CMD25 multi-block
+// write. This is several times faster than writing each block with
+// WriteData.
+func (d Device) WriteBlocks(startBlock int64, src []byte) error {
+	if len(src) == 0 || len(src)%512 != 0 {
+		return fmt.Errorf("len(src) must be a multiple of 512")
+	}
+	if err := d.checkPresent(); err != nil {
+		return err
+	}
+	first := startBlock
+	for attempt := 0; ; attempt++ {
+		n, err := d.writeBlocks(startBlock, src)
+		// The blocks before the failed one were written.
+		startBlock += int64(n)
+		src = src[n*512:]
+		if !d.retry(attempt, err) {
+			if err != nil && d.verify {
+				return &PartialWriteError{Start: uint32(first), Written: uint32(startBlock - first), Err: err}
+			}
+			return err
+		}
+	}
+}
+
+// writeBlocks writes the blocks of src, and returns the number of blocks
+// written.
+func (d Device) writeBlocks(startBlock int64, src []byte) (int, error) {
+	count := uint32(len(src) / 512)
+	if d.preErase {
+		// The hint is optional, so a card rejecting it is not an error.
+		d.acmd(ACMD23_SET_WR_BLK_ERASE_COUNT, count&0x7FFFFF)
+	}
+
+	if err := d.WriteMultiStart(uint32(startBlock)); err != nil {
+		d.cs.High()
+		return 0, err
+	}
+	for i := uint32(0); i < count; i++ {
+		if err := d.WriteMulti(src[i*512 : (i+1)*512]); err != nil {
+			if e, ok := err.(*CRCError); ok {
+				e.Block = uint32(startBlock) + i
+			}
+			d.WriteMultiStop()
+			return d.verifyWrite(int(i), err)
+		}
+	}
+	return d.verifyWrite(int(count), d.WriteMultiStop())
+}
+
 // WriteData writes 512 bytes from dst to sdcard.
 func (d Device) WriteData(block uint32, src []byte) error {
 	if len(src) < 512 {
 		return fmt.Errorf("len(src) must be greater than or equal to 512")
 	}
+	if err := d.checkPresent(); err != nil {
+		return err
+	}
+	for attempt := 0; ; attempt++ {
+		err := d.writeData(block, src)
+		if !d.retry(attempt, err) {
+			return err
+		}
+	}
+}
 
+func (d Device) writeData(block uint32, src []byte) error {
+	addr := block
 	// use address if not SDHC card
-	if d.sdCardType != SD_CARD_TYPE_SDHC {
-		block <<= 9
+	if !d.blockAddressed() {
+		addr <<= 9
 	}
-	if d.cmd(CMD24_WRITE_BLOCK, block, 0xFF) != 0 {
-		return fmt.Errorf("CMD24 error")
+	if r := d.cmd(CMD24_WRITE_BLOCK, addr, 0xFF); r != 0 {
+		d.cs.High()
+		return commandError(r, "CMD24 error")
 	}
 
 	// wait 1 byte?
@@ -445,23 +639,21 @@ func (d Device) WriteData(block uint32, src []byte) error {
 		return err
 	}
 
-	// send dummy CRC (2 byte)
-	d.bus.Transfer(byte(0xFF))
-	d.bus.Transfer(byte(0xFF))
+	crc := d.writeCRC(src[:512])
 
 	// Data Resp.
-	r, err := d.bus.Transfer(byte(0xFF))
-	if err != nil {
-		return err
-	}
-	if (r & 0x1F) != 0x05 {
-		return fmt.Errorf("SD_CARD_ERROR_WRITE")
+	if err := d.dataResponse(block, crc); err != nil {
+		return d.writeError(err)
 	}
 
 	// wait no busy
-	err = d.waitNotBusy(600 * time.Millisecond)
+	err = d.waitNotBusy(d.writeTimeout())
 	if err != nil {
-		return fmt.Errorf("SD_CARD_ERROR_WRITE_TIMEOUT")
+		d.cs.High()
+		if isContextError(err) {
+			return err
+		}
+		return errWriteTimeout
 	}
 
 	// TODO: probably not necessary
@@ -469,153 +661,31 @@ func (d Device) WriteData(block uint32, src []byte) error {
 	return nil
 }
 
-// ReadAt reads the given number of bytes from the sdcard.
-func (dev *Device) ReadAt(buf []byte, addr int64) (int, error) {
-	block := uint64(addr)
-	// use address if not SDHC card
-	if dev.sdCardType == SD_CARD_TYPE_SDHC {
-		block >>= 9
-	}
-
-	idx := uint32(0)
-
-	start := uint32(addr % 512)
-	end := uint32(0)
-	remain := uint32(len(buf))
-
-	// If data starts in the middle
-	if 0 < start {
-		if start+remain <= 512 {
-			end = start + remain
-		} else {
-			end = 512
-		}
-
-		err := dev.ReadData(uint32(block), dev.dummybuf)
-		if err != nil {
-			return 0, err
-		}
-		copy(buf[idx:], dev.dummybuf[start:end])
-
-		remain -= end - start
-		idx += end - start
-		block++
-	}
-
-	// If more than 512 bytes left
-	for 512 <= remain {
-		start = 0
-		end = 512
-
-		err := dev.ReadData(uint32(block), dev.dummybuf)
-		if err != nil {
-			return 0, err
-		}
-		copy(buf[idx:], dev.dummybuf[start:end])
-
-		remain -= end - start
-		idx += end - start
-		block++
-	}
+// The methods below implement the BlockDevice interface of tinyfs, so that
+// a Device can be used directly by its FAT and littlefs file systems.
 
-	// Read to the end
-	if 0 < remain {
-		start = 0
-		end = remain
-
-		err := dev.ReadData(uint32(block), dev.dummybuf)
-		if err != nil {
-			return 0, err
-		}
-		copy(buf[idx:], dev.dummybuf[start:end])
-
-		remain -= end - start
-		idx += end - start
-		block++
-	}
-
-	return int(idx), nil
+// ReadAt reads the given number of bytes from the sdcard. The data may start
+// and end anywhere; partial blocks are read through the block cache.
+func (dev *Device) ReadAt(buf []byte, addr int64) (int, error) {
+	return dev.cache.readAt(dev, buf, addr)
 }
 
-// WriteAt writes the given number of bytes to sdcard.
+// WriteAt writes the given number of bytes to sdcard. Whole blocks are
+// written directly. Partial blocks are changed in the block cache, which is
+// only written back by Sync or when a different block is cached, so that
+// patching a few bytes at a time is cheap.
 func (dev *Device) WriteAt(buf []byte, addr int64) (n int, err error) {
-	block := uint64(addr)
-	// use address if not SDHC card
-	if dev.sdCardType == SD_CARD_TYPE_SDHC {
-		block >>= 9
-	}
-
-	idx := uint32(0)
-
-	start := uint32(addr % 512)
-	end := uint32(0)
-	remain := uint32(len(buf))
-
-	// If data starts in the middle
-	if 0 < start {
-		if start+remain <= 512 {
-			end = start + remain
-		} else {
-			end = 512
-		}
-
-		err := dev.ReadData(uint32(block), dev.dummybuf)
-		if err != nil {
-			return 0, err
-		}
-		copy(dev.dummybuf[start:end], buf[idx:])
-
-		err = dev.WriteData(uint32(block), dev.dummybuf)
-		if err != nil {
-			return 0, err
-		}
-
-		remain -= end - start
-		idx += end - start
-		block++
-	}
-
-	// If more than 512 bytes left
-	for 512 <= remain {
-		start = 0
-		end = 512
-
-		err := dev.WriteData(uint32(block), buf[idx:idx+512])
-		if err != nil {
-			return 0, err
-		}
-
-		remain -= end - start
-		idx += end - start
-		block++
-	}
-
-	// Write to the end
-	if 0 < remain {
-		start = 0
-		end = remain
-
-		err := dev.ReadData(uint32(block), dev.dummybuf)
-		if err != nil {
-			return 0, err
-		}
-		copy(dev.dummybuf[start:end], buf[idx:])
-
-		err = dev.WriteData(uint32(block), dev.dummybuf)
-		if err != nil {
-			return 0, err
-		}
-
-		remain -= end - start
-		idx += end - start
-		block++
-	}
-
-	return int(idx), nil
+	return dev.cache.writeAt(dev, buf, addr)
 }
 
 // Size returns the number of bytes in this sdcard.
 func (dev *Device) Size() int64 {
+	if dev.CSD == nil {
+		return 0
+	}
+	if dev.sectors != 0 {
+		return int64(dev.sectors) * 512
+	}
 	return int64(dev.CSD.Size())
 }
 
@@ -632,16 +702,9 @@ func (dev *Device) EraseBlockSize() int64 {
 
 // EraseBlocks erases the given number of blocks.
 func (dev *Device) EraseBlocks(start, len int64) error {
-	dev.WriteMultiStart(uint32(start))
-
-	for i := range dev.dummybuf {
-		dev.dummybuf[i] = 0
-	}
-
-	for i := 0; i < int(len); i++ {
-		dev.WriteMulti(dev.dummybuf)
+	if len <= 0 {
+		return nil
 	}
-
-	dev.WriteMultiStop()
-	return nil
+	dev.cache.drop(start, start+len)
+	return dev.Erase(start, start+len-1)
 }
@@ -0,0 +1,485 @@
+package sdcard
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+var (
+	errSDIOBlock = errors.New("sdcard: data length must be a multiple of 512")
+	errSDIORange = errors.New("sdcard: block out of range")
+)
+
+// SDIOResponse is the type of response a command expects on the CMD line of
+// the native SD bus.
+type SDIOResponse uint8
+
+const (
+	// SDIONoResponse is for CMD0, which has no response.
+	SDIONoResponse SDIOResponse = iota
+
+	// SDIOResponseR1 is a 48-bit response with a CRC7: R1, R6 and R7.
+	SDIOResponseR1
+
+	// SDIOResponseR1b is an R1 response after which the card holds DAT0 low
+	// while it is busy.
+	SDIOResponseR1b
+
+	// SDIOResponseR2 is a 136-bit response holding the CID or the CSD.
+	SDIOResponseR2
+
+	// SDIOResponseR3 is a 48-bit response holding the OCR, without a valid
+	// CRC7.
+	SDIOResponseR3
+)
+
+// SDIORequest is a command sent by an SDIOHost, with an optional data
+// transfer on the DAT lines.
+type SDIORequest struct {
+	Cmd      uint8
+	Arg      uint32
+	Response SDIOResponse
+
+	// Resp is set by the host to the response: Resp[0] holds the 32 bits
+	// between the command index and the CRC of 48-bit responses, Resp[0] to
+	// Resp[3] hold bits 127 to 0 of 136-bit responses.
+	Resp [4]uint32
+
+	// Data is read from the card, or written to it when Write is set, in
+	// blocks of 512 bytes after the command, or in a single shorter block
+	// for CMD30, CMD42 and ACMD22. No data is transferred when it is empty.
+	Data  []byte
+	Write bool
+
+	// BusyTimeout is the longest time the host waits for the card to release
+	// DAT0 after an R1b response or a written block.
+	BusyTimeout time.Duration
+}
+
+// SDIOHost is a host controller of the native SD bus, such as the SDMMC
+// peripheral of STM32 chips or a PIO program on the RP2040. It handles the
+// framing of the bus: start and end bits, CRC7 of the commands and
+// responses, CRC16 of the data blocks on every DAT line and the CRC status
+// of written blocks, and reports their errors.
+type SDIOHost interface {
+	// SetClock sets the clock of the bus in Hz.
+	SetClock(hz uint32) error
+
+	// SetBusWidth sets the number of DAT lines used for data, 1 or 4.
+	SetBusWidth(width int) error
+
+	// Do sends a command, receives its response and transfers its data.
+	Do(req *SDIORequest) error
+}
+
+// SDIOCard is a card on the native SD bus in 4-bit mode, which is several
+// times faster than the SPI mode of Device. It has the same block device
+// methods.
+type SDIOCard struct {
+	host    SDIOHost
+	rca     uint32 // relative card address, in the upper 16 bits
+	hc      bool   // high capacity card, addressed in blocks
+	uc      bool   // ultra capacity card, with 38-bit block addresses
+	kind    CardKind
+	sectors uint32 // from the extended CSD of large MMC cards
+	maxFreq uint32
+	verify  bool     // check multiple block writes with ACMD22
+	csd     [16]byte // as read, for WriteCSD
+	buf     [512]byte
+	cache   blockCache
+	ctx     context.Context // of the operation in progress, see ctx.go
+	CID     *CID
+	CSD     *CSD
+}
+
+// NewSDIO returns a card on the native SD bus of a host controller.
+//
+// This function only creates the SDIOCard object, it does not touch the
+// device.
+func NewSDIO(host SDIOHost) *SDIOCard {
+	c := &SDIOCard{host: host}
+	c.cache.buf = c.buf[:]
+	return c
+}
+
+// SetMaxFrequency limits the bus clock used after the initialization, which
+// is otherwise the maximum of the card (usually 25MHz). It takes effect at
+// the next Configure.
+func (c *SDIOCard) SetMaxFrequency(hz uint32) {
+	c.maxFreq = hz
+}
+
+// Configure initializes the card, and switches the bus to 4-bit mode at the
+// card speed.
+func (c *SDIOCard) Configure() error {
+	c.cache.reset()
+	c.kind = CardUnknown
+	c.hc, c.uc = false, false
+	if err := c.host.SetBusWidth(1); err != nil {
+		return err
+	}
+	if err := c.host.SetClock(initFrequency); err != nil {
+		return err
+	}
+
+	if _, err := c.cmd(CMD0_GO_IDLE_STATE, 0, SDIONoResponse); err != nil {
+		return err
+	}
+
+	// Version 1 SD cards and MMC cards do not answer CMD8, nor support high
+	// capacity.
+	arg := uint32(0x00FF8000)
+	r, err := c.cmd(CMD8_SEND_IF_COND, 0x01AA, SDIOResponseR1)
+	v2 := err == nil
+	if v2 {
+		if r&0xFFF != 0x1AA {
+			return fmt.Errorf("SD_CARD_ERROR_CMD8 %08X", r)
+		}
+		// High capacity, and SDUC support.
+		arg |= 0x40000000 | 1<<27
+	}
+
+	ok := false
+	c.kind = CardSD
+	tm := setTimeout(0, 2*time.Second)
+	for !tm.expired() {
+		r, err = c.acmd(ACMD41_SD_APP_OP_COND, arg, SDIOResponseR3)
+		if err != nil && !v2 {
+			// MMC cards do not answer CMD55, and are initialized with CMD1.
+			c.kind = CardMMC
+			break
+		}
+		if err != nil {
+			return err
+		}
+		if OCR(r).PowerUp() {
+			c.hc = OCR(r).CCS()
+			c.uc = c.hc && OCR(r).CO2T()
+			ok = true
+			break
+		}
+		if err := canceled(c.ctx); err != nil {
+			return err
+		}
+	}
+	if c.kind == CardMMC {
+		if _, err := c.cmd(CMD0_GO_IDLE_STATE, 0, SDIONoResponse); err != nil {
+			return err
+		}
+		for !tm.expired() {
+			// Voltage window and sector mode, for cards larger than 2GB.
+			r, err = c.cmd(CMD1_SEND_OP_CND, 0x40FF8000, SDIOResponseR3)
+			if err != nil {
+				return err
+			}
+			if OCR(r).PowerUp() {
+				c.hc = OCR(r).mmcSectorMode()
+				ok = true
+				break
+			}
+			if err := canceled(c.ctx); err != nil {
+				return err
+			}
+		}
+		if !ok {
+			return fmt.Errorf("SD_CARD_ERROR_CMD1")
+		}
+	}
+	if !ok {
+		return fmt.Errorf("SD_CARD_ERROR_ACMD41")
+	}
+
+	req := SDIORequest{Cmd: CMD2_ALL_SEND_CID, Response: SDIOResponseR2}
+	if err := c.host.Do(&req); err != nil {
+		return err
+	}
+	var buf [16]byte
+	c.CID = NewCID(longResponse(&req, buf[:]))
+
+	// SD cards choose their address, MMC cards are given one.
+	if c.kind == CardMMC {
+		c.rca = 1 << 16
+		if _, err := c.cmd(CMD3_SEND_RELATIVE_ADDR, c.rca, SDIOResponseR1); err != nil {
+			return err
+		}
+	} else {
+		if r, err = c.cmd(CMD3_SEND_RELATIVE_ADDR, 0, SDIOResponseR1); err != nil {
+			return err
+		}
+		c.rca = r &^ 0xFFFF
+	}
+
+	req = SDIORequest{Cmd: CMD9_SEND_CSD, Arg: c.rca, Response: SDIOResponseR2}
+	if err := c.host.Do(&req); err != nil {
+		return err
+	}
+	c.CSD = NewCSD(longResponse(&req, c.csd[:]))
+	c.sectors = 0
+	if c.kind == CardMMC {
+		c.CSD = NewMMCCSD(c.csd[:])
+	}
+
+	if _, err := c.cmd(CMD7_SELECT_DESELECT_CARD, c.rca, SDIOResponseR1b); err != nil {
+		return err
+	}
+	if !c.hc {
+		if _, err := c.cmd(CMD16_SET_BLOCKLEN, 512, SDIOResponseR1); err != nil {
+			return err
+		}
+	}
+	if c.kind == CardMMC {
+		if c.CSD.C_SIZE == 0xFFF {
+			req = SDIORequest{Cmd: CMD8_SEND_EXT_CSD, Response: SDIOResponseR1, Data: c.buf[:]}
+			if err := c.do(&req); err != nil {
+				return err
+			}
+			c.sectors = extCSDSectors(c.buf[:])
+		}
+		// Write 1 (4-bit) to BUS_WIDTH, byte 183 of the extended CSD.
+		if _, err := c.cmd(CMD6_SWITCH_FUNC, 0x03B70100, SDIOResponseR1b); err != nil {
+			return err
+		}
+	} else if _, err := c.acmd(ACMD6_SET_BUS_WIDTH, 2, SDIOResponseR1); err != nil {
+		return err
+	}
+	if err := c.host.SetBusWidth(4); err != nil {
+		return err
+	}
+
+	hz := c.CSD.TransferSpeed()
+	if c.maxFreq != 0 && hz > c.maxFreq {
+		hz = c.maxFreq
+	}
+	return c.host.SetClock(hz)
+}
+
+// longResponse returns the 16 bytes of a 136-bit response, in the order the
+// SPI mode reads registers.
+func longResponse(req *SDIORequest, buf []byte) []byte {
+	for i, w := range req.Resp {
+		buf[4*i] = byte(w >> 24)
+		buf[4*i+1] = byte(w >> 16)
+		buf[4*i+2] = byte(w >> 8)
+		buf[4*i+3] = byte(w)
+	}
+	return buf
+}
+
+// cmd sends a command without data, and returns its response. It fails if
+// the card status of an R1 response reports an error.
+func (c *SDIOCard) cmd(cmd uint8, arg uint32, resp SDIOResponse) (uint32, error) {
+	req := SDIORequest{Cmd: cmd, Arg: arg, Response: resp, BusyTimeout: 250 * time.Millisecond}
+	err := c.do(&req)
+	return req.Resp[0], err
+}
+
+// acmd sends an application specific command.
+func (c *SDIOCard) acmd(cmd uint8, arg uint32, resp SDIOResponse) (uint32, error) {
+	if _, err := c.cmd(CMD55_APP_CMD, c.rca, SDIOResponseR1); err != nil {
+		return 0, err
+	}
+	req := SDIORequest{Cmd: cmd, Arg: arg, Response: resp}
+	err := c.do(&req)
+	return req.Resp[0], err
+}
+
+// do runs a request, and checks the card status of R1 responses.
+func (c *SDIOCard) do(req *SDIORequest) error {
+	if err := c.host.Do(req); err != nil {
+		return err
+	}
+	if req.Response != SDIOResponseR1 && req.Response != SDIOResponseR1b {
+		return nil
+	}
+	// R6 and R7 responses of CMD3 and CMD8 have no card status, except on
+	// MMC cards.
+	if c.kind != CardMMC && (req.Cmd == CMD3_SEND_RELATIVE_ADDR || req.Cmd == CMD8_SEND_IF_COND) {
+		return nil
+	}
+	if status := CardStatus(req.Resp[0]); status&StatusErrors != 0 {
+		return &CardStatusError{Cmd: req.Cmd, Status: status}
+	}
+	return nil
+}
+
+// Kind returns the family of the card.
+func (c *SDIOCard) Kind() CardKind {
+	return c.kind
+}
+
+// address returns the argument of a data command for block. The upper bits
+// of the block addresses of SDUC cards are sent before with CMD22, which
+// must precede every data and erase command.
+func (c *SDIOCard) address(block int64) (uint32, error) {
+	switch {
+	case block < 0 || block >= 1<<38:
+		return 0, errSDIORange
+	case c.uc:
+		if _, err := c.cmd(CMD22_ADDRESS_EXTENSION, uint32(block>>32), SDIOResponseR1); err != nil {
+			return 0, err
+		}
+		return uint32(block), nil
+	case block >= 1<<32:
+		return 0, errSDIORange
+	case c.hc:
+		return uint32(block), nil
+	}
+	return uint32(block) << 9, nil
+}
+
+// ReadData reads 512 bytes from sdcard into dst.
+func (c *SDIOCard) ReadData(block uint32, dst []byte) error {
+	if len(dst) < 512 {
+		return fmt.Errorf("len(dst) must be greater than or equal to 512")
+	}
+	return c.ReadBlocks(int64(block), dst[:512])
+}
+
+// ReadBlocks reads dst, which must be a multiple of 512 bytes long, from
+// consecutive blocks starting at startBlock, using CMD18 for more than one
+// block.
+func (c *SDIOCard) ReadBlocks(startBlock int64, dst []byte) error {
+	return c.transfer(startBlock, dst, false)
+}
+
+// WriteData writes 512 bytes from src to sdcard.
+func (c *SDIOCard) WriteData(block uint32, src []byte) error {
+	if len(src) < 512 {
+		return fmt.Errorf("len(src) must be greater than or equal to 512")
+	}
+	return c.WriteBlocks(int64(block), src[:512])
+}
+
+// WriteBlocks writes src, which must be a multiple of 512 bytes long, to
+// consecutive blocks starting at startBlock, using CMD25 for more than one
+// block.
+func (c *SDIOCard) WriteBlocks(startBlock int64, src []byte) error {
+	err := c.transfer(startBlock, src, true)
+	if !c.verify || len(src) <= 512 || err == errSDIOBlock || err == errSDIORange {
+		return err
+	}
+	return c.verifyWrite(startBlock, len(src)/512, err)
+}
+
+func (c *SDIOCard) transfer(startBlock int64, data []byte, write bool) error {
+	if len(data) == 0 || len(data)%512 != 0 {
+		return errSDIOBlock
+	}
+	multi := len(data) > 512
+	addr, err := c.address(startBlock)
+	if err != nil {
+		return err
+	}
+	req := SDIORequest{
+		Arg:         addr,
+		Response:    SDIOResponseR1,
+		Data:        data,
+		Write:       write,
+		BusyTimeout: 250 * time.Millisecond,
+	}
+	switch {
+	case write && multi:
+		req.Cmd = CMD25_WRITE_MULTIPLE_BLOCK
+	case write:
+		req.Cmd = CMD24_WRITE_BLOCK
+	case multi:
+		req.Cmd = CMD18_READ_MULTIPLE_BLOCK
+	default:
+		req.Cmd = CMD17_READ_SINGLE_BLOCK
+	}
+	err = c.do(&req)
+	if multi {
+		// Stop the transfer even if it failed, so that the card is ready
+		// for the next command.
+		if _, stopErr := c.cmd(CMD12_STOP_TRANSMISSION, 0, SDIOResponseR1b); err == nil {
+			err = stopErr
+		}
+	}
+	return err
+}
+
+// Erase erases the blocks from startBlock to endBlock, both included, using
+// CMD32, CMD33 and CMD38.
+func (c *SDIOCard) Erase(startBlock, endBlock int64) error {
+	if endBlock < startBlock {
+		return fmt.Errorf("endBlock must not be lower than startBlock")
+	}
+	start, err := c.address(startBlock)
+	if err != nil {
+		return err
+	}
+	if _, err := c.cmd(CMD32_ERASE_WR_BLK_START_ADDR, start, SDIOResponseR1); err != nil {
+		return err
+	}
+	end, err := c.address(endBlock)
+	if err != nil {
+		return err
+	}
+	if _, err := c.cmd(CMD33_ERASE_WR_BLK_END_ADDR, end, SDIOResponseR1); err != nil {
+		return err
+	}
+	timeout := 250 * time.Millisecond * time.Duration(endBlock-startBlock+1)
+	if timeout < time.Second {
+		timeout = time.Second
+	}
+	req := SDIORequest{Cmd: CMD38_ERASE, Response: SDIOResponseR1b, BusyTimeout: timeout}
+	return c.do(&req)
+}
+
+// ReadAt reads the given number of bytes from the card. The data may start
+// and end anywhere; partial blocks are read through the block cache.
+func (c *SDIOCard) ReadAt(buf []byte, addr int64) (int, error) {
+	return c.cache.readAt(c, buf, addr)
+}
+
+// WriteAt writes the given number of bytes to the card. Partial blocks are
+// changed in the block cache, and written back by Sync.
+func (c *SDIOCard) WriteAt(buf []byte, addr int64) (int, error) {
+	return c.cache.writeAt(c, buf, addr)
+}
+
+func (c *SDIOCard) readBlock(block int64, dst []byte) error {
+	return c.ReadBlocks(block, dst[:512])
+}
+
+func (c *SDIOCard) writeBlock(block int64, src []byte) error {
+	return c.WriteBlocks(block, src[:512])
+}
+
+// Sync writes the block cached by an unaligned WriteAt back to the card.
+func (c *SDIOCard) Sync() error {
+	return c.cache.sync(c)
+}
+
+// Size returns the number of bytes in this card.
+func (c *SDIOCard) Size() int64 {
+	if c.CSD == nil {
+		return 0
+	}
+	if c.sectors != 0 {
+		return int64(c.sectors) * 512
+	}
+	return int64(c.CSD.Size())
+}
+
+// WriteBlockSize returns the block size in which data can be written to
+// memory.
+func (c *SDIOCard) WriteBlockSize() int64 {
+	return 512
+}
+
+// EraseBlockSize returns the smallest erasable area on this card in bytes.
+func (c *SDIOCard) EraseBlockSize() int64 {
+	return 512
+}
+
+// EraseBlocks erases the given number of blocks.
+func (c *SDIOCard) EraseBlocks(start, len int64) error {
+	if len <= 0 {
+		return nil
+	}
+	c.cache.drop(start, start+len)
+	return c.Erase(start, start+len-1)
+}
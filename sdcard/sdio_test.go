@@ -0,0 +1,229 @@
+package sdcard
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+)
+
+var errNoResponse = errors.New("no response")
+
+// fakeHost is an SDHC card of 64 blocks on an SD bus, an eMMC chip of 64
+// blocks when mmc is set, or an 8TB SDUC card when uc is set.
+type fakeHost struct {
+	mmc   bool
+	uc    bool
+	stuck bool // never powers up
+	mem   []byte
+	width int
+	clock uint32
+	cmds  []uint8
+	args  []uint32
+
+	// CMD42 state, see lock_test.go.
+	blockLen   uint32
+	pwd        []byte
+	locked     bool
+	lockFailed bool
+
+	// Write protected groups by address, with groups of wpGroup blocks.
+	wp      map[uint32]bool
+	wpGroup uint32
+
+	// CSD programmed with CMD27, see progcsd_test.go.
+	csd          []byte
+	csdOverwrite bool
+
+	// ACMD22 state, see verify_test.go.
+	app       bool
+	written   uint32
+	unwritten uint32
+}
+
+func (h *fakeHost) SetClock(hz uint32) error    { h.clock = hz; return nil }
+func (h *fakeHost) SetBusWidth(width int) error { h.width = width; return nil }
+
+func (h *fakeHost) Do(req *SDIORequest) error {
+	h.cmds = append(h.cmds, req.Cmd)
+	h.args = append(h.args, req.Arg)
+	if h.mmc {
+		return h.doMMC(req)
+	}
+	app := h.app
+	h.app = req.Cmd == CMD55_APP_CMD
+	if app && req.Cmd == ACMD22_SEND_NUM_WR_BLOCKS {
+		n := h.written - h.unwritten
+		h.unwritten = 0
+		req.Data[0], req.Data[1], req.Data[2], req.Data[3] = byte(n>>24), byte(n>>16), byte(n>>8), byte(n)
+		return nil
+	}
+	switch req.Cmd {
+	case CMD8_SEND_IF_COND:
+		req.Resp[0] = req.Arg
+	case ACMD41_SD_APP_OP_COND:
+		req.Resp[0] = 0xC0FF8000 // powered up, high capacity
+		if h.stuck {
+			req.Resp[0] = 0x00FF8000
+		}
+		if h.uc && req.Arg&(1<<27) != 0 {
+			req.Resp[0] |= 1 << 27
+		}
+	case CMD3_SEND_RELATIVE_ADDR:
+		req.Resp[0] = 0x12340500
+	case CMD9_SEND_CSD:
+		// CSD version 2.0, 25MHz, C_SIZE 0.
+		req.Resp = [4]uint32{0x400E0032, 0x5B590000, 0x00007F80, 0x0A400001}
+		if h.uc {
+			// CSD version 3.0, C_SIZE 0xFFFFFF.
+			req.Resp = [4]uint32{0x800E0032, 0x5B5900FF, 0xFFFF7F80, 0x0A400001}
+		}
+	case CMD16_SET_BLOCKLEN:
+		h.blockLen = req.Arg
+	case CMD42_LOCK_UNLOCK:
+		h.lockFailed = !h.lock(req.Data)
+	case CMD28_SET_WRITE_PROT, CMD29_CLR_WRITE_PROT:
+		if h.wp == nil {
+			h.wp = map[uint32]bool{}
+		}
+		h.wp[req.Arg] = req.Cmd == CMD28_SET_WRITE_PROT
+	case CMD30_SEND_WRITE_PROT:
+		bits := uint32(0)
+		for i := uint32(0); i < 32; i++ {
+			if h.wp[req.Arg+i*h.wpGroup] {
+				bits |= 1 << i
+			}
+		}
+		req.Data[0], req.Data[1], req.Data[2], req.Data[3] = byte(bits>>24), byte(bits>>16), byte(bits>>8), byte(bits)
+	case CMD27_PROGRAM_CSD:
+		h.programCSD(req.Data)
+	case CMD13_SEND_STATUS:
+		if h.csdOverwrite {
+			req.Resp[0] |= 1 << 16
+			h.csdOverwrite = false
+		}
+		if h.locked {
+			req.Resp[0] |= 1 << 25
+		}
+		if h.lockFailed {
+			req.Resp[0] |= 1 << 24
+			h.lockFailed = false
+		}
+	case CMD17_READ_SINGLE_BLOCK, CMD18_READ_MULTIPLE_BLOCK:
+		if h.locked {
+			req.Resp[0] = 1 << 22 // illegal command
+			break
+		}
+		copy(req.Data, h.mem[req.Arg*512:])
+	case CMD24_WRITE_BLOCK, CMD25_WRITE_MULTIPLE_BLOCK:
+		copy(h.mem[req.Arg*512:], req.Data)
+		h.written = uint32(len(req.Data) / 512)
+	}
+	return nil
+}
+
+func (h *fakeHost) doMMC(req *SDIORequest) error {
+	switch req.Cmd {
+	case CMD8_SEND_EXT_CSD:
+		if req.Data == nil {
+			return errNoResponse
+		}
+		for i := range req.Data {
+			req.Data[i] = 0
+		}
+		req.Data[212] = 64 // SEC_COUNT
+	case CMD55_APP_CMD:
+		return errNoResponse
+	case CMD1_SEND_OP_CND:
+		req.Resp[0] = 0xC0FF8080 // powered up, sector mode
+	case CMD9_SEND_CSD:
+		// CSD version 1.2, 26MHz, C_SIZE 0xFFF: the size is in the EXT_CSD.
+		req.Resp = [4]uint32{0xD0270132, 0x0F5903FF, 0xFFFFFFFF, 0x92400000}
+	case CMD17_READ_SINGLE_BLOCK:
+		copy(req.Data, h.mem[req.Arg*512:])
+	}
+	return nil
+}
+
+func TestSDIOCard(t *testing.T) {
+	host := &fakeHost{mem: make([]byte, 64*512)}
+	card := NewSDIO(host)
+	card.SetMaxFrequency(20000000)
+	if err := card.Configure(); err != nil {
+		t.Fatal(err)
+	}
+	if host.width != 4 || host.clock != 20000000 {
+		t.Errorf("bus: %d bits at %dHz", host.width, host.clock)
+	}
+	if card.rca != 0x12340000 || !card.hc {
+		t.Errorf("rca %08X, high capacity %v", card.rca, card.hc)
+	}
+	if got, want := card.Size(), int64(512*1024); got != want {
+		t.Errorf("Size: got %d, want %d", got, want)
+	}
+
+	host.cmds = nil
+	data := bytes.Repeat([]byte{1, 2, 3, 4}, 256)
+	if err := card.WriteBlocks(3, data); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(host.mem[3*512:5*512], data) {
+		t.Error("WriteBlocks did not write the blocks")
+	}
+	buf := make([]byte, 6)
+	if _, err := card.ReadAt(buf, 3*512+510); err != nil || !bytes.Equal(buf, []byte{3, 4, 1, 2, 3, 4}) {
+		t.Errorf("ReadAt: %v, %v", buf, err)
+	}
+	want := []uint8{CMD25_WRITE_MULTIPLE_BLOCK, CMD12_STOP_TRANSMISSION, CMD17_READ_SINGLE_BLOCK, CMD17_READ_SINGLE_BLOCK}
+	if !bytes.Equal(host.cmds, want) {
+		t.Errorf("commands: got %v, want %v", host.cmds, want)
+	}
+}
+
+func TestSDIOCardMMC(t *testing.T) {
+	host := &fakeHost{mmc: true, mem: make([]byte, 64*512)}
+	card := NewSDIO(host)
+	if err := card.Configure(); err != nil {
+		t.Fatal(err)
+	}
+	if card.Kind() != CardMMC || !card.hc || card.rca != 1<<16 {
+		t.Errorf("kind %v, high capacity %v, rca %08X", card.Kind(), card.hc, card.rca)
+	}
+	if host.width != 4 || host.clock != 26000000 {
+		t.Errorf("bus: %d bits at %dHz", host.width, host.clock)
+	}
+	if got, want := card.Size(), int64(64*512); got != want {
+		t.Errorf("Size: got %d, want %d", got, want)
+	}
+
+	host.mem[5*512] = 42
+	buf := make([]byte, 512)
+	if err := card.ReadData(5, buf); err != nil || buf[0] != 42 {
+		t.Errorf("ReadData: %d, %v", buf[0], err)
+	}
+}
+
+func TestSDIOCardSDUC(t *testing.T) {
+	host := &fakeHost{uc: true, mem: make([]byte, 64*512)}
+	card := NewSDIO(host)
+	if err := card.Configure(); err != nil {
+		t.Fatal(err)
+	}
+	if !card.uc {
+		t.Error("not detected as SDUC")
+	}
+	if got, want := card.Size(), int64(8)<<40; got != want {
+		t.Errorf("Size: got %d, want %d", got, want)
+	}
+
+	host.cmds, host.args = nil, nil
+	buf := make([]byte, 512)
+	if err := card.ReadBlocks(1<<32+5, buf); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(host.cmds, []uint8{CMD22_ADDRESS_EXTENSION, CMD17_READ_SINGLE_BLOCK}) || host.args[0] != 1 || host.args[1] != 5 {
+		t.Errorf("commands %v, arguments %v", host.cmds, host.args)
+	}
+	if err := card.ReadBlocks(1<<38, buf); err != errSDIORange {
+		t.Errorf("past 38 bits: %v", err)
+	}
+}
@@ -0,0 +1,131 @@
+package sdcard
+
+import (
+	"fmt"
+	"time"
+)
+
+// SDStatus is the 512-bit SD Status register, read with ReadSDStatus. Bit
+// 511 is the MSB of the first byte.
+type SDStatus [64]byte
+
+// auSizes are the allocation unit sizes in KiB for the AU_SIZE and
+// UHS_AU_SIZE fields.
+var auSizes = [16]uint32{0, 16, 32, 64, 128, 256, 512, 1024, 2048, 4096, 8192, 12288, 16384, 24576, 32768, 65536}
+
+// ReadSDStatus reads the SD Status register using ACMD13.
+func (d Device) ReadSDStatus() (SDStatus, error) {
+	var s SDStatus
+	if d.acmd(ACMD13_SD_STATUS, 0) != 0 {
+		d.cs.High()
+		return s, fmt.Errorf("SD_CARD_ERROR_ACMD13")
+	}
+	// second byte of the R2 response
+	d.bus.Transfer(byte(0xFF))
+	if err := d.waitStartBlock(); err != nil {
+		return s, err
+	}
+	if err := d.bus.Tx(dummy[:len(s)], s[:]); err != nil {
+		d.cs.High()
+		return s, err
+	}
+	err := d.readCRC(0, s[:])
+	d.cs.High()
+	return s, err
+}
+
+// BusWidth returns the current data bus width: 1 or 4 bits.
+func (s *SDStatus) BusWidth() int {
+	if s[0]>>6 == 2 {
+		return 4
+	}
+	return 1
+}
+
+// SecuredMode returns whether the card is in secured mode.
+func (s *SDStatus) SecuredMode() bool {
+	return s[0]&0x20 != 0
+}
+
+// CardType returns the SD_CARD_TYPE field, 0 for a regular card.
+func (s *SDStatus) CardType() uint16 {
+	return uint16(s[2])<<8 | uint16(s[3])
+}
+
+// ProtectedAreaSize returns the size of the protected area in bytes.
+func (s *SDStatus) ProtectedAreaSize() uint32 {
+	return uint32(s[4])<<24 | uint32(s[5])<<16 | uint32(s[6])<<8 | uint32(s[7])
+}
+
+// SpeedClass returns the speed class of the card: 0 (class 0, no
+// performance guarantee), 2, 4, 6 or 10. It is the minimum sequential write
+// speed in MB/s when writing whole allocation units.
+func (s *SDStatus) SpeedClass() int {
+	switch s[8] {
+	case 1:
+		return 2
+	case 2:
+		return 4
+	case 3:
+		return 6
+	case 4:
+		return 10
+	}
+	return 0
+}
+
+// PerformanceMove returns the speed at which the card moves data internally,
+// in MB/s. 0 means not defined and 255 means infinite.
+func (s *SDStatus) PerformanceMove() int {
+	return int(s[9])
+}
+
+// AUSize returns the size of an allocation unit in bytes, or 0 if not
+// defined. Writes aligned to allocation units reach the speed class.
+func (s *SDStatus) AUSize() uint32 {
+	return auSizes[s[10]>>4] * 1024
+}
+
+// EraseSize returns the number of allocation units erased within
+// EraseTimeout, or 0 if the erase timeout is not supported.
+func (s *SDStatus) EraseSize() int {
+	return int(s[11])<<8 | int(s[12])
+}
+
+// EraseTimeout returns the time it takes to erase EraseSize allocation
+// units, or 0 if not supported.
+func (s *SDStatus) EraseTimeout() time.Duration {
+	return time.Duration(s[13]>>2) * time.Second
+}
+
+// EraseOffset returns the time to add to any erase.
+func (s *SDStatus) EraseOffset() time.Duration {
+	return time.Duration(s[13]&0x03) * time.Second
+}
+
+// EraseTime returns the time it takes to erase the given number of
+// allocation units, or 0 if the card does not report it.
+func (s *SDStatus) EraseTime(aus int) time.Duration {
+	size := s.EraseSize()
+	if size == 0 {
+		return 0
+	}
+	return s.EraseTimeout()*time.Duration(aus)/time.Duration(size) + s.EraseOffset()
+}
+
+// UHSSpeedGrade returns the UHS speed grade: 0, 1 (10MB/s) or 3 (30MB/s).
+func (s *SDStatus) UHSSpeedGrade() int {
+	return int(s[14] >> 4)
+}
+
+// UHSAUSize returns the allocation unit size in bytes for UHS speed grade
+// operation, or 0 if not defined.
+func (s *SDStatus) UHSAUSize() uint32 {
+	return auSizes[s[14]&0x0F] * 1024
+}
+
+// VideoSpeedClass returns the video speed class, the minimum sequential write
+// speed in MB/s (6, 10, 30, 60 or 90), or 0.
+func (s *SDStatus) VideoSpeedClass() int {
+	return int(s[15])
+}
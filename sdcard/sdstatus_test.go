@@ -0,0 +1,34 @@
+package sdcard
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSDStatus(t *testing.T) {
+	// Class 10, UHS-I U3 card with 4MiB allocation units.
+	s := SDStatus{0x80, 0x00, 0x00, 0x00, 0x05, 0x00, 0x00, 0x00, 0x04, 0x00, 0x90, 0x00, 0x04, 0x0A, 0x3A, 0x1E}
+
+	if got := s.BusWidth(); got != 4 {
+		t.Errorf("BusWidth: got %d, want 4", got)
+	}
+	if got := s.SpeedClass(); got != 10 {
+		t.Errorf("SpeedClass: got %d, want 10", got)
+	}
+	if got := s.AUSize(); got != 4<<20 {
+		t.Errorf("AUSize: got %d, want %d", got, 4<<20)
+	}
+	if got := s.UHSSpeedGrade(); got != 3 {
+		t.Errorf("UHSSpeedGrade: got %d, want 3", got)
+	}
+	if got := s.UHSAUSize(); got != 8<<20 {
+		t.Errorf("UHSAUSize: got %d, want %d", got, 8<<20)
+	}
+	if got := s.VideoSpeedClass(); got != 30 {
+		t.Errorf("VideoSpeedClass: got %d, want 30", got)
+	}
+	// 2s for every 4 AUs, plus an offset of 2s.
+	if got := s.EraseTime(8); got != 6*time.Second {
+		t.Errorf("EraseTime: got %v, want 6s", got)
+	}
+}
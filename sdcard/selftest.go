@@ -0,0 +1,74 @@
+package sdcard
+
+import (
+	"fmt"
+
+	"tinygo.org/x/drivers"
+)
+
+// SelfTest checks that the card answers the basic command sequence sanely
+// after Configure. The checks are:
+//
+//   - "status": the R2 status of CMD13, which must be 0
+//   - "OCR power up": the power up status bit of the OCR (CMD58), must be 1
+//   - "CID CRC" and "CSD CRC": whether the CRC7 of the registers is valid (1)
+//   - "capacity": the number of sectors reported by the CSD, must not be 0
+//   - "read": whether block 0 can be read (1)
+//
+// It only reads from the card. An error is returned if the card does not
+// answer at all.
+func (d *Device) SelfTest() (drivers.SelfTestResult, error) {
+	var result drivers.SelfTestResult
+
+	r1 := d.cmd(CMD13_SEND_STATUS, 0, 0xFF)
+	if r1 == 0xFF {
+		d.cs.High()
+		return nil, fmt.Errorf("SD_CARD_ERROR_CMD13")
+	}
+	r2, err := d.bus.Transfer(byte(0xFF))
+	d.cs.High()
+	if err != nil {
+		return nil, err
+	}
+	result.Add("status", int32(r1)<<8|int32(r2), 0, 0)
+
+	valid := int32(0)
+	if ocr, err := d.ReadOCR(); err == nil && ocr.PowerUp() {
+		valid = 1
+	}
+	result.Add("OCR power up", valid, 1, 1)
+
+	var buf [16]byte
+	valid = 0
+	if d.ReadCID(buf[:]) == nil && crc7(buf[:15]) == buf[15]>>1 {
+		valid = 1
+	}
+	result.Add("CID CRC", valid, 1, 1)
+
+	valid = 0
+	sectors := int64(0)
+	if d.ReadCSD(buf[:]) == nil {
+		if crc7(buf[:15]) == buf[15]>>1 {
+			valid = 1
+		}
+		sectors, _ = NewCSD(buf[:]).Sectors()
+	}
+	result.Add("CSD CRC", valid, 1, 1)
+	if sectors > 0x7FFFFFFF {
+		sectors = 0x7FFFFFFF
+	}
+	result.Add("capacity", int32(sectors), 1, 0x7FFFFFFF)
+
+	// The block is read into the buffer of the block cache, write back its
+	// block first.
+	valid = 0
+	if d.Sync() == nil {
+		d.cache.reset()
+		if d.ReadData(0, d.dummybuf) == nil {
+			valid = 1
+		}
+	}
+	result.Add("read", valid, 1, 1)
+
+	return result, nil
+}
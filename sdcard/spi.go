@@ -0,0 +1,64 @@
+//go:build tinygo
+
+package sdcard
+
+import (
+	"machine"
+
+	"tinygo.org/x/drivers"
+)
+
+// New returns a card on a hardware SPI bus. The bus is configured by
+// Configure, at 250kHz during the initialization of the card and at the card
+// speed afterwards, see SetMaxFrequency.
+func New(b *machine.SPI, sck, sdo, sdi, cs machine.Pin) Device {
+	return NewBus(b, cs, func(hz uint32) error {
+		return b.Configure(machine.SPIConfig{
+			SCK:       sck,
+			SDO:       sdo,
+			SDI:       sdi,
+			Frequency: hz,
+			LSBFirst:  false,
+			Mode:      0, // phase=0, polarity=0
+		})
+	})
+}
+
+// SetCardDetect uses the card-detect switch of the slot, connected to pin,
+// to tell whether a card is inserted. activeLow is set when the switch
+// pulls the pin low with a card in the slot, which is the usual wiring; the
+// internal pull-up or pull-down is enabled accordingly. See Detect.
+func (d *Device) SetCardDetect(pin machine.Pin, activeLow bool) {
+	if activeLow {
+		pin.Configure(machine.PinConfig{Mode: machine.PinInputPullup})
+	} else {
+		pin.Configure(machine.PinConfig{Mode: machine.PinInputPulldown})
+	}
+	d.SetPresence(func() bool {
+		return pin.Get() != activeLow
+	})
+}
+
+// NewBus returns a card on any SPI bus, such as a software or PIO SPI.
+// setFrequency is called to set the clock of the bus in Hz, at most 400kHz
+// while the card is initialized and up to the card speed afterwards. It may
+// be nil when the clock can't be changed, the bus must then run at 400kHz
+// or less.
+//
+// This function only creates the Device object and sets up the CS pin, it
+// does not touch the device.
+func NewBus(bus drivers.SPI, cs machine.Pin, setFrequency func(hz uint32) error) Device {
+	cs.Configure(machine.PinConfig{Mode: machine.PinOutput})
+	cs.High()
+	buf := make([]byte, 512)
+	return Device{
+		bus:        bus,
+		cs:         cs,
+		setFreq:    setFrequency,
+		cmdbuf:     make([]byte, 6),
+		dummybuf:   buf,
+		cache:      blockCache{buf: buf},
+		tokenbuf:   make([]byte, 1),
+		sdCardType: 0,
+	}
+}
@@ -0,0 +1,72 @@
+package sdcard
+
+// Stats counts the operations of a card and their errors, see Device.Stats.
+// Errors becoming more frequent over weeks are a sign of a card wearing out
+// or of a connection going bad.
+type Stats struct {
+	BlocksRead    uint32 // data blocks read without error
+	BlocksWritten uint32 // data blocks accepted by the card
+	CRCErrors     uint32 // data blocks read or written with a wrong CRC
+	Timeouts      uint32 // commands unanswered, and data or busy waits past their timeout
+	Retries       uint32 // reads and writes tried again, see Timeouts.Retries
+	CommandErrors uint32 // commands answered with an error bit, such as an illegal command
+}
+
+// Stats returns the counts of operations and errors since the card was
+// configured, or since ResetStats.
+func (d *Device) Stats() Stats {
+	if d.stats == nil {
+		return Stats{}
+	}
+	return *d.stats
+}
+
+// ResetStats sets the counts of Stats back to 0. Configure calls it once the
+// card is initialized, so that the commands some cards reject while being
+// identified are not counted.
+func (d *Device) ResetStats() {
+	if d.stats == nil {
+		d.stats = new(Stats)
+		return
+	}
+	*d.stats = Stats{}
+}
+
+// The counters below do nothing until the stats are reset for the first
+// time, so that a Device works without them.
+
+func (s *Stats) blockRead() {
+	if s != nil {
+		s.BlocksRead++
+	}
+}
+
+func (s *Stats) blockWritten() {
+	if s != nil {
+		s.BlocksWritten++
+	}
+}
+
+func (s *Stats) crcError() {
+	if s != nil {
+		s.CRCErrors++
+	}
+}
+
+func (s *Stats) timeout() {
+	if s != nil {
+		s.Timeouts++
+	}
+}
+
+func (s *Stats) retry() {
+	if s != nil {
+		s.Retries++
+	}
+}
+
+func (s *Stats) commandError() {
+	if s != nil {
+		s.CommandErrors++
+	}
+}
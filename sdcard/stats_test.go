@@ -0,0 +1,50 @@
+package sdcard
+
+import (
+	"testing"
+	"time"
+)
+
+func TestStats(t *testing.T) {
+	d, card := newSPICard()
+	buf := make([]byte, 3*512)
+
+	// Nothing is counted before the card is configured.
+	d.WriteData(0, buf)
+	if s := d.Stats(); s != (Stats{}) {
+		t.Errorf("stats before Configure: %+v", s)
+	}
+
+	d.ResetStats()
+	d.SetTimeouts(Timeouts{Read: 5 * time.Millisecond, Retries: 2})
+	if err := d.WriteBlocks(0, buf); err != nil {
+		t.Fatal(err)
+	}
+	card.badReads = 1
+	card.lostReads = 1
+	if err := d.ReadData(1, buf); err != nil {
+		t.Fatal(err)
+	}
+	if err := d.ReadData(2, buf); err != nil {
+		t.Fatal(err)
+	}
+	if r := d.cmd(CMD16_SET_BLOCKLEN, 512, 0xFF); r != _R1_ILLEGAL_COMMAND {
+		t.Fatalf("CMD16 answered %#x", r)
+	}
+
+	want := Stats{
+		BlocksRead:    2,
+		BlocksWritten: 3,
+		CRCErrors:     1,
+		Timeouts:      1,
+		Retries:       2,
+		CommandErrors: 1,
+	}
+	if s := d.Stats(); s != want {
+		t.Errorf("stats %+v, want %+v", s, want)
+	}
+	d.ResetStats()
+	if s := d.Stats(); s != (Stats{}) {
+		t.Errorf("stats after ResetStats: %+v", s)
+	}
+}
@@ -0,0 +1,216 @@
+package sdcard
+
+import (
+	"fmt"
+)
+
+// CardStatus is the 32-bit card status of the SD specification, returned by
+// Status. On the native SD bus it is the status of the R1 responses. In SPI
+// mode it is made from the R2 response of CMD13, which has most of the
+// error bits but not the state of the card: a card that is not idle is
+// reported in the transfer state and ready for data.
+type CardStatus uint32
+
+// Bits of the card status.
+const (
+	StatusOutOfRange      CardStatus = 1 << 31
+	StatusAddressError    CardStatus = 1 << 30
+	StatusBlockLenError   CardStatus = 1 << 29
+	StatusEraseSeqError   CardStatus = 1 << 28
+	StatusEraseParam      CardStatus = 1 << 27
+	StatusWPViolation     CardStatus = 1 << 26
+	StatusCardIsLocked    CardStatus = 1 << 25
+	StatusLockUnlockFail  CardStatus = 1 << 24
+	StatusComCRCError     CardStatus = 1 << 23
+	StatusIllegalCommand  CardStatus = 1 << 22
+	StatusCardECCFailed   CardStatus = 1 << 21
+	StatusCCError         CardStatus = 1 << 20
+	StatusError           CardStatus = 1 << 19
+	StatusCSDOverwrite    CardStatus = 1 << 16
+	StatusWPEraseSkip     CardStatus = 1 << 15
+	StatusCardECCDisabled CardStatus = 1 << 14
+	StatusEraseReset      CardStatus = 1 << 13
+	StatusReadyForData    CardStatus = 1 << 8
+	StatusAppCmd          CardStatus = 1 << 5
+	StatusAKESeqError     CardStatus = 1 << 3
+
+	// StatusErrors are the bits that report an error.
+	StatusErrors = StatusOutOfRange | StatusAddressError | StatusBlockLenError |
+		StatusEraseSeqError | StatusEraseParam | StatusWPViolation |
+		StatusLockUnlockFail | StatusComCRCError | StatusIllegalCommand |
+		StatusCardECCFailed | StatusCCError | StatusError |
+		StatusCSDOverwrite | StatusWPEraseSkip | StatusAKESeqError
+)
+
+// CardState is the state of the card, in bits 12 to 9 of the card status.
+type CardState uint8
+
+const (
+	StateIdle CardState = iota
+	StateReady
+	StateIdent
+	StateStandby
+	StateTransfer
+	StateData    // sending data
+	StateReceive // receiving data
+	StateProgram // busy writing
+	StateDisconnect
+)
+
+func (s CardState) String() string {
+	switch s {
+	case StateIdle:
+		return "idle"
+	case StateReady:
+		return "ready"
+	case StateIdent:
+		return "ident"
+	case StateStandby:
+		return "stby"
+	case StateTransfer:
+		return "tran"
+	case StateData:
+		return "data"
+	case StateReceive:
+		return "rcv"
+	case StateProgram:
+		return "prg"
+	case StateDisconnect:
+		return "dis"
+	}
+	return "reserved"
+}
+
+// State returns the state of the card.
+func (s CardStatus) State() CardState {
+	return CardState(s >> 9 & 0x0F)
+}
+
+// ReadyForData returns whether the card is ready for a new data command,
+// that is not busy programming.
+func (s CardStatus) ReadyForData() bool {
+	return s&StatusReadyForData != 0
+}
+
+// Locked returns whether the card is locked by a password.
+func (s CardStatus) Locked() bool {
+	return s&StatusCardIsLocked != 0
+}
+
+// Err returns an error describing the error bits of the status, or nil.
+func (s CardStatus) Err() error {
+	if s&StatusErrors == 0 {
+		return nil
+	}
+	return &CardStatusError{Status: s}
+}
+
+// statusNames are the names of the error bits, from the specification.
+var statusNames = [...]struct {
+	bit  CardStatus
+	name string
+}{
+	{StatusOutOfRange, "OUT_OF_RANGE"},
+	{StatusAddressError, "ADDRESS_ERROR"},
+	{StatusBlockLenError, "BLOCK_LEN_ERROR"},
+	{StatusEraseSeqError, "ERASE_SEQ_ERROR"},
+	{StatusEraseParam, "ERASE_PARAM"},
+	{StatusWPViolation, "WP_VIOLATION"},
+	{StatusLockUnlockFail, "LOCK_UNLOCK_FAILED"},
+	{StatusComCRCError, "COM_CRC_ERROR"},
+	{StatusIllegalCommand, "ILLEGAL_COMMAND"},
+	{StatusCardECCFailed, "CARD_ECC_FAILED"},
+	{StatusCCError, "CC_ERROR"},
+	{StatusError, "ERROR"},
+	{StatusCSDOverwrite, "CSD_OVERWRITE"},
+	{StatusWPEraseSkip, "WP_ERASE_SKIP"},
+	{StatusAKESeqError, "AKE_SEQ_ERROR"},
+}
+
+// CardStatusError is the error of a card status with error bits set.
+type CardStatusError struct {
+	Cmd    uint8 // command that returned the status, 0 if not known
+	Status CardStatus
+}
+
+func (e *CardStatusError) Error() string {
+	msg := "sdcard: card status"
+	if e.Cmd != 0 {
+		msg = fmt.Sprintf("sdcard: CMD%d: card status", e.Cmd)
+	}
+	for _, n := range statusNames {
+		if e.Status&n.bit != 0 {
+			msg += " " + n.name
+		}
+	}
+	return msg
+}
+
+// Status reads the card status with CMD13. After a failed write or erase,
+// its error bits tell why, for instance WP_VIOLATION for a write protected
+// block, or CARD_ECC_FAILED for a worn out card. Reading the status clears
+// its error bits.
+func (d Device) Status() (CardStatus, error) {
+	defer d.cs.High()
+	r1 := d.cmd(CMD13_SEND_STATUS, 0, 0xFF)
+	if r1&0x80 != 0 {
+		return 0, fmt.Errorf("SD_CARD_ERROR_CMD13")
+	}
+	r2, err := d.bus.Transfer(byte(0xFF))
+	if err != nil {
+		return 0, err
+	}
+	return spiStatus(r1, r2), nil
+}
+
+// writeError returns the error of a rejected data block: the error bits of
+// the card status, which tell why, or err if there are none.
+func (d Device) writeError(err error) error {
+	if _, ok := err.(*CRCError); ok {
+		return err
+	}
+	d.cs.High()
+	status, serr := d.Status()
+	if serr != nil || status.Err() == nil {
+		return err
+	}
+	return status.Err()
+}
+
+// spiStatus converts the two bytes of an R2 response to a card status.
+func spiStatus(r1, r2 byte) CardStatus {
+	var s CardStatus
+	set := func(b byte, mask byte, bit CardStatus) {
+		if b&mask != 0 {
+			s |= bit
+		}
+	}
+	set(r1, _R1_ERASE_RESET, StatusEraseReset)
+	set(r1, _R1_ILLEGAL_COMMAND, StatusIllegalCommand)
+	set(r1, _R1_COM_CRC_ERROR, StatusComCRCError)
+	set(r1, _R1_ERASE_SEQUENCE_ERROR, StatusEraseSeqError)
+	set(r1, _R1_ADDRESS_ERROR, StatusAddressError)
+	set(r1, _R1_PARAMETER_ERROR, StatusOutOfRange)
+	set(r2, 0x01, StatusCardIsLocked)
+	set(r2, 0x02, StatusLockUnlockFail)
+	set(r2, 0x04, StatusError)
+	set(r2, 0x08, StatusCCError)
+	set(r2, 0x10, StatusCardECCFailed)
+	set(r2, 0x20, StatusWPViolation)
+	set(r2, 0x40, StatusEraseParam)
+	set(r2, 0x80, StatusOutOfRange)
+	if r1&_R1_IDLE_STATE == 0 {
+		s |= CardStatus(StateTransfer)<<9 | StatusReadyForData
+	}
+	return s
+}
+
+// Status reads the card status with CMD13. After a failed write or erase,
+// its error bits tell why, for instance WP_VIOLATION for a write protected
+// block, or CARD_ECC_FAILED for a worn out card. Reading the status clears
+// its error bits.
+func (c *SDIOCard) Status() (CardStatus, error) {
+	req := SDIORequest{Cmd: CMD13_SEND_STATUS, Arg: c.rca, Response: SDIOResponseR1}
+	err := c.host.Do(&req)
+	return CardStatus(req.Resp[0]), err
+}
@@ -0,0 +1,53 @@
+package sdcard
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestSPIStatus(t *testing.T) {
+	s := spiStatus(0x00, 0x20)
+	if s.State() != StateTransfer || !s.ReadyForData() {
+		t.Errorf("state %v, ready %v", s.State(), s.ReadyForData())
+	}
+	err := s.Err()
+	if err == nil || err.Error() != "sdcard: card status WP_VIOLATION" {
+		t.Errorf("error: %v", err)
+	}
+	s = spiStatus(_R1_IDLE_STATE|_R1_ILLEGAL_COMMAND, 0x01)
+	if s.State() != StateIdle || s.ReadyForData() || !s.Locked() || s&StatusIllegalCommand == 0 {
+		t.Errorf("idle card: %08X", uint32(s))
+	}
+	if spiStatus(0, 0).Err() != nil {
+		t.Errorf("no error bits: %v", spiStatus(0, 0).Err())
+	}
+}
+
+func TestSDIOCardStatus(t *testing.T) {
+	host := &fakeHost{mem: make([]byte, 64*512)}
+	c := NewSDIO(host)
+	if err := c.Configure(); err != nil {
+		t.Fatal(err)
+	}
+	host.locked = true
+	s, err := c.Status()
+	if err != nil || !s.Locked() {
+		t.Errorf("Status: %08X, %v", uint32(s), err)
+	}
+	if host.cmds[len(host.cmds)-1] != CMD13_SEND_STATUS || host.args[len(host.args)-1] != 0x12340000 {
+		t.Errorf("CMD13 sent with %08X", host.args[len(host.args)-1])
+	}
+
+	// The locked card rejects reads as illegal commands.
+	err = c.ReadData(0, make([]byte, 512))
+	var serr *CardStatusError
+	if !errors.As(err, &serr) || serr.Cmd != CMD17_READ_SINGLE_BLOCK || serr.Status&StatusIllegalCommand == 0 {
+		t.Errorf("ReadData of a locked card: %v", err)
+	}
+	if err.Error() != "sdcard: CMD17: card status ILLEGAL_COMMAND" {
+		t.Errorf("error message: %q", err.Error())
+	}
+	if StateProgram.String() != "prg" {
+		t.Errorf("state name %q", StateProgram)
+	}
+}
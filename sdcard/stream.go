@@ -0,0 +1,254 @@
+package sdcard
+
+import (
+	"errors"
+	"io"
+)
+
+var errRangeEnd = errors.New("sdcard: write past the end of the block range")
+
+// BlockReader reads a range of blocks as a stream, with a single CMD18
+// multiple block read: the card keeps sending the following blocks between
+// calls to Read, which is much faster than reading each block with its own
+// command. Reads may be of any length.
+//
+// The card is busy with the transfer until the last block was read or
+// Close is called: the Device must not be used meanwhile.
+type BlockReader struct {
+	d     *Device
+	block uint32 // next block to receive
+	end   uint32
+	buf   [512]byte
+	pos   int // position in buf, 512 when empty
+	open  bool
+	err   error
+}
+
+// OpenBlockRangeReader starts reading n blocks from start. The block cached
+// by an unaligned WriteAt is written back first, so that it is read.
+func (d *Device) OpenBlockRangeReader(start, n int64) (*BlockReader, error) {
+	if err := d.checkPresent(); err != nil {
+		return nil, err
+	}
+	if err := d.Sync(); err != nil {
+		return nil, err
+	}
+	r := &BlockReader{d: d, block: uint32(start), end: uint32(start + n), pos: 512}
+	if n <= 0 {
+		return r, nil
+	}
+	addr := uint32(start)
+	// use address if not SDHC card
+	if !d.blockAddressed() {
+		addr <<= 9
+	}
+	if res := d.cmd(CMD18_READ_MULTIPLE_BLOCK, addr, 0xFF); res != 0 {
+		d.cs.High()
+		return nil, commandError(res, "CMD18 error")
+	}
+	r.open = true
+	return r, nil
+}
+
+// Read reads up to len(p) bytes of the blocks. It returns io.EOF after the
+// last block.
+func (r *BlockReader) Read(p []byte) (int, error) {
+	n := 0
+	for n < len(p) {
+		if r.pos < 512 {
+			k := copy(p[n:], r.buf[r.pos:])
+			r.pos += k
+			n += k
+			continue
+		}
+		if r.err != nil {
+			return n, r.err
+		}
+		if r.block == r.end {
+			if n == 0 {
+				return 0, io.EOF
+			}
+			break
+		}
+		// Whole blocks go straight to p.
+		dst := r.buf[:]
+		if len(p)-n >= 512 {
+			dst = p[n : n+512]
+		}
+		if err := r.receive(dst); err != nil {
+			return n, err
+		}
+		if len(p)-n >= 512 {
+			n += 512
+		} else {
+			r.pos = 0
+		}
+	}
+	return n, nil
+}
+
+// receive receives the next block, and ends the transfer after the last
+// one or an error.
+func (r *BlockReader) receive(dst []byte) error {
+	d := r.d
+	err := d.waitStartBlock()
+	if err == nil {
+		err = d.bus.Tx(dummy[:512], dst)
+	}
+	if err == nil {
+		err = d.readCRC(r.block, dst)
+	}
+	if err != nil {
+		r.err = err
+		r.stop()
+		return err
+	}
+	r.block++
+	if r.block == r.end {
+		r.err = r.stop()
+		return r.err
+	}
+	return nil
+}
+
+// Close ends the transfer if blocks are left.
+func (r *BlockReader) Close() error {
+	if r.open {
+		return r.stop()
+	}
+	return nil
+}
+
+// stop sends CMD12 to end the transfer.
+func (r *BlockReader) stop() error {
+	d := r.d
+	r.open = false
+	defer d.cs.High()
+	if res := d.cmd(CMD12_STOP_TRANSMISSION, 0, 0xFF); res != 0 {
+		return commandError(res, "CMD12 error")
+	}
+	return d.waitNotBusy(d.busyTimeout())
+}
+
+// BlockWriter writes a range of blocks as a stream, with a single CMD25
+// multiple block write: the card waits for the following blocks between
+// calls to Write. Writes may be of any length; each block is sent to the
+// card once complete, and Close pads the last one with zeros.
+//
+// The card is busy with the transfer until Close is called: the Device must
+// not be used meanwhile.
+type BlockWriter struct {
+	d     *Device
+	start uint32
+	block uint32 // next block to send
+	end   uint32
+	buf   [512]byte
+	n     int // bytes in buf
+	open  bool
+	err   error
+}
+
+// OpenBlockRangeWriter starts writing n blocks from start. With SetPreErase,
+// the card is told the number of blocks in advance.
+func (d *Device) OpenBlockRangeWriter(start, n int64) (*BlockWriter, error) {
+	if err := d.checkPresent(); err != nil {
+		return nil, err
+	}
+	w := &BlockWriter{d: d, start: uint32(start), block: uint32(start), end: uint32(start + n)}
+	if n <= 0 {
+		return w, nil
+	}
+	// The cached block would be overwritten.
+	d.cache.drop(start, start+n)
+	if d.preErase {
+		// The hint is optional, so a card rejecting it is not an error.
+		d.acmd(ACMD23_SET_WR_BLK_ERASE_COUNT, uint32(n)&0x7FFFFF)
+	}
+	if err := d.WriteMultiStart(uint32(start)); err != nil {
+		d.cs.High()
+		return nil, err
+	}
+	w.open = true
+	return w, nil
+}
+
+// Write writes p to the blocks. Writing past the end of the range is an
+// error.
+func (w *BlockWriter) Write(p []byte) (int, error) {
+	n := 0
+	for n < len(p) {
+		if w.err != nil {
+			return n, w.err
+		}
+		if w.block == w.end {
+			return n, errRangeEnd
+		}
+		if w.n == 0 && len(p)-n >= 512 {
+			// Whole blocks are sent from p.
+			if err := w.send(p[n : n+512]); err != nil {
+				return n, err
+			}
+			n += 512
+			continue
+		}
+		k := copy(w.buf[w.n:], p[n:])
+		w.n += k
+		n += k
+		if w.n == 512 {
+			if err := w.send(w.buf[:]); err != nil {
+				// The bytes of the block were taken.
+				return n, err
+			}
+			w.n = 0
+		}
+	}
+	return n, nil
+}
+
+// send sends a block, and ends the transfer after an error.
+func (w *BlockWriter) send(src []byte) error {
+	if err := w.d.WriteMulti(src); err != nil {
+		if e, ok := err.(*CRCError); ok {
+			e.Block = w.block
+		}
+		w.err = err
+		w.finish()
+		return w.err
+	}
+	w.block++
+	return nil
+}
+
+// Close sends the last block, padded with zeros if it is partial, and ends
+// the transfer. It returns the error that ended the transfer, if any: with
+// SetVerifyWrites, a *PartialWriteError when not all the blocks sent were
+// programmed.
+func (w *BlockWriter) Close() error {
+	if w.open && w.n > 0 {
+		for i := w.n; i < 512; i++ {
+			w.buf[i] = 0
+		}
+		w.n = 0
+		w.send(w.buf[:])
+	}
+	if w.open {
+		w.finish()
+	}
+	return w.err
+}
+
+// finish sends the stop token ending the transfer, and checks the blocks
+// programmed with SetVerifyWrites.
+func (w *BlockWriter) finish() {
+	w.open = false
+	if err := w.d.WriteMultiStop(); w.err == nil {
+		w.err = err
+	}
+	if !w.d.verify {
+		return
+	}
+	n, err := w.d.verifyWrite(int(w.block-w.start), w.err)
+	if err != nil {
+		w.err = &PartialWriteError{Start: w.start, Written: uint32(n), Err: err}
+	}
+}
@@ -0,0 +1,100 @@
+package sdcard
+
+import (
+	"bytes"
+	"io"
+	"testing"
+)
+
+func TestBlockReader(t *testing.T) {
+	d, card := newSPICard()
+	for i := range card.mem {
+		card.mem[i] = byte(i / 5)
+	}
+
+	// Reads of odd sizes, across blocks.
+	r, err := d.OpenBlockRangeReader(2, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []byte
+	buf := make([]byte, 700)
+	for _, n := range []int{100, 700, 300, 700, 700} {
+		k, err := r.Read(buf[:n])
+		got = append(got, buf[:k]...)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	if !bytes.Equal(got, card.mem[2*512:5*512]) {
+		t.Errorf("read %d bytes, not the blocks", len(got))
+	}
+	if _, err := r.Read(buf); err != io.EOF {
+		t.Errorf("read after the last block: %v", err)
+	}
+	if card.reads {
+		t.Error("transfer not stopped after the last block")
+	}
+
+	// Closed before the end.
+	r, _ = d.OpenBlockRangeReader(0, 10)
+	io.ReadFull(r, buf[:600])
+	if err := r.Close(); err != nil || card.reads {
+		t.Errorf("Close: %v", err)
+	}
+	if !bytes.Equal(buf[:600], card.mem[:600]) {
+		t.Error("wrong data before Close")
+	}
+
+	// A CRC error ends the transfer.
+	r, _ = d.OpenBlockRangeReader(0, 4)
+	card.badReads = 1
+	if _, err := io.ReadAll(r); err == nil {
+		t.Error("CRC error not returned")
+	}
+	if card.reads {
+		t.Error("transfer not stopped after an error")
+	}
+}
+
+func TestBlockWriter(t *testing.T) {
+	d, card := newSPICard()
+	src := make([]byte, 3*512-100)
+	for i := range src {
+		src[i] = byte(i / 3)
+	}
+	for i := range card.mem {
+		card.mem[i] = 0xAA
+	}
+
+	w, err := d.OpenBlockRangeWriter(5, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, chunk := range [][]byte{src[:10], src[10:600], src[600:1112], src[1112:]} {
+		if n, err := w.Write(chunk); n != len(chunk) || err != nil {
+			t.Fatalf("Write: %d, %v", n, err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(card.mem[5*512:5*512+len(src)], src) {
+		t.Error("wrong data written")
+	}
+	if !bytes.Equal(card.mem[5*512+len(src):8*512], make([]byte, 100)) {
+		t.Error("last block not padded with zeros")
+	}
+	if card.mem[8*512] != 0xAA || card.mem[5*512-1] != 0xAA {
+		t.Error("written outside the range")
+	}
+
+	w, _ = d.OpenBlockRangeWriter(0, 1)
+	if n, err := w.Write(make([]byte, 600)); n != 512 || err != errRangeEnd {
+		t.Errorf("write past the end: %d, %v", n, err)
+	}
+	w.Close()
+}
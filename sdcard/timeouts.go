@@ -0,0 +1,120 @@
+package sdcard
+
+import (
+	"errors"
+	"time"
+)
+
+var (
+	errReadTimeout  = errors.New("SD_CARD_ERROR_READ_TIMEOUT")
+	errWriteTimeout = errors.New("SD_CARD_ERROR_WRITE_TIMEOUT")
+	errCmdTimeout   = errors.New("SD_CARD_ERROR_CMD_TIMEOUT")
+	errCmdCRC       = errors.New("SD_CARD_ERROR_COM_CRC")
+)
+
+// Timeouts is the timing and retry policy of a Device, see SetTimeouts. The
+// defaults suit most cards on short wires; slow cards may need longer
+// timeouts, and noisy wiring a few retries.
+type Timeouts struct {
+	// Init bounds each step of the initialization by Configure: the reset
+	// of the card, then its power up. 2s when 0.
+	Init time.Duration
+
+	// Read is the longest wait for the data of a read block. 300ms when 0.
+	Read time.Duration
+
+	// Write is the longest time the card may stay busy programming a
+	// written block. 600ms when 0.
+	Write time.Duration
+
+	// Busy is the longest wait for the card to be ready before a command.
+	// 300ms when 0.
+	Busy time.Duration
+
+	// Retries is the number of times ReadData, WriteData and WriteBlocks
+	// try again after a transient error: a CRC error (see EnableCRC), a
+	// timeout, or a command the card did not answer. Other errors are
+	// returned at once.
+	Retries uint8
+}
+
+const (
+	defaultInitTimeout  = 2 * time.Second
+	defaultReadTimeout  = 300 * time.Millisecond
+	defaultWriteTimeout = 600 * time.Millisecond
+	defaultBusyTimeout  = 300 * time.Millisecond
+)
+
+// SetTimeouts sets the timeouts and the number of retries of the card. The
+// init timeout takes effect at the next Configure.
+func (d *Device) SetTimeouts(t Timeouts) {
+	d.timeouts = t
+}
+
+// Timeouts returns the timeouts in use, with the defaults filled in.
+func (d *Device) Timeouts() Timeouts {
+	t := d.timeouts
+	t.Init = d.initTimeout()
+	t.Read = d.readTimeout()
+	t.Write = d.writeTimeout()
+	t.Busy = d.busyTimeout()
+	return t
+}
+
+func (d Device) initTimeout() time.Duration {
+	if d.timeouts.Init == 0 {
+		return defaultInitTimeout
+	}
+	return d.timeouts.Init
+}
+
+func (d Device) readTimeout() time.Duration {
+	if d.timeouts.Read == 0 {
+		return defaultReadTimeout
+	}
+	return d.timeouts.Read
+}
+
+func (d Device) writeTimeout() time.Duration {
+	if d.timeouts.Write == 0 {
+		return defaultWriteTimeout
+	}
+	return d.timeouts.Write
+}
+
+func (d Device) busyTimeout() time.Duration {
+	if d.timeouts.Busy == 0 {
+		return defaultBusyTimeout
+	}
+	return d.timeouts.Busy
+}
+
+// retry returns whether an operation that failed with err on the given
+// attempt, from 0, should be tried again.
+func (d Device) retry(attempt int, err error) bool {
+	if err == nil || attempt >= int(d.timeouts.Retries) {
+		return false
+	}
+	transient := err == errReadTimeout || err == errWriteTimeout ||
+		err == errCmdTimeout || err == errCmdCRC
+	if _, ok := err.(*CRCError); ok {
+		transient = true
+	}
+	if transient {
+		d.stats.retry()
+	}
+	return transient
+}
+
+// commandError returns the error of a command answered by r1, other than
+// 0: a transient error when the card did not answer or did not understand
+// the command, the error made of msg otherwise.
+func commandError(r1 byte, msg string) error {
+	switch {
+	case r1 == 0xFF:
+		return errCmdTimeout
+	case r1&_R1_COM_CRC_ERROR != 0:
+		return errCmdCRC
+	}
+	return errors.New(msg)
+}
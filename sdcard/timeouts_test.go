@@ -0,0 +1,321 @@
+package sdcard
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"tinygo.org/x/drivers"
+)
+
+type nopPin struct{}
+
+func (nopPin) High() {}
+func (nopPin) Low()  {}
+
+// spiCard is an SDHC card in SPI mode, which answers single and multiple
+// block reads and writes, and fails some of them on request.
+type spiCard struct {
+	mem   []byte
+	out   []byte // bytes sent next by the card
+	cmd   []byte // command being received
+	data  []byte // data block being received
+	state int
+	block uint32
+	multi bool
+	reads bool // sending blocks for CMD18
+	app   bool // after CMD55
+
+	written   uint32 // blocks received by the last CMD25, for ACMD22
+	unwritten uint32 // blocks received but reported not programmed
+
+	status SDStatus // sent for ACMD13
+	csd    [16]byte // sent for CMD9, programmed by CMD27
+	csdErr bool     // CSD_OVERWRITE, for the next CMD13
+	prog   bool     // receiving the CSD
+	erase  []uint32 // arguments of CMD32 and CMD33, then 38 for CMD38, or 138 for ACMD38
+
+	badReads    int   // reads sent with a wrong CRC
+	lostReads   int   // reads never answered
+	rejectBlock int64 // block rejected once with a CRC error, or -1
+}
+
+const (
+	spiIdle = iota
+	spiWaitToken
+	spiData
+)
+
+func newSPICard() (*Device, *spiCard) {
+	card := &spiCard{mem: make([]byte, 16*512), rejectBlock: -1}
+	d := &Device{
+		bus:        card,
+		cs:         nopPin{},
+		cmdbuf:     make([]byte, 6),
+		tokenbuf:   make([]byte, 1),
+		cache:      blockCache{buf: make([]byte, 512)},
+		sdCardType: SD_CARD_TYPE_SDHC,
+		crcEnabled: true,
+	}
+	return d, card
+}
+
+func (c *spiCard) Tx(w, r []byte) error {
+	n := len(w)
+	if w == nil {
+		n = len(r)
+	}
+	for i := 0; i < n; i++ {
+		b := byte(0xFF)
+		if w != nil {
+			b = w[i]
+		}
+		o, _ := c.Transfer(b)
+		if r != nil {
+			r[i] = o
+		}
+	}
+	return nil
+}
+
+func (c *spiCard) Transfer(b byte) (byte, error) {
+	if c.reads && len(c.out) == 0 {
+		c.out = c.dataBlock(c.block)
+		c.block++
+	}
+	o := byte(0xFF)
+	if len(c.out) > 0 {
+		o = c.out[0]
+		c.out = c.out[1:]
+	}
+	c.receive(b)
+	return o, nil
+}
+
+func (c *spiCard) receive(b byte) {
+	switch c.state {
+	case spiWaitToken:
+		switch b {
+		case 0xFE, 0xFC:
+			c.state = spiData
+			c.data = c.data[:0]
+		case 0xFD:
+			// Stop transmission, then busy.
+			c.out = []byte{0xFF, 0x00}
+			c.state = spiIdle
+		}
+	case spiData:
+		c.data = append(c.data, b)
+		if c.prog && len(c.data) == 18 {
+			c.csdErr = !bytes.Equal(c.data[:14], c.csd[:14]) || crc7(c.data[:15])<<1|1 != c.data[15]
+			if !c.csdErr {
+				copy(c.csd[:], c.data)
+			}
+			c.out = []byte{0x05, 0x00, 0x00}
+			c.prog = false
+			c.state = spiIdle
+			return
+		}
+		if len(c.data) < 514 {
+			return
+		}
+		if int64(c.block) == c.rejectBlock {
+			c.rejectBlock = -1
+			c.out = []byte{0x0B}
+		} else {
+			copy(c.mem[c.block*512:], c.data[:512])
+			c.block++
+			c.written++
+			c.out = []byte{0x05, 0x00, 0x00, 0x00}
+		}
+		c.state = spiIdle
+		if c.multi {
+			c.state = spiWaitToken
+		}
+	default:
+		if len(c.cmd) == 0 && b&0xC0 != 0x40 {
+			return
+		}
+		c.cmd = append(c.cmd, b)
+		if len(c.cmd) == 6 {
+			c.command(c.cmd[0]&0x3F, uint32(c.cmd[1])<<24|uint32(c.cmd[2])<<16|uint32(c.cmd[3])<<8|uint32(c.cmd[4]))
+			c.cmd = c.cmd[:0]
+		}
+	}
+}
+
+func (c *spiCard) command(cmd uint8, arg uint32) {
+	app := c.app
+	c.app = false
+	if app && cmd == ACMD22_SEND_NUM_WR_BLOCKS {
+		n := c.written - c.unwritten
+		c.unwritten = 0
+		data := []byte{byte(n >> 24), byte(n >> 16), byte(n >> 8), byte(n)}
+		crc := crc16(data)
+		c.out = append([]byte{0xFF, 0x00, 0xFF, 0xFE}, data...)
+		c.out = append(c.out, byte(crc>>8), byte(crc))
+		return
+	}
+	if app && cmd == ACMD13_SD_STATUS {
+		crc := crc16(c.status[:])
+		c.out = append([]byte{0xFF, 0x00, 0x00, 0xFF, 0xFE}, c.status[:]...)
+		c.out = append(c.out, byte(crc>>8), byte(crc))
+		return
+	}
+	switch cmd {
+	case CMD32_ERASE_WR_BLK_START_ADDR, CMD33_ERASE_WR_BLK_END_ADDR:
+		c.out = []byte{0xFF, 0x00}
+		c.erase = append(c.erase, arg)
+	case CMD38_ERASE:
+		// Busy for a while.
+		c.out = []byte{0xFF, 0x00, 0x00, 0x00}
+		if app {
+			c.erase = append(c.erase, 138)
+		} else {
+			c.erase = append(c.erase, 38)
+		}
+	case CMD55_APP_CMD:
+		c.out = []byte{0xFF, 0x00}
+		c.app = true
+	case CMD9_SEND_CSD:
+		crc := crc16(c.csd[:])
+		c.out = append([]byte{0xFF, 0x00, 0xFF, 0xFE}, c.csd[:]...)
+		c.out = append(c.out, byte(crc>>8), byte(crc))
+	case CMD27_PROGRAM_CSD:
+		c.out = []byte{0xFF, 0x00}
+		c.prog = true
+		c.state = spiWaitToken
+	case CMD13_SEND_STATUS:
+		c.out = []byte{0xFF, 0x00, 0x00}
+		if c.csdErr {
+			c.out[2] = 0x80 // CSD overwrite
+			c.csdErr = false
+		}
+	case CMD17_READ_SINGLE_BLOCK:
+		c.out = []byte{0xFF, 0x00}
+		if c.lostReads > 0 {
+			c.lostReads--
+			return
+		}
+		c.out = append(c.out, c.dataBlock(arg)...)
+	case CMD18_READ_MULTIPLE_BLOCK:
+		c.out = []byte{0xFF, 0x00}
+		c.block = arg
+		c.reads = true
+	case CMD12_STOP_TRANSMISSION:
+		// The stuff byte, then R1.
+		c.out = []byte{0xFF, 0x00}
+		c.reads = false
+	case CMD24_WRITE_BLOCK, CMD25_WRITE_MULTIPLE_BLOCK:
+		c.out = []byte{0xFF, 0x00}
+		c.block = arg
+		c.multi = cmd == CMD25_WRITE_MULTIPLE_BLOCK
+		c.written = 0
+		c.state = spiWaitToken
+	default:
+		c.out = []byte{0xFF, _R1_ILLEGAL_COMMAND}
+	}
+}
+
+// dataBlock returns the start token, data and CRC of a block.
+func (c *spiCard) dataBlock(block uint32) []byte {
+	data := c.mem[block*512 : (block+1)*512]
+	crc := crc16(data)
+	if c.badReads > 0 {
+		c.badReads--
+		crc ^= 1
+	}
+	out := append([]byte{0xFF, 0xFE}, data...)
+	return append(out, byte(crc>>8), byte(crc))
+}
+
+func TestTimeouts(t *testing.T) {
+	d, _ := newSPICard()
+	if got := d.Timeouts(); got.Init != 2*time.Second || got.Read != 300*time.Millisecond ||
+		got.Write != 600*time.Millisecond || got.Busy != 300*time.Millisecond {
+		t.Errorf("default timeouts: %+v", got)
+	}
+	d.SetTimeouts(Timeouts{Read: time.Second, Retries: 3})
+	if got := d.Timeouts(); got.Read != time.Second || got.Write != 600*time.Millisecond || got.Retries != 3 {
+		t.Errorf("timeouts: %+v", got)
+	}
+}
+
+func TestReadRetry(t *testing.T) {
+	d, card := newSPICard()
+	for i := range card.mem {
+		card.mem[i] = byte(i / 7)
+	}
+	buf := make([]byte, 512)
+
+	card.badReads = 1
+	if _, ok := d.ReadData(3, buf).(*CRCError); !ok {
+		t.Error("CRC error without retries not returned")
+	}
+
+	d.SetTimeouts(Timeouts{Read: 5 * time.Millisecond})
+	card.lostReads = 1
+	if err := d.ReadData(3, buf); err != errReadTimeout {
+		t.Errorf("lost read without retries: %v", err)
+	}
+
+	d.SetTimeouts(Timeouts{Read: 5 * time.Millisecond, Retries: 2})
+	card.badReads, card.lostReads = 1, 1
+	if err := d.ReadData(3, buf); err != nil {
+		t.Errorf("ReadData with retries: %v", err)
+	}
+	if !bytes.Equal(buf, card.mem[3*512:4*512]) {
+		t.Error("wrong data read")
+	}
+	card.badReads = 3
+	if _, ok := d.ReadData(3, buf).(*CRCError); !ok {
+		t.Error("CRC error after all retries not returned")
+	}
+}
+
+func TestWriteRetry(t *testing.T) {
+	d, card := newSPICard()
+	src := make([]byte, 4*512)
+	for i := range src {
+		src[i] = byte(i / 3)
+	}
+
+	card.rejectBlock = 1
+	if _, ok := d.WriteData(1, src).(*CRCError); !ok {
+		t.Error("rejected block without retries: no CRC error")
+	}
+
+	d.SetTimeouts(Timeouts{Retries: 1})
+	card.rejectBlock = 1
+	if err := d.WriteData(1, src); err != nil {
+		t.Errorf("WriteData with retries: %v", err)
+	}
+	if !bytes.Equal(card.mem[512:1024], src[:512]) {
+		t.Error("WriteData: wrong data written")
+	}
+
+	// The write starts again from the rejected block.
+	card.rejectBlock = 6
+	if err := d.WriteBlocks(4, src); err != nil {
+		t.Errorf("WriteBlocks with retries: %v", err)
+	}
+	if !bytes.Equal(card.mem[4*512:8*512], src) {
+		t.Error("WriteBlocks: wrong data written")
+	}
+}
+
+func TestYield(t *testing.T) {
+	d, _ := newSPICard()
+	calls := 0
+	drivers.SetYield(func() { calls++ })
+	defer drivers.SetYield(nil)
+
+	// The card is busy for three bytes after taking the block.
+	src := make([]byte, 512)
+	if err := d.WriteData(2, src); err != nil {
+		t.Fatal(err)
+	}
+	if calls == 0 {
+		t.Error("yield hook not called while the card is busy")
+	}
+}
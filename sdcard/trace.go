@@ -0,0 +1,56 @@
+package sdcard
+
+// Response1 is the R1 response of a command in SPI mode: 0 when the command
+// was accepted, the bits below otherwise. It is 0xFF when the card did not
+// answer.
+type Response1 byte
+
+// Bits of the R1 response.
+const (
+	R1IdleState          Response1 = _R1_IDLE_STATE // initializing, not an error
+	R1EraseReset         Response1 = _R1_ERASE_RESET
+	R1IllegalCommand     Response1 = _R1_ILLEGAL_COMMAND
+	R1ComCRCError        Response1 = _R1_COM_CRC_ERROR
+	R1EraseSequenceError Response1 = _R1_ERASE_SEQUENCE_ERROR
+	R1AddressError       Response1 = _R1_ADDRESS_ERROR
+	R1ParameterError     Response1 = _R1_PARAMETER_ERROR
+
+	// R1NoResponse is the response of a command left unanswered.
+	R1NoResponse Response1 = 0xFF
+)
+
+func (r Response1) String() string {
+	switch r {
+	case 0:
+		return "ok"
+	case R1NoResponse:
+		return "no response"
+	}
+	names := [...]string{"idle", "erase reset", "illegal command", "com crc error",
+		"erase sequence error", "address error", "parameter error"}
+	s := ""
+	for i, name := range names {
+		if r&(1<<i) != 0 {
+			if s != "" {
+				s += "|"
+			}
+			s += name
+		}
+	}
+	return s
+}
+
+// SetTrace sets a function called after every command sent to the card,
+// with the command index, its argument, and the response of the card. err
+// is the error of the SPI bus, or the timeout of a command unanswered; the
+// errors of the card are the bits of r1. Application commands show as CMD55
+// followed by the command. It helps to debug a card that misbehaves without
+// a logic analyzer. nil, the default, removes the function, and then costs
+// nothing.
+//
+// The function is called with the card selected, in the middle of an
+// operation: it must not use the card, and should be quick, such as
+// storing the command in a ring buffer or printing it.
+func (d *Device) SetTrace(trace func(cmd byte, arg uint32, r1 Response1, err error)) {
+	d.trace = trace
+}
@@ -0,0 +1,67 @@
+package sdcard
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// noCard is an SPI bus without a card, which reads as all ones.
+type noCard struct{}
+
+func (noCard) Tx(w, r []byte) error {
+	for i := range r {
+		r[i] = 0xFF
+	}
+	return nil
+}
+
+func (noCard) Transfer(b byte) (byte, error) { return 0xFF, nil }
+
+func TestTrace(t *testing.T) {
+	d, _ := newSPICard()
+	var trace []string
+	d.SetTrace(func(cmd byte, arg uint32, r1 Response1, err error) {
+		trace = append(trace, fmt.Sprintf("CMD%d %#x %v %v", cmd, arg, r1, err))
+	})
+	buf := make([]byte, 512)
+	if err := d.WriteData(3, buf); err != nil {
+		t.Fatal(err)
+	}
+	d.acmd(ACMD22_SEND_NUM_WR_BLOCKS, 0)
+	d.cs.High()
+	d.cmd(CMD16_SET_BLOCKLEN, 512, 0xFF)
+	d.bus = noCard{}
+	d.cmd(CMD13_SEND_STATUS, 0, 0xFF)
+
+	want := []string{
+		"CMD24 0x3 ok <nil>",
+		"CMD55 0x0 ok <nil>",
+		"CMD22 0x0 ok <nil>",
+		"CMD16 0x200 illegal command <nil>",
+		"CMD13 0x0 no response SD_CARD_ERROR_CMD_TIMEOUT",
+	}
+	if got := strings.Join(trace, "\n"); got != strings.Join(want, "\n") {
+		t.Errorf("trace:\n%s\nwant:\n%s", got, strings.Join(want, "\n"))
+	}
+
+	d.SetTrace(nil)
+	d.cmd(CMD13_SEND_STATUS, 0, 0xFF)
+	if len(trace) != len(want) {
+		t.Error("traced after SetTrace(nil)")
+	}
+}
+
+func TestResponse1String(t *testing.T) {
+	for r, want := range map[Response1]string{
+		0:                                 "ok",
+		R1IdleState:                       "idle",
+		R1IdleState | R1IllegalCommand:    "idle|illegal command",
+		R1AddressError | R1ParameterError: "address error|parameter error",
+		R1NoResponse:                      "no response",
+	} {
+		if got := r.String(); got != want {
+			t.Errorf("%#x: %q, want %q", byte(r), got, want)
+		}
+	}
+}
@@ -0,0 +1,120 @@
+package sdcard
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+)
+
+// errNotProgrammed is the error of a PartialWriteError when the transfer
+// succeeded but the card programmed fewer blocks than it received.
+var errNotProgrammed = errors.New("sdcard: blocks received but not programmed")
+
+// PartialWriteError is returned by a multiple block write checked with
+// ACMD22 (see SetVerifyWrites) when fewer blocks were programmed than
+// written. The blocks from Start to Start+Written, excluded, hold the new
+// data; the write can be resumed from there.
+type PartialWriteError struct {
+	Start   uint32 // first block of the write
+	Written uint32 // number of blocks programmed
+	Err     error  // error that ended the write
+}
+
+func (e *PartialWriteError) Error() string {
+	return fmt.Sprintf("sdcard: %d blocks written from block %d: %v", e.Written, e.Start, e.Err)
+}
+
+func (e *PartialWriteError) Unwrap() error {
+	return e.Err
+}
+
+// SetVerifyWrites enables the check of multiple block writes with ACMD22:
+// after WriteBlocks and the BlockWriter, the card is asked how many blocks
+// it programmed without error, and a *PartialWriteError tells how many when
+// they are not all. Retries (see SetTimeouts) resume from the first block
+// not programmed.
+func (d *Device) SetVerifyWrites(enable bool) {
+	d.verify = enable
+}
+
+// WrittenBlocks returns the number of blocks programmed without error by the
+// last write, using ACMD22.
+func (d Device) WrittenBlocks() (uint32, error) {
+	if r := d.acmd(ACMD22_SEND_NUM_WR_BLOCKS, 0); r != 0 {
+		d.cs.High()
+		return 0, commandError(r, "SD_CARD_ERROR_ACMD22")
+	}
+	if err := d.waitStartBlock(); err != nil {
+		return 0, err
+	}
+	var buf [4]byte
+	if err := d.bus.Tx(dummy[:len(buf)], buf[:]); err != nil {
+		d.cs.High()
+		return 0, err
+	}
+	err := d.readCRC(0, buf[:])
+	d.cs.High()
+	return binary.BigEndian.Uint32(buf[:]), err
+}
+
+// verifyWrite checks a multiple block write that sent n blocks, ending with
+// err, when SetVerifyWrites is on. It returns the number of blocks
+// programmed, and errNotProgrammed if they are fewer than n without error.
+func (d Device) verifyWrite(n int, err error) (int, error) {
+	if !d.verify {
+		return n, err
+	}
+	written, verr := d.WrittenBlocks()
+	if verr != nil {
+		if err == nil {
+			err = verr
+		}
+		return n, err
+	}
+	if int(written) < n {
+		n = int(written)
+		if err == nil {
+			err = errNotProgrammed
+		}
+	}
+	return n, err
+}
+
+// SetVerifyWrites enables the check of multiple block writes with ACMD22:
+// after WriteBlocks, the card is asked how many blocks it programmed without
+// error, and a *PartialWriteError tells how many when they are not all.
+func (c *SDIOCard) SetVerifyWrites(enable bool) {
+	c.verify = enable
+}
+
+// WrittenBlocks returns the number of blocks programmed without error by the
+// last write, using ACMD22.
+func (c *SDIOCard) WrittenBlocks() (uint32, error) {
+	if _, err := c.cmd(CMD55_APP_CMD, c.rca, SDIOResponseR1); err != nil {
+		return 0, err
+	}
+	var buf [4]byte
+	req := SDIORequest{Cmd: ACMD22_SEND_NUM_WR_BLOCKS, Response: SDIOResponseR1, Data: buf[:]}
+	if err := c.do(&req); err != nil {
+		return 0, err
+	}
+	return binary.BigEndian.Uint32(buf[:]), nil
+}
+
+// verifyWrite checks a write of n blocks from start, which ended with err.
+func (c *SDIOCard) verifyWrite(start int64, n int, err error) error {
+	written, verr := c.WrittenBlocks()
+	if verr != nil {
+		if err == nil {
+			err = verr
+		}
+		return err
+	}
+	if int(written) >= n && err == nil {
+		return nil
+	}
+	if err == nil {
+		err = errNotProgrammed
+	}
+	return &PartialWriteError{Start: uint32(start), Written: written, Err: err}
+}
@@ -0,0 +1,75 @@
+package sdcard
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+)
+
+func TestVerifyWrites(t *testing.T) {
+	d, card := newSPICard()
+	d.SetVerifyWrites(true)
+	src := bytes.Repeat([]byte{1, 2, 3, 4}, 3*128)
+
+	if err := d.WriteBlocks(2, src); err != nil {
+		t.Fatal(err)
+	}
+	if n, err := d.WrittenBlocks(); n != 3 || err != nil {
+		t.Errorf("WrittenBlocks: %d, %v", n, err)
+	}
+
+	// The card acknowledged the last block, but did not program it.
+	card.unwritten = 1
+	var pe *PartialWriteError
+	if err := d.WriteBlocks(2, src); !errors.As(err, &pe) || pe.Start != 2 || pe.Written != 2 || pe.Err != errNotProgrammed {
+		t.Errorf("unprogrammed block: %v", err)
+	}
+
+	// A rejected block, without and with retries.
+	card.rejectBlock = 5
+	err := d.WriteBlocks(4, src)
+	var crcErr *CRCError
+	if !errors.As(err, &pe) || pe.Start != 4 || pe.Written != 1 || !errors.As(err, &crcErr) {
+		t.Errorf("rejected block: %v", err)
+	}
+	d.SetTimeouts(Timeouts{Retries: 1})
+	card.rejectBlock = 5
+	if err := d.WriteBlocks(4, src); err != nil {
+		t.Errorf("rejected block with retries: %v", err)
+	}
+	if !bytes.Equal(card.mem[4*512:7*512], src) {
+		t.Error("wrong data after the retry")
+	}
+
+	w, _ := d.OpenBlockRangeWriter(0, 4)
+	w.Write(src)
+	card.unwritten = 2
+	if err := w.Close(); !errors.As(err, &pe) || pe.Start != 0 || pe.Written != 1 {
+		t.Errorf("BlockWriter: %v", err)
+	}
+}
+
+func TestSDIOVerifyWrites(t *testing.T) {
+	host := &fakeHost{mem: make([]byte, 64*512)}
+	card := NewSDIO(host)
+	if err := card.Configure(); err != nil {
+		t.Fatal(err)
+	}
+	card.SetVerifyWrites(true)
+	src := make([]byte, 4*512)
+
+	host.cmds = nil
+	if err := card.WriteBlocks(8, src); err != nil {
+		t.Fatal(err)
+	}
+	want := []uint8{CMD25_WRITE_MULTIPLE_BLOCK, CMD12_STOP_TRANSMISSION, CMD55_APP_CMD, ACMD22_SEND_NUM_WR_BLOCKS}
+	if !bytes.Equal(host.cmds, want) {
+		t.Errorf("commands: got %v, want %v", host.cmds, want)
+	}
+
+	host.unwritten = 3
+	var pe *PartialWriteError
+	if err := card.WriteBlocks(8, src); !errors.As(err, &pe) || pe.Start != 8 || pe.Written != 1 || pe.Err != errNotProgrammed {
+		t.Errorf("unprogrammed blocks: %v", err)
+	}
+}
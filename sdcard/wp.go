@@ -0,0 +1,135 @@
+package sdcard
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// Standard capacity SD cards and MMC cards can write protect groups of
+// blocks with CMD28, so that writes and erases of these blocks fail until
+// the protection is cleared with CMD29. The size of the groups is given by
+// the CSD. High capacity cards do not support it.
+
+var errNoWriteProtect = errors.New("sdcard: write protect groups not supported")
+
+// SetWriteProtect write protects a group of blocks. The first group starts
+// at block 0, the size of the groups is given by
+// CSD.WriteProtectGroupSizeInSectors.
+func (d *Device) SetWriteProtect(group uint32) error {
+	return d.writeProtect(CMD28_SET_WRITE_PROT, group)
+}
+
+// ClearWriteProtect clears the write protection of a group of blocks.
+func (d *Device) ClearWriteProtect(group uint32) error {
+	return d.writeProtect(CMD29_CLR_WRITE_PROT, group)
+}
+
+// ReadWriteProtectBits returns the write protection of 32 groups starting at
+// group, with bit 0 set when group is protected. The bits of groups past
+// the end of the card are 0.
+func (d *Device) ReadWriteProtectBits(group uint32) (uint32, error) {
+	addr, err := d.groupAddress(group)
+	if err != nil {
+		return 0, err
+	}
+	defer d.cs.High()
+	if d.cmd(CMD30_SEND_WRITE_PROT, addr, 0xFF) != 0 {
+		return 0, fmt.Errorf("CMD30 error")
+	}
+	if err := d.waitStartBlock(); err != nil {
+		return 0, err
+	}
+	var buf [4]byte
+	if err := d.bus.Tx(dummy[:4], buf[:]); err != nil {
+		return 0, err
+	}
+	if err := d.readCRC(0, buf[:]); err != nil {
+		return 0, err
+	}
+	return uint32(buf[0])<<24 | uint32(buf[1])<<16 | uint32(buf[2])<<8 | uint32(buf[3]), nil
+}
+
+func (d *Device) writeProtect(cmd uint8, group uint32) error {
+	addr, err := d.groupAddress(group)
+	if err != nil {
+		return err
+	}
+	defer d.cs.High()
+	if d.cmd(cmd, addr, 0xFF) != 0 {
+		return fmt.Errorf("CMD%d error", cmd)
+	}
+	// The card is busy while it programs the protection bits.
+	return d.waitNotBusy(250 * time.Millisecond)
+}
+
+// groupAddress returns the argument of the write protect commands for a
+// group: the address of its first block.
+func (d *Device) groupAddress(group uint32) (uint32, error) {
+	if err := d.checkPresent(); err != nil {
+		return 0, err
+	}
+	size := uint32(0)
+	if d.CSD != nil {
+		size = d.CSD.WriteProtectGroupSizeInSectors()
+	}
+	if size == 0 {
+		return 0, errNoWriteProtect
+	}
+	block := uint64(group) * uint64(size)
+	if !d.blockAddressed() {
+		block <<= 9
+	}
+	if block >= 1<<32 {
+		return 0, fmt.Errorf("group %d out of range", group)
+	}
+	return uint32(block), nil
+}
+
+// SetWriteProtect write protects a group of blocks. The first group starts
+// at block 0, the size of the groups is given by
+// CSD.WriteProtectGroupSizeInSectors.
+func (c *SDIOCard) SetWriteProtect(group uint32) error {
+	return c.writeProtect(CMD28_SET_WRITE_PROT, group)
+}
+
+// ClearWriteProtect clears the write protection of a group of blocks.
+func (c *SDIOCard) ClearWriteProtect(group uint32) error {
+	return c.writeProtect(CMD29_CLR_WRITE_PROT, group)
+}
+
+// ReadWriteProtectBits returns the write protection of 32 groups starting at
+// group, with bit 0 set when group is protected. The bits of groups past
+// the end of the card are 0.
+func (c *SDIOCard) ReadWriteProtectBits(group uint32) (uint32, error) {
+	addr, err := c.groupAddress(group)
+	if err != nil {
+		return 0, err
+	}
+	var buf [4]byte
+	req := SDIORequest{Cmd: CMD30_SEND_WRITE_PROT, Arg: addr, Response: SDIOResponseR1, Data: buf[:]}
+	if err := c.do(&req); err != nil {
+		return 0, err
+	}
+	return uint32(buf[0])<<24 | uint32(buf[1])<<16 | uint32(buf[2])<<8 | uint32(buf[3]), nil
+}
+
+func (c *SDIOCard) writeProtect(cmd uint8, group uint32) error {
+	addr, err := c.groupAddress(group)
+	if err != nil {
+		return err
+	}
+	req := SDIORequest{Cmd: cmd, Arg: addr, Response: SDIOResponseR1b, BusyTimeout: 250 * time.Millisecond}
+	return c.do(&req)
+}
+
+func (c *SDIOCard) groupAddress(group uint32) (uint32, error) {
+	size := uint32(0)
+	if c.CSD != nil {
+		size = c.CSD.WriteProtectGroupSizeInSectors()
+	}
+	if size == 0 {
+		return 0, errNoWriteProtect
+	}
+	return c.address(int64(group) * int64(size))
+}
@@ -0,0 +1,55 @@
+package sdcard
+
+import "testing"
+
+func TestWriteProtectGroupSize(t *testing.T) {
+	sd := &CSD{WP_GRP_ENABLE: 1, SECTOR_SIZE: 31, WP_GRP_SIZE: 3, WRITE_BL_LEN: 9}
+	if size := sd.WriteProtectGroupSizeInSectors(); size != 128 {
+		t.Errorf("SD: %d sectors", size)
+	}
+	sd.WRITE_BL_LEN = 10
+	if size := sd.WriteProtectGroupSizeInSectors(); size != 256 {
+		t.Errorf("SD with 1024-byte blocks: %d sectors", size)
+	}
+	// ERASE_GRP_SIZE 15, ERASE_GRP_MULT 1, WP_GRP_SIZE 3.
+	mmc := &CSD{MMC: true, WP_GRP_ENABLE: 1, SECTOR_SIZE: 120, WP_GRP_SIZE: 35, WRITE_BL_LEN: 9}
+	if size := mmc.WriteProtectGroupSizeInSectors(); size != 128 {
+		t.Errorf("MMC: %d sectors", size)
+	}
+	if size := (&CSD{CSD_STRUCTURE: 1}).WriteProtectGroupSizeInSectors(); size != 0 {
+		t.Errorf("SDHC: %d sectors", size)
+	}
+}
+
+func TestSDIOCardWriteProtect(t *testing.T) {
+	host := &fakeHost{mem: make([]byte, 64*512), wpGroup: 8}
+	c := NewSDIO(host)
+	if err := c.Configure(); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.SetWriteProtect(0); err != errNoWriteProtect {
+		t.Errorf("SDHC card: %v", err)
+	}
+
+	c.CSD = &CSD{WP_GRP_ENABLE: 1, SECTOR_SIZE: 7, WRITE_BL_LEN: 9}
+	if err := c.SetWriteProtect(1); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.SetWriteProtect(3); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.ClearWriteProtect(1); err != nil {
+		t.Fatal(err)
+	}
+	if host.args[len(host.args)-1] != 8 {
+		t.Errorf("group 1 sent at address %d", host.args[len(host.args)-1])
+	}
+	bits, err := c.ReadWriteProtectBits(0)
+	if err != nil || bits != 0x08 {
+		t.Errorf("ReadWriteProtectBits: %08X, %v", bits, err)
+	}
+	bits, _ = c.ReadWriteProtectBits(2)
+	if bits != 0x02 {
+		t.Errorf("ReadWriteProtectBits from group 2: %08X", bits)
+	}
+}
@@ -0,0 +1,330 @@
+// Package xmodem transfers files over a serial port with the XMODEM and
+// YMODEM protocols, to get the logs off an SD card or put a firmware image
+// on it without a network: the terminal programs of a PC, such as Tera Term,
+// minicom with the sz and rz commands of lrzsz, or ExtraPuTTY, speak them.
+//
+// Receive and Send transfer a file with XMODEM, with a CRC16 and blocks of
+// 1024 bytes (XMODEM-1K); blocks of 128 bytes are received too. ReceiveFiles
+// and SendFiles transfer files with YMODEM, which gives their name and size:
+//
+//	conn := xmodem.New(machine.Serial)
+//	n, err := conn.ReceiveFiles(func(name string, size int64) (io.WriteCloser, error) {
+//		return fsys.Create(name)
+//	})
+//
+// The writer may also be a range of blocks of the card, opened with
+// OpenBlockRangeWriter, for a firmware image read with the firmware
+// package. The checksums of the blocks are the CRC16 of the SD data blocks.
+package xmodem // import "tinygo.org/x/drivers/sdcard/xmodem"
+
+import (
+	"errors"
+	"io"
+	"time"
+
+	"tinygo.org/x/drivers"
+	"tinygo.org/x/drivers/sdcard"
+)
+
+// Control bytes of the protocol.
+const (
+	soh     = 0x01 // starts a block of 128 bytes
+	stx     = 0x02 // starts a block of 1024 bytes
+	eot     = 0x04 // ends the file
+	ack     = 0x06
+	nak     = 0x15
+	can     = 0x18 // cancels the transfer, sent twice
+	crcMode = 'C'  // asks for a transfer with CRC16
+	sub     = 0x1A // pads the last block
+)
+
+var (
+	// ErrCanceled is returned when the other side cancels the transfer.
+	ErrCanceled = errors.New("xmodem: transfer canceled")
+
+	errTimeout  = errors.New("xmodem: timeout")
+	errRetries  = errors.New("xmodem: too many errors")
+	errSequence = errors.New("xmodem: block out of sequence")
+	errBlock    = errors.New("xmodem: bad block")
+)
+
+// Conn transfers files over a serial port. It must not be used
+// concurrently.
+type Conn struct {
+	port drivers.UART
+
+	// Timeout is the time to wait for the other side: for an answer to a
+	// block, or for the next block. It defaults to 3 seconds if zero. A
+	// third of it is the longest pause within a block.
+	Timeout time.Duration
+
+	// Retries is the number of times a block is sent or asked for again
+	// before the transfer is canceled. It defaults to 10 if zero. A side
+	// waits for the other to start for as many timeouts.
+	Retries int
+
+	buf [3 + 1024 + 2]byte
+}
+
+// New returns a Conn on a configured serial port.
+func New(port drivers.UART) *Conn {
+	return &Conn{port: port}
+}
+
+func (c *Conn) timeout() time.Duration {
+	if c.Timeout == 0 {
+		return 3 * time.Second
+	}
+	return c.Timeout
+}
+
+func (c *Conn) retries() int {
+	if c.Retries == 0 {
+		return 10
+	}
+	return c.Retries
+}
+
+// Receive receives a file with XMODEM, and writes it to w. It returns the
+// number of bytes written. XMODEM doesn't tell the size of the file: the
+// padding of the last block, usually 0x1A bytes, is written too.
+func (c *Conn) Receive(w io.Writer) (int64, error) {
+	return c.receive(w, -1)
+}
+
+// receive receives the blocks of a file, starting with block 1, and writes
+// them to w. The padding past size is dropped, when it is not negative.
+func (c *Conn) receive(w io.Writer, size int64) (int64, error) {
+	var n int64
+	seq := byte(1)
+	reply := byte(crcMode)
+	for errs := 0; ; {
+		h, blk, data, err := c.readBlock(reply)
+		switch {
+		case err == errTimeout || err == errBlock:
+			if errs++; errs > c.retries() {
+				c.cancel()
+				return n, errRetries
+			}
+			// Until the first block, the sender is asked to start.
+			if err == errBlock || seq > 1 {
+				reply = nak
+			}
+			continue
+		case err != nil:
+			return n, err
+		case h == eot:
+			_, err := c.port.Write([]byte{ack})
+			return n, err
+		}
+		switch blk {
+		case seq:
+			if size >= 0 && int64(len(data)) > size-n {
+				data = data[:size-n]
+			}
+			k, err := w.Write(data)
+			n += int64(k)
+			if err != nil {
+				c.cancel()
+				return n, err
+			}
+			seq++
+			errs = 0
+		case seq - 1:
+			// Our answer was lost, and the block sent again.
+		default:
+			c.cancel()
+			return n, errSequence
+		}
+		reply = ack
+	}
+}
+
+// readBlock sends reply, unless 0, and reads the next block, or the end of
+// the file. It returns the byte starting the block, its number and its data.
+func (c *Conn) readBlock(reply byte) (h, blk byte, data []byte, err error) {
+	if reply != 0 {
+		if _, err := c.port.Write([]byte{reply}); err != nil {
+			return 0, 0, nil, err
+		}
+	}
+	h, err = c.readByte(time.Now().Add(c.timeout()))
+	if err != nil {
+		return 0, 0, nil, err
+	}
+	size := 128
+	switch h {
+	case eot:
+		return h, 0, nil, nil
+	case can:
+		if b, err := c.readByte(time.Now().Add(c.timeout() / 3)); err == nil && b == can {
+			return 0, 0, nil, ErrCanceled
+		}
+		return 0, 0, nil, errBlock
+	case soh:
+	case stx:
+		size = 1024
+	default:
+		c.purge()
+		return 0, 0, nil, errBlock
+	}
+	b := c.buf[:2+size+2]
+	for i := range b {
+		if b[i], err = c.readByte(time.Now().Add(c.timeout() / 3)); err != nil {
+			if err == errTimeout {
+				err = errBlock
+			}
+			return 0, 0, nil, err
+		}
+	}
+	data = b[2 : 2+size]
+	crc := uint16(b[2+size])<<8 | uint16(b[3+size])
+	if b[0] != ^b[1] || sdcard.CRC16(data) != crc {
+		c.purge()
+		return 0, 0, nil, errBlock
+	}
+	return h, b[0], data, nil
+}
+
+// Send sends the data of r with XMODEM, in blocks of 1024 bytes, or 128
+// bytes for the end of the data. The last block is padded with 0x1A bytes.
+func (c *Conn) Send(r io.Reader) error {
+	if err := c.waitStart(); err != nil {
+		return err
+	}
+	return c.send(r)
+}
+
+// send sends the data of r in blocks starting with block 1, then the end of
+// the file.
+func (c *Conn) send(r io.Reader) error {
+	for seq := byte(1); ; seq++ {
+		n, err := io.ReadFull(r, c.buf[3:3+1024])
+		if err == io.EOF {
+			break
+		}
+		if err != nil && err != io.ErrUnexpectedEOF {
+			c.cancel()
+			return err
+		}
+		size := 1024
+		if n <= 128 {
+			size = 128
+		}
+		for i := n; i < size; i++ {
+			c.buf[3+i] = sub
+		}
+		if err := c.sendBlock(seq, size); err != nil {
+			return err
+		}
+		if n < 1024 {
+			break
+		}
+	}
+	for tries := 0; tries <= c.retries(); tries++ {
+		if _, err := c.port.Write([]byte{eot}); err != nil {
+			return err
+		}
+		switch b, err := c.answer(); {
+		case err == errTimeout:
+		case err != nil:
+			return err
+		case b == ack:
+			return nil
+		}
+	}
+	return errRetries
+}
+
+// sendBlock sends block seq, of size bytes stored in the buffer, until the
+// receiver takes it.
+func (c *Conn) sendBlock(seq byte, size int) error {
+	h := byte(soh)
+	if size == 1024 {
+		h = stx
+	}
+	b := c.buf[:3+size+2]
+	b[0], b[1], b[2] = h, seq, ^seq
+	crc := sdcard.CRC16(b[3 : 3+size])
+	b[3+size], b[4+size] = byte(crc>>8), byte(crc)
+	for tries := 0; tries <= c.retries(); tries++ {
+		if _, err := c.port.Write(b); err != nil {
+			return err
+		}
+		switch a, err := c.answer(); {
+		case err == errTimeout:
+		case err != nil:
+			return err
+		case a == ack:
+			return nil
+		}
+	}
+	c.cancel()
+	return errRetries
+}
+
+// answer waits for the answer of the receiver to a block: ACK or NAK. The
+// 'C' it sent while waiting for the first block are skipped.
+func (c *Conn) answer() (byte, error) {
+	deadline := time.Now().Add(c.timeout())
+	for {
+		b, err := c.readByte(deadline)
+		if err != nil {
+			return 0, err
+		}
+		switch b {
+		case ack, nak:
+			return b, nil
+		case can:
+			if b, err := c.readByte(time.Now().Add(c.timeout() / 3)); err == nil && b == can {
+				return 0, ErrCanceled
+			}
+		}
+	}
+}
+
+// waitStart waits for the receiver to ask for a transfer with CRC16.
+func (c *Conn) waitStart() error {
+	deadline := time.Now().Add(time.Duration(c.retries()) * c.timeout())
+	for {
+		b, err := c.readByte(deadline)
+		if err != nil {
+			return err
+		}
+		switch b {
+		case crcMode:
+			return nil
+		case can:
+			if b, err := c.readByte(time.Now().Add(c.timeout() / 3)); err == nil && b == can {
+				return ErrCanceled
+			}
+		}
+	}
+}
+
+// cancel cancels the transfer.
+func (c *Conn) cancel() {
+	c.port.Write([]byte{can, can})
+}
+
+// purge drops the bytes received until the line is idle, such as the rest
+// of a bad block.
+func (c *Conn) purge() {
+	for {
+		if _, err := c.readByte(time.Now().Add(c.timeout() / 3)); err != nil {
+			return
+		}
+	}
+}
+
+func (c *Conn) readByte(deadline time.Time) (byte, error) {
+	for c.port.Buffered() == 0 {
+		if time.Now().After(deadline) {
+			return 0, errTimeout
+		}
+		drivers.Yield()
+	}
+	var b [1]byte
+	_, err := c.port.Read(b[:])
+	return b[0], err
+}
@@ -0,0 +1,195 @@
+package xmodem
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"runtime"
+	"sync"
+	"testing"
+	"time"
+)
+
+// line is one direction of a serial link.
+type line struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+// port is an end of a serial link. filter, if set, may change what is
+// written.
+type port struct {
+	rx, tx *line
+	filter func(b []byte) []byte
+}
+
+func link() (*port, *port) {
+	a, b := &line{}, &line{}
+	return &port{rx: a, tx: b}, &port{rx: b, tx: a}
+}
+
+func (p *port) Read(b []byte) (int, error) {
+	p.rx.mu.Lock()
+	defer p.rx.mu.Unlock()
+	return p.rx.buf.Read(b)
+}
+
+func (p *port) Write(b []byte) (int, error) {
+	n := len(b)
+	if p.filter != nil {
+		b = p.filter(append([]byte(nil), b...))
+	}
+	p.tx.mu.Lock()
+	defer p.tx.mu.Unlock()
+	p.tx.buf.Write(b)
+	return n, nil
+}
+
+func (p *port) Buffered() int {
+	p.rx.mu.Lock()
+	n := p.rx.buf.Len()
+	p.rx.mu.Unlock()
+	if n == 0 {
+		runtime.Gosched()
+	}
+	return n
+}
+
+func testData(n int) []byte {
+	data := make([]byte, n)
+	for i := range data {
+		data[i] = byte(i * 13)
+	}
+	return data
+}
+
+func newConns() (*Conn, *Conn, *port) {
+	a, b := link()
+	sender, receiver := New(a), New(b)
+	sender.Timeout = 100 * time.Millisecond
+	receiver.Timeout = 100 * time.Millisecond
+	return sender, receiver, a
+}
+
+func TestXMODEM(t *testing.T) {
+	sender, receiver, _ := newConns()
+	data := testData(2100)
+	errc := make(chan error, 1)
+	go func() { errc <- sender.Send(bytes.NewReader(data)) }()
+
+	var got bytes.Buffer
+	n, err := receiver.Receive(&got)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := <-errc; err != nil {
+		t.Fatal(err)
+	}
+	// Two blocks of 1024 bytes, then one of 128 padded with 0x1A.
+	if n != 2176 || !bytes.Equal(got.Bytes()[:2100], data) || !bytes.Equal(got.Bytes()[2100:], bytes.Repeat([]byte{sub}, 76)) {
+		t.Errorf("received %d bytes", n)
+	}
+}
+
+func TestXMODEMErrors(t *testing.T) {
+	sender, receiver, p := newConns()
+	data := testData(3000)
+
+	// The second block is corrupted, the third one lost, once each.
+	blocks := 0
+	p.filter = func(b []byte) []byte {
+		if len(b) < 100 {
+			return b
+		}
+		blocks++
+		switch blocks {
+		case 2:
+			b[500] ^= 0xFF
+		case 4:
+			return nil
+		}
+		return b
+	}
+	errc := make(chan error, 1)
+	go func() { errc <- sender.Send(bytes.NewReader(data)) }()
+	var got bytes.Buffer
+	if _, err := receiver.Receive(&got); err != nil {
+		t.Fatal(err)
+	}
+	if err := <-errc; err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got.Bytes()[:3000], data) || blocks < 5 {
+		t.Errorf("received %d bytes in %d blocks", got.Len(), blocks)
+	}
+}
+
+type failWriter struct{}
+
+func (failWriter) Write(b []byte) (int, error) {
+	return 0, errors.New("card full")
+}
+
+func TestCancel(t *testing.T) {
+	sender, receiver, _ := newConns()
+	errc := make(chan error, 1)
+	go func() { errc <- sender.Send(bytes.NewReader(testData(5000))) }()
+	if _, err := receiver.Receive(failWriter{}); err == nil || err.Error() != "card full" {
+		t.Errorf("Receive: %v", err)
+	}
+	if err := <-errc; err != ErrCanceled {
+		t.Errorf("Send: %v", err)
+	}
+
+	// Nobody sends.
+	_, receiver, _ = newConns()
+	receiver.Retries = 2
+	if _, err := receiver.Receive(io.Discard); err != errRetries {
+		t.Errorf("Receive without sender: %v", err)
+	}
+}
+
+type file struct {
+	bytes.Buffer
+	name   string
+	size   int64
+	closed bool
+}
+
+func (f *file) Close() error {
+	f.closed = true
+	return nil
+}
+
+func TestYMODEM(t *testing.T) {
+	sender, receiver, _ := newConns()
+	files := []File{
+		{Name: "log/TEMP00001.CSV", Size: 2000, Data: bytes.NewReader(testData(2000))},
+		{Name: "empty", Size: 0, Data: bytes.NewReader(nil)},
+		{Name: "fw.img", Size: 100, Data: bytes.NewReader(testData(100))},
+	}
+	errc := make(chan error, 1)
+	go func() { errc <- sender.SendFiles(files...) }()
+
+	var got []*file
+	n, err := receiver.ReceiveFiles(func(name string, size int64) (io.WriteCloser, error) {
+		f := &file{name: name, size: size}
+		got = append(got, f)
+		return f, nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := <-errc; err != nil {
+		t.Fatal(err)
+	}
+	if n != 3 || len(got) != 3 {
+		t.Fatalf("received %d files", n)
+	}
+	for i, f := range got {
+		if f.name != files[i].Name || f.size != files[i].Size || !f.closed ||
+			!bytes.Equal(f.Bytes(), testData(int(f.size))) {
+			t.Errorf("file %d: %q of %d bytes, got %d bytes", i, f.name, f.size, f.Len())
+		}
+	}
+}
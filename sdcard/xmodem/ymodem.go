@@ -0,0 +1,136 @@
+package xmodem
+
+import (
+	"errors"
+	"io"
+	"strconv"
+)
+
+// YMODEM sends each file after a block 0 holding its name and size, and
+// ends the batch with a block 0 without name.
+
+var errNameLength = errors.New("xmodem: file name too long")
+
+// File is a file sent with YMODEM.
+type File struct {
+	Name string
+	Size int64
+	Data io.Reader
+}
+
+// ReceiveFiles receives files with YMODEM. For each file, create is called
+// with its name, as sent, and its size, or -1 if the sender didn't send it,
+// and returns the writer the file is written to, which is closed once the
+// file is complete. It returns the number of files received.
+func (c *Conn) ReceiveFiles(create func(name string, size int64) (io.WriteCloser, error)) (int, error) {
+	for files := 0; ; files++ {
+		name, size, err := c.receiveHeader()
+		if err != nil || name == "" {
+			return files, err
+		}
+		w, err := create(name, size)
+		if err != nil {
+			c.cancel()
+			return files, err
+		}
+		_, err = c.receive(w, size)
+		if cerr := w.Close(); err == nil {
+			err = cerr
+		}
+		if err != nil {
+			return files, err
+		}
+	}
+}
+
+// receiveHeader receives block 0, and returns the name and size of the
+// file, or an empty name at the end of the batch.
+func (c *Conn) receiveHeader() (string, int64, error) {
+	reply := byte(crcMode)
+	for errs := 0; errs <= c.retries(); errs++ {
+		h, blk, data, err := c.readBlock(reply)
+		switch {
+		case err == errTimeout:
+			reply = crcMode
+			continue
+		case err == errBlock:
+			reply = nak
+			continue
+		case err != nil:
+			return "", 0, err
+		case h == eot:
+			// Our answer to the end of the last file was lost.
+			reply = ack
+			continue
+		case blk != 0:
+			c.cancel()
+			return "", 0, errSequence
+		}
+		if _, err := c.port.Write([]byte{ack}); err != nil {
+			return "", 0, err
+		}
+		name, rest := field(data, 0)
+		size, _ := field(rest, ' ')
+		n, err := strconv.ParseInt(size, 10, 64)
+		if err != nil {
+			n = -1
+		}
+		return name, n, nil
+	}
+	c.cancel()
+	return "", 0, errRetries
+}
+
+// field returns the bytes of b until sep or a NUL byte, and the bytes after.
+func field(b []byte, sep byte) (string, []byte) {
+	for i, c := range b {
+		if c == sep || c == 0 {
+			return string(b[:i]), b[i+1:]
+		}
+	}
+	return string(b), nil
+}
+
+// SendFiles sends files with YMODEM, and ends the batch.
+func (c *Conn) SendFiles(files ...File) error {
+	for _, f := range files {
+		if err := c.waitStart(); err != nil {
+			return err
+		}
+		if err := c.sendHeader(f.Name, f.Size); err != nil {
+			return err
+		}
+		if err := c.waitStart(); err != nil {
+			return err
+		}
+		if err := c.send(f.Data); err != nil {
+			return err
+		}
+	}
+	if err := c.waitStart(); err != nil {
+		return err
+	}
+	return c.sendHeader("", 0)
+}
+
+// sendHeader sends block 0 with the name and size of a file, or an empty
+// block 0 when the name is empty.
+func (c *Conn) sendHeader(name string, size int64) error {
+	data := c.buf[3 : 3+1024]
+	for i := range data {
+		data[i] = 0
+	}
+	n := 0
+	if name != "" {
+		if len(name) > 1000 {
+			c.cancel()
+			return errNameLength
+		}
+		n = copy(data, name) + 1
+		n += len(strconv.AppendInt(data[n:n], size, 10))
+	}
+	if n < 128 {
+		return c.sendBlock(0, 128)
+	}
+	return c.sendBlock(0, 1024)
+}
@@ -0,0 +1,39 @@
+package drivers
+
+// SelfTestCheck is the outcome of a single check done by the SelfTest method
+// of a driver. The unit of Value, Min and Max is documented by the driver.
+type SelfTestCheck struct {
+	Name  string
+	Value int32
+	Min   int32 // lowest accepted value
+	Max   int32 // highest accepted value
+}
+
+// Passed returns whether Value is inside the accepted range.
+func (c SelfTestCheck) Passed() bool {
+	return c.Value >= c.Min && c.Value <= c.Max
+}
+
+// SelfTestResult is the list of checks done by the SelfTest method of a
+// driver, in the order they were done. It is meant to be logged or sent to a
+// test fixture as a whole, so that a failing unit can be diagnosed without
+// running the test again.
+type SelfTestResult []SelfTestCheck
+
+// Add appends a check to the result.
+func (r *SelfTestResult) Add(name string, value, min, max int32) {
+	*r = append(*r, SelfTestCheck{Name: name, Value: value, Min: min, Max: max})
+}
+
+// Passed returns whether all checks passed. An empty result did not pass.
+func (r SelfTestResult) Passed() bool {
+	if len(r) == 0 {
+		return false
+	}
+	for _, c := range r {
+		if !c.Passed() {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,372 @@
+// Package settings keeps the settings of a device, such as a name, a
+// calibration or the choices of its user, in a small non-volatile storage:
+// blocks of an SD card, an AT24Cx EEPROM or an SPI NOR flash.
+//
+// The settings are declared with their key and default value, much like the
+// flags of the flag package, then loaded, changed and committed:
+//
+//	store := settings.New(eeprom, 0, 1024)
+//	volume := store.Int("volume", 50)
+//	name := store.String("name", "sensor")
+//	err := store.Load()
+//	...
+//	*volume = 80
+//	err = store.Commit()
+//
+// The storage is split in two banks, written in turn. A commit writes the
+// bank not holding the current settings, with a sequence number and a CRC,
+// so that a power loss in the middle of it leaves the previous settings.
+//
+// Settings are stored with their key and type: a setting added to a new
+// firmware takes its default value, and those removed are dropped at the
+// next commit.
+package settings // import "tinygo.org/x/drivers/settings"
+
+import (
+	"encoding/binary"
+	"errors"
+	"hash/crc32"
+	"io"
+	"math"
+)
+
+var (
+	// ErrNoSettings is returned by Load when the storage holds no valid
+	// settings, as on the first boot. The settings keep their defaults.
+	ErrNoSettings = errors.New("settings: no settings stored")
+
+	errTooLarge   = errors.New("settings: settings larger than a bank")
+	errValueSize  = errors.New("settings: key or value longer than 255 bytes")
+	errEraseAlign = errors.New("settings: banks not aligned on erase blocks")
+)
+
+const (
+	magic      = "TGST"
+	headerSize = 16
+)
+
+// Types of the values, as stored.
+const (
+	typeBool = iota + 1
+	typeInt
+	typeUint
+	typeFloat
+	typeString
+)
+
+// Storage is the storage of the settings: an SD card, an EEPROM or a flash
+// chip. Storages that implement Eraser are erased before they are written,
+// as needed by flash; those that implement Syncer are synced after.
+type Storage interface {
+	io.ReaderAt
+	io.WriterAt
+}
+
+// Eraser is a storage that must be erased before it is written, such as a
+// flash chip. It is the erase part of the BlockDevice interface of TinyFS.
+type Eraser interface {
+	EraseBlockSize() int64
+	EraseBlocks(start, len int64) error
+}
+
+// Syncer is a storage that buffers writes, such as an SD card.
+type Syncer interface {
+	Sync() error
+}
+
+type setting struct {
+	key string
+	ptr interface{} // *bool, *int32, *uint32, *float32 or *string
+	def interface{}
+}
+
+// Store is a set of settings kept in a storage.
+type Store struct {
+	dev      Storage
+	off      int64
+	bankSize int64
+	seq      uint32
+	bank     int // bank of the current settings, -1 if none
+	settings []setting
+}
+
+// New returns a store of settings in the size bytes of dev at offset off.
+// They are split in two banks, each of which must hold all the settings
+// with 16 bytes more, and for a storage that is erased, such as a flash
+// chip, be made of whole erase blocks.
+//
+// This function only creates the Store object, it does not touch the
+// device.
+func New(dev Storage, off, size int64) *Store {
+	return &Store{dev: dev, off: off, bankSize: size / 2, bank: -1}
+}
+
+// Bool declares a boolean setting, and returns a pointer to its value.
+func (s *Store) Bool(key string, def bool) *bool {
+	p := new(bool)
+	s.add(key, p, def)
+	return p
+}
+
+// Int declares an integer setting, and returns a pointer to its value.
+func (s *Store) Int(key string, def int32) *int32 {
+	p := new(int32)
+	s.add(key, p, def)
+	return p
+}
+
+// Uint declares an unsigned integer setting, and returns a pointer to its
+// value.
+func (s *Store) Uint(key string, def uint32) *uint32 {
+	p := new(uint32)
+	s.add(key, p, def)
+	return p
+}
+
+// Float declares a floating point setting, and returns a pointer to its
+// value.
+func (s *Store) Float(key string, def float32) *float32 {
+	p := new(float32)
+	s.add(key, p, def)
+	return p
+}
+
+// String declares a string setting, of up to 255 bytes, and returns a
+// pointer to its value.
+func (s *Store) String(key string, def string) *string {
+	p := new(string)
+	s.add(key, p, def)
+	return p
+}
+
+func (s *Store) add(key string, ptr, def interface{}) {
+	for i := range s.settings {
+		if s.settings[i].key == key {
+			panic("settings: " + key + " declared twice")
+		}
+	}
+	s.settings = append(s.settings, setting{key: key, ptr: ptr, def: def})
+	s.settings[len(s.settings)-1].reset()
+}
+
+// Reset sets all the settings to their default values. They are stored by
+// the next Commit.
+func (s *Store) Reset() {
+	for i := range s.settings {
+		s.settings[i].reset()
+	}
+}
+
+// Load reads the settings from the storage, from the bank committed last.
+// Settings that are not stored keep their default values. It returns
+// ErrNoSettings when none of the banks is valid.
+func (s *Store) Load() error {
+	s.Reset()
+	var data []byte
+	s.bank = -1
+	for bank := 0; bank < 2; bank++ {
+		seq, d, err := s.readBank(bank)
+		if err != nil {
+			return err
+		}
+		if d != nil && (s.bank < 0 || int32(seq-s.seq) > 0) {
+			s.bank, s.seq, data = bank, seq, d
+		}
+	}
+	if s.bank < 0 {
+		return ErrNoSettings
+	}
+	s.decode(data)
+	return nil
+}
+
+// Commit writes the settings to the storage. Should the power fail before
+// it returns, the settings committed before are kept.
+func (s *Store) Commit() error {
+	data, err := s.encode()
+	if err != nil {
+		return err
+	}
+	if int64(len(data)) > s.bankSize {
+		return errTooLarge
+	}
+	bank := 0
+	if s.bank == 0 {
+		bank = 1
+	}
+	seq := s.seq + 1
+	off := s.off + int64(bank)*s.bankSize
+
+	if e, ok := s.dev.(Eraser); ok {
+		size := e.EraseBlockSize()
+		if off%size != 0 || s.bankSize%size != 0 {
+			return errEraseAlign
+		}
+		if err := e.EraseBlocks(off/size, s.bankSize/size); err != nil {
+			return err
+		}
+	}
+	// The header goes last, once the settings it checks are written.
+	binary.LittleEndian.PutUint32(data[4:], seq)
+	binary.LittleEndian.PutUint32(data[8:], uint32(len(data)-headerSize))
+	binary.LittleEndian.PutUint32(data[12:], checksum(data[4:12], data[headerSize:]))
+	if _, err := s.dev.WriteAt(data[headerSize:], off+headerSize); err != nil {
+		return err
+	}
+	if err := s.sync(); err != nil {
+		return err
+	}
+	if _, err := s.dev.WriteAt(data[:headerSize], off); err != nil {
+		return err
+	}
+	if err := s.sync(); err != nil {
+		return err
+	}
+	s.bank, s.seq = bank, seq
+	return nil
+}
+
+func (s *Store) sync() error {
+	if syncer, ok := s.dev.(Syncer); ok {
+		return syncer.Sync()
+	}
+	return nil
+}
+
+// readBank reads a bank, and returns its sequence number and settings, or
+// nil settings when the bank is not valid.
+func (s *Store) readBank(bank int) (uint32, []byte, error) {
+	off := s.off + int64(bank)*s.bankSize
+	var h [headerSize]byte
+	if _, err := s.dev.ReadAt(h[:], off); err != nil {
+		return 0, nil, err
+	}
+	le := binary.LittleEndian
+	length := int64(le.Uint32(h[8:]))
+	if string(h[:4]) != magic || length > s.bankSize-headerSize {
+		return 0, nil, nil
+	}
+	data := make([]byte, length)
+	if _, err := s.dev.ReadAt(data, off+headerSize); err != nil {
+		return 0, nil, err
+	}
+	if le.Uint32(h[12:]) != checksum(h[4:12], data) {
+		return 0, nil, nil
+	}
+	return le.Uint32(h[4:]), data, nil
+}
+
+// checksum returns the CRC-32 of the sequence number and length of a bank,
+// followed by its settings.
+func checksum(header, data []byte) uint32 {
+	return crc32.Update(crc32.ChecksumIEEE(header), crc32.IEEETable, data)
+}
+
+// encode returns the header, to be filled, followed by the settings, each
+// as the length and bytes of its key, its type, and the length and bytes of
+// its value.
+func (s *Store) encode() ([]byte, error) {
+	data := make([]byte, headerSize, 64)
+	copy(data, magic)
+	var v [4]byte
+	for i := range s.settings {
+		st := &s.settings[i]
+		var typ byte
+		var val []byte
+		switch p := st.ptr.(type) {
+		case *bool:
+			typ, val = typeBool, v[:1]
+			v[0] = 0
+			if *p {
+				v[0] = 1
+			}
+		case *int32:
+			typ, val = typeInt, v[:]
+			binary.LittleEndian.PutUint32(v[:], uint32(*p))
+		case *uint32:
+			typ, val = typeUint, v[:]
+			binary.LittleEndian.PutUint32(v[:], *p)
+		case *float32:
+			typ, val = typeFloat, v[:]
+			binary.LittleEndian.PutUint32(v[:], math.Float32bits(*p))
+		case *string:
+			typ, val = typeString, []byte(*p)
+		}
+		if len(st.key) > 255 || len(val) > 255 {
+			return nil, errValueSize
+		}
+		data = append(data, byte(len(st.key)))
+		data = append(data, st.key...)
+		data = append(data, typ, byte(len(val)))
+		data = append(data, val...)
+	}
+	return data, nil
+}
+
+// decode sets the settings stored in data. Unknown keys, and values of
+// another type than declared, are skipped.
+func (s *Store) decode(data []byte) {
+	for len(data) > 0 {
+		n := int(data[0])
+		if len(data) < 1+n+2 {
+			return
+		}
+		key := data[1 : 1+n]
+		typ, size := data[1+n], int(data[2+n])
+		data = data[3+n:]
+		if len(data) < size {
+			return
+		}
+		val := data[:size]
+		data = data[size:]
+		for i := range s.settings {
+			if s.settings[i].key == string(key) {
+				s.settings[i].set(typ, val)
+				break
+			}
+		}
+	}
+}
+
+func (st *setting) reset() {
+	switch p := st.ptr.(type) {
+	case *bool:
+		*p = st.def.(bool)
+	case *int32:
+		*p = st.def.(int32)
+	case *uint32:
+		*p = st.def.(uint32)
+	case *float32:
+		*p = st.def.(float32)
+	case *string:
+		*p = st.def.(string)
+	}
+}
+
+// set sets the value of a setting from its stored type and bytes, if they
+// match its declared type.
+func (st *setting) set(typ byte, val []byte) {
+	le := binary.LittleEndian
+	switch p := st.ptr.(type) {
+	case *bool:
+		if typ == typeBool && len(val) == 1 {
+			*p = val[0] != 0
+		}
+	case *int32:
+		if typ == typeInt && len(val) == 4 {
+			*p = int32(le.Uint32(val))
+		}
+	case *uint32:
+		if typ == typeUint && len(val) == 4 {
+			*p = le.Uint32(val)
+		}
+	case *float32:
+		if typ == typeFloat && len(val) == 4 {
+			*p = math.Float32frombits(le.Uint32(val))
+		}
+	case *string:
+		if typ == typeString {
+			*p = string(val)
+		}
+	}
+}
@@ -0,0 +1,179 @@
+package settings
+
+import (
+	"errors"
+	"testing"
+)
+
+// eeprom is a storage in memory.
+type eeprom struct {
+	data []byte
+
+	// failAfter makes the write after the given number of bytes fail, as
+	// with a power loss.
+	failAfter int
+}
+
+var errPowerLoss = errors.New("power loss")
+
+func (m *eeprom) ReadAt(buf []byte, off int64) (int, error) {
+	return copy(buf, m.data[off:]), nil
+}
+
+func (m *eeprom) WriteAt(buf []byte, off int64) (int, error) {
+	if m.failAfter > 0 {
+		if m.failAfter <= len(buf) {
+			n := copy(m.data[off:], buf[:m.failAfter])
+			m.failAfter = -1
+			return n, errPowerLoss
+		}
+		m.failAfter -= len(buf)
+	} else if m.failAfter < 0 {
+		return 0, errPowerLoss
+	}
+	return copy(m.data[off:], buf), nil
+}
+
+// flash is a storage in memory that must be erased, in blocks of 256
+// bytes, before it is written.
+type flash struct {
+	eeprom
+	erases int
+}
+
+func (f *flash) WriteAt(buf []byte, off int64) (int, error) {
+	for i, b := range buf {
+		f.data[off+int64(i)] &= b
+	}
+	return len(buf), nil
+}
+
+func (f *flash) EraseBlockSize() int64 { return 256 }
+
+func (f *flash) EraseBlocks(start, len int64) error {
+	f.erases++
+	for i := start * 256; i < (start+len)*256; i++ {
+		f.data[i] = 0xFF
+	}
+	return nil
+}
+
+type values struct {
+	enabled *bool
+	volume  *int32
+	id      *uint32
+	gain    *float32
+	name    *string
+}
+
+func declare(s *Store) values {
+	return values{
+		enabled: s.Bool("enabled", true),
+		volume:  s.Int("volume", -5),
+		id:      s.Uint("id", 7),
+		gain:    s.Float("gain", 1.5),
+		name:    s.String("name", "sensor"),
+	}
+}
+
+func TestStore(t *testing.T) {
+	mem := &eeprom{data: make([]byte, 1024)}
+	s := New(mem, 256, 512)
+	v := declare(s)
+	if err := s.Load(); err != ErrNoSettings {
+		t.Errorf("Load of a blank storage: %v", err)
+	}
+	if !*v.enabled || *v.volume != -5 || *v.id != 7 || *v.gain != 1.5 || *v.name != "sensor" {
+		t.Errorf("defaults %v %d %d %f %q", *v.enabled, *v.volume, *v.id, *v.gain, *v.name)
+	}
+
+	*v.enabled, *v.volume, *v.id, *v.gain, *v.name = false, 80, 1<<31, -0.25, "kitchen"
+	if err := s.Commit(); err != nil {
+		t.Fatal(err)
+	}
+	*v.name = "garage"
+	if err := s.Commit(); err != nil {
+		t.Fatal(err)
+	}
+	s = New(mem, 256, 512)
+	v = declare(s)
+	if err := s.Load(); err != nil {
+		t.Fatal(err)
+	}
+	if *v.enabled || *v.volume != 80 || *v.id != 1<<31 || *v.gain != -0.25 || *v.name != "garage" {
+		t.Errorf("loaded %v %d %d %f %q", *v.enabled, *v.volume, *v.id, *v.gain, *v.name)
+	}
+	for _, b := range mem.data[:256] {
+		if b != 0 {
+			t.Fatal("wrote before the offset")
+		}
+	}
+
+	// A commit interrupted by a power loss leaves the previous settings,
+	// whether it stops in the settings or in the header.
+	for _, n := range []int{10, 60} {
+		*v.name = "attic"
+		mem.failAfter = n
+		if err := s.Commit(); err != errPowerLoss {
+			t.Fatalf("power loss after %d bytes: %v", n, err)
+		}
+		mem.failAfter = 0
+		s = New(mem, 256, 512)
+		v = declare(s)
+		if err := s.Load(); err != nil || *v.name != "garage" {
+			t.Errorf("after power loss at %d: %q, %v", n, *v.name, err)
+		}
+	}
+
+	// A new firmware with other settings.
+	s = New(mem, 256, 512)
+	name := s.String("name", "")
+	volume := s.Float("volume", 3) // now a float
+	color := s.Int("color", 4)
+	if err := s.Load(); err != nil {
+		t.Fatal(err)
+	}
+	if *name != "garage" || *volume != 3 || *color != 4 {
+		t.Errorf("new firmware: %q %f %d", *name, *volume, *color)
+	}
+
+	*name = string(make([]byte, 300))
+	if err := s.Commit(); err != errValueSize {
+		t.Errorf("long value: %v", err)
+	}
+	*name = string(make([]byte, 180))
+	s.Int("more", 0)
+	if err := New(mem, 0, 256).Commit(); err != nil {
+		t.Errorf("no settings: %v", err)
+	}
+	if err := s.Commit(); err != nil {
+		t.Errorf("large settings: %v", err)
+	}
+	s.String("other", string(make([]byte, 200)))
+	if err := s.Commit(); err != errTooLarge {
+		t.Errorf("too large: %v", err)
+	}
+}
+
+func TestFlash(t *testing.T) {
+	f := &flash{eeprom: eeprom{data: make([]byte, 2048)}}
+	s := New(f, 1024, 1024)
+	v := declare(s)
+	for i := int32(0); i < 3; i++ {
+		*v.volume = i
+		if err := s.Commit(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if f.erases != 3 {
+		t.Errorf("%d erases", f.erases)
+	}
+	s = New(f, 1024, 1024)
+	v = declare(s)
+	if err := s.Load(); err != nil || *v.volume != 2 {
+		t.Errorf("loaded %d, %v", *v.volume, err)
+	}
+	if err := New(f, 100, 1024).Commit(); err != errEraseAlign {
+		t.Errorf("unaligned: %v", err)
+	}
+}
@@ -6,6 +6,8 @@ const (
 	SHTC3_ADDRESS        = 0x70
 	SHTC3_CMD_WAKEUP     = "\x35\x17" // Wake up
 	SHTC3_CMD_MEASURE_HP = "\x7C\xA2" // Read sensor in high power mode with clock stretching
+	SHTC3_CMD_MEASURE_LP = "\x64\x58" // Read sensor in low power mode with clock stretching
+	SHTC3_CMD_READ_ID    = "\xEF\xC8" // Read ID register
 	SHTC3_CMD_SLEEP      = "\xB0\x98" // Sleep
 	SHTC3_CMD_SOFT_RESET = "\x80\x5D" // Soft Reset
 )
@@ -6,14 +6,21 @@
 package shtc3 // import "tinygo.org/x/drivers/shtc3"
 
 import (
+	"errors"
 	"time"
 
 	"tinygo.org/x/drivers"
 )
 
+var errChecksum = errors.New("shtc3: checksum mismatch")
+
 // Device wraps an I2C connection to a SHT31 device.
 type Device struct {
 	bus drivers.I2C
+
+	// LowPower selects the low power measurement mode, which takes less
+	// than a millisecond instead of 12ms at the cost of more noise.
+	LowPower bool
 }
 
 // New creates a new SHTC3 connection. The I2C bus must already be
@@ -51,28 +58,94 @@ func (d *Device) ReadTemperatureHumidity() (tempMilliCelsius int32, relativeHumi
 	return tempMilliCelsius, relativeHumidity, err
 }
 
+// MeasureOnce wakes the device up, reads both the temperature and relative
+// humidity, and puts it back to sleep. Between measurements the device only
+// draws its sleep current, which makes this the preferred way to use it on
+// battery powered nodes.
+func (d *Device) MeasureOnce() (tempMilliCelsius int32, relativeHumidity int16, err error) {
+	if err = d.WakeUp(); err != nil {
+		return
+	}
+	tempMilliCelsius, relativeHumidity, err = d.ReadTemperatureHumidity()
+	if sleepErr := d.Sleep(); err == nil {
+		err = sleepErr
+	}
+	return
+}
+
 // rawReadings returns the sensor's raw values of the temperature and humidity
 func (d *Device) rawReadings() (uint16, uint16, error) {
 	var data [6]byte
-	d.bus.Tx(SHTC3_ADDRESS, []byte(SHTC3_CMD_MEASURE_HP), data[:])
-	// ignore crc for now
+	cmd := SHTC3_CMD_MEASURE_HP
+	if d.LowPower {
+		cmd = SHTC3_CMD_MEASURE_LP
+	}
+	err := d.bus.Tx(SHTC3_ADDRESS, []byte(cmd), data[:])
+	if err != nil {
+		return 0, 0, err
+	}
+	if crc8(data[0:2]) != data[2] || crc8(data[3:5]) != data[5] {
+		return 0, 0, errChecksum
+	}
 	return readUint(data[0], data[1]), readUint(data[3], data[4]), nil
 }
 
+// ReadID returns the ID register of the device. Bits 11 and 5:0 identify the
+// SHTC3.
+func (d *Device) ReadID() (uint16, error) {
+	var data [3]byte
+	err := d.bus.Tx(SHTC3_ADDRESS, []byte(SHTC3_CMD_READ_ID), data[:])
+	if err != nil {
+		return 0, err
+	}
+	if crc8(data[0:2]) != data[2] {
+		return 0, errChecksum
+	}
+	return readUint(data[0], data[1]), nil
+}
+
+// Connected returns whether a SHTC3 has been found.
+func (d *Device) Connected() bool {
+	id, err := d.ReadID()
+	return err == nil && id&0x083F == 0x0807
+}
+
+// Reset performs a soft reset of the device. The device must be awake.
+func (d *Device) Reset() error {
+	err := d.bus.Tx(SHTC3_ADDRESS, []byte(SHTC3_CMD_SOFT_RESET), nil)
+	time.Sleep(1 * time.Millisecond)
+	return err
+}
+
 // WakeUp makes device leave sleep mode
 func (d *Device) WakeUp() error {
-	d.bus.Tx(SHTC3_ADDRESS, []byte(SHTC3_CMD_WAKEUP), nil)
+	err := d.bus.Tx(SHTC3_ADDRESS, []byte(SHTC3_CMD_WAKEUP), nil)
 	time.Sleep(1 * time.Millisecond)
-	return nil
+	return err
 }
 
 // Sleep makes device go to sleep
 func (d *Device) Sleep() error {
-	d.bus.Tx(SHTC3_ADDRESS, []byte(SHTC3_CMD_SLEEP), nil)
-	return nil
+	return d.bus.Tx(SHTC3_ADDRESS, []byte(SHTC3_CMD_SLEEP), nil)
 }
 
 // readUint converts two bytes to uint16
 func readUint(msb byte, lsb byte) uint16 {
 	return (uint16(msb) << 8) | uint16(lsb)
 }
+
+// crc8 computes the Sensirion CRC-8 of buf.
+func crc8(buf []byte) uint8 {
+	var crc uint8 = 0xff
+	for _, b := range buf {
+		crc ^= b
+		for i := 0; i < 8; i++ {
+			if crc&0x80 != 0 {
+				crc = (crc << 1) ^ 0x31
+			} else {
+				crc <<= 1
+			}
+		}
+	}
+	return crc
+}
@@ -0,0 +1,21 @@
+//go:build tinygo
+
+package si4703
+
+import (
+	"machine"
+	"time"
+)
+
+// Reset resets the Si4703 into its 2-wire (I2C) mode, by holding the SDIO
+// line low while RST rises. sdio is the SDA pin of the I2C bus, which must
+// be configured afterwards.
+func Reset(rst, sdio machine.Pin) {
+	rst.Configure(machine.PinConfig{Mode: machine.PinOutput})
+	sdio.Configure(machine.PinConfig{Mode: machine.PinOutput})
+	sdio.Low()
+	rst.Low()
+	time.Sleep(time.Millisecond)
+	rst.High()
+	time.Sleep(time.Millisecond)
+}
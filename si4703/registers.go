@@ -0,0 +1,52 @@
+package si4703
+
+// The I2C address of the Si4703.
+const Address = 0x10
+
+// Registers
+const (
+	REG_DEVICEID   = 0x00
+	REG_CHIPID     = 0x01
+	REG_POWERCFG   = 0x02
+	REG_CHANNEL    = 0x03
+	REG_SYSCONFIG1 = 0x04
+	REG_SYSCONFIG2 = 0x05
+	REG_SYSCONFIG3 = 0x06
+	REG_TEST1      = 0x07
+	REG_TEST2      = 0x08
+	REG_BOOTCONFIG = 0x09
+	REG_STATUSRSSI = 0x0A
+	REG_READCHAN   = 0x0B
+	REG_RDSA       = 0x0C
+	REG_RDSB       = 0x0D
+	REG_RDSC       = 0x0E
+	REG_RDSD       = 0x0F
+)
+
+// Register bits
+const (
+	POWERCFG_DSMUTE = 0x8000
+	POWERCFG_DMUTE  = 0x4000
+	POWERCFG_MONO   = 0x2000
+	POWERCFG_RDSM   = 0x0800
+	POWERCFG_SKMODE = 0x0400
+	POWERCFG_SEEKUP = 0x0200
+	POWERCFG_SEEK   = 0x0100
+	POWERCFG_ENABLE = 0x0001
+
+	CHANNEL_TUNE = 0x8000
+
+	SYSCONFIG1_RDS = 0x1000
+	SYSCONFIG1_DE  = 0x0800
+
+	TEST1_XOSCEN = 0x8000
+
+	STATUSRSSI_RDSR  = 0x8000
+	STATUSRSSI_STC   = 0x4000
+	STATUSRSSI_SFBL  = 0x2000
+	STATUSRSSI_ST    = 0x0100
+	STATUSRSSI_BLERA = 0x0600
+
+	// MANUFACTURER_ID is the manufacturer of REG_DEVICEID.
+	MANUFACTURER_ID = 0x242
+)
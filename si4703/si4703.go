@@ -0,0 +1,288 @@
+// Package si4703 implements a driver for the Si4703 FM radio receiver, with
+// seek, tuning and RDS decoding.
+//
+// The Si4703 must be put in its 2-wire (I2C) mode by a reset with SDIO low,
+// see Reset, before the I2C bus is configured.
+//
+// Datasheet: https://www.skyworksinc.com/-/media/Skyworks/SL/documents/public/data-sheets/Si4702-03-C19.pdf
+// Programming guide: https://www.skyworksinc.com/-/media/Skyworks/SL/documents/public/application-notes/AN230.pdf
+package si4703 // import "tinygo.org/x/drivers/si4703"
+
+import (
+	"errors"
+	"time"
+
+	"tinygo.org/x/drivers"
+	"tinygo.org/x/drivers/tuner"
+)
+
+var (
+	errNotFound = errors.New("si4703: device not found")
+	errTimeout  = errors.New("si4703: tuning timeout")
+	errRange    = errors.New("si4703: frequency out of band")
+
+	// ErrSeekFailed is returned by Seek when no station was found before
+	// the end of the band.
+	ErrSeekFailed = tuner.ErrSeekFailed
+)
+
+// Band is the FM band of the region.
+type Band uint8
+
+const (
+	BandWorld     Band = iota // 87.5-108MHz, United States and Europe
+	BandJapanWide             // 76-108MHz
+	BandJapan                 // 76-90MHz
+)
+
+// Spacing is the channel spacing of the region.
+type Spacing uint8
+
+const (
+	Spacing200kHz Spacing = iota // United States, Australia
+	Spacing100kHz                // Europe, Japan
+	Spacing50kHz
+)
+
+// Config is the configuration of the receiver.
+type Config struct {
+	Band    Band
+	Spacing Spacing
+
+	// DeEmphasis50us selects the de-emphasis of Europe, Australia and
+	// Japan; it is 75µs otherwise, as in the United States.
+	DeEmphasis50us bool
+
+	// Volume is from 1 to 15, 15 when 0. See SetVolume.
+	Volume uint8
+}
+
+// Device wraps an I2C connection to an Si4703 device.
+type Device struct {
+	bus     drivers.I2C
+	Address uint16
+	regs    [16]uint16
+	bottom  uint32 // kHz
+	top     uint32 // kHz
+	spacing uint32 // kHz
+	buf     [32]byte
+	rds     tuner.RDS
+}
+
+// New creates a new Si4703 connection. The I2C bus must already be
+// configured, after Reset.
+//
+// This function only creates the Device object, it does not touch the device.
+func New(bus drivers.I2C) *Device {
+	return &Device{
+		bus:     bus,
+		Address: Address,
+	}
+}
+
+// Configure starts the oscillator, powers the receiver up, and enables RDS.
+// It takes about 600ms.
+func (d *Device) Configure(cfg Config) error {
+	if err := d.read(); err != nil {
+		return err
+	}
+	if d.regs[REG_DEVICEID]&0x0FFF != MANUFACTURER_ID {
+		return errNotFound
+	}
+	d.regs[REG_TEST1] = TEST1_XOSCEN | 0x0100
+	if err := d.write(); err != nil {
+		return err
+	}
+	time.Sleep(500 * time.Millisecond)
+
+	d.regs[REG_POWERCFG] = POWERCFG_DMUTE | POWERCFG_RDSM | POWERCFG_ENABLE
+	if err := d.write(); err != nil {
+		return err
+	}
+	time.Sleep(110 * time.Millisecond)
+
+	volume := cfg.Volume
+	if volume == 0 {
+		volume = 15
+	}
+	d.regs[REG_SYSCONFIG1] |= SYSCONFIG1_RDS
+	if cfg.DeEmphasis50us {
+		d.regs[REG_SYSCONFIG1] |= SYSCONFIG1_DE
+	}
+	d.regs[REG_SYSCONFIG2] = d.regs[REG_SYSCONFIG2]&0xFF00 |
+		uint16(cfg.Band&0x03)<<6 | uint16(cfg.Spacing&0x03)<<4 | uint16(volume&0x0F)
+
+	d.bottom, d.top = 87500, 108000
+	if cfg.Band != BandWorld {
+		d.bottom = 76000
+	}
+	if cfg.Band == BandJapan {
+		d.top = 90000
+	}
+	d.spacing = [4]uint32{200, 100, 50, 200}[cfg.Spacing&0x03]
+	return d.write()
+}
+
+// Tune tunes to a frequency in kHz, for instance 101100 for 101.1MHz.
+func (d *Device) Tune(khz uint32) error {
+	if khz < d.bottom || khz > d.top {
+		return errRange
+	}
+	channel := (khz - d.bottom) / d.spacing
+	d.regs[REG_CHANNEL] = d.regs[REG_CHANNEL]&0xFC00 | CHANNEL_TUNE | uint16(channel)
+	if err := d.write(); err != nil {
+		return err
+	}
+	_, err := d.complete(REG_CHANNEL, CHANNEL_TUNE, 200*time.Millisecond)
+	return err
+}
+
+// Seek tunes to the next station up or down the band, and returns its
+// frequency in kHz. It stops at the end of the band, with ErrSeekFailed.
+func (d *Device) Seek(up bool) (uint32, error) {
+	d.regs[REG_POWERCFG] |= POWERCFG_SEEK | POWERCFG_SKMODE
+	d.regs[REG_POWERCFG] &^= POWERCFG_SEEKUP
+	if up {
+		d.regs[REG_POWERCFG] |= POWERCFG_SEEKUP
+	}
+	if err := d.write(); err != nil {
+		return 0, err
+	}
+	status, err := d.complete(REG_POWERCFG, POWERCFG_SEEK, 10*time.Second)
+	if err != nil {
+		return 0, err
+	}
+	if status&STATUSRSSI_SFBL != 0 {
+		return d.Frequency(), ErrSeekFailed
+	}
+	return d.Frequency(), nil
+}
+
+// complete waits for the end of a tune or seek, clears the bit that
+// started it, and returns the status at the end.
+func (d *Device) complete(reg int, bit uint16, timeout time.Duration) (uint16, error) {
+	deadline := time.Now().Add(timeout)
+	for {
+		if err := d.read(); err != nil {
+			return 0, err
+		}
+		if d.regs[REG_STATUSRSSI]&STATUSRSSI_STC != 0 {
+			break
+		}
+		if time.Now().After(deadline) {
+			return 0, errTimeout
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	status := d.regs[REG_STATUSRSSI]
+	d.regs[reg] &^= bit
+	if err := d.write(); err != nil {
+		return 0, err
+	}
+	// STC is cleared once the bit is.
+	for d.regs[REG_STATUSRSSI]&STATUSRSSI_STC != 0 {
+		if time.Now().After(deadline) {
+			return 0, errTimeout
+		}
+		if err := d.read(); err != nil {
+			return 0, err
+		}
+	}
+	d.rds.Reset()
+	return status, nil
+}
+
+// Frequency returns the frequency tuned in kHz, as of the last tune or
+// seek.
+func (d *Device) Frequency() uint32 {
+	return d.bottom + uint32(d.regs[REG_READCHAN]&0x03FF)*d.spacing
+}
+
+// RSSI returns the received signal strength in dBµV, from 0 to 75.
+func (d *Device) RSSI() (uint8, error) {
+	err := d.read()
+	return uint8(d.regs[REG_STATUSRSSI]), err
+}
+
+// Stereo returns whether the station is received in stereo.
+func (d *Device) Stereo() (bool, error) {
+	err := d.read()
+	return d.regs[REG_STATUSRSSI]&STATUSRSSI_ST != 0, err
+}
+
+// SetVolume sets the volume from 0 (muted) to 15.
+func (d *Device) SetVolume(volume uint8) error {
+	d.regs[REG_SYSCONFIG2] = d.regs[REG_SYSCONFIG2]&^0x000F | uint16(volume&0x0F)
+	return d.write()
+}
+
+// Mute mutes or unmutes the audio outputs.
+func (d *Device) Mute(mute bool) error {
+	// DMUTE disables the mute.
+	d.regs[REG_POWERCFG] |= POWERCFG_DMUTE
+	if mute {
+		d.regs[REG_POWERCFG] &^= POWERCFG_DMUTE
+	}
+	return d.write()
+}
+
+// SetMono forces mono audio, which is less noisy for weak stations.
+func (d *Device) SetMono(mono bool) error {
+	d.regs[REG_POWERCFG] &^= POWERCFG_MONO
+	if mono {
+		d.regs[REG_POWERCFG] |= POWERCFG_MONO
+	}
+	return d.write()
+}
+
+// ReadRDS checks for a new RDS group, and decodes it, see RDS. It returns
+// whether a group was received. Groups arrive about 11 times per second, so
+// call it at least every 80ms to get them all.
+func (d *Device) ReadRDS() (bool, error) {
+	if err := d.read(); err != nil {
+		return false, err
+	}
+	status := d.regs[REG_STATUSRSSI]
+	if status&STATUSRSSI_RDSR == 0 {
+		return false, nil
+	}
+	// Block error rates: 3 means uncorrectable.
+	blerA := status >> 9 & 0x03
+	readchan := d.regs[REG_READCHAN]
+	blerB, blerC, blerD := readchan>>14, readchan>>12&0x03, readchan>>10&0x03
+	if blerB == 3 {
+		// The group type is unknown.
+		return false, nil
+	}
+	d.rds.Decode(d.regs[REG_RDSA], d.regs[REG_RDSB], d.regs[REG_RDSC], d.regs[REG_RDSD],
+		blerA < 3, blerC < 3, blerD < 3)
+	return true, nil
+}
+
+// RDS returns the data decoded by ReadRDS since the last tune or seek.
+func (d *Device) RDS() *tuner.RDS {
+	return &d.rds
+}
+
+// read reads all the registers. The Si4703 sends them from 0x0A, wrapping
+// around after 0x0F.
+func (d *Device) read() error {
+	if err := d.bus.Tx(d.Address, nil, d.buf[:32]); err != nil {
+		return err
+	}
+	for i := 0; i < 16; i++ {
+		reg := (REG_STATUSRSSI + i) & 0x0F
+		d.regs[reg] = uint16(d.buf[2*i])<<8 | uint16(d.buf[2*i+1])
+	}
+	return nil
+}
+
+// write writes the registers 0x02 to 0x07. The Si4703 receives them from
+// 0x02.
+func (d *Device) write() error {
+	for i := 0; i < 6; i++ {
+		d.buf[2*i] = byte(d.regs[REG_POWERCFG+i] >> 8)
+		d.buf[2*i+1] = byte(d.regs[REG_POWERCFG+i])
+	}
+	return d.bus.Tx(d.Address, d.buf[:12], nil)
+}
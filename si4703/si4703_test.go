@@ -0,0 +1,94 @@
+package si4703
+
+import (
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+	"tinygo.org/x/drivers/tuner"
+)
+
+var _ tuner.Tuner = (*Device)(nil)
+
+// fakeRadio is an Si4703 on an I2C bus, with a station every 1MHz from
+// 88.1MHz.
+type fakeRadio struct {
+	regs [16]uint16
+}
+
+func (r *fakeRadio) ReadRegister(addr uint8, reg uint8, buf []byte) error  { panic("no registers") }
+func (r *fakeRadio) WriteRegister(addr uint8, reg uint8, buf []byte) error { panic("no registers") }
+
+func (r *fakeRadio) Tx(addr uint16, w, rd []byte) error {
+	for i := 0; i+1 < len(w); i += 2 {
+		r.regs[REG_POWERCFG+i/2] = uint16(w[i])<<8 | uint16(w[i+1])
+	}
+	if len(w) > 0 {
+		r.update()
+	}
+	for i := 0; i+1 < len(rd); i += 2 {
+		reg := (REG_STATUSRSSI + i/2) & 0x0F
+		rd[i], rd[i+1] = byte(r.regs[reg]>>8), byte(r.regs[reg])
+	}
+	return nil
+}
+
+// update runs the tune and seek commands, at 200kHz spacing.
+func (r *fakeRadio) update() {
+	status := &r.regs[REG_STATUSRSSI]
+	switch {
+	case r.regs[REG_CHANNEL]&CHANNEL_TUNE != 0:
+		r.regs[REG_READCHAN] = r.regs[REG_CHANNEL] & 0x03FF
+		*status |= STATUSRSSI_STC
+	case r.regs[REG_POWERCFG]&POWERCFG_SEEK != 0:
+		ch := r.regs[REG_READCHAN]
+		for {
+			if r.regs[REG_POWERCFG]&POWERCFG_SEEKUP != 0 {
+				ch++
+			} else {
+				ch--
+			}
+			if ch > 102 {
+				*status |= STATUSRSSI_SFBL
+				break
+			}
+			if (ch-3)%5 == 0 {
+				break
+			}
+		}
+		if ch <= 102 {
+			r.regs[REG_READCHAN] = ch
+		}
+		*status |= STATUSRSSI_STC
+	default:
+		*status &^= STATUSRSSI_STC | STATUSRSSI_SFBL
+	}
+}
+
+func TestTune(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Configure waits 600ms")
+	}
+	c := qt.New(t)
+	radio := &fakeRadio{}
+	radio.regs[REG_DEVICEID] = 0x1242
+	d := New(radio)
+	c.Assert(d.Configure(Config{Volume: 10}), qt.IsNil)
+	c.Assert(radio.regs[REG_POWERCFG]&POWERCFG_ENABLE, qt.Equals, uint16(POWERCFG_ENABLE))
+	c.Assert(radio.regs[REG_SYSCONFIG2], qt.Equals, uint16(0x000A))
+
+	c.Assert(d.Tune(101100), qt.IsNil)
+	c.Assert(radio.regs[REG_CHANNEL], qt.Equals, uint16(68))
+	c.Assert(d.Frequency(), qt.Equals, uint32(101100))
+	c.Assert(d.Tune(120000), qt.Equals, errRange)
+
+	freq, err := d.Seek(true)
+	c.Assert(err, qt.IsNil)
+	c.Assert(freq, qt.Equals, uint32(102100))
+	freq, err = d.Seek(false)
+	c.Assert(err, qt.IsNil)
+	c.Assert(freq, qt.Equals, uint32(101100))
+	d.Tune(107900)
+	_, err = d.Seek(true)
+	c.Assert(err, qt.Equals, ErrSeekFailed)
+	c.Assert(radio.regs[REG_POWERCFG]&POWERCFG_SEEK, qt.Equals, uint16(0))
+}
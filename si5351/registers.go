@@ -0,0 +1,41 @@
+package si5351
+
+// The I2C address of the Si5351A and Si5351C.
+const Address = 0x60
+
+// Registers
+const (
+	REG_STATUS        = 0
+	REG_OUTPUT_ENABLE = 3
+	REG_PLL_SOURCE    = 15
+	REG_CLK0_CONTROL  = 16 // one per output
+	REG_PLLA_PARAMS   = 26
+	REG_PLLB_PARAMS   = 34
+	REG_MS0_PARAMS    = 42 // 8 per multisynth
+	REG_SSC_PARAMS    = 149
+	REG_CLK0_PHASE    = 165 // one per output
+	REG_PLL_RESET     = 177
+	REG_CRYSTAL_LOAD  = 183
+)
+
+// Register bits
+const (
+	STATUS_SYS_INIT = 0x80
+
+	CLK_POWER_DOWN   = 0x80
+	CLK_INTEGER_MODE = 0x40
+	CLK_SOURCE_PLLB  = 0x20
+	CLK_INVERT       = 0x10
+	CLK_INPUT_MS     = 0x0C
+
+	MS_DIVBY4 = 0x0C
+
+	SSC_ENABLE = 0x80
+
+	PLL_RESET_A = 0x20
+	PLL_RESET_B = 0x80
+
+	// CRYSTAL_LOAD_RESERVED are the bits of REG_CRYSTAL_LOAD that must be
+	// written as 010010b.
+	CRYSTAL_LOAD_RESERVED = 0x12
+)
@@ -0,0 +1,415 @@
+// Package si5351 implements a driver for the Si5351 clock generator, which
+// makes up to 8 clocks from 2.3kHz to 200MHz out of a 25MHz or 27MHz
+// crystal.
+//
+// Each output is divided by a multisynth from one of two PLLs, which
+// multiply the crystal frequency to 600-900MHz. The driver computes the
+// fractional PLL and multisynth ratios for a target frequency.
+//
+// Datasheet: https://www.skyworksinc.com/-/media/Skyworks/SL/documents/public/data-sheets/Si5351-B.pdf
+// Register map: https://www.skyworksinc.com/-/media/Skyworks/SL/documents/public/application-notes/AN619.pdf
+package si5351 // import "tinygo.org/x/drivers/si5351"
+
+import (
+	"errors"
+	"time"
+
+	"tinygo.org/x/drivers"
+	"tinygo.org/x/drivers/internal/legacy"
+)
+
+var (
+	errOutput = errors.New("si5351: output out of range")
+	errRange  = errors.New("si5351: frequency out of range")
+	errPLL    = errors.New("si5351: PLL not configured")
+	errPhase  = errors.New("si5351: phase out of range for this frequency")
+	errInit   = errors.New("si5351: device not ready")
+)
+
+// PLL is one of the two PLLs.
+type PLL uint8
+
+const (
+	PLLA PLL = iota
+	PLLB
+)
+
+// Drive is the output current of an output.
+type Drive uint8
+
+const (
+	Drive2mA Drive = iota
+	Drive4mA
+	Drive6mA
+	Drive8mA
+)
+
+// CrystalLoad is the internal load capacitance of the crystal.
+type CrystalLoad uint8
+
+const (
+	CrystalLoad10pF CrystalLoad = iota // default
+	CrystalLoad8pF
+	CrystalLoad6pF
+)
+
+// Outputs is the number of outputs with a fractional multisynth. The
+// outputs 6 and 7 of the 8-output versions are not supported.
+const Outputs = 6
+
+const (
+	vcoMin = 600000000
+	vcoMax = 900000000
+
+	// denominator of the fractional ratios
+	denominator = 1048575
+)
+
+// Config is the configuration of the device.
+type Config struct {
+	// Crystal is the crystal frequency in Hz, 25MHz when 0.
+	Crystal uint32
+
+	// CrystalLoad is the load capacitance required by the crystal.
+	CrystalLoad CrystalLoad
+
+	// Correction is the error of the crystal in parts per billion, positive
+	// when it runs fast.
+	Correction int32
+}
+
+// Device wraps an I2C connection to an Si5351 device.
+type Device struct {
+	bus     drivers.I2C
+	Address uint16
+	crystal uint32
+	vco     [2]uint32    // PLL frequencies, 0 when not configured
+	ratio   [2][3]uint32 // PLL ratios: a, b and c of a + b/c
+	control [Outputs]uint8
+	div     [Outputs]uint32 // integer multisynth divider, 0 when fractional
+	buf     [14]byte
+}
+
+// New creates a new Si5351 connection. The I2C bus must already be
+// configured.
+//
+// This function only creates the Device object, it does not touch the device.
+func New(bus drivers.I2C) *Device {
+	return &Device{
+		bus:     bus,
+		Address: Address,
+	}
+}
+
+// Configure waits for the device to be ready, then disables and powers
+// down all outputs.
+func (d *Device) Configure(cfg Config) error {
+	d.crystal = cfg.Crystal
+	if d.crystal == 0 {
+		d.crystal = 25000000
+	}
+	d.crystal = uint32(int64(d.crystal) + int64(d.crystal)*int64(cfg.Correction)/1e9)
+	d.vco = [2]uint32{}
+
+	ready := false
+	for i := 0; i < 10; i++ {
+		status, err := d.readRegister(REG_STATUS)
+		if err != nil {
+			return err
+		}
+		if status&STATUS_SYS_INIT == 0 {
+			ready = true
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if !ready {
+		return errInit
+	}
+
+	if err := d.writeRegister(REG_OUTPUT_ENABLE, 0xFF); err != nil {
+		return err
+	}
+	for i := range d.control {
+		d.control[i] = CLK_POWER_DOWN | CLK_INPUT_MS | uint8(Drive8mA)
+		if err := d.writeRegister(REG_CLK0_CONTROL+uint8(i), d.control[i]); err != nil {
+			return err
+		}
+	}
+	// Both PLLs from the crystal.
+	if err := d.writeRegister(REG_PLL_SOURCE, 0); err != nil {
+		return err
+	}
+	load := (uint8(cfg.CrystalLoad)^0x03)<<6 | CRYSTAL_LOAD_RESERVED
+	return d.writeRegister(REG_CRYSTAL_LOAD, load)
+}
+
+// SetFrequency sets an output to a frequency in Hz, from 2.3kHz to 200MHz,
+// and powers it up. The PLL is tuned to an even multiple of the frequency,
+// which gives the lowest jitter and allows SetPhase, but changes the other
+// outputs on the same PLL: give each output its own PLL, or use
+// SetFrequencyFractional for the other outputs of a PLL.
+func (d *Device) SetFrequency(output uint8, pll PLL, hz uint32) error {
+	if output >= Outputs {
+		return errOutput
+	}
+	r, f := rDivider(hz)
+	var div, vco uint32
+	switch {
+	case f == 0 || f > 200000000:
+		return errRange
+	case f > 150000000:
+		div = 4
+	default:
+		div = vcoMax / f &^ 1
+		if div > 2048 {
+			div = 2048
+		}
+	}
+	vco = div * f
+	if vco < vcoMin {
+		return errRange
+	}
+	if err := d.SetPLL(pll, vco); err != nil {
+		return err
+	}
+	if err := d.setMultisynth(output, pll, r, div, 0, 1); err != nil {
+		return err
+	}
+	return d.resetPLL(pll)
+}
+
+// SetFrequencyFractional sets an output to a frequency in Hz with a
+// fractional multisynth, and powers it up. The PLL keeps its frequency, set
+// by SetPLL or SetFrequency, so any number of outputs can share it.
+func (d *Device) SetFrequencyFractional(output uint8, pll PLL, hz uint32) error {
+	if output >= Outputs {
+		return errOutput
+	}
+	vco := d.vco[pll&1]
+	if vco == 0 {
+		return errPLL
+	}
+	r, f := rDivider(hz)
+	if f == 0 {
+		return errRange
+	}
+	a, rem := vco/f, vco%f
+	b := uint32(uint64(rem) * denominator / uint64(f))
+	switch {
+	case a > 2048 || a == 2048 && b > 0:
+		return errRange
+	case a < 8 && !(b == 0 && (a == 4 || a == 6)):
+		return errRange
+	}
+	return d.setMultisynth(output, pll, r, a, b, denominator)
+}
+
+// SetPLL sets the frequency of a PLL in Hz, from 600MHz to 900MHz.
+func (d *Device) SetPLL(pll PLL, vco uint32) error {
+	if vco < vcoMin || vco > vcoMax {
+		return errRange
+	}
+	pll &= 1
+	a, rem := vco/d.crystal, vco%d.crystal
+	b, c := uint32(uint64(rem)*denominator/uint64(d.crystal)), uint32(denominator)
+	if b == 0 {
+		c = 1
+	}
+	p := encode(a, b, c)
+	reg := uint8(REG_PLLA_PARAMS)
+	if pll == PLLB {
+		reg = REG_PLLB_PARAMS
+	}
+	if err := d.writeParams(reg, p, 0); err != nil {
+		return err
+	}
+	d.vco[pll] = vco
+	d.ratio[pll] = [3]uint32{a, b, c}
+	return nil
+}
+
+// Enable enables or disables an output. A disabled output is driven low.
+func (d *Device) Enable(output uint8, enable bool) error {
+	if output >= Outputs {
+		return errOutput
+	}
+	oe, err := d.readRegister(REG_OUTPUT_ENABLE)
+	if err != nil {
+		return err
+	}
+	// The bits disable the outputs.
+	if enable {
+		oe &^= 1 << output
+	} else {
+		oe |= 1 << output
+	}
+	return d.writeRegister(REG_OUTPUT_ENABLE, oe)
+}
+
+// SetDrive sets the output current of an output.
+func (d *Device) SetDrive(output uint8, drive Drive) error {
+	if output >= Outputs {
+		return errOutput
+	}
+	return d.setControl(output, d.control[output]&^0x03|uint8(drive&0x03))
+}
+
+// SetInvert inverts an output, for instance to drive a balanced mixer with
+// two outputs of the same frequency.
+func (d *Device) SetInvert(output uint8, invert bool) error {
+	if output >= Outputs {
+		return errOutput
+	}
+	c := d.control[output] &^ CLK_INVERT
+	if invert {
+		c |= CLK_INVERT
+	}
+	return d.setControl(output, c)
+}
+
+// SetPhase delays an output by a phase in degrees, relative to the other
+// outputs of the same PLL and frequency, for instance 90 for quadrature
+// mixers. The output must have been set with SetFrequency, and the delay
+// is limited to 127 quarter periods of the PLL: 90 degrees needs a divider
+// of at most 127, so frequencies above 4.7MHz. The PLL is reset to align
+// the outputs.
+func (d *Device) SetPhase(output uint8, degrees uint16) error {
+	if output >= Outputs {
+		return errOutput
+	}
+	div := d.div[output]
+	if div == 0 {
+		return errPhase
+	}
+	// One output period is 4*div quarter periods of the PLL.
+	offset := (uint32(degrees)*4*div + 180) / 360
+	if offset > 127 {
+		return errPhase
+	}
+	if err := d.writeRegister(REG_CLK0_PHASE+output, uint8(offset)); err != nil {
+		return err
+	}
+	pll := PLLA
+	if d.control[output]&CLK_SOURCE_PLLB != 0 {
+		pll = PLLB
+	}
+	return d.resetPLL(pll)
+}
+
+// SetSpreadSpectrum enables or disables a down spread of 1.5% at 31.5kHz,
+// which lowers the peak emissions of clocks for digital circuits. Only the
+// outputs of PLLA are spread, whose frequency must be set first.
+func (d *Device) SetSpreadSpectrum(enable bool) error {
+	if !enable {
+		ssc, err := d.readRegister(REG_SSC_PARAMS)
+		if err != nil {
+			return err
+		}
+		return d.writeRegister(REG_SSC_PARAMS, ssc&^SSC_ENABLE)
+	}
+	if d.vco[PLLA] == 0 {
+		return errPLL
+	}
+	// AN619, down spread: SSUDP = fPFD / (4 * 31.5kHz), and
+	// SSDN = 64 * ratio * 0.015 / (1.015 * SSUDP) in 12.15 fixed point.
+	udp := d.crystal / (4 * 31500)
+	a, b, c := uint64(d.ratio[PLLA][0]), uint64(d.ratio[PLLA][1]), uint64(d.ratio[PLLA][2])
+	dn := 64 * 15 * (a*c + b) * 32767 / (1015 * uint64(udp) * c)
+	p1, p2, p3 := uint32(dn/32767), uint32(dn%32767), uint32(32767)
+	buf := d.buf[:1+13]
+	buf[0] = REG_SSC_PARAMS
+	buf[1] = SSC_ENABLE | uint8(p2>>8&0x7F)
+	buf[2] = uint8(p2)
+	buf[3] = uint8(p3 >> 8 & 0x7F) // down spread mode
+	buf[4] = uint8(p3)
+	buf[5] = uint8(p1)
+	buf[6] = uint8(udp>>8&0x0F)<<4 | uint8(p1>>8&0x0F)
+	buf[7] = uint8(udp)
+	// No up spread: SSUP_P1 and SSUP_P2 are 0, SSUP_P3 is 1.
+	buf[8], buf[9], buf[10], buf[11], buf[12], buf[13] = 0, 0, 0, 1, 0, 0
+	return d.bus.Tx(d.Address, buf, nil)
+}
+
+// rDivider returns the R divider, as a power of 2, that brings a frequency
+// above 500kHz, where the multisynth dividers can make it, and the
+// frequency before the R divider.
+func rDivider(hz uint32) (uint8, uint32) {
+	r := uint8(0)
+	for hz < 500000 && r < 7 {
+		hz <<= 1
+		r++
+	}
+	return r, hz
+}
+
+// encode returns the P1, P2 and P3 parameters of a ratio a + b/c.
+func encode(a, b, c uint32) [3]uint32 {
+	f := uint32(uint64(128) * uint64(b) / uint64(c))
+	return [3]uint32{
+		128*a + f - 512,
+		128*b - c*f,
+		c,
+	}
+}
+
+// writeParams writes the 8 parameter registers of a PLL or multisynth,
+// with the R divider and divide-by-4 bits in extra.
+func (d *Device) writeParams(reg uint8, p [3]uint32, extra uint8) error {
+	buf := d.buf[:9]
+	buf[0] = reg
+	buf[1] = uint8(p[2] >> 8)
+	buf[2] = uint8(p[2])
+	buf[3] = extra | uint8(p[0]>>16&0x03)
+	buf[4] = uint8(p[0] >> 8)
+	buf[5] = uint8(p[0])
+	buf[6] = uint8(p[2]>>16&0x0F)<<4 | uint8(p[1]>>16&0x0F)
+	buf[7] = uint8(p[1] >> 8)
+	buf[8] = uint8(p[1])
+	return d.bus.Tx(d.Address, buf, nil)
+}
+
+// setMultisynth sets the divider of an output to a + b/c from a PLL, and
+// the R divider to 2^r, and powers the output up.
+func (d *Device) setMultisynth(output uint8, pll PLL, r uint8, a, b, c uint32) error {
+	p, extra := encode(a, b, c), r<<4
+	if a == 4 && b == 0 {
+		p, extra = [3]uint32{0, 0, 1}, extra|MS_DIVBY4
+	}
+	if err := d.writeParams(REG_MS0_PARAMS+8*output, p, extra); err != nil {
+		return err
+	}
+	control := d.control[output] &^ (CLK_POWER_DOWN | CLK_INTEGER_MODE | CLK_SOURCE_PLLB)
+	d.div[output] = 0
+	if b == 0 && a%2 == 0 {
+		control |= CLK_INTEGER_MODE
+		d.div[output] = a
+	}
+	if pll&1 == PLLB {
+		control |= CLK_SOURCE_PLLB
+	}
+	return d.setControl(output, control)
+}
+
+func (d *Device) setControl(output, control uint8) error {
+	d.control[output] = control
+	return d.writeRegister(REG_CLK0_CONTROL+output, control)
+}
+
+// resetPLL resets a PLL, which aligns the phases of its outputs.
+func (d *Device) resetPLL(pll PLL) error {
+	if pll&1 == PLLB {
+		return d.writeRegister(REG_PLL_RESET, PLL_RESET_B)
+	}
+	return d.writeRegister(REG_PLL_RESET, PLL_RESET_A)
+}
+
+func (d *Device) readRegister(reg uint8) (uint8, error) {
+	err := legacy.ReadRegister(d.bus, uint8(d.Address), reg, d.buf[:1])
+	return d.buf[0], err
+}
+
+func (d *Device) writeRegister(reg, value uint8) error {
+	d.buf[0] = value
+	return legacy.WriteRegister(d.bus, uint8(d.Address), reg, d.buf[:1])
+}
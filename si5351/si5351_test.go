@@ -0,0 +1,88 @@
+package si5351
+
+import (
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+	"tinygo.org/x/drivers/tester"
+)
+
+func newDevice(c *qt.C) (*Device, *tester.I2CDevice8) {
+	bus := tester.NewI2CBus(c)
+	fake := bus.NewDevice(Address)
+	d := New(bus)
+	c.Assert(d.Configure(Config{}), qt.IsNil)
+	return d, fake
+}
+
+func TestConfigure(t *testing.T) {
+	c := qt.New(t)
+	_, fake := newDevice(c)
+	c.Assert(fake.Registers[REG_OUTPUT_ENABLE], qt.Equals, uint8(0xFF))
+	c.Assert(fake.Registers[REG_CLK0_CONTROL], qt.Equals, uint8(0x8F))
+	c.Assert(fake.Registers[REG_CRYSTAL_LOAD], qt.Equals, uint8(0xD2))
+}
+
+func TestSetFrequency(t *testing.T) {
+	c := qt.New(t)
+	d, fake := newDevice(c)
+
+	// 10MHz: PLLA at 900MHz, 36 times 25MHz, divided by 90.
+	c.Assert(d.SetFrequency(0, PLLA, 10000000), qt.IsNil)
+	c.Assert(fake.Registers[REG_PLLA_PARAMS:REG_PLLA_PARAMS+8], qt.DeepEquals,
+		[]uint8{0x00, 0x01, 0x00, 0x10, 0x00, 0x00, 0x00, 0x00})
+	c.Assert(fake.Registers[REG_MS0_PARAMS:REG_MS0_PARAMS+8], qt.DeepEquals,
+		[]uint8{0x00, 0x01, 0x00, 0x2B, 0x00, 0x00, 0x00, 0x00})
+	c.Assert(fake.Registers[REG_CLK0_CONTROL], qt.Equals, uint8(0x4F))
+	c.Assert(fake.Registers[REG_PLL_RESET], qt.Equals, uint8(PLL_RESET_A))
+
+	// 7MHz from the same PLL: 128 + 599185/1048575.
+	c.Assert(d.SetFrequencyFractional(1, PLLA, 7000000), qt.IsNil)
+	c.Assert(fake.Registers[REG_MS0_PARAMS+8:REG_MS0_PARAMS+16], qt.DeepEquals,
+		[]uint8{0xFF, 0xFF, 0x00, 0x3E, 0x49, 0xF2, 0x48, 0xC9})
+	c.Assert(fake.Registers[REG_CLK0_CONTROL+1], qt.Equals, uint8(0x0F))
+
+	// 10kHz through the R divider, and 200MHz divided by 4, on PLLB.
+	c.Assert(d.SetFrequency(2, PLLB, 10000), qt.IsNil)
+	c.Assert(fake.Registers[REG_MS0_PARAMS+16+2], qt.Equals, uint8(0x62))
+	c.Assert(fake.Registers[REG_CLK0_CONTROL+2], qt.Equals, uint8(0x6F))
+	c.Assert(d.SetFrequency(2, PLLB, 200000000), qt.IsNil)
+	c.Assert(fake.Registers[REG_MS0_PARAMS+16+2], qt.Equals, uint8(MS_DIVBY4))
+	c.Assert(d.vco[PLLB], qt.Equals, uint32(800000000))
+
+	c.Assert(d.SetFrequency(0, PLLA, 2000), qt.Equals, errRange)
+	c.Assert(d.SetFrequency(0, PLLA, 250000000), qt.Equals, errRange)
+	c.Assert(d.SetFrequencyFractional(1, PLLA, 140000000), qt.Equals, errRange)
+	c.Assert(d.SetFrequency(Outputs, PLLA, 10000000), qt.Equals, errOutput)
+}
+
+func TestOutputs(t *testing.T) {
+	c := qt.New(t)
+	d, fake := newDevice(c)
+	c.Assert(d.SetFrequencyFractional(0, PLLB, 10000000), qt.Equals, errPLL)
+
+	c.Assert(d.SetFrequency(0, PLLA, 10000000), qt.IsNil)
+	c.Assert(d.SetFrequency(1, PLLA, 10000000), qt.IsNil)
+	c.Assert(d.Enable(0, true), qt.IsNil)
+	c.Assert(d.Enable(1, true), qt.IsNil)
+	c.Assert(fake.Registers[REG_OUTPUT_ENABLE], qt.Equals, uint8(0xFC))
+	c.Assert(d.Enable(0, false), qt.IsNil)
+	c.Assert(fake.Registers[REG_OUTPUT_ENABLE], qt.Equals, uint8(0xFD))
+
+	// Quadrature: a quarter of 90 divider periods, in quarter PLL periods.
+	c.Assert(d.SetPhase(1, 90), qt.IsNil)
+	c.Assert(fake.Registers[REG_CLK0_PHASE+1], qt.Equals, uint8(90))
+	c.Assert(d.SetPhase(1, 180), qt.Equals, errPhase)
+
+	c.Assert(d.SetDrive(1, Drive2mA), qt.IsNil)
+	c.Assert(d.SetInvert(1, true), qt.IsNil)
+	c.Assert(fake.Registers[REG_CLK0_CONTROL+1], qt.Equals, uint8(0x5C))
+
+	c.Assert(d.SetSpreadSpectrum(true), qt.IsNil)
+	// SSUDP 198, SSDN 36 * 64 * 0.015 / (1.015 * 198) = 0.17196.
+	c.Assert(fake.Registers[REG_SSC_PARAMS]&SSC_ENABLE, qt.Not(qt.Equals), uint8(0))
+	c.Assert(fake.Registers[REG_SSC_PARAMS+6], qt.Equals, uint8(198))
+	c.Assert(uint16(fake.Registers[REG_SSC_PARAMS]&0x7F)<<8|uint16(fake.Registers[REG_SSC_PARAMS+1]), qt.Equals, uint16(5634))
+	c.Assert(d.SetSpreadSpectrum(false), qt.IsNil)
+	c.Assert(fake.Registers[REG_SSC_PARAMS]&SSC_ENABLE, qt.Equals, uint8(0))
+}
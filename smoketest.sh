@@ -11,11 +11,13 @@ tinygo build -size short -o ./build/test.hex -target=pybadge ./examples/amg88xx
 tinygo build -size short -o ./build/test.hex -target=itsybitsy-m0 ./examples/apa102/main.go
 tinygo build -size short -o ./build/test.hex -target=nano-33-ble ./examples/apds9960/proximity/main.go
 tinygo build -size short -o ./build/test.hex -target=itsybitsy-m0 ./examples/apa102/itsybitsy-m0/main.go
+tinygo build -size short -o ./build/test.hex -target=itsybitsy-m0 ./examples/apa102/pov/main.go
 tinygo build -size short -o ./build/test.hex -target=microbit ./examples/at24cx/main.go
 tinygo build -size short -o ./build/test.hex -target=itsybitsy-m0 ./examples/bh1750/main.go
 tinygo build -size short -o ./build/test.hex -target=itsybitsy-m0 ./examples/blinkm/main.go
 tinygo build -size short -o ./build/test.hex -target=pinetime     ./examples/bma42x/main.go
 tinygo build -size short -o ./build/test.hex -target=itsybitsy-m0 ./examples/bmi160/main.go
+tinygo build -size short -o ./build/test.hex -target=itsybitsy-m0 ./examples/bmi270/main.go
 tinygo build -size short -o ./build/test.hex -target=itsybitsy-m0 ./examples/bmp180/main.go
 tinygo build -size short -o ./build/test.hex -target=itsybitsy-m0 ./examples/bmp280/main.go
 tinygo build -size short -o ./build/test.hex -target=trinket-m0 ./examples/bmp388/main.go
@@ -135,3 +137,66 @@ tinygo build -size short -o ./build/test.hex -target=pico ./examples/ndir/main_n
 tinygo build -size short -o ./build/test.hex -target=microbit ./examples/ndir/main_ndir.go
 tinygo build -size short -o ./build/test.hex -target=arduino-nano33 ./examples/ndir/main_ndir.go
 tinygo build -size short -o ./build/test.uf2 -target=pico ./examples/mpu9150/main.go
+tinygo build -size short -o ./build/test.hex -target=itsybitsy-m0 ./examples/qmc5883/main.go
+tinygo build -size short -o ./build/test.hex -target=trinket-m0 ./examples/bmp388/altitude/
+tinygo build -size short -o ./build/test.hex -target=microbit ./examples/hdc302x/main.go
+tinygo build -size short -o ./build/test.hex -target=itsybitsy-m0 ./examples/tcs34725/main.go
+tinygo build -size short -o ./build/test.hex -target=itsybitsy-m0 ./examples/as7341/main.go
+tinygo build -size short -o ./build/test.hex -target=itsybitsy-m0 ./examples/max30102/main.go
+tinygo build -size short -o ./build/test.hex -target=itsybitsy-m0 ./examples/ad8232/main.go
+tinygo build -size short -o ./build/test.hex -target=itsybitsy-m0 ./examples/mpr121/main.go
+tinygo build -size short -o ./build/test.hex -target=itsybitsy-m0 ./examples/cap1188/main.go
+tinygo build -size short -o ./build/test.hex -target=itsybitsy-m0 ./examples/touch/slider/main.go
+tinygo build -size short -o ./build/test.hex -target=itsybitsy-m0 ./examples/hid/main.go
+tinygo build -size short -o ./build/test.hex -target=itsybitsy-m0 ./examples/ps2/main.go
+tinygo build -size short -o ./build/test.hex -target=itsybitsy-m0 ./examples/wii/main.go
+tinygo build -size short -o ./build/test.hex -target=itsybitsy-m0 ./examples/minigamepad/main.go
+tinygo build -size short -o ./build/test.hex -target=pico ./examples/sbus/main.go
+tinygo build -size short -o ./build/test.hex -target=pico ./examples/ibus/main.go
+tinygo build -size short -o ./build/test.hex -target=pico ./examples/dshot/main.go
+tinygo build -size short -o ./build/test.hex -target=pico ./examples/crsf/main.go
+tinygo build -size short -o ./build/test.hex -target=itsybitsy-m0 ./examples/pulse/main.go
+tinygo build -size short -o ./build/test.hex -target=itsybitsy-m0 ./examples/weathermeter/main.go
+tinygo build -size short -o ./build/test.hex -target=pico ./examples/ina260/profiler/main.go
+tinygo build -size short -o ./build/test.hex -target=itsybitsy-m0 ./examples/itg3200/main.go
+tinygo build -size short -o ./build/test.hex -target=itsybitsy-m0 ./examples/multigas/main.go
+tinygo build -size short -o ./build/test.hex -target=itsybitsy-m0 ./examples/mics5524/main.go
+tinygo build -size short -o ./build/test.hex -target=itsybitsy-m0 ./examples/scd30/main.go
+tinygo build -size short -o ./build/test.hex -target=itsybitsy-m0 ./examples/ltr390/main.go
+tinygo build -size short -o ./build/test.hex -target=itsybitsy-m0 ./examples/veml6075/main.go
+tinygo build -size short -o ./build/test.hex -target=pico ./examples/us100/main.go
+tinygo build -size short -o ./build/test.hex -target=pico ./examples/tof10120/main.go
+tinygo build -size short -o ./build/test.hex -target=itsybitsy-m0 ./examples/tca9548a/main.go
+tinygo build -size short -o ./build/test.hex -target=itsybitsy-m0 ./examples/spisoft/main.go
+tinygo build -size short -o ./build/test.hex -target=pico ./examples/ibutton/main.go
+tinygo build -size short -o ./build/test.hex -target=itsybitsy-m0 ./examples/ds248x/main.go
+tinygo build -size short -o ./build/test.hex -target=itsybitsy-m0 ./examples/max31856/main.go
+tinygo build -size short -o ./build/test.hex -target=itsybitsy-m0 ./examples/ads131m04/main.go
+tinygo build -size short -o ./build/test.hex -target=pico ./examples/hlw8032/main.go
+tinygo build -size short -o ./build/test.hex -target=pico ./examples/bl0940/main.go
+tinygo build -size short -o ./build/test.hex -target=itsybitsy-m0 ./examples/atm90e32/main.go
+tinygo build -size short -o ./build/test.hex -target=circuitplay-express ./examples/statusled/main.go
+tinygo build -size short -o ./build/test.hex -target=pico ./examples/charlieplex/main.go
+tinygo build -size short -o ./build/test.hex -target=pico ./examples/flipdot/main.go
+tinygo build -size short -o ./build/test.hex -target=pico ./examples/modbus/master/main.go
+tinygo build -size short -o ./build/test.hex -target=pico ./examples/modbus/slave/main.go
+tinygo build -size short -o ./build/test.hex -target=pico ./examples/dmx/main.go
+tinygo build -size short -o ./build/test.hex -target=pico ./examples/dali/main.go
+tinygo build -size short -o ./build/test.hex -target=pico ./examples/midi/main.go
+tinygo build -size short -o ./build/test.hex -target=pico ./examples/si5351/main.go
+tinygo build -size short -o ./build/test.hex -target=pico ./examples/ad9833/main.go
+tinygo build -size short -o ./build/test.hex -target=pico ./examples/si4703/main.go
+tinygo build -size short -o ./build/test.hex -target=pico ./examples/vs1053/main.go
+tinygo build -size short -o ./build/test.hex -target=pico ./examples/rda5807/main.go
+tinygo build -size short -o ./build/test.hex -target=pico ./examples/tea5767/main.go
+tinygo build -size short -o ./build/test.hex -target=pybadge ./examples/lora/p2p/
+tinygo build -size short -o ./build/test.hex -target=nucleo-wl55jc ./examples/lora/meshtastic/
+tinygo build -size short -o ./build/test.hex -target=pico ./examples/wiegand/main.go
+tinygo build -size short -o ./build/test.hex -target=pico ./examples/pid/main.go
+tinygo build -size short -o ./build/test.hex -target=arduino-nano33 ./examples/filter/main.go
+tinygo build -size short -o ./build/test.hex -target=pico ./examples/sx1280/ranging/main.go
+tinygo build -size short -o ./build/test.hex -target=bluepill ./examples/persist/main.go
+tinygo build -size short -o ./build/test.hex -target=pico ./examples/tlc5940/main.go
+tinygo build -size short -o ./build/test.hex -target=pico ./examples/tlc59711/main.go
+tinygo build -size short -o ./build/test.hex -target=microbit ./examples/settings/main.go
+tinygo build -size short -o ./build/test.uf2 -target=pico ./examples/usbmsc/main.go
@@ -11,3 +11,19 @@ type SPI interface {
 	// If you want to transfer multiple bytes, it is more efficient to use Tx instead.
 	Transfer(b byte) (byte, error)
 }
+
+// AsyncSPI is an SPI bus that can also run a transfer in the background,
+// for instance with DMA, while the CPU does something else. Drivers check
+// for it with a type assertion on their SPI bus.
+type AsyncSPI interface {
+	SPI
+
+	// StartTx starts a transfer like Tx, and returns without waiting for it
+	// to complete. The buffers must not be used, and no other transfer may
+	// be done, until IsBusy returns false.
+	StartTx(w, r []byte) error
+
+	// IsBusy returns whether the transfer started by StartTx is still
+	// running.
+	IsBusy() bool
+}
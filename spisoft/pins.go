@@ -0,0 +1,44 @@
+//go:build tinygo
+
+package spisoft
+
+import (
+	"machine"
+	"time"
+
+	"tinygo.org/x/drivers/delay"
+)
+
+type pin = machine.Pin
+
+const noPin = machine.NoPin
+
+func sleep(d time.Duration) {
+	delay.Sleep(d)
+}
+
+// New returns a software SPI bus on the given pins. sdo or sdi may be
+// machine.NoPin for a bus that only writes or only reads.
+func New(sck, sdo, sdi machine.Pin) *SPI {
+	return &SPI{
+		sck: sck,
+		sdo: sdo,
+		sdi: sdi,
+	}
+}
+
+// Configure sets up the pins and the SPI mode.
+func (s *SPI) Configure(config SPIConfig) error {
+	s.setMode(config)
+
+	s.sck.Configure(machine.PinConfig{Mode: machine.PinOutput})
+	s.sck.Set(s.cpol)
+	if s.sdo != machine.NoPin {
+		s.sdo.Configure(machine.PinConfig{Mode: machine.PinOutput})
+		s.sdo.Low()
+	}
+	if s.sdi != machine.NoPin {
+		s.sdi.Configure(machine.PinConfig{Mode: machine.PinInput})
+	}
+	return nil
+}
@@ -0,0 +1,17 @@
+//go:build !tinygo
+
+package spisoft
+
+import "time"
+
+// pin stands in for machine.Pin, so that the bit-banging can be tested with
+// go test.
+type pin interface {
+	Set(high bool)
+	Get() bool
+}
+
+var noPin pin
+
+// sleep does not wait, there is no device on the other end to wait for.
+func sleep(d time.Duration) {}
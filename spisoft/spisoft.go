@@ -0,0 +1,104 @@
+// Package spisoft provides a software (bit-banged) SPI bus over arbitrary
+// GPIO pins. It implements drivers.SPI, so it can be passed to any driver
+// that takes a SPI bus, on boards that have run out of hardware SPI
+// peripherals or can't route them to the needed pins.
+//
+// It is much slower than a hardware SPI bus and keeps the CPU busy while
+// transferring.
+package spisoft // import "tinygo.org/x/drivers/spisoft"
+
+import "time"
+
+// SPI is a SPI implementation by software.
+type SPI struct {
+	sck      pin
+	sdo      pin
+	sdi      pin
+	cpol     bool
+	cpha     bool
+	lsbFirst bool
+	half     time.Duration // half of a SCK cycle
+}
+
+// SPIConfig is used to store config info for SPI. It mirrors
+// machine.SPIConfig.
+type SPIConfig struct {
+	// Frequency of SCK. Defaults to 1MHz. The actual frequency is lower, as
+	// the time to toggle the pins adds to the delays.
+	Frequency uint32
+
+	// Mode is the SPI mode, 0 to 3: bit 1 is the clock polarity (CPOL) and
+	// bit 0 the clock phase (CPHA).
+	Mode uint8
+
+	LSBFirst bool
+}
+
+// setMode sets the timing and the SPI mode from config.
+func (s *SPI) setMode(config SPIConfig) {
+	if config.Frequency == 0 {
+		config.Frequency = 1000000
+	}
+	s.half = time.Second / time.Duration(config.Frequency) / 2
+	s.cpol = config.Mode&0b10 != 0
+	s.cpha = config.Mode&0b01 != 0
+	s.lsbFirst = config.LSBFirst
+}
+
+// Tx transmits w and receives into r at the same time. If w is nil, zeros
+// are sent; if r is nil, the received bytes are dropped. Otherwise both must
+// have the same length.
+func (s *SPI) Tx(w, r []byte) error {
+	n := len(w)
+	if w == nil {
+		n = len(r)
+	}
+	for i := 0; i < n; i++ {
+		var b byte
+		if w != nil {
+			b = w[i]
+		}
+		b = s.transfer(b)
+		if r != nil {
+			r[i] = b
+		}
+	}
+	return nil
+}
+
+// Transfer writes a single byte and returns the byte received at the same
+// time.
+func (s *SPI) Transfer(b byte) (byte, error) {
+	return s.transfer(b), nil
+}
+
+func (s *SPI) transfer(out byte) byte {
+	var in byte
+	for i := 0; i < 8; i++ {
+		shift := 7 - i
+		if s.lsbFirst {
+			shift = i
+		}
+		bit := out>>shift&1 != 0
+
+		// With CPHA=0 data is set up before the first edge and sampled on
+		// it, with CPHA=1 it is set up on the first edge and sampled on the
+		// second one.
+		if s.cpha {
+			s.sck.Set(!s.cpol)
+		}
+		if s.sdo != noPin {
+			s.sdo.Set(bit)
+		}
+		sleep(s.half)
+		s.sck.Set(s.cpha == s.cpol)
+		if s.sdi != noPin && s.sdi.Get() {
+			in |= 1 << shift
+		}
+		sleep(s.half)
+		if !s.cpha {
+			s.sck.Set(s.cpol)
+		}
+	}
+	return in
+}
@@ -0,0 +1,125 @@
+//go:build !tinygo
+
+package spisoft
+
+import (
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+)
+
+// slave is a SPI device in one of the four modes. It samples SDO and sets
+// SDI on the clock edges of its mode, like a real device would.
+type slave struct {
+	cpol, cpha bool
+	lsbFirst   bool
+	sck, sdo   bool
+	sdi        bool
+	received   []bool // bits sampled
+	send       []bool // bits to send
+}
+
+// funcPin is a pin driven or read by the slave.
+type funcPin struct {
+	set func(bool)
+	get func() bool
+}
+
+func (p funcPin) Set(high bool) { p.set(high) }
+func (p funcPin) Get() bool     { return p.get() }
+
+func newSlave(mode uint8, lsbFirst bool, send []byte) *slave {
+	s := &slave{cpol: mode&0b10 != 0, cpha: mode&0b01 != 0, lsbFirst: lsbFirst}
+	s.sck = s.cpol
+	for _, b := range send {
+		s.send = append(s.send, s.bits(b)...)
+	}
+	if !s.cpha {
+		// The first bit is set up before the first edge.
+		s.shift()
+	}
+	return s
+}
+
+// bits returns the bits of b in the order they are sent.
+func (s *slave) bits(b byte) []bool {
+	var bits []bool
+	for i := 0; i < 8; i++ {
+		shift := 7 - i
+		if s.lsbFirst {
+			shift = i
+		}
+		bits = append(bits, b>>shift&1 != 0)
+	}
+	return bits
+}
+
+func (s *slave) shift() {
+	s.sdi = false
+	if len(s.send) > 0 {
+		s.sdi, s.send = s.send[0], s.send[1:]
+	}
+}
+
+func (s *slave) clock(level bool) {
+	if level == s.sck {
+		return
+	}
+	s.sck = level
+	leading := level != s.cpol
+	if leading != s.cpha {
+		s.received = append(s.received, s.sdo)
+	} else {
+		s.shift()
+	}
+}
+
+func (s *slave) bus() *SPI {
+	return &SPI{
+		sck: funcPin{set: s.clock},
+		sdo: funcPin{set: func(v bool) { s.sdo = v }},
+		sdi: funcPin{get: func() bool { return s.sdi }},
+	}
+}
+
+func TestModes(t *testing.T) {
+	c := qt.New(t)
+	for mode := uint8(0); mode < 4; mode++ {
+		for _, lsbFirst := range []bool{false, true} {
+			s := newSlave(mode, lsbFirst, []byte{0xC5, 0x3A})
+			bus := s.bus()
+			bus.setMode(SPIConfig{Mode: mode, LSBFirst: lsbFirst})
+
+			r := make([]byte, 2)
+			c.Assert(bus.Tx([]byte{0x81, 0x6E}, r), qt.IsNil)
+			c.Assert(r, qt.DeepEquals, []byte{0xC5, 0x3A}, qt.Commentf("mode %d, LSB first %v", mode, lsbFirst))
+			want := append(s.bits(0x81), s.bits(0x6E)...)
+			c.Assert(s.received, qt.DeepEquals, want, qt.Commentf("mode %d, LSB first %v", mode, lsbFirst))
+			// The clock is left idle.
+			c.Assert(s.sck, qt.Equals, s.cpol)
+		}
+	}
+}
+
+func TestHalfDuplex(t *testing.T) {
+	c := qt.New(t)
+	s := newSlave(0, false, []byte{0xA5})
+	bus := s.bus()
+	bus.setMode(SPIConfig{})
+
+	// Without SDI, nothing is received.
+	bus.sdi = noPin
+	b, err := bus.Transfer(0x0F)
+	c.Assert(err, qt.IsNil)
+	c.Assert(b, qt.Equals, byte(0))
+	c.Assert(s.received, qt.DeepEquals, s.bits(0x0F))
+
+	// Reading sends zeros.
+	s = newSlave(0, false, []byte{0xA5})
+	bus = s.bus()
+	bus.setMode(SPIConfig{})
+	r := make([]byte, 1)
+	c.Assert(bus.Tx(nil, r), qt.IsNil)
+	c.Assert(r, qt.DeepEquals, []byte{0xA5})
+	c.Assert(s.received, qt.DeepEquals, s.bits(0))
+}
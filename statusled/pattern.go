@@ -0,0 +1,150 @@
+package statusled
+
+import (
+	"image/color"
+	"time"
+)
+
+// Step is a step of a pattern: the LED shows Color for Duration, or fades to
+// it from the color of the previous step if Fade is set.
+type Step struct {
+	Color    color.RGBA
+	Duration time.Duration
+	Fade     bool
+}
+
+// Pattern is a sequence of steps, played once or repeated.
+type Pattern struct {
+	Steps  []Step
+	Repeat bool
+}
+
+// Colors used by the named patterns.
+var (
+	Black  = color.RGBA{A: 255}
+	Red    = color.RGBA{R: 255, A: 255}
+	Green  = color.RGBA{G: 255, A: 255}
+	Blue   = color.RGBA{B: 255, A: 255}
+	Yellow = color.RGBA{R: 255, G: 160, A: 255}
+	White  = color.RGBA{R: 255, G: 255, B: 255, A: 255}
+)
+
+// Named patterns.
+var (
+	// Off turns the LED off.
+	Off = Solid(Black)
+
+	// Boot fades in white and back out once, to show that the firmware
+	// started.
+	Boot = Pattern{Steps: []Step{
+		{Color: White, Duration: 300 * time.Millisecond, Fade: true},
+		{Color: Black, Duration: 300 * time.Millisecond, Fade: true},
+	}}
+
+	// NetworkConnecting quickly pulses blue.
+	NetworkConnecting = Pulse(Blue, time.Second)
+
+	// NetworkConnected blinks green briefly every few seconds.
+	NetworkConnected = Pattern{Repeat: true, Steps: []Step{
+		{Color: Green, Duration: 50 * time.Millisecond},
+		{Color: Black, Duration: 2950 * time.Millisecond},
+	}}
+
+	// NetworkOffline slowly pulses yellow.
+	NetworkOffline = Pulse(Yellow, 3*time.Second)
+)
+
+// Solid shows a single color.
+func Solid(c color.RGBA) Pattern {
+	return Pattern{Steps: []Step{{Color: c}}}
+}
+
+// Blink turns the LED on and off with the given period.
+func Blink(c color.RGBA, period time.Duration) Pattern {
+	return Pattern{Repeat: true, Steps: []Step{
+		{Color: c, Duration: period / 2},
+		{Color: Black, Duration: period / 2},
+	}}
+}
+
+// Pulse fades the LED in and out with the given period.
+func Pulse(c color.RGBA, period time.Duration) Pattern {
+	return Pattern{Repeat: true, Steps: []Step{
+		{Color: c, Duration: period / 2, Fade: true},
+		{Color: Black, Duration: period / 2, Fade: true},
+	}}
+}
+
+// ErrorCode blinks red code times, then pauses, so that an error can be
+// identified by counting the blinks.
+func ErrorCode(code int) Pattern {
+	steps := make([]Step, 0, 2*code)
+	for i := 0; i < code; i++ {
+		steps = append(steps,
+			Step{Color: Red, Duration: 200 * time.Millisecond},
+			Step{Color: Black, Duration: 300 * time.Millisecond})
+	}
+	if len(steps) > 0 {
+		steps[len(steps)-1].Duration = 1500 * time.Millisecond
+	}
+	return Pattern{Steps: steps, Repeat: true}
+}
+
+// At returns the color of the pattern at the given time after its start.
+// A pattern that is not repeated keeps the color of its last step.
+func (p Pattern) At(t time.Duration) color.RGBA {
+	if len(p.Steps) == 0 {
+		return Black
+	}
+	total := time.Duration(0)
+	for _, s := range p.Steps {
+		total += s.Duration
+	}
+	if total <= 0 {
+		return p.Steps[len(p.Steps)-1].Color
+	}
+	if p.Repeat {
+		t %= total
+	} else if t >= total {
+		return p.Steps[len(p.Steps)-1].Color
+	}
+
+	for i, s := range p.Steps {
+		if t >= s.Duration {
+			t -= s.Duration
+			continue
+		}
+		if !s.Fade {
+			return s.Color
+		}
+		from := Black
+		if i > 0 {
+			from = p.Steps[i-1].Color
+		} else if p.Repeat {
+			from = p.Steps[len(p.Steps)-1].Color
+		}
+		return blend(from, s.Color, t, s.Duration)
+	}
+	return p.Steps[len(p.Steps)-1].Color
+}
+
+// equal returns whether two patterns have the same steps.
+func (p Pattern) equal(q Pattern) bool {
+	if p.Repeat != q.Repeat || len(p.Steps) != len(q.Steps) {
+		return false
+	}
+	for i := range p.Steps {
+		if p.Steps[i] != q.Steps[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// blend returns the color at t of a fade from a to b taking d.
+func blend(a, b color.RGBA, t, d time.Duration) color.RGBA {
+	mix := func(x, y uint8) uint8 {
+		return uint8(int64(x) + (int64(y)-int64(x))*int64(t)/int64(d))
+	}
+	return color.RGBA{R: mix(a.R, b.R), G: mix(a.G, b.G), B: mix(a.B, b.B), A: 255}
+}
@@ -0,0 +1,56 @@
+//go:build tinygo
+
+package statusled
+
+import (
+	"image/color"
+	"machine"
+)
+
+// PWM is the PWM peripheral interface used by PWMOutput. It is implemented
+// by the machine.PWM types.
+type PWM interface {
+	Configure(config machine.PWMConfig) error
+	Channel(pin machine.Pin) (channel uint8, err error)
+	Top() uint32
+	Set(channel uint8, value uint32)
+}
+
+// PWMOutput drives a discrete RGB LED with three PWM channels.
+type PWMOutput struct {
+	pwm         [3]PWM
+	ch          [3]uint8
+	commonAnode bool
+}
+
+// NewPWMOutput configures the PWM channels of the red, green and blue pins.
+// The pins may use the same or different PWM peripherals. Set commonAnode
+// for LEDs with a common anode, which are on when the pin is low.
+func NewPWMOutput(pwms [3]PWM, pins [3]machine.Pin, commonAnode bool) (*PWMOutput, error) {
+	o := &PWMOutput{pwm: pwms, commonAnode: commonAnode}
+	for i := range pins {
+		// 1kHz, which doesn't flicker.
+		if err := pwms[i].Configure(machine.PWMConfig{Period: 1e6}); err != nil {
+			return nil, err
+		}
+		ch, err := pwms[i].Channel(pins[i])
+		if err != nil {
+			return nil, err
+		}
+		o.ch[i] = ch
+	}
+	return o, nil
+}
+
+// SetColor sets the duty cycles of the channels.
+func (o *PWMOutput) SetColor(c color.RGBA) error {
+	for i, v := range [3]uint8{c.R, c.G, c.B} {
+		top := o.pwm[i].Top()
+		duty := uint32(uint64(top) * uint64(v) / 255)
+		if o.commonAnode {
+			duty = top - duty
+		}
+		o.pwm[i].Set(o.ch[i], duty)
+	}
+	return nil
+}
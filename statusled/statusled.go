@@ -0,0 +1,99 @@
+// Package statusled shows the state of the firmware on a single RGB LED,
+// such as a WS2812, an APA102 or a discrete LED on three PWM channels, with
+// patterns like blink codes and pulses running in the background.
+package statusled // import "tinygo.org/x/drivers/statusled"
+
+import (
+	"image/color"
+	"time"
+)
+
+// Output sets the color of the LED.
+type Output interface {
+	SetColor(c color.RGBA) error
+}
+
+// OutputFunc adapts a function to the Output interface, for instance to
+// write a single color to a ws2812.Device or an apa102.Device:
+//
+//	statusled.OutputFunc(func(c color.RGBA) error {
+//		return ws.WriteColors([]color.RGBA{c})
+//	})
+type OutputFunc func(c color.RGBA) error
+
+// SetColor calls f(c).
+func (f OutputFunc) SetColor(c color.RGBA) error {
+	return f(c)
+}
+
+// UpdateInterval is the interval at which Run updates the LED.
+const UpdateInterval = 20 * time.Millisecond
+
+// LED runs patterns on an Output.
+type LED struct {
+	out     Output
+	pattern Pattern
+	start   time.Time
+	last    color.RGBA
+	written bool
+
+	// Brightness scales all colors, from 0 to 255. It defaults to 255.
+	Brightness uint8
+}
+
+// New returns a new status LED, initially off.
+func New(out Output) *LED {
+	return &LED{out: out, pattern: Off, Brightness: 255}
+}
+
+// SetPattern starts a pattern from its first step, unless it is already
+// running.
+func (l *LED) SetPattern(p Pattern) {
+	if p.equal(l.pattern) {
+		return
+	}
+	l.pattern = p
+	l.start = time.Time{}
+}
+
+// Pattern returns the running pattern.
+func (l *LED) Pattern() Pattern {
+	return l.pattern
+}
+
+// Update sets the LED to the color of the pattern at the given time. It
+// only writes to the output when the color changes. Call it regularly, at
+// least every UpdateInterval for smooth fades, or use Run.
+func (l *LED) Update(now time.Time) error {
+	if l.start.IsZero() {
+		l.start = now
+	}
+	c := scale(l.pattern.At(now.Sub(l.start)), l.Brightness)
+	if l.written && c == l.last {
+		return nil
+	}
+	if err := l.out.SetColor(c); err != nil {
+		return err
+	}
+	l.last, l.written = c, true
+	return nil
+}
+
+// Run updates the LED every UpdateInterval forever. Start it in its own
+// goroutine.
+func (l *LED) Run() {
+	for {
+		l.Update(time.Now())
+		time.Sleep(UpdateInterval)
+	}
+}
+
+func scale(c color.RGBA, brightness uint8) color.RGBA {
+	b := uint16(brightness)
+	return color.RGBA{
+		R: uint8(uint16(c.R) * b / 255),
+		G: uint8(uint16(c.G) * b / 255),
+		B: uint8(uint16(c.B) * b / 255),
+		A: c.A,
+	}
+}
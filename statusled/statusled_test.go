@@ -0,0 +1,52 @@
+package statusled
+
+import (
+	"image/color"
+	"testing"
+	"time"
+
+	qt "github.com/frankban/quicktest"
+)
+
+func TestPatternAt(t *testing.T) {
+	c := qt.New(t)
+
+	p := Pulse(Blue, time.Second)
+	c.Assert(p.At(0), qt.Equals, Black)
+	c.Assert(p.At(250*time.Millisecond).B, qt.Equals, uint8(127))
+	c.Assert(p.At(500*time.Millisecond), qt.Equals, color.RGBA{B: 255, A: 255})
+	c.Assert(p.At(1250*time.Millisecond).B, qt.Equals, uint8(127))
+
+	e := ErrorCode(3)
+	c.Assert(e.At(100*time.Millisecond), qt.Equals, Red)
+	c.Assert(e.At(300*time.Millisecond), qt.Equals, Black)
+	c.Assert(e.At(1100*time.Millisecond), qt.Equals, Red)
+	c.Assert(e.At(2000*time.Millisecond), qt.Equals, Black)
+	c.Assert(e.At(2750*time.Millisecond), qt.Equals, Red)
+
+	// Patterns that don't repeat keep their last color.
+	c.Assert(Boot.At(time.Hour), qt.Equals, Black)
+	c.Assert(Solid(Green).At(time.Hour), qt.Equals, Green)
+}
+
+func TestUpdate(t *testing.T) {
+	c := qt.New(t)
+	var written []color.RGBA
+	led := New(OutputFunc(func(c color.RGBA) error {
+		written = append(written, c)
+		return nil
+	}))
+	led.Brightness = 128
+
+	start := time.Now()
+	led.SetPattern(Blink(Red, time.Second))
+	c.Assert(led.Update(start), qt.IsNil)
+	c.Assert(led.Update(start.Add(100*time.Millisecond)), qt.IsNil)
+	c.Assert(led.Update(start.Add(600*time.Millisecond)), qt.IsNil)
+	c.Assert(written, qt.DeepEquals, []color.RGBA{{R: 128, A: 255}, {A: 255}})
+
+	// Setting the running pattern again doesn't restart it.
+	led.SetPattern(Blink(Red, time.Second))
+	c.Assert(led.Update(start.Add(700*time.Millisecond)), qt.IsNil)
+	c.Assert(written, qt.HasLen, 2)
+}
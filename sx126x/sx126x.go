@@ -696,6 +696,35 @@ func (d *Device) Rx(timeoutMs uint32) ([]uint8, error) {
 	return pkt, nil
 }
 
+// StartRSSI tunes to a frequency in Hz and receives continuously, with the
+// interrupts disabled, for InstantRSSI. See lora.ScanChannels.
+func (d *Device) StartRSSI(freq uint32) {
+	if d.controller != nil {
+		d.controller.SetRfSwitchMode(RFSWITCH_RX)
+	}
+	d.SetStandby()
+	d.ClearIrqStatus(SX126X_IRQ_ALL)
+	d.SetDioIrqParams(SX126X_IRQ_NONE, SX126X_IRQ_NONE, SX126X_IRQ_NONE, SX126X_IRQ_NONE)
+	d.SetPacketType(SX126X_PACKET_TYPE_LORA)
+	d.SetRfFrequency(freq)
+	d.SetModulationParams(d.loraConf.Sf, bandwidth(d.loraConf.Bw), d.loraConf.Cr, d.loraConf.Ldr)
+	d.SetRx(0xFFFFFF)
+	// Let the RSSI settle.
+	time.Sleep(time.Millisecond)
+}
+
+// InstantRSSI returns the signal level received, in dBm, while receiving.
+func (d *Device) InstantRSSI() int16 {
+	r := d.ExecGetCommand(SX126X_CMD_GET_RSSI_INST, 1)
+	return -int16(r[0]) / 2
+}
+
+// StopRSSI puts the radio back in standby after StartRSSI.
+func (d *Device) StopRSSI() {
+	d.SetStandby()
+	d.ClearIrqStatus(SX126X_IRQ_ALL)
+}
+
 // HandleInterrupt must be called by main code on DIO state change.
 func (d *Device) HandleInterrupt() {
 	st := d.GetIrqStatus()
@@ -28,6 +28,7 @@ type Device struct {
 	controller     RadioController      // to manage interactions with the radio
 	deepSleep      bool                 // Internal Sleep state
 	deviceType     int                  // sx1261,sx1262,sx1268 (defaults sx1261)
+	rssiFreq       uint32               // frequency of StartRSSI
 	spiTxBuf       []byte               // global Tx buffer to avoid heap allocations in interrupt
 	spiRxBuf       []byte               // global Rx buffer to avoid heap allocations in interrupt
 }
@@ -137,11 +138,16 @@ func (d *Device) IsTransmitting() bool {
 // LastPacketRSSI gives the RSSI of the last packet received
 func (d *Device) LastPacketRSSI() uint8 {
 	// section 5.5.5
-	var adjustValue uint8 = 157
-	if d.loraConf.Freq < 868000000 {
-		adjustValue = 164
+	return d.ReadRegister(SX127X_REG_PKT_RSSI_VALUE) - rssiOffset(d.loraConf.Freq)
+}
+
+// rssiOffset returns the offset of the RSSI registers to dBm on the port
+// of a frequency, section 5.5.5.
+func rssiOffset(freq uint32) uint8 {
+	if freq < 868000000 {
+		return 164
 	}
-	return d.ReadRegister(SX127X_REG_PKT_RSSI_VALUE) - adjustValue
+	return 157
 }
 
 // LastPacketSNR gives the SNR of the last packet received
@@ -154,6 +160,36 @@ func (d *Device) GetRSSI() uint8 {
 	return d.ReadRegister(SX127X_REG_RSSI_VALUE)
 }
 
+// StartRSSI tunes to a frequency in Hz and receives continuously, with the
+// interrupts masked, for InstantRSSI. See lora.ScanChannels.
+func (d *Device) StartRSSI(freq uint32) {
+	d.SetOpModeLora()
+	d.SetOpMode(SX127X_OPMODE_SLEEP)
+	d.SetLowFrequencyModeOn(false)
+	d.WriteRegister(SX127X_REG_LNA, SX127X_LNA_MAX_GAIN)
+	conf := d.loraConf.Freq
+	d.SetFrequency(freq)
+	d.loraConf.Freq = conf
+	d.rssiFreq = freq
+	d.WriteRegister(SX127X_REG_IRQ_FLAGS_MASK, 0xFF)
+	d.WriteRegister(SX127X_REG_IRQ_FLAGS, 0xFF)
+	d.SetOpMode(SX127X_OPMODE_STANDBY)
+	d.SetOpMode(SX127X_OPMODE_RX)
+	// Let the RSSI settle.
+	time.Sleep(time.Millisecond)
+}
+
+// InstantRSSI returns the signal level received, in dBm, while receiving.
+func (d *Device) InstantRSSI() int16 {
+	return int16(d.GetRSSI()) - int16(rssiOffset(d.rssiFreq))
+}
+
+// StopRSSI puts the radio back in standby after StartRSSI.
+func (d *Device) StopRSSI() {
+	d.SetOpMode(SX127X_OPMODE_STANDBY)
+	d.WriteRegister(SX127X_REG_IRQ_FLAGS, 0xFF)
+}
+
 /*
 // GetBandwidth returns the bandwidth the LoRa module is using
 func (d *Device) GetBandwidth() int32 {
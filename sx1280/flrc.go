@@ -0,0 +1,82 @@
+package sx1280
+
+// FLRCConfig holds the configuration of the FLRC (Fast Long Range
+// Communication) modem, a GFSK modulation with forward error correction of
+// up to 1.3 Mb/s.
+type FLRCConfig struct {
+	Freq         uint32 // Frequency in Hz
+	Bitrate      uint8  // SX1280_FLRC_BR_*, 1.3 Mb/s when 0
+	CodingRate   uint8  // SX1280_FLRC_CR_*, 1/2 when 0
+	Shaping      uint8  // SX1280_FLRC_BT_*, none when 0
+	PreambleBits uint8  // Preamble length in bits, from 8 to 32 by 4, 32 when 0
+	SyncWord     uint32 // Sync word, none when 0
+	Crc          uint8  // CRC length in bytes: 0 (none), 2, 3 or 4
+	TxPowerDBm   int8   // Tx power in dBm
+}
+
+// FLRCConfig applies an FLRC configuration, used by Tx and Rx in place of
+// the LoRa configuration until LoraConfig is called. Packets are up to 127
+// bytes long.
+func (d *Device) FLRCConfig(cnf FLRCConfig) {
+	if cnf.Bitrate == 0 {
+		cnf.Bitrate = SX1280_FLRC_BR_1300_BW_1_2
+	}
+	if cnf.PreambleBits == 0 {
+		cnf.PreambleBits = 32
+	}
+	d.flrcConf = cnf
+	d.packetType = SX1280_PACKET_TYPE_FLRC
+	d.SetStandby()
+	d.ClearIrqStatus(SX1280_IRQ_ALL)
+	d.SetDioIrqParams(SX1280_IRQ_NONE, SX1280_IRQ_NONE, SX1280_IRQ_NONE, SX1280_IRQ_NONE)
+	d.setupFLRC(SX1280_FLRC_MAX_PACKET_LENGTH)
+}
+
+// setupFLRC sets the packet type, frequency, power, modulation and packet
+// parameters and the sync word of the FLRC configuration.
+func (d *Device) setupFLRC(payloadLength uint8) {
+	c := &d.flrcConf
+	d.SetPacketType(SX1280_PACKET_TYPE_FLRC)
+	d.SetRfFrequency(c.Freq)
+	d.SetTxParams(c.TxPowerDBm, SX1280_RAMP_20_US)
+	d.SetBufferBaseAddress(0, 0)
+	d.SetModulationParams(c.Bitrate, c.CodingRate, c.Shaping)
+	d.SetPacketParams(flrcPacketParams(c, payloadLength))
+	if c.SyncWord != 0 {
+		// The FLRC sync word is the last 4 bytes of the GFSK one.
+		d.WriteRegister(SX1280_REG_SYNC_WORD_1+1, []uint8{
+			uint8(c.SyncWord >> 24), uint8(c.SyncWord >> 16), uint8(c.SyncWord >> 8), uint8(c.SyncWord),
+		})
+	}
+}
+
+// flrcPacketParams returns the packet parameters of an FLRC configuration,
+// for variable length packets.
+func flrcPacketParams(c *FLRCConfig, payloadLength uint8) [7]uint8 {
+	bits := c.PreambleBits
+	if bits < 8 {
+		bits = 8
+	} else if bits > 32 {
+		bits = 32
+	}
+	syncLen, syncMatch := uint8(SX1280_FLRC_SYNC_WORD_NONE), uint8(SX1280_FLRC_SYNC_MATCH_OFF)
+	if c.SyncWord != 0 {
+		syncLen, syncMatch = SX1280_FLRC_SYNC_WORD_32_BITS, SX1280_FLRC_SYNC_MATCH_1
+	}
+	crc := uint8(SX1280_FLRC_CRC_OFF)
+	if c.Crc >= 2 && c.Crc <= 4 {
+		crc = (c.Crc - 1) << 4
+	}
+	if payloadLength > SX1280_FLRC_MAX_PACKET_LENGTH {
+		payloadLength = SX1280_FLRC_MAX_PACKET_LENGTH
+	}
+	return [7]uint8{
+		(bits/4 - 1) << 4,
+		syncLen,
+		syncMatch,
+		SX1280_FLRC_PACKET_VARIABLE,
+		payloadLength,
+		crc,
+		SX1280_FLRC_WHITENING_OFF,
+	}
+}
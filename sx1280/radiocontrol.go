@@ -0,0 +1,14 @@
+package sx1280
+
+// SX1280 radio transceiver has several pins that control
+// NSS, BUSY, RESET and the external RF switch or amplifier.
+// This interface allows the creation of struct
+// that can drive them (used in Tx, Rx and ranging).
+type RadioController interface {
+	Init() error
+	Reset() error
+	SetRfSwitchMode(mode int) error
+	SetNss(state bool) error
+	WaitWhileBusy() error
+	SetupInterrupts(handler func()) error
+}
@@ -0,0 +1,108 @@
+//go:build tinygo
+
+package sx1280
+
+import (
+	"machine"
+
+	"time"
+)
+
+// RadioControl for modules that are connected using normal pins, such as the
+// Lambda80 or the E28. The rxEn and txEn pins drive the RF switch or the
+// amplifier of the module, use machine.NoPin when there are none.
+type RadioControl struct {
+	nssPin, busyPin, dio1Pin, rstPin machine.Pin
+	rxEnPin, txEnPin                 machine.Pin
+}
+
+func NewRadioControl(nssPin, busyPin, dio1Pin, rstPin,
+	rxEnPin, txEnPin machine.Pin) *RadioControl {
+	return &RadioControl{
+		nssPin:  nssPin,
+		busyPin: busyPin,
+		dio1Pin: dio1Pin,
+		rstPin:  rstPin,
+		rxEnPin: rxEnPin,
+		txEnPin: txEnPin,
+	}
+}
+
+// SetNss sets the NSS line aka chip select for SPI.
+func (rc *RadioControl) SetNss(state bool) error {
+	rc.nssPin.Set(state)
+	return nil
+}
+
+// WaitWhileBusy wait until the radio is no longer busy
+func (rc *RadioControl) WaitWhileBusy() error {
+	count := 100
+	for count > 0 {
+		if !rc.busyPin.Get() {
+			return nil
+		}
+		count--
+		time.Sleep(time.Millisecond)
+	}
+	return errWaitWhileBusyTimeout
+}
+
+// Init() configures whatever needed for sx1280 radio control
+func (rc *RadioControl) Init() error {
+	rc.nssPin.Configure(machine.PinConfig{Mode: machine.PinOutput})
+	rc.nssPin.High()
+	rc.busyPin.Configure(machine.PinConfig{Mode: machine.PinInput})
+	rc.rstPin.Configure(machine.PinConfig{Mode: machine.PinOutput})
+	rc.rstPin.High()
+	for _, p := range []machine.Pin{rc.rxEnPin, rc.txEnPin} {
+		if p != machine.NoPin {
+			p.Configure(machine.PinConfig{Mode: machine.PinOutput})
+			p.Low()
+		}
+	}
+	return nil
+}
+
+// Reset pulses the reset line, and waits for the radio to be ready.
+func (rc *RadioControl) Reset() error {
+	rc.rstPin.Low()
+	time.Sleep(2 * time.Millisecond)
+	rc.rstPin.High()
+	time.Sleep(10 * time.Millisecond)
+	return rc.WaitWhileBusy()
+}
+
+// add interrupt handler for Radio IRQs for pins
+func (rc *RadioControl) SetupInterrupts(handler func()) error {
+	irqHandler = handler
+
+	rc.dio1Pin.Configure(machine.PinConfig{Mode: machine.PinInputPulldown})
+	if err := rc.dio1Pin.SetInterrupt(machine.PinRising, handleInterrupt); err != nil {
+		return errRadioNotFound
+	}
+
+	return nil
+}
+
+var irqHandler func()
+
+func handleInterrupt(machine.Pin) {
+	irqHandler()
+}
+
+func (rc *RadioControl) SetRfSwitchMode(mode int) error {
+	rx, tx := false, false
+	switch mode {
+	case RFSWITCH_RX:
+		rx = true
+	case RFSWITCH_TX:
+		tx = true
+	}
+	if rc.rxEnPin != machine.NoPin {
+		rc.rxEnPin.Set(rx)
+	}
+	if rc.txEnPin != machine.NoPin {
+		rc.txEnPin.Set(tx)
+	}
+	return nil
+}
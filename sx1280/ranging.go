@@ -0,0 +1,259 @@
+package sx1280
+
+import (
+	"errors"
+	"time"
+
+	"tinygo.org/x/drivers/lora"
+)
+
+var (
+	// ErrRangingTimeout is returned when no ranging answer or request came
+	// before the timeout.
+	ErrRangingTimeout = errors.New("sx1280: ranging timeout")
+
+	errRangingConfig = errors.New("sx1280: ranging needs SF5 to SF10 and a bandwidth of 406, 812 or 1625kHz")
+)
+
+// rangingCalibration holds the default calibrations of the delay of the
+// radio, in ranging mode, for SF5 to SF10. They are those of Semtech's
+// reference design, and are close for most modules.
+var rangingCalibration = [3][6]uint16{
+	{10299, 10271, 10244, 10242, 10230, 10246}, // 406.25kHz
+	{11486, 11474, 11453, 11426, 11417, 11401}, // 812.5kHz
+	{13308, 13493, 13528, 13515, 13430, 13376}, // 1625kHz
+}
+
+// defaultRangingCalibration returns the default calibration for a spreading
+// factor and a bandwidth (lora.Bandwidth_*), and false when ranging does not
+// support them.
+func defaultRangingCalibration(sf, bw uint8) (uint16, bool) {
+	if sf < 5 || sf > 10 {
+		return 0, false
+	}
+	switch bw {
+	case lora.Bandwidth_406_25:
+		return rangingCalibration[0][sf-5], true
+	case lora.Bandwidth_812_5:
+		return rangingCalibration[1][sf-5], true
+	case lora.Bandwidth_1625_0:
+		return rangingCalibration[2][sf-5], true
+	}
+	return 0, false
+}
+
+// bandwidthHz returns a bandwidth (lora.Bandwidth_*) in Hz.
+func bandwidthHz(bw uint8) uint32 {
+	switch bw {
+	case lora.Bandwidth_203_125:
+		return 203125
+	case lora.Bandwidth_406_25:
+		return 406250
+	case lora.Bandwidth_1625_0:
+		return 1625000
+	default:
+		return 812500
+	}
+}
+
+// rangingDistance converts a raw ranging result, a 24-bit two's complement
+// round trip time, to a distance in cm for the bandwidth of the exchange:
+// distance = result * 150m / (2^12 * bandwidth in MHz).
+func rangingDistance(raw uint32, bwHz uint32) int32 {
+	v := int64(raw & 0xFFFFFF)
+	if v&0x800000 != 0 {
+		v -= 1 << 24
+	}
+	return int32(v * 150 * 100 * 1000000 / (4096 * int64(bwHz)))
+}
+
+// median returns the median of values, which it sorts.
+func median(values []int32) int32 {
+	for i := 1; i < len(values); i++ {
+		for j := i; j > 0 && values[j] < values[j-1]; j-- {
+			values[j], values[j-1] = values[j-1], values[j]
+		}
+	}
+	n := len(values)
+	if n%2 == 1 {
+		return values[n/2]
+	}
+	return (values[n/2-1] + values[n/2]) / 2
+}
+
+// SetRangingCalibration sets the calibration of the delay of the radio used
+// for ranging, which depends on the spreading factor and the bandwidth. 0
+// restores the default for the LoRa configuration.
+func (d *Device) SetRangingCalibration(cal uint16) {
+	d.rangingCal = cal
+}
+
+// SetRangingOffset sets a distance in cm subtracted from the ranging results,
+// such as the one found by CalibrateRanging.
+func (d *Device) SetRangingOffset(offset int32) {
+	d.rangingOffset = offset
+}
+
+// RangingOffset returns the distance in cm subtracted from the ranging
+// results.
+func (d *Device) RangingOffset() int32 {
+	return d.rangingOffset
+}
+
+// Range measures the distance to the slave at address, in cm, with a single
+// ranging exchange on the frequency, spreading factor and bandwidth of the
+// LoRa configuration. The slave answers with RespondRanging, with the same
+// configuration. It returns ErrRangingTimeout when the slave did not answer
+// within timeoutMs.
+//
+// A single result is noisy, of a few meters; RangeMedian combines several.
+func (d *Device) Range(address uint32, timeoutMs uint32) (int32, error) {
+	if err := d.setupRanging(SX1280_RANGING_ROLE_MASTER, RFSWITCH_TX); err != nil {
+		return 0, err
+	}
+	d.WriteRegister(SX1280_REG_RANGING_REQUEST_ADDRESS, []uint8{
+		uint8(address >> 24), uint8(address >> 16), uint8(address >> 8), uint8(address),
+	})
+	irqVal := uint16(SX1280_IRQ_RANGING_MASTER_RESULT_VALID | SX1280_IRQ_RANGING_MASTER_TIMEOUT)
+	d.SetDioIrqParams(irqVal, irqVal, SX1280_IRQ_NONE, SX1280_IRQ_NONE)
+	d.SetTx(SX1280_PERIOD_BASE_1_MS, timeoutParam(timeoutMs))
+
+	msg := <-d.GetRadioEventChan()
+	switch msg.EventType {
+	case lora.RadioEventRxDone:
+	case lora.RadioEventTimeout:
+		d.SetStandby()
+		return 0, ErrRangingTimeout
+	default:
+		d.SetStandby()
+		return 0, errUnexpectedRxRadioEvent
+	}
+	raw := d.rangingResult(SX1280_RANGING_RESULT_RAW)
+	return rangingDistance(raw, bandwidthHz(d.loraConf.Bw)) - d.rangingOffset, nil
+}
+
+// RangeMedian ranges n times with the slave at address, and returns the
+// median of the distances measured, in cm. Exchanges without an answer are
+// skipped; it returns ErrRangingTimeout when none was answered.
+func (d *Device) RangeMedian(address uint32, n int, timeoutMs uint32) (int32, error) {
+	results := make([]int32, 0, n)
+	for i := 0; i < n; i++ {
+		r, err := d.Range(address, timeoutMs)
+		if err == ErrRangingTimeout {
+			continue
+		}
+		if err != nil {
+			return 0, err
+		}
+		results = append(results, r)
+	}
+	if len(results) == 0 {
+		return 0, ErrRangingTimeout
+	}
+	return median(results), nil
+}
+
+// CalibrateRanging ranges n times with the slave at address, placed at a
+// known distance in cm, and sets the ranging offset so that the median of
+// the results is that distance. The offset depends on the modules, their
+// antennas and the LoRa configuration, so calibrate again when they change.
+func (d *Device) CalibrateRanging(address uint32, distance int32, n int, timeoutMs uint32) error {
+	offset := d.rangingOffset
+	d.rangingOffset = 0
+	m, err := d.RangeMedian(address, n, timeoutMs)
+	if err != nil {
+		d.rangingOffset = offset
+		return err
+	}
+	d.rangingOffset = m - distance
+	return nil
+}
+
+// RespondRanging answers a ranging request for address, on the frequency,
+// spreading factor and bandwidth of the LoRa configuration. Requests for
+// other addresses are ignored. It returns nil once a request was answered,
+// and ErrRangingTimeout when none came within timeoutMs; 0 waits forever.
+func (d *Device) RespondRanging(address uint32, timeoutMs uint32) error {
+	if err := d.setupRanging(SX1280_RANGING_ROLE_SLAVE, RFSWITCH_RX); err != nil {
+		return err
+	}
+	d.WriteRegister(SX1280_REG_RANGING_DEVICE_ADDRESS, []uint8{
+		uint8(address >> 24), uint8(address >> 16), uint8(address >> 8), uint8(address),
+	})
+	// Check the 32 bits of the address.
+	idCheck := d.readRegister8(SX1280_REG_RANGING_ID_CHECK_LENGTH)
+	d.WriteRegister(SX1280_REG_RANGING_ID_CHECK_LENGTH, []uint8{idCheck&0x3F | 3<<6})
+	irqVal := uint16(SX1280_IRQ_RANGING_SLAVE_RESPONSE_DONE | SX1280_IRQ_RANGING_SLAVE_REQUEST_DISCARD | SX1280_IRQ_TIMEOUT)
+	d.SetDioIrqParams(irqVal, irqVal, SX1280_IRQ_NONE, SX1280_IRQ_NONE)
+
+	deadline := time.Now().Add(time.Duration(timeoutMs) * time.Millisecond)
+	for {
+		var count uint16
+		if timeoutMs > 0 {
+			left := time.Until(deadline).Milliseconds()
+			if left <= 0 {
+				d.SetStandby()
+				return ErrRangingTimeout
+			}
+			count = timeoutParam(uint32(left))
+		}
+		d.SetRx(SX1280_PERIOD_BASE_1_MS, count)
+
+		msg := <-d.GetRadioEventChan()
+		switch msg.EventType {
+		case lora.RadioEventTxDone:
+			return nil
+		case lora.RadioEventUnhandled:
+			// A request for another address.
+		case lora.RadioEventTimeout:
+			d.SetStandby()
+			return ErrRangingTimeout
+		default:
+			d.SetStandby()
+			return errUnexpectedRxRadioEvent
+		}
+	}
+}
+
+// setupRanging configures the radio for a ranging exchange in role, with the
+// LoRa configuration and the ranging calibration.
+func (d *Device) setupRanging(role uint8, rfSwitchMode int) error {
+	if d.loraConf.Freq == 0 {
+		return lora.ErrUndefinedLoraConf
+	}
+	cal, ok := defaultRangingCalibration(d.loraConf.Sf, d.loraConf.Bw)
+	if !ok {
+		return errRangingConfig
+	}
+	if d.rangingCal != 0 {
+		cal = d.rangingCal
+	}
+	if d.controller != nil {
+		err := d.controller.SetRfSwitchMode(rfSwitchMode)
+		if err != nil {
+			return err
+		}
+	}
+
+	d.SetStandby()
+	d.ClearIrqStatus(SX1280_IRQ_ALL)
+	d.setupLora(SX1280_PACKET_TYPE_RANGING, 0)
+	d.WriteRegister(SX1280_REG_RANGING_CALIBRATION, []uint8{uint8(cal >> 8), uint8(cal)})
+	d.ExecSetCommand(SX1280_CMD_SET_RANGING_ROLE, []uint8{role})
+	return nil
+}
+
+// rangingResult reads the result of the last ranging exchange, raw or
+// averaged (SX1280_RANGING_RESULT_*).
+func (d *Device) rangingResult(kind uint8) uint32 {
+	d.SetStandbyXosc()
+	// The result registers are read with the LoRa modem clock enabled.
+	clk := d.readRegister8(SX1280_REG_LORA_MODEM_CLOCK)
+	d.WriteRegister(SX1280_REG_LORA_MODEM_CLOCK, []uint8{clk | 1<<1})
+	cfg := d.readRegister8(SX1280_REG_RANGING_RESULT_CONFIG)
+	d.WriteRegister(SX1280_REG_RANGING_RESULT_CONFIG, []uint8{cfg&SX1280_RANGING_RESULT_MASK | kind<<4})
+	r, _ := d.ReadRegister(SX1280_REG_RANGING_RESULT, 3)
+	raw := uint32(r[0])<<16 | uint32(r[1])<<8 | uint32(r[2])
+	d.SetStandby()
+	return raw
+}
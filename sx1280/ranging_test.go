@@ -0,0 +1,49 @@
+package sx1280
+
+import (
+	"testing"
+
+	"tinygo.org/x/drivers/lora"
+)
+
+func TestRangingDistance(t *testing.T) {
+	for _, tc := range []struct {
+		raw  uint32
+		bw   uint32
+		dist int32
+	}{
+		{0, 812500, 0},
+		{4096, 812500, 18461},      // 150m / 0.8125MHz
+		{0xFFF000, 812500, -18461}, // negative, close to the master
+		{4096, 1625000, 9230},
+		{1000, 406250, 9014},
+	} {
+		if got := rangingDistance(tc.raw, tc.bw); got != tc.dist {
+			t.Errorf("rangingDistance(%#x, %d) = %d, want %d", tc.raw, tc.bw, got, tc.dist)
+		}
+	}
+}
+
+func TestDefaultRangingCalibration(t *testing.T) {
+	if cal, ok := defaultRangingCalibration(lora.SpreadingFactor5, lora.Bandwidth_406_25); !ok || cal != 10299 {
+		t.Errorf("SF5 406kHz: %d, %v", cal, ok)
+	}
+	if cal, ok := defaultRangingCalibration(lora.SpreadingFactor10, lora.Bandwidth_1625_0); !ok || cal != 13376 {
+		t.Errorf("SF10 1625kHz: %d, %v", cal, ok)
+	}
+	if _, ok := defaultRangingCalibration(lora.SpreadingFactor11, lora.Bandwidth_812_5); ok {
+		t.Error("SF11 is not supported by ranging")
+	}
+	if _, ok := defaultRangingCalibration(lora.SpreadingFactor7, lora.Bandwidth_203_125); ok {
+		t.Error("203kHz is not supported by ranging")
+	}
+}
+
+func TestMedian(t *testing.T) {
+	if m := median([]int32{520, -80, 310, 300, 4000}); m != 310 {
+		t.Errorf("median of 5: %d", m)
+	}
+	if m := median([]int32{40, 10, 30, 20}); m != 25 {
+		t.Errorf("median of 4: %d", m)
+	}
+}
@@ -0,0 +1,198 @@
+package sx1280
+
+const (
+	// SX1280 physical layer properties
+	SX1280_CRYSTAL_FREQ       = 52000000
+	SX1280_FREQ_STEP_EXPONENT = 18
+	SX1280_MAX_PACKET_LENGTH  = 255
+
+	// SX1280 SPI commands
+	// operational modes commands
+	SX1280_CMD_NOP                        = 0x00
+	SX1280_CMD_SET_SLEEP                  = 0x84
+	SX1280_CMD_SET_STANDBY                = 0x80
+	SX1280_CMD_SET_FS                     = 0xC1
+	SX1280_CMD_SET_TX                     = 0x83
+	SX1280_CMD_SET_RX                     = 0x82
+	SX1280_CMD_SET_RX_DUTY_CYCLE          = 0x94
+	SX1280_CMD_SET_CAD                    = 0xC5
+	SX1280_CMD_SET_TX_CONTINUOUS_WAVE     = 0xD1
+	SX1280_CMD_SET_TX_CONTINUOUS_PREAMBLE = 0xD2
+	SX1280_CMD_SET_AUTO_TX                = 0x98
+	SX1280_CMD_SET_AUTO_FS                = 0x9E
+	SX1280_CMD_SET_REGULATOR_MODE         = 0x96
+	SX1280_CMD_SET_SAVE_CONTEXT           = 0xD5
+	SX1280_CMD_SET_LONG_PREAMBLE          = 0x9B
+	SX1280_CMD_SET_RANGING_ROLE           = 0xA3
+	SX1280_CMD_SET_ADVANCED_RANGING       = 0x9A
+	SX1280_CMD_SET_UART_SPEED             = 0x9D
+	SX1280_CMD_SET_PERF_COUNTER_MODE      = 0x9C
+
+	// register and buffer access commands
+	SX1280_CMD_WRITE_REGISTER = 0x18
+	SX1280_CMD_READ_REGISTER  = 0x19
+	SX1280_CMD_WRITE_BUFFER   = 0x1A
+	SX1280_CMD_READ_BUFFER    = 0x1B
+
+	// DIO and IRQ control
+	SX1280_CMD_SET_DIO_IRQ_PARAMS = 0x8D
+	SX1280_CMD_GET_IRQ_STATUS     = 0x15
+	SX1280_CMD_CLEAR_IRQ_STATUS   = 0x97
+
+	// RF, modulation and packet commands
+	SX1280_CMD_SET_RF_FREQUENCY        = 0x86
+	SX1280_CMD_SET_PACKET_TYPE         = 0x8A
+	SX1280_CMD_GET_PACKET_TYPE         = 0x03
+	SX1280_CMD_SET_TX_PARAMS           = 0x8E
+	SX1280_CMD_SET_MODULATION_PARAMS   = 0x8B
+	SX1280_CMD_SET_PACKET_PARAMS       = 0x8C
+	SX1280_CMD_SET_CAD_PARAMS          = 0x88
+	SX1280_CMD_SET_BUFFER_BASE_ADDRESS = 0x8F
+
+	// status commands
+	SX1280_CMD_GET_STATUS           = 0xC0
+	SX1280_CMD_GET_RSSI_INST        = 0x1F
+	SX1280_CMD_GET_RX_BUFFER_STATUS = 0x17
+	SX1280_CMD_GET_PACKET_STATUS    = 0x1D
+
+	// SX1280 registers
+	SX1280_REG_FIRMWARE_VERSION          = 0x0153
+	SX1280_REG_RANGING_REQUEST_ADDRESS   = 0x0912 // 4 bytes, MSB first
+	SX1280_REG_RANGING_DEVICE_ADDRESS    = 0x0916 // 4 bytes, MSB first
+	SX1280_REG_RANGING_FILTER_WINDOW     = 0x091E
+	SX1280_REG_RANGING_RESULT_CONFIG     = 0x0924
+	SX1280_REG_LORA_SF_CONFIG            = 0x0925
+	SX1280_REG_RANGING_CALIBRATION       = 0x092C // 2 bytes, MSB first
+	SX1280_REG_RANGING_ID_CHECK_LENGTH   = 0x0931
+	SX1280_REG_LORA_FREQ_ERR_CORRECTION  = 0x093C
+	SX1280_REG_LORA_SYNC_WORD_MSB        = 0x0944
+	SX1280_REG_LORA_SYNC_WORD_LSB        = 0x0945
+	SX1280_REG_RANGING_RESULT            = 0x0961 // 3 bytes, MSB first
+	SX1280_REG_RANGING_RSSI              = 0x0964
+	SX1280_REG_LORA_MODEM_CLOCK          = 0x097F
+	SX1280_REG_SYNC_WORD_1               = 0x09CE // 5 bytes, FLRC uses the last 4
+	SX1280_REG_LORA_IMPLICIT_PAYLOAD_LEN = 0x0901
+
+	// SX1280 SPI command variables
+	// SX1280_CMD_SET_STANDBY
+	SX1280_STANDBY_RC   = 0x00
+	SX1280_STANDBY_XOSC = 0x01
+
+	// SX1280_CMD_SET_SLEEP
+	SX1280_SLEEP_DATA_BUFFER_RETAIN = 0x02
+	SX1280_SLEEP_DATA_RAM_RETAIN    = 0x01
+
+	// SX1280_CMD_SET_TX, SX1280_CMD_SET_RX
+	SX1280_PERIOD_BASE_15_US = 0x00 // 15.625 us
+	SX1280_PERIOD_BASE_62_US = 0x01 // 62.5 us
+	SX1280_PERIOD_BASE_1_MS  = 0x02
+	SX1280_PERIOD_BASE_4_MS  = 0x03
+	SX1280_RX_TIMEOUT_NONE   = 0x0000
+	SX1280_RX_TIMEOUT_INF    = 0xFFFF
+
+	// SX1280_CMD_SET_REGULATOR_MODE
+	SX1280_REGULATOR_LDO   = 0x00
+	SX1280_REGULATOR_DC_DC = 0x01
+
+	// SX1280_CMD_SET_RANGING_ROLE
+	SX1280_RANGING_ROLE_SLAVE  = 0x00
+	SX1280_RANGING_ROLE_MASTER = 0x01
+
+	// SX1280_CMD_SET_PACKET_TYPE
+	SX1280_PACKET_TYPE_GFSK    = 0x00
+	SX1280_PACKET_TYPE_LORA    = 0x01
+	SX1280_PACKET_TYPE_RANGING = 0x02
+	SX1280_PACKET_TYPE_FLRC    = 0x03
+	SX1280_PACKET_TYPE_BLE     = 0x04
+
+	// SX1280_CMD_SET_TX_PARAMS
+	SX1280_RAMP_02_US = 0x00
+	SX1280_RAMP_04_US = 0x20
+	SX1280_RAMP_06_US = 0x40
+	SX1280_RAMP_08_US = 0x60
+	SX1280_RAMP_10_US = 0x80
+	SX1280_RAMP_12_US = 0xA0
+	SX1280_RAMP_16_US = 0xC0
+	SX1280_RAMP_20_US = 0xE0
+
+	// SX1280_CMD_SET_MODULATION_PARAMS, LoRa and ranging
+	SX1280_LORA_BW_1600 = 0x0A // 1625.0 kHz
+	SX1280_LORA_BW_800  = 0x18 // 812.5 kHz
+	SX1280_LORA_BW_400  = 0x26 // 406.25 kHz
+	SX1280_LORA_BW_200  = 0x34 // 203.125 kHz
+	SX1280_LORA_CR_4_5  = 0x01
+	SX1280_LORA_CR_4_6  = 0x02
+	SX1280_LORA_CR_4_7  = 0x03
+	SX1280_LORA_CR_4_8  = 0x04
+	SX1280_LORA_CR_LI_5 = 0x05 // long interleaving 4/5
+	SX1280_LORA_CR_LI_6 = 0x06 // long interleaving 4/6
+	SX1280_LORA_CR_LI_8 = 0x07 // long interleaving 4/8
+
+	// SX1280_CMD_SET_MODULATION_PARAMS, FLRC
+	SX1280_FLRC_BR_1300_BW_1_2 = 0x45 // 1.3 Mb/s
+	SX1280_FLRC_BR_1000_BW_1_2 = 0x69 // 1.04 Mb/s
+	SX1280_FLRC_BR_650_BW_0_6  = 0x86 // 650 kb/s
+	SX1280_FLRC_BR_520_BW_0_6  = 0xAA // 520 kb/s
+	SX1280_FLRC_BR_325_BW_0_3  = 0xC7 // 325 kb/s
+	SX1280_FLRC_BR_260_BW_0_3  = 0xEB // 260 kb/s
+	SX1280_FLRC_CR_1_2         = 0x00
+	SX1280_FLRC_CR_3_4         = 0x02
+	SX1280_FLRC_CR_1_0         = 0x04
+	SX1280_FLRC_BT_OFF         = 0x00
+	SX1280_FLRC_BT_1_0         = 0x10
+	SX1280_FLRC_BT_0_5         = 0x20
+
+	// SX1280_CMD_SET_PACKET_PARAMS, LoRa and ranging
+	SX1280_LORA_HEADER_EXPLICIT = 0x00
+	SX1280_LORA_HEADER_IMPLICIT = 0x80
+	SX1280_LORA_CRC_ON          = 0x20
+	SX1280_LORA_CRC_OFF         = 0x00
+	SX1280_LORA_IQ_STANDARD     = 0x40
+	SX1280_LORA_IQ_INVERTED     = 0x00
+
+	// SX1280_CMD_SET_PACKET_PARAMS, FLRC
+	SX1280_FLRC_PREAMBLE_8_BITS   = 0x10
+	SX1280_FLRC_PREAMBLE_16_BITS  = 0x30
+	SX1280_FLRC_PREAMBLE_32_BITS  = 0x70
+	SX1280_FLRC_SYNC_WORD_NONE    = 0x00
+	SX1280_FLRC_SYNC_WORD_32_BITS = 0x04
+	SX1280_FLRC_SYNC_MATCH_OFF    = 0x00
+	SX1280_FLRC_SYNC_MATCH_1      = 0x10
+	SX1280_FLRC_PACKET_FIXED      = 0x00
+	SX1280_FLRC_PACKET_VARIABLE   = 0x20
+	SX1280_FLRC_CRC_OFF           = 0x00
+	SX1280_FLRC_CRC_2_BYTES       = 0x10
+	SX1280_FLRC_CRC_3_BYTES       = 0x20
+	SX1280_FLRC_CRC_4_BYTES       = 0x30
+	SX1280_FLRC_WHITENING_OFF     = 0x08
+	SX1280_FLRC_MAX_PACKET_LENGTH = 127
+
+	// SX1280_REG_RANGING_RESULT_CONFIG
+	SX1280_RANGING_RESULT_RAW      = 0x00
+	SX1280_RANGING_RESULT_AVERAGED = 0x01
+	SX1280_RANGING_RESULT_MASK     = 0xCF
+
+	// SX1280_CMD_SET_DIO_IRQ_PARAMS
+	SX1280_IRQ_TX_DONE                       = 0x0001
+	SX1280_IRQ_RX_DONE                       = 0x0002
+	SX1280_IRQ_SYNC_WORD_VALID               = 0x0004
+	SX1280_IRQ_SYNC_WORD_ERROR               = 0x0008
+	SX1280_IRQ_HEADER_VALID                  = 0x0010
+	SX1280_IRQ_HEADER_ERROR                  = 0x0020
+	SX1280_IRQ_CRC_ERR                       = 0x0040
+	SX1280_IRQ_RANGING_SLAVE_RESPONSE_DONE   = 0x0080
+	SX1280_IRQ_RANGING_SLAVE_REQUEST_DISCARD = 0x0100
+	SX1280_IRQ_RANGING_MASTER_RESULT_VALID   = 0x0200
+	SX1280_IRQ_RANGING_MASTER_TIMEOUT        = 0x0400
+	SX1280_IRQ_RANGING_SLAVE_REQUEST_VALID   = 0x0800
+	SX1280_IRQ_CAD_DONE                      = 0x1000
+	SX1280_IRQ_CAD_DETECTED                  = 0x2000
+	SX1280_IRQ_TIMEOUT                       = 0x4000
+	SX1280_IRQ_PREAMBLE_DETECTED             = 0x8000
+	SX1280_IRQ_ALL                           = 0xFFFF
+	SX1280_IRQ_NONE                          = 0x0000
+
+	// LoRa sync words
+	SX1280_LORA_MAC_PRIVATE_SYNCWORD = 0x1424
+	SX1280_LORA_MAC_PUBLIC_SYNCWORD  = 0x3444
+)
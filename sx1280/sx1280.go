@@ -0,0 +1,703 @@
+// Package sx1280 provides a driver for SX1280 2.4GHz LoRa, FLRC and ranging
+// transceivers.
+// Inspired from the Semtech SX1280 driver of the SX1280 development kit.
+//
+// Datasheet: https://www.semtech.com/products/wireless-rf/lora-connect/sx1280
+package sx1280
+
+import (
+	"errors"
+	"time"
+
+	"tinygo.org/x/drivers"
+	"tinygo.org/x/drivers/lora"
+)
+
+var (
+	errWaitWhileBusyTimeout   = errors.New("WaitWhileBusy Timeout")
+	errRadioNotFound          = errors.New("LoRa radio not found")
+	errUnexpectedRxRadioEvent = errors.New("Unexpected Radio Event during RX")
+	errUnexpectedTxRadioEvent = errors.New("Unexpected Radio Event during TX")
+	errPacketTooLong          = errors.New("sx1280: packet too long")
+)
+
+const (
+	RFSWITCH_RX = iota
+	RFSWITCH_TX = iota
+)
+
+const (
+	SPI_BUFFER_SIZE     = 256
+	RADIOEVENTCHAN_SIZE = 1
+)
+
+// Device wraps an SPI connection to a SX1280 device.
+type Device struct {
+	spi            drivers.SPI          // SPI bus for module communication
+	radioEventChan chan lora.RadioEvent // Channel for Receiving events
+	loraConf       lora.Config          // Current Lora configuration
+	flrcConf       FLRCConfig           // Current FLRC configuration
+	packetType     uint8                // LoRa or FLRC, used by Tx and Rx
+	controller     RadioController      // to manage interactions with the radio
+	deepSleep      bool                 // Internal Sleep state
+	rangingCal     uint16               // ranging calibration, 0 for the default
+	rangingOffset  int32                // distance subtracted from the ranging results, in cm
+	spiTxBuf       []byte               // global Tx buffer to avoid heap allocations in interrupt
+	spiRxBuf       []byte               // global Rx buffer to avoid heap allocations in interrupt
+}
+
+// New creates a new SX1280 connection. The radio is driven through a
+// RadioController, set with SetRadioController.
+//
+// This function only creates the Device object, it does not touch the device.
+func New(spi drivers.SPI) *Device {
+	return &Device{
+		spi:            spi,
+		radioEventChan: make(chan lora.RadioEvent, RADIOEVENTCHAN_SIZE),
+		packetType:     SX1280_PACKET_TYPE_LORA,
+		spiTxBuf:       make([]byte, SPI_BUFFER_SIZE+4),
+		spiRxBuf:       make([]byte, SPI_BUFFER_SIZE),
+	}
+}
+
+// --------------------------------------------------
+//  Helper functions
+// --------------------------------------------------
+
+// frequencySteps converts a frequency in Hz to the steps of the PLL, of
+// 52MHz / 2^18 (about 198Hz).
+func frequencySteps(freq uint32) uint32 {
+	return uint32(uint64(freq) << SX1280_FREQ_STEP_EXPONENT / SX1280_CRYSTAL_FREQ)
+}
+
+// preambleParam encodes a LoRa preamble length in symbols as its mantissa and
+// exponent, rounding up to the next length that can be encoded.
+func preambleParam(symbols uint16) uint8 {
+	if symbols < 1 {
+		symbols = 1
+	}
+	var exp uint8
+	for symbols > 15 {
+		symbols = (symbols + 1) >> 1
+		exp++
+	}
+	return exp<<4 | uint8(symbols)
+}
+
+// timeoutParam converts a timeout in ms to the period count of the SetTx and
+// SetRx commands, in steps of 1ms. 0 means no timeout.
+func timeoutParam(timeoutMs uint32) uint16 {
+	if timeoutMs >= SX1280_RX_TIMEOUT_INF {
+		return SX1280_RX_TIMEOUT_INF - 1
+	}
+	return uint16(timeoutMs)
+}
+
+// --------------------------------------------------
+//  Channel and events
+// --------------------------------------------------
+
+// GetRadioEventChan returns the RadioIf event channel
+func (d *Device) GetRadioEventChan() chan lora.RadioEvent {
+	return d.radioEventChan
+}
+
+// SetRadioController sets the interface for controlling the NSS, BUSY and
+// RESET pins and the RF switch, and resets the radio.
+func (d *Device) SetRadioController(rc RadioController) error {
+	d.controller = rc
+	if err := d.controller.Init(); err != nil {
+		return err
+	}
+	if err := d.controller.Reset(); err != nil {
+		return err
+	}
+	return d.controller.SetupInterrupts(d.HandleInterrupt)
+}
+
+// --------------------------------------------------
+//  Operational modes functions
+// --------------------------------------------------
+
+// Reset resets the device, which is then in the standby mode.
+func (d *Device) Reset() {
+	d.controller.Reset()
+	d.deepSleep = false
+}
+
+// DetectDevice checks if device is ready, by writing and reading back the
+// LoRa sync word.
+func (d *Device) DetectDevice() bool {
+	bak := d.GetSyncWord()
+	d.SetSyncWord(0xBEEF)
+	tmp := d.GetSyncWord()
+	if tmp != 0xBEEF {
+		return false
+	} else {
+		d.SetSyncWord(bak)
+		return true
+	}
+}
+
+// SetSleep sets the device in sleep mode, keeping the data buffer.
+func (d *Device) SetSleep() {
+	d.ExecSetCommand(SX1280_CMD_SET_SLEEP, []uint8{SX1280_SLEEP_DATA_BUFFER_RETAIN})
+}
+
+// SetStandby sets the device in standby mode, on its RC oscillator.
+func (d *Device) SetStandby() {
+	d.ExecSetCommand(SX1280_CMD_SET_STANDBY, []uint8{SX1280_STANDBY_RC})
+}
+
+// SetStandbyXosc sets the device in standby mode, with its crystal oscillator
+// running.
+func (d *Device) SetStandbyXosc() {
+	d.ExecSetCommand(SX1280_CMD_SET_STANDBY, []uint8{SX1280_STANDBY_XOSC})
+}
+
+// SetFs sets the device in frequency synthesis mode
+func (d *Device) SetFs() {
+	d.ExecSetCommand(SX1280_CMD_SET_FS, nil)
+}
+
+// SetTxContinuousWave sets the device in a continuous wave transmission mode,
+// for tests.
+func (d *Device) SetTxContinuousWave() {
+	d.ExecSetCommand(SX1280_CMD_SET_TX_CONTINUOUS_WAVE, nil)
+}
+
+// SetTx sets the device in transmit mode, with a timeout in steps of the
+// period base (SX1280_PERIOD_BASE_*).
+func (d *Device) SetTx(periodBase uint8, count uint16) {
+	d.ExecSetCommand(SX1280_CMD_SET_TX, []uint8{periodBase, uint8(count >> 8), uint8(count)})
+}
+
+// SetRx sets the device in receive mode, with a timeout in steps of the period
+// base (SX1280_PERIOD_BASE_*). SX1280_RX_TIMEOUT_INF keeps receiving packets
+// until another mode is set.
+func (d *Device) SetRx(periodBase uint8, count uint16) {
+	d.ExecSetCommand(SX1280_CMD_SET_RX, []uint8{periodBase, uint8(count >> 8), uint8(count)})
+}
+
+// SetRegulatorMode selects the LDO or the DC-DC converter, which uses less
+// current on the modules that have its inductor.
+func (d *Device) SetRegulatorMode(mode uint8) {
+	d.ExecSetCommand(SX1280_CMD_SET_REGULATOR_MODE, []uint8{mode})
+}
+
+// --------------------------------------------------
+//  Registers and buffer
+// --------------------------------------------------
+
+// ReadRegister reads register value
+func (d *Device) ReadRegister(addr, size uint16) ([]uint8, error) {
+	d.CheckDeviceReady()
+	d.controller.SetNss(false)
+	// Send command
+	d.spiTxBuf = d.spiTxBuf[:0]
+	d.spiTxBuf = append(d.spiTxBuf, SX1280_CMD_READ_REGISTER, uint8((addr&0xFF00)>>8), uint8(addr&0x00FF), 0x00)
+	d.spi.Tx(d.spiTxBuf, nil)
+	// Read registers
+	d.spiRxBuf = d.spiRxBuf[0:size]
+	d.spi.Tx(nil, d.spiRxBuf)
+	d.controller.SetNss(true)
+	d.controller.WaitWhileBusy()
+	return d.spiRxBuf, nil
+}
+
+// WriteRegister writes value to register
+func (d *Device) WriteRegister(addr uint16, data []uint8) {
+	d.CheckDeviceReady()
+	d.controller.SetNss(false)
+	d.spiTxBuf = d.spiTxBuf[:0]
+	d.spiTxBuf = append(d.spiTxBuf, SX1280_CMD_WRITE_REGISTER, uint8((addr&0xFF00)>>8), uint8(addr&0x00FF))
+	d.spiTxBuf = append(d.spiTxBuf, data...)
+	d.spi.Tx(d.spiTxBuf, nil)
+	d.controller.SetNss(true)
+	d.controller.WaitWhileBusy()
+}
+
+// readRegister8 reads a single register.
+func (d *Device) readRegister8(addr uint16) uint8 {
+	r, _ := d.ReadRegister(addr, 1)
+	return r[0]
+}
+
+// WriteBuffer writes data to the data buffer, from its start.
+func (d *Device) WriteBuffer(data []uint8) {
+	d.CheckDeviceReady()
+	d.controller.SetNss(false)
+	d.spiTxBuf = d.spiTxBuf[:0]
+	d.spiTxBuf = append(d.spiTxBuf, SX1280_CMD_WRITE_BUFFER, 0x00)
+	d.spiTxBuf = append(d.spiTxBuf, data...)
+	d.spi.Tx(d.spiTxBuf, nil)
+	d.controller.SetNss(true)
+	d.controller.WaitWhileBusy()
+}
+
+// ReadBuffer reads size bytes of the data buffer, from offset.
+func (d *Device) ReadBuffer(offset, size uint8) []uint8 {
+	d.CheckDeviceReady()
+	d.controller.SetNss(false)
+	d.spiTxBuf = d.spiTxBuf[:0]
+	d.spiTxBuf = append(d.spiTxBuf, SX1280_CMD_READ_BUFFER, offset, 0x00)
+	d.spi.Tx(d.spiTxBuf, nil)
+	d.spiRxBuf = d.spiRxBuf[0:size]
+	d.spi.Tx(nil, d.spiRxBuf)
+	d.controller.SetNss(true)
+	d.controller.WaitWhileBusy()
+	return d.spiRxBuf
+}
+
+// --------------------------------------------------
+//  DIO and IRQ control
+// --------------------------------------------------
+
+// SetDioIrqParams configures DIO Irq
+func (d *Device) SetDioIrqParams(irqMask, dio1Mask, dio2Mask, dio3Mask uint16) {
+	d.ExecSetCommand(SX1280_CMD_SET_DIO_IRQ_PARAMS, []uint8{
+		uint8(irqMask >> 8), uint8(irqMask),
+		uint8(dio1Mask >> 8), uint8(dio1Mask),
+		uint8(dio2Mask >> 8), uint8(dio2Mask),
+		uint8(dio3Mask >> 8), uint8(dio3Mask),
+	})
+}
+
+// GetIrqStatus returns the IRQ status
+func (d *Device) GetIrqStatus() (irqStatus uint16) {
+	r := d.ExecGetCommand(SX1280_CMD_GET_IRQ_STATUS, 2)
+	return uint16(r[0])<<8 | uint16(r[1])
+}
+
+// ClearIrqStatus clears IRQ flags
+func (d *Device) ClearIrqStatus(clearIrqParams uint16) {
+	d.ExecSetCommand(SX1280_CMD_CLEAR_IRQ_STATUS, []uint8{uint8(clearIrqParams >> 8), uint8(clearIrqParams)})
+}
+
+// --------------------------------------------------
+//  RF, modulation and packet
+// --------------------------------------------------
+
+// GetStatus returns the radio status byte
+func (d *Device) GetStatus() (radioStatus uint8) {
+	d.CheckDeviceReady()
+	d.controller.SetNss(false)
+	d.spiRxBuf = d.spiRxBuf[:1]
+	d.spi.Tx([]uint8{SX1280_CMD_GET_STATUS}, d.spiRxBuf)
+	d.controller.SetNss(true)
+	return d.spiRxBuf[0]
+}
+
+// GetPacketType returns the current packet type (SX1280_PACKET_TYPE_*)
+func (d *Device) GetPacketType() (packetType uint8) {
+	r := d.ExecGetCommand(SX1280_CMD_GET_PACKET_TYPE, 1)
+	return r[0]
+}
+
+// SetPacketType sets the packet type (SX1280_PACKET_TYPE_*), which must be
+// set before the modulation and packet parameters.
+func (d *Device) SetPacketType(packetType uint8) {
+	d.ExecSetCommand(SX1280_CMD_SET_PACKET_TYPE, []uint8{packetType})
+}
+
+// SetRfFrequency sets the radio frequency, in Hz
+func (d *Device) SetRfFrequency(frequency uint32) {
+	f := frequencySteps(frequency)
+	d.ExecSetCommand(SX1280_CMD_SET_RF_FREQUENCY, []uint8{uint8(f >> 16), uint8(f >> 8), uint8(f)})
+}
+
+// SetTxParams sets the output power, from -18 to +13 dBm, and the ramp time
+// (SX1280_RAMP_*).
+func (d *Device) SetTxParams(power int8, rampTime uint8) {
+	if power > 13 {
+		power = 13
+	} else if power < -18 {
+		power = -18
+	}
+	d.ExecSetCommand(SX1280_CMD_SET_TX_PARAMS, []uint8{uint8(power + 18), rampTime})
+}
+
+// SetBufferBaseAddress sets the start of the transmitted and received data in
+// the data buffer.
+func (d *Device) SetBufferBaseAddress(txBaseAddress, rxBaseAddress uint8) {
+	d.ExecSetCommand(SX1280_CMD_SET_BUFFER_BASE_ADDRESS, []uint8{txBaseAddress, rxBaseAddress})
+}
+
+// SetModulationParams sets the three modulation parameters of the current
+// packet type. For LoRa and ranging, they are the spreading factor (SF << 4),
+// the bandwidth (SX1280_LORA_BW_*) and the coding rate (SX1280_LORA_CR_*).
+func (d *Device) SetModulationParams(p1, p2, p3 uint8) {
+	d.ExecSetCommand(SX1280_CMD_SET_MODULATION_PARAMS, []uint8{p1, p2, p3})
+}
+
+// SetPacketParams sets the seven packet parameters of the current packet
+// type.
+func (d *Device) SetPacketParams(p [7]uint8) {
+	d.ExecSetCommand(SX1280_CMD_SET_PACKET_PARAMS, p[:])
+}
+
+// GetRxBufferStatus returns the length of the last packet received and its
+// start in the data buffer.
+func (d *Device) GetRxBufferStatus() (payloadLengthRx uint8, rxStartBufferPointer uint8) {
+	r := d.ExecGetCommand(SX1280_CMD_GET_RX_BUFFER_STATUS, 2)
+	return r[0], r[1]
+}
+
+// GetPacketStatus returns the RSSI (in dBm) and for LoRa the SNR (in dB) of
+// the last packet received.
+func (d *Device) GetPacketStatus() (rssi int16, snr int8) {
+	r := d.ExecGetCommand(SX1280_CMD_GET_PACKET_STATUS, 5)
+	if d.packetType == SX1280_PACKET_TYPE_FLRC {
+		return -int16(r[1]) / 2, 0
+	}
+	return -int16(r[0]) / 2, int8(r[1]) / 4
+}
+
+// SetSyncWord sets the LoRa sync word, in the same format as for the SX126x:
+// 0x1424 for private networks, 0x3444 for public ones.
+func (d *Device) SetSyncWord(sw uint16) {
+	d.loraConf.SyncWord = sw
+	d.WriteRegister(SX1280_REG_LORA_SYNC_WORD_MSB, []uint8{uint8(sw >> 8), uint8(sw)})
+}
+
+// GetSyncWord returns the LoRa sync word
+func (d *Device) GetSyncWord() uint16 {
+	r, _ := d.ReadRegister(SX1280_REG_LORA_SYNC_WORD_MSB, 2)
+	return uint16(r[0])<<8 | uint16(r[1])
+}
+
+// SetPublicNetwork sets the sync word of public or private networks
+func (d *Device) SetPublicNetwork(enable bool) {
+	if enable {
+		d.SetSyncWord(SX1280_LORA_MAC_PUBLIC_SYNCWORD)
+	} else {
+		d.SetSyncWord(SX1280_LORA_MAC_PRIVATE_SYNCWORD)
+	}
+}
+
+// CheckDeviceReady wakes the device up if needed and waits until it is no
+// longer busy.
+func (d *Device) CheckDeviceReady() error {
+	if d.deepSleep {
+		d.controller.SetNss(false)
+		time.Sleep(time.Millisecond)
+		d.controller.SetNss(true)
+		d.deepSleep = false
+	}
+	return d.controller.WaitWhileBusy()
+}
+
+// ExecSetCommand send a command to configure the peripheral
+func (d *Device) ExecSetCommand(cmd uint8, buf []uint8) {
+	d.CheckDeviceReady()
+	d.deepSleep = cmd == SX1280_CMD_SET_SLEEP
+	d.controller.SetNss(false)
+	// Send command and params
+	d.spiTxBuf = d.spiTxBuf[:0]
+	d.spiTxBuf = append(d.spiTxBuf, cmd)
+	d.spiTxBuf = append(d.spiTxBuf, buf...)
+	d.spi.Tx(d.spiTxBuf, nil)
+	d.controller.SetNss(true)
+	if cmd != SX1280_CMD_SET_SLEEP {
+		d.controller.WaitWhileBusy()
+	}
+}
+
+// ExecGetCommand queries the peripheral
+func (d *Device) ExecGetCommand(cmd uint8, size uint8) []uint8 {
+	d.CheckDeviceReady()
+	d.controller.SetNss(false)
+	// Send the command and flush first status byte (as not used)
+	d.spiTxBuf = d.spiTxBuf[:0]
+	d.spiTxBuf = append(d.spiTxBuf, cmd, 0x00)
+	d.spi.Tx(d.spiTxBuf, nil)
+	// Read resp
+	d.spiRxBuf = d.spiRxBuf[:size]
+	d.spi.Tx(nil, d.spiRxBuf)
+	d.controller.SetNss(true)
+	d.controller.WaitWhileBusy()
+	return d.spiRxBuf
+}
+
+// --------------------------------------------------
+//  LoRa configuration (lora.Radio interface)
+// --------------------------------------------------
+
+// SetFrequency sets the frequency, in Hz (2400MHz to 2500MHz)
+func (d *Device) SetFrequency(freq uint32) {
+	d.loraConf.Freq = freq
+}
+
+// SetIqMode defines the current IQ Mode (Standard/Inverted)
+func (d *Device) SetIqMode(mode uint8) {
+	if mode == 0 {
+		d.loraConf.Iq = lora.IQStandard
+	} else {
+		d.loraConf.Iq = lora.IQInverted
+	}
+}
+
+// SetCodingRate sets the coding rate
+func (d *Device) SetCodingRate(cr uint8) {
+	d.loraConf.Cr = cr
+}
+
+// SetBandwidth sets the bandwidth, one of lora.Bandwidth_203_125 to
+// lora.Bandwidth_1625_0.
+func (d *Device) SetBandwidth(bw uint8) {
+	d.loraConf.Bw = bw
+}
+
+// SetCrc sets the CRC mode (ON/OFF)
+func (d *Device) SetCrc(enable bool) {
+	if enable {
+		d.loraConf.Crc = lora.CRCOn
+	} else {
+		d.loraConf.Crc = lora.CRCOff
+	}
+}
+
+// SetSpreadingFactor sets the spreading factor, from 5 to 12
+func (d *Device) SetSpreadingFactor(sf uint8) {
+	d.loraConf.Sf = sf
+}
+
+// SetPreambleLength sets the preamble length, in symbols
+func (d *Device) SetPreambleLength(pl uint16) {
+	d.loraConf.Preamble = pl
+}
+
+// SetTxPower sets the transmit power, in dBm
+func (d *Device) SetTxPower(txpow int8) {
+	d.loraConf.LoraTxPowerDBm = txpow
+}
+
+// SetHeaderType sets implicit or explicit header mode
+func (d *Device) SetHeaderType(headerType uint8) {
+	d.loraConf.HeaderType = headerType
+}
+
+// LoraConfig applies a LoRa configuration, used by Tx, Rx and the ranging
+// functions. Its bandwidth is one of lora.Bandwidth_203_125 to
+// lora.Bandwidth_1625_0.
+func (d *Device) LoraConfig(cnf lora.Config) {
+	// Save given configuration
+	d.loraConf = cnf
+	d.loraConf.SyncWord = syncword(int(cnf.SyncWord))
+	d.packetType = SX1280_PACKET_TYPE_LORA
+	// Switch to standby prior to configuration changes
+	d.SetStandby()
+	d.ClearIrqStatus(SX1280_IRQ_ALL)
+	d.SetDioIrqParams(SX1280_IRQ_NONE, SX1280_IRQ_NONE, SX1280_IRQ_NONE, SX1280_IRQ_NONE)
+	d.setupLora(SX1280_PACKET_TYPE_LORA, 0xFF)
+	d.SetSyncWord(d.loraConf.SyncWord)
+}
+
+// setupLora sets the packet type, frequency, power and the modulation and
+// packet parameters of the LoRa configuration.
+func (d *Device) setupLora(packetType uint8, payloadLength uint8) {
+	d.SetPacketType(packetType)
+	d.SetRfFrequency(d.loraConf.Freq)
+	d.SetTxParams(d.loraConf.LoraTxPowerDBm, SX1280_RAMP_20_US)
+	d.SetBufferBaseAddress(0, 0)
+	d.SetModulationParams(d.loraConf.Sf<<4, bandwidth(d.loraConf.Bw), d.loraConf.Cr)
+	// The demodulator needs to know the range of the spreading factor
+	// (datasheet 14.4.1), and the frequency error compensation enabled.
+	switch {
+	case d.loraConf.Sf <= 6:
+		d.WriteRegister(SX1280_REG_LORA_SF_CONFIG, []uint8{0x1E})
+	case d.loraConf.Sf <= 8:
+		d.WriteRegister(SX1280_REG_LORA_SF_CONFIG, []uint8{0x37})
+	default:
+		d.WriteRegister(SX1280_REG_LORA_SF_CONFIG, []uint8{0x32})
+	}
+	fec := d.readRegister8(SX1280_REG_LORA_FREQ_ERR_CORRECTION)
+	d.WriteRegister(SX1280_REG_LORA_FREQ_ERR_CORRECTION, []uint8{fec&0xF8 | 0x01})
+
+	header, crc, iq := uint8(SX1280_LORA_HEADER_EXPLICIT), uint8(SX1280_LORA_CRC_OFF), uint8(SX1280_LORA_IQ_STANDARD)
+	if d.loraConf.HeaderType == lora.HeaderImplicit {
+		header = SX1280_LORA_HEADER_IMPLICIT
+	}
+	if d.loraConf.Crc == lora.CRCOn {
+		crc = SX1280_LORA_CRC_ON
+	}
+	if d.loraConf.Iq == lora.IQInverted {
+		iq = SX1280_LORA_IQ_INVERTED
+	}
+	d.SetPacketParams([7]uint8{preambleParam(d.loraConf.Preamble), header, payloadLength, crc, iq})
+}
+
+// setup configures the radio for the packet type of the last configuration
+// applied, LoRa or FLRC.
+func (d *Device) setup(payloadLength uint8) {
+	if d.packetType == SX1280_PACKET_TYPE_FLRC {
+		d.setupFLRC(payloadLength)
+	} else {
+		d.setupLora(SX1280_PACKET_TYPE_LORA, payloadLength)
+	}
+}
+
+// configured tells whether the configuration of the current packet type was
+// applied.
+func (d *Device) configured() bool {
+	if d.packetType == SX1280_PACKET_TYPE_FLRC {
+		return d.flrcConf.Freq != 0
+	}
+	return d.loraConf.Freq != 0
+}
+
+// Tx sends a packet with the last configuration applied, LoRa or FLRC.
+func (d *Device) Tx(pkt []uint8, timeoutMs uint32) error {
+	if !d.configured() {
+		return lora.ErrUndefinedLoraConf
+	}
+	if len(pkt) > SX1280_MAX_PACKET_LENGTH ||
+		d.packetType == SX1280_PACKET_TYPE_FLRC && len(pkt) > SX1280_FLRC_MAX_PACKET_LENGTH {
+		return errPacketTooLong
+	}
+
+	if d.controller != nil {
+		err := d.controller.SetRfSwitchMode(RFSWITCH_TX)
+		if err != nil {
+			return err
+		}
+	}
+
+	d.SetStandby()
+	d.ClearIrqStatus(SX1280_IRQ_ALL)
+	irqVal := uint16(SX1280_IRQ_TX_DONE | SX1280_IRQ_TIMEOUT)
+	d.setup(uint8(len(pkt)))
+	d.WriteBuffer(pkt)
+	d.SetDioIrqParams(irqVal, irqVal, SX1280_IRQ_NONE, SX1280_IRQ_NONE)
+	d.SetTx(SX1280_PERIOD_BASE_1_MS, timeoutParam(timeoutMs))
+
+	msg := <-d.GetRadioEventChan()
+	if msg.EventType != lora.RadioEventTxDone {
+		return errUnexpectedTxRadioEvent
+	}
+	return nil
+}
+
+// Rx waits for a packet with the last configuration applied, LoRa or FLRC.
+// It returns nil and no error when the timeout is reached.
+func (d *Device) Rx(timeoutMs uint32) ([]uint8, error) {
+	if !d.configured() {
+		return nil, lora.ErrUndefinedLoraConf
+	}
+
+	if d.controller != nil {
+		err := d.controller.SetRfSwitchMode(RFSWITCH_RX)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	d.SetStandby()
+	d.ClearIrqStatus(SX1280_IRQ_ALL)
+	irqVal := uint16(SX1280_IRQ_RX_DONE | SX1280_IRQ_TIMEOUT | SX1280_IRQ_CRC_ERR | SX1280_IRQ_HEADER_ERROR)
+	maxLength := uint8(SX1280_MAX_PACKET_LENGTH)
+	if d.packetType == SX1280_PACKET_TYPE_FLRC {
+		maxLength = SX1280_FLRC_MAX_PACKET_LENGTH
+	}
+	d.setup(maxLength)
+	d.SetDioIrqParams(irqVal, irqVal, SX1280_IRQ_NONE, SX1280_IRQ_NONE)
+	d.SetRx(SX1280_PERIOD_BASE_1_MS, timeoutParam(timeoutMs))
+
+	msg := <-d.GetRadioEventChan()
+
+	if msg.EventType == lora.RadioEventTimeout {
+		return nil, nil
+	} else if msg.EventType != lora.RadioEventRxDone {
+		return nil, errUnexpectedRxRadioEvent
+	}
+
+	pLen, pStart := d.GetRxBufferStatus()
+	if d.packetType == SX1280_PACKET_TYPE_LORA && d.loraConf.HeaderType == lora.HeaderImplicit {
+		pLen = d.readRegister8(SX1280_REG_LORA_IMPLICIT_PAYLOAD_LEN)
+	}
+	return d.ReadBuffer(pStart, pLen), nil
+}
+
+// StartRSSI sets the radio in continuous receive mode on freq, to measure the
+// signal level with InstantRSSI.
+func (d *Device) StartRSSI(freq uint32) {
+	if d.controller != nil {
+		d.controller.SetRfSwitchMode(RFSWITCH_RX)
+	}
+	d.SetStandby()
+	d.ClearIrqStatus(SX1280_IRQ_ALL)
+	d.SetDioIrqParams(SX1280_IRQ_NONE, SX1280_IRQ_NONE, SX1280_IRQ_NONE, SX1280_IRQ_NONE)
+	conf := d.loraConf
+	d.loraConf.Freq = freq
+	d.setupLora(SX1280_PACKET_TYPE_LORA, 0xFF)
+	d.loraConf = conf
+	d.SetRx(SX1280_PERIOD_BASE_1_MS, SX1280_RX_TIMEOUT_INF)
+	// Let the RSSI settle.
+	time.Sleep(time.Millisecond)
+}
+
+// InstantRSSI returns the signal level in dBm
+func (d *Device) InstantRSSI() int16 {
+	r := d.ExecGetCommand(SX1280_CMD_GET_RSSI_INST, 1)
+	return -int16(r[0]) / 2
+}
+
+// StopRSSI ends the continuous receive mode of StartRSSI.
+func (d *Device) StopRSSI() {
+	d.SetStandby()
+}
+
+// HandleInterrupt must be called by main code on DIO state change.
+func (d *Device) HandleInterrupt() {
+	st := d.GetIrqStatus()
+	d.ClearIrqStatus(SX1280_IRQ_ALL)
+
+	eType, ok := eventType(st)
+	if !ok {
+		return
+	}
+	select {
+	case d.radioEventChan <- lora.RadioEvent{EventType: eType, IRQStatus: st}:
+	default:
+	}
+}
+
+// eventType returns the radio event of an IRQ status. The ranging results
+// and answers are reported as packets received and sent.
+func eventType(st uint16) (int, bool) {
+	switch {
+	case st&(SX1280_IRQ_CRC_ERR|SX1280_IRQ_HEADER_ERROR) != 0:
+		return lora.RadioEventCrcError, true
+	case st&(SX1280_IRQ_RX_DONE|SX1280_IRQ_RANGING_MASTER_RESULT_VALID) != 0:
+		return lora.RadioEventRxDone, true
+	case st&(SX1280_IRQ_TX_DONE|SX1280_IRQ_RANGING_SLAVE_RESPONSE_DONE) != 0:
+		return lora.RadioEventTxDone, true
+	case st&(SX1280_IRQ_TIMEOUT|SX1280_IRQ_RANGING_MASTER_TIMEOUT) != 0:
+		return lora.RadioEventTimeout, true
+	case st&SX1280_IRQ_RANGING_SLAVE_REQUEST_DISCARD != 0:
+		return lora.RadioEventUnhandled, true
+	}
+	return 0, false
+}
+
+func bandwidth(bw uint8) uint8 {
+	switch bw {
+	case lora.Bandwidth_203_125:
+		return SX1280_LORA_BW_200
+	case lora.Bandwidth_406_25:
+		return SX1280_LORA_BW_400
+	case lora.Bandwidth_812_5:
+		return SX1280_LORA_BW_800
+	case lora.Bandwidth_1625_0:
+		return SX1280_LORA_BW_1600
+	default:
+		return SX1280_LORA_BW_800
+	}
+}
+
+func syncword(sw int) uint16 {
+	if sw == lora.SyncPublic {
+		return SX1280_LORA_MAC_PUBLIC_SYNCWORD
+	}
+	return SX1280_LORA_MAC_PRIVATE_SYNCWORD
+}
@@ -0,0 +1,78 @@
+package sx1280
+
+import (
+	"testing"
+
+	"tinygo.org/x/drivers/lora"
+)
+
+var (
+	_ lora.Radio       = (*Device)(nil)
+	_ lora.RSSIMonitor = (*Device)(nil)
+)
+
+func TestFrequencySteps(t *testing.T) {
+	for _, tc := range []struct {
+		freq  uint32
+		steps uint32
+	}{
+		{2400000000, 12098953},
+		{2450000000, 12351015},
+		{52000000, 1 << 18},
+	} {
+		if got := frequencySteps(tc.freq); got != tc.steps {
+			t.Errorf("frequencySteps(%d) = %d, want %d", tc.freq, got, tc.steps)
+		}
+	}
+}
+
+func TestPreambleParam(t *testing.T) {
+	for _, tc := range []struct {
+		symbols uint16
+		param   uint8
+	}{
+		{0, 0x01},
+		{12, 0x0C},
+		{15, 0x0F},
+		{16, 0x18}, // 8 * 2^1
+		{17, 0x19}, // rounded up to 18
+		{64, 0x38}, // 8 * 2^3
+	} {
+		if got := preambleParam(tc.symbols); got != tc.param {
+			t.Errorf("preambleParam(%d) = %#x, want %#x", tc.symbols, got, tc.param)
+		}
+	}
+}
+
+func TestFLRCPacketParams(t *testing.T) {
+	c := FLRCConfig{PreambleBits: 32, Crc: 2}
+	if got, want := flrcPacketParams(&c, 200), [7]uint8{0x70, 0x00, 0x00, 0x20, 127, 0x10, 0x08}; got != want {
+		t.Errorf("packet params % x, want % x", got, want)
+	}
+	c = FLRCConfig{PreambleBits: 8, SyncWord: 0x12345678, Crc: 4}
+	if got, want := flrcPacketParams(&c, 10), [7]uint8{0x10, 0x04, 0x10, 0x20, 10, 0x30, 0x08}; got != want {
+		t.Errorf("packet params % x, want % x", got, want)
+	}
+}
+
+func TestEventType(t *testing.T) {
+	for _, tc := range []struct {
+		irq   uint16
+		event int
+		ok    bool
+	}{
+		{SX1280_IRQ_RX_DONE, lora.RadioEventRxDone, true},
+		{SX1280_IRQ_RX_DONE | SX1280_IRQ_CRC_ERR, lora.RadioEventCrcError, true},
+		{SX1280_IRQ_TX_DONE, lora.RadioEventTxDone, true},
+		{SX1280_IRQ_TIMEOUT, lora.RadioEventTimeout, true},
+		{SX1280_IRQ_RANGING_MASTER_RESULT_VALID, lora.RadioEventRxDone, true},
+		{SX1280_IRQ_RANGING_MASTER_TIMEOUT, lora.RadioEventTimeout, true},
+		{SX1280_IRQ_RANGING_SLAVE_RESPONSE_DONE, lora.RadioEventTxDone, true},
+		{SX1280_IRQ_RANGING_SLAVE_REQUEST_DISCARD, lora.RadioEventUnhandled, true},
+		{SX1280_IRQ_PREAMBLE_DETECTED, 0, false},
+	} {
+		if event, ok := eventType(tc.irq); event != tc.event || ok != tc.ok {
+			t.Errorf("eventType(%#04x) = %d, %v, want %d, %v", tc.irq, event, ok, tc.event, tc.ok)
+		}
+	}
+}
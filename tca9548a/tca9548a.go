@@ -0,0 +1,109 @@
+// Package tca9548a provides a driver for the TCA9548A and PCA9548A 8-channel
+// I2C multiplexers.
+//
+// Each channel is available as a virtual bus that implements drivers.I2C and
+// selects its channel before every transaction, so devices with the same
+// address can be attached to different channels and used with the stock
+// drivers:
+//
+//	mux := tca9548a.New(machine.I2C0)
+//	left := vl53l1x.New(mux.Channel(0))
+//	right := vl53l1x.New(mux.Channel(1))
+//
+// Datasheet: https://www.ti.com/lit/ds/symlink/tca9548a.pdf
+package tca9548a // import "tinygo.org/x/drivers/tca9548a"
+
+import (
+	"errors"
+
+	"tinygo.org/x/drivers"
+)
+
+// Address is the default I2C address, with A0..A2 low. The address range is
+// 0x70 to 0x77.
+const Address = 0x70
+
+var errChannel = errors.New("tca9548a: channel must be 0 to 7")
+
+// Device wraps an I2C connection to a TCA9548A device.
+type Device struct {
+	bus      drivers.I2C
+	Address  uint16
+	channels uint8 // enabled channels, as last written
+	known    bool  // whether channels matches the device
+	buf      [1]byte
+	virtual  [8]Bus
+}
+
+// New creates a new TCA9548A connection. The I2C bus must already be
+// configured.
+//
+// This function only creates the Device object, it does not touch the device.
+func New(bus drivers.I2C) *Device {
+	d := &Device{bus: bus, Address: Address}
+	for i := range d.virtual {
+		d.virtual[i] = Bus{mux: d, mask: 1 << i}
+	}
+	return d
+}
+
+// Connected returns whether the multiplexer answers on its address.
+func (d *Device) Connected() bool {
+	return d.bus.Tx(d.Address, nil, d.buf[:]) == nil
+}
+
+// SetChannels enables the channels set in mask, bit 0 being channel 0.
+// Several channels can be enabled at once, for example to broadcast to
+// devices with the same address.
+func (d *Device) SetChannels(mask uint8) error {
+	d.buf[0] = mask
+	if err := d.bus.Tx(d.Address, d.buf[:], nil); err != nil {
+		d.known = false
+		return err
+	}
+	d.channels = mask
+	d.known = true
+	return nil
+}
+
+// Channels reads the enabled channels from the device.
+func (d *Device) Channels() (uint8, error) {
+	if err := d.bus.Tx(d.Address, nil, d.buf[:]); err != nil {
+		return 0, err
+	}
+	d.channels = d.buf[0]
+	d.known = true
+	return d.channels, nil
+}
+
+// Disable disconnects all channels.
+func (d *Device) Disable() error {
+	return d.SetChannels(0)
+}
+
+// Channel returns a virtual bus for a channel. It panics if ch is not a
+// valid channel.
+func (d *Device) Channel(ch int) *Bus {
+	if ch < 0 || ch > 7 {
+		panic(errChannel)
+	}
+	return &d.virtual[ch]
+}
+
+// Bus is an I2C bus behind one channel of the multiplexer.
+type Bus struct {
+	mux  *Device
+	mask uint8
+}
+
+// Tx selects the channel, if it is not selected yet, and performs the
+// transaction on it.
+func (b *Bus) Tx(addr uint16, w, r []byte) error {
+	d := b.mux
+	if !d.known || d.channels != b.mask {
+		if err := d.SetChannels(b.mask); err != nil {
+			return err
+		}
+	}
+	return d.bus.Tx(addr, w, r)
+}
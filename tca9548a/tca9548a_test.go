@@ -0,0 +1,40 @@
+package tca9548a
+
+import (
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+)
+
+// recorder is an I2C bus that records the addresses of all transactions and
+// the channel writes to the multiplexer.
+type recorder struct {
+	log []uint16
+}
+
+func (r *recorder) Tx(addr uint16, w, _ []byte) error {
+	if addr == Address {
+		r.log = append(r.log, 0x100|uint16(w[0]))
+	} else {
+		r.log = append(r.log, addr)
+	}
+	return nil
+}
+
+func TestChannels(t *testing.T) {
+	c := qt.New(t)
+	bus := &recorder{}
+	mux := New(bus)
+
+	a := mux.Channel(0)
+	b := mux.Channel(3)
+	c.Assert(a.Tx(0x29, []byte{0}, nil), qt.IsNil)
+	c.Assert(a.Tx(0x29, []byte{0}, nil), qt.IsNil)
+	c.Assert(b.Tx(0x29, []byte{0}, nil), qt.IsNil)
+	c.Assert(a.Tx(0x29, []byte{0}, nil), qt.IsNil)
+
+	// The channel is only switched when needed.
+	c.Assert(bus.log, qt.DeepEquals, []uint16{0x101, 0x29, 0x29, 0x108, 0x29, 0x101, 0x29})
+
+	c.Assert(func() { mux.Channel(8) }, qt.PanicMatches, ".*channel.*")
+}
@@ -0,0 +1,13 @@
+//go:build tinygo
+
+package tcs34725
+
+import "machine"
+
+// SetLEDPin sets the pin driving the illumination LED found on most breakout
+// boards, and turns the LED off.
+func (d *Device) SetLEDPin(pin machine.Pin) {
+	pin.Configure(machine.PinConfig{Mode: machine.PinOutput})
+	pin.Low()
+	d.led = pin.Set
+}
@@ -0,0 +1,50 @@
+package tcs34725
+
+// The I2C address which this device listens to.
+const Address = 0x29
+
+// Registers. They must be or'ed with CMD when addressed.
+const (
+	CMD          = 0x80
+	CMD_AUTOINC  = 0x20
+	CMD_INTCLEAR = 0x66
+
+	ENABLE  = 0x00
+	ATIME   = 0x01
+	WTIME   = 0x03
+	AILTL   = 0x04
+	AIHTL   = 0x06
+	PERS    = 0x0C
+	CONFIG  = 0x0D
+	CONTROL = 0x0F
+	ID      = 0x12
+	STATUS  = 0x13
+	CDATAL  = 0x14
+	RDATAL  = 0x16
+	GDATAL  = 0x18
+	BDATAL  = 0x1A
+)
+
+// Bits in the ENABLE register.
+const (
+	ENABLE_PON  = 0x01 // power on
+	ENABLE_AEN  = 0x02 // RGBC enable
+	ENABLE_WEN  = 0x08 // wait enable
+	ENABLE_AIEN = 0x10 // RGBC interrupt enable
+)
+
+// Bits in the STATUS register.
+const (
+	STATUS_AVALID = 0x01
+	STATUS_AINT   = 0x10
+)
+
+// Gain of the RGBC channels.
+type Gain uint8
+
+const (
+	GAIN_1X  Gain = 0x00
+	GAIN_4X  Gain = 0x01
+	GAIN_16X Gain = 0x02
+	GAIN_60X Gain = 0x03
+)
@@ -0,0 +1,197 @@
+// Package tcs34725 implements a driver for the TCS34725 RGB color sensor.
+//
+// Datasheet: https://cdn-shop.adafruit.com/datasheets/TCS34725.pdf
+//
+// The lux and color temperature calculations follow the AMS design note DN40
+// "Lux and CCT Calculations using ams Color Sensors".
+package tcs34725 // import "tinygo.org/x/drivers/tcs34725"
+
+import (
+	"time"
+
+	"tinygo.org/x/drivers"
+)
+
+// Device wraps an I2C connection to a TCS34725 device.
+type Device struct {
+	bus     drivers.I2C
+	Address uint8
+
+	led func(on bool) // drives the illumination LED, see SetLEDPin
+
+	atime uint8
+	gain  Gain
+	buf   [8]byte
+}
+
+// Config contains the integration time and gain.
+type Config struct {
+	// Integration time in microseconds, between 2400 (2.4ms) and 614400
+	// (614ms), in steps of 2.4ms. Longer times give more resolution and
+	// sensitivity. The default is 153.6ms.
+	IntegrationTime uint32
+
+	Gain Gain
+}
+
+// New creates a new TCS34725 connection. The I2C bus must already be
+// configured.
+//
+// This function only creates the Device object, it does not touch the device.
+func New(bus drivers.I2C) Device {
+	return Device{bus: bus, Address: Address}
+}
+
+// Connected returns whether a TCS34725 or TCS34727 has been found.
+func (d *Device) Connected() bool {
+	id, err := d.read8(ID)
+	return err == nil && (id == 0x44 || id == 0x4D)
+}
+
+// Configure powers up the sensor and starts continuous RGBC conversions.
+func (d *Device) Configure(cfg Config) error {
+	if cfg.IntegrationTime == 0 {
+		cfg.IntegrationTime = 153600
+	}
+	if err := d.SetIntegrationTime(cfg.IntegrationTime); err != nil {
+		return err
+	}
+	if err := d.SetGain(cfg.Gain); err != nil {
+		return err
+	}
+	if err := d.write8(ENABLE, ENABLE_PON); err != nil {
+		return err
+	}
+	// The datasheet requires 2.4ms between power on and enabling the ADC.
+	time.Sleep(3 * time.Millisecond)
+	return d.write8(ENABLE, ENABLE_PON|ENABLE_AEN)
+}
+
+// SetIntegrationTime sets the integration time in microseconds. It is
+// rounded down to a multiple of 2.4ms, and clamped to 2.4ms-614.4ms.
+func (d *Device) SetIntegrationTime(us uint32) error {
+	cycles := us / 2400
+	if cycles < 1 {
+		cycles = 1
+	}
+	if cycles > 256 {
+		cycles = 256
+	}
+	d.atime = uint8(256 - cycles)
+	return d.write8(ATIME, d.atime)
+}
+
+// SetGain sets the analog gain of the RGBC channels.
+func (d *Device) SetGain(gain Gain) error {
+	d.gain = gain & 0x03
+	return d.write8(CONTROL, uint8(d.gain))
+}
+
+// SetLED turns the illumination LED on or off, if its pin was set with
+// SetLEDPin.
+func (d *Device) SetLED(on bool) {
+	if d.led != nil {
+		d.led(on)
+	}
+}
+
+// DataReady returns whether a new RGBC conversion is available.
+func (d *Device) DataReady() (bool, error) {
+	status, err := d.read8(STATUS)
+	return status&STATUS_AVALID != 0, err
+}
+
+// ReadRawColor returns the raw clear, red, green and blue channel counts of
+// the last conversion.
+func (d *Device) ReadRawColor() (c, r, g, b uint16, err error) {
+	d.buf[0] = CMD | CMD_AUTOINC | CDATAL
+	err = d.bus.Tx(uint16(d.Address), d.buf[:1], d.buf[:8])
+	if err != nil {
+		return
+	}
+	c = uint16(d.buf[0]) | uint16(d.buf[1])<<8
+	r = uint16(d.buf[2]) | uint16(d.buf[3])<<8
+	g = uint16(d.buf[4]) | uint16(d.buf[5])<<8
+	b = uint16(d.buf[6]) | uint16(d.buf[7])<<8
+	return
+}
+
+// ReadLux returns the illuminance in milli-lux and the correlated color
+// temperature in kelvin, calculated from a fresh reading of all channels.
+func (d *Device) ReadLux() (milliLux int32, cct uint32, err error) {
+	c, r, g, b, err := d.ReadRawColor()
+	if err != nil {
+		return
+	}
+	milliLux, cct = d.Lux(c, r, g, b)
+	return
+}
+
+// Lux calculates the illuminance in milli-lux and the correlated color
+// temperature in kelvin from raw channel counts, using the current
+// integration time and gain. The result is only valid when no channel is
+// saturated. A CCT of zero means it could not be calculated.
+func (d *Device) Lux(c, r, g, b uint16) (milliLux int32, cct uint32) {
+	// Remove the IR component, which all channels see equally.
+	ir := (int32(r) + int32(g) + int32(b) - int32(c)) / 2
+	if ir < 0 {
+		ir = 0
+	}
+	r2 := int32(r) - ir
+	g2 := int32(g) - ir
+	b2 := int32(b) - ir
+
+	// Coefficients from DN40 for an open aperture, scaled by 1000:
+	// G'' = 0.136*R' + 1.000*G' - 0.444*B'
+	g3 := 136*r2 + 1000*g2 - 444*b2
+
+	// Counts per lux: CPL = (ATIME_ms * AGAIN) / (GA * DF), with a glass
+	// attenuation GA of 1 and a device factor DF of 310.
+	atimeUs := int64(256-int32(d.atime)) * 2400
+	again := int64([4]int32{1, 4, 16, 60}[d.gain])
+	cplScaled := atimeUs * again // CPL * 310 * 1000
+	if cplScaled > 0 {
+		milliLux = int32(int64(g3) * 310 * 1000 / cplScaled)
+	}
+	if milliLux < 0 {
+		milliLux = 0
+	}
+
+	// CCT = 3810 * B'/R' + 1391
+	if r2 > 0 {
+		cct = uint32(3810*int64(b2)/int64(r2) + 1391)
+	}
+	return
+}
+
+// ConfigureInterrupt enables the RGBC interrupt, which is raised when the
+// clear channel leaves the window between low and high for persistence
+// consecutive conversions (0 means every conversion).
+func (d *Device) ConfigureInterrupt(low, high uint16, persistence uint8) error {
+	w := [...]byte{CMD | CMD_AUTOINC | AILTL, byte(low), byte(low >> 8), byte(high), byte(high >> 8)}
+	if err := d.bus.Tx(uint16(d.Address), w[:], nil); err != nil {
+		return err
+	}
+	if err := d.write8(PERS, persistence&0x0F); err != nil {
+		return err
+	}
+	return d.write8(ENABLE, ENABLE_PON|ENABLE_AEN|ENABLE_AIEN)
+}
+
+// ClearInterrupt clears a pending RGBC interrupt.
+func (d *Device) ClearInterrupt() error {
+	d.buf[0] = CMD | CMD_INTCLEAR
+	return d.bus.Tx(uint16(d.Address), d.buf[:1], nil)
+}
+
+func (d *Device) read8(reg uint8) (uint8, error) {
+	d.buf[0] = CMD | reg
+	err := d.bus.Tx(uint16(d.Address), d.buf[:1], d.buf[1:2])
+	return d.buf[1], err
+}
+
+func (d *Device) write8(reg, value uint8) error {
+	d.buf[0] = CMD | reg
+	d.buf[1] = value
+	return d.bus.Tx(uint16(d.Address), d.buf[:2], nil)
+}
@@ -0,0 +1,38 @@
+package tcs34725
+
+import (
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+	"tinygo.org/x/drivers/tester"
+)
+
+func TestReadLux(t *testing.T) {
+	c := qt.New(t)
+	bus := tester.NewI2CBus(c)
+	fake := bus.NewDevice(Address)
+	fake.Registers[CMD|ID] = 0x44
+
+	dev := New(bus)
+	c.Assert(dev.Connected(), qt.IsTrue)
+	c.Assert(dev.Configure(Config{}), qt.IsNil)
+	c.Assert(fake.Registers[CMD|ATIME], qt.Equals, uint8(256-64))
+	c.Assert(fake.Registers[CMD|ENABLE], qt.Equals, uint8(ENABLE_PON|ENABLE_AEN))
+
+	// Channels are read in one burst from CDATAL.
+	copy(fake.Registers[CMD|CMD_AUTOINC|CDATAL:], []byte{0xE8, 0x03, 0x90, 0x01, 0x90, 0x01, 0x2C, 0x01})
+	cl, r, g, b, err := dev.ReadRawColor()
+	c.Assert(err, qt.IsNil)
+	c.Assert([]uint16{cl, r, g, b}, qt.DeepEquals, []uint16{1000, 400, 400, 300})
+
+	// An IR component of 50 counts is removed from all channels.
+	lux, cct, err := dev.ReadLux()
+	c.Assert(err, qt.IsNil)
+	c.Assert(lux, qt.Equals, int32(578424))
+	c.Assert(cct, qt.Equals, uint32(4112))
+
+	// The lux scale with the integration time and the gain.
+	c.Assert(dev.SetGain(GAIN_4X), qt.IsNil)
+	lux, _ = dev.Lux(cl, r, g, b)
+	c.Assert(lux, qt.Equals, int32(578424/4))
+}
@@ -0,0 +1,35 @@
+package tea5767
+
+// The I2C address of the TEA5767.
+const Address = 0x60
+
+// Bits of the five bytes written to the TEA5767, by byte.
+const (
+	W1_MUTE = 0x80 // mute both channels
+	W1_SM   = 0x40 // search mode
+
+	W3_SUD  = 0x80 // search up
+	W3_SSL  = 0x60 // search stop level
+	W3_HLSI = 0x10 // high side injection
+	W3_MS   = 0x08 // mono
+	W3_ML   = 0x04 // mute left
+	W3_MR   = 0x02 // mute right
+
+	W4_STBY  = 0x40 // standby
+	W4_BL    = 0x20 // Japanese band limits
+	W4_XTAL  = 0x10 // 32.768kHz crystal
+	W4_SMUTE = 0x08 // soft mute
+	W4_HCC   = 0x04 // high cut control
+	W4_SNC   = 0x02 // stereo noise cancelling
+
+	W5_PLLREF = 0x80 // 6.5MHz reference frequency
+	W5_DTC    = 0x40 // 75µs de-emphasis
+)
+
+// Bits of the five bytes read from the TEA5767, by byte.
+const (
+	R1_RF  = 0x80 // ready: station found or band limit reached
+	R1_BLF = 0x40 // band limit reached
+
+	R3_STEREO = 0x80
+)
@@ -0,0 +1,253 @@
+// Package tea5767 implements a driver for the TEA5767 FM radio receiver,
+// with seek and tuning.
+//
+// The Device implements tuner.Tuner, like the Si4703 and RDA5807M drivers,
+// so a radio can use any of them. The TEA5767 has no volume control and no
+// RDS decoder: SetVolume only mutes at 0, and ReadRDS never receives
+// anything.
+//
+// Datasheet: https://www.sparkfun.com/datasheets/Wireless/General/TEA5767.pdf
+package tea5767 // import "tinygo.org/x/drivers/tea5767"
+
+import (
+	"errors"
+	"time"
+
+	"tinygo.org/x/drivers"
+	"tinygo.org/x/drivers/tuner"
+)
+
+var (
+	errTimeout = errors.New("tea5767: seek timeout")
+	errRange   = errors.New("tea5767: frequency out of band")
+
+	// ErrSeekFailed is returned by Seek when no station was found before
+	// the end of the band.
+	ErrSeekFailed = tuner.ErrSeekFailed
+)
+
+// Band is the FM band of the region.
+type Band uint8
+
+const (
+	BandWorld Band = iota // 87.5-108MHz, United States and Europe
+	BandJapan             // 76-91MHz
+)
+
+// SeekLevel is the signal level at which Seek stops on a station.
+type SeekLevel uint8
+
+const (
+	SeekLevelMid  SeekLevel = iota // ADC level 7
+	SeekLevelLow                   // ADC level 5, finds weaker stations
+	SeekLevelHigh                  // ADC level 10, only finds strong stations
+)
+
+// Config is the configuration of the receiver.
+type Config struct {
+	Band Band
+
+	// DeEmphasis50us selects the de-emphasis of Europe, Australia and
+	// Japan; it is 75µs otherwise, as in the United States.
+	DeEmphasis50us bool
+
+	// SeekLevel is the signal level at which Seek stops.
+	SeekLevel SeekLevel
+}
+
+// Device wraps an I2C connection to a TEA5767 device.
+type Device struct {
+	bus     drivers.I2C
+	Address uint16
+	w       [5]byte // bytes written, the TEA5767 has no registers
+	r       [5]byte // bytes read
+	bottom  uint32  // kHz
+	top     uint32  // kHz
+	freq    uint32  // kHz
+	muted   bool
+	silent  bool // volume 0
+	rds     tuner.RDS
+}
+
+// New creates a new TEA5767 connection. The I2C bus must already be
+// configured.
+//
+// This function only creates the Device object, it does not touch the device.
+func New(bus drivers.I2C) *Device {
+	return &Device{
+		bus:     bus,
+		Address: Address,
+	}
+}
+
+// Configure wakes the receiver up with the given configuration. The
+// TEA5767 is tuned at the bottom of the band until Tune or Seek is called.
+func (d *Device) Configure(cfg Config) error {
+	d.bottom, d.top = 87500, 108000
+	d.w = [5]byte{}
+	d.w[3] = W4_XTAL | W4_SMUTE | W4_HCC | W4_SNC
+	if cfg.Band == BandJapan {
+		d.bottom, d.top = 76000, 91000
+		d.w[3] |= W4_BL
+	}
+	d.w[2] = W3_HLSI | ([3]byte{2, 1, 3}[cfg.SeekLevel%3])<<5
+	if !cfg.DeEmphasis50us {
+		d.w[4] = W5_DTC
+	}
+	d.setPLL(d.bottom)
+	d.freq = d.bottom
+	if err := d.write(); err != nil {
+		return err
+	}
+	return d.read()
+}
+
+// Tune tunes to a frequency in kHz, for instance 101100 for 101.1MHz.
+func (d *Device) Tune(khz uint32) error {
+	if khz < d.bottom || khz > d.top {
+		return errRange
+	}
+	d.w[0] &^= W1_SM
+	d.setPLL(khz)
+	if err := d.write(); err != nil {
+		return err
+	}
+	d.freq = khz
+	d.rds.Reset()
+	return nil
+}
+
+// Seek tunes to the next station up or down the band, whose signal is at
+// least at the seek level of the configuration, and returns its frequency
+// in kHz. It stops at the end of the band, with ErrSeekFailed.
+func (d *Device) Seek(up bool) (uint32, error) {
+	// Start next to the current station, so as not to find it again.
+	start := d.freq - 100
+	d.w[2] &^= W3_SUD
+	if up {
+		start = d.freq + 100
+		d.w[2] |= W3_SUD
+	}
+	d.w[0] |= W1_SM
+	d.setPLL(start)
+	if err := d.write(); err != nil {
+		return 0, err
+	}
+	deadline := time.Now().Add(10 * time.Second)
+	for {
+		if err := d.read(); err != nil {
+			return 0, err
+		}
+		if d.r[0]&R1_RF != 0 {
+			break
+		}
+		if time.Now().After(deadline) {
+			return 0, errTimeout
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	// Stay on the station found.
+	pll := uint16(d.r[0]&0x3F)<<8 | uint16(d.r[1])
+	d.freq = frequency(pll)
+	d.w[0] = d.w[0]&^(W1_SM|0x3F) | byte(pll>>8)
+	d.w[1] = byte(pll)
+	if err := d.write(); err != nil {
+		return 0, err
+	}
+	d.rds.Reset()
+	if d.r[0]&R1_BLF != 0 {
+		return d.freq, ErrSeekFailed
+	}
+	return d.freq, nil
+}
+
+// setPLL sets the PLL word for a frequency in kHz, with high side
+// injection: N = 4 * (f + 225kHz) / 32.768kHz.
+func (d *Device) setPLL(khz uint32) {
+	n := ((khz+225)*4000 + 16384) / 32768
+	d.w[0] = d.w[0]&^0x3F | byte(n>>8)&0x3F
+	d.w[1] = byte(n)
+}
+
+// frequency returns the frequency of a PLL word in kHz, rounded to 50kHz
+// as all FM channels are.
+func frequency(pll uint16) uint32 {
+	khz := uint32(pll)*32768/4000 - 225
+	return (khz + 25) / 50 * 50
+}
+
+// Frequency returns the frequency tuned in kHz, as of the last tune or
+// seek.
+func (d *Device) Frequency() uint32 {
+	return d.freq
+}
+
+// RSSI returns the level of the signal given by the ADC, from 0 to 15.
+func (d *Device) RSSI() (uint8, error) {
+	err := d.read()
+	return d.r[3] >> 4, err
+}
+
+// Stereo returns whether the station is received in stereo.
+func (d *Device) Stereo() (bool, error) {
+	err := d.read()
+	return d.r[2]&R3_STEREO != 0, err
+}
+
+// SetVolume mutes the audio outputs at 0. The TEA5767 has no volume
+// control, the other values unmute them.
+func (d *Device) SetVolume(volume uint8) error {
+	d.silent = volume == 0
+	return d.setMute()
+}
+
+// Mute mutes or unmutes the audio outputs.
+func (d *Device) Mute(mute bool) error {
+	d.muted = mute
+	return d.setMute()
+}
+
+func (d *Device) setMute() error {
+	d.w[0] &^= W1_MUTE
+	if d.muted || d.silent {
+		d.w[0] |= W1_MUTE
+	}
+	return d.write()
+}
+
+// SetMono forces mono audio, which is less noisy for weak stations.
+func (d *Device) SetMono(mono bool) error {
+	d.w[2] &^= W3_MS
+	if mono {
+		d.w[2] |= W3_MS
+	}
+	return d.write()
+}
+
+// Sleep puts the receiver in standby, or wakes it up.
+func (d *Device) Sleep(sleep bool) error {
+	d.w[3] &^= W4_STBY
+	if sleep {
+		d.w[3] |= W4_STBY
+	}
+	return d.write()
+}
+
+// ReadRDS always returns false: the TEA5767 has no RDS decoder.
+func (d *Device) ReadRDS() (bool, error) {
+	return false, nil
+}
+
+// RDS returns empty RDS data, as the TEA5767 has no RDS decoder.
+func (d *Device) RDS() *tuner.RDS {
+	return &d.rds
+}
+
+func (d *Device) write() error {
+	return d.bus.Tx(d.Address, d.w[:], nil)
+}
+
+func (d *Device) read() error {
+	return d.bus.Tx(d.Address, nil, d.r[:])
+}
@@ -0,0 +1,99 @@
+package tea5767
+
+import (
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+	"tinygo.org/x/drivers/tuner"
+)
+
+var _ tuner.Tuner = (*Device)(nil)
+
+// fakeRadio is a TEA5767 on an I2C bus, with a station every 1MHz from
+// 88.1MHz.
+type fakeRadio struct {
+	w [5]byte
+	r [5]byte
+}
+
+func (r *fakeRadio) ReadRegister(addr uint8, reg uint8, buf []byte) error  { panic("no registers") }
+func (r *fakeRadio) WriteRegister(addr uint8, reg uint8, buf []byte) error { panic("no registers") }
+
+func (r *fakeRadio) Tx(addr uint16, w, rd []byte) error {
+	if len(w) == 5 {
+		copy(r.w[:], w)
+		r.update()
+	}
+	copy(rd, r.r[:])
+	return nil
+}
+
+// update runs the searches, at 100kHz steps.
+func (r *fakeRadio) update() {
+	pll := uint16(r.w[0]&0x3F)<<8 | uint16(r.w[1])
+	r.r[0] = byte(pll >> 8)
+	r.r[1] = byte(pll)
+	r.r[2] = R3_STEREO
+	r.r[3] = 9 << 4
+	if r.w[0]&W1_SM == 0 {
+		return
+	}
+	khz := frequency(pll)
+	step := int32(-100)
+	if r.w[2]&W3_SUD != 0 {
+		step = 100
+	}
+	for ; khz >= 87500 && khz <= 108000; khz = uint32(int32(khz) + step) {
+		if (khz-88100)%1000 == 0 {
+			n := ((khz+225)*4000 + 16384) / 32768
+			r.r[0] = R1_RF | byte(n>>8)
+			r.r[1] = byte(n)
+			return
+		}
+	}
+	r.r[0] |= R1_RF | R1_BLF
+}
+
+func TestPLL(t *testing.T) {
+	c := qt.New(t)
+	d := New(&fakeRadio{})
+	for _, khz := range []uint32{76000, 87500, 88100, 101100, 107950, 108000} {
+		d.setPLL(khz)
+		c.Assert(frequency(uint16(d.w[0]&0x3F)<<8|uint16(d.w[1])), qt.Equals, khz)
+	}
+}
+
+func TestTune(t *testing.T) {
+	c := qt.New(t)
+	radio := &fakeRadio{}
+	d := New(radio)
+	c.Assert(d.Configure(Config{DeEmphasis50us: true, SeekLevel: SeekLevelHigh}), qt.IsNil)
+	c.Assert(radio.w[2]&W3_SSL, qt.Equals, byte(0x60))
+	c.Assert(radio.w[4]&W5_DTC, qt.Equals, byte(0))
+
+	c.Assert(d.Tune(101100), qt.IsNil)
+	c.Assert(d.Frequency(), qt.Equals, uint32(101100))
+	c.Assert(d.Tune(120000), qt.Equals, errRange)
+
+	freq, err := d.Seek(true)
+	c.Assert(err, qt.IsNil)
+	c.Assert(freq, qt.Equals, uint32(102100))
+	c.Assert(radio.w[0]&W1_SM, qt.Equals, byte(0))
+	freq, err = d.Seek(false)
+	c.Assert(err, qt.IsNil)
+	c.Assert(freq, qt.Equals, uint32(101100))
+	d.Tune(107900)
+	_, err = d.Seek(true)
+	c.Assert(err, qt.Equals, ErrSeekFailed)
+
+	level, err := d.RSSI()
+	c.Assert(err, qt.IsNil)
+	c.Assert(level, qt.Equals, uint8(9))
+	stereo, _ := d.Stereo()
+	c.Assert(stereo, qt.IsTrue)
+
+	c.Assert(d.SetVolume(0), qt.IsNil)
+	c.Assert(radio.w[0]&W1_MUTE, qt.Equals, byte(W1_MUTE))
+	c.Assert(d.SetVolume(8), qt.IsNil)
+	c.Assert(radio.w[0]&W1_MUTE, qt.Equals, byte(0))
+}
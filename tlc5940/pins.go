@@ -0,0 +1,32 @@
+//go:build tinygo
+
+package tlc5940
+
+import (
+	"machine"
+
+	"tinygo.org/x/drivers"
+)
+
+// New returns a chain of TLC5940 chips on an SPI bus in mode 0, up to 30MHz,
+// whose SDO and SCK are connected to SIN and SCLK. The BLANK pin starts high,
+// keeping the outputs off until the first Cycle. Pass machine.NoPin for vprg
+// when VPRG is tied low, without dot correction.
+//
+// This function only creates the Device object and sets up the pins, it
+// does not touch the device.
+func New(bus drivers.SPI, xlat, blank, vprg machine.Pin, chips int) *Device {
+	for _, p := range []machine.Pin{xlat, blank, vprg} {
+		if p != machine.NoPin {
+			p.Configure(machine.PinConfig{Mode: machine.PinOutput})
+		}
+	}
+	xlat.Low()
+	blank.High()
+	var v pin
+	if vprg != machine.NoPin {
+		vprg.Low()
+		v = vprg
+	}
+	return newDevice(bus, xlat, blank, v, chips)
+}
@@ -0,0 +1,198 @@
+// Package tlc5940 implements a driver for the TLC5940 16 channel LED driver,
+// with 12-bit grayscale PWM and 6-bit dot correction of the current of each
+// channel. Several chips can be daisy-chained, SOUT to SIN, to drive many
+// LEDs from the same pins.
+//
+// The chips need a grayscale clock on GSCLK, such as a PWM output with a 50%
+// duty cycle: each PWM cycle of the outputs lasts 4096 of its periods, after
+// which the outputs stay off until Cycle is called.
+//
+// Datasheet: https://www.ti.com/lit/ds/symlink/tlc5940.pdf
+package tlc5940 // import "tinygo.org/x/drivers/tlc5940"
+
+import (
+	"errors"
+
+	"tinygo.org/x/drivers"
+)
+
+const (
+	// Channels is the number of channels of a chip.
+	Channels = 16
+
+	// MaxValue is the largest grayscale value, which keeps a channel on
+	// for the whole PWM cycle but one GSCLK period.
+	MaxValue = 4095
+
+	// MaxDotCorrection is the largest dot correction value, for the full
+	// current set by the IREF resistor.
+	MaxDotCorrection = 63
+)
+
+var errNoVPRG = errors.New("tlc5940: dot correction needs the VPRG pin")
+
+type pin interface {
+	High()
+	Low()
+}
+
+// Device is a chain of TLC5940 chips. The channels of the chip connected to
+// the microcontroller come first, then those of the next chip in the chain.
+type Device struct {
+	bus               drivers.SPI
+	xlat, blank, vprg pin
+
+	gs []byte // grayscale data, as shifted in
+	dc []byte // dot correction data, as shifted in
+
+	latch      bool // grayscale data shifted in, to latch at the next Cycle
+	extraClock bool // the next grayscale latch follows a dot correction
+}
+
+func newDevice(bus drivers.SPI, xlat, blank, vprg pin, chips int) *Device {
+	d := &Device{
+		bus:   bus,
+		xlat:  xlat,
+		blank: blank,
+		vprg:  vprg,
+		gs:    make([]byte, chips*Channels*12/8),
+		dc:    make([]byte, chips*Channels*6/8),
+	}
+	d.SetAllDotCorrection(MaxDotCorrection)
+	return d
+}
+
+// Len returns the number of channels of the chain.
+func (d *Device) Len() int {
+	return len(d.gs) * 8 / 12
+}
+
+// Set sets the grayscale value of channel ch, from 0 (off) to MaxValue. It
+// is sent to the chips by Update.
+func (d *Device) Set(ch int, value uint16) {
+	if value > MaxValue {
+		value = MaxValue
+	}
+	put12(d.gs, d.Len()-1-ch, value)
+}
+
+// Get returns the grayscale value of channel ch.
+func (d *Device) Get(ch int) uint16 {
+	return get12(d.gs, d.Len()-1-ch)
+}
+
+// SetAll sets the grayscale value of all the channels.
+func (d *Device) SetAll(value uint16) {
+	for ch := 0; ch < d.Len(); ch++ {
+		d.Set(ch, value)
+	}
+}
+
+// SetDotCorrection sets the dot correction of channel ch, from 0 to
+// MaxDotCorrection, which scales its current to value/63 of the current set
+// by the IREF resistor. It evens out the brightness of LEDs, or of the
+// colors of an RGB LED. It is sent to the chips by WriteDotCorrection, all
+// channels start at MaxDotCorrection.
+func (d *Device) SetDotCorrection(ch int, value uint8) {
+	if value > MaxDotCorrection {
+		value = MaxDotCorrection
+	}
+	put6(d.dc, d.Len()-1-ch, value)
+}
+
+// SetAllDotCorrection sets the dot correction of all the channels.
+func (d *Device) SetAllDotCorrection(value uint8) {
+	for ch := 0; ch < d.Len(); ch++ {
+		d.SetDotCorrection(ch, value)
+	}
+}
+
+// Update shifts the grayscale values into the chips. The outputs change at
+// the next Cycle, between two PWM cycles, so that they don't glitch.
+func (d *Device) Update() error {
+	if err := d.bus.Tx(d.gs, nil); err != nil {
+		return err
+	}
+	d.latch = true
+	return nil
+}
+
+// WriteDotCorrection writes the dot correction values to the chips, which
+// takes effect immediately. It needs the VPRG pin, and the DCPRG pin of the
+// chips tied high. The grayscale values are shifted in again afterwards, as
+// by Update.
+func (d *Device) WriteDotCorrection() error {
+	if d.vprg == nil {
+		return errNoVPRG
+	}
+	d.vprg.High()
+	err := d.bus.Tx(d.dc, nil)
+	if err == nil {
+		d.xlat.High()
+		d.xlat.Low()
+	}
+	d.vprg.Low()
+	if err != nil {
+		return err
+	}
+	d.extraClock = true
+	return d.Update()
+}
+
+// Cycle starts a new PWM cycle of the outputs, and latches the grayscale
+// values shifted in by Update since the last one. Call it every 4096 GSCLK
+// periods, for instance from a timer: when late, the outputs stay off in
+// the meantime.
+func (d *Device) Cycle() {
+	d.blank.High()
+	if d.latch {
+		d.xlat.High()
+		d.xlat.Low()
+		d.latch = false
+		if d.extraClock {
+			// After a dot correction, the first grayscale latch needs an
+			// additional SCLK pulse.
+			d.bus.Transfer(0)
+			d.extraClock = false
+		}
+	}
+	d.blank.Low()
+}
+
+// put12 stores the 12-bit value v as the i-th value of buf, MSB first.
+func put12(buf []byte, i int, v uint16) {
+	n := i * 3 / 2
+	if i%2 == 0 {
+		buf[n] = byte(v >> 4)
+		buf[n+1] = buf[n+1]&0x0F | byte(v<<4)
+	} else {
+		buf[n] = buf[n]&0xF0 | byte(v>>8)
+		buf[n+1] = byte(v)
+	}
+}
+
+// get12 returns the i-th 12-bit value of buf.
+func get12(buf []byte, i int) uint16 {
+	n := i * 3 / 2
+	if i%2 == 0 {
+		return uint16(buf[n])<<4 | uint16(buf[n+1])>>4
+	}
+	return uint16(buf[n]&0x0F)<<8 | uint16(buf[n+1])
+}
+
+// put6 stores the 6-bit value v as the i-th value of buf, MSB first.
+func put6(buf []byte, i int, v uint8) {
+	n := i * 6 / 8
+	switch i % 4 {
+	case 0:
+		buf[n] = buf[n]&0x03 | v<<2
+	case 1:
+		buf[n] = buf[n]&0xFC | v>>4
+		buf[n+1] = buf[n+1]&0x0F | v<<4
+	case 2:
+		buf[n] = buf[n]&0xF0 | v>>2
+		buf[n+1] = buf[n+1]&0x3F | v<<6
+	case 3:
+		buf[n] = buf[n]&0xC0 | v
+	}
+}
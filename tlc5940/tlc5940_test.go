@@ -0,0 +1,122 @@
+package tlc5940
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+// fakeBus records the bytes sent, and the events of the pins.
+type fakeBus struct {
+	log []string
+	tx  [][]byte
+}
+
+func (b *fakeBus) Tx(w, r []byte) error {
+	b.tx = append(b.tx, append([]byte(nil), w...))
+	b.log = append(b.log, "tx")
+	return nil
+}
+
+func (b *fakeBus) Transfer(w byte) (byte, error) {
+	b.log = append(b.log, "clk")
+	return 0, nil
+}
+
+type fakePin struct {
+	name string
+	bus  *fakeBus
+}
+
+func (p fakePin) High() { p.bus.log = append(p.bus.log, p.name+"+") }
+func (p fakePin) Low()  { p.bus.log = append(p.bus.log, p.name+"-") }
+
+func newTestDevice(chips int) (*Device, *fakeBus) {
+	b := &fakeBus{}
+	d := newDevice(b, fakePin{"xlat", b}, fakePin{"blank", b}, fakePin{"vprg", b}, chips)
+	return d, b
+}
+
+func TestPack(t *testing.T) {
+	buf := make([]byte, 6)
+	for i, v := range []uint16{0xABC, 0x123, 0xFFF, 0x000} {
+		put12(buf, i, v)
+	}
+	if want := []byte{0xAB, 0xC1, 0x23, 0xFF, 0xF0, 0x00}; !bytes.Equal(buf, want) {
+		t.Errorf("put12: % X, want % X", buf, want)
+	}
+	put12(buf, 1, 0x456)
+	if get12(buf, 0) != 0xABC || get12(buf, 1) != 0x456 || get12(buf, 2) != 0xFFF {
+		t.Errorf("get12: % X", buf)
+	}
+
+	buf = buf[:3]
+	for i := range buf {
+		buf[i] = 0
+	}
+	for i, v := range []uint8{0x3F, 0x00, 0x2A, 0x15} {
+		put6(buf, i, v)
+	}
+	// 111111 000000 101010 010101
+	if want := []byte{0xFC, 0x0A, 0x95}; !bytes.Equal(buf, want) {
+		t.Errorf("put6: % X, want % X", buf, want)
+	}
+}
+
+func TestChain(t *testing.T) {
+	d, b := newTestDevice(2)
+	if d.Len() != 32 {
+		t.Fatalf("Len: %d", d.Len())
+	}
+	d.Set(0, 0x123)
+	d.Set(31, 0xFED)
+	d.Set(16, 5000)
+	if d.Get(16) != MaxValue {
+		t.Errorf("Get: %d", d.Get(16))
+	}
+	if err := d.Update(); err != nil {
+		t.Fatal(err)
+	}
+	gs := b.tx[0]
+	if len(gs) != 48 {
+		t.Fatalf("sent %d bytes", len(gs))
+	}
+	// Channel 15 of the last chip goes first, channel 0 of the first chip
+	// last.
+	if gs[0] != 0xFE || gs[1] != 0xD0 || gs[22] != 0x0F || gs[23] != 0xFF || gs[46] != 0x01 || gs[47] != 0x23 {
+		t.Errorf("grayscale data % X", gs)
+	}
+}
+
+func TestLatch(t *testing.T) {
+	d, b := newTestDevice(1)
+	d.Cycle()
+	d.Update()
+	d.Cycle()
+	d.Cycle()
+	want := "blank+ blank- tx blank+ xlat+ xlat- blank- blank+ blank-"
+	if got := strings.Join(b.log, " "); got != want {
+		t.Errorf("got %s, want %s", got, want)
+	}
+
+	b.log, b.tx = nil, nil
+	d.SetDotCorrection(0, 0x2A)
+	if err := d.WriteDotCorrection(); err != nil {
+		t.Fatal(err)
+	}
+	d.Cycle()
+	d.Update()
+	d.Cycle()
+	want = "vprg+ tx xlat+ xlat- vprg- tx blank+ xlat+ xlat- clk blank- tx blank+ xlat+ xlat- blank-"
+	if got := strings.Join(b.log, " "); got != want {
+		t.Errorf("got %s, want %s", got, want)
+	}
+	if dc := b.tx[0]; len(dc) != 12 || dc[0] != 0xFF || dc[10] != 0xFF || dc[11] != 0xEA {
+		t.Errorf("dot correction data % X", dc)
+	}
+
+	d = newDevice(b, fakePin{"xlat", b}, fakePin{"blank", b}, nil, 1)
+	if err := d.WriteDotCorrection(); err != errNoVPRG {
+		t.Errorf("without VPRG: %v", err)
+	}
+}
@@ -0,0 +1,143 @@
+// Package tlc59711 implements a driver for the TLC59711 12 channel LED
+// driver, with 16-bit grayscale PWM and a 7-bit brightness control of each
+// of its three color groups. Several chips can be daisy-chained, SDTO to
+// SDTI and SCKO to SCKI.
+//
+// The chips have no chip select: they take every clock of the bus, which
+// must not be shared with other devices. They latch the data sent once the
+// clock stays low for 8 of its periods, so the outputs all change at once
+// when Update returns.
+//
+// Datasheet: https://www.ti.com/lit/ds/symlink/tlc59711.pdf
+package tlc59711 // import "tinygo.org/x/drivers/tlc59711"
+
+import (
+	"encoding/binary"
+
+	"tinygo.org/x/drivers"
+)
+
+const (
+	// Channels is the number of channels of a chip: 4 RGB LEDs.
+	Channels = 12
+
+	// MaxBrightness is the largest brightness control value, for the full
+	// current set by the IREF resistor.
+	MaxBrightness = 127
+)
+
+const (
+	frameLen = 28 // 224 bits
+
+	cmdWrite = 0x25
+
+	// Function control bits.
+	fcOUTTMG = 1 << 4 // outputs change on the rising edges of the clock
+	fcEXTGCK = 1 << 3 // PWM clocked by SCKI instead of the oscillator
+	fcTMGRST = 1 << 2 // restart the PWM cycle when the data is latched
+	fcDSPRPT = 1 << 1 // repeat the PWM cycle
+	fcBLANK  = 1 << 0 // outputs off
+)
+
+// Device is a chain of TLC59711 chips. The channels of the chip connected to
+// the microcontroller come first, then those of the next chip in the chain.
+// The channels of a chip are R0, G0, B0, R1, G1, B1 and so on, as named in
+// the datasheet.
+type Device struct {
+	bus drivers.SPI
+	buf []byte // frames of the chips, as sent
+}
+
+// New returns a chain of TLC59711 chips on an SPI bus in mode 0, up to
+// 10MHz. The brightness control starts at MaxBrightness.
+//
+// This function only creates the Device object, it does not touch the
+// device.
+func New(bus drivers.SPI, chips int) *Device {
+	d := &Device{
+		bus: bus,
+		buf: make([]byte, chips*frameLen),
+	}
+	d.SetBrightness(MaxBrightness, MaxBrightness, MaxBrightness)
+	return d
+}
+
+// Len returns the number of channels of the chain.
+func (d *Device) Len() int {
+	return len(d.buf) / frameLen * Channels
+}
+
+// Set sets the grayscale value of channel ch, from 0 (off) to 65535. It is
+// sent to the chips by Update.
+func (d *Device) Set(ch int, value uint16) {
+	binary.BigEndian.PutUint16(d.buf[d.offset(ch):], value)
+}
+
+// Get returns the grayscale value of channel ch.
+func (d *Device) Get(ch int) uint16 {
+	return binary.BigEndian.Uint16(d.buf[d.offset(ch):])
+}
+
+// SetAll sets the grayscale value of all the channels.
+func (d *Device) SetAll(value uint16) {
+	for ch := 0; ch < d.Len(); ch++ {
+		d.Set(ch, value)
+	}
+}
+
+// SetRGB sets the grayscale values of the red, green and blue channels of
+// RGB LED n, of the channels 3*n to 3*n+2.
+func (d *Device) SetRGB(n int, r, g, b uint16) {
+	d.Set(3*n, r)
+	d.Set(3*n+1, g)
+	d.Set(3*n+2, b)
+}
+
+// SetBrightness sets the brightness control of the red, green and blue
+// channels of all the chips, from 0 to MaxBrightness, which scales their
+// current to value/127 of the current set by the IREF resistor. It evens
+// out the brightness of the colors of the LEDs, and is sent to the chips by
+// Update.
+func (d *Device) SetBrightness(r, g, b uint8) {
+	for chip := 0; chip < len(d.buf)/frameLen; chip++ {
+		d.SetChipBrightness(chip, r, g, b)
+	}
+}
+
+// SetChipBrightness sets the brightness control of a chip of the chain, for
+// LEDs that differ from one chip to the other. See SetBrightness.
+func (d *Device) SetChipBrightness(chip int, r, g, b uint8) {
+	if r > MaxBrightness {
+		r = MaxBrightness
+	}
+	if g > MaxBrightness {
+		g = MaxBrightness
+	}
+	if b > MaxBrightness {
+		b = MaxBrightness
+	}
+	// The write command, the function control bits, and the brightness
+	// control of blue, green and red.
+	header := uint32(cmdWrite)<<26 | uint32(fcOUTTMG|fcTMGRST|fcDSPRPT)<<21 |
+		uint32(b)<<14 | uint32(g)<<7 | uint32(r)
+	binary.BigEndian.PutUint32(d.buf[d.frame(chip):], header)
+}
+
+// Update sends the grayscale values and the brightness control to the
+// chips. The PWM cycle of the outputs restarts with the new values, so they
+// don't glitch.
+func (d *Device) Update() error {
+	return d.bus.Tx(d.buf, nil)
+}
+
+// frame returns the offset in the buffer of the frame of a chip. The frame
+// of the last chip in the chain is sent first.
+func (d *Device) frame(chip int) int {
+	return len(d.buf) - (chip+1)*frameLen
+}
+
+// offset returns the offset in the buffer of the grayscale value of channel
+// ch. The grayscale values of a chip are sent from B3 to R0.
+func (d *Device) offset(ch int) int {
+	return d.frame(ch/Channels) + 4 + 2*(Channels-1-ch%Channels)
+}
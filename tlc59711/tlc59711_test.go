@@ -0,0 +1,59 @@
+package tlc59711
+
+import (
+	"bytes"
+	"testing"
+)
+
+type fakeBus struct {
+	tx [][]byte
+}
+
+func (b *fakeBus) Tx(w, r []byte) error {
+	b.tx = append(b.tx, append([]byte(nil), w...))
+	return nil
+}
+
+func (b *fakeBus) Transfer(w byte) (byte, error) {
+	b.tx = append(b.tx, []byte{w})
+	return 0, nil
+}
+
+func TestFrames(t *testing.T) {
+	b := &fakeBus{}
+	d := New(b, 2)
+	if d.Len() != 24 {
+		t.Fatalf("Len: %d", d.Len())
+	}
+	d.Set(0, 0x1234)
+	d.SetRGB(7, 0xAAAA, 0xBBBB, 0xCCCC)
+	d.SetChipBrightness(1, 1, 2, 200)
+	if d.Get(22) != 0xBBBB {
+		t.Errorf("Get: %04X", d.Get(22))
+	}
+	if err := d.Update(); err != nil {
+		t.Fatal(err)
+	}
+	if len(b.tx) != 1 || len(b.tx[0]) != 56 {
+		t.Fatalf("sent %d transfers", len(b.tx))
+	}
+	buf := b.tx[0]
+
+	// The frame of the second chip goes first: write command 100101, the
+	// function control bits 10110, and the brightness of blue (127), green
+	// (2) and red (1).
+	if want := []byte{0x96, 0xDF, 0xC1, 0x01}; !bytes.Equal(buf[:4], want) {
+		t.Errorf("header % X, want % X", buf[:4], want)
+	}
+	// B3, G3 and R3 of the second chip.
+	if want := []byte{0xCC, 0xCC, 0xBB, 0xBB, 0xAA, 0xAA}; !bytes.Equal(buf[4:10], want) {
+		t.Errorf("second chip % X, want % X", buf[4:10], want)
+	}
+	if want := []byte{0x96, 0xDF, 0xFF, 0xFF}; !bytes.Equal(buf[28:32], want) {
+		t.Errorf("header % X, want % X", buf[28:32], want)
+	}
+	// R0 of the first chip goes last.
+	if buf[54] != 0x12 || buf[55] != 0x34 {
+		t.Errorf("first chip % X", buf[28:])
+	}
+}
@@ -0,0 +1,167 @@
+// Package tof10120 provides a driver for the TOF10120 laser distance sensor
+// over its serial interface.
+//
+// By default the sensor sends the distance as ASCII text, such as "123mm",
+// at a fixed interval (active mode). In passive mode it only sends a
+// distance when asked to with ReadDistance.
+package tof10120 // import "tinygo.org/x/drivers/tof10120"
+
+import (
+	"errors"
+	"strconv"
+	"time"
+
+	"tinygo.org/x/drivers"
+)
+
+var errTimeout = errors.New("tof10120: timeout")
+
+// Decoder parses the distances sent by the sensor. It skips anything that is
+// not a number followed by "mm", such as the "ok" responses to commands.
+type Decoder struct {
+	value  int32
+	digits uint8
+	unit   uint8 // number of 'm' seen after the digits
+}
+
+// Feed adds a received byte. It returns the distance in mm and true when a
+// complete distance has been received.
+func (p *Decoder) Feed(b byte) (int32, bool) {
+	switch {
+	case b >= '0' && b <= '9':
+		if p.unit != 0 || p.digits == 5 {
+			p.reset()
+		}
+		p.value = p.value*10 + int32(b-'0')
+		p.digits++
+	case b == 'm' && p.digits > 0:
+		p.unit++
+		if p.unit == 2 {
+			v := p.value
+			p.reset()
+			return v, true
+		}
+	default:
+		p.reset()
+	}
+	return 0, false
+}
+
+func (p *Decoder) reset() {
+	p.value, p.digits, p.unit = 0, 0, 0
+}
+
+// Device wraps a UART connection to a TOF10120.
+type Device struct {
+	uart     drivers.UART
+	decoder  Decoder
+	distance int32
+	buf      [16]byte
+
+	// Timeout for a response in passive mode. Defaults to 100ms.
+	Timeout time.Duration
+}
+
+// New creates a new TOF10120 connection. The UART must already be configured
+// at 9600 baud.
+//
+// This function only creates the Device object, it does not touch the device.
+func New(uart drivers.UART) *Device {
+	return &Device{uart: uart, Timeout: 100 * time.Millisecond}
+}
+
+// Update reads the received bytes and returns whether a new distance has
+// been decoded. Use it in active mode.
+func (d *Device) Update() (bool, error) {
+	updated := false
+	for d.uart.Buffered() > 0 {
+		n, err := d.uart.Read(d.buf[:])
+		if err != nil {
+			return updated, err
+		}
+		for _, b := range d.buf[:n] {
+			if mm, ok := d.decoder.Feed(b); ok {
+				d.distance = mm
+				updated = true
+			}
+		}
+	}
+	return updated, nil
+}
+
+// Distance returns the last received distance in mm.
+func (d *Device) Distance() int32 {
+	return d.distance
+}
+
+// ReadDistance asks the sensor for the distance and waits for the answer.
+// Use it in passive mode.
+func (d *Device) ReadDistance() (int32, error) {
+	// Drop stale bytes, such as part of a distance sent in active mode.
+	for d.uart.Buffered() > 0 {
+		if _, err := d.uart.Read(d.buf[:]); err != nil {
+			return 0, err
+		}
+	}
+	d.decoder.reset()
+	if err := d.command("r6#"); err != nil {
+		return 0, err
+	}
+	deadline := time.Now().Add(d.Timeout)
+	for time.Now().Before(deadline) {
+		updated, err := d.Update()
+		if err != nil {
+			return 0, err
+		}
+		if updated {
+			return d.distance, nil
+		}
+		time.Sleep(time.Millisecond)
+	}
+	return 0, errTimeout
+}
+
+// SetPassive switches between active mode, where the sensor sends distances
+// by itself, and passive mode. The setting is stored in the sensor.
+func (d *Device) SetPassive(passive bool) error {
+	if passive {
+		return d.command("s5-1#")
+	}
+	return d.command("s5-0#")
+}
+
+// SetInterval sets the interval between distances in active mode, from 10ms
+// to 9999ms. The setting is stored in the sensor.
+func (d *Device) SetInterval(interval time.Duration) error {
+	return d.setValue('2', int32(interval/time.Millisecond))
+}
+
+// SetOffset sets an offset in mm that the sensor adds to the distance. The
+// setting is stored in the sensor.
+func (d *Device) SetOffset(mm int32) error {
+	return d.setValue('1', mm)
+}
+
+// SetFiltered selects between the filtered distance (the default) and the
+// real time distance, which reacts faster but is noisier. The setting is
+// stored in the sensor.
+func (d *Device) SetFiltered(filtered bool) error {
+	if filtered {
+		return d.command("s3-0#")
+	}
+	return d.command("s3-1#")
+}
+
+// setValue sends a "s<n>-<value>#" command.
+func (d *Device) setValue(n byte, value int32) error {
+	cmd := append(d.buf[:0], 's', n, '-')
+	cmd = strconv.AppendInt(cmd, int64(value), 10)
+	cmd = append(cmd, '#')
+	_, err := d.uart.Write(cmd)
+	return err
+}
+
+func (d *Device) command(cmd string) error {
+	_, err := d.uart.Write([]byte(cmd))
+	return err
+}
@@ -0,0 +1,61 @@
+package tof10120
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	qt "github.com/frankban/quicktest"
+)
+
+func TestDecoder(t *testing.T) {
+	c := qt.New(t)
+	var p Decoder
+	var got []int32
+	for _, b := range []byte("123mm\r\nok\r\n45mm\r\n1x2mm\r\nm7mm 99m9mm") {
+		if mm, ok := p.Feed(b); ok {
+			got = append(got, mm)
+		}
+	}
+	c.Assert(got, qt.DeepEquals, []int32{123, 45, 2, 7, 9})
+}
+
+// fakeSensor answers the r6# query with the distance set.
+type fakeSensor struct {
+	rx       bytes.Buffer
+	tx       bytes.Buffer
+	distance string
+}
+
+func (s *fakeSensor) Read(b []byte) (int, error) { return s.rx.Read(b) }
+func (s *fakeSensor) Buffered() int              { return s.rx.Len() }
+
+func (s *fakeSensor) Write(b []byte) (int, error) {
+	s.tx.Write(b)
+	if string(b) == "r6#" && s.distance != "" {
+		s.rx.WriteString(s.distance)
+	}
+	return len(b), nil
+}
+
+func TestReadDistance(t *testing.T) {
+	c := qt.New(t)
+	s := &fakeSensor{distance: "321mm\r\n"}
+	dev := New(s)
+
+	// A partial distance left from active mode is discarded.
+	s.rx.WriteString("98")
+	mm, err := dev.ReadDistance()
+	c.Assert(err, qt.IsNil)
+	c.Assert(mm, qt.Equals, int32(321))
+	c.Assert(dev.Distance(), qt.Equals, int32(321))
+
+	c.Assert(dev.SetInterval(500*time.Millisecond), qt.IsNil)
+	c.Assert(dev.SetPassive(true), qt.IsNil)
+	c.Assert(s.tx.String(), qt.Equals, "r6#s2-500#s5-1#")
+
+	s.distance = ""
+	dev.Timeout = 5 * time.Millisecond
+	_, err = dev.ReadDistance()
+	c.Assert(err, qt.Equals, errTimeout)
+}
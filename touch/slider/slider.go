@@ -0,0 +1,208 @@
+// Package slider combines adjacent capacitive touch electrodes into linear
+// sliders and circular wheels.
+//
+// It works with any source of per-electrode signal strength: the delta
+// counts of a CAP1188, the baseline minus filtered data of a MPR121, or raw
+// touch channels of a microcontroller with the untouched level subtracted.
+// The touch position is interpolated between the strongest electrode and its
+// neighbours, so the resolution is much higher than the number of
+// electrodes.
+package slider // import "tinygo.org/x/drivers/touch/slider"
+
+import "time"
+
+// Config describes the electrode layout.
+type Config struct {
+	// Electrodes is the number of electrodes, in order along the slider or
+	// around the wheel.
+	Electrodes int
+
+	// Wheel joins the last electrode to the first one.
+	Wheel bool
+
+	// Threshold is the minimum signal strength of the strongest electrode
+	// for a touch. Defaults to 10.
+	Threshold int32
+
+	// Resolution is the number of positions between two electrodes.
+	// Defaults to 64.
+	Resolution int32
+
+	// SwipeSpeed is the minimum speed, in positions per second, at the time
+	// of release for a swipe gesture. Defaults to 4 electrodes per second.
+	SwipeSpeed int32
+}
+
+// Gesture is a gesture detected on release.
+type Gesture uint8
+
+const (
+	GestureNone Gesture = iota
+
+	// GestureSwipeForward is a swipe towards higher positions, which is
+	// clockwise on a wheel when the electrodes are numbered clockwise.
+	GestureSwipeForward
+
+	// GestureSwipeBackward is a swipe towards lower positions.
+	GestureSwipeBackward
+)
+
+// State is the state of a slider after an update.
+type State struct {
+	Touched bool
+
+	// Position of the touch, from 0 to Range()-1 on a wheel and from 0 to
+	// Range() on a slider. Valid while touched, and kept after release.
+	Position int32
+
+	// Velocity in positions per second, smoothed over a few updates.
+	Velocity int32
+}
+
+// Slider decodes the position and movement of a touch.
+type Slider struct {
+	cfg      Config
+	state    State
+	last     time.Time
+	start    int32
+	distance int32
+}
+
+// New returns a new slider or wheel.
+func New(cfg Config) *Slider {
+	if cfg.Threshold == 0 {
+		cfg.Threshold = 10
+	}
+	if cfg.Resolution == 0 {
+		cfg.Resolution = 64
+	}
+	if cfg.SwipeSpeed == 0 {
+		cfg.SwipeSpeed = 4 * cfg.Resolution
+	}
+	return &Slider{cfg: cfg}
+}
+
+// Range returns the number of positions: the position of the last electrode
+// on a slider, or the number of positions in one turn of a wheel.
+func (s *Slider) Range() int32 {
+	if s.cfg.Wheel {
+		return int32(s.cfg.Electrodes) * s.cfg.Resolution
+	}
+	return int32(s.cfg.Electrodes-1) * s.cfg.Resolution
+}
+
+// State returns the state after the last update.
+func (s *Slider) State() State {
+	return s.state
+}
+
+// Update processes a new set of signal strengths, one per electrode, taken at
+// the given time. It returns the new state and the gesture that ended with
+// this update, if any.
+func (s *Slider) Update(strengths []int32, now time.Time) (State, Gesture) {
+	pos, touched := s.interpolate(strengths)
+	if !touched {
+		gesture := GestureNone
+		if s.state.Touched {
+			gesture = s.release()
+		}
+		s.state.Touched = false
+		s.state.Velocity = 0
+		return s.state, gesture
+	}
+
+	if !s.state.Touched {
+		// New touch.
+		s.state = State{Touched: true, Position: pos}
+		s.start = pos
+		s.distance = 0
+		s.last = now
+		return s.state, GestureNone
+	}
+
+	delta := s.delta(s.state.Position, pos)
+	s.distance += delta
+	if dt := now.Sub(s.last); dt > 0 {
+		v := int32(int64(delta) * int64(time.Second) / int64(dt))
+		// Exponential moving average with alpha 1/2.
+		s.state.Velocity = (s.state.Velocity + v) / 2
+	}
+	s.state.Position = pos
+	s.last = now
+	return s.state, GestureNone
+}
+
+// release detects a swipe at the end of a touch.
+func (s *Slider) release() Gesture {
+	v := s.state.Velocity
+	d := s.distance
+	switch {
+	case v >= s.cfg.SwipeSpeed && d >= s.cfg.Resolution:
+		return GestureSwipeForward
+	case v <= -s.cfg.SwipeSpeed && d <= -s.cfg.Resolution:
+		return GestureSwipeBackward
+	}
+	return GestureNone
+}
+
+// delta returns the movement from a to b, taking the shortest way around a
+// wheel.
+func (s *Slider) delta(a, b int32) int32 {
+	d := b - a
+	if s.cfg.Wheel {
+		r := s.Range()
+		if d > r/2 {
+			d -= r
+		} else if d < -r/2 {
+			d += r
+		}
+	}
+	return d
+}
+
+// interpolate returns the touch position from the signal strengths.
+func (s *Slider) interpolate(strengths []int32) (int32, bool) {
+	n := s.cfg.Electrodes
+	if len(strengths) < n {
+		n = len(strengths)
+	}
+	if n == 0 {
+		return 0, false
+	}
+	peak := 0
+	for i := 1; i < n; i++ {
+		if strengths[i] > strengths[peak] {
+			peak = i
+		}
+	}
+	m := strengths[peak]
+	if m < s.cfg.Threshold {
+		return 0, false
+	}
+
+	neighbour := func(i int) int32 {
+		if s.cfg.Wheel {
+			i = (i + n) % n
+		} else if i < 0 || i >= n {
+			return 0
+		}
+		if v := strengths[i]; v > 0 {
+			return v
+		}
+		return 0
+	}
+	l := neighbour(peak - 1)
+	r := neighbour(peak + 1)
+
+	// Centroid of the peak and its neighbours.
+	pos := int32(peak)*s.cfg.Resolution + (r-l)*s.cfg.Resolution/(l+m+r)
+	if s.cfg.Wheel {
+		rng := s.Range()
+		pos = (pos%rng + rng) % rng
+	} else if pos < 0 {
+		pos = 0
+	} else if max := s.Range(); pos > max {
+		pos = max
+	}
+	return pos, true
+}
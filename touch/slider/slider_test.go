@@ -0,0 +1,61 @@
+package slider
+
+import (
+	"testing"
+	"time"
+
+	qt "github.com/frankban/quicktest"
+)
+
+func TestInterpolate(t *testing.T) {
+	c := qt.New(t)
+
+	s := New(Config{Electrodes: 4, Resolution: 100})
+	st, _ := s.Update([]int32{0, 50, 0, 0}, time.Time{})
+	c.Assert(st.Touched, qt.IsTrue)
+	c.Assert(st.Position, qt.Equals, int32(100))
+	st, _ = s.Update([]int32{0, 50, 50, 0}, time.Time{})
+	c.Assert(st.Position, qt.Equals, int32(150))
+	st, _ = s.Update([]int32{0, 0, 0, 5}, time.Time{})
+	c.Assert(st.Touched, qt.IsFalse)
+
+	w := New(Config{Electrodes: 4, Resolution: 100, Wheel: true})
+	st, _ = w.Update([]int32{50, 0, 0, 50}, time.Time{})
+	c.Assert(st.Position == 350 || st.Position == 0, qt.IsTrue, qt.Commentf("position: %d", st.Position))
+	st, _ = w.Update([]int32{60, 20, 0, 20}, time.Time{})
+	c.Assert(st.Position, qt.Equals, int32(0))
+}
+
+func TestSwipe(t *testing.T) {
+	c := qt.New(t)
+
+	w := New(Config{Electrodes: 4, Resolution: 100, Wheel: true})
+	now := time.Now()
+	// Move forward across the wrap point, from electrode 2 to 1.
+	frames := [][]int32{
+		{0, 0, 50, 0},
+		{0, 0, 25, 50},
+		{25, 0, 0, 50},
+		{50, 0, 0, 25},
+		{50, 25, 0, 0},
+	}
+	var st State
+	for _, f := range frames {
+		st, _ = w.Update(f, now)
+		now = now.Add(50 * time.Millisecond)
+	}
+	c.Assert(st.Velocity > 0, qt.IsTrue, qt.Commentf("velocity: %d", st.Velocity))
+	_, g := w.Update([]int32{0, 0, 0, 0}, now)
+	c.Assert(g, qt.Equals, GestureSwipeForward)
+
+	// A slow movement is not a swipe.
+	s := New(Config{Electrodes: 4, Resolution: 100})
+	for i := int32(0); i < 4; i++ {
+		f := []int32{0, 0, 0, 0}
+		f[3-i] = 50
+		s.Update(f, now)
+		now = now.Add(time.Second)
+	}
+	_, g = s.Update([]int32{0, 0, 0, 0}, now)
+	c.Assert(g, qt.Equals, GestureNone)
+}
@@ -0,0 +1,108 @@
+package tuner
+
+// RDS decodes the Radio Data System groups sent by FM stations: the station
+// identification, its name and its radio text.
+type RDS struct {
+	// PI is the program identification code of the station.
+	PI uint16
+
+	// PTY is the program type, such as 1 for news or 10 for pop music.
+	PTY uint8
+
+	// TP is set when the station broadcasts traffic announcements.
+	TP bool
+
+	ps     [8]byte  // last complete station name
+	psBuf  [8]byte  // station name being received
+	psMask uint8    // segments of psBuf received
+	rt     [64]byte // radio text
+	rtLen  int
+	rtAB   bool // text A/B flag, which toggles for a new text
+}
+
+// Reset forgets the data of the previous station.
+func (r *RDS) Reset() {
+	*r = RDS{}
+}
+
+// Decode decodes a group of four blocks. okA, okC and okD tell whether the
+// blocks A, C and D were received without uncorrectable errors; block B
+// holds the group type and must be valid.
+func (r *RDS) Decode(a, b, c, d uint16, okA, okC, okD bool) {
+	if okA {
+		r.PI = a
+	}
+	group, versionB := b>>12, b&0x0800 != 0
+	r.TP = b&0x0400 != 0
+	r.PTY = uint8(b >> 5 & 0x1F)
+
+	switch {
+	case group == 0 && okD:
+		// Basic tuning: the station name, two characters at a time.
+		seg := b & 0x03
+		r.psBuf[2*seg] = byte(d >> 8)
+		r.psBuf[2*seg+1] = byte(d)
+		r.psMask |= 1 << seg
+		if r.psMask == 0x0F {
+			r.ps = r.psBuf
+			r.psMask = 0
+		}
+	case group == 2:
+		ab := b&0x0010 != 0
+		if ab != r.rtAB {
+			r.rtAB = ab
+			r.rt = [64]byte{}
+			r.rtLen = 0
+		}
+		seg := int(b & 0x0F)
+		if versionB {
+			if okD {
+				r.setText(2*seg, d)
+			}
+		} else if okC && okD {
+			r.setText(4*seg, c)
+			r.setText(4*seg+2, d)
+		}
+	}
+}
+
+// setText sets two characters of the radio text.
+func (r *RDS) setText(i int, chars uint16) {
+	r.rt[i] = byte(chars >> 8)
+	r.rt[i+1] = byte(chars)
+	if i+2 > r.rtLen {
+		r.rtLen = i + 2
+	}
+}
+
+// StationName returns the name of the station, 8 characters padded with
+// spaces, or an empty string until it was received.
+func (r *RDS) StationName() string {
+	if r.ps[0] == 0 {
+		return ""
+	}
+	return string(r.ps[:])
+}
+
+// RadioText returns the text received so far, such as the title of the
+// song, up to 64 characters.
+func (r *RDS) RadioText() string {
+	var buf [64]byte
+	text := buf[:0]
+	for _, c := range r.rt[:r.rtLen] {
+		// A carriage return ends a text shorter than 64 characters.
+		if c == '\r' {
+			break
+		}
+		if c == 0 {
+			// Not received yet.
+			c = ' '
+		}
+		text = append(text, c)
+	}
+	// Trailing spaces.
+	for len(text) > 0 && text[len(text)-1] == ' ' {
+		text = text[:len(text)-1]
+	}
+	return string(text)
+}
@@ -0,0 +1,36 @@
+package tuner
+
+import (
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+)
+
+func TestRDS(t *testing.T) {
+	c := qt.New(t)
+	var r RDS
+	name := "RADIO 1 "
+	// Group 0A, program type 10, with a segment of the name in block D.
+	for seg := uint16(0); seg < 4; seg++ {
+		c.Assert(r.StationName(), qt.Equals, "")
+		d := uint16(name[2*seg])<<8 | uint16(name[2*seg+1])
+		r.Decode(0xC201, 0x0000|10<<5|seg, 0, d, true, true, true)
+	}
+	c.Assert(r.StationName(), qt.Equals, name)
+	c.Assert(r.PI, qt.Equals, uint16(0xC201))
+	c.Assert(r.PTY, qt.Equals, uint8(10))
+
+	// Group 2A, radio text in blocks C and D, the second segment is lost.
+	text := "Now playing: something\r "
+	for seg := uint16(0); seg < 6; seg++ {
+		chars := []byte(text[4*seg : 4*seg+4])
+		cc := uint16(chars[0])<<8 | uint16(chars[1])
+		dd := uint16(chars[2])<<8 | uint16(chars[3])
+		r.Decode(0xC201, 0x2000|seg, cc, dd, true, seg != 1, true)
+	}
+	c.Assert(r.RadioText(), qt.Equals, "Now     ing: something")
+
+	// A new text.
+	r.Decode(0xC201, 0x2010, 'H'<<8|'i', '\r'<<8|' ', true, true, true)
+	c.Assert(r.RadioText(), qt.Equals, "Hi")
+}
@@ -0,0 +1,47 @@
+// Package tuner defines the interface shared by the FM radio receivers, such
+// as the Si4703, the RDA5807M and the TEA5767, so that a radio can be
+// written once for all of them, and the decoder of the Radio Data System
+// (RDS) they use.
+package tuner // import "tinygo.org/x/drivers/tuner"
+
+import "errors"
+
+// ErrSeekFailed is returned by Seek when no station was found before the
+// end of the band.
+var ErrSeekFailed = errors.New("tuner: no station found")
+
+// Tuner is an FM receiver. Frequencies are in kHz, for instance 101100 for
+// 101.1MHz.
+type Tuner interface {
+	// Tune tunes to a frequency of the band.
+	Tune(khz uint32) error
+
+	// Seek tunes to the next station up or down the band, and returns its
+	// frequency. It stops at the end of the band, with ErrSeekFailed.
+	Seek(up bool) (uint32, error)
+
+	// Frequency returns the frequency tuned, as of the last tune or seek.
+	Frequency() uint32
+
+	// RSSI returns the received signal strength, on a logarithmic scale
+	// which depends on the chip: in dBµV for the Si4703.
+	RSSI() (uint8, error)
+
+	// Stereo returns whether the station is received in stereo.
+	Stereo() (bool, error)
+
+	// SetVolume sets the volume from 0 (muted) to 15. Chips without a
+	// volume control only mute at 0.
+	SetVolume(volume uint8) error
+
+	// Mute mutes or unmutes the audio outputs.
+	Mute(mute bool) error
+
+	// ReadRDS checks for a new RDS group, and decodes it. It returns whether
+	// a group was received, never for chips without RDS.
+	ReadRDS() (bool, error)
+
+	// RDS returns the data decoded by ReadRDS, which is reset by Tune and
+	// Seek.
+	RDS() *RDS
+}
@@ -1,5 +1,8 @@
 package uc8151
 
+type Rotation uint8
+type Speed uint8
+
 // Registers
 const (
 	// Display resolution
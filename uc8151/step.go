@@ -0,0 +1,11 @@
+package uc8151
+
+// chunkEnd returns the end of the next chunk of the buffer sent by
+// DisplayStep: at most maxBytes bytes past sent, or all the rest of the
+// buffer when maxBytes is not positive.
+func chunkEnd(sent, length uint32, maxBytes int) uint32 {
+	if maxBytes <= 0 || uint32(maxBytes) >= length-sent {
+		return length
+	}
+	return sent + uint32(maxBytes)
+}
@@ -0,0 +1,21 @@
+package uc8151
+
+import "testing"
+
+func TestChunkEnd(t *testing.T) {
+	for _, tc := range []struct {
+		sent, length uint32
+		maxBytes     int
+		want         uint32
+	}{
+		{0, 4736, 1000, 1000},
+		{4000, 4736, 1000, 4736},
+		{4736, 4736, 1000, 4736},
+		{1000, 4736, 0, 4736},
+		{1000, 4736, -1, 4736},
+	} {
+		if got := chunkEnd(tc.sent, tc.length, tc.maxBytes); got != tc.want {
+			t.Errorf("chunkEnd(%d, %d, %d) = %d, want %d", tc.sent, tc.length, tc.maxBytes, got, tc.want)
+		}
+	}
+}
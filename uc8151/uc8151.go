@@ -1,3 +1,5 @@
+//go:build tinygo
+
 // Package uc8151 implements a driver for e-ink displays controlled by UC8151
 //
 // Inspired by https://github.com/pimoroni/pimoroni-pico/blob/main/drivers/uc8151/uc8151.cpp
@@ -34,11 +36,10 @@ type Device struct {
 	rotation     Rotation
 	speed        Speed
 	blocking     bool
+	sent         uint32 // buffer bytes sent by DisplayStep
+	stepping     bool
 }
 
-type Rotation uint8
-type Speed uint8
-
 // New returns a new epd2in13x driver. Pass in a fully configured SPI bus.
 func New(bus drivers.SPI, csPin, dcPin, rstPin, busyPin machine.Pin) Device {
 	csPin.Configure(machine.PinConfig{Mode: machine.PinOutput})
@@ -196,6 +197,42 @@ func (d *Device) Display() error {
 	return nil
 }
 
+// DisplayStep sends the buffer to the screen in chunks of at most maxBytes
+// per call, so that a single call never takes long; a maxBytes of 0 or less
+// sends all the rest of the buffer at once. It returns
+// drivers.ErrWouldBlock while the display is still busy with a previous
+// refresh or the buffer is not completely sent yet, and nil once the refresh
+// has started. The refresh itself takes a few seconds; the display is not
+// powered off afterwards, call PowerOff once it is idle.
+//
+// The buffer must not be modified until DisplayStep has returned nil.
+func (d *Device) DisplayStep(maxBytes int) error {
+	if !d.stepping {
+		if !d.busy.Get() {
+			return drivers.ErrWouldBlock
+		}
+		d.SendCommand(PON)
+		d.SendCommand(PTOU)
+		d.SendCommand(DTM2)
+		d.sent = 0
+		d.stepping = true
+		return drivers.ErrWouldBlock
+	}
+
+	end := chunkEnd(d.sent, d.bufferLength, maxBytes)
+	for ; d.sent < end; d.sent++ {
+		d.SendData(d.buffer[d.sent])
+	}
+	if d.sent < d.bufferLength {
+		return drivers.ErrWouldBlock
+	}
+
+	d.SendCommand(DSP)
+	d.SendCommand(DRF)
+	d.stepping = false
+	return nil
+}
+
 // DisplayRect sends only an area of the buffer to the screen.
 // The rectangle points need to be a multiple of 8 in the screen.
 // They might not work as expected if the screen is rotated.
@@ -269,6 +306,7 @@ func (d *Device) ClearDisplay() {
 // WaitUntilIdle waits until the display is ready
 func (d *Device) WaitUntilIdle() {
 	for !d.busy.Get() {
+		drivers.Yield()
 		time.Sleep(100 * time.Millisecond)
 	}
 }
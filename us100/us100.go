@@ -0,0 +1,93 @@
+// Package us100 provides a driver for the US-100 ultrasonic distance sensor
+// in serial mode (jumper on the back of the module set).
+//
+// In serial mode, the sensor measures the time of flight itself and
+// compensates it for the temperature measured by its built-in sensor, so
+// the readings are more accurate than with the HC-SR04 style pulse mode and
+// don't need precise pin timing.
+package us100 // import "tinygo.org/x/drivers/us100"
+
+import (
+	"errors"
+	"time"
+
+	"tinygo.org/x/drivers"
+)
+
+// Commands
+const (
+	cmdDistance    = 0x55
+	cmdTemperature = 0x50
+)
+
+// MaxDistance is the largest distance the sensor can measure, in mm.
+const MaxDistance = 4500
+
+var (
+	errTimeout    = errors.New("us100: timeout")
+	errOutOfRange = errors.New("us100: no echo")
+)
+
+// Device wraps a UART connection to a US-100.
+type Device struct {
+	uart drivers.UART
+
+	// Timeout for a response. Defaults to 100ms, a measurement takes up to
+	// about 60ms at the maximum distance.
+	Timeout time.Duration
+
+	buf [2]byte
+}
+
+// New creates a new US-100 connection. The UART must already be configured
+// at 9600 baud.
+//
+// This function only creates the Device object, it does not touch the device.
+func New(uart drivers.UART) *Device {
+	return &Device{uart: uart, Timeout: 100 * time.Millisecond}
+}
+
+// ReadDistance measures the distance to the nearest object in mm. It returns
+// an error if there is no object in range.
+func (d *Device) ReadDistance() (int32, error) {
+	if err := d.query(cmdDistance, d.buf[:2]); err != nil {
+		return 0, err
+	}
+	mm := int32(d.buf[0])<<8 | int32(d.buf[1])
+	if mm == 0 || mm > MaxDistance {
+		return mm, errOutOfRange
+	}
+	return mm, nil
+}
+
+// ReadTemperature reads the built-in temperature sensor and returns the
+// temperature in celsius milli degrees. The resolution is 1°C.
+func (d *Device) ReadTemperature() (int32, error) {
+	if err := d.query(cmdTemperature, d.buf[:1]); err != nil {
+		return 0, err
+	}
+	return (int32(d.buf[0]) - 45) * 1000, nil
+}
+
+// query sends a command and reads a response of len(resp) bytes.
+func (d *Device) query(cmd byte, resp []byte) error {
+	// Drop stale bytes, for example from a previous timed out query.
+	for d.uart.Buffered() > 0 {
+		if _, err := d.uart.Read(resp); err != nil {
+			return err
+		}
+	}
+	d.buf[0] = cmd
+	if _, err := d.uart.Write(d.buf[:1]); err != nil {
+		return err
+	}
+	deadline := time.Now().Add(d.Timeout)
+	for d.uart.Buffered() < len(resp) {
+		if time.Now().After(deadline) {
+			return errTimeout
+		}
+		time.Sleep(time.Millisecond)
+	}
+	_, err := d.uart.Read(resp)
+	return err
+}
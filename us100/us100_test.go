@@ -0,0 +1,56 @@
+package us100
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	qt "github.com/frankban/quicktest"
+)
+
+// fakeSensor answers the commands written to it with the responses set for
+// them.
+type fakeSensor struct {
+	rx        bytes.Buffer
+	responses map[byte][]byte
+}
+
+func (s *fakeSensor) Read(b []byte) (int, error) { return s.rx.Read(b) }
+func (s *fakeSensor) Buffered() int              { return s.rx.Len() }
+
+func (s *fakeSensor) Write(b []byte) (int, error) {
+	for _, cmd := range b {
+		s.rx.Write(s.responses[cmd])
+	}
+	return len(b), nil
+}
+
+func TestReadDistance(t *testing.T) {
+	c := qt.New(t)
+	s := &fakeSensor{responses: map[byte][]byte{
+		cmdDistance:    {0x04, 0xD2},
+		cmdTemperature: {45 + 23},
+	}}
+	dev := New(s)
+
+	// Stale bytes of an earlier query are dropped.
+	s.rx.WriteString("\x07")
+	mm, err := dev.ReadDistance()
+	c.Assert(err, qt.IsNil)
+	c.Assert(mm, qt.Equals, int32(1234))
+
+	temp, err := dev.ReadTemperature()
+	c.Assert(err, qt.IsNil)
+	c.Assert(temp, qt.Equals, int32(23000))
+
+	s.responses[cmdDistance] = []byte{0x27, 0x10}
+	mm, err = dev.ReadDistance()
+	c.Assert(err, qt.Equals, errOutOfRange)
+	c.Assert(mm, qt.Equals, int32(10000))
+
+	// A response cut short times out.
+	s.responses[cmdDistance] = []byte{0x04}
+	dev.Timeout = 5 * time.Millisecond
+	_, err = dev.ReadDistance()
+	c.Assert(err, qt.Equals, errTimeout)
+}
@@ -0,0 +1,228 @@
+// Package usbmsc shares a block device, such as an SD card or an SPI NOR
+// flash, between the firmware and a USB host, to which the device is a USB
+// mass storage drive. The data of a logger can then be copied to a PC over
+// USB, without pulling the card.
+//
+// The USB stack, such as TinyGo's machine/usb/msc package, is given the
+// device returned by Host; the firmware uses the one returned by Firmware.
+// Only one of them has the medium at a time, as neither knows of the writes
+// of the other: the file system of one would overwrite what the other wrote.
+// Each takes the medium with Acquire and gives it back with Release; the
+// other side gets an error meanwhile, which the host shows as a drive
+// without a medium.
+//
+//	bridge := usbmsc.New(&card)
+//	msc.Port(bridge.Host())
+//
+//	// When the USB cable is plugged in, or a button pressed.
+//	fsys.Sync()
+//	bridge.Release(usbmsc.Firmware)
+//	bridge.Acquire(usbmsc.Host)
+//
+//	// Once the drive was ejected on the PC.
+//	bridge.Release(usbmsc.Host)
+//	bridge.Acquire(usbmsc.Firmware)
+//	if bridge.Modified() {
+//		fsys, err = fat.Mount(bridge.Firmware())
+//	}
+package usbmsc // import "tinygo.org/x/drivers/usbmsc"
+
+import (
+	"errors"
+	"sync"
+)
+
+var (
+	// ErrInUse is returned by Acquire when the other side has the medium.
+	ErrInUse = errors.New("usbmsc: medium in use")
+
+	// ErrNotOwner is returned by the devices of the side that doesn't have
+	// the medium.
+	ErrNotOwner = errors.New("usbmsc: medium not acquired")
+)
+
+// BlockDevice is the block device interface of TinyGo's machine package and
+// TinyFS, implemented by the cards of the sdcard package and the flash
+// package.
+type BlockDevice interface {
+	ReadAt(buf []byte, off int64) (n int, err error)
+	WriteAt(buf []byte, off int64) (n int, err error)
+	Size() int64
+	WriteBlockSize() int64
+	EraseBlockSize() int64
+	EraseBlocks(start, len int64) error
+}
+
+// syncer is implemented by devices holding writes in a cache.
+type syncer interface {
+	Sync() error
+}
+
+// Owner is the side having the medium.
+type Owner uint8
+
+const (
+	None Owner = iota
+	Firmware
+	Host
+)
+
+func (o Owner) String() string {
+	switch o {
+	case Firmware:
+		return "firmware"
+	case Host:
+		return "host"
+	}
+	return "none"
+}
+
+// Bridge shares a block device between the firmware and a USB host.
+type Bridge struct {
+	dev      BlockDevice
+	mu       sync.Mutex // held during the operations on dev
+	owner    Owner
+	modified bool // written by the host
+	host     side
+	firmware side
+}
+
+// New returns a Bridge sharing dev. Neither side has the medium until it
+// calls Acquire.
+//
+// This function only creates the Bridge object, it does not touch the
+// device.
+func New(dev BlockDevice) *Bridge {
+	b := &Bridge{dev: dev}
+	b.host = side{b: b, owner: Host}
+	b.firmware = side{b: b, owner: Firmware}
+	return b
+}
+
+// Host returns the device of the USB host, to give to the USB stack.
+func (b *Bridge) Host() BlockDevice {
+	return &b.host
+}
+
+// Firmware returns the device of the firmware, to mount a file system or
+// write a log.
+func (b *Bridge) Firmware() BlockDevice {
+	return &b.firmware
+}
+
+// Owner returns the side having the medium.
+func (b *Bridge) Owner() Owner {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.owner
+}
+
+// Acquire gives the medium to o. It returns ErrInUse when the other side
+// has it. When the host takes it, Modified is cleared.
+func (b *Bridge) Acquire(o Owner) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.owner != None && b.owner != o {
+		return ErrInUse
+	}
+	if o == Host && b.owner != Host {
+		b.modified = false
+	}
+	b.owner = o
+	return nil
+}
+
+// Release gives back the medium held by o, once the writes left in the
+// cache of the device are written. It does nothing when o doesn't have it.
+func (b *Bridge) Release(o Owner) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.owner != o {
+		return nil
+	}
+	var err error
+	if s, ok := b.dev.(syncer); ok {
+		err = s.Sync()
+	}
+	b.owner = None
+	return err
+}
+
+// Modified returns whether the host wrote to the medium the last time it had
+// it: the firmware must then mount its file system again, as the one it had
+// mounted is stale.
+func (b *Bridge) Modified() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.modified
+}
+
+// side is the device of the firmware or of the host.
+type side struct {
+	b     *Bridge
+	owner Owner
+}
+
+// lock locks the bridge when s has the medium.
+func (s *side) lock() error {
+	s.b.mu.Lock()
+	if s.b.owner != s.owner {
+		s.b.mu.Unlock()
+		return ErrNotOwner
+	}
+	return nil
+}
+
+func (s *side) ReadAt(buf []byte, off int64) (int, error) {
+	if err := s.lock(); err != nil {
+		return 0, err
+	}
+	defer s.b.mu.Unlock()
+	return s.b.dev.ReadAt(buf, off)
+}
+
+func (s *side) WriteAt(buf []byte, off int64) (int, error) {
+	if err := s.lock(); err != nil {
+		return 0, err
+	}
+	defer s.b.mu.Unlock()
+	if s.owner == Host {
+		s.b.modified = true
+	}
+	return s.b.dev.WriteAt(buf, off)
+}
+
+func (s *side) Size() int64 {
+	return s.b.dev.Size()
+}
+
+func (s *side) WriteBlockSize() int64 {
+	return s.b.dev.WriteBlockSize()
+}
+
+func (s *side) EraseBlockSize() int64 {
+	return s.b.dev.EraseBlockSize()
+}
+
+func (s *side) EraseBlocks(start, len int64) error {
+	if err := s.lock(); err != nil {
+		return err
+	}
+	defer s.b.mu.Unlock()
+	if s.owner == Host {
+		s.b.modified = true
+	}
+	return s.b.dev.EraseBlocks(start, len)
+}
+
+// Sync writes the writes left in the cache of the device, if any.
+func (s *side) Sync() error {
+	if err := s.lock(); err != nil {
+		return err
+	}
+	defer s.b.mu.Unlock()
+	if sy, ok := s.b.dev.(syncer); ok {
+		return sy.Sync()
+	}
+	return nil
+}
@@ -0,0 +1,72 @@
+package usbmsc
+
+import (
+	"bytes"
+	"testing"
+
+	"tinygo.org/x/drivers/sdcard"
+)
+
+var _ BlockDevice = (*sdcard.MemoryCard)(nil)
+
+func TestBridge(t *testing.T) {
+	card := sdcard.NewMemoryCard(1 << 20)
+	b := New(card)
+	host, fw := b.Host(), b.Firmware()
+	buf := make([]byte, 512)
+
+	if _, err := host.ReadAt(buf, 0); err != ErrNotOwner {
+		t.Errorf("host read without the medium: got %v", err)
+	}
+	if err := b.Acquire(Firmware); err != nil {
+		t.Fatal(err)
+	}
+	if err := b.Acquire(Host); err != ErrInUse {
+		t.Errorf("host acquire: got %v", err)
+	}
+	if _, err := fw.WriteAt([]byte("firmware"), 10); err != nil {
+		t.Fatal(err)
+	}
+	if b.Owner() != Firmware || b.Modified() {
+		t.Errorf("owner %v, modified %v", b.Owner(), b.Modified())
+	}
+	// The partial block is synced when the firmware gives back the medium.
+	if err := b.Release(Firmware); err != nil {
+		t.Fatal(err)
+	}
+	card.ReadBlocks(0, buf)
+	if !bytes.Equal(buf[10:18], []byte("firmware")) {
+		t.Error("write not synced on release")
+	}
+
+	if err := b.Acquire(Host); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := fw.ReadAt(buf, 0); err != ErrNotOwner {
+		t.Errorf("firmware read while the host has the medium: got %v", err)
+	}
+	if err := fw.EraseBlocks(0, 1); err != ErrNotOwner {
+		t.Errorf("firmware erase while the host has the medium: got %v", err)
+	}
+	if _, err := host.ReadAt(buf, 0); err != nil || !bytes.Equal(buf[10:18], []byte("firmware")) {
+		t.Errorf("host read: %v", err)
+	}
+	if b.Modified() {
+		t.Error("modified by a read")
+	}
+	if _, err := host.WriteAt(make([]byte, 512), 512); err != nil {
+		t.Fatal(err)
+	}
+	b.Release(Host)
+	if err := b.Acquire(Firmware); err != nil || !b.Modified() {
+		t.Errorf("firmware acquire: %v, modified %v", err, b.Modified())
+	}
+	b.Release(Firmware)
+	b.Acquire(Host)
+	if b.Modified() {
+		t.Error("modified not cleared when the host takes the medium")
+	}
+	if host.Size() != card.Size() {
+		t.Errorf("size %d", host.Size())
+	}
+}
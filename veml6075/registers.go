@@ -0,0 +1,23 @@
+package veml6075
+
+// Address is the I2C address of the VEML6075.
+const Address = 0x10
+
+// Registers (command codes), all 16 bit little endian.
+const (
+	UV_CONF  = 0x00
+	UVA_DATA = 0x07
+	UVB_DATA = 0x09
+	UVCOMP1  = 0x0A
+	UVCOMP2  = 0x0B
+	ID       = 0x0C
+)
+
+// Bits of UV_CONF.
+const (
+	confShutdown    = 1 << 0
+	confForced      = 1 << 1
+	confTrigger     = 1 << 2
+	confHighDynamic = 1 << 3
+	deviceID        = 0x26
+)
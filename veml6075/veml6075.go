@@ -0,0 +1,177 @@
+// Package veml6075 provides a driver for the VEML6075 UVA and UVB light
+// sensor by Vishay.
+//
+// The UV index is calculated as described in the application note, using
+// the visible and infrared compensation channels.
+//
+// Datasheet: https://www.vishay.com/docs/84304/veml6075.pdf
+// Application note: https://www.vishay.com/docs/84339/designingveml6075.pdf
+package veml6075 // import "tinygo.org/x/drivers/veml6075"
+
+import (
+	"errors"
+	"time"
+
+	"tinygo.org/x/drivers"
+	"tinygo.org/x/drivers/internal/legacy"
+)
+
+var errNotConnected = errors.New("veml6075: device not found")
+
+// IntegrationTime of a measurement.
+type IntegrationTime uint8
+
+const (
+	IntegrationTime50ms IntegrationTime = iota + 1
+	IntegrationTime100ms
+	IntegrationTime200ms
+	IntegrationTime400ms
+	IntegrationTime800ms
+)
+
+// Coefficients are the compensation and responsivity factors of the UV index
+// calculation. They depend on the window in front of the sensor, see the
+// application note.
+type Coefficients struct {
+	// UVA and UVB compensation for the visible (comp1) and infrared (comp2)
+	// channels.
+	A, B, C, D float32
+
+	// Responsivity in UVI per count at 100ms integration time, normal
+	// dynamic.
+	UVAResponse, UVBResponse float32
+}
+
+// OpenAir are the coefficients without a window in front of the sensor.
+var OpenAir = Coefficients{
+	A: 2.22, B: 1.33, C: 2.95, D: 1.74,
+	UVAResponse: 0.001461, UVBResponse: 0.002591,
+}
+
+// Config holds the measurement settings.
+type Config struct {
+	// IntegrationTime defaults to IntegrationTime100ms.
+	IntegrationTime IntegrationTime
+
+	// HighDynamic halves the sensitivity, for use in direct sunlight.
+	HighDynamic bool
+
+	// Coefficients default to OpenAir.
+	Coefficients *Coefficients
+}
+
+// Device wraps an I2C connection to a VEML6075 device.
+type Device struct {
+	bus     drivers.I2C
+	Address uint16
+	conf    uint8
+	it      time.Duration
+	coef    Coefficients
+	buf     [2]byte
+
+	// last readings
+	uva, uvb, comp1, comp2 uint16
+}
+
+// New creates a new VEML6075 connection. The I2C bus must already be
+// configured.
+//
+// This function only creates the Device object, it does not touch the device.
+func New(bus drivers.I2C) *Device {
+	return &Device{bus: bus, Address: Address}
+}
+
+// Connected returns whether a VEML6075 has been found.
+func (d *Device) Connected() bool {
+	id, err := d.read16(ID)
+	return err == nil && id&0xFF == deviceID
+}
+
+// Configure sets up the sensor and starts continuous measurement.
+func (d *Device) Configure(cfg Config) error {
+	if cfg.IntegrationTime == 0 {
+		cfg.IntegrationTime = IntegrationTime100ms
+	}
+	if cfg.Coefficients == nil {
+		cfg.Coefficients = &OpenAir
+	}
+	d.coef = *cfg.Coefficients
+	d.it = 50 * time.Millisecond << (cfg.IntegrationTime - 1)
+	d.conf = uint8(cfg.IntegrationTime-1) << 4
+	if cfg.HighDynamic {
+		d.conf |= confHighDynamic
+	}
+	if !d.Connected() {
+		return errNotConnected
+	}
+	return d.write16(UV_CONF, uint16(d.conf))
+}
+
+// SetEnabled starts or stops continuous measurement. The sensor draws less
+// than 1µA when stopped.
+func (d *Device) SetEnabled(enabled bool) error {
+	conf := d.conf
+	if !enabled {
+		conf |= confShutdown
+	}
+	return d.write16(UV_CONF, uint16(conf))
+}
+
+// Update reads the UVA, UVB and compensation channels.
+func (d *Device) Update() error {
+	var err error
+	if d.uva, err = d.read16(UVA_DATA); err != nil {
+		return err
+	}
+	if d.uvb, err = d.read16(UVB_DATA); err != nil {
+		return err
+	}
+	if d.comp1, err = d.read16(UVCOMP1); err != nil {
+		return err
+	}
+	d.comp2, err = d.read16(UVCOMP2)
+	return err
+}
+
+// IntegrationTime returns the time between two measurements.
+func (d *Device) IntegrationTime() time.Duration {
+	return d.it
+}
+
+// UVA returns the last compensated UVA reading, in counts.
+func (d *Device) UVA() int32 {
+	return int32(float32(d.uva) - d.coef.A*float32(d.comp1) - d.coef.B*float32(d.comp2))
+}
+
+// UVB returns the last compensated UVB reading, in counts.
+func (d *Device) UVB() int32 {
+	return int32(float32(d.uvb) - d.coef.C*float32(d.comp1) - d.coef.D*float32(d.comp2))
+}
+
+// UVIndex returns the UV index of the last reading, in thousandths. It is the
+// average of the UV indexes calculated from the UVA and UVB channels.
+func (d *Device) UVIndex() int32 {
+	// The responsivity is given for 100ms and normal dynamic.
+	scale := float32(100*time.Millisecond) / float32(d.it)
+	if d.conf&confHighDynamic != 0 {
+		scale *= 2
+	}
+	uva := float32(d.UVA()) * d.coef.UVAResponse * scale
+	uvb := float32(d.UVB()) * d.coef.UVBResponse * scale
+	uvi := (uva + uvb) / 2 * 1000
+	if uvi < 0 {
+		return 0
+	}
+	return int32(uvi)
+}
+
+func (d *Device) read16(reg uint8) (uint16, error) {
+	err := legacy.ReadRegister(d.bus, uint8(d.Address), reg, d.buf[:])
+	return uint16(d.buf[0]) | uint16(d.buf[1])<<8, err
+}
+
+func (d *Device) write16(reg uint8, value uint16) error {
+	d.buf[0] = byte(value)
+	d.buf[1] = byte(value >> 8)
+	return legacy.WriteRegister(d.bus, uint8(d.Address), reg, d.buf[:])
+}
@@ -0,0 +1,37 @@
+package veml6075
+
+import (
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+	"tinygo.org/x/drivers/tester"
+)
+
+func TestUVIndex(t *testing.T) {
+	c := qt.New(t)
+	bus := tester.NewI2CBus(c)
+	fake := tester.NewI2CDevice16(c, Address)
+	bus.AddDevice(fake)
+	// The mock is big endian, the VEML6075 little endian.
+	fake.Registers[UV_CONF] = 0
+	fake.Registers[ID] = swap(0x0026)
+
+	dev := New(bus)
+	c.Assert(dev.Configure(Config{}), qt.IsNil)
+	c.Assert(fake.Registers[UV_CONF], qt.Equals, swap(0x0010))
+
+	// UVA 1000 - 2.22*100 - 1.33*50 = 711.5, UVB 1500 - 2.95*100 - 1.74*50 = 1118.
+	fake.Registers[UVA_DATA] = swap(1000)
+	fake.Registers[UVB_DATA] = swap(1500)
+	fake.Registers[UVCOMP1] = swap(100)
+	fake.Registers[UVCOMP2] = swap(50)
+	c.Assert(dev.Update(), qt.IsNil)
+	c.Assert(dev.UVA(), qt.Equals, int32(711))
+	c.Assert(dev.UVB(), qt.Equals, int32(1118))
+	// (711*0.001461 + 1118*0.002591) / 2 = 1.968
+	c.Assert(dev.UVIndex(), qt.Equals, int32(1967))
+}
+
+func swap(v uint16) uint16 {
+	return v<<8 | v>>8
+}
@@ -0,0 +1,58 @@
+package vs1053
+
+import (
+	"io"
+)
+
+// Player plays a stream without blocking: Poll sends the decoder as much
+// data as it can take, and reads more from the source when its buffer is
+// empty. A buffer of a few card blocks lets a card reader read them with a
+// single multi-block read.
+type Player struct {
+	d    *Device
+	r    io.Reader
+	buf  []byte
+	data []byte // part of buf not sent yet
+	eof  bool
+}
+
+// NewPlayer returns a Player of r on the device, reading into buf, which
+// should be a multiple of 512 bytes when r reads a card.
+func (d *Device) NewPlayer(r io.Reader, buf []byte) *Player {
+	return &Player{d: d, r: r, buf: buf}
+}
+
+// Poll sends data while the decoder is ready, and returns true once the
+// whole stream was sent and the decoder flushed with Finish. Call it more
+// often than the decoder empties its 2kB buffer: every 50ms for a 320kbit/s
+// stream.
+func (p *Player) Poll() (bool, error) {
+	for p.d.Ready() {
+		if len(p.data) == 0 {
+			if p.eof {
+				return true, p.d.Finish()
+			}
+			n, err := p.r.Read(p.buf)
+			p.data = p.buf[:n]
+			if err == io.EOF {
+				p.eof = true
+			} else if err != nil {
+				return false, err
+			}
+			continue
+		}
+		n, err := p.d.writeData(p.data)
+		p.data = p.data[n:]
+		if err != nil {
+			return false, err
+		}
+	}
+	return false, nil
+}
+
+// Stop stops the playback before the end of the stream.
+func (p *Player) Stop() error {
+	p.data = nil
+	p.eof = true
+	return p.d.Finish()
+}
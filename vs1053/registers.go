@@ -0,0 +1,44 @@
+package vs1053
+
+// SCI registers
+const (
+	SCI_MODE        = 0x0
+	SCI_STATUS      = 0x1
+	SCI_BASS        = 0x2
+	SCI_CLOCKF      = 0x3
+	SCI_DECODE_TIME = 0x4
+	SCI_AUDATA      = 0x5
+	SCI_WRAM        = 0x6
+	SCI_WRAMADDR    = 0x7
+	SCI_HDAT0       = 0x8
+	SCI_HDAT1       = 0x9
+	SCI_AIADDR      = 0xA
+	SCI_VOL         = 0xB
+	SCI_AICTRL0     = 0xC
+	SCI_AICTRL1     = 0xD
+	SCI_AICTRL2     = 0xE
+	SCI_AICTRL3     = 0xF
+)
+
+// SCI_MODE bits
+const (
+	SM_DIFF     = 0x0001
+	SM_LAYER12  = 0x0002
+	SM_RESET    = 0x0004
+	SM_CANCEL   = 0x0008
+	SM_TESTS    = 0x0020
+	SM_STREAM   = 0x0040
+	SM_DACT     = 0x0100
+	SM_SDIORD   = 0x0200
+	SM_SDISHARE = 0x0400
+	SM_SDINEW   = 0x0800
+)
+
+const (
+	opWrite = 0x02
+	opRead  = 0x03
+
+	// endFillByteAddr is the address in X memory of the byte to send after
+	// the end of a stream.
+	endFillByteAddr = 0x1E06
+)
@@ -0,0 +1,35 @@
+//go:build tinygo
+
+package vs1053
+
+import (
+	"machine"
+	"time"
+
+	"tinygo.org/x/drivers"
+)
+
+// New creates a new VS1053 connection on an SPI bus configured in mode 0.
+// reset is the XRESET pin, or machine.NoPin when it is tied high.
+//
+// This function sets up the pins and resets the chip with XRESET, it does
+// not configure it.
+func New(bus drivers.SPI, xcs, xdcs, dreq, reset machine.Pin) *Device {
+	xcs.Configure(machine.PinConfig{Mode: machine.PinOutput})
+	xdcs.Configure(machine.PinConfig{Mode: machine.PinOutput})
+	dreq.Configure(machine.PinConfig{Mode: machine.PinInput})
+	xcs.High()
+	xdcs.High()
+	if reset != machine.NoPin {
+		reset.Configure(machine.PinConfig{Mode: machine.PinOutput})
+		reset.Low()
+		time.Sleep(time.Millisecond)
+		reset.High()
+	}
+	return &Device{
+		bus:  bus,
+		xcs:  xcs,
+		xdcs: xdcs,
+		dreq: dreq.Get,
+	}
+}
@@ -0,0 +1,253 @@
+// Package vs1053 implements a driver for the VS1053 and VS1003 audio codecs,
+// which decode MP3, Ogg Vorbis, AAC, WMA and MIDI streams in hardware: the
+// microcontroller only copies the file to the chip.
+//
+// The chip has two SPI interfaces on the same bus: SCI, selected by XCS, for
+// the registers, and SDI, selected by XDCS, for the audio data. DREQ is high
+// when the chip can take at least 32 bytes of data.
+//
+// Datasheet: https://www.vlsi.fi/fileadmin/datasheets/vs1053.pdf
+package vs1053 // import "tinygo.org/x/drivers/vs1053"
+
+import (
+	"errors"
+	"time"
+
+	"tinygo.org/x/drivers"
+)
+
+var (
+	errTimeout = errors.New("vs1053: timeout waiting for DREQ")
+	errPlugin  = errors.New("vs1053: truncated plugin")
+	errCancel  = errors.New("vs1053: decoder did not stop")
+)
+
+// chipSelect is the XCS or XDCS pin, a machine.Pin.
+type chipSelect interface {
+	High()
+	Low()
+}
+
+// Config is the configuration of the device.
+type Config struct {
+	// ClockF is the value of SCI_CLOCKF, which sets the clock multiplier.
+	// It is 0x6000 when 0: 3 times a 12.288MHz crystal for the VS1053, which
+	// is enough for all formats.
+	ClockF uint16
+}
+
+// Device wraps the SPI connection to a VS1053 or VS1003.
+type Device struct {
+	bus  drivers.SPI
+	xcs  chipSelect
+	xdcs chipSelect
+	dreq func() bool
+	buf  [4]byte
+}
+
+// Configure resets the decoder, and sets its clock multiplier. Until then,
+// the SPI clock must not be higher than 1.75MHz; SCI reads can then go up
+// to a seventh of the internal clock, and writes to a quarter.
+func (d *Device) Configure(cfg Config) error {
+	if cfg.ClockF == 0 {
+		cfg.ClockF = 0x6000
+	}
+	if err := d.WriteRegister(SCI_MODE, SM_SDINEW|SM_RESET); err != nil {
+		return err
+	}
+	time.Sleep(2 * time.Millisecond)
+	if err := d.waitReady(100 * time.Millisecond); err != nil {
+		return err
+	}
+	if err := d.WriteRegister(SCI_CLOCKF, cfg.ClockF); err != nil {
+		return err
+	}
+	time.Sleep(time.Millisecond)
+	return d.waitReady(100 * time.Millisecond)
+}
+
+// Version returns the chip version from SCI_STATUS: 3 for the VS1003, 4 for
+// the VS1053.
+func (d *Device) Version() (uint8, error) {
+	status, err := d.ReadRegister(SCI_STATUS)
+	return uint8(status >> 4 & 0x0F), err
+}
+
+// SetVolume sets the attenuation of the left and right channels in steps of
+// 0.5dB: 0 is the loudest, 254 is silence.
+func (d *Device) SetVolume(left, right uint8) error {
+	return d.WriteRegister(SCI_VOL, uint16(left)<<8|uint16(right))
+}
+
+// SetBassTreble sets the bass and treble enhancers, see SCI_BASS in the
+// datasheet: trebleDB from -8 to 7 in 1.5dB steps above trebleFreq kHz,
+// bassDB from 0 to 15 dB below bassFreq tens of Hz. Zero amplitudes turn
+// them off.
+func (d *Device) SetBassTreble(trebleDB int8, trebleFreq, bassDB, bassFreq uint8) error {
+	v := uint16(trebleDB&0x0F)<<12 | uint16(trebleFreq&0x0F)<<8 |
+		uint16(bassDB&0x0F)<<4 | uint16(bassFreq&0x0F)
+	return d.WriteRegister(SCI_BASS, v)
+}
+
+// DecodeTime returns the decoding time of the current stream in seconds.
+func (d *Device) DecodeTime() (uint16, error) {
+	return d.ReadRegister(SCI_DECODE_TIME)
+}
+
+// ReadRegister reads an SCI register.
+func (d *Device) ReadRegister(reg uint8) (uint16, error) {
+	if err := d.waitReady(100 * time.Millisecond); err != nil {
+		return 0, err
+	}
+	d.buf = [4]byte{opRead, reg, 0xFF, 0xFF}
+	d.xcs.Low()
+	err := d.bus.Tx(d.buf[:], d.buf[:])
+	d.xcs.High()
+	return uint16(d.buf[2])<<8 | uint16(d.buf[3]), err
+}
+
+// WriteRegister writes an SCI register.
+func (d *Device) WriteRegister(reg uint8, value uint16) error {
+	if err := d.waitReady(100 * time.Millisecond); err != nil {
+		return err
+	}
+	d.buf = [4]byte{opWrite, reg, byte(value >> 8), byte(value)}
+	d.xcs.Low()
+	err := d.bus.Tx(d.buf[:], nil)
+	d.xcs.High()
+	return err
+}
+
+// LoadPlugin loads a plugin or patch, in the compressed format of the
+// .plg files published by VLSI: an address, a count, then the values to
+// write; a count with bit 15 set repeats the next value.
+func (d *Device) LoadPlugin(plugin []uint16) error {
+	for i := 0; i < len(plugin); {
+		if i+2 > len(plugin) {
+			return errPlugin
+		}
+		addr, n := uint8(plugin[i]), int(plugin[i+1])
+		i += 2
+		if n&0x8000 != 0 {
+			// Run of the same value.
+			if i >= len(plugin) {
+				return errPlugin
+			}
+			for n &= 0x7FFF; n > 0; n-- {
+				if err := d.WriteRegister(addr, plugin[i]); err != nil {
+					return err
+				}
+			}
+			i++
+			continue
+		}
+		if i+n > len(plugin) {
+			return errPlugin
+		}
+		for _, v := range plugin[i : i+n] {
+			if err := d.WriteRegister(addr, v); err != nil {
+				return err
+			}
+		}
+		i += n
+	}
+	return nil
+}
+
+// Write sends audio data to the decoder, waiting for it to take it. An
+// io.Copy from a file plays it; see Player to play without blocking.
+func (d *Device) Write(p []byte) (int, error) {
+	n := 0
+	for n < len(p) {
+		if err := d.waitReady(time.Second); err != nil {
+			return n, err
+		}
+		m, err := d.writeData(p[n:])
+		n += m
+		if err != nil {
+			return n, err
+		}
+	}
+	return n, nil
+}
+
+// Ready returns whether the decoder can take at least 32 bytes of data.
+func (d *Device) Ready() bool {
+	return d.dreq()
+}
+
+// writeData sends up to 32 bytes of data, which the decoder can always
+// take when DREQ is high.
+func (d *Device) writeData(p []byte) (int, error) {
+	if len(p) > 32 {
+		p = p[:32]
+	}
+	d.xdcs.Low()
+	err := d.bus.Tx(p, nil)
+	d.xdcs.High()
+	return len(p), err
+}
+
+// Finish ends the current stream, so that the next one starts cleanly: it
+// flushes the decoder with the end fill byte, and cancels the decoding,
+// with a software reset if the decoder does not stop.
+func (d *Device) Finish() error {
+	d.WriteRegister(SCI_WRAMADDR, endFillByteAddr)
+	fill, err := d.ReadRegister(SCI_WRAM)
+	if err != nil {
+		return err
+	}
+	var buf [32]byte
+	for i := range buf {
+		buf[i] = byte(fill)
+	}
+	send := func(n int) error {
+		for ; n > 0; n -= len(buf) {
+			if _, err := d.Write(buf[:]); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	if err := send(2052); err != nil {
+		return err
+	}
+	mode, err := d.ReadRegister(SCI_MODE)
+	if err != nil {
+		return err
+	}
+	if err := d.WriteRegister(SCI_MODE, mode|SM_CANCEL); err != nil {
+		return err
+	}
+	for i := 0; i < 2048/32; i++ {
+		if err := send(32); err != nil {
+			return err
+		}
+		mode, err := d.ReadRegister(SCI_MODE)
+		if err != nil {
+			return err
+		}
+		if mode&SM_CANCEL == 0 {
+			return nil
+		}
+	}
+	if err := d.WriteRegister(SCI_MODE, SM_SDINEW|SM_RESET); err != nil {
+		return err
+	}
+	return errCancel
+}
+
+// waitReady waits for DREQ, yielding to other goroutines.
+func (d *Device) waitReady(timeout time.Duration) error {
+	if d.dreq() {
+		return nil
+	}
+	deadline := time.Now().Add(timeout)
+	for !d.dreq() {
+		if time.Now().After(deadline) {
+			return errTimeout
+		}
+		drivers.Yield()
+	}
+	return nil
+}
@@ -0,0 +1,127 @@
+package vs1053
+
+import (
+	"bytes"
+	"io"
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+)
+
+// fakeChip decodes the SCI commands and records the SDI data.
+type fakeChip struct {
+	regs   [16]uint16
+	wram   map[uint16]uint16
+	xcs    bool // selected
+	xdcs   bool
+	data   bytes.Buffer
+	writes []uint16 // SCI writes, as register<<12 | low bits of value
+	// cancelAfter is the number of data bytes after SM_CANCEL is set at
+	// which the decoder clears it.
+	cancelAfter int
+	canceled    int
+}
+
+type pin struct{ sel *bool }
+
+func (p pin) High() { *p.sel = false }
+func (p pin) Low()  { *p.sel = true }
+
+func (c *fakeChip) Transfer(b byte) (byte, error) { return 0, nil }
+
+func (c *fakeChip) Tx(w, r []byte) error {
+	switch {
+	case c.xcs && w[0] == opRead:
+		v := c.regs[w[1]]
+		if w[1] == SCI_WRAM {
+			v = c.wram[c.regs[SCI_WRAMADDR]]
+		}
+		r[2], r[3] = byte(v>>8), byte(v)
+	case c.xcs && w[0] == opWrite:
+		v := uint16(w[2])<<8 | uint16(w[3])
+		c.writes = append(c.writes, uint16(w[1])<<12|v&0x0FFF)
+		c.regs[w[1]] = v
+		if w[1] == SCI_MODE {
+			c.regs[SCI_MODE] &^= SM_RESET
+		}
+	case c.xdcs:
+		if len(w) > 32 {
+			panic("more than 32 bytes of data at once")
+		}
+		c.data.Write(w)
+		if c.regs[SCI_MODE]&SM_CANCEL != 0 {
+			c.canceled += len(w)
+			if c.canceled >= c.cancelAfter {
+				c.regs[SCI_MODE] &^= SM_CANCEL
+			}
+		}
+	}
+	return nil
+}
+
+func newDevice() (*Device, *fakeChip) {
+	chip := &fakeChip{wram: map[uint16]uint16{endFillByteAddr: 0x00AB}, cancelAfter: 64}
+	d := &Device{
+		bus:  chip,
+		xcs:  pin{&chip.xcs},
+		xdcs: pin{&chip.xdcs},
+		dreq: func() bool { return true },
+	}
+	return d, chip
+}
+
+func TestRegisters(t *testing.T) {
+	c := qt.New(t)
+	d, chip := newDevice()
+	c.Assert(d.Configure(Config{}), qt.IsNil)
+	c.Assert(chip.regs[SCI_CLOCKF], qt.Equals, uint16(0x6000))
+	c.Assert(chip.regs[SCI_MODE], qt.Equals, uint16(SM_SDINEW))
+
+	chip.regs[SCI_STATUS] = 0x0040
+	version, err := d.Version()
+	c.Assert(err, qt.IsNil)
+	c.Assert(version, qt.Equals, uint8(4))
+
+	c.Assert(d.SetVolume(20, 40), qt.IsNil)
+	c.Assert(chip.regs[SCI_VOL], qt.Equals, uint16(0x1428))
+	c.Assert(d.SetBassTreble(-2, 3, 10, 6), qt.IsNil)
+	c.Assert(chip.regs[SCI_BASS], qt.Equals, uint16(0xE3A6))
+}
+
+func TestLoadPlugin(t *testing.T) {
+	c := qt.New(t)
+	d, chip := newDevice()
+	plugin := []uint16{
+		0x0007, 0x0001, 0x8010, // WRAMADDR = 0x8010
+		0x0006, 0x0002, 0x1234, 0x5678, // two values to WRAM
+		0x0006, 0x8003, 0x0000, // three zeros to WRAM
+	}
+	c.Assert(d.LoadPlugin(plugin), qt.IsNil)
+	c.Assert(chip.writes, qt.DeepEquals, []uint16{0x7010, 0x6234, 0x6678, 0x6000, 0x6000, 0x6000})
+	c.Assert(d.LoadPlugin(plugin[:5]), qt.Equals, errPlugin)
+}
+
+func TestPlayer(t *testing.T) {
+	c := qt.New(t)
+	d, chip := newDevice()
+	song := make([]byte, 1000)
+	for i := range song {
+		song[i] = byte(i)
+	}
+	p := d.NewPlayer(io.MultiReader(bytes.NewReader(song[:300]), bytes.NewReader(song[300:])), make([]byte, 512))
+	done, err := p.Poll()
+	c.Assert(err, qt.IsNil)
+	c.Assert(done, qt.IsTrue)
+
+	// The song, 2052 end fill bytes rounded to 32, and the bytes until
+	// SM_CANCEL cleared.
+	sent := chip.data.Bytes()
+	c.Assert(sent[:1000], qt.DeepEquals, song)
+	c.Assert(len(sent), qt.Equals, 1000+2080+64)
+	c.Assert(sent[1000], qt.Equals, byte(0xAB))
+	c.Assert(chip.regs[SCI_MODE]&SM_CANCEL, qt.Equals, uint16(0))
+
+	// A decoder that does not stop is reset.
+	chip.cancelAfter = 1 << 20
+	c.Assert(d.Finish(), qt.Equals, errCancel)
+}
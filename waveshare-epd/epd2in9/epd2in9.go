@@ -246,6 +246,7 @@ func (d *Device) setMemoryPointer(x int16, y int16) {
 // WaitUntilIdle waits until the display is ready
 func (d *Device) WaitUntilIdle() {
 	for d.busy.Get() {
+		drivers.Yield()
 		time.Sleep(100 * time.Millisecond)
 	}
 }
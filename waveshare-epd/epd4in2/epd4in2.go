@@ -312,6 +312,7 @@ func (d *Device) ClearDisplay() {
 // WaitUntilIdle waits until the display is ready
 func (d *Device) WaitUntilIdle() {
 	for d.busy.Get() {
+		drivers.Yield()
 		time.Sleep(100 * time.Millisecond)
 	}
 }
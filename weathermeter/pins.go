@@ -0,0 +1,82 @@
+//go:build tinygo
+
+package weathermeter
+
+import (
+	"machine"
+	"sync/atomic"
+	"time"
+)
+
+type (
+	pin = machine.Pin
+	adc = machine.ADC
+)
+
+func (c *counter) configure() error {
+	c.pin.Configure(machine.PinConfig{Mode: machine.PinInputPullup})
+	return c.pin.SetInterrupt(machine.PinFalling, func(machine.Pin) {
+		now := time.Now().UnixNano()
+		if now-c.lastEdge < c.debounce {
+			return
+		}
+		c.lastEdge = now
+		atomic.AddUint32(&c.count, 1)
+	})
+}
+
+// NewAnemometer returns an anemometer on the given pin. The switch connects
+// the pin to ground.
+func NewAnemometer(pin machine.Pin) *Anemometer {
+	return &Anemometer{
+		counter: counter{pin: pin, debounce: int64(2 * time.Millisecond)},
+		// 2.4km/h per Hz
+		SpeedPerHz: 667,
+	}
+}
+
+// Configure sets up the pin interrupt.
+func (a *Anemometer) Configure() error {
+	return a.configure()
+}
+
+// NewRainGauge returns a rain gauge on the given pin. The switch connects
+// the pin to ground.
+func NewRainGauge(pin machine.Pin) *RainGauge {
+	return &RainGauge{
+		// The bucket switch bounces for several milliseconds.
+		counter: counter{pin: pin, debounce: int64(50 * time.Millisecond)},
+		PerTip:  279,
+	}
+}
+
+// Configure sets up the pin interrupt.
+func (r *RainGauge) Configure() error {
+	return r.configure()
+}
+
+// NewWindVane returns a wind vane on the given ADC pin. pullup is the
+// resistance in ohms of the resistor between the pin and the supply; the
+// vane connects the pin to ground. SparkFun weather shields use 10kΩ.
+func NewWindVane(pin machine.Pin, pullup uint32) *WindVane {
+	w := &WindVane{adc: machine.ADC{Pin: pin}}
+	w.setPullup(pullup)
+	return w
+}
+
+// Configure sets up the ADC.
+func (w *WindVane) Configure() {
+	machine.InitADC()
+	w.adc.Configure(machine.ADCConfig{})
+}
+
+// Direction returns the wind direction in tenths of a degree, clockwise from
+// north, in 22.5° steps.
+func (w *WindVane) Direction() uint16 {
+	return uint16(w.index(w.adc.Get())) * 225
+}
+
+// Cardinal returns the wind direction as a compass point such as "NNE".
+func (w *WindVane) Cardinal() string {
+	return cardinals[w.index(w.adc.Get())]
+}
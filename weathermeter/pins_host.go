@@ -0,0 +1,10 @@
+//go:build !tinygo
+
+package weathermeter
+
+// pin and adc stand in for machine.Pin and machine.ADC, so that the
+// calculations can be tested with go test.
+type (
+	pin uint8
+	adc struct{}
+)
@@ -0,0 +1,112 @@
+// Package weathermeter implements drivers for the common weather meter kit
+// sold by SparkFun, Misol and others: a cup anemometer and a tipping bucket
+// rain gauge with reed switches, and a wind vane with a resistor ladder.
+//
+// Datasheet: https://cdn.sparkfun.com/assets/d/1/e/0/6/DS-15901-Weather_Meter.pdf
+package weathermeter // import "tinygo.org/x/drivers/weathermeter"
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+// counter counts debounced falling edges of a reed switch.
+type counter struct {
+	pin      pin
+	debounce int64
+	count    uint32
+	lastEdge int64
+}
+
+func (c *counter) load() uint32 {
+	return atomic.LoadUint32(&c.count)
+}
+
+// Anemometer is a cup anemometer closing a switch once per rotation.
+type Anemometer struct {
+	counter
+
+	// SpeedPerHz is the wind speed in mm/s for one switch closure per
+	// second. Defaults to 667 (2.4km/h).
+	SpeedPerHz uint32
+
+	lastCount uint32
+	lastTime  time.Time
+	speed     uint32
+	gust      uint32
+}
+
+// Update calculates the average wind speed since the previous update, and
+// updates the gust speed. Gusts are conventionally the highest 3 second
+// average, so call Update every 3 seconds.
+func (a *Anemometer) Update(now time.Time) {
+	count := a.load()
+	if !a.lastTime.IsZero() {
+		elapsed := now.Sub(a.lastTime)
+		if elapsed > 0 {
+			pulses := uint64(count - a.lastCount)
+			a.speed = uint32(pulses * uint64(a.SpeedPerHz) * uint64(time.Second) / uint64(elapsed))
+			if a.speed > a.gust {
+				a.gust = a.speed
+			}
+		}
+	}
+	a.lastCount = count
+	a.lastTime = now
+}
+
+// Speed returns the wind speed in mm/s over the last update interval.
+func (a *Anemometer) Speed() uint32 {
+	return a.speed
+}
+
+// Gust returns the highest wind speed in mm/s since the last ResetGust.
+func (a *Anemometer) Gust() uint32 {
+	return a.gust
+}
+
+// ResetGust starts a new gust period, for example every 10 minutes.
+func (a *Anemometer) ResetGust() {
+	a.gust = 0
+}
+
+// RainGauge is a tipping bucket rain gauge closing a switch once per tip.
+type RainGauge struct {
+	counter
+
+	// PerTip is the rainfall in µm per bucket tip. Defaults to 279
+	// (0.2794mm).
+	PerTip uint32
+
+	dailyStart uint32
+	day        int
+	year       int
+}
+
+// Total returns the rainfall in µm since the gauge was configured.
+func (r *RainGauge) Total() uint32 {
+	return r.load() * r.PerTip
+}
+
+// Daily returns the rainfall in µm since the last daily reset.
+func (r *RainGauge) Daily() uint32 {
+	return (r.load() - r.dailyStart) * r.PerTip
+}
+
+// Update resets the daily rainfall when the day of now differs from the
+// previous update. Pass the time of a real-time clock, in the local time
+// zone of the station.
+func (r *RainGauge) Update(now time.Time) {
+	if now.YearDay() != r.day || now.Year() != r.year {
+		if r.year != 0 {
+			r.ResetDaily()
+		}
+		r.day = now.YearDay()
+		r.year = now.Year()
+	}
+}
+
+// ResetDaily resets the daily rainfall.
+func (r *RainGauge) ResetDaily() {
+	r.dailyStart = r.load()
+}
@@ -0,0 +1,50 @@
+package weathermeter
+
+import (
+	"testing"
+	"time"
+
+	qt "github.com/frankban/quicktest"
+)
+
+func TestWindVane(t *testing.T) {
+	c := qt.New(t)
+
+	w := &WindVane{}
+	w.setPullup(10000)
+	for i, r := range vaneResistance {
+		v := uint16(uint64(0xFFFF) * uint64(r) / uint64(r+10000))
+		c.Assert(w.index(v), qt.Equals, i)
+	}
+	// Readings between two directions pick the closest one.
+	c.Assert(w.index(w.expected[4]+200), qt.Equals, 4)
+}
+
+func TestAnemometer(t *testing.T) {
+	c := qt.New(t)
+
+	a := &Anemometer{SpeedPerHz: 667}
+	now := time.Now()
+	a.Update(now)
+	a.count += 6 // two rotations per second
+	a.Update(now.Add(3 * time.Second))
+	c.Assert(a.Speed(), qt.Equals, uint32(1334))
+	a.count += 3
+	a.Update(now.Add(6 * time.Second))
+	c.Assert(a.Speed(), qt.Equals, uint32(667))
+	c.Assert(a.Gust(), qt.Equals, uint32(1334))
+}
+
+func TestRainGauge(t *testing.T) {
+	c := qt.New(t)
+
+	r := &RainGauge{PerTip: 279}
+	day := time.Date(2024, 5, 1, 23, 0, 0, 0, time.UTC)
+	r.Update(day)
+	r.count += 10
+	r.Update(day.Add(30 * time.Minute))
+	c.Assert(r.Daily(), qt.Equals, uint32(2790))
+	r.Update(day.Add(2 * time.Hour))
+	c.Assert(r.Daily(), qt.Equals, uint32(0))
+	c.Assert(r.Total(), qt.Equals, uint32(2790))
+}
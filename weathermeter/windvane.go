@@ -0,0 +1,44 @@
+package weathermeter
+
+// vaneResistance is the resistance of the wind vane for every direction,
+// in 22.5° steps clockwise from north.
+var vaneResistance = [16]uint32{
+	33000, 6570, 8200, 891, 1000, 688, 2200, 1410,
+	3900, 3140, 16000, 14120, 120000, 42120, 64900, 21880,
+}
+
+var cardinals = [16]string{
+	"N", "NNE", "NE", "ENE", "E", "ESE", "SE", "SSE",
+	"S", "SSW", "SW", "WSW", "W", "WNW", "NW", "NNW",
+}
+
+// WindVane is a wind vane with a resistor ladder, read through a voltage
+// divider with a pull-up resistor.
+type WindVane struct {
+	adc      adc
+	expected [16]uint16
+}
+
+// setPullup calculates the expected readings for the given pull-up
+// resistance.
+func (w *WindVane) setPullup(pullup uint32) {
+	for i, r := range vaneResistance {
+		w.expected[i] = uint16(uint64(0xFFFF) * uint64(r) / uint64(r+pullup))
+	}
+}
+
+// index returns the direction closest to an ADC reading.
+func (w *WindVane) index(v uint16) int {
+	best := 0
+	bestDiff := int32(0x10000)
+	for i, e := range w.expected {
+		diff := int32(v) - int32(e)
+		if diff < 0 {
+			diff = -diff
+		}
+		if diff < bestDiff {
+			best, bestDiff = i, diff
+		}
+	}
+	return best
+}
@@ -0,0 +1,80 @@
+package wiegand
+
+import "errors"
+
+var (
+	errParity = errors.New("wiegand: parity error")
+	errLength = errors.New("wiegand: unsupported frame length")
+)
+
+// Frame is a frame received: its bits, the first one in the highest bit
+// of Data.
+type Frame struct {
+	Bits uint8
+	Data uint64
+}
+
+// Card is a card read.
+type Card struct {
+	Bits uint8  // length of the frame, 26 or 34
+	ID   uint32 // data bits of the frame, without the parity bits
+}
+
+// Facility returns the facility code: the 8 or 16 bits before the number.
+func (c Card) Facility() uint16 {
+	return uint16(c.ID >> 16)
+}
+
+// Number returns the card number: the last 16 bits.
+func (c Card) Number() uint16 {
+	return uint16(c.ID)
+}
+
+// Keys of the keypads.
+const (
+	KeyStar  = 10 // also ESC
+	KeyPound = 11 // also ENT
+)
+
+// Card decodes a 26 or 34 bit frame, with an even parity bit over the first
+// half of the data and an odd parity bit over the second half.
+func (f Frame) Card() (Card, error) {
+	if f.Bits != 26 && f.Bits != 34 {
+		return Card{}, errLength
+	}
+	n := f.Bits - 2 // data bits
+	data := uint32(f.Data>>1) & (1<<n - 1)
+	even := f.Data>>(f.Bits-1)&1 != 0
+	odd := f.Data&1 != 0
+	if parity(data>>(n/2)) != even || parity(data&(1<<(n/2)-1)) == odd {
+		return Card{}, errParity
+	}
+	return Card{Bits: f.Bits, ID: data}, nil
+}
+
+// Key decodes the frame of a key pressed on a keypad: 0 to 9, KeyStar or
+// KeyPound. Keypads send 4 bit frames, or 8 bit frames where the high
+// nibble is the complement of the key.
+func (f Frame) Key() (byte, error) {
+	switch f.Bits {
+	case 4:
+		return byte(f.Data), nil
+	case 8:
+		key := byte(f.Data) & 0x0F
+		if byte(f.Data)>>4 != ^key&0x0F {
+			return 0, errParity
+		}
+		return key, nil
+	}
+	return 0, errLength
+}
+
+// parity returns whether v has an odd number of ones.
+func parity(v uint32) bool {
+	v ^= v >> 16
+	v ^= v >> 8
+	v ^= v >> 4
+	v ^= v >> 2
+	v ^= v >> 1
+	return v&1 != 0
+}
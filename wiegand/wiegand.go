@@ -0,0 +1,140 @@
+//go:build tinygo
+
+// Package wiegand implements a receiver for the Wiegand interface of access
+// control readers: RFID card readers and keypads.
+//
+// The reader pulls the D0 line low for a 0 bit, and D1 for a 1 bit. Both
+// lines trigger a pin interrupt, so no polling is needed while a frame is
+// received; a frame ends when no bit came for the frame timeout. Frames are
+// buffered until read.
+//
+// Most readers run from 12V and drive the lines to 5V: use a level shifter
+// or a resistor divider on 3.3V boards.
+package wiegand // import "tinygo.org/x/drivers/wiegand"
+
+import (
+	"machine"
+	"runtime/interrupt"
+	"time"
+)
+
+// bufferSize is the size of the receive buffer.
+const bufferSize = 8
+
+// Config is the configuration of a receiver.
+type Config struct {
+	// Timeout is the gap after the last bit that ends a frame, 25ms when
+	// 0. Bits are 1 to 2ms apart.
+	Timeout time.Duration
+}
+
+// Device is a Wiegand receiver.
+type Device struct {
+	d0      machine.Pin
+	d1      machine.Pin
+	timeout time.Duration
+
+	// Frame being received, written by the interrupt handlers.
+	frame   Frame
+	lastBit time.Time
+
+	// Receive buffer, written by the interrupt handlers.
+	buffer [bufferSize]Frame
+	head   uint8
+	tail   uint8
+
+	errors uint32
+}
+
+// New returns a new receiver on the D0 and D1 pins.
+func New(d0, d1 machine.Pin) *Device {
+	return &Device{d0: d0, d1: d1}
+}
+
+// Configure sets up the pins and starts receiving.
+func (d *Device) Configure(cfg Config) error {
+	d.timeout = cfg.Timeout
+	if d.timeout == 0 {
+		d.timeout = 25 * time.Millisecond
+	}
+	d.d0.Configure(machine.PinConfig{Mode: machine.PinInputPullup})
+	d.d1.Configure(machine.PinConfig{Mode: machine.PinInputPullup})
+	if err := d.d0.SetInterrupt(machine.PinFalling, d.handleD0); err != nil {
+		return err
+	}
+	return d.d1.SetInterrupt(machine.PinFalling, d.handleD1)
+}
+
+func (d *Device) handleD0(machine.Pin) {
+	d.bit(0)
+}
+
+func (d *Device) handleD1(machine.Pin) {
+	d.bit(1)
+}
+
+// bit appends a bit to the frame, after ending the previous frame if the
+// timeout passed.
+func (d *Device) bit(b uint64) {
+	now := time.Now()
+	if d.frame.Bits != 0 && now.Sub(d.lastBit) > d.timeout {
+		d.end()
+	}
+	d.lastBit = now
+	if d.frame.Bits == 64 {
+		// Too long, dropped when it ends.
+		return
+	}
+	d.frame.Data = d.frame.Data<<1 | b
+	d.frame.Bits++
+}
+
+// end moves the frame received to the buffer.
+func (d *Device) end() {
+	next := (d.head + 1) % bufferSize
+	if d.frame.Bits == 64 || next == d.tail {
+		d.errors++
+	} else {
+		d.buffer[d.head] = d.frame
+		d.head = next
+	}
+	d.frame = Frame{}
+}
+
+// Receive returns the next frame received, or false when none is
+// available.
+func (d *Device) Receive() (Frame, bool) {
+	mask := interrupt.Disable()
+	if d.frame.Bits != 0 && time.Since(d.lastBit) > d.timeout {
+		d.end()
+	}
+	interrupt.Restore(mask)
+	if d.head == d.tail {
+		return Frame{}, false
+	}
+	f := d.buffer[d.tail]
+	d.tail = (d.tail + 1) % bufferSize
+	return f, true
+}
+
+// ReadCard returns the next card read, or false when none is available.
+// Frames that are not valid 26 or 34 bit frames are dropped, and counted by
+// Errors.
+func (d *Device) ReadCard() (Card, bool) {
+	for {
+		f, ok := d.Receive()
+		if !ok {
+			return Card{}, false
+		}
+		if c, err := f.Card(); err == nil {
+			return c, true
+		}
+		d.errors++
+	}
+}
+
+// Errors returns the number of frames dropped because they were invalid
+// or the buffer was full.
+func (d *Device) Errors() uint32 {
+	return d.errors
+}
@@ -0,0 +1,46 @@
+package wiegand
+
+import (
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+)
+
+func TestCard(t *testing.T) {
+	c := qt.New(t)
+
+	// Facility 123, card 4567: both halves have 7 ones, so the even parity
+	// bit is 1 and the odd one 0.
+	f := Frame{Bits: 26, Data: 1<<25 | (123<<16|4567)<<1}
+	card, err := f.Card()
+	c.Assert(err, qt.IsNil)
+	c.Assert(card.Facility(), qt.Equals, uint16(123))
+	c.Assert(card.Number(), qt.Equals, uint16(4567))
+
+	f.Data ^= 1 << 5
+	_, err = f.Card()
+	c.Assert(err, qt.Equals, errParity)
+
+	// 34 bits: 0x0000 has even parity, 0x0001 needs the odd bit at 0.
+	card, err = Frame{Bits: 34, Data: 0x00001 << 1}.Card()
+	c.Assert(err, qt.IsNil)
+	c.Assert(card, qt.Equals, Card{Bits: 34, ID: 1})
+	_, err = Frame{Bits: 34, Data: 0x00001<<1 | 1}.Card()
+	c.Assert(err, qt.Equals, errParity)
+
+	_, err = Frame{Bits: 32}.Card()
+	c.Assert(err, qt.Equals, errLength)
+}
+
+func TestKey(t *testing.T) {
+	c := qt.New(t)
+
+	key, err := Frame{Bits: 4, Data: 7}.Key()
+	c.Assert(err, qt.IsNil)
+	c.Assert(key, qt.Equals, byte(7))
+	key, err = Frame{Bits: 8, Data: 0x4B}.Key()
+	c.Assert(err, qt.IsNil)
+	c.Assert(key, qt.Equals, byte(KeyPound))
+	_, err = Frame{Bits: 8, Data: 0x5B}.Key()
+	c.Assert(err, qt.Equals, errParity)
+}
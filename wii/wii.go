@@ -0,0 +1,254 @@
+// Package wii implements a driver for Wii extension controllers: the
+// Nunchuk and the Classic Controller (and compatible third party pads),
+// which all share the same I2C protocol.
+//
+// The state of either controller is normalized to a gamepad State, so the
+// same code can handle both. Buttons uses the same bit layout as the
+// minigamepad package, sticks are scaled to the int16 range.
+//
+// Protocol reference: https://wiibrew.org/wiki/Wiimote/Extension_Controllers
+package wii // import "tinygo.org/x/drivers/wii"
+
+import (
+	"errors"
+	"time"
+
+	"tinygo.org/x/drivers"
+)
+
+// The I2C address of all extension controllers.
+const Address = 0x52
+
+var errUnknown = errors.New("wii: unknown extension controller")
+
+// Controller is the type of extension controller.
+type Controller uint8
+
+const (
+	Unknown Controller = iota
+	Nunchuk
+	Classic
+)
+
+// Buttons.
+const (
+	ButtonA = 1 << iota
+	ButtonB
+	ButtonX
+	ButtonY
+	ButtonL
+	ButtonR
+	ButtonZL
+	ButtonZR
+	ButtonMinus
+	ButtonPlus
+	ButtonHome
+	ButtonUp
+	ButtonDown
+	ButtonLeft
+	ButtonRight
+)
+
+// The Nunchuk C and Z buttons share the bits of A and B, so a Nunchuk works
+// as a two button gamepad.
+const (
+	ButtonC = ButtonA
+	ButtonZ = ButtonB
+)
+
+// State is the normalized state of a controller.
+type State struct {
+	Buttons uint16
+
+	// Left stick (the only one of a Nunchuk) and right stick, from -32767
+	// to 32767, positive to the right and up.
+	X, Y   int16
+	RX, RY int16
+
+	// Analog triggers of a Classic Controller, from 0 to 32767. Pads
+	// without analog triggers report 0 or full scale.
+	LT, RT int16
+
+	// Accelerometer of a Nunchuk, 10 bit raw values centered at 0.
+	AX, AY, AZ int16
+}
+
+// Pressed returns whether all the buttons in mask are pressed.
+func (s State) Pressed(mask uint16) bool {
+	return s.Buttons&mask == mask
+}
+
+// Hat returns the direction of the D-pad, 0 to 7 clockwise from up, or
+// 0x0F when centered, as used by HID gamepad hat switches.
+func (s State) Hat() uint8 {
+	up := s.Buttons&ButtonUp != 0
+	down := s.Buttons&ButtonDown != 0
+	left := s.Buttons&ButtonLeft != 0
+	right := s.Buttons&ButtonRight != 0
+	switch {
+	case up && right:
+		return 1
+	case down && right:
+		return 3
+	case down && left:
+		return 5
+	case up && left:
+		return 7
+	case up:
+		return 0
+	case right:
+		return 2
+	case down:
+		return 4
+	case left:
+		return 6
+	}
+	return 0x0F
+}
+
+// Device wraps an I2C connection to a Wii extension controller.
+type Device struct {
+	bus        drivers.I2C
+	controller Controller
+	center     [2]uint8 // Nunchuk stick center
+	data       [6]byte
+	State      State
+}
+
+// New creates a new connection. The I2C bus must already be configured, at
+// 100kHz or 400kHz.
+//
+// This function only creates the Device object, it does not touch the device.
+func New(bus drivers.I2C) *Device {
+	return &Device{bus: bus}
+}
+
+// Configure initializes the controller without encryption and identifies
+// it. The Nunchuk stick must be centered while configuring.
+func (d *Device) Configure() error {
+	if err := d.bus.Tx(Address, []byte{0xF0, 0x55}, nil); err != nil {
+		return err
+	}
+	time.Sleep(time.Millisecond)
+	if err := d.bus.Tx(Address, []byte{0xFB, 0x00}, nil); err != nil {
+		return err
+	}
+	time.Sleep(time.Millisecond)
+
+	var id [6]byte
+	if err := d.read(0xFA, id[:]); err != nil {
+		return err
+	}
+	switch {
+	case id[2] == 0xA4 && id[3] == 0x20 && id[4] == 0x00 && id[5] == 0x00:
+		d.controller = Nunchuk
+	case id[2] == 0xA4 && id[3] == 0x20 && id[4] == 0x01 && id[5] == 0x01:
+		d.controller = Classic
+	default:
+		d.controller = Unknown
+		return errUnknown
+	}
+
+	d.center = [2]uint8{128, 128}
+	if d.controller == Nunchuk {
+		if err := d.read(0x00, d.data[:]); err != nil {
+			return err
+		}
+		d.center = [2]uint8{d.data[0], d.data[1]}
+	}
+	return nil
+}
+
+// Controller returns the type of controller found by Configure.
+func (d *Device) Controller() Controller {
+	return d.controller
+}
+
+// Update reads the controller and updates State.
+func (d *Device) Update() error {
+	if err := d.read(0x00, d.data[:]); err != nil {
+		return err
+	}
+	switch d.controller {
+	case Nunchuk:
+		d.State = decodeNunchuk(d.data, d.center)
+	case Classic:
+		d.State = decodeClassic(d.data)
+	}
+	return nil
+}
+
+func (d *Device) read(reg uint8, data []byte) error {
+	if err := d.bus.Tx(Address, []byte{reg}, nil); err != nil {
+		return err
+	}
+	// The controller needs some time before the data can be read.
+	time.Sleep(200 * time.Microsecond)
+	return d.bus.Tx(Address, nil, data)
+}
+
+func decodeNunchuk(b [6]byte, center [2]uint8) State {
+	var s State
+	// Buttons are active low.
+	if b[5]&0x01 == 0 {
+		s.Buttons |= ButtonZ
+	}
+	if b[5]&0x02 == 0 {
+		s.Buttons |= ButtonC
+	}
+	// The stick range is roughly ±100 around the center.
+	s.X = stick(int32(b[0])-int32(center[0]), 100)
+	s.Y = stick(int32(b[1])-int32(center[1]), 100)
+	s.AX = int16(b[2])<<2 | int16(b[5]>>2&0x03) - 512
+	s.AY = int16(b[3])<<2 | int16(b[5]>>4&0x03) - 512
+	s.AZ = int16(b[4])<<2 | int16(b[5]>>6&0x03) - 512
+	return s
+}
+
+func decodeClassic(b [6]byte) State {
+	var s State
+	lx := int32(b[0] & 0x3F)
+	ly := int32(b[1] & 0x3F)
+	rx := int32(b[0]>>6)<<3 | int32(b[1]>>6)<<1 | int32(b[2]>>7)
+	ry := int32(b[2] & 0x1F)
+	lt := int32(b[2]>>5&0x03)<<3 | int32(b[3]>>5)
+	rt := int32(b[3] & 0x1F)
+
+	s.X = stick(lx-32, 32)
+	s.Y = stick(ly-32, 32)
+	s.RX = stick(rx-16, 16)
+	s.RY = stick(ry-16, 16)
+	s.LT = int16(lt * 32767 / 31)
+	s.RT = int16(rt * 32767 / 31)
+
+	// Buttons are active low.
+	buttons := ^(uint16(b[4])<<8 | uint16(b[5]))
+	bits := [...]struct {
+		mask   uint16
+		button uint16
+	}{
+		{0x8000, ButtonRight}, {0x4000, ButtonDown}, {0x2000, ButtonL},
+		{0x1000, ButtonMinus}, {0x0800, ButtonHome}, {0x0400, ButtonPlus},
+		{0x0200, ButtonR}, {0x0080, ButtonZL}, {0x0040, ButtonB},
+		{0x0020, ButtonY}, {0x0010, ButtonA}, {0x0008, ButtonX},
+		{0x0004, ButtonZR}, {0x0002, ButtonLeft}, {0x0001, ButtonUp},
+	}
+	for _, bit := range bits {
+		if buttons&bit.mask != 0 {
+			s.Buttons |= bit.button
+		}
+	}
+	return s
+}
+
+// stick scales a centered stick value with the given range to the int16
+// range.
+func stick(v, max int32) int16 {
+	v = v * 32767 / max
+	if v > 32767 {
+		v = 32767
+	} else if v < -32767 {
+		v = -32767
+	}
+	return int16(v)
+}
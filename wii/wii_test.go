@@ -0,0 +1,32 @@
+package wii
+
+import (
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+)
+
+func TestDecodeNunchuk(t *testing.T) {
+	c := qt.New(t)
+
+	s := decodeNunchuk([6]byte{228, 28, 0x80, 0x80, 0xB3, 0b00000010}, [2]uint8{128, 128})
+	c.Assert(s.Buttons, qt.Equals, uint16(ButtonZ))
+	c.Assert(s.X, qt.Equals, int16(32767))
+	c.Assert(s.Y, qt.Equals, int16(-32767))
+	c.Assert(s.AX, qt.Equals, int16(0))
+	c.Assert(s.AZ, qt.Equals, int16(0xB3<<2-512))
+}
+
+func TestDecodeClassic(t *testing.T) {
+	c := qt.New(t)
+
+	// Sticks centered, triggers released, A and D-pad up pressed.
+	s := decodeClassic([6]byte{0xA0, 0x20, 0x10, 0x00, 0xFF, 0xFF &^ 0x11})
+	c.Assert(s.X, qt.Equals, int16(0))
+	c.Assert(s.Y, qt.Equals, int16(0))
+	c.Assert(s.RX, qt.Equals, int16(0))
+	c.Assert(s.RY, qt.Equals, int16(0))
+	c.Assert(s.LT, qt.Equals, int16(0))
+	c.Assert(s.Buttons, qt.Equals, uint16(ButtonA|ButtonUp))
+	c.Assert(s.Hat(), qt.Equals, uint8(0))
+}
@@ -0,0 +1,21 @@
+package drivers
+
+// yield is the function set by SetYield.
+var yield func()
+
+// SetYield sets a function that drivers call periodically while they wait in
+// long running operations, such as SD card writes, e-paper refreshes and
+// modem responses. Applications can use it to feed a watchdog or run other
+// work during these operations. The function must be fast and must not use
+// the driver that called it. Pass nil to remove the hook.
+func SetYield(f func()) {
+	yield = f
+}
+
+// Yield calls the function set by SetYield, if any. Drivers call it from
+// their wait loops.
+func Yield() {
+	if yield != nil {
+		yield()
+	}
+}